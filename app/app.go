@@ -0,0 +1,352 @@
+// Package app builds the services and the HTTP handler from the configuration. Both entrypoints use
+// it: the long-running server (main.go) and the Lambda function (cmd/lambda).
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"vibin_server/config"
+	"vibin_server/helpers"
+	"vibin_server/models"
+	"vibin_server/routes"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/cors"
+)
+
+// Options selects what runs beside the HTTP handler
+type Options struct {
+	Workers bool // Job queue pollers, stream consumers, sweepers and cache reloaders; off for Lambda, where the process is frozen between requests
+}
+
+// cacheRefreshInterval is how often the in-memory caches (moderation rules, feature flags, webhooks,
+// network blocks) are reloaded
+const cacheRefreshInterval = 30 * time.Second
+
+// App is the wired application
+type App struct {
+	Handler http.Handler // Router wrapped in CORS, tracing and request IDs
+
+	// ✅ Services the gRPC API is built from
+	UserProfileService *services.UserProfileService
+	InteractionService *services.InteractionService
+	ChatService        *services.ChatService
+
+	dateCheckIns    *services.DateCheckInService
+	accountDeletion *services.AccountDeletionService
+	caches          []cacheReloader
+	cachesLoadedAt  time.Time
+	flushTracing    func(context.Context) error
+	shutdownTracing func(context.Context) error
+}
+
+// cacheReloader is one in-memory cache and how to reload it
+type cacheReloader struct {
+	name   string
+	reload func(context.Context) error
+}
+
+// New creates the AWS clients and services, and builds the HTTP handler
+func New(cfg *config.Config, opts Options) (*App, error) {
+	// Initialize tracing before any AWS client so DynamoDB spans are exported
+	flushTracing, shutdownTracing, err := services.InitializeTracing(context.Background(), cfg.OTLPEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	models.ApplyTablePrefix(cfg.TablePrefix) // ✅ Per-environment table names; nothing below can fail
+	log.Printf("Loaded configuration (region=%s, tablePrefix=%q)", cfg.AWSRegion, cfg.TablePrefix)
+
+	// Initialize DynamoDB client and service
+	log.Println("Initializing DynamoDB client...")
+	dynamoClient := services.InitializeDynamoDBClient(cfg.AWSRegion)
+	dynamoService := &services.DynamoService{Client: dynamoClient, Retry: services.DynamoRetryPolicy{
+		MaxAttempts: cfg.DynamoRetryMaxAttempts,
+		BaseDelay:   cfg.DynamoRetryBaseDelay,
+		MaxDelay:    cfg.DynamoRetryMaxDelay,
+	}}
+	log.Println("DynamoDB client initialized.")
+
+	// Initialize Services
+	moderationService := &services.ModerationService{Dynamo: dynamoService}
+	featureFlagService := &services.FeatureFlagService{Dynamo: dynamoService}
+	webhookService := &services.WebhookService{Dynamo: dynamoService}
+	if opts.Workers {
+		moderationService.StartRuleReloader(context.Background(), cacheRefreshInterval)  // ✅ Hot-reload content rules
+		featureFlagService.StartFlagReloader(context.Background(), cacheRefreshInterval) // ✅ Remote config / percentage rollouts
+		webhookService.StartWebhookReloader(context.Background(), cacheRefreshInterval)  // ✅ Outbound events for trust & safety / CRM
+	}
+	encryptionService := &services.EncryptionService{Dynamo: dynamoService}
+	if cfg.KMSKeyID != "" { // ✅ Encrypt message content at rest when configured
+		encryptionService.KMS = services.InitializeKMSClient(cfg.AWSRegion)
+		encryptionService.KMSKeyID = cfg.KMSKeyID
+	}
+	retention := models.RetentionPolicy{ // ✅ Expire declined interactions and unmatched conversations via DynamoDB TTL
+		DeclinedInteractionDays: cfg.RetentionDeclinedInteractionDays,
+		UnmatchedMessageDays:    cfg.RetentionUnmatchedMessageDays,
+	}
+	piiService := &services.PIIService{Encryption: encryptionService, BlindIndexKey: cfg.PIIBlindIndexKey}
+	blockService := &services.BlockService{Dynamo: dynamoService, Webhooks: webhookService}
+	contactService := &services.ContactService{Dynamo: dynamoService, PII: piiService}
+	keyService := &services.KeyService{Dynamo: dynamoService, Blocks: blockService}
+	geocodingService := &services.GeocodingService{PlaceIndex: cfg.GeocodingPlaceIndex}
+	if cfg.GeocodingPlaceIndex != "" { // ✅ Name each user's city/region for place browsing
+		geocodingService.Client = services.InitializeLocationClient(cfg.AWSRegion)
+	}
+	userProfileService := &services.UserProfileService{Dynamo: dynamoService, PII: piiService, Webhooks: webhookService, Blocks: blockService, Contacts: contactService, Geocoding: geocodingService, MinSuggestionCompleteness: cfg.SuggestionMinCompleteness, NewUserBoost: cfg.NewUserBoost}
+	userProfileService.Shown = &services.ShownProfileService{Dynamo: dynamoService, RepeatBatches: cfg.SuggestionRepeatBatches} // ✅ Don't repeat the deck every refresh
+	analyticsService := &services.AnalyticsService{Dynamo: dynamoService, UserProfileService: userProfileService}
+	userProfileService.Analytics = analyticsService // ✅ Funnel events (profile created)
+	chatService := &services.ChatService{Dynamo: dynamoService, Moderation: moderationService, Encryption: encryptionService, UserProfileService: userProfileService, Analytics: analyticsService, Webhooks: webhookService, Retention: retention}
+	photoInsightsService := &services.PhotoInsightsService{Dynamo: dynamoService, UserProfileService: userProfileService}
+	billingService := &services.BillingService{ // ✅ Stripe billing; premium features are open to everyone until configured
+		Dynamo:             dynamoService,
+		UserProfileService: userProfileService,
+		StripeSecretKey:    cfg.Stripe.SecretKey,
+		WebhookSecret:      cfg.Stripe.WebhookSecret,
+		PremiumPriceID:     cfg.Stripe.PremiumPriceID,
+	}
+	spotifyService := &services.SpotifyService{ // ✅ Top artists on profiles; connect is unavailable until configured
+		Dynamo:       dynamoService,
+		ClientID:     cfg.Spotify.ClientID,
+		ClientSecret: cfg.Spotify.ClientSecret,
+		RedirectURI:  cfg.Spotify.RedirectURI,
+	}
+	promoCodeService := &services.PromoCodeService{Dynamo: dynamoService, Billing: billingService}
+	passportService := &services.PassportService{Dynamo: dynamoService, Billing: billingService}
+	interactionService := &services.InteractionService{Dynamo: dynamoService, UserProfileService: userProfileService, ChatService: chatService, PhotoInsights: photoInsightsService, Billing: billingService, Analytics: analyticsService, Webhooks: webhookService, Blocks: blockService, Retention: retention}
+	groupChatService := &services.GroupChatService{Dynamo: dynamoService, Moderation: moderationService, Encryption: encryptionService, Webhooks: webhookService} // ✅ Initialize GroupChatService
+	groupInteractionService := &services.GroupInteractionService{Dynamo: dynamoService, UserProfileService: userProfileService, GroupChat: groupChatService}
+	inboxService := &services.InboxService{Chat: chatService, GroupChat: groupChatService, Groups: groupInteractionService, UserProfileService: userProfileService}
+	badgeService := &services.BadgeService{Chat: chatService, GroupChat: groupChatService, Interaction: interactionService, Groups: groupInteractionService}
+	singleTableService := &services.SingleTableService{Dynamo: dynamoService}
+	dateIdeasService := &services.DateIdeasService{Dynamo: dynamoService, UserProfileService: userProfileService}
+	openerService := &services.OpenerService{Dynamo: dynamoService, UserProfileService: userProfileService, Moderation: moderationService, FeatureFlags: featureFlagService}
+	bioSuggestionService := &services.BioSuggestionService{Dynamo: dynamoService, Moderation: moderationService}
+	messageSafetyService := &services.MessageSafetyService{Dynamo: dynamoService, Chat: chatService, GroupChat: groupChatService, Webhooks: webhookService}
+	chatService.Safety = messageSafetyService
+	groupChatService.Safety = messageSafetyService
+	if cfg.LLM.Endpoint != "" { // ✅ Generated openers/bios and message screening stay off until a model is configured
+		llm := &services.OpenAICompatibleProvider{Endpoint: cfg.LLM.Endpoint, APIKey: cfg.LLM.APIKey, Model: cfg.LLM.Model}
+		openerService.LLM = llm
+		bioSuggestionService.LLM = llm
+		classifierLLM := *llm
+		classifierLLM.Greedy = true
+		messageSafetyService.Classifier = &services.LLMMessageClassifier{LLM: &classifierLLM}
+	}
+	callService := &services.CallService{Dynamo: dynamoService}
+	switch cfg.RTC.Provider { // ✅ Call tokens stay unavailable until a provider is configured
+	case models.RTCProviderAgora:
+		callService.Provider = &services.AgoraTokenProvider{AppID: cfg.RTC.AgoraAppID, AppCertificate: cfg.RTC.AgoraAppCertificate}
+	case models.RTCProviderTwilio:
+		callService.Provider = &services.TwilioTokenProvider{AccountSID: cfg.Twilio.AccountSID, APIKeySID: cfg.Twilio.APIKeySID, APIKeySecret: cfg.Twilio.APIKeySecret}
+	}
+	var smsSender services.SMSSender
+	if cfg.Twilio.SMSFrom != "" { // ✅ Texts check-in emergency contacts, and sign-in codes to accounts without an email
+		smsSender = &services.TwilioSMSSender{AccountSID: cfg.Twilio.AccountSID, APIKeySID: cfg.Twilio.APIKeySID, APIKeySecret: cfg.Twilio.APIKeySecret, From: cfg.Twilio.SMSFrom}
+	}
+	dateCheckInService := &services.DateCheckInService{Dynamo: dynamoService, Encryption: encryptionService, Webhooks: webhookService, SMS: smsSender, Profiles: userProfileService}
+	profileViewService := &services.ProfileViewService{Dynamo: dynamoService, UserProfileService: userProfileService}
+	s3Service := &services.S3Service{Client: services.InitializeS3Client(cfg.AWSRegion), Bucket: cfg.S3BucketName}
+	userProfileService.S3 = s3Service // ✅ Voice prompt URLs
+	accountDeletionService := &services.AccountDeletionService{Dynamo: dynamoService, S3: s3Service}
+	ageVerificationService := &services.AgeVerificationService{Dynamo: dynamoService, S3: s3Service}
+	conversationExportService := &services.ConversationExportService{Dynamo: dynamoService, Chat: chatService, GroupChat: groupChatService, S3: s3Service}
+	reportService := &services.ReportService{Dynamo: dynamoService, Blocks: blockService, S3: s3Service, Webhooks: webhookService}
+	accountStandingService := &services.AccountStandingService{Dynamo: dynamoService}
+	sessionService := &services.SessionService{Dynamo: dynamoService, PII: piiService, SMS: smsSender, Required: cfg.SessionsRequired, CountryHeader: cfg.CountryHeader}
+	if cfg.EmailFrom != "" { // ✅ Every sign-in needs a code sent to the account's email or phone
+		sessionService.Email = services.InitializeSESEmailSender(cfg.AWSRegion, cfg.EmailFrom)
+	}
+	captchaService := &services.CaptchaService{Provider: cfg.Captcha.Provider, Secret: cfg.Captcha.Secret, Actions: cfg.Captcha.Actions} // ✅ Off unless CAPTCHA_PROVIDER is set
+	spamDetector := &services.SpamDetector{Dynamo: dynamoService}
+	networkGuardService := &services.NetworkGuardService{Dynamo: dynamoService, IPLimit: cfg.RateLimitPerIP, ASNLimit: cfg.RateLimitPerASN}
+	if opts.Workers {
+		networkGuardService.StartBlockReloader(context.Background(), cacheRefreshInterval) // ✅ Admin-managed CIDR/ASN/country blocks
+	}
+	healthService := &services.HealthService{Dynamo: dynamoService, S3: s3Service}
+	photoModerationService := &services.PhotoModerationService{Dynamo: dynamoService, UserProfileService: userProfileService}
+	if cfg.PhotoModeration { // ✅ Quarantine nudity/violence until reviewed; primary photos need a face
+		photoModerationService.Rekognition = services.InitializeRekognitionClient(cfg.AWSRegion)
+	}
+	photoService := &services.PhotoService{S3: s3Service, UserProfileService: userProfileService, PhotoInsights: photoInsightsService, Moderation: photoModerationService}
+	photoModerationService.Photos = photoService
+
+	// Move non-critical side effects (analytics, photo insights, webhooks, photo renditions) off the request path
+	jobQueue := &services.JobQueue{QueueURL: cfg.JobQueueURL, Inline: !opts.Workers}
+	if cfg.JobQueueURL != "" {
+		jobQueue.SQS = services.InitializeSQSClient(cfg.AWSRegion)
+	}
+	analyticsService.RegisterJobs(jobQueue)
+	interactionService.RegisterJobs(jobQueue)
+	chatService.RegisterJobs(jobQueue)
+	messageSafetyService.RegisterJobs(jobQueue)
+	webhookService.RegisterJobs(jobQueue)
+	photoService.RegisterJobs(jobQueue)
+	if opts.Workers {
+		jobQueue.StartWorkers(context.Background(), cfg.JobWorkers)
+		accountDeletionService.StartSweeper(context.Background(), time.Hour) // ✅ Purge accounts past their restore window
+		dateCheckInService.StartSweeper(context.Background(), time.Minute)   // ✅ Prompt and escalate due date check-ins
+	}
+
+	// Maintain conversation summaries (last message, unread counters) and the single-table mirror from
+	// the table streams; one consumer per table runs every handler it needs
+	streamHandlers := map[string][]services.StreamRecordHandler{}
+	if cfg.StreamConsumers && opts.Workers {
+		summaryService := &services.ConversationSummaryService{Dynamo: dynamoService}
+		chatService.Summaries = summaryService
+		streamHandlers[models.MessagesTable] = append(streamHandlers[models.MessagesTable], summaryService.HandleMessageRecord)
+		streamHandlers[models.InteractionsTable] = append(streamHandlers[models.InteractionsTable], summaryService.HandleInteractionRecord)
+	}
+	if cfg.SingleTableDualWrite && opts.Workers {
+		mirror := &services.SingleTableMirror{Dynamo: dynamoService} // ✅ Keep VibinData current while the migration runs
+		for _, table := range services.SingleTableMirrorTables {
+			streamHandlers[table] = append(streamHandlers[table], mirror.HandleRecord(table))
+		}
+	}
+	if len(streamHandlers) > 0 {
+		streamsClient := services.InitializeDynamoDBStreamsClient(cfg.AWSRegion)
+		for table, handlers := range streamHandlers {
+			consumer := &services.StreamConsumer{Dynamo: dynamoService, Streams: streamsClient, Table: table, Handler: services.ChainStreamHandlers(handlers...)}
+			consumer.Start(context.Background(), 30*time.Second)
+		}
+	}
+
+	// Initialize the router
+	r := mux.NewRouter()
+	r.Use(helpers.RouteSpanMiddleware)                   // ✅ Name server spans after the matched route
+	r.Use(helpers.TimeoutMiddleware(cfg.RequestTimeout)) // ✅ Per-request deadline for DynamoDB work
+
+	// Register a welcome route
+	r.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "Welcome to Vibin")
+	}).Methods("GET")
+
+	// Register liveness and readiness probes
+	routes.RegisterHealthRoutes(r, healthService)
+
+	// Register API routes (/api/v1, /api/v2 and the unversioned /api shim)
+	routes.RegisterAPIRoutes(r, routes.APIServices{
+		UserProfile:      userProfileService,
+		Chat:             chatService,
+		Interaction:      interactionService,
+		GroupInteraction: groupInteractionService,
+		GroupChat:        groupChatService,
+		SingleTable:      singleTableService,
+		PhotoInsights:    photoInsightsService,
+		DateIdeas:        dateIdeasService,
+		ProfileView:      profileViewService,
+		Billing:          billingService,
+		PromoCode:        promoCodeService,
+		FeatureFlag:      featureFlagService,
+		Moderation:       moderationService,
+		Encryption:       encryptionService,
+		Analytics:        analyticsService,
+		Webhook:          webhookService,
+		S3:               s3Service,
+		Photo:            photoService,
+		PhotoModeration:  photoModerationService,
+		MessageSafety:    messageSafetyService,
+		Call:             callService,
+		DateCheckIn:      dateCheckInService,
+		Spotify:          spotifyService,
+		Passport:         passportService,
+		AccountDeletion:  accountDeletionService,
+		AgeVerification:  ageVerificationService,
+		Block:            blockService,
+		Report:           reportService,
+		AccountStanding:  accountStandingService,
+		Session:          sessionService,
+		Captcha:          captchaService,
+		Spam:             spamDetector,
+		NetworkGuard:     networkGuardService,
+		Contact:          contactService,
+		Key:              keyService,
+		Export:           conversationExportService,
+		Opener:           openerService,
+		BioSuggestion:    bioSuggestionService,
+		Inbox:            inboxService,
+		Badge:            badgeService,
+		AdminUserHandles: cfg.AdminUserHandles,
+	})
+	routes.RegisterLegacyS3Routes(r, s3Service, userProfileService, photoModerationService, sessionService) // ✅ Legacy root-level presigned URL routes
+	routes.RegisterGraphQLRoutes(r, userProfileService, interactionService, chatService, sessionService)
+
+	r.HandleFunc("/privacy-policy", routes.PrivacyPolicyHandler).Methods("GET")
+
+	// Add CORS middleware
+	handler := cors.New(cors.Options{
+		AllowedOrigins:   []string{"*"}, // Adjust for specific domains if needed
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", helpers.RequestIDHeader, models.CaptchaTokenHeader, "traceparent", "tracestate"},
+		ExposedHeaders:   []string{helpers.RequestIDHeader, helpers.NextCursorHeader, helpers.AfterCursorHeader, helpers.APIVersionHeader, "Deprecation", "Link", "ETag"}, // ✅ Let web clients read request IDs, page cursors, version hints and ETags
+		AllowCredentials: true,
+	}).Handler(helpers.TracingHandler(helpers.CompressionMiddleware(cfg.CompressionMinBytes)(helpers.RequestIDMiddleware( // ✅ gzip large JSON (suggestions, message history) for mobile clients
+		helpers.NetworkGuardMiddleware(networkGuardService, cfg.ASNHeader, cfg.CountryHeader, routes.IsHealthProbe)(r), // ✅ Blocklists and per-IP/ASN throttling, before routing so unknown paths count too
+	)))) // ✅ Server span + X-Request-ID for logs and error responses
+
+	return &App{
+		Handler:            handler,
+		UserProfileService: userProfileService,
+		InteractionService: interactionService,
+		ChatService:        chatService,
+		dateCheckIns:       dateCheckInService,
+		accountDeletion:    accountDeletionService,
+		caches: []cacheReloader{
+			{name: "moderation rules", reload: moderationService.ReloadRules},
+			{name: "feature flags", reload: featureFlagService.ReloadFlags},
+			{name: "webhooks", reload: webhookService.ReloadWebhooks},
+			{name: "network blocks", reload: networkGuardService.ReloadBlocks},
+		},
+		flushTracing:    flushTracing,
+		shutdownTracing: shutdownTracing,
+	}, nil
+}
+
+// RefreshCaches reloads the in-memory caches when they are older than cacheRefreshInterval. The
+// server's reloaders do this on timers; the Lambda function calls it at the start of each
+// invocation, since timers don't fire while the process is frozen. Failed reloads keep the cache.
+func (a *App) RefreshCaches(ctx context.Context) {
+	if time.Since(a.cachesLoadedAt) < cacheRefreshInterval {
+		return
+	}
+	for _, cache := range a.caches {
+		if err := cache.reload(ctx); err != nil {
+			log.Printf("⚠️ Failed to reload %s, keeping the cached copy: %v", cache.name, err)
+		}
+	}
+	a.cachesLoadedAt = time.Now()
+}
+
+// Flush exports the spans buffered so far; the Lambda function calls it after each invocation,
+// before the process is frozen
+func (a *App) Flush(ctx context.Context) {
+	if err := a.flushTracing(ctx); err != nil {
+		log.Printf("⚠️ Failed to flush traces: %v", err)
+	}
+}
+
+// Sweep runs one pass of the periodic sweepers (date check-in prompts and escalations, account
+// purges). The server runs them on timers with Workers on; the Lambda function has no timers and
+// runs this from a scheduled event instead.
+func (a *App) Sweep(ctx context.Context) error {
+	if err := a.dateCheckIns.SweepDueCheckIns(ctx); err != nil {
+		return fmt.Errorf("date check-in sweep failed: %w", err)
+	}
+	if err := a.accountDeletion.SweepDeletedAccounts(ctx); err != nil {
+		return fmt.Errorf("account deletion sweep failed: %w", err)
+	}
+	return nil
+}
+
+// Shutdown flushes buffered spans
+func (a *App) Shutdown(ctx context.Context) {
+	if err := a.shutdownTracing(ctx); err != nil {
+		log.Printf("⚠️ Failed to flush traces: %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+// Command lambda runs the API as an AWS Lambda function behind an API Gateway HTTP API (payload
+// format 2.0), for low-traffic environments. It serves the same router as the long-running server;
+// background workers are off, so set JOB_QUEUE_URL to have jobs processed by a server instance (without
+// it jobs, webhook deliveries included, run inline before the response).
+//
+// The server's periodic sweepers (date check-ins, account purges) don't run between invocations, so
+// add an EventBridge schedule (e.g. rate(1 minute)) targeting the function; scheduled events run one
+// sweep instead of proxying a request.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+
+	"vibin_server/app"
+	"vibin_server/config"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/awslabs/aws-lambda-go-api-proxy/httpadapter"
+)
+
+var (
+	mu          sync.Mutex
+	application *app.App
+	adapter     *httpadapter.HandlerAdapterV2
+)
+
+func main() {
+	lambda.Start(handleEvent)
+}
+
+// handleEvent tells EventBridge scheduled events apart from API Gateway requests. Caches are
+// refreshed before and spans flushed after every invocation, since nothing runs while the process
+// is frozen in between.
+func handleEvent(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	current, proxy, err := getApp()
+	if err != nil {
+		log.Printf("❌ %v", err)
+		return events.APIGatewayV2HTTPResponse{StatusCode: http.StatusServiceUnavailable, Body: "Service unavailable"}, nil
+	}
+	current.RefreshCaches(ctx)
+	defer current.Flush(ctx)
+
+	var envelope struct {
+		Source     string `json:"source"`
+		DetailType string `json:"detail-type"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err == nil && envelope.Source == "aws.events" && envelope.DetailType == "Scheduled Event" {
+		// ✅ An error fails the invocation so it shows up in the function's error metrics; the next
+		// scheduled event retries
+		return nil, current.Sweep(ctx)
+	}
+
+	var event events.APIGatewayV2HTTPRequest
+	if err := json.Unmarshal(raw, &event); err != nil {
+		return nil, fmt.Errorf("unrecognised event: %w", err)
+	}
+	return proxy.ProxyWithContext(ctx, event)
+}
+
+// getApp builds the application on the first invocation rather than at cold start, and retries on
+// the next invocation if that fails (e.g. a transient AWS config error)
+func getApp() (*app.App, *httpadapter.HandlerAdapterV2, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if application != nil {
+		return application, adapter, nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+	built, err := app.New(cfg, app.Options{Workers: false})
+	if err != nil {
+		return nil, nil, err
+	}
+	application, adapter = built, httpadapter.NewV2(built.Handler)
+	return application, adapter, nil
+}
@@ -0,0 +1,81 @@
+// Command migrate copies the legacy Interactions, Message, GroupInteractions and
+// GroupMessages tables into the consolidated single table. With -backfill-active-groups it
+// instead adds the ActiveGroupsIndex keys to group memberships written before the index existed,
+// and with -backfill-match-activity it does the same for MatchActivityIndex on match records.
+//
+// Turn SINGLE_TABLE_DUAL_WRITE on in the servers before copying, and keep it on until cutover, so
+// writes made during and after the copy reach the consolidated table too: their workers mirror the
+// legacy tables' streams (NEW_AND_OLD_IMAGES) into it.
+//
+//	go run ./cmd/migrate -dry-run
+//	go run ./cmd/migrate -resume 'Message@eyJ...'
+//	go run ./cmd/migrate -backfill-active-groups
+//	go run ./cmd/migrate -backfill-match-activity
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"vibin_server/config"
+	"vibin_server/models"
+	"vibin_server/services"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report what would be written without writing")
+	resume := flag.String("resume", "", "continue a failed migration from the resume point it reported")
+	backfillActiveGroups := flag.Bool("backfill-active-groups", false, "add the active groups index keys to existing group memberships")
+	backfillMatchActivity := flag.Bool("backfill-match-activity", false, "add the match activity index keys to existing match records")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	models.ApplyTablePrefix(cfg.TablePrefix)
+
+	log.Println("Initializing DynamoDB client...")
+	dynamoService := &services.DynamoService{Client: services.InitializeDynamoDBClient(cfg.AWSRegion), Retry: services.DynamoRetryPolicy{
+		MaxAttempts: cfg.DynamoRetryMaxAttempts,
+		BaseDelay:   cfg.DynamoRetryBaseDelay,
+		MaxDelay:    cfg.DynamoRetryMaxDelay,
+	}}
+
+	if *backfillActiveGroups {
+		groupInteractionService := &services.GroupInteractionService{Dynamo: dynamoService}
+		updated, err := groupInteractionService.BackfillActiveGroupsIndex(context.Background())
+		if err != nil {
+			log.Fatalf("Backfill failed after %d records: %v", updated, err)
+		}
+		log.Printf("✅ Backfilled %d group memberships", updated)
+		return
+	}
+
+	if *backfillMatchActivity {
+		interactionService := &services.InteractionService{Dynamo: dynamoService}
+		updated, err := interactionService.BackfillMatchActivityIndex(context.Background())
+		if err != nil {
+			log.Fatalf("Backfill failed after %d records: %v", updated, err)
+		}
+		log.Printf("✅ Backfilled %d interaction records", updated)
+		return
+	}
+
+	singleTableService := &services.SingleTableService{Dynamo: dynamoService}
+
+	report, err := singleTableService.MigrateToSingleTable(context.Background(), *dryRun, *resume)
+	if err != nil && report != nil && report.Resume != "" {
+		log.Fatalf("Migration failed: %v (continue with -resume %s)", err, report.Resume)
+	}
+	if err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+}
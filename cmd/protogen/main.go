@@ -0,0 +1,43 @@
+// Command protogen writes the .proto file for the gRPC API, derived from the Go structs the
+// server exchanges, so other services can generate typed clients. Field numbers come from
+// grpcapi/fieldnumbers.json; after adding fields, record numbers for them first:
+//
+//	go run ./cmd/protogen -assign
+//	go run ./cmd/protogen > api/proto/vibin/v1/vibin.proto
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"vibin_server/grpcapi"
+)
+
+func main() {
+	assign := flag.Bool("assign", false, "record field numbers for new fields in the registry instead of printing the .proto")
+	registry := flag.String("registry", "grpcapi/fieldnumbers.json", "field number registry written by -assign")
+	flag.Parse()
+
+	if *assign {
+		numbers, err := grpcapi.AssignFieldNumbers()
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		out, err := numbers.JSON()
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		if err := os.WriteFile(*registry, out, 0o644); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		return
+	}
+
+	source, err := grpcapi.ProtoSource()
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	fmt.Print(source)
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"go/format"
+	"regexp"
+	"strings"
+)
+
+// pathParamPattern finds {name} segments in a path template
+var pathParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+// GenerateGo renders a Go client package for the spec
+func GenerateGo(spec *Spec, specPath, packageName string) ([]byte, error) {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "// Code generated by cmd/sdkgen from %s; DO NOT EDIT.\n\n", specPath)
+	fmt.Fprintf(&b, "// Package %s is a typed client for the %s (%s).\n", packageName, spec.Info.Title, spec.Info.Version)
+	fmt.Fprintf(&b, "package %s\n\n", packageName)
+	b.WriteString(`import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+`)
+
+	for _, name := range spec.SchemaNames() {
+		writeGoType(&b, name, spec.Components.Schemas[name])
+	}
+
+	b.WriteString(goClientRuntime)
+
+	for _, op := range spec.Operations() {
+		writeGoOperation(&b, op)
+	}
+
+	source, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return nil, fmt.Errorf("generated Go does not compile cleanly: %w", err)
+	}
+	return source, nil
+}
+
+// writeGoType emits a struct for an object schema
+func writeGoType(b *strings.Builder, name string, schema *Schema) {
+	if schema.Description != "" {
+		fmt.Fprintf(b, "// %s: %s\n", name, schema.Description)
+	} else {
+		fmt.Fprintf(b, "// %s is defined in the API spec\n", name)
+	}
+	fmt.Fprintf(b, "type %s struct {\n", name)
+	for _, property := range schema.SortedProperties() {
+		propSchema := schema.Properties[property]
+		tag := property
+		if !schema.IsRequired(property) {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(b, "\t%s %s `json:\"%s\"`", exportedName(property), goType(propSchema), tag)
+		if propSchema.Description != "" {
+			fmt.Fprintf(b, " // %s", propSchema.Description)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("}\n\n")
+}
+
+// goType maps a schema to a Go type; references are pointers so optional objects can be absent
+func goType(schema *Schema) string {
+	if schema.Ref != "" {
+		return "*" + schema.RefName()
+	}
+	switch schema.Type {
+	case "string":
+		return "string"
+	case "integer":
+		return "int"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + strings.TrimPrefix(goType(schema.Items), "*")
+	}
+	return "map[string]interface{}"
+}
+
+// goParamName turns a parameter name into a Go identifier (conversationId stays conversationId)
+func goParamName(name string) string {
+	exported := exportedName(name)
+	return strings.ToLower(exported[:1]) + exported[1:]
+}
+
+// writeGoOperation emits one client method
+func writeGoOperation(b *strings.Builder, op *Operation) {
+	methodName := exportedName(op.OperationID)
+
+	args := []string{"ctx context.Context"}
+	for _, p := range op.ParamsIn("path") {
+		args = append(args, goParamName(p.Name)+" string")
+	}
+	for _, p := range op.ParamsIn("query") {
+		args = append(args, goParamName(p.Name)+" string")
+	}
+	body := op.BodySchema()
+	if body != nil {
+		args = append(args, "body "+goType(body))
+	}
+
+	response := op.ResponseSchema()
+	returns := "error"
+	if response != nil {
+		returns = fmt.Sprintf("(%s, error)", goType(response))
+	}
+
+	if op.Summary != "" {
+		fmt.Fprintf(b, "// %s: %s\n", methodName, op.Summary)
+	} else {
+		fmt.Fprintf(b, "// %s calls %s %s\n", methodName, op.Method, op.Path)
+	}
+	fmt.Fprintf(b, "func (c *Client) %s(%s) %s {\n", methodName, strings.Join(args, ", "), returns)
+
+	path := pathParamPattern.ReplaceAllStringFunc(op.Path, func(segment string) string {
+		return `" + url.PathEscape(` + goParamName(segment[1:len(segment)-1]) + `) + "`
+	})
+	fmt.Fprintf(b, "\tpath := \"%s\"\n", path)
+
+	b.WriteString("\tquery := url.Values{}\n")
+	for _, p := range op.ParamsIn("query") {
+		name := goParamName(p.Name)
+		fmt.Fprintf(b, "\tif %s != \"\" {\n\t\tquery.Set(%q, %s)\n\t}\n", name, p.Name, name)
+	}
+
+	bodyArg := "nil"
+	if body != nil {
+		bodyArg = "body"
+	}
+	if response != nil {
+		// ✅ Objects come back as pointers; arrays and maps are returned as-is
+		responseType := goType(response)
+		result := "out"
+		if strings.HasPrefix(responseType, "*") {
+			responseType, result = responseType[1:], "&out"
+		}
+		fmt.Fprintf(b, "\tvar out %s\n", responseType)
+		fmt.Fprintf(b, "\tif err := c.do(ctx, %q, path, query, %s, &out); err != nil {\n\t\treturn nil, err\n\t}\n", op.Method, bodyArg)
+		fmt.Fprintf(b, "\treturn %s, nil\n}\n\n", result)
+	} else {
+		fmt.Fprintf(b, "\treturn c.do(ctx, %q, path, query, %s, nil)\n}\n\n", op.Method, bodyArg)
+	}
+}
+
+// goClientRuntime is the hand-written part of every generated Go client
+const goClientRuntime = `// Client calls the API over HTTP
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	Header     http.Header // Added to every request (e.g. Authorization)
+}
+
+// NewClient creates a client for the server at baseURL (e.g. "https://api.example.com")
+func NewClient(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimRight(baseURL, "/"), HTTPClient: http.DefaultClient, Header: http.Header{}}
+}
+
+// APIError is returned when the server answers with a non-2xx status
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api error %d: %s", e.StatusCode, e.Message)
+}
+
+// do sends one request and decodes a JSON response into out (when non-nil)
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body interface{}, out interface{}) error {
+	endpoint := c.BaseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	for key, values := range c.Header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(resp.Body)
+		return &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(message))}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+`
@@ -0,0 +1,48 @@
+// Command sdkgen generates the typed Go and TypeScript admin API clients from the OpenAPI spec.
+// CI runs it on every build and publishes sdk/ as an artifact.
+//
+//	go run ./cmd/sdkgen -spec api/admin.openapi.json -out sdk
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	specPath := flag.String("spec", "api/admin.openapi.json", "OpenAPI spec (JSON) to generate from")
+	outDir := flag.String("out", "sdk", "directory to write the clients into")
+	goPackage := flag.String("go-package", "adminclient", "package name of the Go client")
+	tsClass := flag.String("ts-class", "AdminClient", "class name of the TypeScript client")
+	flag.Parse()
+
+	spec, err := Load(*specPath)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	goSource, err := GenerateGo(spec, *specPath, *goPackage)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	goFile := filepath.Join(*outDir, "go", *goPackage, "client.go")
+	tsFile := filepath.Join(*outDir, "ts", "adminClient.ts")
+
+	write(goFile, goSource)
+	write(tsFile, GenerateTypeScript(spec, *specPath, *tsClass))
+
+	log.Printf("✅ Generated %d operations and %d types into %s", len(spec.Operations()), len(spec.SchemaNames()), *outDir)
+}
+
+// write creates parent directories and writes the file, exiting on failure
+func write(path string, contents []byte) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		log.Fatalf("❌ Failed to create %s: %v", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, contents, 0o644); err != nil {
+		log.Fatalf("❌ Failed to write %s: %v", path, err)
+	}
+	log.Printf("📝 Wrote %s", path)
+}
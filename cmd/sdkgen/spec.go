@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Spec is the subset of OpenAPI 3 that the generator understands: JSON bodies, path/query
+// string parameters, and object schemas built from primitives, arrays and $refs.
+type Spec struct {
+	Info struct {
+		Title   string `json:"title"`
+		Version string `json:"version"`
+	} `json:"info"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components struct {
+		Schemas map[string]*Schema `json:"schemas"`
+	} `json:"components"`
+}
+
+// Operation is one HTTP method on a path
+type Operation struct {
+	OperationID string      `json:"operationId"`
+	Summary     string      `json:"summary"`
+	Parameters  []Parameter `json:"parameters"`
+	RequestBody *struct {
+		Content map[string]struct {
+			Schema *Schema `json:"schema"`
+		} `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]struct {
+		Content map[string]struct {
+			Schema *Schema `json:"schema"`
+		} `json:"content"`
+	} `json:"responses"`
+
+	// Filled in by Load
+	Method string `json:"-"`
+	Path   string `json:"-"`
+}
+
+// Parameter is a path or query parameter (always a string on the wire)
+type Parameter struct {
+	Name        string `json:"name"`
+	In          string `json:"in"`
+	Required    bool   `json:"required"`
+	Description string `json:"description"`
+}
+
+// Schema is an object, array, primitive or reference
+type Schema struct {
+	Ref         string             `json:"$ref"`
+	Type        string             `json:"type"`
+	Description string             `json:"description"`
+	Items       *Schema            `json:"items"`
+	Properties  map[string]*Schema `json:"properties"`
+	Required    []string           `json:"required"`
+}
+
+// RefName returns the component name a $ref points to
+func (s *Schema) RefName() string {
+	return strings.TrimPrefix(s.Ref, "#/components/schemas/")
+}
+
+// IsRequired reports whether a property is listed as required
+func (s *Schema) IsRequired(property string) bool {
+	for _, name := range s.Required {
+		if name == property {
+			return true
+		}
+	}
+	return false
+}
+
+// SortedProperties returns property names in a stable order
+func (s *Schema) SortedProperties() []string {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load reads and validates a spec file
+func Load(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var spec Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	for path, methods := range spec.Paths {
+		for method, op := range methods {
+			if op.OperationID == "" {
+				return nil, fmt.Errorf("%s %s has no operationId", strings.ToUpper(method), path)
+			}
+			op.Method = strings.ToUpper(method)
+			op.Path = path
+		}
+	}
+	return &spec, nil
+}
+
+// SchemaNames returns component schema names in a stable order
+func (s *Spec) SchemaNames() []string {
+	names := make([]string, 0, len(s.Components.Schemas))
+	for name := range s.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Operations returns every operation ordered by operationId
+func (s *Spec) Operations() []*Operation {
+	var ops []*Operation
+	for _, methods := range s.Paths {
+		for _, op := range methods {
+			ops = append(ops, op)
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i].OperationID < ops[j].OperationID })
+	return ops
+}
+
+// BodySchema returns the JSON request body schema, or nil
+func (op *Operation) BodySchema() *Schema {
+	if op.RequestBody == nil {
+		return nil
+	}
+	return op.RequestBody.Content["application/json"].Schema
+}
+
+// ResponseSchema returns the JSON schema of the first 2xx response, or nil
+func (op *Operation) ResponseSchema() *Schema {
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		if strings.HasPrefix(code, "2") {
+			return op.Responses[code].Content["application/json"].Schema
+		}
+	}
+	return nil
+}
+
+// ParamsIn returns the parameters of one location ("path" or "query") in declaration order
+func (op *Operation) ParamsIn(location string) []Parameter {
+	var params []Parameter
+	for _, p := range op.Parameters {
+		if p.In == location {
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+// exportedName turns "getModerationRules" or "conversation_id" into "GetModerationRules"/"ConversationId"
+func exportedName(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if r == '_' || r == '-' {
+			upper = true
+			continue
+		}
+		if upper {
+			b.WriteString(strings.ToUpper(string(r)))
+			upper = false
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GenerateTypeScript renders a fetch-based TypeScript client for the spec
+func GenerateTypeScript(spec *Spec, specPath, className string) []byte {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "// Code generated by cmd/sdkgen from %s; DO NOT EDIT.\n", specPath)
+	fmt.Fprintf(&b, "// Typed client for the %s (%s).\n\n", spec.Info.Title, spec.Info.Version)
+
+	for _, name := range spec.SchemaNames() {
+		writeTSInterface(&b, name, spec.Components.Schemas[name])
+	}
+
+	b.WriteString(`/** Thrown when the server answers with a non-2xx status */
+export class ApiError extends Error {
+  constructor(public readonly status: number, message: string) {
+    super(` + "`api error ${status}: ${message}`" + `);
+    this.name = "ApiError";
+  }
+}
+
+`)
+
+	fmt.Fprintf(&b, "export class %s {\n", className)
+	b.WriteString(`  /**
+   * @param baseUrl server root, e.g. "https://api.example.com"
+   * @param headers added to every request (e.g. Authorization)
+   */
+  constructor(
+    private readonly baseUrl: string,
+    private readonly headers: Record<string, string> = {},
+    private readonly fetchImpl: typeof fetch = fetch,
+  ) {}
+
+`)
+	for _, op := range spec.Operations() {
+		writeTSOperation(&b, op)
+	}
+	b.WriteString(`  private async request<T>(method: string, path: string, query: Record<string, string | undefined>, body?: unknown): Promise<T> {
+    const params = new URLSearchParams();
+    for (const [key, value] of Object.entries(query)) {
+      if (value !== undefined && value !== "") params.set(key, value);
+    }
+    const search = params.toString();
+    const url = this.baseUrl.replace(/\/+$/, "") + path + (search ? "?" + search : "");
+
+    const headers: Record<string, string> = { ...this.headers };
+    if (body !== undefined) headers["Content-Type"] = "application/json";
+
+    const response = await this.fetchImpl(url, {
+      method,
+      headers,
+      body: body === undefined ? undefined : JSON.stringify(body),
+    });
+    if (!response.ok) {
+      throw new ApiError(response.status, (await response.text()).trim());
+    }
+    const text = await response.text();
+    return (text ? JSON.parse(text) : undefined) as T;
+  }
+}
+`)
+	return []byte(b.String())
+}
+
+// writeTSInterface emits an interface for an object schema
+func writeTSInterface(b *strings.Builder, name string, schema *Schema) {
+	if schema.Description != "" {
+		fmt.Fprintf(b, "/** %s */\n", schema.Description)
+	}
+	fmt.Fprintf(b, "export interface %s {\n", name)
+	for _, property := range schema.SortedProperties() {
+		propSchema := schema.Properties[property]
+		if propSchema.Description != "" {
+			fmt.Fprintf(b, "  /** %s */\n", propSchema.Description)
+		}
+		optional := "?"
+		if schema.IsRequired(property) {
+			optional = ""
+		}
+		fmt.Fprintf(b, "  %s%s: %s;\n", property, optional, tsType(propSchema))
+	}
+	b.WriteString("}\n\n")
+}
+
+// tsType maps a schema to a TypeScript type
+func tsType(schema *Schema) string {
+	if schema.Ref != "" {
+		return schema.RefName()
+	}
+	switch schema.Type {
+	case "string":
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "array":
+		return tsType(schema.Items) + "[]"
+	}
+	return "Record<string, unknown>"
+}
+
+// writeTSOperation emits one client method
+func writeTSOperation(b *strings.Builder, op *Operation) {
+	var args []string
+	for _, p := range op.ParamsIn("path") {
+		args = append(args, goParamName(p.Name)+": string")
+	}
+	for _, p := range op.ParamsIn("query") {
+		optional := "?"
+		if p.Required {
+			optional = ""
+		}
+		args = append(args, goParamName(p.Name)+optional+": string")
+	}
+	body := op.BodySchema()
+	if body != nil {
+		args = append(args, "body: "+tsType(body))
+	}
+
+	returns := "void"
+	if response := op.ResponseSchema(); response != nil {
+		returns = tsType(response)
+	}
+
+	if op.Summary != "" {
+		fmt.Fprintf(b, "  /** %s */\n", op.Summary)
+	}
+	fmt.Fprintf(b, "  %s(%s): Promise<%s> {\n", op.OperationID, strings.Join(args, ", "), returns)
+
+	path := pathParamPattern.ReplaceAllStringFunc(op.Path, func(segment string) string {
+		return "${encodeURIComponent(" + goParamName(segment[1:len(segment)-1]) + ")}"
+	})
+
+	query := "{}"
+	if params := op.ParamsIn("query"); len(params) > 0 {
+		var fields []string
+		for _, p := range params {
+			fields = append(fields, fmt.Sprintf("%q: %s", p.Name, goParamName(p.Name)))
+		}
+		query = "{ " + strings.Join(fields, ", ") + " }"
+	}
+
+	bodyArg := ""
+	if body != nil {
+		bodyArg = ", body"
+	}
+	fmt.Fprintf(b, "    return this.request<%s>(%q, `%s`, %s%s);\n  }\n\n", returns, op.Method, path, query, bodyArg)
+}
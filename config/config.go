@@ -0,0 +1,368 @@
+// Package config loads the server's settings from the environment once at startup.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tablePrefixPattern keeps prefixes within DynamoDB's table-name alphabet
+var tablePrefixPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]*$`)
+
+// Config is every environment setting the server reads; main builds services from it
+type Config struct {
+	Port         string // PORT (default 8080)
+	GRPCPort     string // GRPC_PORT; the internal gRPC API is off when empty
+	AWSRegion    string // AWS_REGION (required)
+	S3BucketName string // S3_BUCKET_NAME (required)
+	TablePrefix  string // TABLE_PREFIX, e.g. "staging-" (default none: production table names)
+
+	RequestTimeout time.Duration // REQUEST_TIMEOUT, e.g. "10s" (default 10s); deadline for each API request
+
+	CompressionMinBytes int // COMPRESSION_MIN_BYTES (default 1024, 0 = off); smaller responses are sent uncompressed
+
+	DynamoRetryMaxAttempts int           // DYNAMO_RETRY_MAX_ATTEMPTS (default 3, 1 disables app-level retries)
+	DynamoRetryBaseDelay   time.Duration // DYNAMO_RETRY_BASE_DELAY (default 50ms)
+	DynamoRetryMaxDelay    time.Duration // DYNAMO_RETRY_MAX_DELAY (default 1s)
+
+	KMSKeyID         string // KMS_KEY_ID; message/PII encryption is off when empty
+	PIIBlindIndexKey []byte // PII_BLIND_INDEX_KEY; required when KMS_KEY_ID is set
+
+	OTLPEndpoint string // OTEL_EXPORTER_OTLP_ENDPOINT, e.g. "http://collector:4318"; tracing is off when empty
+
+	JobQueueURL string // JOB_QUEUE_URL (SQS); background jobs run in-process when empty
+	JobWorkers  int    // JOB_WORKERS (default 2); queue pollers per instance
+
+	StreamConsumers bool // STREAM_CONSUMERS ("true" to maintain conversation summaries from the Messages/Interactions streams)
+
+	SingleTableDualWrite bool // SINGLE_TABLE_DUAL_WRITE ("true" to mirror the legacy tables' streams into VibinData until cutover; needs workers)
+
+	PhotoModeration bool // PHOTO_MODERATION ("true" to screen uploads and require one face in primary photos, via Rekognition)
+
+	SessionsRequired bool     // SESSIONS_REQUIRED ("true" to reject API requests without a valid session token; off while clients roll out sessions)
+	EmailFrom        string   // EMAIL_FROM (a verified SES identity); sign-in codes are emailed from it, or texted from TWILIO_SMS_FROM to accounts without an email
+	AdminUserHandles []string // ADMIN_USERHANDLES (comma-separated); users whose sessions may call /admin (the admin API is closed when empty)
+	CountryHeader    string   // COUNTRY_HEADER (default "CloudFront-Viewer-Country"); request header with the client's ISO country
+	ASNHeader        string   // ASN_HEADER; request header with the client's AS number, set by the edge (ASN blocks and throttling are off when empty)
+
+	RateLimitPerIP  int // RATE_LIMIT_PER_IP (default 600, 0 = off); API requests per minute from one IP, per instance
+	RateLimitPerASN int // RATE_LIMIT_PER_ASN (default 0 = off); API requests per minute from one ASN, per instance
+
+	SuggestionMinCompleteness int // SUGGESTION_MIN_COMPLETENESS (0-100, default 0 = off); less complete profiles are suggested last
+	SuggestionRepeatBatches   int // SUGGESTION_REPEAT_BATCHES (default 3, 0 = off); profiles served are skipped for this many refreshes
+
+	NewUserBoost float64 // NEW_USER_BOOST (default 2, 1 = off); profiles younger than 72 hours rank as if this many times closer
+
+	GeocodingPlaceIndex string // GEOCODING_PLACE_INDEX (Amazon Location place index); city/region browsing is off when empty
+
+	// Retention periods enforced with DynamoDB TTL on expiresAt; 0 keeps the data indefinitely
+	RetentionDeclinedInteractionDays int // RETENTION_DECLINED_INTERACTION_DAYS (default 90)
+	RetentionUnmatchedMessageDays    int // RETENTION_UNMATCHED_MESSAGE_DAYS (default 30)
+
+	Stripe  StripeConfig
+	Spotify SpotifyConfig
+	LLM     LLMConfig
+	RTC     RTCConfig
+	Twilio  TwilioConfig
+	Captcha CaptchaConfig
+}
+
+// StripeConfig holds the billing settings; billing stays disabled when all are empty
+type StripeConfig struct {
+	SecretKey      string // STRIPE_SECRET_KEY
+	WebhookSecret  string // STRIPE_WEBHOOK_SECRET
+	PremiumPriceID string // STRIPE_PREMIUM_PRICE_ID
+}
+
+// SpotifyConfig holds the Spotify app credentials; the integration stays disabled when all are empty
+type SpotifyConfig struct {
+	ClientID     string // SPOTIFY_CLIENT_ID
+	ClientSecret string // SPOTIFY_CLIENT_SECRET
+	RedirectURI  string // SPOTIFY_REDIRECT_URI; must match the one the app sends users through
+}
+
+// LLMConfig points at an OpenAI-compatible chat completions API; generated features stay
+// disabled when all are empty
+type LLMConfig struct {
+	Endpoint string // LLM_ENDPOINT, e.g. "https://api.openai.com/v1/chat/completions"
+	APIKey   string // LLM_API_KEY; optional for self-hosted endpoints
+	Model    string // LLM_MODEL
+}
+
+// RTCConfig selects the provider call tokens are minted for; calls stay disabled without RTC_PROVIDER
+type RTCConfig struct {
+	Provider            string // RTC_PROVIDER: "agora" or "twilio" (which uses TwilioConfig)
+	AgoraAppID          string // AGORA_APP_ID
+	AgoraAppCertificate string // AGORA_APP_CERTIFICATE
+}
+
+// CaptchaConfig selects the CAPTCHA provider whose tokens are verified; challenges stay off without
+// CAPTCHA_PROVIDER
+type CaptchaConfig struct {
+	Provider string   // CAPTCHA_PROVIDER: "hcaptcha" or "turnstile"
+	Secret   string   // CAPTCHA_SECRET
+	Actions  []string // CAPTCHA_ACTIONS (default "signup,ping"): which actions need a challenge
+}
+
+// TwilioConfig holds the Twilio API key used for video tokens and SMS; SMS (date check-in
+// escalation) stays disabled without a sender number
+type TwilioConfig struct {
+	AccountSID   string // TWILIO_ACCOUNT_SID
+	APIKeySID    string // TWILIO_API_KEY_SID
+	APIKeySecret string // TWILIO_API_KEY_SECRET
+	SMSFrom      string // TWILIO_SMS_FROM, an E.164 number or messaging service SID
+}
+
+// Load reads and validates the environment, reporting every problem at once
+func Load() (*Config, error) {
+	cfg := &Config{
+		Port:             getenv("PORT", "8080"),
+		GRPCPort:         getenv("GRPC_PORT", ""),
+		AWSRegion:        getenv("AWS_REGION", ""),
+		S3BucketName:     getenv("S3_BUCKET_NAME", ""),
+		TablePrefix:      getenv("TABLE_PREFIX", ""),
+		KMSKeyID:         getenv("KMS_KEY_ID", ""),
+		PIIBlindIndexKey: []byte(getenv("PII_BLIND_INDEX_KEY", "")),
+		OTLPEndpoint:     getenv("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+		JobQueueURL:      getenv("JOB_QUEUE_URL", ""),
+		Stripe: StripeConfig{
+			SecretKey:      getenv("STRIPE_SECRET_KEY", ""),
+			WebhookSecret:  getenv("STRIPE_WEBHOOK_SECRET", ""),
+			PremiumPriceID: getenv("STRIPE_PREMIUM_PRICE_ID", ""),
+		},
+		Spotify: SpotifyConfig{
+			ClientID:     getenv("SPOTIFY_CLIENT_ID", ""),
+			ClientSecret: getenv("SPOTIFY_CLIENT_SECRET", ""),
+			RedirectURI:  getenv("SPOTIFY_REDIRECT_URI", ""),
+		},
+		LLM: LLMConfig{
+			Endpoint: getenv("LLM_ENDPOINT", ""),
+			APIKey:   getenv("LLM_API_KEY", ""),
+			Model:    getenv("LLM_MODEL", ""),
+		},
+		RTC: RTCConfig{
+			Provider:            getenv("RTC_PROVIDER", ""),
+			AgoraAppID:          getenv("AGORA_APP_ID", ""),
+			AgoraAppCertificate: getenv("AGORA_APP_CERTIFICATE", ""),
+		},
+		Twilio: TwilioConfig{
+			AccountSID:   getenv("TWILIO_ACCOUNT_SID", ""),
+			APIKeySID:    getenv("TWILIO_API_KEY_SID", ""),
+			APIKeySecret: getenv("TWILIO_API_KEY_SECRET", ""),
+			SMSFrom:      getenv("TWILIO_SMS_FROM", ""),
+		},
+		Captcha: CaptchaConfig{
+			Provider: getenv("CAPTCHA_PROVIDER", ""),
+			Secret:   getenv("CAPTCHA_SECRET", ""),
+			Actions:  strings.Split(getenv("CAPTCHA_ACTIONS", "signup,ping"), ","),
+		},
+	}
+	cfg.GeocodingPlaceIndex = getenv("GEOCODING_PLACE_INDEX", "")
+	cfg.EmailFrom = getenv("EMAIL_FROM", "")
+	cfg.AdminUserHandles = splitList(getenv("ADMIN_USERHANDLES", ""))
+	cfg.CountryHeader = getenv("COUNTRY_HEADER", "CloudFront-Viewer-Country")
+	cfg.ASNHeader = getenv("ASN_HEADER", "")
+	var problems []string
+	cfg.RequestTimeout = parseDuration("REQUEST_TIMEOUT", "10s", &problems)
+	cfg.CompressionMinBytes = parseInt("COMPRESSION_MIN_BYTES", "1024", &problems)
+	cfg.DynamoRetryBaseDelay = parseDuration("DYNAMO_RETRY_BASE_DELAY", "50ms", &problems)
+	cfg.DynamoRetryMaxDelay = parseDuration("DYNAMO_RETRY_MAX_DELAY", "1s", &problems)
+	cfg.DynamoRetryMaxAttempts = parseInt("DYNAMO_RETRY_MAX_ATTEMPTS", "3", &problems)
+	cfg.JobWorkers = parseInt("JOB_WORKERS", "2", &problems)
+	cfg.StreamConsumers = parseBool("STREAM_CONSUMERS", "false", &problems)
+	cfg.SingleTableDualWrite = parseBool("SINGLE_TABLE_DUAL_WRITE", "false", &problems)
+	cfg.PhotoModeration = parseBool("PHOTO_MODERATION", "false", &problems)
+	cfg.SessionsRequired = parseBool("SESSIONS_REQUIRED", "false", &problems)
+	cfg.RateLimitPerIP = parseInt("RATE_LIMIT_PER_IP", "600", &problems)
+	cfg.RateLimitPerASN = parseInt("RATE_LIMIT_PER_ASN", "0", &problems)
+	cfg.SuggestionMinCompleteness = parseInt("SUGGESTION_MIN_COMPLETENESS", "0", &problems)
+	cfg.SuggestionRepeatBatches = parseInt("SUGGESTION_REPEAT_BATCHES", "3", &problems)
+	cfg.NewUserBoost = parseFloat("NEW_USER_BOOST", "2", &problems)
+	cfg.RetentionDeclinedInteractionDays = parseInt("RETENTION_DECLINED_INTERACTION_DAYS", "90", &problems)
+	cfg.RetentionUnmatchedMessageDays = parseInt("RETENTION_UNMATCHED_MESSAGE_DAYS", "30", &problems)
+	if len(problems) > 0 {
+		return nil, errors.New("invalid configuration: " + strings.Join(problems, "; "))
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate checks required values and settings that only make sense together
+func (c *Config) Validate() error {
+	var problems []string
+	if port, err := strconv.Atoi(c.Port); err != nil || port <= 0 || port > 65535 {
+		problems = append(problems, fmt.Sprintf("PORT %q is not a valid port", c.Port))
+	}
+	if port, err := strconv.Atoi(c.GRPCPort); c.GRPCPort != "" && (err != nil || port <= 0 || port > 65535 || c.GRPCPort == c.Port) {
+		problems = append(problems, fmt.Sprintf("GRPC_PORT %q is not a valid port different from PORT", c.GRPCPort))
+	}
+	if c.AWSRegion == "" {
+		problems = append(problems, "AWS_REGION is required")
+	}
+	if c.S3BucketName == "" {
+		problems = append(problems, "S3_BUCKET_NAME is required")
+	}
+	if c.RequestTimeout <= 0 {
+		problems = append(problems, "REQUEST_TIMEOUT must be positive")
+	}
+	if c.DynamoRetryMaxAttempts < 1 || c.DynamoRetryBaseDelay <= 0 || c.DynamoRetryMaxDelay < c.DynamoRetryBaseDelay {
+		problems = append(problems, "DYNAMO_RETRY_MAX_ATTEMPTS must be at least 1 and 0 < DYNAMO_RETRY_BASE_DELAY <= DYNAMO_RETRY_MAX_DELAY")
+	}
+	if !tablePrefixPattern.MatchString(c.TablePrefix) {
+		problems = append(problems, "TABLE_PREFIX may only contain letters, digits, '_', '-' and '.'")
+	}
+	if c.KMSKeyID != "" && len(c.PIIBlindIndexKey) == 0 {
+		problems = append(problems, "PII_BLIND_INDEX_KEY is required when KMS_KEY_ID is set")
+	}
+	if c.JobQueueURL != "" && c.JobWorkers < 1 {
+		problems = append(problems, "JOB_WORKERS must be at least 1 when JOB_QUEUE_URL is set")
+	}
+	if c.SuggestionMinCompleteness < 0 || c.SuggestionMinCompleteness > 100 {
+		problems = append(problems, "SUGGESTION_MIN_COMPLETENESS must be between 0 and 100")
+	}
+	if c.SuggestionRepeatBatches < 0 {
+		problems = append(problems, "SUGGESTION_REPEAT_BATCHES must not be negative")
+	}
+	if c.CompressionMinBytes < 0 {
+		problems = append(problems, "COMPRESSION_MIN_BYTES must not be negative")
+	}
+	if c.RateLimitPerIP < 0 || c.RateLimitPerASN < 0 {
+		problems = append(problems, "RATE_LIMIT_PER_IP and RATE_LIMIT_PER_ASN must not be negative")
+	}
+	if c.NewUserBoost < 1 {
+		problems = append(problems, "NEW_USER_BOOST must be at least 1")
+	}
+	if c.RetentionDeclinedInteractionDays < 0 || c.RetentionUnmatchedMessageDays < 0 {
+		problems = append(problems, "RETENTION_DECLINED_INTERACTION_DAYS and RETENTION_UNMATCHED_MESSAGE_DAYS must not be negative")
+	}
+	if c.Stripe.configured() && (c.Stripe.SecretKey == "" || c.Stripe.WebhookSecret == "" || c.Stripe.PremiumPriceID == "") {
+		problems = append(problems, "STRIPE_SECRET_KEY, STRIPE_WEBHOOK_SECRET and STRIPE_PREMIUM_PRICE_ID must be set together")
+	}
+	if c.Spotify.configured() && (c.Spotify.ClientID == "" || c.Spotify.ClientSecret == "" || c.Spotify.RedirectURI == "") {
+		problems = append(problems, "SPOTIFY_CLIENT_ID, SPOTIFY_CLIENT_SECRET and SPOTIFY_REDIRECT_URI must be set together")
+	}
+	if c.LLM.configured() && (c.LLM.Endpoint == "" || c.LLM.Model == "") {
+		problems = append(problems, "LLM_ENDPOINT and LLM_MODEL must be set together")
+	}
+	switch c.RTC.Provider {
+	case "":
+	case "agora":
+		if c.RTC.AgoraAppID == "" || c.RTC.AgoraAppCertificate == "" {
+			problems = append(problems, "RTC_PROVIDER=agora needs AGORA_APP_ID and AGORA_APP_CERTIFICATE")
+		}
+	case "twilio":
+		if !c.Twilio.configured() {
+			problems = append(problems, "RTC_PROVIDER=twilio needs TWILIO_ACCOUNT_SID, TWILIO_API_KEY_SID and TWILIO_API_KEY_SECRET")
+		}
+	default:
+		problems = append(problems, "RTC_PROVIDER must be agora or twilio")
+	}
+	if c.SessionsRequired && c.EmailFrom == "" && c.Twilio.SMSFrom == "" {
+		problems = append(problems, "SESSIONS_REQUIRED needs EMAIL_FROM or TWILIO_SMS_FROM to send sign-in codes")
+	}
+	if c.Twilio.SMSFrom != "" && !c.Twilio.configured() {
+		problems = append(problems, "TWILIO_SMS_FROM needs TWILIO_ACCOUNT_SID, TWILIO_API_KEY_SID and TWILIO_API_KEY_SECRET")
+	}
+	switch c.Captcha.Provider {
+	case "":
+	case "hcaptcha", "turnstile":
+		if c.Captcha.Secret == "" {
+			problems = append(problems, "CAPTCHA_PROVIDER needs CAPTCHA_SECRET")
+		}
+		for _, action := range c.Captcha.Actions {
+			if action != "signup" && action != "ping" {
+				problems = append(problems, fmt.Sprintf("CAPTCHA_ACTIONS entry %q must be signup or ping", action))
+			}
+		}
+	default:
+		problems = append(problems, "CAPTCHA_PROVIDER must be hcaptcha or turnstile")
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid configuration: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// configured reports whether any Stripe setting is present
+func (s StripeConfig) configured() bool {
+	return s.SecretKey != "" || s.WebhookSecret != "" || s.PremiumPriceID != ""
+}
+
+// configured reports whether any Spotify setting is present
+func (s SpotifyConfig) configured() bool {
+	return s.ClientID != "" || s.ClientSecret != "" || s.RedirectURI != ""
+}
+
+// configured reports whether any LLM setting is present
+func (l LLMConfig) configured() bool {
+	return l.Endpoint != "" || l.APIKey != "" || l.Model != ""
+}
+
+// configured reports whether the Twilio API key is complete
+func (t TwilioConfig) configured() bool {
+	return t.AccountSID != "" && t.APIKeySID != "" && t.APIKeySecret != ""
+}
+
+// parseDuration reads a duration variable, recording a problem if it doesn't parse
+func parseDuration(key, fallback string, problems *[]string) time.Duration {
+	value, err := time.ParseDuration(getenv(key, fallback))
+	if err != nil {
+		*problems = append(*problems, fmt.Sprintf("%s: %v", key, err))
+	}
+	return value
+}
+
+// parseInt reads an integer variable, recording a problem if it doesn't parse
+func parseInt(key, fallback string, problems *[]string) int {
+	value, err := strconv.Atoi(getenv(key, fallback))
+	if err != nil {
+		*problems = append(*problems, fmt.Sprintf("%s must be an integer", key))
+	}
+	return value
+}
+
+// parseFloat reads a decimal variable, recording a problem if it doesn't parse
+func parseFloat(key, fallback string, problems *[]string) float64 {
+	value, err := strconv.ParseFloat(getenv(key, fallback), 64)
+	if err != nil {
+		*problems = append(*problems, fmt.Sprintf("%s must be a number", key))
+	}
+	return value
+}
+
+// parseBool reads a boolean variable ("true"/"false", "1"/"0"), recording a problem if it doesn't parse
+func parseBool(key, fallback string, problems *[]string) bool {
+	value, err := strconv.ParseBool(getenv(key, fallback))
+	if err != nil {
+		*problems = append(*problems, fmt.Sprintf("%s must be true or false", key))
+	}
+	return value
+}
+
+// splitList splits a comma-separated variable, dropping blank entries
+func splitList(value string) []string {
+	var entries []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// getenv returns the trimmed variable, or fallback when unset or blank
+func getenv(key, fallback string) string {
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+		return value
+	}
+	return fallback
+}
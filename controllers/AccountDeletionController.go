@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"vibin_server/helpers"
+	"vibin_server/services"
+)
+
+// AccountDeletionController deletes accounts with a grace period and restores them
+type AccountDeletionController struct {
+	AccountDeletionService *services.AccountDeletionService
+}
+
+// NewAccountDeletionController creates a new instance of AccountDeletionController
+func NewAccountDeletionController(service *services.AccountDeletionService) *AccountDeletionController {
+	return &AccountDeletionController{AccountDeletionService: service}
+}
+
+// DeleteAccount hides the account immediately and purges it after the grace period
+func (c *AccountDeletionController) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	if userHandle == "" {
+		http.Error(w, "Missing required parameter: userhandle", http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	deletion, err := c.AccountDeletionService.DeleteAccount(r.Context(), userHandle)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, deletion)
+}
+
+// RestoreAccount cancels a pending deletion
+func (c *AccountDeletionController) RestoreAccount(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserHandle string `json:"userhandle"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Handle("userhandle", request.UserHandle)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	restored, err := c.AccountDeletionService.RestoreAccount(r.Context(), request.UserHandle)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, restored)
+}
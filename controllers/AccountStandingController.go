@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"vibin_server/helpers"
+	"vibin_server/models"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+// ✅ Account action history page size defaults and caps
+const (
+	defaultAccountActionsPageSize = 50
+	maxAccountActionsPageSize     = 200
+)
+
+// AccountStandingController handles admin warnings, suspensions and bans, and the user's view of them
+type AccountStandingController struct {
+	AccountStandingService *services.AccountStandingService
+}
+
+// NewAccountStandingController creates a new instance of AccountStandingController
+func NewAccountStandingController(service *services.AccountStandingService) *AccountStandingController {
+	return &AccountStandingController{AccountStandingService: service}
+}
+
+// GetStanding returns the user's account status and moderation history (?userhandle=)
+func (c *AccountStandingController) GetStanding(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	var v helpers.Validator
+	v.Handle("userhandle", userHandle)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	standing, err := c.AccountStandingService.GetStanding(r.Context(), userHandle)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, standing)
+}
+
+// TakeAction warns, suspends, bans or reinstates a user (admin)
+func (c *AccountStandingController) TakeAction(w http.ResponseWriter, r *http.Request) {
+	userHandle := mux.Vars(r)["userhandle"]
+	var request models.AccountActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Handle("userhandle", userHandle)
+	v.OneOf("type", request.Type, models.AccountActionTypes...)
+	v.Required("reason", request.Reason)
+	v.MaxLength("reason", request.Reason, models.MaxAccountActionReasonLength)
+	v.Check(request.Type != models.AccountActionSuspension || request.Until != "", "until", "is required for a suspension")
+	v.Check(request.Type == models.AccountActionSuspension || request.Until == "", "until", "is only allowed for a suspension")
+	if v.WriteErrors(w) {
+		return
+	}
+	request.IssuedBy = helpers.AdminHandle(r)
+
+	action, err := c.AccountStandingService.TakeAction(r.Context(), userHandle, request)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusCreated, action)
+}
+
+// ListActions returns the user's moderation history, newest first (?limit=&cursor=, admin)
+func (c *AccountStandingController) ListActions(w http.ResponseWriter, r *http.Request) {
+	userHandle := mux.Vars(r)["userhandle"]
+	var v helpers.Validator
+	v.Handle("userhandle", userHandle)
+	if v.WriteErrors(w) {
+		return
+	}
+
+	limit := helpers.PageLimit(r, defaultAccountActionsPageSize, maxAccountActionsPageSize)
+	actions, nextCursor, err := c.AccountStandingService.ListActions(r.Context(), userHandle, int32(limit), r.URL.Query().Get("cursor"))
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.SetNextCursor(w, nextCursor)
+	helpers.WriteJSONResponse(w, http.StatusOK, actions)
+}
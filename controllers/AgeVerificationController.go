@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"vibin_server/helpers"
+	"vibin_server/models"
+	"vibin_server/services"
+)
+
+// ✅ Age verification queue page size defaults and caps
+const (
+	defaultAgeVerificationsPageSize = 50
+	maxAgeVerificationsPageSize     = 200
+	maxDisputeReasonLength          = 500
+)
+
+// AgeVerificationController handles age disputes: users submit an ID document, admins review it
+type AgeVerificationController struct {
+	AgeVerificationService *services.AgeVerificationService
+}
+
+// NewAgeVerificationController creates a new instance of AgeVerificationController
+func NewAgeVerificationController(service *services.AgeVerificationService) *AgeVerificationController {
+	return &AgeVerificationController{AgeVerificationService: service}
+}
+
+// GetVerification returns the user's age dispute and its status (?userhandle=)
+func (c *AgeVerificationController) GetVerification(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	if userHandle == "" {
+		http.Error(w, "Missing required parameter: userhandle", http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	verification, err := c.AgeVerificationService.GetVerification(r.Context(), userHandle)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, verification)
+}
+
+// SubmitDocument queues an uploaded ID document for review of a disputed age
+func (c *AgeVerificationController) SubmitDocument(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserHandle  string `json:"userhandle"`
+		DocumentKey string `json:"documentKey"` // Key from a presigned image upload
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Handle("userhandle", request.UserHandle)
+	v.Required("documentKey", request.DocumentKey)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	verification, err := c.AgeVerificationService.SubmitDocument(r.Context(), request.UserHandle, request.DocumentKey)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, verification)
+}
+
+// ListVerifications returns age verifications, oldest first (?status=disputed|pending|approved|rejected&limit=&cursor=, admin)
+func (c *AgeVerificationController) ListVerifications(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = models.AgeVerificationPending
+	}
+	var v helpers.Validator
+	v.OneOf("status", status, models.AgeVerificationDisputed, models.AgeVerificationPending, models.AgeVerificationApproved, models.AgeVerificationRejected)
+	if v.WriteErrors(w) {
+		return
+	}
+
+	limit := helpers.PageLimit(r, defaultAgeVerificationsPageSize, maxAgeVerificationsPageSize)
+	verifications, nextCursor, err := c.AgeVerificationService.ListVerifications(r.Context(), status, int32(limit), r.URL.Query().Get("cursor"))
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.SetNextCursor(w, nextCursor)
+	helpers.WriteJSONResponse(w, http.StatusOK, verifications)
+}
+
+// DisputeAge hides a profile reported as underage until the user's ID document is reviewed (admin)
+func (c *AgeVerificationController) DisputeAge(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserHandle string `json:"userhandle"`
+		Reason     string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Handle("userhandle", request.UserHandle)
+	v.Required("reason", request.Reason)
+	v.MaxLength("reason", request.Reason, maxDisputeReasonLength)
+	if v.WriteErrors(w) {
+		return
+	}
+
+	verification, err := c.AgeVerificationService.DisputeAge(r.Context(), request.UserHandle, request.Reason, helpers.AdminHandle(r))
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusCreated, verification)
+}
+
+// ResolveVerification approves (optionally correcting the date of birth) or rejects a submitted document (admin)
+func (c *AgeVerificationController) ResolveVerification(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserHandle string `json:"userhandle"`
+		Decision   string `json:"decision"`      // "approved" or "rejected"
+		DOB        string `json:"dob,omitempty"` // Date of birth read from the document, when it differs from the profile
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Handle("userhandle", request.UserHandle)
+	v.OneOf("decision", request.Decision, models.AgeVerificationApproved, models.AgeVerificationRejected)
+	if request.DOB != "" {
+		v.Check(request.Decision == models.AgeVerificationApproved, "dob", "is only set when approving")
+		v.Adult("dob", request.DOB)
+	}
+	if v.WriteErrors(w) {
+		return
+	}
+
+	verification, err := c.AgeVerificationService.ResolveVerification(r.Context(), request.UserHandle, request.Decision, helpers.AdminHandle(r), request.DOB)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, verification)
+}
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+	"vibin_server/helpers"
+	"vibin_server/services"
+	"vibin_server/utils"
+)
+
+// AnalyticsController exposes the internal funnel counts API
+type AnalyticsController struct {
+	AnalyticsService *services.AnalyticsService
+}
+
+// NewAnalyticsController creates a new instance of AnalyticsController
+func NewAnalyticsController(service *services.AnalyticsService) *AnalyticsController {
+	return &AnalyticsController{AnalyticsService: service}
+}
+
+// GetEventCounts returns daily and total counts per event type (admin)
+// Query: from, to (YYYY-MM-DD, default last 7 days), events (comma-separated, default all)
+func (c *AnalyticsController) GetEventCounts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	to := time.Now().UTC()
+	if value := query.Get("to"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			http.Error(w, "Invalid to date (expected YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		to = parsed
+	}
+	from := to.AddDate(0, 0, -6)
+	if value := query.Get("from"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			http.Error(w, "Invalid from date (expected YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		from = parsed
+	}
+
+	var eventTypes []string
+	if value := query.Get("events"); value != "" {
+		for _, eventType := range strings.Split(value, ",") {
+			eventType = strings.TrimSpace(eventType)
+			if !services.IsAnalyticsEventType(eventType) {
+				http.Error(w, "Unknown event type: "+eventType, http.StatusBadRequest)
+				return
+			}
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+
+	counts, err := c.AnalyticsService.CountEvents(r.Context(), eventTypes, from, to)
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Failed to count analytics events: %v", err)
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, counts)
+}
@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"net/http"
+	"vibin_server/helpers"
+	"vibin_server/services"
+	"vibin_server/utils"
+)
+
+// BadgeController serves the aggregated tab badge counts
+type BadgeController struct {
+	BadgeService *services.BadgeService
+}
+
+// NewBadgeController creates a new instance of BadgeController
+func NewBadgeController(service *services.BadgeService) *BadgeController {
+	return &BadgeController{BadgeService: service}
+}
+
+// GetBadges returns the user's unread message, new like, pending ping and pending group approval counts
+func (c *BadgeController) GetBadges(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	if userHandle == "" {
+		http.Error(w, "Missing required parameter: userhandle", http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	badges, err := c.BadgeService.GetBadgeCounts(r.Context(), userHandle)
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Failed to count badges for %s: %v", userHandle, err)
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, badges)
+}
@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"vibin_server/helpers"
+	"vibin_server/services"
+	"vibin_server/utils"
+)
+
+// maxWebhookBodyBytes caps the size of a Stripe webhook payload
+const maxWebhookBodyBytes = 64 << 10
+
+// BillingController exposes premium checkout, subscription status and the Stripe webhook
+type BillingController struct {
+	BillingService *services.BillingService
+}
+
+// NewBillingController creates a new instance of BillingController
+func NewBillingController(service *services.BillingService) *BillingController {
+	return &BillingController{BillingService: service}
+}
+
+// CreateCheckoutSession returns a Stripe Checkout URL for the premium plan
+func (c *BillingController) CreateCheckoutSession(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserHandle string `json:"userHandle"`
+		SuccessURL string `json:"successUrl"`
+		CancelURL  string `json:"cancelUrl"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if request.UserHandle == "" || request.SuccessURL == "" || request.CancelURL == "" {
+		http.Error(w, "Missing required fields: userHandle, successUrl, cancelUrl", http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	checkoutURL, err := c.BillingService.CreateCheckoutSession(r.Context(), request.UserHandle, request.SuccessURL, request.CancelURL)
+	if errors.Is(err, services.ErrBillingNotConfigured) {
+		http.Error(w, "Billing is not available", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Failed to create checkout session for %s: %v", request.UserHandle, err)
+		http.Error(w, "Failed to start checkout", http.StatusBadGateway)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, map[string]string{"checkoutUrl": checkoutURL})
+}
+
+// GetSubscription returns the user's plan, status and entitlements (?userhandle=)
+func (c *BillingController) GetSubscription(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	if userHandle == "" {
+		http.Error(w, "Missing userhandle parameter", http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	subscription, err := c.BillingService.GetSubscription(r.Context(), userHandle)
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Failed to fetch subscription for %s: %v", userHandle, err)
+		http.Error(w, "Failed to fetch subscription", http.StatusInternalServerError)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, subscription)
+}
+
+// HandleWebhook receives Stripe events; the raw body is needed to verify the signature
+func (c *BillingController) HandleWebhook(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodyBytes))
+	if err != nil {
+		http.Error(w, "Invalid webhook payload", http.StatusBadRequest)
+		return
+	}
+
+	err = c.BillingService.HandleWebhook(r.Context(), payload, r.Header.Get("Stripe-Signature"))
+	if errors.Is(err, services.ErrInvalidWebhookSignature) {
+		http.Error(w, "Invalid signature", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, services.ErrBillingNotConfigured) {
+		http.Error(w, "Billing is not available", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		// ✅ Non-2xx makes Stripe retry the event later
+		utils.Logf(r.Context(), "❌ Failed to process Stripe webhook: %v", err)
+		http.Error(w, "Failed to process webhook", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+	"vibin_server/helpers"
+	"vibin_server/models"
+	"vibin_server/services"
+)
+
+// BioSuggestionController drafts profile bios
+type BioSuggestionController struct {
+	BioSuggestionService *services.BioSuggestionService
+}
+
+// NewBioSuggestionController creates a new instance of BioSuggestionController
+func NewBioSuggestionController(service *services.BioSuggestionService) *BioSuggestionController {
+	return &BioSuggestionController{BioSuggestionService: service}
+}
+
+// SuggestBios returns generated bio drafts for the given interests and keywords
+func (c *BioSuggestionController) SuggestBios(w http.ResponseWriter, r *http.Request) {
+	var request models.BioSuggestionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	terms := append(append([]string{}, request.Interests...), request.Keywords...)
+	var v helpers.Validator
+	v.Handle("userhandle", request.UserHandle)
+	v.Check(len(terms) > 0, "keywords", "give at least one interest or keyword")
+	v.MaxItems("keywords", len(terms), models.MaxBioSuggestionTerms)
+	for _, term := range terms {
+		if term == "" || len([]rune(term)) > models.MaxBioSuggestionTermLen {
+			v.Check(false, "keywords", fmt.Sprintf("interests and keywords must be 1 to %d characters", models.MaxBioSuggestionTermLen))
+			break
+		}
+	}
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	suggestions, err := c.BioSuggestionService.SuggestBios(r.Context(), request)
+	if errors.Is(err, services.ErrBioSuggestionsUnavailable) {
+		http.Error(w, "Bio suggestions are not available", http.StatusServiceUnavailable)
+		return
+	}
+	var quotaErr *services.QuotaError
+	if errors.As(err, &quotaErr) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(quotaErr.RetryAt).Seconds())+1))
+		helpers.WriteJSONResponse(w, http.StatusTooManyRequests, quotaErr)
+		return
+	}
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, suggestions)
+}
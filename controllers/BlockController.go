@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"vibin_server/helpers"
+	"vibin_server/services"
+)
+
+// BlockController lets users block and report each other
+type BlockController struct {
+	BlockService *services.BlockService
+}
+
+// NewBlockController creates a new instance of BlockController
+func NewBlockController(service *services.BlockService) *BlockController {
+	return &BlockController{BlockService: service}
+}
+
+// ListBlocks returns the users the user blocked or reported (?userhandle=)
+func (c *BlockController) ListBlocks(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	if userHandle == "" {
+		http.Error(w, "Missing required parameter: userhandle", http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	blocks, err := c.BlockService.ListBlocks(r.Context(), userHandle)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, blocks)
+}
+
+// BlockUser hides another user from every feed, both ways
+func (c *BlockController) BlockUser(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserHandle    string `json:"userhandle"`
+		BlockedHandle string `json:"blockedHandle"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Handle("userhandle", request.UserHandle)
+	v.Handle("blockedHandle", request.BlockedHandle)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	block, err := c.BlockService.BlockUser(r.Context(), request.UserHandle, request.BlockedHandle)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusCreated, block)
+}
+
+// Unblock removes a block or report (?userhandle=&blockedHandle=)
+func (c *BlockController) Unblock(w http.ResponseWriter, r *http.Request) {
+	var v helpers.Validator
+	v.Handle("userhandle", r.URL.Query().Get("userhandle"))
+	v.Handle("blockedHandle", r.URL.Query().Get("blockedHandle"))
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, r.URL.Query().Get("userhandle")) {
+		return
+	}
+
+	if err := c.BlockService.Unblock(r.Context(), r.URL.Query().Get("userhandle"), r.URL.Query().Get("blockedHandle")); err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"vibin_server/helpers"
+	"vibin_server/services"
+)
+
+// ✅ Call log page size defaults and caps
+const (
+	defaultCallLogPageSize = 50
+	maxCallLogPageSize     = 200
+)
+
+// CallController issues call tokens to matched users and lists call history for support
+type CallController struct {
+	CallService *services.CallService
+}
+
+// NewCallController creates a new instance of CallController
+func NewCallController(service *services.CallService) *CallController {
+	return &CallController{CallService: service}
+}
+
+// IssueToken returns a short-lived token for joining a match's audio/video call
+func (c *CallController) IssueToken(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserHandle string `json:"userhandle"`
+		MatchID    string `json:"matchId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Handle("userhandle", request.UserHandle)
+	v.Required("matchId", request.MatchID)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	token, err := c.CallService.IssueToken(r.Context(), request.MatchID, request.UserHandle)
+	if errors.Is(err, services.ErrCallsUnavailable) {
+		http.Error(w, "Calls are not available", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, token)
+}
+
+// ListCalls returns the call tokens issued in a match, newest first (?matchId=&limit=&cursor=, admin)
+func (c *CallController) ListCalls(w http.ResponseWriter, r *http.Request) {
+	matchID := r.URL.Query().Get("matchId")
+	var v helpers.Validator
+	v.Required("matchId", matchID)
+	if v.WriteErrors(w) {
+		return
+	}
+
+	limit := helpers.PageLimit(r, defaultCallLogPageSize, maxCallLogPageSize)
+	entries, nextCursor, err := c.CallService.ListCalls(r.Context(), matchID, int32(limit), r.URL.Query().Get("cursor"))
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.SetNextCursor(w, nextCursor)
+	helpers.WriteJSONResponse(w, http.StatusOK, entries)
+}
@@ -1,14 +1,15 @@
 package controllers
 
 import (
-	"context"
 	"encoding/json"
-	"log"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
+	"vibin_server/helpers"
 	"vibin_server/models"
 	"vibin_server/services"
+	"vibin_server/utils"
 
 	"github.com/google/uuid"
 )
@@ -41,17 +42,22 @@ func (c *ChatController) HandleGetMessages(w http.ResponseWriter, r *http.Reques
 		limit = 50 // Default to 50 messages
 	}
 
-	log.Printf("🔍 Fetching latest %d messages for matchId: %s", limit, matchID)
+	utils.Logf(r.Context(), "🔍 Fetching latest %d messages for matchId: %s", limit, matchID)
 
 	// ✅ Fetch messages
-	messages, err := c.ChatService.GetMessagesByMatchID(context.TODO(), matchID, limit)
+	messages, nextCursor, err := c.ChatService.GetMessagesByMatchID(r.Context(), matchID, limit, r.URL.Query().Get("cursor"))
+	if errors.Is(err, utils.ErrInvalidCursor) {
+		http.Error(w, `{"error": "Invalid cursor"}`, http.StatusBadRequest)
+		return
+	}
 	if err != nil {
-		log.Printf("❌ Error fetching messages: %v", err)
+		utils.Logf(r.Context(), "❌ Error fetching messages: %v", err)
 		http.Error(w, `{"error": "Failed to fetch messages"}`, http.StatusInternalServerError)
 		return
 	}
 
-	// ✅ Send response
+	// ✅ Send response (older messages: pass X-Next-Cursor back as ?cursor=)
+	helpers.SetNextCursor(w, nextCursor)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(messages)
 }
@@ -68,10 +74,14 @@ func (c *ChatController) HandleMarkMessagesAsRead(w http.ResponseWriter, r *http
 		return
 	}
 
-	log.Printf("🔄 Marking messages as read for matchId: %s, User: %s", request.MatchID, request.UserHandle)
+	if !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	utils.Logf(r.Context(), "🔄 Marking messages as read for matchId: %s, User: %s", request.MatchID, request.UserHandle)
 
 	// ✅ Call service function to update messages
-	err := c.ChatService.MarkMessagesAsRead(context.TODO(), request.MatchID, request.UserHandle)
+	err := c.ChatService.MarkMessagesAsRead(r.Context(), request.MatchID, request.UserHandle)
 	if err != nil {
 		http.Error(w, `{"error": "Failed to mark messages as read"}`, http.StatusInternalServerError)
 		return
@@ -81,6 +91,33 @@ func (c *ChatController) HandleMarkMessagesAsRead(w http.ResponseWriter, r *http
 	json.NewEncoder(w).Encode(map[string]string{"status": "success", "message": "Messages received by user marked as read"})
 }
 
+// HandleMarkMessagesDelivered - Acknowledge that messages reached the recipient's client, up to
+// and including createdAt upTo (every message when omitted)
+func (c *ChatController) HandleMarkMessagesDelivered(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		MatchID    string `json:"matchId"`
+		UserHandle string `json:"userHandle"` // ✅ The recipient acknowledging delivery
+		UpTo       string `json:"upTo,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, `{"error": "Invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Required("matchId", request.MatchID)
+	v.Handle("userHandle", request.UserHandle)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	delivered, err := c.ChatService.MarkMessagesDelivered(r.Context(), request.MatchID, request.UserHandle, request.UpTo)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, map[string]int{"delivered": delivered})
+}
+
 // HandleSendMessage - Handles sending a new message
 func (c *ChatController) HandleSendMessage(w http.ResponseWriter, r *http.Request) {
 	var message models.Message
@@ -92,8 +129,14 @@ func (c *ChatController) HandleSendMessage(w http.ResponseWriter, r *http.Reques
 	}
 
 	// ✅ Validate required fields
-	if message.MatchID == "" || message.SenderID == "" || message.Content == "" {
-		http.Error(w, `{"error": "Missing required fields: matchId, senderId, or content"}`, http.StatusBadRequest)
+	var v helpers.Validator
+	v.Required("matchId", message.MatchID)
+	v.Required("senderId", message.SenderID)
+	if message.E2E == nil { // ✅ End-to-end encrypted messages carry ciphertexts instead
+		v.Required("content", message.Content)
+	}
+	v.MaxLength("content", message.Content, helpers.MaxMessageLength)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, message.SenderID) {
 		return
 	}
 
@@ -108,12 +151,32 @@ func (c *ChatController) HandleSendMessage(w http.ResponseWriter, r *http.Reques
 	// ✅ Set `isUnread` to "true" by default
 	message.SetIsUnread(true)
 
-	log.Printf("📩 Received message request: %+v", message)
+	utils.Logf(r.Context(), "📩 Received message request: %+v", message)
 
 	// ✅ Save message to DynamoDB using the existing SendMessage function
-	err := c.ChatService.SendMessage(context.TODO(), message)
+	err := c.ChatService.SendMessage(r.Context(), message)
+	if errors.Is(err, services.ErrContentRejected) {
+		http.Error(w, `{"error": "Message violates content rules"}`, http.StatusUnprocessableEntity)
+		return
+	}
+	var firstMessageErr *services.FirstMessageError
+	if errors.As(err, &firstMessageErr) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]string{"error": firstMessageErr.Reason})
+		return
+	}
+	if errors.Is(err, services.ErrMediaNotAllowed) {
+		http.Error(w, `{"error": "This conversation is text-only. The recipient is not accepting images or voice messages."}`, http.StatusForbidden)
+		return
+	}
+	var serviceErr *services.ServiceError
+	if errors.Is(err, services.ErrValidation) && errors.As(err, &serviceErr) {
+		helpers.WriteJSONResponse(w, http.StatusBadRequest, map[string]string{"error": serviceErr.Message})
+		return
+	}
 	if err != nil {
-		log.Printf("❌ Failed to send message: %v", err)
+		utils.Logf(r.Context(), "❌ Failed to send message: %v", err)
 		http.Error(w, `{"error": "Failed to send message"}`, http.StatusInternalServerError)
 		return
 	}
@@ -146,12 +209,12 @@ func (c *ChatController) HandleLikeMessage(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	log.Printf("💖 Updating like status for message at %s in MatchID: %s to %v", request.CreatedAt, request.MatchID, request.Liked)
+	utils.Logf(r.Context(), "💖 Updating like status for message at %s in MatchID: %s to %v", request.CreatedAt, request.MatchID, request.Liked)
 
 	// ✅ Call the service to update the like status
-	err := c.ChatService.UpdateMessageLikeStatus(context.TODO(), request.MatchID, request.CreatedAt, request.Liked)
+	err := c.ChatService.UpdateMessageLikeStatus(r.Context(), request.MatchID, request.CreatedAt, request.Liked)
 	if err != nil {
-		log.Printf("❌ Failed to update like status: %v", err)
+		utils.Logf(r.Context(), "❌ Failed to update like status: %v", err)
 		http.Error(w, `{"error": "Failed to update like status"}`, http.StatusInternalServerError)
 		return
 	}
@@ -164,3 +227,129 @@ func (c *ChatController) HandleLikeMessage(w http.ResponseWriter, r *http.Reques
 		"message": "Like status updated successfully",
 	})
 }
+
+// HandlePinMessage - Pin or unpin a message for both participants
+func (c *ChatController) HandlePinMessage(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		MatchID    string `json:"matchId"`
+		CreatedAt  string `json:"createdAt"`
+		UserHandle string `json:"userHandle"` // ✅ Participant pinning the message
+		Pinned     bool   `json:"pinned"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, `{"error": "Invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Required("matchId", request.MatchID)
+	v.Required("createdAt", request.CreatedAt)
+	v.Handle("userHandle", request.UserHandle)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	if err := c.ChatService.SetMessagePinned(r.Context(), request.MatchID, request.CreatedAt, request.UserHandle, request.Pinned); err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, map[string]bool{"pinned": request.Pinned})
+}
+
+// HandleGetUnreadCounts - Fetch unread message counts per match for a user
+func (c *ChatController) HandleGetUnreadCounts(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userHandle")
+	if userHandle == "" {
+		http.Error(w, `{"error": "userHandle is required"}`, http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	counts, err := c.ChatService.GetUnreadCounts(r.Context(), userHandle)
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Error fetching unread counts: %v", err)
+		http.Error(w, `{"error": "Failed to fetch unread counts"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(counts)
+}
+
+// HandleSearchMessages - Search a match's messages by keyword and date range
+func (c *ChatController) HandleSearchMessages(w http.ResponseWriter, r *http.Request) {
+	matchID := r.URL.Query().Get("matchId")
+	if matchID == "" {
+		http.Error(w, `{"error": "matchId is required"}`, http.StatusBadRequest)
+		return
+	}
+	query, err := parseMessageSearchQuery(r)
+	if err != nil {
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	results, err := c.ChatService.SearchMessages(r.Context(), matchID, query)
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Error searching messages: %v", err)
+		http.Error(w, `{"error": "Failed to search messages"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+}
+
+// HandleGetConversationSettings - Fetch a user's settings for a conversation
+func (c *ChatController) HandleGetConversationSettings(w http.ResponseWriter, r *http.Request) {
+	matchID := r.URL.Query().Get("matchId")
+	userHandle := r.URL.Query().Get("userHandle")
+	if matchID == "" || userHandle == "" {
+		http.Error(w, `{"error": "matchId and userHandle are required"}`, http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	settings, err := c.ChatService.GetConversationSettings(r.Context(), matchID, userHandle)
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Error fetching conversation settings: %v", err)
+		http.Error(w, `{"error": "Failed to fetch conversation settings"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+}
+
+// HandleSetTextOnly - Restrict (or reopen) a conversation to text-only messages for the recipient
+func (c *ChatController) HandleSetTextOnly(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		MatchID    string `json:"matchId"`
+		UserHandle string `json:"userHandle"`
+		TextOnly   bool   `json:"textOnly"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, `{"error": "Invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	if request.MatchID == "" || request.UserHandle == "" {
+		http.Error(w, `{"error": "matchId and userHandle are required"}`, http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	settings, err := c.ChatService.SetTextOnly(r.Context(), request.MatchID, request.UserHandle, request.TextOnly)
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Error updating text-only setting: %v", err)
+		http.Error(w, `{"error": "Failed to update conversation settings"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+}
@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"net/http"
+	"vibin_server/helpers"
+	"vibin_server/models"
+	"vibin_server/services"
+	"vibin_server/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// ✅ Item collection page size defaults and caps
+const (
+	defaultCollectionPageSize = 100
+	maxCollectionPageSize     = 500
+)
+
+// CollectionController serves single-query item collections from the consolidated table (admin)
+type CollectionController struct {
+	SingleTableService *services.SingleTableService
+}
+
+// NewCollectionController creates a new instance of CollectionController
+func NewCollectionController(service *services.SingleTableService) *CollectionController {
+	return &CollectionController{SingleTableService: service}
+}
+
+// GetUserCollection returns one page of the records stored under a user's partition (?limit=&cursor=)
+func (c *CollectionController) GetUserCollection(w http.ResponseWriter, r *http.Request) {
+	userHandle := mux.Vars(r)["userHandle"]
+	limit := helpers.PageLimit(r, defaultCollectionPageSize, maxCollectionPageSize)
+
+	collection, nextCursor, err := c.SingleTableService.GetUserCollection(r.Context(), userHandle, int32(limit), r.URL.Query().Get("cursor"))
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Failed to fetch collection for user %s: %v", userHandle, err)
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	writeCollection(w, collection, nextCursor)
+}
+
+// GetMatchCollection returns one page of the match summary and messages under a match's partition
+func (c *CollectionController) GetMatchCollection(w http.ResponseWriter, r *http.Request) {
+	matchID := mux.Vars(r)["matchId"]
+	limit := helpers.PageLimit(r, defaultCollectionPageSize, maxCollectionPageSize)
+
+	collection, nextCursor, err := c.SingleTableService.GetMatchCollection(r.Context(), matchID, int32(limit), r.URL.Query().Get("cursor"))
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Failed to fetch collection for match %s: %v", matchID, err)
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	writeCollection(w, collection, nextCursor)
+}
+
+// GetGroupCollection returns one page of the group summary and messages under a group's partition
+func (c *CollectionController) GetGroupCollection(w http.ResponseWriter, r *http.Request) {
+	groupID := mux.Vars(r)["groupId"]
+	limit := helpers.PageLimit(r, defaultCollectionPageSize, maxCollectionPageSize)
+
+	collection, nextCursor, err := c.SingleTableService.GetGroupCollection(r.Context(), groupID, int32(limit), r.URL.Query().Get("cursor"))
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Failed to fetch collection for group %s: %v", groupID, err)
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	writeCollection(w, collection, nextCursor)
+}
+
+// writeCollection sends a page of a collection, with the next page cursor in X-Next-Cursor
+func writeCollection(w http.ResponseWriter, collection *models.ItemCollection, nextCursor string) {
+	helpers.SetNextCursor(w, nextCursor)
+	helpers.WriteJSONResponse(w, http.StatusOK, collection)
+}
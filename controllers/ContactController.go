@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"vibin_server/helpers"
+	"vibin_server/models"
+	"vibin_server/services"
+)
+
+// ContactController syncs hashed phone contacts and their discovery modes
+type ContactController struct {
+	ContactService *services.ContactService
+}
+
+// NewContactController creates a new instance of ContactController
+func NewContactController(service *services.ContactService) *ContactController {
+	return &ContactController{ContactService: service}
+}
+
+// SyncContacts replaces the user's contacts with SHA-256 hashes of their E.164 numbers
+func (c *ContactController) SyncContacts(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserHandle string   `json:"userhandle"`
+		Hashes     []string `json:"hashes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Handle("userhandle", request.UserHandle)
+	v.Check(len(request.Hashes) <= models.MaxSyncedContacts, "hashes", fmt.Sprintf("must have at most %d entries", models.MaxSyncedContacts))
+	for _, hash := range request.Hashes {
+		if !models.ContactHashPattern.MatchString(hash) {
+			v.Check(false, "hashes", "must be lowercase hex SHA-256 hashes")
+			break
+		}
+	}
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	settings, err := c.ContactService.SyncContacts(r.Context(), request.UserHandle, request.Hashes)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, settings)
+}
+
+// DeleteContacts removes every synced contact (?userhandle=)
+func (c *ContactController) DeleteContacts(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	if userHandle == "" {
+		http.Error(w, "Missing required parameter: userhandle", http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	if err := c.ContactService.DeleteContacts(r.Context(), userHandle); err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetSettings returns the contact sync modes (?userhandle=)
+func (c *ContactController) GetSettings(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	if userHandle == "" {
+		http.Error(w, "Missing required parameter: userhandle", http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	settings, err := c.ContactService.GetSettings(r.Context(), userHandle)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, settings)
+}
+
+// UpdateSettings switches "never show my contacts" and mutual connection counts on or off
+func (c *ContactController) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserHandle            string `json:"userhandle"`
+		HideContacts          bool   `json:"hideContacts"`
+		ShowMutualConnections bool   `json:"showMutualConnections"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Handle("userhandle", request.UserHandle)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	settings, err := c.ContactService.UpdateSettings(r.Context(), request.UserHandle, request.HideContacts, request.ShowMutualConnections)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, settings)
+}
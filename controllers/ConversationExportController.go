@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+	"vibin_server/helpers"
+	"vibin_server/models"
+	"vibin_server/services"
+)
+
+// ✅ Export log page size defaults and caps
+const (
+	defaultExportLogPageSize = 50
+	maxExportLogPageSize     = 200
+)
+
+// ConversationExportController exports transcripts for participants and lists exports for support
+type ConversationExportController struct {
+	ExportService *services.ConversationExportService
+}
+
+// NewConversationExportController creates a new instance of ConversationExportController
+func NewConversationExportController(service *services.ConversationExportService) *ConversationExportController {
+	return &ConversationExportController{ExportService: service}
+}
+
+// ExportConversation downloads one match's or group's transcript
+// (?userhandle=&matchId= or ?userhandle=&groupId=, &format=json|text)
+func (c *ConversationExportController) ExportConversation(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	userHandle := query.Get("userhandle")
+	matchID, groupID := query.Get("matchId"), query.Get("groupId")
+	format := query.Get("format")
+	if format == "" {
+		format = models.ExportFormatJSON
+	}
+	var v helpers.Validator
+	v.Handle("userhandle", userHandle)
+	v.Check((matchID == "") != (groupID == ""), "matchId", "give exactly one of matchId and groupId")
+	v.OneOf("format", format, models.ExportFormatJSON, models.ExportFormatText)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	kind, conversationID := models.ConversationKindMatch, matchID
+	if groupID != "" {
+		kind, conversationID = models.ConversationKindGroup, groupID
+	}
+	export, err := c.ExportService.ExportConversation(r.Context(), userHandle, kind, conversationID, format)
+	var quotaErr *services.QuotaError
+	if errors.As(err, &quotaErr) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(quotaErr.RetryAt).Seconds())+1))
+		helpers.WriteJSONResponse(w, http.StatusTooManyRequests, quotaErr)
+		return
+	}
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	fileName := fmt.Sprintf("vibin-%s-%s", kind, conversationID)
+	if format == models.ExportFormatText {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.txt"`, fileName))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(export.Text()))
+		return
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, fileName))
+	helpers.WriteJSONResponse(w, http.StatusOK, export)
+}
+
+// ListExports returns the exports a user made, newest first (?userhandle=&limit=&cursor=, admin)
+func (c *ConversationExportController) ListExports(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	var v helpers.Validator
+	v.Handle("userhandle", userHandle)
+	if v.WriteErrors(w) {
+		return
+	}
+
+	limit := helpers.PageLimit(r, defaultExportLogPageSize, maxExportLogPageSize)
+	entries, nextCursor, err := c.ExportService.ListExports(r.Context(), userHandle, int32(limit), r.URL.Query().Get("cursor"))
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.SetNextCursor(w, nextCursor)
+	helpers.WriteJSONResponse(w, http.StatusOK, entries)
+}
@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"vibin_server/helpers"
+	"vibin_server/models"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+// DateCheckInController lets users register planned dates and answer their safety check-ins
+type DateCheckInController struct {
+	CheckInService *services.DateCheckInService
+}
+
+// NewDateCheckInController creates a new instance of DateCheckInController
+func NewDateCheckInController(service *services.DateCheckInService) *DateCheckInController {
+	return &DateCheckInController{CheckInService: service}
+}
+
+// CreateCheckIn registers a planned date and the emergency contact to text if the user doesn't check in
+func (c *DateCheckInController) CreateCheckIn(w http.ResponseWriter, r *http.Request) {
+	var request models.DateCheckInRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Handle("userhandle", request.UserHandle)
+	v.Required("matchId", request.MatchID)
+	v.Required("scheduledAt", request.ScheduledAt)
+	v.Required("place", request.Place)
+	v.MaxLength("place", request.Place, models.MaxDatePlaceLength)
+	v.Required("contactName", request.ContactName)
+	v.MaxLength("contactName", request.ContactName, models.MaxContactNameLength)
+	v.Required("contactPhone", request.ContactPhone)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	checkIn, err := c.CheckInService.CreateCheckIn(r.Context(), request)
+	if errors.Is(err, services.ErrCheckInsUnavailable) {
+		http.Error(w, "Date check-ins are not available", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusCreated, checkIn)
+}
+
+// ListCheckIns returns the user's check-ins, soonest date first (?userhandle=)
+func (c *DateCheckInController) ListCheckIns(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	var v helpers.Validator
+	v.Handle("userhandle", userHandle)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	checkIns, err := c.CheckInService.ListCheckIns(r.Context(), userHandle)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, checkIns)
+}
+
+// ConfirmSafe answers a check-in: the user is safe
+func (c *DateCheckInController) ConfirmSafe(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserHandle string `json:"userhandle"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Handle("userhandle", request.UserHandle)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	checkIn, err := c.CheckInService.ConfirmSafe(r.Context(), request.UserHandle, mux.Vars(r)["checkInId"])
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, checkIn)
+}
+
+// CancelCheckIn stops an upcoming check-in (?userhandle=)
+func (c *DateCheckInController) CancelCheckIn(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	var v helpers.Validator
+	v.Handle("userhandle", userHandle)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	checkIn, err := c.CheckInService.CancelCheckIn(r.Context(), userHandle, mux.Vars(r)["checkInId"])
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, checkIn)
+}
@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"vibin_server/helpers"
+	"vibin_server/services"
+	"vibin_server/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// ✅ Date idea page size defaults and caps
+const (
+	defaultDateIdeas = 5
+	maxDateIdeas     = 20
+)
+
+// DateIdeasController serves date/activity suggestions for matches
+type DateIdeasController struct {
+	DateIdeasService *services.DateIdeasService
+}
+
+// NewDateIdeasController creates a new instance of DateIdeasController
+func NewDateIdeasController(service *services.DateIdeasService) *DateIdeasController {
+	return &DateIdeasController{DateIdeasService: service}
+}
+
+// GetDateIdeas returns ranked date ideas and a meeting point for a match
+func (c *DateIdeasController) GetDateIdeas(w http.ResponseWriter, r *http.Request) {
+	matchID := mux.Vars(r)["matchId"]
+	userHandle := r.URL.Query().Get("userHandle")
+	if userHandle == "" {
+		http.Error(w, "Missing required parameter: userHandle", http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	limit := defaultDateIdeas
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxDateIdeas)
+	}
+
+	ideas, err := c.DateIdeasService.SuggestDateIdeas(r.Context(), matchID, userHandle, limit)
+	if errors.Is(err, services.ErrMatchNotFound) {
+		http.Error(w, "Match not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Failed to suggest date ideas for match %s: %v", matchID, err)
+		http.Error(w, "Failed to fetch date ideas", http.StatusInternalServerError)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, ideas)
+}
@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"net/http"
+	"vibin_server/helpers"
+	"vibin_server/services"
+	"vibin_server/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// EncryptionController exposes admin operations on conversation encryption keys
+type EncryptionController struct {
+	EncryptionService *services.EncryptionService
+}
+
+// NewEncryptionController creates a new instance of EncryptionController
+func NewEncryptionController(service *services.EncryptionService) *EncryptionController {
+	return &EncryptionController{EncryptionService: service}
+}
+
+// RotateConversationKey issues a new data key version for a match or group conversation
+func (c *EncryptionController) RotateConversationKey(w http.ResponseWriter, r *http.Request) {
+	conversationID := mux.Vars(r)["conversationId"]
+
+	if !c.EncryptionService.Enabled() {
+		http.Error(w, "Message encryption is not configured", http.StatusConflict)
+		return
+	}
+
+	key, err := c.EncryptionService.RotateKey(r.Context(), conversationID)
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Failed to rotate key for conversation %s: %v", conversationID, err)
+		http.Error(w, "Failed to rotate conversation key", http.StatusInternalServerError)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, key)
+}
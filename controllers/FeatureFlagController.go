@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"vibin_server/helpers"
+	"vibin_server/models"
+	"vibin_server/services"
+	"vibin_server/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// FeatureFlagController exposes client config and admin flag management
+type FeatureFlagController struct {
+	FeatureFlagService *services.FeatureFlagService
+}
+
+// NewFeatureFlagController creates a new instance of FeatureFlagController
+func NewFeatureFlagController(service *services.FeatureFlagService) *FeatureFlagController {
+	return &FeatureFlagController{FeatureFlagService: service}
+}
+
+// GetClientConfig returns the flags (and their remote config) evaluated for ?userhandle=
+func (c *FeatureFlagController) GetClientConfig(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	if userHandle == "" {
+		http.Error(w, "Missing required query parameter: userhandle", http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, c.FeatureFlagService.ClientConfig(userHandle))
+}
+
+// ListFlags returns every stored flag (admin)
+func (c *FeatureFlagController) ListFlags(w http.ResponseWriter, r *http.Request) {
+	flags, err := c.FeatureFlagService.ListFlags(r.Context())
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Failed to list feature flags: %v", err)
+		http.Error(w, "Failed to list feature flags", http.StatusInternalServerError)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, flags)
+}
+
+// SaveFlag creates or replaces the flag named in the path (admin)
+func (c *FeatureFlagController) SaveFlag(w http.ResponseWriter, r *http.Request) {
+	var request models.FeatureFlag
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	request.Key = mux.Vars(r)["key"]
+	request.UpdatedBy = helpers.AdminHandle(r)
+
+	flag, err := c.FeatureFlagService.SaveFlag(r.Context(), request)
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Failed to save feature flag: %v", err)
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, flag)
+}
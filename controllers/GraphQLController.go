@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"vibin_server/graph"
+	"vibin_server/helpers"
+	"vibin_server/services"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// maxGraphQLBodyBytes bounds the query document plus variables
+const maxGraphQLBodyBytes = 64 << 10
+
+// GraphQLController executes GraphQL queries against the shared services
+type GraphQLController struct {
+	Schema             *graphql.Schema
+	UserProfileService *services.UserProfileService
+}
+
+// NewGraphQLController creates a new instance of GraphQLController
+func NewGraphQLController(schema *graphql.Schema, userProfileService *services.UserProfileService) *GraphQLController {
+	return &GraphQLController{Schema: schema, UserProfileService: userProfileService}
+}
+
+// HandleQuery runs one query ({"query", "operationName", "variables"}) with fresh per-request loaders
+func (c *GraphQLController) HandleQuery(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName"`
+		Variables     map[string]interface{} `json:"variables"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxGraphQLBodyBytes)).Decode(&request); err != nil || request.Query == "" {
+		http.Error(w, "Invalid GraphQL request", http.StatusBadRequest)
+		return
+	}
+
+	ctx := graph.WithLoaders(r.Context(), c.UserProfileService)
+	response := c.Schema.Exec(ctx, request.Query, request.OperationName, request.Variables)
+
+	// ✅ GraphQL reports resolver errors in the body; the status stays 200 per the spec
+	helpers.WriteJSONResponse(w, http.StatusOK, response)
+}
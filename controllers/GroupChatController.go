@@ -1,18 +1,24 @@
 package controllers
 
 import (
-	"context"
 	"encoding/json"
-	"log"
+	"errors"
 	"net/http"
-	"strconv"
 	"time"
+	"vibin_server/helpers"
 	"vibin_server/models"
 	"vibin_server/services"
+	"vibin_server/utils"
 
 	"github.com/google/uuid"
 )
 
+// ✅ Group message page sizes
+const (
+	defaultGroupMessagesPageSize = 50
+	maxGroupMessagesPageSize     = 200
+)
+
 // GroupChatController struct
 type GroupChatController struct {
 	GroupChatService *services.GroupChatService
@@ -31,6 +37,9 @@ func (c *GroupChatController) HandleCreateGroupMessage(w http.ResponseWriter, r
 		Content  string   `json:"content"`
 		ImageURL *string  `json:"imageUrl,omitempty"`
 		Members  []string `json:"members"`
+
+		ReplyToMessageID string `json:"replyToMessageId,omitempty"` // ✅ Quote-reply (with replyToCreatedAt)
+		ReplyToCreatedAt string `json:"replyToCreatedAt,omitempty"`
 	}
 
 	// Decode request body
@@ -40,8 +49,12 @@ func (c *GroupChatController) HandleCreateGroupMessage(w http.ResponseWriter, r
 	}
 
 	// ✅ Validate required fields
-	if request.GroupID == "" || request.SenderID == "" || request.Content == "" {
-		http.Error(w, `{"error": "Missing required fields: groupId, senderId, or content"}`, http.StatusBadRequest)
+	var v helpers.Validator
+	v.Required("groupId", request.GroupID)
+	v.Required("senderId", request.SenderID)
+	v.Required("content", request.Content)
+	v.MaxLength("content", request.Content, helpers.MaxMessageLength)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.SenderID) {
 		return
 	}
 
@@ -60,10 +73,11 @@ func (c *GroupChatController) HandleCreateGroupMessage(w http.ResponseWriter, r
 		Content:     request.Content,
 		ImageURL:    request.ImageURL,
 		IsRead:      make(map[string]bool),
-		Likes:       make(map[string]bool),
 		ReadCount:   1, // Sender has read the message
-		LikeCount:   0,
 		MemberCount: len(request.Members),
+
+		ReplyToMessageID: request.ReplyToMessageID,
+		ReplyToCreatedAt: request.ReplyToCreatedAt,
 	}
 
 	// ✅ Initialize isRead map (Only sender has read the message initially)
@@ -71,13 +85,23 @@ func (c *GroupChatController) HandleCreateGroupMessage(w http.ResponseWriter, r
 		message.IsRead[member] = false
 	}
 	message.IsRead[request.SenderID] = true // Sender has read their own message
+	message.ReadAt = map[string]string{request.SenderID: createdAt}
 
-	log.Printf("📩 Creating group message: %+v", message)
+	utils.Logf(r.Context(), "📩 Creating group message: %+v", message)
 
 	// ✅ Save message to DynamoDB using GroupChatService
-	err := c.GroupChatService.CreateGroupMessage(context.TODO(), message)
+	err := c.GroupChatService.CreateGroupMessage(r.Context(), message)
+	if errors.Is(err, services.ErrContentRejected) {
+		http.Error(w, `{"error": "Message violates content rules"}`, http.StatusUnprocessableEntity)
+		return
+	}
+	var serviceErr *services.ServiceError
+	if errors.Is(err, services.ErrValidation) && errors.As(err, &serviceErr) {
+		helpers.WriteJSONResponse(w, http.StatusBadRequest, map[string]string{"error": serviceErr.Message})
+		return
+	}
 	if err != nil {
-		log.Printf("❌ Failed to send group message: %v", err)
+		utils.Logf(r.Context(), "❌ Failed to send group message: %v", err)
 		http.Error(w, `{"error": "Failed to send group message"}`, http.StatusInternalServerError)
 		return
 	}
@@ -91,11 +115,12 @@ func (c *GroupChatController) HandleCreateGroupMessage(w http.ResponseWriter, r
 	})
 }
 
-// HandleGetGroupMessages - Fetch latest messages based on groupId and limit
+// HandleGetGroupMessages - Fetch a window of a group's messages: the latest by default, older than
+// ?before= (or the legacy ?cursor=), newer than ?after=, or around ?at= (RFC3339 or YYYY-MM-DD)
 func (c *GroupChatController) HandleGetGroupMessages(w http.ResponseWriter, r *http.Request) {
 	// ✅ Parse query parameters
-	groupID := r.URL.Query().Get("groupId")
-	limitStr := r.URL.Query().Get("limit")
+	params := r.URL.Query()
+	groupID := params.Get("groupId")
 
 	// ✅ Validate groupId
 	if groupID == "" {
@@ -103,23 +128,263 @@ func (c *GroupChatController) HandleGetGroupMessages(w http.ResponseWriter, r *h
 		return
 	}
 
-	// ✅ Convert limit from string to int (default: 50)
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 {
-		limit = 50 // Default to 50 messages
+	window := models.GroupMessageWindow{
+		Before: params.Get("before"),
+		After:  params.Get("after"),
+		Limit:  helpers.PageLimit(r, defaultGroupMessagesPageSize, maxGroupMessagesPageSize),
+	}
+	if window.Before == "" {
+		window.Before = params.Get("cursor")
+	}
+	around, err := parseSearchBound(params.Get("at"), false)
+	if err != nil {
+		http.Error(w, `{"error": "at must be RFC3339 or YYYY-MM-DD"}`, http.StatusBadRequest)
+		return
+	}
+	window.Around = around
+
+	modes := 0
+	for _, value := range []string{window.Before, window.After, window.Around} {
+		if value != "" {
+			modes++
+		}
+	}
+	if modes > 1 {
+		http.Error(w, `{"error": "Only one of before, after and at may be given"}`, http.StatusBadRequest)
+		return
 	}
 
-	log.Printf("🔍 Fetching latest %d messages for groupId: %s", limit, groupID)
+	utils.Logf(r.Context(), "🔍 Fetching %d messages for groupId: %s", window.Limit, groupID)
 
 	// ✅ Fetch messages from service
-	messages, err := c.GroupChatService.GetMessagesByGroupID(context.TODO(), groupID, limit)
+	page, err := c.GroupChatService.GetMessagesByGroupID(r.Context(), groupID, window)
+	if errors.Is(err, utils.ErrInvalidCursor) {
+		http.Error(w, `{"error": "Invalid cursor"}`, http.StatusBadRequest)
+		return
+	}
 	if err != nil {
-		log.Printf("❌ Error fetching group messages: %v", err)
+		utils.Logf(r.Context(), "❌ Error fetching group messages: %v", err)
 		http.Error(w, `{"error": "Failed to fetch group messages"}`, http.StatusInternalServerError)
 		return
 	}
 
-	// ✅ Send response
+	// ✅ Send response (older messages: pass X-Next-Cursor back as ?before=; newer: X-After-Cursor as ?after=)
+	helpers.SetNextCursor(w, page.BeforeCursor)
+	helpers.SetAfterCursor(w, page.AfterCursor)
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(messages)
+	json.NewEncoder(w).Encode(page.Messages)
+}
+
+// HandleGetUnreadCounts - Fetch unread group message counts per group for a user
+func (c *GroupChatController) HandleGetUnreadCounts(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userHandle")
+	if userHandle == "" {
+		http.Error(w, `{"error": "userHandle is required"}`, http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	counts, err := c.GroupChatService.GetUnreadCounts(r.Context(), userHandle)
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Error fetching group unread counts: %v", err)
+		http.Error(w, `{"error": "Failed to fetch unread counts"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(counts)
+}
+
+// HandleSearchMessages - Search a group's messages by keyword and date range
+func (c *GroupChatController) HandleSearchMessages(w http.ResponseWriter, r *http.Request) {
+	groupID := r.URL.Query().Get("groupId")
+	if groupID == "" {
+		http.Error(w, `{"error": "groupId is required"}`, http.StatusBadRequest)
+		return
+	}
+	query, err := parseMessageSearchQuery(r)
+	if err != nil {
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	results, err := c.GroupChatService.SearchMessages(r.Context(), groupID, query)
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Error searching group messages: %v", err)
+		http.Error(w, `{"error": "Failed to search group messages"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+}
+
+// HandleMarkGroupMessageAsRead - Record that a member has read a group message
+func (c *GroupChatController) HandleMarkGroupMessageAsRead(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		GroupID   string `json:"groupId"`
+		CreatedAt string `json:"createdAt"`
+		UserID    string `json:"userId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, `{"error": "Invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	if request.GroupID == "" || request.CreatedAt == "" || request.UserID == "" {
+		http.Error(w, `{"error": "Missing required fields: groupId, createdAt, or userId"}`, http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, request.UserID) {
+		return
+	}
+
+	if err := c.GroupChatService.MarkGroupMessageAsRead(r.Context(), request.GroupID, request.CreatedAt, request.UserID); err != nil {
+		utils.Logf(r.Context(), "❌ Failed to mark group message as read: %v", err)
+		http.Error(w, `{"error": "Failed to mark message as read"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":  "success",
+		"message": "Message marked as read",
+	})
+}
+
+// HandleGetReadReceipts - Fetch who has read a group message and when
+func (c *GroupChatController) HandleGetReadReceipts(w http.ResponseWriter, r *http.Request) {
+	groupID := r.URL.Query().Get("groupId")
+	createdAt := r.URL.Query().Get("createdAt")
+	if groupID == "" || createdAt == "" {
+		http.Error(w, `{"error": "groupId and createdAt are required"}`, http.StatusBadRequest)
+		return
+	}
+
+	receipts, err := c.GroupChatService.GetReadReceipts(r.Context(), groupID, createdAt)
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Error fetching read receipts: %v", err)
+		http.Error(w, `{"error": "Failed to fetch read receipts"}`, http.StatusInternalServerError)
+		return
+	}
+	if receipts == nil {
+		http.Error(w, `{"error": "Message not found"}`, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(receipts)
+}
+
+// HandleUpdatePresence - Heartbeat from a member (optionally typing); responds with who else is around
+func (c *GroupChatController) HandleUpdatePresence(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		GroupID    string `json:"groupId"`
+		UserHandle string `json:"userHandle"`
+		Typing     bool   `json:"typing"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, `{"error": "Invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Required("groupId", request.GroupID)
+	v.Handle("userHandle", request.UserHandle)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	state, err := c.GroupChatService.UpdatePresence(r.Context(), request.GroupID, request.UserHandle, request.Typing)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, state)
+}
+
+// HandleGetPresence - Who else is online and typing in a group (?groupId=&userHandle=)
+func (c *GroupChatController) HandleGetPresence(w http.ResponseWriter, r *http.Request) {
+	groupID := r.URL.Query().Get("groupId")
+	userHandle := r.URL.Query().Get("userHandle")
+	var v helpers.Validator
+	v.Required("groupId", groupID)
+	v.Handle("userHandle", userHandle)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	state, err := c.GroupChatService.GetPresence(r.Context(), groupID, userHandle)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, state)
+}
+
+// HandleClearPresence - Mark the user offline in a group right away (?groupId=&userHandle=)
+func (c *GroupChatController) HandleClearPresence(w http.ResponseWriter, r *http.Request) {
+	groupID := r.URL.Query().Get("groupId")
+	userHandle := r.URL.Query().Get("userHandle")
+	var v helpers.Validator
+	v.Required("groupId", groupID)
+	v.Handle("userHandle", userHandle)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	if err := c.GroupChatService.ClearPresence(r.Context(), groupID, userHandle); err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleAddReaction - Add a member's emoji reaction to a group message
+func (c *GroupChatController) HandleAddReaction(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		GroupID    string `json:"groupId"`
+		CreatedAt  string `json:"createdAt"`
+		UserHandle string `json:"userHandle"`
+		Emoji      string `json:"emoji"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, `{"error": "Invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Required("groupId", request.GroupID)
+	v.Required("createdAt", request.CreatedAt)
+	v.Handle("userHandle", request.UserHandle)
+	v.Required("emoji", request.Emoji)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	reactions, err := c.GroupChatService.AddReaction(r.Context(), request.GroupID, request.CreatedAt, request.UserHandle, request.Emoji)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{"reactions": reactions})
+}
+
+// HandleRemoveReaction - Remove a member's emoji reaction (?groupId=&createdAt=&userHandle=&emoji=)
+func (c *GroupChatController) HandleRemoveReaction(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	var v helpers.Validator
+	v.Required("groupId", params.Get("groupId"))
+	v.Required("createdAt", params.Get("createdAt"))
+	v.Handle("userHandle", params.Get("userHandle"))
+	v.Required("emoji", params.Get("emoji"))
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, params.Get("userHandle")) {
+		return
+	}
+
+	reactions, err := c.GroupChatService.RemoveReaction(r.Context(), params.Get("groupId"), params.Get("createdAt"), params.Get("userHandle"), params.Get("emoji"))
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{"reactions": reactions})
 }
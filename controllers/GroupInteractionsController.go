@@ -1,17 +1,28 @@
 package controllers
 
 import (
-	"context"
 	"encoding/json"
-	"log"
+	"errors"
 	"net/http"
+	"strings"
 	"time"
+	"vibin_server/helpers"
 	"vibin_server/models"
 	"vibin_server/services"
+	"vibin_server/utils"
 
 	"github.com/gorilla/mux"
 )
 
+// maxActiveGroupsPageSize caps how many active groups one request returns
+const maxActiveGroupsPageSize = 50
+
+// Page sizes for the sent-invites and pending-approvals lists
+const (
+	defaultInvitesPageSize = 50
+	maxInvitesPageSize     = 100
+)
+
 // GroupInteractionController handles group invite operations
 type GroupInteractionController struct {
 	service *services.GroupInteractionService
@@ -25,10 +36,12 @@ func NewGroupInteractionController(service *services.GroupInteractionService) *G
 // ✅ CreateGroupInvite - Handles the creation of a group invite
 func (c *GroupInteractionController) CreateGroupInvite(w http.ResponseWriter, r *http.Request) {
 	var inviteRequest struct {
-		InviterHandle  string `json:"inviterHandle"`
-		ApproverHandle string `json:"approverHandle"`
-		InviteeHandle  string `json:"inviteeHandle"`
-		GroupName      string `json:"groupName"`
+		InviterHandle   string   `json:"inviterHandle"`
+		ApproverHandle  string   `json:"approverHandle"`
+		ApproverHandles []string `json:"approverHandles"` // Optional: several approvers (first is the primary)
+		ApprovalPolicy  string   `json:"approvalPolicy"`  // Optional: "all" (default) or "majority"
+		InviteeHandle   string   `json:"inviteeHandle"`
+		GroupName       string   `json:"groupName"`
 	}
 
 	// Decode request body
@@ -37,12 +50,38 @@ func (c *GroupInteractionController) CreateGroupInvite(w http.ResponseWriter, r
 		return
 	}
 
+	// ✅ Collect approvers (single approverHandle stays supported), dropping blanks and duplicates
+	var approvers []string
+	for _, handle := range append([]string{inviteRequest.ApproverHandle}, inviteRequest.ApproverHandles...) {
+		if handle != "" && !containsHandle(approvers, handle) {
+			approvers = append(approvers, handle)
+		}
+	}
+
+	policy := inviteRequest.ApprovalPolicy
+	if policy == "" {
+		policy = models.ApprovalPolicyAll
+	}
+
 	// ✅ Validate that all required fields are provided
-	if inviteRequest.InviterHandle == "" || inviteRequest.ApproverHandle == "" || inviteRequest.InviteeHandle == "" {
-		http.Error(w, "Missing required fields", http.StatusBadRequest)
+	var v helpers.Validator
+	v.Required("inviterHandle", inviteRequest.InviterHandle)
+	v.Check(len(approvers) > 0, "approverHandle", "is required")
+	v.Required("inviteeHandle", inviteRequest.InviteeHandle)
+	v.MaxLength("groupName", inviteRequest.GroupName, helpers.MaxGroupNameLength)
+	v.OneOf("approvalPolicy", policy, models.ApprovalPolicyAll, models.ApprovalPolicyMajority)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, inviteRequest.InviterHandle) {
 		return
 	}
 
+	// ✅ Members are the inviter plus every approver
+	members := []string{inviteRequest.InviterHandle}
+	for _, approver := range approvers {
+		if !containsHandle(members, approver) {
+			members = append(members, approver)
+		}
+	}
+
 	// ✅ Call service layer to validate and save invite
 	invite := models.GroupInteraction{
 		PK:              "USER#" + inviteRequest.InviterHandle,
@@ -52,17 +91,21 @@ func (c *GroupInteractionController) CreateGroupInvite(w http.ResponseWriter, r
 		GroupID:         nil, // No group ID yet
 		GroupName:       &inviteRequest.GroupName,
 		InviterHandle:   inviteRequest.InviterHandle,
-		ApproverHandle:  inviteRequest.ApproverHandle,
+		ApproverHandle:  approvers[0],
 		InviteeHandle:   inviteRequest.InviteeHandle,
-		Members:         []string{inviteRequest.InviterHandle, inviteRequest.ApproverHandle},
+		Members:         members,
 		CreatedAt:       time.Now(),
 		LastUpdated:     time.Now(),
 	}
+	if len(approvers) > 1 {
+		invite.Approvers = approvers
+		invite.ApprovalPolicy = policy
+	}
 
-	err := c.service.CreateGroupInvite(context.Background(), invite)
+	err := c.service.CreateGroupInvite(r.Context(), invite)
 	if err != nil {
 		// ✅ Handle invalid invitee case separately
-		if err.Error() == "invalid_invitee_handle" {
+		if errors.Is(err, services.ErrInvalidInvitee) {
 			http.Error(w, "Invitee handle does not exist", http.StatusNotFound)
 			return
 		}
@@ -84,14 +127,23 @@ func (c *GroupInteractionController) GetSentInvites(w http.ResponseWriter, r *ht
 		http.Error(w, "userHandle is required", http.StatusBadRequest)
 		return
 	}
+	if !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
 
-	// Fetch invites from service layer
-	invites, err := c.service.GetSentInvites(context.Background(), userHandle)
+	// Fetch invites from service layer (?limit=&cursor=, next page cursor in X-Next-Cursor)
+	limit := helpers.PageLimit(r, defaultInvitesPageSize, maxInvitesPageSize)
+	invites, nextCursor, err := c.service.GetSentInvites(r.Context(), userHandle, int32(limit), r.URL.Query().Get("cursor"))
+	if errors.Is(err, utils.ErrInvalidCursor) {
+		http.Error(w, "Invalid cursor parameter", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		http.Error(w, "Failed to fetch invites", http.StatusInternalServerError)
 		return
 	}
 
+	helpers.SetNextCursor(w, nextCursor)
 	json.NewEncoder(w).Encode(invites)
 }
 
@@ -103,14 +155,23 @@ func (c *GroupInteractionController) GetPendingApprovals(w http.ResponseWriter,
 		http.Error(w, "approverHandle is required", http.StatusBadRequest)
 		return
 	}
+	if !helpers.ActsFor(w, r, approverHandle) {
+		return
+	}
 
-	// Fetch pending approvals from service layer
-	pendingInvites, err := c.service.GetPendingApprovals(context.Background(), approverHandle)
+	// Fetch pending approvals from service layer (?limit=&cursor=, next page cursor in X-Next-Cursor)
+	limit := helpers.PageLimit(r, defaultInvitesPageSize, maxInvitesPageSize)
+	pendingInvites, nextCursor, err := c.service.GetPendingApprovals(r.Context(), approverHandle, int32(limit), r.URL.Query().Get("cursor"))
+	if errors.Is(err, utils.ErrInvalidCursor) {
+		http.Error(w, "Invalid cursor parameter", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		http.Error(w, "Failed to fetch pending approvals", http.StatusInternalServerError)
 		return
 	}
 
+	helpers.SetNextCursor(w, nextCursor)
 	json.NewEncoder(w).Encode(pendingInvites)
 }
 
@@ -134,11 +195,22 @@ func (c *GroupInteractionController) ApproveOrDeclineInvite(w http.ResponseWrite
 		http.Error(w, "Invalid status value", http.StatusBadRequest)
 		return
 	}
+	if !helpers.ActsFor(w, r, approvalRequest.ApproverHandle) {
+		return
+	}
 
 	// Call service layer to approve/decline invite
-	err := c.service.ApproveOrDeclineInvite(context.Background(), approvalRequest.ApproverHandle, approvalRequest.InviterHandle, approvalRequest.InviteeHandle, approvalRequest.Status)
+	err := c.service.ApproveOrDeclineInvite(r.Context(), approvalRequest.ApproverHandle, approvalRequest.InviterHandle, approvalRequest.InviteeHandle, approvalRequest.Status)
+	if errors.Is(err, services.ErrNotAnApprover) {
+		http.Error(w, "You are not an approver of this invite", http.StatusForbidden)
+		return
+	}
+	if errors.Is(err, services.ErrInviteResolved) {
+		http.Error(w, "Invite has already been approved or declined", http.StatusConflict)
+		return
+	}
 	if err != nil {
-		http.Error(w, "Failed to update invite status", http.StatusInternalServerError)
+		helpers.WriteError(w, r, err)
 		return
 	}
 
@@ -146,21 +218,124 @@ func (c *GroupInteractionController) ApproveOrDeclineInvite(w http.ResponseWrite
 	json.NewEncoder(w).Encode(map[string]string{"message": "Invite status updated successfully"})
 }
 
-// ✅ GetActiveGroups - Fetches all active groups for a given user
+// ✅ GetActiveGroups - Fetches one page of active groups for a user, most recently active first
+// (?limit=&cursor=, next page cursor in X-Next-Cursor); the body stays the v1 array
 func (c *GroupInteractionController) GetActiveGroups(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	userHandle := vars["userHandle"]
+	groups, nextCursor, ok := c.activeGroupsPage(w, r)
+	if !ok {
+		return
+	}
 
-	log.Printf("🔍 Fetching active groups for user: %s", userHandle)
+	// ✅ Send response
+	helpers.SetNextCursor(w, nextCursor)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(groups)
+}
 
-	groups, err := c.service.GetActiveGroups(r.Context(), userHandle)
-	if err != nil {
-		log.Printf("❌ Error fetching active groups for %s: %v", userHandle, err)
-		http.Error(w, "Failed to fetch active groups", http.StatusInternalServerError)
+// ✅ GetActiveGroupsPage - v2 of GetActiveGroups: the page and its cursor in one envelope
+func (c *GroupInteractionController) GetActiveGroupsPage(w http.ResponseWriter, r *http.Request) {
+	groups, nextCursor, ok := c.activeGroupsPage(w, r)
+	if !ok {
 		return
 	}
 
 	// ✅ Send response
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(groups)
+	json.NewEncoder(w).Encode(struct {
+		Groups     []models.GroupInteraction `json:"groups"`
+		NextCursor string                    `json:"nextCursor,omitempty"`
+	}{groups, nextCursor})
+}
+
+// activeGroupsPage loads the requested page of active groups, writing the error response if it fails
+func (c *GroupInteractionController) activeGroupsPage(w http.ResponseWriter, r *http.Request) ([]models.GroupInteraction, string, bool) {
+	userHandle := mux.Vars(r)["userHandle"]
+	if !helpers.ActsFor(w, r, userHandle) {
+		return nil, "", false
+	}
+	limit := helpers.PageLimit(r, maxActiveGroupsPageSize, maxActiveGroupsPageSize)
+
+	utils.Logf(r.Context(), "🔍 Fetching active groups for user: %s (limit=%d)", userHandle, limit)
+
+	groups, nextCursor, err := c.service.GetActiveGroups(r.Context(), userHandle, limit, r.URL.Query().Get("cursor"))
+	if errors.Is(err, utils.ErrInvalidCursor) {
+		http.Error(w, "Invalid cursor parameter", http.StatusBadRequest)
+		return nil, "", false
+	}
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Error fetching active groups for %s: %v", userHandle, err)
+		http.Error(w, "Failed to fetch active groups", http.StatusInternalServerError)
+		return nil, "", false
+	}
+	return groups, nextCursor, true
+}
+
+// ✅ LeaveGroup - Removes the user from a group and logs the departure in the group timeline
+func (c *GroupInteractionController) LeaveGroup(w http.ResponseWriter, r *http.Request) {
+	groupID := mux.Vars(r)["groupId"]
+	var request struct {
+		UserHandle string `json:"userHandle"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.UserHandle == "" {
+		http.Error(w, "userHandle is required", http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	err := c.service.LeaveGroup(r.Context(), groupID, request.UserHandle)
+	if errors.Is(err, services.ErrGroupNotFound) {
+		http.Error(w, "Group not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to leave group", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Left group successfully"})
+}
+
+// ✅ RenameGroup - Changes the group name for every member and logs the rename in the group timeline
+func (c *GroupInteractionController) RenameGroup(w http.ResponseWriter, r *http.Request) {
+	groupID := mux.Vars(r)["groupId"]
+	var request struct {
+		UserHandle string `json:"userHandle"`
+		GroupName  string `json:"groupName"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	request.GroupName = strings.TrimSpace(request.GroupName)
+	var v helpers.Validator
+	v.Required("userHandle", request.UserHandle)
+	v.Required("groupName", request.GroupName)
+	v.MaxLength("groupName", request.GroupName, helpers.MaxGroupNameLength)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	err := c.service.RenameGroup(r.Context(), groupID, request.UserHandle, request.GroupName)
+	if errors.Is(err, services.ErrGroupNotFound) {
+		http.Error(w, "Group not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to rename group", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Group renamed successfully"})
+}
+
+// containsHandle reports whether handle is already in the list
+func containsHandle(handles []string, handle string) bool {
+	for _, h := range handles {
+		if h == handle {
+			return true
+		}
+	}
+	return false
 }
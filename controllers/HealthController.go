@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"net/http"
+	"vibin_server/helpers"
+	"vibin_server/models"
+	"vibin_server/services"
+)
+
+// HealthController serves the liveness and readiness probes
+type HealthController struct {
+	HealthService *services.HealthService
+}
+
+// NewHealthController creates a new instance of HealthController
+func NewHealthController(service *services.HealthService) *HealthController {
+	return &HealthController{HealthService: service}
+}
+
+// Livez reports that the process is up; it never checks dependencies so a DynamoDB outage
+// doesn't get every instance restarted
+func (c *HealthController) Livez(w http.ResponseWriter, r *http.Request) {
+	helpers.WriteJSONResponse(w, http.StatusOK, map[string]string{"status": "healthy"})
+}
+
+// Readyz checks each dependency and answers 503 if any is failing so the load balancer drains this instance
+func (c *HealthController) Readyz(w http.ResponseWriter, r *http.Request) {
+	report := c.HealthService.CheckReadiness(r.Context())
+
+	status := http.StatusOK
+	if report.Status != models.HealthStatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	helpers.WriteJSONResponse(w, status, report)
+}
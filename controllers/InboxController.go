@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"vibin_server/helpers"
+	"vibin_server/models"
+	"vibin_server/services"
+	"vibin_server/utils"
+)
+
+// ✅ Inbox page sizes; every entry on a page is enriched with profiles and unread counts
+const (
+	defaultInboxPageSize = 30
+	maxInboxPageSize     = 100
+)
+
+// InboxController serves the unified inbox of matches and group chats
+type InboxController struct {
+	InboxService *services.InboxService
+}
+
+// NewInboxController creates a new instance of InboxController
+func NewInboxController(service *services.InboxService) *InboxController {
+	return &InboxController{InboxService: service}
+}
+
+// GetInbox returns one page of the user's matches and group chats, most recently active first
+func (c *InboxController) GetInbox(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	if userHandle == "" {
+		http.Error(w, "Missing required parameter: userhandle", http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+	limit := helpers.PageLimit(r, defaultInboxPageSize, maxInboxPageSize)
+
+	entries, nextCursor, err := c.InboxService.GetInbox(r.Context(), userHandle, limit, r.URL.Query().Get("cursor"))
+	if errors.Is(err, utils.ErrInvalidCursor) {
+		http.Error(w, "Invalid cursor parameter", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Failed to build inbox for %s: %v", userHandle, err)
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.SetNextCursor(w, nextCursor)
+	helpers.WriteJSONResponse(w, http.StatusOK, struct {
+		Conversations []models.InboxEntry `json:"conversations"`
+		NextCursor    string              `json:"nextCursor,omitempty"`
+	}{entries, nextCursor})
+}
@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"net/http"
+	"vibin_server/helpers"
+	"vibin_server/services"
+	"vibin_server/utils"
+)
+
+// InsightsController serves a user's profile performance insights
+type InsightsController struct {
+	PhotoInsightsService *services.PhotoInsightsService
+}
+
+// NewInsightsController creates a new instance of InsightsController
+func NewInsightsController(service *services.PhotoInsightsService) *InsightsController {
+	return &InsightsController{PhotoInsightsService: service}
+}
+
+// GetPhotoInsights returns likes/dislikes per profile photo and a suggested photo order
+func (c *InsightsController) GetPhotoInsights(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	if userHandle == "" {
+		http.Error(w, "Missing required parameter: userhandle", http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	insights, err := c.PhotoInsightsService.GetPhotoInsights(r.Context(), userHandle)
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Failed to fetch photo insights for %s: %v", userHandle, err)
+		http.Error(w, "Failed to fetch photo insights", http.StatusInternalServerError)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, insights)
+}
@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"net/http"
+	"vibin_server/helpers"
+	"vibin_server/services"
+)
+
+// ✅ Interaction audit page size defaults and caps
+const (
+	defaultInteractionAuditPageSize = 100
+	maxInteractionAuditPageSize     = 500
+)
+
+// InteractionAuditController lets support read the history of interactions between two users
+type InteractionAuditController struct {
+	InteractionService *services.InteractionService
+}
+
+// NewInteractionAuditController creates a new instance of InteractionAuditController
+func NewInteractionAuditController(service *services.InteractionService) *InteractionAuditController {
+	return &InteractionAuditController{InteractionService: service}
+}
+
+// GetAudit returns the status changes between two users, oldest first (?userA=&userB=&limit=&cursor=, admin)
+func (c *InteractionAuditController) GetAudit(w http.ResponseWriter, r *http.Request) {
+	userA := r.URL.Query().Get("userA")
+	userB := r.URL.Query().Get("userB")
+	var v helpers.Validator
+	v.Handle("userA", userA)
+	v.Handle("userB", userB)
+	if v.WriteErrors(w) {
+		return
+	}
+
+	limit := helpers.PageLimit(r, defaultInteractionAuditPageSize, maxInteractionAuditPageSize)
+	entries, nextCursor, err := c.InteractionService.GetInteractionAudit(r.Context(), userA, userB, int32(limit), r.URL.Query().Get("cursor"))
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.SetNextCursor(w, nextCursor)
+	helpers.WriteJSONResponse(w, http.StatusOK, entries)
+}
@@ -1,19 +1,37 @@
 package controllers
 
 import (
-	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"vibin_server/helpers"
 	"vibin_server/models"
 	"vibin_server/services"
+	"vibin_server/utils"
+
+	"github.com/gorilla/mux"
 )
 
+// maxMatchesPageSize caps how many matches a single page may return
+const maxMatchesPageSize = 100
+
+// defaultMatchesPageSize is the page size when ?limit= is omitted; each match on a page is enriched
+// with a profile and last message, so large default pages are slow for users with many matches
+const defaultMatchesPageSize = 30
+
+// maxInteractionsPageSize is the default and maximum page size for sent/received interactions
+const maxInteractionsPageSize = 100
+
 // InteractionController handles API requests related to interactions
 type InteractionController struct {
 	InteractionService *services.InteractionService
+	Captcha            *services.CaptchaService // ✅ Challenge for pings the spam detector flags
+	Spam               *services.SpamDetector
 }
 
 // CreateInteractionHandler processes interaction requests (like, ping, approval, etc.)
@@ -21,30 +39,52 @@ func (c *InteractionController) CreateInteractionHandler(w http.ResponseWriter,
 	var request struct {
 		SenderHandle    string  `json:"senderHandle"`
 		ReceiverHandle  string  `json:"receiverHandle"`
-		InteractionType string  `json:"interactionType"` // like, ping, invite
-		Action          string  `json:"action"`          // like, dislike, approve, reject
+		InteractionType string  `json:"interactionType"` // like, ping, invite, save
+		Action          string  `json:"action"`          // like, dislike, save, approve, reject
 		Message         *string `json:"message,omitempty"`
+		PhotoIndex      *int    `json:"photoIndex,omitempty"` // Photo on screen when liking/disliking
 	}
 
 	// Decode request body
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		log.Println("❌ Invalid request payload:", err)
+		utils.Logln(r.Context(), "❌ Invalid request payload:", err)
 		http.Error(w, "Invalid request payload", http.StatusBadRequest)
 		return
 	}
 
 	// Validate required fields
-	if request.SenderHandle == "" || request.ReceiverHandle == "" || request.InteractionType == "" || request.Action == "" {
-		log.Println("⚠️ Missing required fields in request")
-		http.Error(w, "Missing required fields", http.StatusBadRequest)
+	var v helpers.Validator
+	v.Required("senderHandle", request.SenderHandle)
+	v.Required("receiverHandle", request.ReceiverHandle)
+	v.Required("interactionType", request.InteractionType)
+	v.Required("action", request.Action)
+	v.Check(request.SenderHandle == "" || request.SenderHandle != request.ReceiverHandle, "receiverHandle", "must differ from senderHandle")
+	v.Check(request.PhotoIndex == nil || (*request.PhotoIndex >= 0 && *request.PhotoIndex < helpers.MaxPhotos), "photoIndex", "must be a valid photo position")
+	if request.Message != nil {
+		v.MaxLength("message", *request.Message, helpers.MaxMessageLength)
+	}
+	if v.WriteErrors(w) {
+		utils.Logf(r.Context(), "⚠️ Invalid interaction request: %+v", v.Errors)
 		return
 	}
-	log.Printf("🔍 Received interaction request: Sender=%s, Receiver=%s, Type=%s, Action=%s",
+	if !helpers.ActsFor(w, r, request.SenderHandle) {
+		return
+	}
+	utils.Logf(r.Context(), "🔍 Received interaction request: Sender=%s, Receiver=%s, Type=%s, Action=%s",
 		request.SenderHandle, request.ReceiverHandle, request.InteractionType, request.Action)
 
-	// Set a timeout for database operations
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := r.Context()
+
+	// ✅ High-risk pings need a solved challenge, when the environment requires one
+	if request.Action == "ping" && c.Captcha.Requires(models.CaptchaActionPing) {
+		if signal := c.Spam.PingRisk(ctx, request.SenderHandle, request.Message); signal != "" {
+			utils.Logf(ctx, "🤖 Ping from %s flagged (%s), checking challenge", request.SenderHandle, signal)
+			if err := c.Captcha.Verify(ctx, models.CaptchaActionPing, r.Header.Get(models.CaptchaTokenHeader), helpers.ClientIP(r)); err != nil {
+				helpers.WriteError(w, r, err)
+				return
+			}
+		}
+	}
 
 	// Process interaction dynamically
 	isMatch, matchedProfile, err := c.InteractionService.CreateOrUpdateInteraction(
@@ -53,11 +93,22 @@ func (c *InteractionController) CreateInteractionHandler(w http.ResponseWriter,
 		request.ReceiverHandle,
 		request.InteractionType,
 		request.Action,
-		request.Message, // Pass optional message if available
+		request.Message,    // Pass optional message if available
+		request.PhotoIndex, // Pass optional photo position for swipe analytics
 	)
+	if errors.Is(err, services.ErrLikeLimitReached) {
+		http.Error(w, "Daily like limit reached. Upgrade to premium for unlimited likes.", http.StatusTooManyRequests)
+		return
+	}
+	var quotaErr *services.QuotaError
+	if errors.As(err, &quotaErr) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(quotaErr.RetryAt).Seconds())+1))
+		helpers.WriteJSONResponse(w, http.StatusTooManyRequests, quotaErr)
+		return
+	}
 	if err != nil {
-		log.Printf("❌ Failed to process interaction: %v", err)
-		http.Error(w, "Failed to process interaction: "+err.Error(), http.StatusInternalServerError)
+		utils.Logf(r.Context(), "❌ Failed to process interaction: %v", err)
+		helpers.WriteError(w, r, err)
 		return
 	}
 
@@ -79,21 +130,23 @@ func (c *InteractionController) ApprovePingHandler(w http.ResponseWriter, r *htt
 
 	// Decode request body
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		log.Println("❌ Invalid approve ping request:", err)
+		utils.Logln(r.Context(), "❌ Invalid approve ping request:", err)
 		http.Error(w, "Invalid request payload", http.StatusBadRequest)
 		return
 	}
 
-	log.Printf("✅ Approving ping from %s -> %s", request.SenderHandle, request.ReceiverHandle)
+	if !helpers.ActsFor(w, r, request.ReceiverHandle) { // ✅ Only the ping's receiver approves it
+		return
+	}
+
+	utils.Logf(r.Context(), "✅ Approving ping from %s -> %s", request.SenderHandle, request.ReceiverHandle)
 
-	// Process approval in InteractionService
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := r.Context()
 
 	err := c.InteractionService.HandlePingApproval(ctx, request.SenderHandle, request.ReceiverHandle)
 	if err != nil {
-		log.Printf("❌ Failed to approve ping: %v", err)
-		http.Error(w, "Failed to approve ping: "+err.Error(), http.StatusInternalServerError)
+		utils.Logf(r.Context(), "❌ Failed to approve ping: %v", err)
+		helpers.WriteError(w, r, err)
 		return
 	}
 
@@ -111,21 +164,23 @@ func (c *InteractionController) DeclinePingHandler(w http.ResponseWriter, r *htt
 
 	// Decode request body
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		log.Println("❌ Invalid decline ping request:", err)
+		utils.Logln(r.Context(), "❌ Invalid decline ping request:", err)
 		http.Error(w, "Invalid request payload", http.StatusBadRequest)
 		return
 	}
 
-	log.Printf("🚫 Declining ping from %s -> %s", request.SenderHandle, request.ReceiverHandle)
+	if !helpers.ActsFor(w, r, request.ReceiverHandle) {
+		return
+	}
 
-	// Process decline in InteractionService
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	utils.Logf(r.Context(), "🚫 Declining ping from %s -> %s", request.SenderHandle, request.ReceiverHandle)
+
+	ctx := r.Context()
 
 	err := c.InteractionService.HandlePingDecline(ctx, request.SenderHandle, request.ReceiverHandle)
 	if err != nil {
-		log.Printf("❌ Failed to decline ping: %v", err)
-		http.Error(w, "Failed to decline ping: "+err.Error(), http.StatusInternalServerError)
+		utils.Logf(r.Context(), "❌ Failed to decline ping: %v", err)
+		helpers.WriteError(w, r, err)
 		return
 	}
 
@@ -136,41 +191,74 @@ func (c *InteractionController) DeclinePingHandler(w http.ResponseWriter, r *htt
 	json.NewEncoder(w).Encode(response)
 }
 
-// GetMutualMatchesHandler fetches all mutual matches for a user
+// GetMatchDetailHandler returns one match with the other user's profile, pinned messages, unread count
+// and conversation settings
+func (c *InteractionController) GetMatchDetailHandler(w http.ResponseWriter, r *http.Request) {
+	matchID := mux.Vars(r)["matchId"]
+	userHandle := r.URL.Query().Get("userHandle")
+	var v helpers.Validator
+	v.Handle("userHandle", userHandle)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	detail, err := c.InteractionService.GetMatchDetail(r.Context(), matchID, userHandle)
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Failed to fetch match %s for %s: %v", matchID, userHandle, err)
+		helpers.WriteError(w, r, err)
+		return
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, detail)
+}
+
+// GetMutualMatchesHandler fetches one page of mutual matches for a user (?limit=&cursor=); only the
+// requested page is enriched, so pass nextCursor back to load more
 func (c *InteractionController) GetMutualMatchesHandler(w http.ResponseWriter, r *http.Request) {
 	userHandle := r.URL.Query().Get("userHandle")
+	cursor := r.URL.Query().Get("cursor")
 
 	// Validate input
 	if userHandle == "" {
 		http.Error(w, "Missing userHandle parameter", http.StatusBadRequest)
 		return
 	}
+	if !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	// ✅ Default 30, max 100
+	limit := helpers.PageLimit(r, defaultMatchesPageSize, maxMatchesPageSize)
+	unreadFirst := r.URL.Query().Get("unreadFirst") == "true" // ✅ Matches with unread messages before the rest of the page
 
-	// Set a timeout for database operations
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := r.Context()
 
 	// Fetch mutual matches (with minimal profile data)
-	matches, err := c.InteractionService.GetMutualMatches(ctx, userHandle)
+	matches, nextCursor, err := c.InteractionService.GetMutualMatches(ctx, userHandle, unreadFirst, int32(limit), cursor)
+	if errors.Is(err, utils.ErrInvalidCursor) {
+		http.Error(w, "Invalid cursor parameter", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
-		log.Printf("❌ Failed to fetch mutual matches for %s: %v", userHandle, err)
-		http.Error(w, "Failed to fetch mutual matches: "+err.Error(), http.StatusInternalServerError)
+		utils.Logf(r.Context(), "❌ Failed to fetch mutual matches for %s: %v", userHandle, err)
+		helpers.WriteError(w, r, err)
 		return
 	}
 
 	// Convert to JSON and send response
+	helpers.SetNextCursor(w, nextCursor)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	if matches == nil {
 		matches = []models.MatchedUserDetailsForConnections{}
 	}
 	json.NewEncoder(w).Encode(struct {
-		Matches []models.MatchedUserDetailsForConnections `json:"matches"`
-	}{matches})
+		Matches    []models.MatchedUserDetailsForConnections `json:"matches"`
+		NextCursor string                                    `json:"nextCursor,omitempty"`
+	}{matches, nextCursor})
 
 }
 
-// GetSentInteractionsHandler fetches all sent interactions for a user
+// GetSentInteractionsHandler fetches one page of sent interactions for a user
 func (c *InteractionController) GetSentInteractionsHandler(w http.ResponseWriter, r *http.Request) {
 	userHandle := r.URL.Query().Get("userHandle")
 
@@ -179,15 +267,27 @@ func (c *InteractionController) GetSentInteractionsHandler(w http.ResponseWriter
 		http.Error(w, "Missing userHandle parameter", http.StatusBadRequest)
 		return
 	}
+	if !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	ctx := r.Context()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	filter, ok := parseInteractionFilter(w, r)
+	if !ok {
+		return
+	}
 
-	// Fetch sent interactions with user profile data
-	interactions, err := c.InteractionService.GetUserInteractions(ctx, userHandle)
+	// Fetch sent interactions with user profile data (?interactionType=&status=&limit=&cursor=)
+	limit := helpers.PageLimit(r, maxInteractionsPageSize, maxInteractionsPageSize)
+	interactions, nextCursor, err := c.InteractionService.GetUserInteractions(ctx, userHandle, filter, int32(limit), r.URL.Query().Get("cursor"))
+	if errors.Is(err, utils.ErrInvalidCursor) {
+		http.Error(w, "Invalid cursor parameter", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
-		log.Printf("❌ Failed to fetch sent interactions for %s: %v", userHandle, err)
-		http.Error(w, "Failed to fetch interactions: "+err.Error(), http.StatusInternalServerError)
+		utils.Logf(r.Context(), "❌ Failed to fetch sent interactions for %s: %v", userHandle, err)
+		helpers.WriteError(w, r, err)
 		return
 	}
 
@@ -196,10 +296,11 @@ func (c *InteractionController) GetSentInteractionsHandler(w http.ResponseWriter
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(struct {
 		Interactions []models.InteractionWithProfile `json:"interactions"`
-	}{interactions})
+		NextCursor   string                          `json:"nextCursor,omitempty"`
+	}{interactions, nextCursor})
 }
 
-// GetReceivedInteractionsHandler fetches all received interactions for a user
+// GetReceivedInteractionsHandler fetches one page of received interactions for a user
 func (c *InteractionController) GetReceivedInteractionsHandler(w http.ResponseWriter, r *http.Request) {
 	userHandle := r.URL.Query().Get("userHandle")
 
@@ -208,15 +309,27 @@ func (c *InteractionController) GetReceivedInteractionsHandler(w http.ResponseWr
 		http.Error(w, "Missing userHandle parameter", http.StatusBadRequest)
 		return
 	}
+	if !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := r.Context()
 
-	// Fetch received interactions with user profile data
-	interactions, err := c.InteractionService.GetReceivedInteractions(ctx, userHandle)
+	filter, ok := parseInteractionFilter(w, r)
+	if !ok {
+		return
+	}
+
+	// Fetch received interactions with user profile data (?interactionType=&status=&limit=&cursor=)
+	limit := helpers.PageLimit(r, maxInteractionsPageSize, maxInteractionsPageSize)
+	interactions, nextCursor, err := c.InteractionService.GetReceivedInteractions(ctx, userHandle, filter, int32(limit), r.URL.Query().Get("cursor"))
+	if errors.Is(err, utils.ErrInvalidCursor) {
+		http.Error(w, "Invalid cursor parameter", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
-		log.Printf("❌ Failed to fetch received interactions for %s: %v", userHandle, err)
-		http.Error(w, "Failed to fetch interactions: "+err.Error(), http.StatusInternalServerError)
+		utils.Logf(r.Context(), "❌ Failed to fetch received interactions for %s: %v", userHandle, err)
+		helpers.WriteError(w, r, err)
 		return
 	}
 
@@ -225,5 +338,211 @@ func (c *InteractionController) GetReceivedInteractionsHandler(w http.ResponseWr
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(struct {
 		Interactions []models.InteractionWithProfile `json:"interactions"`
-	}{interactions})
+		NextCursor   string                          `json:"nextCursor,omitempty"`
+	}{interactions, nextCursor})
+}
+
+// parseInteractionFilter reads the comma-separated ?interactionType= and ?status= feed filters,
+// writing a 400 and returning false when a value is unknown
+func parseInteractionFilter(w http.ResponseWriter, r *http.Request) (models.InteractionFilter, bool) {
+	var filter models.InteractionFilter
+	var v helpers.Validator
+	if raw := r.URL.Query().Get("interactionType"); raw != "" {
+		filter.Types = strings.Split(raw, ",")
+		v.MaxItems("interactionType", len(filter.Types), len(models.InteractionTypes))
+		for _, interactionType := range filter.Types {
+			v.OneOf("interactionType", interactionType, models.InteractionTypes...)
+		}
+	}
+	if raw := r.URL.Query().Get("status"); raw != "" {
+		filter.Statuses = strings.Split(raw, ",")
+		v.MaxItems("status", len(filter.Statuses), len(models.InteractionStatuses))
+		for _, status := range filter.Statuses {
+			v.OneOf("status", status, models.InteractionStatuses...)
+		}
+	}
+	return filter, !v.WriteErrors(w)
+}
+
+// GetSecondLookHandler fetches one page of received likes the user never acted on
+// (?userHandle=&olderThanDays=&limit=&cursor=)
+func (c *InteractionController) GetSecondLookHandler(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userHandle")
+	days := models.DefaultSecondLookDays
+	var v helpers.Validator
+	v.Handle("userHandle", userHandle)
+	if raw := r.URL.Query().Get("olderThanDays"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		v.Check(err == nil && parsed >= 1 && parsed <= models.MaxSecondLookDays, "olderThanDays", fmt.Sprintf("must be a whole number of days between 1 and %d", models.MaxSecondLookDays))
+		days = parsed
+	}
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	limit := helpers.PageLimit(r, maxInteractionsPageSize, maxInteractionsPageSize)
+	interactions, nextCursor, err := c.InteractionService.GetSecondLookLikes(r.Context(), userHandle, time.Duration(days)*24*time.Hour, int32(limit), r.URL.Query().Get("cursor"))
+	if errors.Is(err, utils.ErrInvalidCursor) {
+		http.Error(w, "Invalid cursor parameter", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, struct {
+		Interactions []models.InteractionWithProfile `json:"interactions"`
+		NextCursor   string                          `json:"nextCursor,omitempty"`
+	}{interactions, nextCursor})
+}
+
+// EngageSecondLookHandler records that the user opened a resurfaced like, re-notifying its sender
+func (c *InteractionController) EngageSecondLookHandler(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserHandle   string `json:"userHandle"`
+		SenderHandle string `json:"senderHandle"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Handle("userHandle", request.UserHandle)
+	v.Handle("senderHandle", request.SenderHandle)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	if err := c.InteractionService.EngageSecondLook(r.Context(), request.UserHandle, request.SenderHandle); err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetPassedHandler fetches one page of the profiles the user disliked (?userHandle=&limit=&cursor=)
+func (c *InteractionController) GetPassedHandler(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userHandle")
+	var v helpers.Validator
+	v.Handle("userHandle", userHandle)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	limit := helpers.PageLimit(r, maxInteractionsPageSize, maxInteractionsPageSize)
+	interactions, nextCursor, err := c.InteractionService.GetPassedProfiles(r.Context(), userHandle, int32(limit), r.URL.Query().Get("cursor"))
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, struct {
+		Interactions []models.InteractionWithProfile `json:"interactions"`
+		NextCursor   string                          `json:"nextCursor,omitempty"`
+	}{interactions, nextCursor})
+}
+
+// ReconsiderHandler flips a dislike from the passed list back to neutral (premium)
+func (c *InteractionController) ReconsiderHandler(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserHandle     string `json:"userHandle"`
+		ReceiverHandle string `json:"receiverHandle"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Handle("userHandle", request.UserHandle)
+	v.Handle("receiverHandle", request.ReceiverHandle)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	err := c.InteractionService.ReconsiderPass(r.Context(), request.UserHandle, request.ReceiverHandle)
+	if errors.Is(err, services.ErrPremiumRequired) {
+		http.Error(w, "Reconsidering passes is a premium feature", http.StatusPaymentRequired)
+		return
+	}
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetSavedHandler fetches one page of the profiles the user saved for later (?userHandle=&limit=&cursor=)
+func (c *InteractionController) GetSavedHandler(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userHandle")
+	var v helpers.Validator
+	v.Handle("userHandle", userHandle)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	limit := helpers.PageLimit(r, maxInteractionsPageSize, maxInteractionsPageSize)
+	interactions, nextCursor, err := c.InteractionService.GetSavedProfiles(r.Context(), userHandle, int32(limit), r.URL.Query().Get("cursor"))
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, struct {
+		Interactions []models.InteractionWithProfile `json:"interactions"`
+		NextCursor   string                          `json:"nextCursor,omitempty"`
+	}{interactions, nextCursor})
+}
+
+// RemoveSavedHandler takes a profile off the saved list (?userHandle=&receiverHandle=)
+func (c *InteractionController) RemoveSavedHandler(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userHandle")
+	receiverHandle := r.URL.Query().Get("receiverHandle")
+	var v helpers.Validator
+	v.Handle("userHandle", userHandle)
+	v.Handle("receiverHandle", receiverHandle)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	if err := c.InteractionService.RemoveSaved(r.Context(), userHandle, receiverHandle); err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RewindHandler undoes the user's most recent dislike (premium only)
+func (c *InteractionController) RewindHandler(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserHandle string `json:"userHandle"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.UserHandle == "" {
+		http.Error(w, "Missing userHandle", http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	rewoundHandle, err := c.InteractionService.RewindLastDislike(r.Context(), request.UserHandle)
+	if errors.Is(err, services.ErrPremiumRequired) {
+		http.Error(w, "Rewind is a premium feature", http.StatusPaymentRequired)
+		return
+	}
+	if errors.Is(err, services.ErrNothingToRewind) {
+		http.Error(w, "Nothing to rewind", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Failed to rewind for %s: %v", request.UserHandle, err)
+		http.Error(w, "Failed to rewind", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"rewoundHandle": rewoundHandle})
 }
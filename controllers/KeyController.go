@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+	"vibin_server/helpers"
+	"vibin_server/models"
+	"vibin_server/services"
+)
+
+// KeyController publishes and hands out the public keys of end-to-end encrypted chats
+type KeyController struct {
+	KeyService *services.KeyService
+}
+
+// NewKeyController creates a new instance of KeyController
+func NewKeyController(service *services.KeyService) *KeyController {
+	return &KeyController{KeyService: service}
+}
+
+// PublishBundle stores a device's identity key, signed prekey and one-time prekeys
+func (c *KeyController) PublishBundle(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		models.KeyBundle
+		OneTimePreKeys []models.OneTimePreKey `json:"oneTimePreKeys"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Handle("userhandle", request.UserHandle)
+	v.Check(models.DeviceIDPattern.MatchString(request.DeviceID), "deviceId", "must be 1-64 letters, digits, - or _")
+	validatePublicKey(&v, "identityKey", request.IdentityKey)
+	validatePublicKey(&v, "signedPreKey.publicKey", request.SignedPreKey.PublicKey)
+	validatePublicKey(&v, "signedPreKey.signature", request.SignedPreKey.Signature)
+	validatePreKeys(&v, request.OneTimePreKeys)
+	if v.WriteErrors(w) || !requireSessionUser(w, r, request.UserHandle) {
+		return
+	}
+
+	count, err := c.KeyService.PublishBundle(r.Context(), request.KeyBundle, request.OneTimePreKeys)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	writePreKeyCount(w, count)
+}
+
+// AddPreKeys replenishes a device's one-time prekeys
+func (c *KeyController) AddPreKeys(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserHandle     string                 `json:"userhandle"`
+		DeviceID       string                 `json:"deviceId"`
+		OneTimePreKeys []models.OneTimePreKey `json:"oneTimePreKeys"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Handle("userhandle", request.UserHandle)
+	v.Required("deviceId", request.DeviceID)
+	v.Check(len(request.OneTimePreKeys) > 0, "oneTimePreKeys", "must not be empty")
+	validatePreKeys(&v, request.OneTimePreKeys)
+	if v.WriteErrors(w) || !requireSessionUser(w, r, request.UserHandle) {
+		return
+	}
+
+	count, err := c.KeyService.AddPreKeys(r.Context(), request.UserHandle, request.DeviceID, request.OneTimePreKeys)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	writePreKeyCount(w, count)
+}
+
+// GetPreKeyCount reports how many one-time prekeys a device has left (?userhandle=&deviceId=)
+func (c *KeyController) GetPreKeyCount(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	deviceID := r.URL.Query().Get("deviceId")
+	if userHandle == "" || deviceID == "" {
+		http.Error(w, "Missing required parameters: userhandle, deviceId", http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	count, err := c.KeyService.PreKeyCount(r.Context(), userHandle, deviceID)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	writePreKeyCount(w, count)
+}
+
+// FetchBundles returns the key bundles of every device of a peer, claiming a one-time prekey for
+// each (?userhandle=&peerHandle=)
+func (c *KeyController) FetchBundles(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	peerHandle := r.URL.Query().Get("peerHandle")
+	if userHandle == "" || peerHandle == "" {
+		http.Error(w, "Missing required parameters: userhandle, peerHandle", http.StatusBadRequest)
+		return
+	}
+	if !requireSessionUser(w, r, userHandle) {
+		return
+	}
+
+	bundles, err := c.KeyService.FetchBundles(r.Context(), userHandle, peerHandle)
+	var quotaErr *services.QuotaError
+	if errors.As(err, &quotaErr) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(quotaErr.RetryAt).Seconds())+1))
+		helpers.WriteJSONResponse(w, http.StatusTooManyRequests, quotaErr)
+		return
+	}
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{"bundles": bundles})
+}
+
+// RemoveDevice deletes a device's keys (?userhandle=&deviceId=)
+func (c *KeyController) RemoveDevice(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	deviceID := r.URL.Query().Get("deviceId")
+	if userHandle == "" || deviceID == "" {
+		http.Error(w, "Missing required parameters: userhandle, deviceId", http.StatusBadRequest)
+		return
+	}
+	if !requireSessionUser(w, r, userHandle) {
+		return
+	}
+
+	if err := c.KeyService.RemoveDevice(r.Context(), userHandle, deviceID); err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// requireSessionUser refuses key changes and fetches unless the request carries a session of
+// userHandle; a body or query handle alone proves nothing. Writes the error and returns false if not.
+func requireSessionUser(w http.ResponseWriter, r *http.Request, userHandle string) bool {
+	session := helpers.SessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "A session token is required", http.StatusUnauthorized)
+		return false
+	}
+	if session.UserHandle != userHandle {
+		http.Error(w, "The session belongs to another user", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// validatePublicKey checks a base64 key or signature; its contents are opaque to the server
+func validatePublicKey(v *helpers.Validator, field, value string) {
+	v.Required(field, value)
+	v.MaxLength(field, value, models.MaxPublicKeyLength)
+}
+
+// validatePreKeys checks an upload of one-time prekeys
+func validatePreKeys(v *helpers.Validator, preKeys []models.OneTimePreKey) {
+	v.MaxItems("oneTimePreKeys", len(preKeys), models.MaxOneTimePreKeysUpload)
+	for _, preKey := range preKeys {
+		if preKey.KeyID < 0 || preKey.PublicKey == "" || len(preKey.PublicKey) > models.MaxPublicKeyLength {
+			v.Check(false, "oneTimePreKeys", fmt.Sprintf("need a non-negative keyId and a publicKey of at most %d characters", models.MaxPublicKeyLength))
+			break
+		}
+	}
+}
+
+// writePreKeyCount reports a device's remaining one-time prekeys and whether to upload more
+func writePreKeyCount(w http.ResponseWriter, count int) {
+	helpers.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"oneTimePreKeys": count,
+		"replenish":      count < models.LowOneTimePreKeyCount,
+	})
+}
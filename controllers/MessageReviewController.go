@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"vibin_server/helpers"
+	"vibin_server/models"
+	"vibin_server/services"
+)
+
+// ✅ Review queue page size defaults and caps
+const (
+	defaultMessageReviewsPageSize = 50
+	maxMessageReviewsPageSize     = 200
+)
+
+// MessageReviewController exposes the admin queue of messages flagged by the safety classifier
+type MessageReviewController struct {
+	MessageSafetyService *services.MessageSafetyService
+}
+
+// NewMessageReviewController creates a new instance of MessageReviewController
+func NewMessageReviewController(service *services.MessageSafetyService) *MessageReviewController {
+	return &MessageReviewController{MessageSafetyService: service}
+}
+
+// ListReviews returns flagged messages, oldest first (?status=pending|upheld|dismissed&limit=&cursor=, admin)
+func (c *MessageReviewController) ListReviews(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = models.MessageReviewPending
+	}
+	var v helpers.Validator
+	v.OneOf("status", status, models.MessageReviewPending, models.MessageReviewUpheld, models.MessageReviewDismissed)
+	if v.WriteErrors(w) {
+		return
+	}
+
+	limit := helpers.PageLimit(r, defaultMessageReviewsPageSize, maxMessageReviewsPageSize)
+	reviews, nextCursor, err := c.MessageSafetyService.ListReviews(r.Context(), status, int32(limit), r.URL.Query().Get("cursor"))
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.SetNextCursor(w, nextCursor)
+	helpers.WriteJSONResponse(w, http.StatusOK, reviews)
+}
+
+// ResolveReview upholds (keeps hidden) or dismisses (shows again) a flagged message (admin)
+func (c *MessageReviewController) ResolveReview(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		MatchID   string `json:"matchId"`
+		CreatedAt string `json:"createdAt"`
+		Decision  string `json:"decision"` // "upheld" or "dismissed"
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Required("matchId", request.MatchID)
+	v.Required("createdAt", request.CreatedAt)
+	v.OneOf("decision", request.Decision, models.MessageReviewUpheld, models.MessageReviewDismissed)
+	if v.WriteErrors(w) {
+		return
+	}
+
+	review, err := c.MessageSafetyService.ResolveReview(r.Context(), request.MatchID, request.CreatedAt, request.Decision, helpers.AdminHandle(r))
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, review)
+}
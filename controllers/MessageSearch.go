@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+	"vibin_server/models"
+)
+
+// ✅ Message search defaults and caps
+const (
+	defaultSearchLimit   = 20
+	maxSearchLimit       = 100
+	defaultSearchContext = 2
+	maxSearchContext     = 10
+)
+
+// parseMessageSearchQuery reads q, from, to, context and limit from the query string.
+// from/to accept RFC3339 timestamps or YYYY-MM-DD dates (to is inclusive of the whole day).
+func parseMessageSearchQuery(r *http.Request) (models.MessageSearchQuery, error) {
+	params := r.URL.Query()
+	query := models.MessageSearchQuery{
+		Keyword:     params.Get("q"),
+		ContextSize: defaultSearchContext,
+		Limit:       defaultSearchLimit,
+	}
+	if query.Keyword == "" {
+		return query, errors.New("q is required")
+	}
+
+	var err error
+	if query.From, err = parseSearchBound(params.Get("from"), false); err != nil {
+		return query, errors.New("from must be RFC3339 or YYYY-MM-DD")
+	}
+	if query.To, err = parseSearchBound(params.Get("to"), true); err != nil {
+		return query, errors.New("to must be RFC3339 or YYYY-MM-DD")
+	}
+	if query.From != "" && query.To != "" && query.From > query.To {
+		return query, errors.New("from must not be after to")
+	}
+
+	if v := params.Get("context"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return query, errors.New("context must be a non-negative integer")
+		}
+		query.ContextSize = min(n, maxSearchContext)
+	}
+	if v := params.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return query, errors.New("limit must be a positive integer")
+		}
+		query.Limit = min(n, maxSearchLimit)
+	}
+	return query, nil
+}
+
+// parseSearchBound normalizes a date bound to the RFC3339 form messages are stored with
+func parseSearchBound(value string, endOfDay bool) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.Format(time.RFC3339), nil
+	}
+	day, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return "", err
+	}
+	if endOfDay {
+		day = day.Add(24*time.Hour - time.Second)
+	}
+	return day.Format(time.RFC3339), nil
+}
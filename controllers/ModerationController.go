@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"vibin_server/helpers"
+	"vibin_server/models"
+	"vibin_server/services"
+	"vibin_server/utils"
+)
+
+// ModerationController exposes admin operations on moderation rules
+type ModerationController struct {
+	ModerationService *services.ModerationService
+}
+
+// NewModerationController creates a new instance of ModerationController
+func NewModerationController(service *services.ModerationService) *ModerationController {
+	return &ModerationController{ModerationService: service}
+}
+
+// GetRules returns the latest published rule set and the version currently loaded in memory
+func (c *ModerationController) GetRules(w http.ResponseWriter, r *http.Request) {
+	ruleSet, err := c.ModerationService.GetLatestRuleSet(r.Context())
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Failed to fetch moderation rules: %v", err)
+		http.Error(w, "Failed to fetch moderation rules", http.StatusInternalServerError)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"ruleSet":       ruleSet,
+		"loadedVersion": c.ModerationService.CurrentVersion(),
+	})
+}
+
+// UpdateRules publishes a new rule set version; other instances pick it up on their next reload
+func (c *ModerationController) UpdateRules(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Keywords     []string                   `json:"keywords"`
+		Patterns     []string                   `json:"patterns"`
+		FirstMessage *models.FirstMessagePolicy `json:"firstMessage,omitempty"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	ruleSet, err := c.ModerationService.PublishRuleSet(r.Context(), request.Keywords, request.Patterns, request.FirstMessage, helpers.AdminHandle(r))
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Failed to publish moderation rules: %v", err)
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, ruleSet)
+}
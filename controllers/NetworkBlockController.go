@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"vibin_server/helpers"
+	"vibin_server/models"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+// NetworkBlockController manages the CIDR, ASN and country blocklists (admin)
+type NetworkBlockController struct {
+	NetworkGuardService *services.NetworkGuardService
+}
+
+// NewNetworkBlockController creates a new instance of NetworkBlockController
+func NewNetworkBlockController(service *services.NetworkGuardService) *NetworkBlockController {
+	return &NetworkBlockController{NetworkGuardService: service}
+}
+
+// ListBlocks returns every active network block (admin)
+func (c *NetworkBlockController) ListBlocks(w http.ResponseWriter, r *http.Request) {
+	blocks, err := c.NetworkGuardService.ListBlocks(r.Context())
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, blocks)
+}
+
+// AddBlock blocks a CIDR range, ASN or country, permanently or until a given time (admin)
+func (c *NetworkBlockController) AddBlock(w http.ResponseWriter, r *http.Request) {
+	var request models.NetworkBlockRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.OneOf("kind", request.Kind, models.NetworkBlockKinds...)
+	v.Required("value", request.Value)
+	v.MaxLength("reason", request.Reason, models.MaxNetworkBlockReasonLength)
+	if v.WriteErrors(w) {
+		return
+	}
+	request.CreatedBy = helpers.AdminHandle(r)
+
+	block, err := c.NetworkGuardService.AddBlock(r.Context(), request)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusCreated, block)
+}
+
+// RemoveBlock lifts a network block (admin)
+func (c *NetworkBlockController) RemoveBlock(w http.ResponseWriter, r *http.Request) {
+	blockID := mux.Vars(r)["blockId"]
+	var v helpers.Validator
+	v.Required("blockId", blockID)
+	if v.WriteErrors(w) {
+		return
+	}
+
+	if err := c.NetworkGuardService.RemoveBlock(r.Context(), blockID); err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"vibin_server/helpers"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+// OpenerController serves generated conversation openers for matches
+type OpenerController struct {
+	OpenerService *services.OpenerService
+}
+
+// NewOpenerController creates a new instance of OpenerController
+func NewOpenerController(service *services.OpenerService) *OpenerController {
+	return &OpenerController{OpenerService: service}
+}
+
+// GetOpeners returns 2-3 suggested first messages for the user in a match (?userHandle=)
+func (c *OpenerController) GetOpeners(w http.ResponseWriter, r *http.Request) {
+	matchID := mux.Vars(r)["matchId"]
+	userHandle := r.URL.Query().Get("userHandle")
+	if userHandle == "" {
+		http.Error(w, "Missing required parameter: userHandle", http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	openers, err := c.OpenerService.SuggestOpeners(r.Context(), matchID, userHandle)
+	if errors.Is(err, services.ErrOpenersUnavailable) {
+		http.Error(w, "Openers are not available", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, openers)
+}
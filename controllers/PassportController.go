@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"vibin_server/helpers"
+	"vibin_server/models"
+	"vibin_server/services"
+)
+
+// maxCityLength caps the display name of a passport city
+const maxCityLength = 100
+
+// PassportController sets and clears the premium virtual location
+type PassportController struct {
+	PassportService *services.PassportService
+}
+
+// NewPassportController creates a new instance of PassportController
+func NewPassportController(service *services.PassportService) *PassportController {
+	return &PassportController{PassportService: service}
+}
+
+// SetPassport browses suggestions from the given city for up to MaxPassportDays (premium only)
+func (c *PassportController) SetPassport(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserHandle string   `json:"userhandle"`
+		City       string   `json:"city"`
+		Latitude   *float64 `json:"latitude"` // The city's coordinates, from the app's city picker
+		Longitude  *float64 `json:"longitude"`
+		Days       int      `json:"days"` // Defaults to MaxPassportDays
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if request.Days == 0 {
+		request.Days = models.MaxPassportDays
+	}
+	var v helpers.Validator
+	v.Handle("userhandle", request.UserHandle)
+	v.Required("city", request.City)
+	v.MaxLength("city", request.City, maxCityLength)
+	v.Check(request.Latitude != nil && *request.Latitude >= -90 && *request.Latitude <= 90 && *request.Latitude != 0, "latitude", "must be between -90 and 90 and not 0")
+	v.Check(request.Longitude != nil && *request.Longitude >= -180 && *request.Longitude <= 180 && *request.Longitude != 0, "longitude", "must be between -180 and 180 and not 0")
+	v.Check(request.Days >= 1 && request.Days <= models.MaxPassportDays, "days", fmt.Sprintf("must be between 1 and %d", models.MaxPassportDays))
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	passport, err := c.PassportService.SetPassport(r.Context(), request.UserHandle, request.City, *request.Latitude, *request.Longitude, request.Days)
+	if errors.Is(err, services.ErrPremiumRequired) {
+		http.Error(w, "Passport is a premium feature", http.StatusPaymentRequired)
+		return
+	}
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, passport)
+}
+
+// ClearPassport switches suggestions back to the user's GPS location
+func (c *PassportController) ClearPassport(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	if userHandle == "" {
+		http.Error(w, "Missing required parameter: userhandle", http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	if err := c.PassportService.ClearPassport(r.Context(), userHandle); err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"vibin_server/helpers"
+	"vibin_server/services"
+)
+
+// PhotoController processes uploaded profile photos and manages their order, primary photo and captions
+type PhotoController struct {
+	PhotoService *services.PhotoService
+}
+
+// NewPhotoController creates a new instance of PhotoController
+func NewPhotoController(service *services.PhotoService) *PhotoController {
+	return &PhotoController{PhotoService: service}
+}
+
+// ProcessPhoto is called by the client once a presigned upload completes. Renditions are generated in
+// the background and appear under photoRenditions on the profile.
+func (c *PhotoController) ProcessPhoto(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserHandle string `json:"userhandle"`
+		Key        string `json:"key"` // S3 key returned by /generate-presigned-url
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Required("userhandle", request.UserHandle)
+	v.Required("key", request.Key)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	if err := c.PhotoService.RequestProcessing(r.Context(), request.UserHandle, request.Key); err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusAccepted, map[string]string{"key": request.Key, "status": "processing"})
+}
+
+// AddPhoto adds an uploaded photo to the end of the user's photos and starts generating its renditions
+func (c *PhotoController) AddPhoto(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserHandle string `json:"userhandle"`
+		Key        string `json:"key"` // S3 key returned by /generate-presigned-url
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Handle("userhandle", request.UserHandle)
+	v.Required("key", request.Key)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	photos, err := c.PhotoService.AddPhoto(r.Context(), request.UserHandle, request.Key, helpers.MaxPhotos)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusCreated, photos)
+}
+
+// ReorderPhotos stores a new order for the user's photos
+func (c *PhotoController) ReorderPhotos(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserHandle string   `json:"userhandle"`
+		Photos     []string `json:"photos"` // Every current photo key, in the new order
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Handle("userhandle", request.UserHandle)
+	v.Check(len(request.Photos) > 0, "photos", "is required")
+	v.MaxItems("photos", len(request.Photos), helpers.MaxPhotos)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	photos, err := c.PhotoService.ReorderPhotos(r.Context(), request.UserHandle, request.Photos)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, photos)
+}
+
+// SetPrimaryPhoto makes one of the user's photos the primary (first) photo
+func (c *PhotoController) SetPrimaryPhoto(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserHandle string `json:"userhandle"`
+		Key        string `json:"key"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Handle("userhandle", request.UserHandle)
+	v.Required("key", request.Key)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	photos, err := c.PhotoService.SetPrimaryPhoto(r.Context(), request.UserHandle, request.Key)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, photos)
+}
+
+// SetPhotoCaption sets or clears the caption of one of the user's photos
+func (c *PhotoController) SetPhotoCaption(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserHandle string `json:"userhandle"`
+		Key        string `json:"key"`
+		Caption    string `json:"caption"` // Empty removes the caption
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	request.Caption = strings.TrimSpace(request.Caption)
+	var v helpers.Validator
+	v.Handle("userhandle", request.UserHandle)
+	v.Required("key", request.Key)
+	v.MaxLength("caption", request.Caption, helpers.MaxCaptionLength)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	photos, err := c.PhotoService.SetPhotoCaption(r.Context(), request.UserHandle, request.Key, request.Caption)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, photos)
+}
+
+// DeletePhoto removes one of the user's photos and its stored files
+func (c *PhotoController) DeletePhoto(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	key := r.URL.Query().Get("key")
+	var v helpers.Validator
+	v.Handle("userhandle", userHandle)
+	v.Required("key", key)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	photos, err := c.PhotoService.DeletePhoto(r.Context(), userHandle, key)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, photos)
+}
@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"vibin_server/helpers"
+	"vibin_server/models"
+	"vibin_server/services"
+)
+
+// ✅ Review queue page size defaults and caps
+const (
+	defaultPhotoReviewsPageSize = 50
+	maxPhotoReviewsPageSize     = 200
+)
+
+// PhotoReviewController exposes the admin queue of photos quarantined by automated moderation
+type PhotoReviewController struct {
+	PhotoModerationService *services.PhotoModerationService
+}
+
+// NewPhotoReviewController creates a new instance of PhotoReviewController
+func NewPhotoReviewController(service *services.PhotoModerationService) *PhotoReviewController {
+	return &PhotoReviewController{PhotoModerationService: service}
+}
+
+// ListReviews returns flagged photos, oldest first (?status=pending|approved|rejected&limit=&cursor=, admin)
+func (c *PhotoReviewController) ListReviews(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = models.PhotoReviewPending
+	}
+	var v helpers.Validator
+	v.OneOf("status", status, models.PhotoReviewPending, models.PhotoReviewApproved, models.PhotoReviewRejected)
+	if v.WriteErrors(w) {
+		return
+	}
+
+	limit := helpers.PageLimit(r, defaultPhotoReviewsPageSize, maxPhotoReviewsPageSize)
+	reviews, nextCursor, err := c.PhotoModerationService.ListReviews(r.Context(), status, int32(limit), r.URL.Query().Get("cursor"))
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.SetNextCursor(w, nextCursor)
+	helpers.WriteJSONResponse(w, http.StatusOK, reviews)
+}
+
+// ResolveReview approves (releases) or rejects (deletes) a quarantined photo (admin)
+func (c *PhotoReviewController) ResolveReview(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		PhotoKey string `json:"photoKey"`
+		Decision string `json:"decision"` // "approved" or "rejected"
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Required("photoKey", request.PhotoKey)
+	v.OneOf("decision", request.Decision, models.PhotoReviewApproved, models.PhotoReviewRejected)
+	if v.WriteErrors(w) {
+		return
+	}
+
+	review, err := c.PhotoModerationService.ResolveReview(r.Context(), request.PhotoKey, request.Decision, helpers.AdminHandle(r))
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, review)
+}
@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"vibin_server/helpers"
+	"vibin_server/services"
+	"vibin_server/utils"
+)
+
+// ✅ Recent viewer list defaults and caps
+const (
+	defaultViewersLimit = 50
+	maxViewersLimit     = 200
+)
+
+// ProfileViewController records profile views and lists recent viewers
+type ProfileViewController struct {
+	ProfileViewService *services.ProfileViewService
+}
+
+// NewProfileViewController creates a new instance of ProfileViewController
+func NewProfileViewController(service *services.ProfileViewService) *ProfileViewController {
+	return &ProfileViewController{ProfileViewService: service}
+}
+
+// RecordView is called by the client when a suggestion card is opened full-screen
+func (c *ProfileViewController) RecordView(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		ViewerHandle string `json:"viewerHandle"`
+		ViewedHandle string `json:"viewedHandle"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Required("viewerHandle", request.ViewerHandle)
+	v.Required("viewedHandle", request.ViewedHandle)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.ViewerHandle) {
+		return
+	}
+
+	recorded, err := c.ProfileViewService.RecordView(r.Context(), request.ViewerHandle, request.ViewedHandle)
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Failed to record profile view: %v", err)
+		http.Error(w, "Failed to record profile view", http.StatusInternalServerError)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, map[string]bool{"recorded": recorded})
+}
+
+// GetRecentViewers lists who viewed the user's profile recently
+func (c *ProfileViewController) GetRecentViewers(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	if userHandle == "" {
+		http.Error(w, "Missing required parameter: userhandle", http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	limit := defaultViewersLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxViewersLimit)
+	}
+
+	viewers, err := c.ProfileViewService.GetRecentViewers(r.Context(), userHandle, limit)
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Failed to fetch profile viewers for %s: %v", userHandle, err)
+		http.Error(w, "Failed to fetch profile viewers", http.StatusInternalServerError)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{"viewers": viewers})
+}
@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"vibin_server/helpers"
+	"vibin_server/models"
+	"vibin_server/services"
+	"vibin_server/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// PromoCodeController exposes admin promo code management and user redemption
+type PromoCodeController struct {
+	PromoCodeService *services.PromoCodeService
+}
+
+// NewPromoCodeController creates a new instance of PromoCodeController
+func NewPromoCodeController(service *services.PromoCodeService) *PromoCodeController {
+	return &PromoCodeController{PromoCodeService: service}
+}
+
+// CreatePromoCode adds a new campaign code (admin)
+func (c *PromoCodeController) CreatePromoCode(w http.ResponseWriter, r *http.Request) {
+	var request models.PromoCode
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Required("code", request.Code)
+	if v.WriteErrors(w) {
+		return
+	}
+	request.CreatedBy = helpers.AdminHandle(r)
+
+	promo, err := c.PromoCodeService.CreatePromoCode(r.Context(), request)
+	if errors.Is(err, services.ErrPromoCodeExists) {
+		http.Error(w, "Promo code already exists", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Failed to create promo code: %v", err)
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusCreated, promo)
+}
+
+// ListPromoCodes returns every promo code with its usage (admin)
+func (c *PromoCodeController) ListPromoCodes(w http.ResponseWriter, r *http.Request) {
+	promos, err := c.PromoCodeService.ListPromoCodes(r.Context())
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Failed to list promo codes: %v", err)
+		http.Error(w, "Failed to list promo codes", http.StatusInternalServerError)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, promos)
+}
+
+// DeactivatePromoCode stops further redemptions of a code (admin)
+func (c *PromoCodeController) DeactivatePromoCode(w http.ResponseWriter, r *http.Request) {
+	code := mux.Vars(r)["code"]
+
+	promo, err := c.PromoCodeService.DeactivatePromoCode(r.Context(), code)
+	if errors.Is(err, services.ErrPromoNotFound) {
+		http.Error(w, "Promo code not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Failed to deactivate promo code %s: %v", code, err)
+		http.Error(w, "Failed to deactivate promo code", http.StatusInternalServerError)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, promo)
+}
+
+// RedeemPromoCode applies a code to the user's account
+func (c *PromoCodeController) RedeemPromoCode(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserHandle string `json:"userHandle"`
+		Code       string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Required("userHandle", request.UserHandle)
+	v.Required("code", request.Code)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	result, err := c.PromoCodeService.RedeemPromoCode(r.Context(), request.UserHandle, request.Code)
+	switch {
+	case errors.Is(err, services.ErrPromoNotFound):
+		http.Error(w, "This promo code doesn't exist", http.StatusNotFound)
+		return
+	case errors.Is(err, services.ErrPromoUnavailable):
+		http.Error(w, "This promo code has expired or is no longer available", http.StatusGone)
+		return
+	case errors.Is(err, services.ErrPromoAlreadyRedeemed):
+		http.Error(w, "You've already used this promo code", http.StatusConflict)
+		return
+	case err != nil:
+		utils.Logf(r.Context(), "❌ Failed to redeem promo code for %s: %v", request.UserHandle, err)
+		http.Error(w, "Failed to redeem promo code", http.StatusInternalServerError)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, result)
+}
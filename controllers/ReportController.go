@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"vibin_server/helpers"
+	"vibin_server/models"
+	"vibin_server/services"
+)
+
+// ✅ Report queue page size defaults and caps
+const (
+	defaultReportsPageSize = 50
+	maxReportsPageSize     = 200
+)
+
+// ReportController files safety center reports and exposes the admin report queue
+type ReportController struct {
+	ReportService *services.ReportService
+}
+
+// NewReportController creates a new instance of ReportController
+func NewReportController(service *services.ReportService) *ReportController {
+	return &ReportController{ReportService: service}
+}
+
+// ReportUser blocks another user and files a categorized report with optional evidence
+func (c *ReportController) ReportUser(w http.ResponseWriter, r *http.Request) {
+	var request models.ReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Handle("userhandle", request.UserHandle)
+	v.Handle("reportedHandle", request.ReportedHandle)
+	v.OneOf("category", request.Category, models.ReportCategories...)
+	v.MaxLength("details", request.Details, models.MaxReportDetailsLength)
+	v.Check(request.Category != models.ReportCategoryOther || request.Details != "", "details", "is required for the other category")
+	v.MaxItems("messageIds", len(request.MessageIDs), models.MaxReportMessageIDs)
+	v.MaxItems("screenshotKeys", len(request.ScreenshotKeys), models.MaxReportScreenshots)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	report, err := c.ReportService.ReportUser(r.Context(), request)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusCreated, report)
+}
+
+// ListReports returns reports, most severe then oldest first (?status=open|actioned|dismissed&limit=&cursor=, admin)
+func (c *ReportController) ListReports(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = models.ReportStatusOpen
+	}
+	var v helpers.Validator
+	v.OneOf("status", status, models.ReportStatusOpen, models.ReportStatusActioned, models.ReportStatusDismissed)
+	if v.WriteErrors(w) {
+		return
+	}
+
+	limit := helpers.PageLimit(r, defaultReportsPageSize, maxReportsPageSize)
+	reports, nextCursor, err := c.ReportService.ListReports(r.Context(), status, int32(limit), r.URL.Query().Get("cursor"))
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.SetNextCursor(w, nextCursor)
+	helpers.WriteJSONResponse(w, http.StatusOK, reports)
+}
+
+// ResolveReport marks an open report actioned or dismissed (admin)
+func (c *ReportController) ResolveReport(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		ReportID string `json:"reportId"`
+		Decision string `json:"decision"` // "actioned" or "dismissed"
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Required("reportId", request.ReportID)
+	v.OneOf("decision", request.Decision, models.ReportStatusActioned, models.ReportStatusDismissed)
+	if v.WriteErrors(w) {
+		return
+	}
+
+	report, err := c.ReportService.ResolveReport(r.Context(), request.ReportID, request.Decision, helpers.AdminHandle(r))
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, report)
+}
@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+	"vibin_server/helpers"
+	"vibin_server/models"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultLoginAuditPageSize = 50
+	maxLoginAuditPageSize     = 200
+)
+
+// SessionController starts sessions and lets users see and sign out their devices
+type SessionController struct {
+	SessionService *services.SessionService
+}
+
+// NewSessionController creates a new instance of SessionController
+func NewSessionController(service *services.SessionService) *SessionController {
+	return &SessionController{SessionService: service}
+}
+
+// CreateSession starts a pending session on the calling device and sends the account a sign-in code;
+// it returns 202 with the pending session, whose token comes from VerifySession
+func (c *SessionController) CreateSession(w http.ResponseWriter, r *http.Request) {
+	var request models.SessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Handle("userhandle", request.UserHandle)
+	v.OneOf("platform", request.Platform, models.SessionPlatforms...)
+	v.MaxLength("deviceModel", request.DeviceModel, models.MaxSessionDeviceFieldLength)
+	v.MaxLength("osVersion", request.OSVersion, models.MaxSessionDeviceFieldLength)
+	v.MaxLength("appVersion", request.AppVersion, models.MaxSessionDeviceFieldLength)
+	v.MaxLength("deviceId", request.DeviceID, models.MaxSessionDeviceFieldLength)
+	if v.WriteErrors(w) {
+		return
+	}
+
+	session, err := c.SessionService.CreateSession(r.Context(), request, helpers.ClientIP(r), r.Header.Get(c.SessionService.CountryHeader))
+	if writeLockout(w, err) {
+		return
+	}
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusAccepted, session)
+}
+
+// VerifySession completes a sign-in with the code that was sent and returns the session's token (shown only once)
+func (c *SessionController) VerifySession(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserHandle string `json:"userhandle"`
+		Code       string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	sessionID := mux.Vars(r)["sessionId"]
+	var v helpers.Validator
+	v.Handle("userhandle", request.UserHandle)
+	v.Required("sessionId", sessionID)
+	v.Required("code", request.Code)
+	v.MaxLength("code", request.Code, 6)
+	if v.WriteErrors(w) {
+		return
+	}
+
+	session, err := c.SessionService.VerifySession(r.Context(), request.UserHandle, sessionID, request.Code, helpers.ClientIP(r))
+	if writeLockout(w, err) {
+		return
+	}
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, session)
+}
+
+// ListLoginAudit returns the user's sign-in decisions, newest first (?limit=&cursor=, admin)
+func (c *SessionController) ListLoginAudit(w http.ResponseWriter, r *http.Request) {
+	userHandle := mux.Vars(r)["userhandle"]
+	var v helpers.Validator
+	v.Handle("userhandle", userHandle)
+	if v.WriteErrors(w) {
+		return
+	}
+
+	limit := helpers.PageLimit(r, defaultLoginAuditPageSize, maxLoginAuditPageSize)
+	entries, nextCursor, err := c.SessionService.ListLoginAudit(r.Context(), userHandle, int32(limit), r.URL.Query().Get("cursor"))
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.SetNextCursor(w, nextCursor)
+	helpers.WriteJSONResponse(w, http.StatusOK, entries)
+}
+
+// ListSessions returns the user's signed-in devices (?userhandle=)
+func (c *SessionController) ListSessions(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	var v helpers.Validator
+	v.Handle("userhandle", userHandle)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	var currentID string
+	if session := helpers.SessionFromContext(r.Context()); session != nil {
+		currentID = session.SessionID
+	}
+	sessions, err := c.SessionService.ListSessions(r.Context(), userHandle, currentID)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, sessions)
+}
+
+// RevokeSession signs a device out (?userhandle=)
+func (c *SessionController) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	sessionID := mux.Vars(r)["sessionId"]
+	var v helpers.Validator
+	v.Handle("userhandle", userHandle)
+	v.Required("sessionId", sessionID)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	if err := c.SessionService.RevokeSession(r.Context(), userHandle, sessionID); err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeLockout answers a sign-in lockout or code send quota with 429 and Retry-After, reporting whether it did
+func writeLockout(w http.ResponseWriter, err error) bool {
+	var quotaErr *services.QuotaError
+	if !errors.As(err, &quotaErr) {
+		return false
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(quotaErr.RetryAt).Seconds())+1))
+	helpers.WriteJSONResponse(w, http.StatusTooManyRequests, quotaErr)
+	return true
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"vibin_server/helpers"
+	"vibin_server/services"
+)
+
+// SpotifyController connects and disconnects Spotify on profiles
+type SpotifyController struct {
+	SpotifyService *services.SpotifyService
+}
+
+// NewSpotifyController creates a new instance of SpotifyController
+func NewSpotifyController(service *services.SpotifyService) *SpotifyController {
+	return &SpotifyController{SpotifyService: service}
+}
+
+// Connect exchanges the code from Spotify's OAuth redirect and returns the stored top artists and anthem
+func (c *SpotifyController) Connect(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserHandle string `json:"userhandle"`
+		Code       string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Handle("userhandle", request.UserHandle)
+	v.Required("code", request.Code)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	spotify, err := c.SpotifyService.Connect(r.Context(), request.UserHandle, request.Code)
+	if errors.Is(err, services.ErrSpotifyNotConfigured) {
+		http.Error(w, "Spotify is not available", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, spotify)
+}
+
+// Disconnect removes the user's Spotify data from their profile
+func (c *SpotifyController) Disconnect(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	if userHandle == "" {
+		http.Error(w, "Missing required parameter: userhandle", http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	if err := c.SpotifyService.Disconnect(r.Context(), userHandle); err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
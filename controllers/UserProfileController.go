@@ -1,23 +1,35 @@
 package controllers
 
 import (
-	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
+	"vibin_server/helpers"
 	"vibin_server/models"
 	"vibin_server/services"
+	"vibin_server/utils"
+)
+
+// ✅ Place browsing page size defaults and caps (filtering can return fewer profiles than the limit)
+const (
+	defaultBrowsePageSize = 50
+	maxBrowsePageSize     = 100
 )
 
 // UserProfileController handles user profile-related operations
 type UserProfileController struct {
 	UserProfileService *services.UserProfileService
+	Captcha            *services.CaptchaService // ✅ Signup challenge, when the environment requires one
 }
 
 // NewUserProfileController creates a new instance of UserProfileController
-func NewUserProfileController(userProfileService *services.UserProfileService) *UserProfileController {
-	return &UserProfileController{UserProfileService: userProfileService}
+func NewUserProfileController(userProfileService *services.UserProfileService, captcha *services.CaptchaService) *UserProfileController {
+	return &UserProfileController{UserProfileService: userProfileService, Captcha: captcha}
 }
 
 func (c *UserProfileController) CreateUserProfile(w http.ResponseWriter, r *http.Request) {
@@ -27,15 +39,73 @@ func (c *UserProfileController) CreateUserProfile(w http.ResponseWriter, r *http
 		return
 	}
 
-	createdProfile, err := c.UserProfileService.AddUserProfile(context.TODO(), profile)
+	var v helpers.Validator
+	v.Handle("userhandle", profile.UserHandle)
+	v.MaxLength("name", profile.Name, helpers.MaxNameLength)
+	v.MaxLength("username", profile.UserName, helpers.MaxNameLength)
+	v.MaxLength("bio", profile.Bio, helpers.MaxBioLength)
+	v.Adult("dob", profile.DOB)
+	v.MaxItems("photos", len(profile.Photos), helpers.MaxPhotos)
+	v.MaxItems("videos", len(profile.Videos), helpers.MaxVideos)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, profile.UserHandle) {
+		return
+	}
+
+	if err := c.Captcha.Verify(r.Context(), models.CaptchaActionSignup, r.Header.Get(models.CaptchaTokenHeader), helpers.ClientIP(r)); err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	createdProfile, err := c.UserProfileService.AddUserProfile(r.Context(), profile)
 	if err != nil {
-		http.Error(w, "Failed to add profile", http.StatusInternalServerError)
+		helpers.WriteError(w, r, err)
 		return
 	}
 
 	json.NewEncoder(w).Encode(createdProfile)
 }
 
+// UpdateUserProfile edits the fields of the user's profile present in the body (PATCH); absent fields
+// are left as they are and empty ones are cleared
+func (c *UserProfileController) UpdateUserProfile(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserHandle string `json:"userhandle"`
+		models.ProfileUpdate
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Handle("userhandle", request.UserHandle)
+	if request.Name != nil {
+		v.MaxLength("name", *request.Name, helpers.MaxNameLength)
+	}
+	if request.UserName != nil {
+		v.MaxLength("username", *request.UserName, helpers.MaxNameLength)
+	}
+	if request.Bio != nil {
+		v.MaxLength("bio", *request.Bio, helpers.MaxBioLength)
+	}
+	if request.DOB != nil {
+		v.Adult("dob", *request.DOB)
+	}
+	if request.Videos != nil {
+		v.MaxItems("videos", len(*request.Videos), helpers.MaxVideos)
+	}
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	profile, err := c.UserProfileService.UpdateUserProfile(r.Context(), request.UserHandle, request.ProfileUpdate)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, profile)
+}
+
 // GetUserProfileByEmail fetches a user profile using the email ID from the GSI
 func (c *UserProfileController) GetUserProfileByEmail(w http.ResponseWriter, r *http.Request) {
 	var request struct {
@@ -49,7 +119,7 @@ func (c *UserProfileController) GetUserProfileByEmail(w http.ResponseWriter, r *
 	}
 
 	// Fetch user profile
-	profile, err := c.UserProfileService.GetUserProfileByEmail(context.TODO(), request.EmailID)
+	profile, err := c.UserProfileService.GetUserProfileByEmail(r.Context(), request.EmailID)
 	if err != nil {
 		http.Error(w, "Failed to fetch profile", http.StatusInternalServerError)
 		return
@@ -72,16 +142,14 @@ func (c *UserProfileController) CheckUserHandleAvailability(w http.ResponseWrite
 		return
 	}
 
-	log.Printf("🔍 API Request to check userhandle: %s", userHandle)
+	utils.Logf(r.Context(), "🔍 API Request to check userhandle: %s", userHandle)
 
-	// Use a context with timeout to avoid long-running requests
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := r.Context()
 
 	// Check if userhandle exists
 	isAvailable, err := c.UserProfileService.IsUserHandleAvailable(ctx, userHandle)
 	if err != nil {
-		log.Printf("❌ Internal Server Error while checking userhandle '%s': %v", userHandle, err)
+		utils.Logf(r.Context(), "❌ Internal Server Error while checking userhandle '%s': %v", userHandle, err)
 		http.Error(w, `{"error": "Error checking userhandle"}`, http.StatusInternalServerError)
 		return
 	}
@@ -105,7 +173,7 @@ func (c *UserProfileController) CheckEmailAvailability(w http.ResponseWriter, r
 	}
 
 	// Check if email exists
-	exists, err := c.UserProfileService.CheckEmailExists(context.TODO(), request.EmailID)
+	exists, err := c.UserProfileService.CheckEmailExists(r.Context(), request.EmailID)
 	if err != nil {
 		http.Error(w, "Error checking email availability", http.StatusInternalServerError)
 		return
@@ -124,7 +192,7 @@ func (c *UserProfileController) GetUserHandleByEmail(w http.ResponseWriter, r *h
 	}
 
 	// Fetch userhandle
-	userHandle, err := c.UserProfileService.GetUserHandleByEmail(context.TODO(), emailID)
+	userHandle, err := c.UserProfileService.GetUserHandleByEmail(r.Context(), emailID)
 	if err != nil {
 		http.Error(w, "Error fetching userhandle", http.StatusInternalServerError)
 		return
@@ -140,28 +208,442 @@ func (c *UserProfileController) GetUserHandleByEmail(w http.ResponseWriter, r *h
 	json.NewEncoder(w).Encode(map[string]string{"userhandle": userHandle})
 }
 
-// ✅ GetUserSuggestions retrieves a list of users based on gender (excluding requester)
+// GetUserProfile returns the user's own profile (?userhandle=) with a weak ETag; If-None-Match
+// yields 304 while it is unchanged
+func (c *UserProfileController) GetUserProfile(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	var v helpers.Validator
+	v.Handle("userhandle", userHandle)
+	if v.WriteErrors(w) {
+		return
+	}
+
+	profile, err := c.UserProfileService.GetUserProfileByHandle(r.Context(), userHandle)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONWithETag(w, r, http.StatusOK, profile)
+}
+
+// ✅ GetUserSuggestions retrieves users compatible with the requester's orientation and lookingFor
+// (excluding requester); genders (or the older single gender) optionally filters, "everyone" = all
 func (c *UserProfileController) GetUserSuggestions(w http.ResponseWriter, r *http.Request) {
 	var request struct {
-		UserHandle string `json:"userhandle"`
-		Gender     string `json:"gender"`
+		UserHandle string   `json:"userhandle"`
+		Gender     string   `json:"gender"`
+		Genders    []string `json:"genders"`
 	}
 
 	// Decode JSON request
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Gender == "" || request.UserHandle == "" {
-		http.Error(w, `{"error": "Invalid request payload, must include 'userHandle' and 'gender'"}`, http.StatusBadRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.UserHandle == "" {
+		http.Error(w, `{"error": "Invalid request payload, must include 'userHandle'"}`, http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+	if request.Gender != "" {
+		request.Genders = append(request.Genders, request.Gender)
+	}
+	c.writeSuggestions(w, r, request.UserHandle, request.Genders)
+}
+
+// ListUserSuggestions is the cacheable GET form of GetUserSuggestions (?userhandle=&genders=a,b):
+// responses carry a weak ETag and If-None-Match yields 304 while the suggestions are unchanged
+func (c *UserProfileController) ListUserSuggestions(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	if userHandle == "" {
+		http.Error(w, `{"error": "Missing required parameter: userhandle"}`, http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+	var genders []string
+	if value := r.URL.Query().Get("genders"); value != "" {
+		genders = strings.Split(value, ",")
+	}
+	c.writeSuggestions(w, r, userHandle, genders)
+}
+
+// writeSuggestions validates the gender filter and writes the user's suggestions
+func (c *UserProfileController) writeSuggestions(w http.ResponseWriter, r *http.Request, userHandle string, genders []string) {
+	known := true
+	for _, gender := range genders {
+		canonical := models.CanonicalGender(gender)
+		known = known && (canonical == models.GenderEveryone || slices.Contains(models.AllGenders, canonical))
+	}
+	var v helpers.Validator
+	v.Check(known, "genders", fmt.Sprintf("must each be %s, %s, %s or %s", models.GenderMan, models.GenderWoman, models.GenderNonbinary, models.GenderEveryone))
+	if v.WriteErrors(w) {
 		return
 	}
 
 	// Fetch user suggestions
-	users, err := c.UserProfileService.GetUserSuggestions(context.TODO(), request.UserHandle, request.Gender)
+	users, err := c.UserProfileService.GetUserSuggestions(r.Context(), userHandle, genders)
 	if err != nil {
-		log.Printf("❌ Error fetching user suggestions: %v", err)
+		utils.Logf(r.Context(), "❌ Error fetching user suggestions: %v", err)
 		http.Error(w, `{"error": "Failed to fetch user suggestions"}`, http.StatusInternalServerError)
 		return
 	}
 
 	// Return users as JSON response
+	helpers.WriteJSONWithETag(w, r, http.StatusOK, users)
+}
+
+// BrowsePlace lists compatible users in the requester's city or region, one page at a time
+// (?userhandle=&scope=city|region&limit=&cursor=)
+func (c *UserProfileController) BrowsePlace(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	scope := r.URL.Query().Get("scope")
+	if scope == "" {
+		scope = models.BrowseScopeCity
+	}
+	var v helpers.Validator
+	v.Handle("userhandle", userHandle)
+	v.OneOf("scope", scope, models.BrowseScopeCity, models.BrowseScopeRegion)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	limit := helpers.PageLimit(r, defaultBrowsePageSize, maxBrowsePageSize)
+	users, nextCursor, err := c.UserProfileService.BrowsePlace(r.Context(), userHandle, scope, int32(limit), r.URL.Query().Get("cursor"))
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.SetNextCursor(w, nextCursor)
+	helpers.WriteJSONResponse(w, http.StatusOK, users)
+}
+
+// GetProcessingConsents returns which kinds of processing the user currently allows
+func (c *UserProfileController) GetProcessingConsents(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	if userHandle == "" {
+		http.Error(w, "Missing required parameter: userhandle", http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	consents, err := c.UserProfileService.GetProcessingConsents(r.Context(), userHandle)
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Error fetching processing consents: %v", err)
+		http.Error(w, `{"error": "Failed to fetch consents"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(consents)
+}
+
+// UpdateProcessingConsents lets the user object to (or re-allow) processing purposes at any time
+func (c *UserProfileController) UpdateProcessingConsents(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	if userHandle == "" {
+		http.Error(w, "Missing required parameter: userhandle", http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	var update models.ProcessingConsentsUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	consents, err := c.UserProfileService.UpdateProcessingConsents(r.Context(), userHandle, update)
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Error updating processing consents: %v", err)
+		http.Error(w, `{"error": "Failed to update consents"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(consents)
+}
+
+// GetProfileCompleteness returns the profile's completeness score and the sections it is missing
+func (c *UserProfileController) GetProfileCompleteness(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	if userHandle == "" {
+		http.Error(w, "Missing required parameter: userhandle", http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	profile, err := c.UserProfileService.GetUserProfileByHandle(r.Context(), userHandle)
+	if errors.Is(err, services.ErrNotFound) {
+		http.Error(w, `{"error": "Profile not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Error fetching profile completeness: %v", err)
+		http.Error(w, `{"error": "Failed to fetch profile completeness"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile.Completeness)
+}
+
+// GetInterestCatalog lists the interests users can pick
+func (c *UserProfileController) GetInterestCatalog(w http.ResponseWriter, r *http.Request) {
+	helpers.WriteJSONResponse(w, http.StatusOK, c.UserProfileService.InterestCatalog())
+}
+
+// UpdateProfileInterests replaces the user's interests with catalog IDs (up to MaxInterests)
+func (c *UserProfileController) UpdateProfileInterests(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserHandle  string   `json:"userhandle"`
+		InterestIDs []string `json:"interestIds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Handle("userhandle", request.UserHandle)
+	v.MaxItems("interestIds", len(request.InterestIDs), helpers.MaxInterests)
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	interests, err := c.UserProfileService.SetInterests(r.Context(), request.UserHandle, request.InterestIDs)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, interests)
+}
+
+// GetPromptQuestions lists the prompt questions users can answer on their profile
+func (c *UserProfileController) GetPromptQuestions(w http.ResponseWriter, r *http.Request) {
+	helpers.WriteJSONResponse(w, http.StatusOK, c.UserProfileService.PromptQuestions())
+}
+
+// UpdateProfilePrompts replaces the user's answered prompts (up to MaxProfilePrompts)
+func (c *UserProfileController) UpdateProfilePrompts(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserHandle string                       `json:"userhandle"`
+		Prompts    []models.ProfilePromptAnswer `json:"prompts"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Handle("userhandle", request.UserHandle)
+	v.MaxItems("prompts", len(request.Prompts), helpers.MaxProfilePrompts)
+	for i, prompt := range request.Prompts {
+		field := fmt.Sprintf("prompts[%d]", i)
+		v.Required(field+".promptId", prompt.PromptID)
+		v.Required(field+".answer", strings.TrimSpace(prompt.Answer))
+		v.MaxLength(field+".answer", prompt.Answer, helpers.MaxAnswerLength)
+	}
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	prompts, err := c.UserProfileService.SetProfilePrompts(r.Context(), request.UserHandle, request.Prompts)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, prompts)
+}
+
+// SetAudioPrompt attaches an uploaded recording (see /generate-presigned-url) as the voice answer to a prompt
+func (c *UserProfileController) SetAudioPrompt(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserHandle      string  `json:"userhandle"`
+		PromptID        string  `json:"promptId"`
+		Key             string  `json:"key"`
+		DurationSeconds float64 `json:"durationSeconds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Handle("userhandle", request.UserHandle)
+	v.Required("promptId", request.PromptID)
+	v.Required("key", request.Key)
+	v.Check(request.DurationSeconds > 0 && request.DurationSeconds <= models.MaxAudioPromptSeconds, "durationSeconds", fmt.Sprintf("must be between 0 and %d", models.MaxAudioPromptSeconds))
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	audioPrompt, err := c.UserProfileService.SetAudioPrompt(r.Context(), request.UserHandle, request.PromptID, request.Key, request.DurationSeconds)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, audioPrompt)
+}
+
+// DeleteAudioPrompt removes the user's voice answer
+func (c *UserProfileController) DeleteAudioPrompt(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	if userHandle == "" {
+		http.Error(w, "Missing required parameter: userhandle", http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	if err := c.UserProfileService.DeleteAudioPrompt(r.Context(), userHandle); err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UpdateLocation stores the user's current coordinates; small moves are ignored (see the moved flag)
+func (c *UserProfileController) UpdateLocation(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserHandle string   `json:"userhandle"`
+		Latitude   *float64 `json:"latitude"`
+		Longitude  *float64 `json:"longitude"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Handle("userhandle", request.UserHandle)
+	v.Check(request.Latitude != nil && *request.Latitude >= -90 && *request.Latitude <= 90, "latitude", "must be between -90 and 90")
+	v.Check(request.Longitude != nil && *request.Longitude >= -180 && *request.Longitude <= 180, "longitude", "must be between -180 and 180")
+	if request.Latitude != nil && request.Longitude != nil {
+		v.Check(*request.Latitude != 0 || *request.Longitude != 0, "latitude", "0,0 is not a valid location")
+	}
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	update, err := c.UserProfileService.UpdateLocation(r.Context(), request.UserHandle, *request.Latitude, *request.Longitude)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, update)
+}
+
+// PauseProfile hides the user from discovery and pings, optionally until an auto-resume time
+func (c *UserProfileController) PauseProfile(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserHandle string `json:"userhandle"`
+		Until      string `json:"until,omitempty"` // RFC3339 auto-resume time; omit to pause until resumed
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var until time.Time
+	var v helpers.Validator
+	v.Handle("userhandle", request.UserHandle)
+	if request.Until != "" {
+		var err error
+		until, err = time.Parse(time.RFC3339, request.Until)
+		v.Check(err == nil && until.After(time.Now()) && until.Before(time.Now().AddDate(0, 0, models.MaxPauseDays)),
+			"until", fmt.Sprintf("must be an RFC3339 time within the next %d days", models.MaxPauseDays))
+	}
+	if v.WriteErrors(w) || !helpers.ActsFor(w, r, request.UserHandle) {
+		return
+	}
+
+	if err := c.UserProfileService.PauseProfile(r.Context(), request.UserHandle, until); err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{"paused": true, "pausedUntil": request.Until})
+}
+
+// ResumeProfile ends the user's pause (?userhandle=)
+func (c *UserProfileController) ResumeProfile(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	if userHandle == "" {
+		http.Error(w, "Missing required parameter: userhandle", http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	if err := c.UserProfileService.ResumeProfile(r.Context(), userHandle); err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CheckProfileVersion is the cheap freshness check for cached suggestion cards.
+// HEAD answers with headers only (ETag is the version; If-None-Match yields 304),
+// GET also returns the version info and, when ?version= is given, whether it changed.
+func (c *UserProfileController) CheckProfileVersion(w http.ResponseWriter, r *http.Request) {
+	userHandle := r.URL.Query().Get("userhandle")
+	if userHandle == "" {
+		http.Error(w, "Missing required parameter: userhandle", http.StatusBadRequest)
+		return
+	}
+	if !helpers.ActsFor(w, r, userHandle) {
+		return
+	}
+
+	info, err := c.UserProfileService.GetProfileVersion(r.Context(), userHandle)
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Error checking profile version: %v", err)
+		http.Error(w, `{"error": "Failed to check profile version"}`, http.StatusInternalServerError)
+		return
+	}
+	if info == nil {
+		http.Error(w, `{"error": "Profile not found"}`, http.StatusNotFound)
+		return
+	}
+
+	etag := `"` + strconv.Itoa(info.ProfileVersion) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("X-Profile-Version", strconv.Itoa(info.ProfileVersion))
+	w.Header().Set("Cache-Control", "no-cache")
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if r.Method == http.MethodHead {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	response := map[string]interface{}{
+		"userhandle":     info.UserHandle,
+		"profileVersion": info.ProfileVersion,
+		"updatedAt":      info.UpdatedAt,
+	}
+	if known := r.URL.Query().Get("version"); known != "" {
+		knownVersion, err := strconv.Atoi(known)
+		if err != nil {
+			http.Error(w, "version must be an integer", http.StatusBadRequest)
+			return
+		}
+		response["changed"] = knownVersion != info.ProfileVersion
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(users)
+	json.NewEncoder(w).Encode(response)
 }
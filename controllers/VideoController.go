@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"vibin_server/helpers"
+	"vibin_server/models"
+	"vibin_server/services"
+	"vibin_server/utils"
+)
+
+// VideoController runs the multipart upload flow for profile videos: start an upload, presign its
+// parts in batches, then complete it to attach the video to the profile (or abort it)
+type VideoController struct {
+	S3Service          *services.S3Service
+	UserProfileService *services.UserProfileService
+}
+
+// NewVideoController creates a new instance of VideoController
+func NewVideoController(s3Service *services.S3Service, userProfileService *services.UserProfileService) *VideoController {
+	return &VideoController{S3Service: s3Service, UserProfileService: userProfileService}
+}
+
+// CreateUpload starts a multipart upload and tells the client the part size and count
+func (c *VideoController) CreateUpload(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		FileName string `json:"fileName"`
+		FileType string `json:"fileType"`
+		Path     string `json:"path"`
+		Size     int64  `json:"size"` // Total bytes, used to compute the part count
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	owner, ok := uploadOwner(w, r)
+	if !ok {
+		return
+	}
+	var v helpers.Validator
+	v.Required("fileName", request.FileName)
+	v.Required("fileType", request.FileType)
+	v.Required("path", request.Path)
+	if v.WriteErrors(w) {
+		return
+	}
+
+	upload, err := c.S3Service.CreateMultipartUpload(r.Context(), owner, request.FileName, request.FileType, request.Path, request.Size)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	utils.Logf(r.Context(), "✅ Started multipart upload %s for %s (%d parts)", upload.UploadID, upload.Key, upload.PartCount)
+	helpers.WriteJSONResponse(w, http.StatusCreated, upload)
+}
+
+// GetPartURLs presigns PUT URLs for a batch of parts
+func (c *VideoController) GetPartURLs(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Key         string  `json:"key"`
+		UploadID    string  `json:"uploadId"`
+		PartNumbers []int32 `json:"partNumbers"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	owner, ok := uploadOwner(w, r)
+	if !ok {
+		return
+	}
+	var v helpers.Validator
+	v.Required("key", request.Key)
+	v.Check(models.OwnsUploadKey(owner, request.Key), "key", "must be an upload you started")
+	v.Required("uploadId", request.UploadID)
+	v.Check(len(request.PartNumbers) > 0, "partNumbers", "is required")
+	v.MaxItems("partNumbers", len(request.PartNumbers), models.MaxPartURLsPerBatch)
+	for _, partNumber := range request.PartNumbers {
+		if partNumber < 1 || partNumber > models.MaxUploadPartNumber {
+			v.Check(false, "partNumbers", "must be between 1 and 10000")
+			break
+		}
+	}
+	if v.WriteErrors(w) {
+		return
+	}
+
+	urls, err := c.S3Service.GeneratePartUploadURLs(r.Context(), request.Key, request.UploadID, request.PartNumbers)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{"parts": urls})
+}
+
+// CompleteUpload assembles the parts and adds the video to the user's profile
+func (c *VideoController) CompleteUpload(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Key      string                `json:"key"`
+		UploadID string                `json:"uploadId"`
+		Parts    []models.UploadedPart `json:"parts"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	owner, ok := uploadOwner(w, r)
+	if !ok {
+		return
+	}
+	var v helpers.Validator
+	v.Required("key", request.Key)
+	v.Check(models.OwnsUploadKey(owner, request.Key), "key", "must be an upload you started")
+	v.Required("uploadId", request.UploadID)
+	v.Check(len(request.Parts) > 0, "parts", "is required")
+	v.MaxItems("parts", len(request.Parts), models.MaxUploadPartNumber)
+	if v.WriteErrors(w) {
+		return
+	}
+
+	if err := c.S3Service.CompleteMultipartUpload(r.Context(), request.Key, request.UploadID, request.Parts); err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+	if err := c.UserProfileService.AddProfileVideo(r.Context(), owner, request.Key, helpers.MaxVideos); err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	utils.Logf(r.Context(), "✅ Completed video upload %s for %s", request.Key, owner)
+	helpers.WriteJSONResponse(w, http.StatusOK, map[string]string{"key": request.Key})
+}
+
+// AbortUpload discards an unfinished upload so its parts stop costing storage
+func (c *VideoController) AbortUpload(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Key      string `json:"key"`
+		UploadID string `json:"uploadId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	owner, ok := uploadOwner(w, r)
+	if !ok {
+		return
+	}
+	var v helpers.Validator
+	v.Required("key", request.Key)
+	v.Check(models.OwnsUploadKey(owner, request.Key), "key", "must be an upload you started")
+	v.Required("uploadId", request.UploadID)
+	if v.WriteErrors(w) {
+		return
+	}
+
+	if err := c.S3Service.AbortMultipartUpload(r.Context(), request.Key, request.UploadID); err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
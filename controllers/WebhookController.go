@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"vibin_server/helpers"
+	"vibin_server/models"
+	"vibin_server/services"
+	"vibin_server/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// WebhookController exposes admin management of outbound webhooks
+type WebhookController struct {
+	WebhookService *services.WebhookService
+}
+
+// NewWebhookController creates a new instance of WebhookController
+func NewWebhookController(service *services.WebhookService) *WebhookController {
+	return &WebhookController{WebhookService: service}
+}
+
+// ListWebhooks returns every registered webhook without its secret (admin)
+func (c *WebhookController) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := c.WebhookService.ListWebhooks(r.Context())
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Failed to list webhooks: %v", err)
+		http.Error(w, "Failed to list webhooks", http.StatusInternalServerError)
+		return
+	}
+	for i := range webhooks {
+		webhooks[i].Secret = "" // ✅ Secrets are only shown at creation
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, webhooks)
+}
+
+// CreateWebhook registers a URL for the given events and returns it with its signing secret (admin)
+func (c *WebhookController) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var request models.Webhook
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	var v helpers.Validator
+	v.Required("url", request.URL)
+	v.Check(len(request.Events) > 0, "events", "must list at least one event type")
+	if v.WriteErrors(w) {
+		return
+	}
+	request.CreatedBy = helpers.AdminHandle(r)
+
+	webhook, err := c.WebhookService.CreateWebhook(r.Context(), request)
+	if err != nil {
+		utils.Logf(r.Context(), "❌ Failed to create webhook: %v", err)
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusCreated, webhook)
+}
+
+// DeleteWebhook removes the webhook named in the path (admin)
+func (c *WebhookController) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	webhookID := mux.Vars(r)["webhookId"]
+
+	if err := c.WebhookService.DeleteWebhook(r.Context(), webhookID); err != nil {
+		utils.Logf(r.Context(), "❌ Failed to delete webhook %s: %v", webhookID, err)
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -2,58 +2,82 @@ package controllers
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
+	"vibin_server/helpers"
 	"vibin_server/services"
+	"vibin_server/utils"
 )
 
+// S3Controller presigns media uploads and reads
+type S3Controller struct {
+	S3Service          *services.S3Service
+	UserProfileService *services.UserProfileService     // Decides which media a reader may see
+	PhotoModeration    *services.PhotoModerationService // Photos awaiting review are never signed
+}
+
+// NewS3Controller creates a new instance of S3Controller
+func NewS3Controller(service *services.S3Service, userProfileService *services.UserProfileService, photoModeration *services.PhotoModerationService) *S3Controller {
+	return &S3Controller{S3Service: service, UserProfileService: userProfileService, PhotoModeration: photoModeration}
+}
+
 // GeneratePresignedURL generates a presigned URL for S3 uploads
-func GeneratePresignedURL(w http.ResponseWriter, r *http.Request) {
-	log.Println("GeneratePresignedURL: Received request")
+func (c *S3Controller) GeneratePresignedURL(w http.ResponseWriter, r *http.Request) {
+	utils.Logln(r.Context(), "GeneratePresignedURL: Received request")
 
 	var payload struct {
-		FileName string `json:"fileName"`
-		FileType string `json:"fileType"`
-		Path     string `json:"path"` // Accept path parameter
+		UserHandle string `json:"userhandle,omitempty"` // Optional; the owner is always the session's user
+		FileName   string `json:"fileName"`             // Only its extension is used, and it must match fileType
+		FileType   string `json:"fileType"`
+		Path       string `json:"path"`           // Folder under the user's prefix, e.g. "photos"
+		Size       int64  `json:"size,omitempty"` // Exact upload size in bytes, signed into the URL when given
 	}
 
 	// Decode JSON payload
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		log.Printf("Error decoding request body: %v", err)
+		utils.Logf(r.Context(), "Error decoding request body: %v", err)
 		http.Error(w, "Invalid request payload", http.StatusBadRequest)
 		return
 	}
 
+	owner, ok := uploadOwner(w, r)
+	if !ok {
+		return
+	}
+
 	// Validate required fields
-	if payload.FileName == "" || payload.FileType == "" || payload.Path == "" {
-		log.Println("Error: Missing required fields in request payload")
-		http.Error(w, "Missing required fields", http.StatusBadRequest)
+	var v helpers.Validator
+	v.Required("fileName", payload.FileName)
+	v.Required("fileType", payload.FileType)
+	v.Required("path", payload.Path)
+	if v.WriteErrors(w) {
+		utils.Logln(r.Context(), "Error: Invalid fields in request payload")
 		return
 	}
 
-	log.Printf("GeneratePresignedURL: Generating pre-signed URL for FileName: %s, Path: %s", payload.FileName, payload.Path)
+	utils.Logf(r.Context(), "GeneratePresignedURL: Generating pre-signed URL for %s, FileName: %s, Path: %s", owner, payload.FileName, payload.Path)
 
-	url, fileName, err := services.GenerateUploadURL(payload.FileName, payload.FileType, payload.Path)
+	url, fileName, err := c.S3Service.GenerateUploadURL(r.Context(), owner, payload.FileName, payload.FileType, payload.Path, payload.Size)
 	if err != nil {
-		log.Printf("Error generating pre-signed URL: %v", err)
-		http.Error(w, "Failed to generate pre-signed URL", http.StatusInternalServerError)
+		utils.Logf(r.Context(), "Error generating pre-signed URL: %v", err)
+		helpers.WriteError(w, r, err)
 		return
 	}
 
-	log.Printf("GeneratePresignedURL: Successfully generated URL: %s for file: %s", url, fileName)
+	utils.Logf(r.Context(), "GeneratePresignedURL: Successfully generated URL: %s for file: %s", url, fileName)
 
 	response := map[string]string{"url": url, "fileName": fileName}
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("Error encoding response: %v", err)
+		utils.Logf(r.Context(), "Error encoding response: %v", err)
 		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 		return
 	}
 
-	log.Println("GeneratePresignedURL: Response successfully sent")
+	utils.Logln(r.Context(), "GeneratePresignedURL: Response successfully sent")
 }
 
-// GetPresignedReadURL generates a presigned URL for reading S3 objects
-func GetPresignedReadURL(w http.ResponseWriter, r *http.Request) {
+// GetPresignedReadURL generates a presigned URL for reading S3 objects. Only the session user's own
+// uploads and media shown on a profile they can see are signed, and never a photo awaiting review.
+func (c *S3Controller) GetPresignedReadURL(w http.ResponseWriter, r *http.Request) {
 	var payload struct {
 		Key string `json:"key"`
 	}
@@ -62,7 +86,31 @@ func GetPresignedReadURL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	url, err := services.GenerateReadURL(payload.Key)
+	session := helpers.SessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "A session token is required to read media", http.StatusUnauthorized)
+		return
+	}
+	readable, err := c.UserProfileService.CanReadMedia(r.Context(), session.UserHandle, payload.Key)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+	if readable {
+		pending, err := c.PhotoModeration.PendingReview(r.Context(), payload.Key)
+		if err != nil {
+			helpers.WriteError(w, r, err)
+			return
+		}
+		readable = !pending
+	}
+	if !readable {
+		utils.Logf(r.Context(), "GetPresignedReadURL: %s may not read %s", session.UserHandle, payload.Key)
+		http.Error(w, "Media not found", http.StatusNotFound)
+		return
+	}
+
+	url, err := c.S3Service.GenerateReadURL(r.Context(), payload.Key)
 	if err != nil {
 		http.Error(w, "Failed to generate read pre-signed URL", http.StatusInternalServerError)
 		return
@@ -70,3 +118,14 @@ func GetPresignedReadURL(w http.ResponseWriter, r *http.Request) {
 
 	json.NewEncoder(w).Encode(map[string]string{"url": url})
 }
+
+// uploadOwner is the user uploads are namespaced under: the session's user, never a handle from the
+// request body (the session middleware already rejects a body handle naming someone else)
+func uploadOwner(w http.ResponseWriter, r *http.Request) (string, bool) {
+	session := helpers.SessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "A session token is required to upload", http.StatusUnauthorized)
+		return "", false
+	}
+	return session.UserHandle, true
+}
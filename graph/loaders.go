@@ -0,0 +1,116 @@
+package graph
+
+import (
+	"context"
+	"sync"
+	"time"
+	"vibin_server/models"
+	"vibin_server/services"
+)
+
+// profileBatchWindow is how long the loader waits for sibling resolvers before issuing one BatchGetItem
+const profileBatchWindow = 2 * time.Millisecond
+
+// loadersKey is the context key holding the current query's loaders
+type loadersKey struct{}
+
+// loaders are created per GraphQL request, so cached results never outlive the query
+type loaders struct {
+	profiles *profileLoader
+
+	unreadMu sync.Mutex
+	unread   map[string]*unreadResult // userHandle → memoized unread counts
+}
+
+type unreadResult struct {
+	once   sync.Once
+	counts *models.UnreadCounts
+	err    error
+}
+
+// WithLoaders attaches fresh per-request loaders to ctx; the GraphQL controller calls it for every query
+func WithLoaders(ctx context.Context, userProfileService *services.UserProfileService) context.Context {
+	return context.WithValue(ctx, loadersKey{}, &loaders{
+		profiles: &profileLoader{fetch: userProfileService.GetUserProfilesByHandles, results: map[string]*profileResult{}},
+		unread:   map[string]*unreadResult{},
+	})
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	l, _ := ctx.Value(loadersKey{}).(*loaders)
+	return l
+}
+
+// unreadCounts fetches a user's unread counts once per query, however many fields ask for them
+func (l *loaders) unreadCounts(ctx context.Context, chat *services.ChatService, userHandle string) (*models.UnreadCounts, error) {
+	l.unreadMu.Lock()
+	result, ok := l.unread[userHandle]
+	if !ok {
+		result = &unreadResult{}
+		l.unread[userHandle] = result
+	}
+	l.unreadMu.Unlock()
+
+	result.once.Do(func() {
+		result.counts, result.err = chat.GetUnreadCounts(ctx, userHandle)
+	})
+	return result.counts, result.err
+}
+
+// profileLoader collects the handles requested by concurrently resolving fields and fetches them
+// with one GetUserProfilesByHandles call per batch window, caching results for the rest of the query
+type profileLoader struct {
+	fetch func(ctx context.Context, handles []string) (map[string]*models.UserProfile, error)
+
+	mu      sync.Mutex
+	results map[string]*profileResult
+	pending []string
+}
+
+type profileResult struct {
+	done    chan struct{}
+	profile *models.UserProfile // nil when the user has no profile
+	err     error
+}
+
+// Load returns the profile for handle (nil when it doesn't exist)
+func (l *profileLoader) Load(ctx context.Context, handle string) (*models.UserProfile, error) {
+	l.mu.Lock()
+	result, ok := l.results[handle]
+	if !ok {
+		result = &profileResult{done: make(chan struct{})}
+		l.results[handle] = result
+		l.pending = append(l.pending, handle)
+		if len(l.pending) == 1 {
+			go l.dispatch(ctx) // ✅ First handle of a batch starts the window
+		}
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-result.done:
+		return result.profile, result.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// dispatch waits for the batch window, then resolves every pending handle with one fetch
+func (l *profileLoader) dispatch(ctx context.Context) {
+	time.Sleep(profileBatchWindow)
+
+	l.mu.Lock()
+	handles := l.pending
+	l.pending = nil
+	l.mu.Unlock()
+
+	profiles, err := l.fetch(ctx, handles)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, handle := range handles {
+		result := l.results[handle]
+		result.profile, result.err = profiles[handle], err
+		close(result.done)
+	}
+}
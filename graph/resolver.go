@@ -0,0 +1,288 @@
+// Package graph serves the /graphql endpoint: resolvers over the existing services so a screen can
+// fetch exactly the data it needs in one round trip.
+package graph
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"sort"
+	"vibin_server/helpers"
+	"vibin_server/models"
+	"vibin_server/services"
+	"vibin_server/utils"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/trace/otel"
+)
+
+//go:embed schema.graphql
+var schemaSource string
+
+// ✅ Query limits
+const (
+	defaultConnectionsPageSize = 30  // Same default as GET /api/v1/interactions/matches
+	maxConnectionsPageSize     = 100 // Same cap as GET /api/v1/interactions/matches
+	maxQueryDepth              = 6
+	maxParallelism             = 16 // Resolvers running at once per query
+)
+
+// Resolver is the root query resolver
+type Resolver struct {
+	UserProfileService *services.UserProfileService
+	InteractionService *services.InteractionService
+	ChatService        *services.ChatService
+}
+
+// NewSchema parses the schema and binds it to the resolvers
+func NewSchema(resolver *Resolver) (*graphql.Schema, error) {
+	return graphql.ParseSchema(schemaSource, resolver,
+		graphql.MaxDepth(maxQueryDepth),
+		graphql.MaxParallelism(maxParallelism),
+		graphql.Tracer(otel.DefaultTracer()), // ✅ Query and field spans under the HTTP server span
+	)
+}
+
+///// 🔹🔹🔹 Query 🔹🔹🔹 /////
+
+// Connections returns one page of matches for the connections screen
+func (r *Resolver) Connections(ctx context.Context, args struct {
+	UserHandle  string
+	First       *int32
+	After       *string
+	UnreadFirst *bool
+}) (*connectionsPageResolver, error) {
+	limit := int32(defaultConnectionsPageSize)
+	if args.First != nil && *args.First > 0 {
+		limit = min(*args.First, maxConnectionsPageSize)
+	}
+	cursor := ""
+	if args.After != nil {
+		cursor = *args.After
+	}
+
+	unreadFirst := args.UnreadFirst != nil && *args.UnreadFirst
+	if err := actsFor(ctx, args.UserHandle); err != nil {
+		return nil, err
+	}
+
+	matches, nextCursor, err := r.InteractionService.GetMutualMatches(ctx, args.UserHandle, unreadFirst, limit, cursor)
+	if err != nil {
+		return nil, publicError(ctx, err)
+	}
+	return &connectionsPageResolver{root: r, viewer: args.UserHandle, matches: matches, nextCursor: nextCursor}, nil
+}
+
+// Profile returns the session user's profile, or null when it doesn't exist
+func (r *Resolver) Profile(ctx context.Context, args struct{ UserHandle string }) (*profileResolver, error) {
+	if err := actsFor(ctx, args.UserHandle); err != nil {
+		return nil, err
+	}
+	return r.profile(ctx, args.UserHandle)
+}
+
+// profile loads any user's public profile; matches reach their profiles through it
+func (r *Resolver) profile(ctx context.Context, userHandle string) (*profileResolver, error) {
+	profile, err := loadersFrom(ctx).profiles.Load(ctx, userHandle)
+	if err != nil {
+		return nil, publicError(ctx, err)
+	}
+	if profile == nil {
+		return nil, nil
+	}
+	return &profileResolver{profile}, nil
+}
+
+// UnreadCounts returns the user's unread counts across matches and groups
+func (r *Resolver) UnreadCounts(ctx context.Context, args struct{ UserHandle string }) (*unreadCountsResolver, error) {
+	if err := actsFor(ctx, args.UserHandle); err != nil {
+		return nil, err
+	}
+	counts, err := loadersFrom(ctx).unreadCounts(ctx, r.ChatService, args.UserHandle)
+	if err != nil {
+		return nil, publicError(ctx, err)
+	}
+	return &unreadCountsResolver{counts}, nil
+}
+
+///// 🔹🔹🔹 ConnectionsPage / Match 🔹🔹🔹 /////
+
+type connectionsPageResolver struct {
+	root       *Resolver
+	viewer     string
+	matches    []models.MatchedUserDetailsForConnections
+	nextCursor string
+}
+
+func (p *connectionsPageResolver) Matches() []*matchResolver {
+	resolvers := make([]*matchResolver, len(p.matches))
+	for i := range p.matches {
+		resolvers[i] = &matchResolver{root: p.root, viewer: p.viewer, match: &p.matches[i]}
+	}
+	return resolvers
+}
+
+func (p *connectionsPageResolver) NextCursor() *string {
+	if p.nextCursor == "" {
+		return nil
+	}
+	return &p.nextCursor
+}
+
+func (p *connectionsPageResolver) UnreadCounts(ctx context.Context) (*unreadCountsResolver, error) {
+	return p.root.UnreadCounts(ctx, struct{ UserHandle string }{p.viewer})
+}
+
+type matchResolver struct {
+	root   *Resolver
+	viewer string
+	match  *models.MatchedUserDetailsForConnections
+}
+
+func (m *matchResolver) MatchID() graphql.ID     { return graphql.ID(m.match.MatchID) }
+func (m *matchResolver) UserHandle() string      { return m.match.UserHandle }
+func (m *matchResolver) Name() string            { return m.match.Name }
+func (m *matchResolver) EnrichmentError() bool   { return m.match.EnrichmentError }
+func (m *matchResolver) Photo() *string          { return optional(m.match.Photo) }
+func (m *matchResolver) LastActivityAt() *string { return optional(m.match.LastActivityAt) }
+func (m *matchResolver) LastMessage() *lastMessageResolver {
+	if m.match.LastMessage == "" && m.match.LastMessageSender == "" {
+		return nil
+	}
+	return &lastMessageResolver{m.match}
+}
+
+// UnreadCount reads the viewer's unread counts, fetched once for the whole page
+func (m *matchResolver) UnreadCount(ctx context.Context) (int32, error) {
+	counts, err := loadersFrom(ctx).unreadCounts(ctx, m.root.ChatService, m.viewer)
+	if err != nil {
+		return 0, publicError(ctx, err)
+	}
+	return int32(counts.Conversations[m.match.MatchID]), nil
+}
+
+// Profile goes through the loader, so all matches on the page share one BatchGetItem
+func (m *matchResolver) Profile(ctx context.Context) (*profileResolver, error) {
+	return m.root.profile(ctx, m.match.UserHandle)
+}
+
+type lastMessageResolver struct {
+	match *models.MatchedUserDetailsForConnections
+}
+
+func (l *lastMessageResolver) Content() string { return l.match.LastMessage }
+func (l *lastMessageResolver) Sender() string  { return l.match.LastMessageSender }
+func (l *lastMessageResolver) IsRead() bool    { return l.match.LastMessageIsRead }
+
+///// 🔹🔹🔹 Profile 🔹🔹🔹 /////
+
+// profileResolver exposes only public profile fields (no email, phone, location or subscription)
+type profileResolver struct {
+	profile *models.UserProfile
+}
+
+func (p *profileResolver) UserHandle() string  { return p.profile.UserHandle }
+func (p *profileResolver) Name() *string       { return optional(p.profile.Name) }
+func (p *profileResolver) Gender() *string     { return optional(p.profile.Gender) }
+func (p *profileResolver) Bio() *string        { return optional(p.profile.Bio) }
+func (p *profileResolver) LookingFor() *string { return optional(p.profile.LookingFor) }
+func (p *profileResolver) Photos() []string    { return nonNil(p.profile.Photos) }
+func (p *profileResolver) Interests() []string { return nonNil(p.profile.Interests) }
+func (p *profileResolver) Prompts() []*promptResolver {
+	resolvers := make([]*promptResolver, len(p.profile.Prompts))
+	for i := range p.profile.Prompts {
+		resolvers[i] = &promptResolver{&p.profile.Prompts[i]}
+	}
+	return resolvers
+}
+func (p *profileResolver) Age() *int32 {
+	if p.profile.Age == 0 {
+		return nil
+	}
+	age := int32(p.profile.Age)
+	return &age
+}
+
+type promptResolver struct {
+	prompt *models.ProfilePrompt
+}
+
+func (p *promptResolver) PromptID() graphql.ID { return graphql.ID(p.prompt.PromptID) }
+func (p *promptResolver) Question() string     { return p.prompt.Question }
+func (p *promptResolver) Answer() string       { return p.prompt.Answer }
+
+///// 🔹🔹🔹 UnreadCounts 🔹🔹🔹 /////
+
+type unreadCountsResolver struct {
+	counts *models.UnreadCounts
+}
+
+func (u *unreadCountsResolver) Total() int32 { return int32(u.counts.Total) }
+func (u *unreadCountsResolver) UnreadConversations() int32 {
+	return int32(u.counts.UnreadConversations)
+}
+func (u *unreadCountsResolver) Conversations() []*conversationUnreadResolver {
+	ids := make([]string, 0, len(u.counts.Conversations))
+	for id := range u.counts.Conversations {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids) // ✅ Stable order for clients diffing responses
+
+	resolvers := make([]*conversationUnreadResolver, len(ids))
+	for i, id := range ids {
+		resolvers[i] = &conversationUnreadResolver{id: id, unread: u.counts.Conversations[id]}
+	}
+	return resolvers
+}
+
+type conversationUnreadResolver struct {
+	id     string
+	unread int
+}
+
+func (c *conversationUnreadResolver) ConversationID() graphql.ID { return graphql.ID(c.id) }
+func (c *conversationUnreadResolver) Unread() int32              { return int32(c.unread) }
+
+///// 🔹🔹🔹 Helpers 🔹🔹🔹 /////
+
+func optional(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// publicError keeps raw DynamoDB/AWS text out of GraphQL responses, like helpers.WriteError does for REST
+// errOtherUser answers a query for a user other than the session's
+var errOtherUser = errors.New("the session belongs to another user")
+
+// actsFor refuses queries for anyone but the session's user, like helpers.ActsFor does for REST
+// handlers; requests without a session (while sessions aren't required) may name any user
+func actsFor(ctx context.Context, userHandle string) error {
+	if session := helpers.SessionFromContext(ctx); session != nil && session.UserHandle != userHandle {
+		return errOtherUser
+	}
+	return nil
+}
+
+func publicError(ctx context.Context, err error) error {
+	var serviceErr *services.ServiceError
+	switch {
+	case errors.As(err, &serviceErr):
+		return serviceErr
+	case errors.Is(err, utils.ErrInvalidCursor):
+		return utils.ErrInvalidCursor
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return err
+	default:
+		utils.Logf(ctx, "❌ GraphQL resolver failed: %v", err)
+		return errors.New("internal error")
+	}
+}
@@ -0,0 +1,73 @@
+package grpcapi
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+// codec puts the Go structs on the wire as protobuf. Values travel through their JSON form, so the
+// json tags the REST API already uses decide field names, omitempty and skipped fields. It is
+// registered for the "proto" content-subtype (application/grpc and application/grpc+proto), so
+// standard gRPC clients use it; generated protobuf messages, such as those of the reflection
+// service, are passed to the regular protobuf encoding.
+type codec struct{}
+
+// Name is the content-subtype the codec is registered for
+func (codec) Name() string { return "proto" }
+
+func (codec) Marshal(v interface{}) ([]byte, error) {
+	if m, ok := v.(proto.Message); ok {
+		return proto.Marshal(m)
+	}
+	s, err := defaultSchema()
+	if err != nil {
+		return nil, err
+	}
+	desc, err := s.messageFor(v)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("grpcapi: encode %T: %w", v, err)
+	}
+	msg := dynamicpb.NewMessage(desc)
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(raw, msg); err != nil {
+		return nil, fmt.Errorf("grpcapi: encode %T: %w", v, err)
+	}
+	return proto.Marshal(msg)
+}
+
+func (codec) Unmarshal(data []byte, v interface{}) error {
+	if m, ok := v.(proto.Message); ok {
+		return proto.Unmarshal(data, m)
+	}
+	s, err := defaultSchema()
+	if err != nil {
+		return err
+	}
+	desc, err := s.messageFor(v)
+	if err != nil {
+		return err
+	}
+
+	msg := dynamicpb.NewMessage(desc)
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return fmt.Errorf("grpcapi: decode %T: %w", v, err)
+	}
+	raw, err := protojson.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("grpcapi: decode %T: %w", v, err)
+	}
+	return json.Unmarshal(raw, v)
+}
@@ -0,0 +1,69 @@
+package grpcapi
+
+import "vibin_server/models"
+
+// ✅ Request/response envelopes for the gRPC methods; payload types are shared with the REST API in models/
+
+// GetProfileRequest looks up a profile by handle
+type GetProfileRequest struct {
+	UserHandle string `json:"userHandle"`
+}
+
+// CheckHandleRequest asks whether a handle is still free
+type CheckHandleRequest struct {
+	UserHandle string `json:"userHandle"`
+}
+
+// CheckHandleResponse reports handle availability
+type CheckHandleResponse struct {
+	Available bool `json:"available"`
+}
+
+// CreateInteractionRequest mirrors POST /api/v1/interactions
+type CreateInteractionRequest struct {
+	SenderHandle    string  `json:"senderHandle"`
+	ReceiverHandle  string  `json:"receiverHandle"`
+	InteractionType string  `json:"interactionType"`
+	Action          string  `json:"action"`
+	Message         *string `json:"message,omitempty"`
+	PhotoIndex      *int    `json:"photoIndex,omitempty"`
+}
+
+// CreateInteractionResponse reports whether the interaction produced a match
+type CreateInteractionResponse struct {
+	IsMatch        bool                       `json:"isMatch"`
+	MatchedProfile *models.MatchedUserDetails `json:"matchedProfile,omitempty"`
+}
+
+// ListMatchesRequest pages through a user's mutual matches
+type ListMatchesRequest struct {
+	UserHandle  string `json:"userHandle"`
+	Limit       int    `json:"limit,omitempty"`
+	Cursor      string `json:"cursor,omitempty"`
+	UnreadFirst bool   `json:"unreadFirst,omitempty"` // Matches with unread messages before the rest of the page
+}
+
+// ListMatchesResponse is one page of matches
+type ListMatchesResponse struct {
+	Matches    []models.MatchedUserDetailsForConnections `json:"matches"`
+	NextCursor string                                    `json:"nextCursor,omitempty"`
+}
+
+// SendMessageResponse returns the identifiers the server assigned
+type SendMessageResponse struct {
+	MessageID string `json:"messageId"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// ListMessagesRequest pages through a match's messages, newest first
+type ListMessagesRequest struct {
+	MatchID string `json:"matchId"`
+	Limit   int    `json:"limit,omitempty"`
+	Cursor  string `json:"cursor,omitempty"`
+}
+
+// ListMessagesResponse is one page of messages
+type ListMessagesResponse struct {
+	Messages   []models.Message `json:"messages"`
+	NextCursor string           `json:"nextCursor,omitempty"`
+}
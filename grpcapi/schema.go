@@ -0,0 +1,413 @@
+package grpcapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// ✅ Protobuf package and file every vibin message and service is declared in
+const (
+	ProtoPackage  = "vibin.v1"
+	ProtoFileName = "vibin/v1/vibin.proto"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+//go:embed fieldnumbers.json
+var fieldNumbersJSON []byte
+
+// FieldNumbers pins the protobuf number of every message field (message → field → number), so
+// reordering struct fields never renumbers the wire format. Numbers are never changed or reused:
+// a removed field stays listed and its number and name are reserved in the schema.
+type FieldNumbers map[string]map[string]int32
+
+// LoadFieldNumbers parses the checked-in registry (grpcapi/fieldnumbers.json)
+func LoadFieldNumbers() (FieldNumbers, error) {
+	numbers := FieldNumbers{}
+	if err := json.Unmarshal(fieldNumbersJSON, &numbers); err != nil {
+		return nil, fmt.Errorf("grpcapi: invalid fieldnumbers.json: %w", err)
+	}
+	return numbers, nil
+}
+
+// JSON renders the registry in the layout of fieldnumbers.json
+func (n FieldNumbers) JSON() ([]byte, error) {
+	out, err := json.MarshalIndent(n, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// assign gives a new field the number after every number its message has ever used
+func (n FieldNumbers) assign(message, field string) int32 {
+	if n[message] == nil {
+		n[message] = map[string]int32{}
+	}
+	next := int32(1)
+	for _, number := range n[message] {
+		next = max(next, number+1)
+	}
+	n[message][field] = next
+	return next
+}
+
+// schema is the protobuf file built from the Go structs the gRPC methods exchange.
+// Field numbers come from the FieldNumbers registry, not from struct order.
+type schema struct {
+	file     *descriptorpb.FileDescriptorProto
+	desc     protoreflect.FileDescriptor
+	messages map[reflect.Type]protoreflect.MessageDescriptor
+}
+
+// buildSchema derives one message per Go struct reachable from the methods and one service per group.
+// A field missing from numbers is an error unless assign is set, which records a new number for it.
+func buildSchema(methods []rpcMethod, numbers FieldNumbers, assign bool) (*schema, error) {
+	b := &schemaBuilder{
+		numbers: numbers,
+		assign:  assign,
+		file: &descriptorpb.FileDescriptorProto{
+			Name:    proto.String(ProtoFileName),
+			Package: proto.String(ProtoPackage),
+			Syntax:  proto.String("proto3"),
+		},
+		names: map[string]reflect.Type{},
+		types: map[reflect.Type]string{},
+	}
+
+	services := map[string]*descriptorpb.ServiceDescriptorProto{}
+	for _, m := range methods {
+		request, err := b.message(m.Request)
+		if err != nil {
+			return nil, err
+		}
+		response, err := b.message(m.Response)
+		if err != nil {
+			return nil, err
+		}
+
+		service, ok := services[m.Service]
+		if !ok {
+			service = &descriptorpb.ServiceDescriptorProto{Name: proto.String(m.Service)}
+			services[m.Service] = service
+			b.file.Service = append(b.file.Service, service)
+		}
+		service.Method = append(service.Method, &descriptorpb.MethodDescriptorProto{
+			Name:       proto.String(m.Method),
+			InputType:  proto.String(request),
+			OutputType: proto.String(response),
+		})
+	}
+
+	desc, err := protodesc.NewFile(b.file, protoregistry.GlobalFiles)
+	if err != nil {
+		return nil, fmt.Errorf("invalid protobuf schema: %w", err)
+	}
+
+	s := &schema{file: b.file, desc: desc, messages: map[reflect.Type]protoreflect.MessageDescriptor{}}
+	for t, name := range b.types {
+		s.messages[t] = desc.Messages().ByName(protoreflect.Name(name))
+	}
+	return s, nil
+}
+
+// messageFor returns the descriptor of v's struct type
+func (s *schema) messageFor(v interface{}) (protoreflect.MessageDescriptor, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	desc, ok := s.messages[t]
+	if !ok {
+		return nil, fmt.Errorf("grpcapi: %v is not part of the protobuf schema", t)
+	}
+	return desc, nil
+}
+
+type schemaBuilder struct {
+	file    *descriptorpb.FileDescriptorProto
+	names   map[string]reflect.Type // Message name -> Go type, to catch two structs with the same name
+	types   map[reflect.Type]string // Go type -> message name
+	numbers FieldNumbers
+	assign  bool
+}
+
+// message adds t (and every struct it references) to the file and returns its fully-qualified name
+func (b *schemaBuilder) message(t reflect.Type) (string, error) {
+	if name, ok := b.types[t]; ok {
+		return qualified(name), nil
+	}
+	name := t.Name()
+	if other, ok := b.names[name]; ok {
+		return "", fmt.Errorf("grpcapi: %v and %v both map to message %s", t, other, name)
+	}
+	b.names[name] = t
+	b.types[t] = name
+
+	msg := &descriptorpb.DescriptorProto{Name: proto.String(name)}
+	b.file.MessageType = append(b.file.MessageType, msg) // ✅ Added before fields so recursive types resolve
+
+	used := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		jsonName, ok := jsonFieldName(sf)
+		if !ok {
+			continue
+		}
+		fieldName := snakeCase(jsonName)
+		number, ok := b.numbers[name][fieldName]
+		if !ok {
+			if !b.assign {
+				return "", fmt.Errorf("grpcapi: %v.%s has no field number; run go run ./cmd/protogen -assign", t, sf.Name)
+			}
+			number = b.numbers.assign(name, fieldName)
+		}
+		used[fieldName] = true
+
+		field := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(fieldName),
+			JsonName: proto.String(jsonName),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		}
+		if err := b.fieldType(msg, field, sf.Type); err != nil {
+			return "", fmt.Errorf("grpcapi: %v.%s: %w", t, sf.Name, err)
+		}
+		msg.Field = append(msg.Field, field)
+	}
+
+	// ✅ Numbers and names of removed fields stay reserved so they are never reused
+	var removed []string
+	for fieldName := range b.numbers[name] {
+		if !used[fieldName] {
+			removed = append(removed, fieldName)
+		}
+	}
+	sort.Slice(removed, func(i, j int) bool { return b.numbers[name][removed[i]] < b.numbers[name][removed[j]] })
+	for _, fieldName := range removed {
+		number := b.numbers[name][fieldName]
+		msg.ReservedRange = append(msg.ReservedRange, &descriptorpb.DescriptorProto_ReservedRange{Start: proto.Int32(number), End: proto.Int32(number + 1)})
+		msg.ReservedName = append(msg.ReservedName, fieldName)
+	}
+	return qualified(name), nil
+}
+
+// fieldType fills in the protobuf type of a Go field: pointers to scalars become proto3 optional,
+// slices become repeated fields and maps become map<string, V>
+func (b *schemaBuilder) fieldType(msg *descriptorpb.DescriptorProto, field *descriptorpb.FieldDescriptorProto, t reflect.Type) error {
+	switch {
+	case t.Kind() == reflect.Ptr && t.Elem().Kind() != reflect.Struct:
+		if err := b.setType(field, t.Elem()); err != nil {
+			return err
+		}
+		field.Proto3Optional = proto.Bool(true)
+		field.OneofIndex = proto.Int32(int32(len(msg.OneofDecl)))
+		msg.OneofDecl = append(msg.OneofDecl, &descriptorpb.OneofDescriptorProto{Name: proto.String("_" + field.GetName())})
+		return nil
+	case t.Kind() == reflect.Slice:
+		field.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+		return b.setType(field, t.Elem())
+	case t.Kind() == reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return fmt.Errorf("map keys must be strings")
+		}
+		entry := &descriptorpb.DescriptorProto{
+			Name:    proto.String(camelCase(field.GetName()) + "Entry"),
+			Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+		}
+		key := &descriptorpb.FieldDescriptorProto{Name: proto.String("key"), JsonName: proto.String("key"), Number: proto.Int32(1), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()}
+		value := &descriptorpb.FieldDescriptorProto{Name: proto.String("value"), JsonName: proto.String("value"), Number: proto.Int32(2), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()}
+		if err := b.setType(key, t.Key()); err != nil {
+			return err
+		}
+		if err := b.setType(value, t.Elem()); err != nil {
+			return err
+		}
+		entry.Field = []*descriptorpb.FieldDescriptorProto{key, value}
+		msg.NestedType = append(msg.NestedType, entry)
+
+		field.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+		field.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+		field.TypeName = proto.String(qualified(msg.GetName() + "." + entry.GetName()))
+		return nil
+	default:
+		return b.setType(field, t)
+	}
+}
+
+// setType maps a Go scalar or struct type onto the field
+func (b *schemaBuilder) setType(field *descriptorpb.FieldDescriptorProto, t reflect.Type) error {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		field.Type = descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum() // ✅ RFC3339, as in the JSON API
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		field.Type = descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()
+	case reflect.Bool:
+		field.Type = descriptorpb.FieldDescriptorProto_TYPE_BOOL.Enum()
+	case reflect.Int, reflect.Int32:
+		field.Type = descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum()
+	case reflect.Float32, reflect.Float64:
+		field.Type = descriptorpb.FieldDescriptorProto_TYPE_DOUBLE.Enum()
+	case reflect.Struct:
+		name, err := b.message(t)
+		if err != nil {
+			return err
+		}
+		field.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+		field.TypeName = proto.String(name)
+	default:
+		// int64 is left out on purpose: protojson quotes it, which encoding/json can't read back into an int64
+		return fmt.Errorf("unsupported type %v", t)
+	}
+	return nil
+}
+
+// jsonFieldName returns the JSON key of an exported field, or false when JSON skips it
+func jsonFieldName(sf reflect.StructField) (string, bool) {
+	if !sf.IsExported() || sf.Anonymous {
+		return "", false
+	}
+	name := strings.Split(sf.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return "", false
+	}
+	if name == "" {
+		name = sf.Name
+	}
+	return name, true
+}
+
+func qualified(name string) string {
+	return "." + ProtoPackage + "." + name
+}
+
+// snakeCase turns a JSON key like "matchId" or "PK" into a protobuf field name ("match_id", "pk")
+func snakeCase(name string) string {
+	var out []rune
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				out = append(out, '_')
+			}
+			r = unicode.ToLower(r)
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
+
+// camelCase turns "match_id" into "MatchId" (used for map entry message names)
+func camelCase(name string) string {
+	parts := strings.Split(name, "_")
+	for i, part := range parts {
+		if part != "" {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+///// 🔹🔹🔹 .proto source 🔹🔹🔹 /////
+
+// AssignFieldNumbers returns the registry with numbers recorded for fields that don't have one yet
+func AssignFieldNumbers() (FieldNumbers, error) {
+	numbers, err := LoadFieldNumbers()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := buildSchema(methods, numbers, true); err != nil {
+		return nil, err
+	}
+	return numbers, nil
+}
+
+// ProtoSource renders the schema as a .proto file for generating typed clients
+func ProtoSource() (string, error) {
+	s, err := defaultSchema()
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	out.WriteString("// Code generated by cmd/protogen from the Go structs in models/ and grpcapi/. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "syntax = \"proto3\";\n\npackage %s;\n\noption go_package = \"vibin_server/grpcapi/vibinpb\";\n", ProtoPackage)
+
+	for _, service := range s.file.Service {
+		fmt.Fprintf(&out, "\nservice %s {\n", service.GetName())
+		for _, m := range service.Method {
+			fmt.Fprintf(&out, "  rpc %s(%s) returns (%s);\n", m.GetName(), shortName(m.GetInputType()), shortName(m.GetOutputType()))
+		}
+		out.WriteString("}\n")
+	}
+
+	messages := append([]*descriptorpb.DescriptorProto(nil), s.file.MessageType...)
+	sort.Slice(messages, func(i, j int) bool { return messages[i].GetName() < messages[j].GetName() })
+	for _, msg := range messages {
+		fmt.Fprintf(&out, "\nmessage %s {\n", msg.GetName())
+		for _, field := range msg.Field {
+			fmt.Fprintf(&out, "  %s %s = %d;\n", fieldTypeSource(msg, field), field.GetName(), field.GetNumber())
+		}
+		if len(msg.ReservedRange) > 0 {
+			numbers := make([]string, len(msg.ReservedRange))
+			names := make([]string, len(msg.ReservedName))
+			for i, r := range msg.ReservedRange {
+				numbers[i] = fmt.Sprint(r.GetStart())
+			}
+			for i, name := range msg.ReservedName {
+				names[i] = fmt.Sprintf("%q", name)
+			}
+			fmt.Fprintf(&out, "  reserved %s;\n  reserved %s;\n", strings.Join(numbers, ", "), strings.Join(names, ", "))
+		}
+		out.WriteString("}\n")
+	}
+	return out.String(), nil
+}
+
+// fieldTypeSource prints the type part of a field declaration, including its label
+func fieldTypeSource(msg *descriptorpb.DescriptorProto, field *descriptorpb.FieldDescriptorProto) string {
+	for _, entry := range msg.NestedType {
+		if entry.GetOptions().GetMapEntry() && field.GetTypeName() == qualified(msg.GetName()+"."+entry.GetName()) {
+			return fmt.Sprintf("map<%s, %s>", scalarSource(entry.Field[0]), scalarSource(entry.Field[1]))
+		}
+	}
+
+	typ := scalarSource(field)
+	switch {
+	case field.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REPEATED:
+		return "repeated " + typ
+	case field.GetProto3Optional():
+		return "optional " + typ
+	default:
+		return typ
+	}
+}
+
+func scalarSource(field *descriptorpb.FieldDescriptorProto) string {
+	if field.GetType() == descriptorpb.FieldDescriptorProto_TYPE_MESSAGE {
+		return shortName(field.GetTypeName())
+	}
+	return strings.ToLower(strings.TrimPrefix(field.GetType().String(), "TYPE_"))
+}
+
+func shortName(qualifiedName string) string {
+	return strings.TrimPrefix(qualifiedName, "."+ProtoPackage+".")
+}
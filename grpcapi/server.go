@@ -0,0 +1,351 @@
+// Package grpcapi exposes the core profile, interaction and chat operations over gRPC for internal
+// services. It shares the service layer with the REST API; the protobuf schema is derived from the
+// same Go structs (see ProtoSource and api/proto/vibin/v1/vibin.proto).
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+	"vibin_server/helpers"
+	"vibin_server/models"
+	"vibin_server/services"
+	"vibin_server/utils"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// ✅ Page sizes, matching the REST endpoints
+const (
+	defaultMatchesPageSize = 30
+	maxMatchesPageSize     = 100
+	defaultMessagesPerPage = 50
+	maxMessagesPerPage     = 200
+)
+
+// requestIDMetadataKey carries the request ID in gRPC metadata (same value as the X-Request-ID header)
+const requestIDMetadataKey = "x-request-id"
+
+// Server implements the vibin.v1 gRPC services on top of the shared services
+type Server struct {
+	UserProfileService *services.UserProfileService
+	InteractionService *services.InteractionService
+	ChatService        *services.ChatService
+}
+
+// rpcMethod is one unary method: its place in the schema and its gRPC handler
+type rpcMethod struct {
+	Service  string
+	Method   string
+	Request  reflect.Type
+	Response reflect.Type
+	Desc     grpc.MethodDesc
+}
+
+// methods lists every RPC; the schema and service descriptors are built from it
+var methods = []rpcMethod{
+	unary("ProfileService", "GetProfile", (*Server).GetProfile),
+	unary("ProfileService", "CheckHandle", (*Server).CheckHandle),
+	unary("InteractionService", "CreateInteraction", (*Server).CreateInteraction),
+	unary("InteractionService", "ListMatches", (*Server).ListMatches),
+	unary("ChatService", "SendMessage", (*Server).SendMessage),
+	unary("ChatService", "ListMessages", (*Server).ListMessages),
+}
+
+var (
+	schemaOnce   sync.Once
+	sharedSchema *schema
+	schemaErr    error
+)
+
+// defaultSchema builds the schema once and registers it so gRPC reflection can describe it
+func defaultSchema() (*schema, error) {
+	schemaOnce.Do(func() {
+		var numbers FieldNumbers
+		if numbers, schemaErr = LoadFieldNumbers(); schemaErr != nil {
+			return
+		}
+		sharedSchema, schemaErr = buildSchema(methods, numbers, false)
+		if schemaErr == nil {
+			schemaErr = protoregistry.GlobalFiles.RegisterFile(sharedSchema.desc)
+		}
+	})
+	return sharedSchema, schemaErr
+}
+
+// NewGRPCServer builds a gRPC server with every vibin.v1 service, request IDs, a per-call timeout and reflection
+func NewGRPCServer(api *Server, requestTimeout time.Duration) (*grpc.Server, error) {
+	if _, err := defaultSchema(); err != nil {
+		return nil, err
+	}
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(requestIDInterceptor, timeoutInterceptor(requestTimeout)),
+	)
+
+	descs := map[string]*grpc.ServiceDesc{}
+	var order []string
+	for _, m := range methods {
+		desc, ok := descs[m.Service]
+		if !ok {
+			desc = &grpc.ServiceDesc{
+				ServiceName: ProtoPackage + "." + m.Service,
+				HandlerType: (*interface{})(nil),
+				Metadata:    ProtoFileName,
+			}
+			descs[m.Service] = desc
+			order = append(order, m.Service)
+		}
+		desc.Methods = append(desc.Methods, m.Desc)
+	}
+	for _, name := range order {
+		server.RegisterService(descs[name], api)
+	}
+
+	reflection.Register(server) // ✅ grpcurl / grpcui can list and call methods without the .proto
+	return server, nil
+}
+
+// unary adapts a typed Server method to a gRPC method handler
+func unary[Req, Resp any](service, method string, call func(*Server, context.Context, *Req) (*Resp, error)) rpcMethod {
+	fullMethod := "/" + ProtoPackage + "." + service + "/" + method
+	return rpcMethod{
+		Service:  service,
+		Method:   method,
+		Request:  reflect.TypeOf((*Req)(nil)).Elem(),
+		Response: reflect.TypeOf((*Resp)(nil)).Elem(),
+		Desc: grpc.MethodDesc{
+			MethodName: method,
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				request := new(Req)
+				if err := dec(request); err != nil {
+					return nil, err
+				}
+				handler := func(ctx context.Context, request interface{}) (interface{}, error) {
+					response, err := call(srv.(*Server), ctx, request.(*Req))
+					if err != nil {
+						return nil, statusError(ctx, err)
+					}
+					return response, nil
+				}
+				if interceptor == nil {
+					return handler(ctx, request)
+				}
+				return interceptor(ctx, request, &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod}, handler)
+			},
+		},
+	}
+}
+
+///// 🔹🔹🔹 ProfileService 🔹🔹🔹 /////
+
+// GetProfile returns a user's profile
+func (s *Server) GetProfile(ctx context.Context, request *GetProfileRequest) (*models.UserProfile, error) {
+	if err := validate(func(v *helpers.Validator) { v.Required("userHandle", request.UserHandle) }); err != nil {
+		return nil, err
+	}
+	return s.UserProfileService.GetUserProfileByHandle(ctx, request.UserHandle)
+}
+
+// CheckHandle reports whether a handle is still free
+func (s *Server) CheckHandle(ctx context.Context, request *CheckHandleRequest) (*CheckHandleResponse, error) {
+	if err := validate(func(v *helpers.Validator) { v.Handle("userHandle", request.UserHandle) }); err != nil {
+		return nil, err
+	}
+	available, err := s.UserProfileService.IsUserHandleAvailable(ctx, request.UserHandle)
+	if err != nil {
+		return nil, err
+	}
+	return &CheckHandleResponse{Available: available}, nil
+}
+
+///// 🔹🔹🔹 InteractionService 🔹🔹🔹 /////
+
+// CreateInteraction records a like, dislike or ping and reports whether it produced a match
+func (s *Server) CreateInteraction(ctx context.Context, request *CreateInteractionRequest) (*CreateInteractionResponse, error) {
+	err := validate(func(v *helpers.Validator) {
+		v.Required("senderHandle", request.SenderHandle)
+		v.Required("receiverHandle", request.ReceiverHandle)
+		v.Required("interactionType", request.InteractionType)
+		v.Required("action", request.Action)
+		v.Check(request.SenderHandle == "" || request.SenderHandle != request.ReceiverHandle, "receiverHandle", "must differ from senderHandle")
+		v.Check(request.PhotoIndex == nil || (*request.PhotoIndex >= 0 && *request.PhotoIndex < helpers.MaxPhotos), "photoIndex", "must be a valid photo position")
+		if request.Message != nil {
+			v.MaxLength("message", *request.Message, helpers.MaxMessageLength)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	isMatch, matchedProfile, err := s.InteractionService.CreateOrUpdateInteraction(ctx,
+		request.SenderHandle, request.ReceiverHandle, request.InteractionType, request.Action, request.Message, request.PhotoIndex)
+	if err != nil {
+		return nil, err
+	}
+	return &CreateInteractionResponse{IsMatch: isMatch, MatchedProfile: matchedProfile}, nil
+}
+
+// ListMatches returns one page of mutual matches
+func (s *Server) ListMatches(ctx context.Context, request *ListMatchesRequest) (*ListMatchesResponse, error) {
+	if err := validate(func(v *helpers.Validator) { v.Required("userHandle", request.UserHandle) }); err != nil {
+		return nil, err
+	}
+	limit := request.Limit
+	if limit <= 0 {
+		limit = defaultMatchesPageSize
+	}
+	if limit > maxMatchesPageSize {
+		limit = maxMatchesPageSize
+	}
+
+	matches, nextCursor, err := s.InteractionService.GetMutualMatches(ctx, request.UserHandle, request.UnreadFirst, int32(limit), request.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	return &ListMatchesResponse{Matches: matches, NextCursor: nextCursor}, nil
+}
+
+///// 🔹🔹🔹 ChatService 🔹🔹🔹 /////
+
+// SendMessage stores a 1:1 chat message, applying the same moderation and settings as the REST API
+func (s *Server) SendMessage(ctx context.Context, message *models.Message) (*SendMessageResponse, error) {
+	err := validate(func(v *helpers.Validator) {
+		v.Required("matchId", message.MatchID)
+		v.Required("senderId", message.SenderID)
+		if message.E2E == nil { // ✅ End-to-end encrypted messages carry ciphertexts instead
+			v.Required("content", message.Content)
+		}
+		v.MaxLength("content", message.Content, helpers.MaxMessageLength)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if message.MessageID == "" {
+		message.MessageID = uuid.New().String()
+	}
+	message.CreatedAt = time.Now().Format(time.RFC3339)
+	message.SetIsUnread(true)
+
+	if err := s.ChatService.SendMessage(ctx, *message); err != nil {
+		return nil, err
+	}
+	return &SendMessageResponse{MessageID: message.MessageID, CreatedAt: message.CreatedAt}, nil
+}
+
+// ListMessages returns one page of a match's messages, newest first
+func (s *Server) ListMessages(ctx context.Context, request *ListMessagesRequest) (*ListMessagesResponse, error) {
+	if err := validate(func(v *helpers.Validator) { v.Required("matchId", request.MatchID) }); err != nil {
+		return nil, err
+	}
+	limit := request.Limit
+	if limit <= 0 {
+		limit = defaultMessagesPerPage
+	}
+	if limit > maxMessagesPerPage {
+		limit = maxMessagesPerPage
+	}
+
+	messages, nextCursor, err := s.ChatService.GetMessagesByMatchID(ctx, request.MatchID, limit, request.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	return &ListMessagesResponse{Messages: messages, NextCursor: nextCursor}, nil
+}
+
+///// 🔹🔹🔹 Errors and interceptors 🔹🔹🔹 /////
+
+// validate runs the shared payload rules and turns field errors into InvalidArgument
+func validate(rules func(v *helpers.Validator)) error {
+	var v helpers.Validator
+	rules(&v)
+	if v.Valid() {
+		return nil
+	}
+	message := "validation failed:"
+	for _, fieldErr := range v.Errors {
+		message += " " + fieldErr.Field + " " + fieldErr.Message + ";"
+	}
+	return status.Error(codes.InvalidArgument, message)
+}
+
+// statusError maps service errors onto gRPC codes; like the REST API, raw AWS errors never reach the caller
+func statusError(ctx context.Context, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var serviceErr *services.ServiceError
+	var firstMessageErr *services.FirstMessageError
+	switch {
+	case errors.As(err, &serviceErr):
+		return status.Error(serviceCode(serviceErr.Kind), serviceErr.Message)
+	case errors.As(err, &firstMessageErr):
+		return status.Error(codes.FailedPrecondition, firstMessageErr.Reason)
+	case errors.Is(err, utils.ErrInvalidCursor):
+		return status.Error(codes.InvalidArgument, "invalid cursor")
+	case errors.Is(err, services.ErrLikeLimitReached), errors.Is(err, services.ErrQuotaExceeded):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, services.ErrContentRejected), errors.Is(err, services.ErrMediaNotAllowed):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, services.ErrPremiumRequired):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, services.ErrNotFound), errors.Is(err, services.ErrConflict), errors.Is(err, services.ErrValidation):
+		code := serviceCode(err)
+		return status.Error(code, code.String()) // ✅ Wrapped DynamoDB text stays in the logs
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "deadline exceeded")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, "request canceled")
+	default:
+		utils.Logf(ctx, "❌ gRPC call failed: %v", err)
+		return status.Error(codes.Internal, "internal error")
+	}
+}
+
+func serviceCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		return codes.NotFound
+	case errors.Is(err, services.ErrConflict):
+		return codes.FailedPrecondition
+	case errors.Is(err, services.ErrValidation):
+		return codes.InvalidArgument
+	default:
+		return codes.Internal
+	}
+}
+
+// requestIDInterceptor accepts or generates an x-request-id, puts it in the context for logging and echoes it back
+func requestIDInterceptor(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	var requestID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDMetadataKey); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+	requestID = helpers.NormalizeRequestID(requestID)
+	grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadataKey, requestID))
+
+	ctx = utils.WithRequestID(ctx, requestID)
+	utils.Logf(ctx, "📡 gRPC %s", info.FullMethod)
+	return handler(ctx, request)
+}
+
+// timeoutInterceptor applies the same per-request deadline as the REST API
+func timeoutInterceptor(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return handler(ctx, request)
+	}
+}
@@ -0,0 +1,33 @@
+package helpers
+
+import (
+	"net/http"
+	"strings"
+)
+
+// APIVersionHeader tells clients which API version served the request
+const APIVersionHeader = "X-API-Version"
+
+// APIVersionMiddleware stamps responses with the version of the route group that matched
+func APIVersionMiddleware(version string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(APIVersionHeader, version)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// LegacyAPIMiddleware serves unversioned /api/... requests as version and points clients at the
+// versioned path through the Deprecation and Link headers (RFC 8594 / 9745)
+func LegacyAPIMiddleware(version string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			successor := "/api/" + version + strings.TrimPrefix(r.URL.Path, "/api")
+			w.Header().Set(APIVersionHeader, version)
+			w.Header().Set("Deprecation", "true")
+			w.Header().Set("Link", "<"+successor+`>; rel="successor-version"`)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package helpers
+
+import (
+	"net/http"
+	"slices"
+)
+
+// AdminAuthMiddleware restricts a route group to admins: the request needs a valid session (set by
+// SessionMiddleware) whose user is one of admins. With no admins configured every request is refused.
+func AdminAuthMiddleware(admins []string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			session := SessionFromContext(r.Context())
+			if session == nil {
+				http.Error(w, "An admin session token is required", http.StatusUnauthorized)
+				return
+			}
+			if !slices.Contains(admins, session.UserHandle) {
+				http.Error(w, "Admin access is required", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// AdminHandle is the admin calling a route behind AdminAuthMiddleware. Admin actions are attributed to
+// it, never to a name in the request body.
+func AdminHandle(r *http.Request) string {
+	return SessionFromContext(r.Context()).UserHandle
+}
@@ -0,0 +1,227 @@
+package helpers
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"expvar"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// compressionMetrics exposes compression counters on /admin/debug/vars: "<encoding>.responses",
+// "<encoding>.bytes_in" (uncompressed) and "<encoding>.bytes_out" (sent), so savings can be measured
+var compressionMetrics = expvar.NewMap("compression")
+
+// compressionEncoder is a content coding the server can produce
+type compressionEncoder struct {
+	name string
+	pool *sync.Pool // of resettableWriter
+}
+
+// resettableWriter is what compress/* writers have in common
+type resettableWriter interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// compressionEncoders are the codings offered, most preferred first. Brotli can be added here once an
+// encoder is vendored; until then "br"-only clients get identity responses.
+var compressionEncoders = []compressionEncoder{
+	{name: "gzip", pool: &sync.Pool{New: func() any {
+		writer, _ := gzip.NewWriterLevel(io.Discard, gzip.DefaultCompression)
+		return writer
+	}}},
+}
+
+// compressibleTypes are the Content-Type prefixes worth compressing (images and video already are)
+var compressibleTypes = []string{"application/json", "application/graphql-response+json", "text/", "application/javascript", "application/xml"}
+
+// CompressionMiddleware compresses responses of at least minSize bytes with the best coding the
+// client accepts (Accept-Encoding, honoring q-values). Smaller bodies, already-encoded bodies and
+// non-text content types pass through untouched; minSize <= 0 disables compression.
+func CompressionMiddleware(minSize int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if minSize <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			encoder := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+			if encoder == nil || r.Method == http.MethodHead || r.Header.Get("Range") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{ResponseWriter: w, encoder: encoder, minSize: minSize, status: http.StatusOK}
+			defer cw.Close()
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// negotiateEncoding picks the most preferred encoder with the highest q-value in acceptEncoding
+func negotiateEncoding(acceptEncoding string) *compressionEncoder {
+	if acceptEncoding == "" {
+		return nil
+	}
+	qualities := map[string]float64{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				quality = parsed
+			}
+		}
+		qualities[strings.ToLower(strings.TrimSpace(name))] = quality
+	}
+
+	var best *compressionEncoder
+	bestQuality := 0.0
+	for i := range compressionEncoders {
+		quality, ok := qualities[compressionEncoders[i].name]
+		if !ok {
+			quality, ok = qualities["*"]
+		}
+		if ok && quality > bestQuality {
+			best, bestQuality = &compressionEncoders[i], quality
+		}
+	}
+	return best
+}
+
+// compressWriter buffers the start of a response until it knows whether compressing is worth it:
+// minSize bytes arrived (compress), the handler finished first (send as is), or the handler flushed
+type compressWriter struct {
+	http.ResponseWriter
+	encoder *compressionEncoder
+	minSize int
+
+	status      int
+	wroteHeader bool // The handler called WriteHeader (buffered until the decision)
+	decided     bool
+	buffer      bytes.Buffer
+	writer      resettableWriter // Set once compressing
+	counter     countingWriter
+	bytesIn     int64
+}
+
+func (c *compressWriter) WriteHeader(status int) {
+	if c.wroteHeader || c.decided {
+		return
+	}
+	c.status, c.wroteHeader = status, true
+}
+
+func (c *compressWriter) Write(p []byte) (int, error) {
+	if !c.decided {
+		c.buffer.Write(p)
+		if c.buffer.Len() < c.minSize {
+			return len(p), nil
+		}
+		if err := c.decide(true); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	if c.writer != nil {
+		c.bytesIn += int64(len(p))
+		return c.writer.Write(p)
+	}
+	return c.ResponseWriter.Write(p)
+}
+
+// decide commits the headers, compressing when large is set and the response qualifies, then
+// writes out the buffered bytes
+func (c *compressWriter) decide(large bool) error {
+	c.decided = true
+	header := c.Header()
+	if large && c.compressible(header) {
+		header.Set("Content-Encoding", c.encoder.name)
+		header.Del("Content-Length")
+		c.counter.w = c.ResponseWriter
+		c.writer = c.encoder.pool.Get().(resettableWriter)
+		c.writer.Reset(&c.counter)
+	}
+	c.ResponseWriter.WriteHeader(c.status)
+	if c.buffer.Len() == 0 {
+		return nil
+	}
+	_, err := c.Write(c.buffer.Bytes())
+	c.buffer.Reset()
+	return err
+}
+
+// compressible reports whether the response may be compressed
+func (c *compressWriter) compressible(header http.Header) bool {
+	if header.Get("Content-Encoding") != "" || c.status < http.StatusOK || c.status == http.StatusNoContent || c.status == http.StatusNotModified {
+		return false
+	}
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(c.buffer.Bytes())
+	}
+	for _, prefix := range compressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Flush sends what the handler wrote so far, compressed when it is already large enough
+func (c *compressWriter) Flush() {
+	if !c.decided {
+		c.decide(c.buffer.Len() >= c.minSize)
+	}
+	if c.writer != nil {
+		c.writer.Flush()
+	}
+	if flusher, ok := c.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack hands the connection over (e.g. for WebSocket upgrades), bypassing compression
+func (c *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	c.decided = true
+	return http.NewResponseController(c.ResponseWriter).Hijack()
+}
+
+// Close finishes the response: small responses go out as is, compressed ones get their trailer
+func (c *compressWriter) Close() {
+	if !c.decided {
+		c.decide(false)
+	}
+	if c.writer == nil {
+		return
+	}
+	c.writer.Close()
+	c.writer.Reset(io.Discard)
+	c.encoder.pool.Put(c.writer)
+
+	compressionMetrics.Add(c.encoder.name+".responses", 1)
+	compressionMetrics.Add(c.encoder.name+".bytes_in", c.bytesIn)
+	compressionMetrics.Add(c.encoder.name+".bytes_out", c.counter.n)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (c *compressWriter) Unwrap() http.ResponseWriter { return c.ResponseWriter }
+
+// countingWriter counts the compressed bytes sent
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,48 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"vibin_server/services"
+	"vibin_server/utils"
+)
+
+// ErrorStatus maps service error categories to HTTP status codes (500 for anything unclassified)
+func ErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrValidation), errors.Is(err, utils.ErrInvalidCursor):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, services.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// WriteError writes a plain-text error for err. Only messages of services.ServiceError reach the client;
+// anything else (raw DynamoDB/AWS errors) is logged and replaced by the generic status text.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	status := ErrorStatus(err)
+
+	message := http.StatusText(status)
+	var serviceErr *services.ServiceError
+	if errors.As(err, &serviceErr) {
+		message = serviceErr.Message
+	} else if errors.Is(err, utils.ErrInvalidCursor) {
+		message = "Invalid cursor parameter"
+	}
+
+	if status >= http.StatusInternalServerError {
+		utils.Logf(r.Context(), "❌ %s %s failed: %v", r.Method, r.URL.Path, err)
+	}
+	http.Error(w, message, status)
+}
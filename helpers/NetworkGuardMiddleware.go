@@ -0,0 +1,43 @@
+package helpers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+	"vibin_server/services"
+	"vibin_server/utils"
+)
+
+// NetworkGuardMiddleware refuses requests from blocked networks with 403 and throttles busy IPs and
+// ASNs with 429 + Retry-After. The client's ASN and country come from headers the edge sets (empty
+// names disable those checks); exempt skips everything, e.g. for load balancer health probes.
+func NetworkGuardMiddleware(guard *services.NetworkGuardService, asnHeader, countryHeader string, exempt func(r *http.Request) bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if exempt(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ip := ClientIP(r)
+			var asn, country string
+			if asnHeader != "" {
+				asn = r.Header.Get(asnHeader)
+			}
+			if countryHeader != "" {
+				country = r.Header.Get(countryHeader)
+			}
+
+			if block := guard.Blocked(ip, asn, country); block != nil {
+				utils.Logf(r.Context(), "🚧 Refused %s %s from %s (asn=%s, country=%s): %s block %s", r.Method, r.URL.Path, ip, asn, country, block.Kind, block.BlockID)
+				http.Error(w, "Access from your network is blocked", http.StatusForbidden)
+				return
+			}
+			if quotaErr := guard.Throttle(ip, asn); quotaErr != nil {
+				w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(quotaErr.RetryAt).Seconds())+1))
+				WriteJSONResponse(w, http.StatusTooManyRequests, quotaErr)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package helpers
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// NextCursorHeader carries the next-page cursor for list endpoints whose body is a bare array
+const NextCursorHeader = "X-Next-Cursor"
+
+// AfterCursorHeader carries the cursor for newer items, on endpoints that page both ways
+const AfterCursorHeader = "X-After-Cursor"
+
+// PageLimit reads ?limit=, falling back to defaultLimit when missing or invalid and capping at maxLimit
+func PageLimit(r *http.Request, defaultLimit, maxLimit int) int {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		return defaultLimit
+	}
+	if limit > maxLimit {
+		return maxLimit
+	}
+	return limit
+}
+
+// SetNextCursor sets the next-page cursor header; call before writing the body ("" = last page)
+func SetNextCursor(w http.ResponseWriter, cursor string) {
+	if cursor != "" {
+		w.Header().Set(NextCursorHeader, cursor)
+	}
+}
+
+// SetAfterCursor sets the newer-items cursor header; call before writing the body
+func SetAfterCursor(w http.ResponseWriter, cursor string) {
+	if cursor != "" {
+		w.Header().Set(AfterCursorHeader, cursor)
+	}
+}
@@ -0,0 +1,59 @@
+package helpers
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"vibin_server/utils"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the request ID in both directions
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDPattern is what we accept from callers (anything else is replaced with a fresh ID)
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestIDMiddleware accepts or generates an X-Request-ID, puts it in the request context for
+// logging, echoes it on every response and appends it to plain-text error bodies so users can quote it
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := NormalizeRequestID(r.Header.Get(RequestIDHeader))
+
+		w.Header().Set(RequestIDHeader, requestID)
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(utils.WithRequestID(r.Context(), requestID)))
+
+		if recorder.status >= 400 && strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain") {
+			fmt.Fprintf(w, "Request ID: %s\n", requestID)
+		}
+	})
+}
+
+// NormalizeRequestID keeps a caller-supplied ID when it is well-formed and generates a fresh one otherwise
+func NormalizeRequestID(requestID string) string {
+	if !requestIDPattern.MatchString(requestID) {
+		return uuid.New().String()
+	}
+	return requestID
+}
+
+// statusRecorder remembers the status code the handler wrote
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+// Flush keeps streaming responses working through the wrapper
+func (s *statusRecorder) Flush() {
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -1,8 +1,11 @@
 package helpers
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"net/http"
+	"strings"
 )
 
 // WriteJSONResponse writes a JSON response to the client
@@ -11,3 +14,45 @@ func WriteJSONResponse(w http.ResponseWriter, statusCode int, data interface{})
 	w.WriteHeader(statusCode)
 	json.NewEncoder(w).Encode(data)
 }
+
+// WriteJSONWithETag writes a JSON response with a weak ETag over its body. A GET or HEAD whose
+// If-None-Match already lists that ETag gets 304 Not Modified without the body, so clients that
+// refresh often only download data that changed.
+func WriteJSONWithETag(w http.ResponseWriter, r *http.Request, statusCode int, data interface{}) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	body = append(body, '\n') // ✅ Same bytes json.Encoder writes
+	sum := sha256.Sum256(body)
+	etag := `W/"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache") // ✅ Revalidate every time; never shared caches
+	if (r.Method == http.MethodGet || r.Method == http.MethodHead) && etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if r.Method != http.MethodHead {
+		w.Write(body)
+	}
+}
+
+// etagMatches applies If-None-Match's weak comparison: any listed tag (or "*") equal to etag,
+// ignoring W/ prefixes
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
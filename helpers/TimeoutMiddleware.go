@@ -0,0 +1,19 @@
+package helpers
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// TimeoutMiddleware gives every request a deadline; services pass r.Context() down so DynamoDB
+// calls stop when it expires or the client disconnects
+func TimeoutMiddleware(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package helpers
+
+import (
+	"net/http"
+	"vibin_server/utils"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingHandler starts a server span for every request (continuing the caller's trace when it sends one)
+func TracingHandler(next http.Handler) http.Handler {
+	return otelhttp.NewHandler(next, "http.request")
+}
+
+// RouteSpanMiddleware renames the server span to the matched route template (e.g.
+// "GET /api/interactions/matches/{userHandle}") so spans group by endpoint, and tags it with the request ID
+func RouteSpanMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		span := trace.SpanFromContext(r.Context())
+		if route := mux.CurrentRoute(r); route != nil {
+			if template, err := route.GetPathTemplate(); err == nil {
+				span.SetName(r.Method + " " + template)
+				span.SetAttributes(attribute.String("http.route", template))
+			}
+		}
+		if requestID := utils.RequestIDFromContext(r.Context()); requestID != "" {
+			span.SetAttributes(attribute.String("vibin.request_id", requestID))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
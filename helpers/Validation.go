@@ -0,0 +1,116 @@
+package helpers
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+	"unicode/utf8"
+	"vibin_server/models"
+	"vibin_server/utils"
+)
+
+// ✅ Payload limits shared by every controller
+const (
+	MinUserAge         = models.MinUserAge
+	MaxPhotos          = 6
+	MaxVideos          = 2
+	MaxMessageLength   = 2000
+	MaxBioLength       = 500
+	MaxCaptionLength   = 100
+	MaxProfilePrompts  = 3
+	MaxInterests       = 10
+	MaxAnswerLength    = 150
+	MaxNameLength      = 50
+	MaxGroupNameLength = 50
+)
+
+// handlePattern matches user handles: 3-30 letters, digits, '_' or '.'
+var handlePattern = regexp.MustCompile(`^[A-Za-z0-9_.]{3,30}$`)
+
+// FieldError describes one invalid payload field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Validator collects every field error of a payload so the client can fix them in one go
+type Validator struct {
+	Errors []FieldError
+}
+
+// Check records message for field unless ok holds
+func (v *Validator) Check(ok bool, field, message string) {
+	if !ok {
+		v.Errors = append(v.Errors, FieldError{Field: field, Message: message})
+	}
+}
+
+// Required rejects empty values
+func (v *Validator) Required(field, value string) {
+	v.Check(value != "", field, "is required")
+}
+
+// Handle rejects missing or malformed user handles
+func (v *Validator) Handle(field, value string) {
+	if value == "" {
+		v.Required(field, value)
+		return
+	}
+	v.Check(handlePattern.MatchString(value), field, "must be 3-30 letters, digits, '_' or '.'")
+}
+
+// MaxLength rejects values longer than max characters (runes, so emoji count once)
+func (v *Validator) MaxLength(field, value string, max int) {
+	v.Check(utf8.RuneCountInString(value) <= max, field, fmt.Sprintf("must be at most %d characters", max))
+}
+
+// MaxItems rejects lists with more than max entries
+func (v *Validator) MaxItems(field string, count, max int) {
+	v.Check(count <= max, field, fmt.Sprintf("must have at most %d items", max))
+}
+
+// OneOf rejects values outside allowed
+func (v *Validator) OneOf(field, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.Check(false, field, fmt.Sprintf("must be one of %v", allowed))
+}
+
+// Adult requires a date of birth (YYYY-MM-DD or RFC3339) at least MinUserAge years ago; the age is
+// always computed from it, never taken from the client
+func (v *Validator) Adult(field, dob string) {
+	if dob == "" {
+		v.Required(field, dob)
+		return
+	}
+
+	born, err := utils.ParseDOB(dob)
+	if err != nil {
+		v.Check(false, field, "must be a YYYY-MM-DD date")
+		return
+	}
+	age := utils.AgeOn(born, time.Now())
+	v.Check(age >= MinUserAge, field, fmt.Sprintf("must be at least %d years ago", MinUserAge))
+	v.Check(age <= models.MaxUserAge, field, fmt.Sprintf("must be within the last %d years", models.MaxUserAge))
+}
+
+// Valid reports whether no field errors were recorded
+func (v *Validator) Valid() bool {
+	return len(v.Errors) == 0
+}
+
+// WriteErrors answers 400 with the field errors and reports whether it did; handlers return when it's true
+func (v *Validator) WriteErrors(w http.ResponseWriter) bool {
+	if v.Valid() {
+		return false
+	}
+	WriteJSONResponse(w, http.StatusBadRequest, struct {
+		Error  string       `json:"error"`
+		Fields []FieldError `json:"fields"`
+	}{"Validation failed", v.Errors})
+	return true
+}
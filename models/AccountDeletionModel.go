@@ -0,0 +1,24 @@
+package models
+
+// AccountDeletionGraceDays is how long a deleted account can be restored before it is purged
+const AccountDeletionGraceDays = 30
+
+// PendingDeletionIndex is a sparse GSI over profiles awaiting purge
+// PK: pendingDeletion (always PendingDeletionMarker), SK: purgeAfter
+const PendingDeletionIndex = "pendingDeletion-purgeAfter-index"
+
+// PendingDeletionMarker is the pendingDeletion value of every profile in PendingDeletionIndex
+const PendingDeletionMarker = "pending"
+
+// AccountDeletion is returned when an account is deleted or restored
+type AccountDeletion struct {
+	UserHandle string `json:"userhandle"`
+	DeletedAt  string `json:"deletedAt,omitempty"`
+	PurgeAfter string `json:"purgeAfter,omitempty"` // After this time the account can no longer be restored
+	Restored   bool   `json:"restored,omitempty"`
+}
+
+// IsDeleted reports whether the account is in its deletion grace period
+func (p *UserProfile) IsDeleted() bool {
+	return p.DeletedAt != ""
+}
@@ -0,0 +1,82 @@
+package models
+
+import "time"
+
+// AccountActionsTable records every warning, suspension, ban and reinstatement issued to a user, so
+// appeals are reviewed against the full history. Like reports, it outlives account deletion.
+// PK: userhandle, SK: createdAt (RFC3339Nano)
+var AccountActionsTable = "AccountActions"
+
+// ✅ Actions trust & safety can take on an account
+const (
+	AccountActionWarning       = "warning"        // Recorded only; the account stays usable
+	AccountActionSuspension    = "suspension"     // Read-only and hidden until the suspension ends
+	AccountActionBan           = "ban"            // Read-only and hidden until reinstated
+	AccountActionReinstatement = "reinstatement"  // Lifts a suspension or ban (e.g. after an appeal)
+	AccountActionShadowban     = "shadowban"      // The user keeps using the app, but their likes and pings go nowhere and they are never suggested
+	AccountActionShadowbanLift = "shadowban_lift" // Ends a shadowban
+)
+
+// AccountActionTypes lists the accepted action types
+var AccountActionTypes = []string{AccountActionWarning, AccountActionSuspension, AccountActionBan, AccountActionReinstatement, AccountActionShadowban, AccountActionShadowbanLift}
+
+// IsShadow reports whether the action is a shadowban or its lifting, which the user must not learn about
+func (a *AccountAction) IsShadow() bool {
+	return a.Type == AccountActionShadowban || a.Type == AccountActionShadowbanLift
+}
+
+// ✅ Account statuses; the profile's accountStatus holds the restricted ones
+const (
+	AccountStatusActive    = "active" // In good standing (never stored)
+	AccountStatusSuspended = "suspended"
+	AccountStatusBanned    = "banned"
+)
+
+// ✅ Action limits
+const (
+	MaxSuspensionDays            = 365
+	MaxAccountActionReasonLength = 1000
+)
+
+// AccountAction is one entry of a user's moderation history
+type AccountAction struct {
+	UserHandle string `dynamodbav:"userhandle" json:"userhandle"`
+	CreatedAt  string `dynamodbav:"createdAt" json:"createdAt"`
+	ActionID   string `dynamodbav:"actionId" json:"actionId"`
+	Type       string `dynamodbav:"type" json:"type"`
+	Reason     string `dynamodbav:"reason" json:"reason"`
+	ReportID   string `dynamodbav:"reportId,omitempty" json:"reportId,omitempty"` // Report the action was taken on
+	Until      string `dynamodbav:"until,omitempty" json:"until,omitempty"`       // End of a suspension (RFC3339)
+	IssuedBy   string `dynamodbav:"issuedBy" json:"issuedBy,omitempty"`           // Admin who took the action (not shown to the user)
+}
+
+// AccountActionRequest is an admin's warning, suspension, ban or reinstatement
+type AccountActionRequest struct {
+	Type     string `json:"type"`
+	Reason   string `json:"reason"`
+	ReportID string `json:"reportId,omitempty"`
+	Until    string `json:"until,omitempty"` // Required for a suspension
+	IssuedBy string `json:"-"`               // The admin's session user
+}
+
+// AccountStanding is the user's own view of their status and moderation history, for appeals (shadow
+// actions left out)
+type AccountStanding struct {
+	Status         string          `json:"status"` // One of the AccountStatus* constants
+	SuspendedUntil string          `json:"suspendedUntil,omitempty"`
+	Actions        []AccountAction `json:"actions"` // Newest first
+}
+
+// AccountRestriction returns AccountStatusSuspended or AccountStatusBanned while the account is
+// restricted at now, and "" otherwise; a suspension whose end has passed no longer applies even
+// though the status is still stored
+func (p *UserProfile) AccountRestriction(now time.Time) string {
+	if p.AccountStatus != AccountStatusSuspended {
+		return p.AccountStatus
+	}
+	until, err := time.Parse(time.RFC3339, p.SuspendedUntil)
+	if err == nil && !now.Before(until) {
+		return ""
+	}
+	return AccountStatusSuspended
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+	"vibin_server/utils"
+)
+
+// MinUserAge is the minimum age to hold an account
+const MinUserAge = 18
+
+// MaxUserAge rejects dates of birth that are almost certainly typos
+const MaxUserAge = 120
+
+// AgeVerificationsTable holds age disputes and the ID documents submitted to resolve them
+// PK: userhandle; GSI status-createdAt-index lists the review queue oldest first
+var AgeVerificationsTable = "AgeVerifications"
+
+// AgeVerificationStatusIndex is the GSI (PK status, SK createdAt) the review queue is read from
+const AgeVerificationStatusIndex = "status-createdAt-index"
+
+// ✅ Age verification states; the profile's ageStatus mirrors all but approved
+const (
+	AgeVerificationDisputed = "disputed" // Reported as underage: hidden until a document is reviewed
+	AgeVerificationPending  = "pending"  // Document submitted, awaiting an admin
+	AgeVerificationApproved = "approved" // Confirmed adult; the profile is visible again
+	AgeVerificationRejected = "rejected" // Confirmed underage: the account stays blocked
+)
+
+// AgeVerification is one age dispute and its resolution
+type AgeVerification struct {
+	UserHandle  string `dynamodbav:"userhandle" json:"userhandle"`
+	Status      string `dynamodbav:"status" json:"status"`
+	Reason      string `dynamodbav:"reason,omitempty" json:"reason,omitempty"`
+	ReportedBy  string `dynamodbav:"reportedBy,omitempty" json:"reportedBy,omitempty"`
+	DocumentKey string `dynamodbav:"documentKey,omitempty" json:"documentKey,omitempty"` // S3 key of the ID document; deleted once reviewed
+	CreatedAt   string `dynamodbav:"createdAt" json:"createdAt"`
+	SubmittedAt string `dynamodbav:"submittedAt,omitempty" json:"submittedAt,omitempty"`
+	ReviewedAt  string `dynamodbav:"reviewedAt,omitempty" json:"reviewedAt,omitempty"`
+	ReviewedBy  string `dynamodbav:"reviewedBy,omitempty" json:"reviewedBy,omitempty"`
+}
+
+// IsAgeRestricted reports whether the profile is hidden while its age is disputed or after it was rejected
+func (p *UserProfile) IsAgeRestricted() bool {
+	return p.AgeStatus != ""
+}
+
+// RefreshAge recomputes Age from the date of birth, so stored ages don't go stale after birthdays
+func (p *UserProfile) RefreshAge(now time.Time) {
+	if born, err := utils.ParseDOB(p.DOB); err == nil {
+		p.Age = utils.AgeOn(born, now)
+	}
+}
@@ -0,0 +1,40 @@
+package models
+
+// AnalyticsEventsTable stores server-side funnel events
+// PK: "<eventType>#<YYYY-MM-DD>" (one partition per event per UTC day), SK: "<createdAt>#<eventId>"
+var AnalyticsEventsTable = "AnalyticsEvents"
+
+// AnalyticsRetentionDays is how long events live before DynamoDB TTL removes them
+const AnalyticsRetentionDays = 180
+
+// ✅ Funnel events recorded by the server
+const (
+	EventProfileCreated = "profile_created"
+	EventSwipe          = "swipe"         // properties: action (like/dislike)
+	EventPingSent       = "ping_sent"     //
+	EventMatch          = "match"         // properties: matchId
+	EventFirstMessage   = "first_message" // properties: matchId
+)
+
+// AnalyticsEventTypes lists every event type in funnel order
+var AnalyticsEventTypes = []string{EventProfileCreated, EventSwipe, EventPingSent, EventMatch, EventFirstMessage}
+
+// AnalyticsEvent is one stored funnel event
+type AnalyticsEvent struct {
+	Bucket     string            `dynamodbav:"bucket" json:"-"`                                  // ✅ Partition Key
+	EventKey   string            `dynamodbav:"eventKey" json:"-"`                                // ✅ Sort Key
+	EventID    string            `dynamodbav:"eventId" json:"eventId"`                           // Unique event ID
+	EventType  string            `dynamodbav:"eventType" json:"eventType"`                       // One of the Event* constants
+	UserHandle string            `dynamodbav:"userhandle" json:"userhandle"`                     // User who performed the action
+	Properties map[string]string `dynamodbav:"properties,omitempty" json:"properties,omitempty"` // Event-specific details
+	CreatedAt  string            `dynamodbav:"createdAt" json:"createdAt"`                       // RFC3339 (UTC)
+	ExpiresAt  int64             `dynamodbav:"expiresAt" json:"-"`                               // ✅ TTL attribute (epoch seconds)
+}
+
+// AnalyticsCounts is the response of the internal counts API
+type AnalyticsCounts struct {
+	From   string                    `json:"from"`   // First day (YYYY-MM-DD, UTC)
+	To     string                    `json:"to"`     // Last day, inclusive
+	Daily  map[string]map[string]int `json:"daily"`  // eventType → day → count
+	Totals map[string]int            `json:"totals"` // eventType → count over the range
+}
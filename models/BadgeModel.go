@@ -0,0 +1,9 @@
+package models
+
+// BadgeCounts are the numbers behind the app's tab badges, fetched in one call
+type BadgeCounts struct {
+	UnreadMessages        int `json:"unreadMessages"`        // Unread messages across matches and group chats
+	NewLikes              int `json:"newLikes"`              // Likes received and not yet acted on
+	PendingPings          int `json:"pendingPings"`          // Pings received and not yet approved or declined
+	PendingGroupApprovals int `json:"pendingGroupApprovals"` // Group invites waiting on the user's decision
+}
@@ -0,0 +1,23 @@
+package models
+
+// ✅ Bio suggestion limits
+const (
+	DailyBioSuggestionLimit  = 10 // Suggestion requests a user may make per UTC day
+	QuotaDailyBioSuggestions = "daily_bio_suggestions"
+	BioDrafts                = 3
+	MaxBioDraftLength        = 500 // Same as the bio limit, so any draft can be saved as is
+	MaxBioSuggestionTerms    = 20  // Interests plus keywords per request
+	MaxBioSuggestionTermLen  = 50
+)
+
+// BioSuggestionRequest is what a bio is written from
+type BioSuggestionRequest struct {
+	UserHandle string   `json:"userhandle"`
+	Interests  []string `json:"interests"`
+	Keywords   []string `json:"keywords"`
+}
+
+// BioSuggestions are generated bio drafts, for the user to pick and edit
+type BioSuggestions struct {
+	Drafts []string `json:"drafts"`
+}
@@ -0,0 +1,23 @@
+package models
+
+// BlocksTable holds the users each user blocked or reported
+// PK: userhandle (who blocked), SK: blockedHandle
+var BlocksTable = "Blocks"
+
+// BlockedHandleIndex is the GSI (PK blockedHandle) listing who blocked a user, so blocks hide both ways
+const BlockedHandleIndex = "blockedHandle-index"
+
+// ✅ Why a user was blocked; reporting always blocks too
+const (
+	BlockKindBlock  = "block"
+	BlockKindReport = "report"
+)
+
+// Block is one user hiding another from every feed (both ways)
+type Block struct {
+	UserHandle    string `dynamodbav:"userhandle" json:"userhandle"`
+	BlockedHandle string `dynamodbav:"blockedHandle" json:"blockedHandle"`
+	Kind          string `dynamodbav:"kind" json:"kind"`                         // block or report
+	Reason        string `dynamodbav:"reason,omitempty" json:"reason,omitempty"` // Reports only: the report category
+	CreatedAt     string `dynamodbav:"createdAt" json:"createdAt"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// CallLogTable records every call token issued, for safety review
+// PK: matchId, SK: "<RFC3339Nano>#<userhandle>"
+// Entries outlive deleted accounts and expire after CallLogRetentionDays.
+var CallLogTable = "CallLog"
+
+// CallLogRetentionDays is how long call log entries live before DynamoDB TTL removes them
+const CallLogRetentionDays = 365
+
+// CallTokenTTL is how long a call token can be used to join; clients request a new one to rejoin
+// or renew before it lapses
+const CallTokenTTL = 10 * time.Minute
+
+// ✅ Supported RTC providers
+const (
+	RTCProviderAgora  = "agora"
+	RTCProviderTwilio = "twilio"
+)
+
+// CallToken is what a client needs to join the match's call
+type CallToken struct {
+	Provider  string `json:"provider"`
+	AppID     string `json:"appId,omitempty"` // ✅ Agora app ID; Twilio tokens carry the account
+	Channel   string `json:"channel"`         // Agora channel / Twilio room, derived from the matchId
+	Identity  string `json:"identity"`        // Agora user account / Twilio identity: the userhandle
+	Token     string `json:"token"`
+	ExpiresAt string `json:"expiresAt"` // RFC3339
+}
+
+// CallLogEntry is one issued call token: who asked to join which match's call, and when
+type CallLogEntry struct {
+	MatchID       string `dynamodbav:"matchId" json:"matchId"`
+	SK            string `dynamodbav:"SK" json:"-"`
+	UserHandle    string `dynamodbav:"userhandle" json:"userhandle"`
+	PartnerHandle string `dynamodbav:"partnerHandle" json:"partnerHandle"`
+	Provider      string `dynamodbav:"provider" json:"provider"`
+	Channel       string `dynamodbav:"channel" json:"channel"`
+	IssuedAt      string `dynamodbav:"issuedAt" json:"issuedAt"`
+	TokenExpires  string `dynamodbav:"tokenExpiresAt" json:"tokenExpiresAt"`
+	ExpiresAt     int64  `dynamodbav:"expiresAt" json:"-"` // ✅ TTL attribute (epoch seconds)
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// CaptchaTokenHeader carries the token the client's hCaptcha or Turnstile widget produced
+const CaptchaTokenHeader = "X-Captcha-Token"
+
+// ✅ Supported CAPTCHA providers; both use the same siteverify protocol
+const (
+	CaptchaProviderHCaptcha  = "hcaptcha"
+	CaptchaProviderTurnstile = "turnstile"
+)
+
+// ✅ Actions a challenge can be required for (CAPTCHA_ACTIONS)
+const (
+	CaptchaActionSignup = "signup" // Every profile creation
+	CaptchaActionPing   = "ping"   // Pings the spam detector flags
+)
+
+// ✅ Reasons the spam detector flags a ping
+const (
+	SpamSignalNewAccount = "new_account" // Sender signed up less than SpamNewAccountAge ago
+	SpamSignalLink       = "link"        // Ping message contains a link or web address
+)
+
+// SpamNewAccountAge is how long after signing up a user's pings count as high risk
+const SpamNewAccountAge = 24 * time.Hour
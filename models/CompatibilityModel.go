@@ -0,0 +1,139 @@
+package models
+
+import "strings"
+
+// ✅ Canonical genders; profiles may store any spelling in genderAliases, in lower or title case
+const (
+	GenderMan       = "man"
+	GenderWoman     = "woman"
+	GenderNonbinary = "nonbinary"
+)
+
+// GenderEveryone requests suggestions of every gender the user is interested in
+const GenderEveryone = "everyone"
+
+// AllGenders is every canonical gender, in discovery query order
+var AllGenders = []string{GenderMan, GenderWoman, GenderNonbinary}
+
+// genderAliases are the spellings clients have stored for each canonical gender
+var genderAliases = map[string][]string{
+	GenderMan:       {"man", "male"},
+	GenderWoman:     {"woman", "female"},
+	GenderNonbinary: {"nonbinary", "non-binary"},
+}
+
+// ✅ What users are looking for, grouped for compatibility
+const (
+	LookingForLongTerm   = "long_term"
+	LookingForShortTerm  = "short_term"
+	LookingForFriendship = "friendship"
+	LookingForOpen       = "open" // Not sure yet, or not set
+)
+
+// lookingForKeywords maps words in the free-form lookingFor to a group (first match wins)
+var lookingForKeywords = []struct{ keyword, group string }{
+	{"benefits", LookingForShortTerm},
+	{"friend", LookingForFriendship},
+	{"long", LookingForLongTerm},
+	{"marriage", LookingForLongTerm},
+	{"life partner", LookingForLongTerm},
+	{"casual", LookingForShortTerm},
+	{"hookup", LookingForShortTerm},
+	{"short", LookingForShortTerm},
+	{"fun", LookingForShortTerm},
+	{"relationship", LookingForLongTerm},
+}
+
+// CanonicalGender maps a stored or requested gender to its canonical value; unknown values are
+// returned lowercased
+func CanonicalGender(gender string) string {
+	normalized := strings.ToLower(strings.TrimSpace(gender))
+	for canonical, aliases := range genderAliases {
+		for _, alias := range aliases {
+			if normalized == alias {
+				return canonical
+			}
+		}
+	}
+	return normalized
+}
+
+// GenderIndexValues lists the stored spellings of a canonical gender, for gender-index queries
+func GenderIndexValues(gender string) []string {
+	var values []string
+	for _, alias := range genderAliases[gender] {
+		values = append(values, alias, strings.ToUpper(alias[:1])+alias[1:])
+	}
+	if len(values) == 0 {
+		values = []string{gender}
+	}
+	return values
+}
+
+// LookingForGroup classifies the free-form lookingFor
+func LookingForGroup(lookingFor string) string {
+	normalized := strings.ToLower(lookingFor)
+	for _, entry := range lookingForKeywords {
+		if strings.Contains(normalized, entry.keyword) {
+			return entry.group
+		}
+	}
+	return LookingForOpen
+}
+
+// SeekingGenders is who the user wants to see, from their gender and orientation. Users whose
+// orientation (or, for straight and gay users, gender) is unknown see everyone.
+func (p *UserProfile) SeekingGenders() []string {
+	gender := CanonicalGender(p.Gender)
+	switch strings.ToLower(strings.TrimSpace(p.Orientation)) {
+	case "straight", "heterosexual":
+		switch gender {
+		case GenderMan:
+			return []string{GenderWoman}
+		case GenderWoman:
+			return []string{GenderMan}
+		}
+	case "gay", "homosexual":
+		switch gender {
+		case GenderMan, GenderWoman:
+			return []string{gender}
+		}
+	case "lesbian":
+		return []string{GenderWoman, GenderNonbinary}
+	}
+	return AllGenders
+}
+
+// IsInterestedIn reports whether the user wants to see other's gender; users of an unknown gender
+// are only shown to users who see everyone
+func (p *UserProfile) IsInterestedIn(other *UserProfile) bool {
+	seeking := p.SeekingGenders()
+	if len(seeking) == len(AllGenders) {
+		return true
+	}
+	gender := CanonicalGender(other.Gender)
+	for _, wanted := range seeking {
+		if gender == wanted {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCompatibleWith reports whether both users want to see each other and are after the same kind
+// of connection: friendship only meets friendship, long and short term don't mix, and users who
+// are not sure yet meet either
+func (p *UserProfile) IsCompatibleWith(other *UserProfile) bool {
+	if !p.IsInterestedIn(other) || !other.IsInterestedIn(p) {
+		return false
+	}
+	mine, theirs := LookingForGroup(p.LookingFor), LookingForGroup(other.LookingFor)
+	switch {
+	case mine == theirs:
+		return true
+	case mine == LookingForFriendship || theirs == LookingForFriendship:
+		return false
+	default:
+		return mine == LookingForOpen || theirs == LookingForOpen
+	}
+}
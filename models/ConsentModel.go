@@ -0,0 +1,64 @@
+package models
+
+// ✅ Processing purposes a user can object to
+const (
+	PurposePersonalizedRanking = "personalizedRanking"
+	PurposeAnalytics           = "analytics"
+	PurposeMarketing           = "marketing"
+)
+
+// ProcessingConsents records which kinds of processing a user allows; stored on the profile
+type ProcessingConsents struct {
+	PersonalizedRanking bool   `dynamodbav:"personalizedRanking" json:"personalizedRanking"` // Rank suggestions using the user's location/history
+	Analytics           bool   `dynamodbav:"analytics" json:"analytics"`                     // Include the user in product analytics
+	Marketing           bool   `dynamodbav:"marketing" json:"marketing"`                     // Send the user marketing campaigns
+	UpdatedAt           string `dynamodbav:"updatedAt,omitempty" json:"updatedAt,omitempty"`
+}
+
+// ProcessingConsentsUpdate is a partial change; nil fields keep their current value
+type ProcessingConsentsUpdate struct {
+	PersonalizedRanking *bool `json:"personalizedRanking,omitempty"`
+	Analytics           *bool `json:"analytics,omitempty"`
+	Marketing           *bool `json:"marketing,omitempty"`
+}
+
+// DefaultProcessingConsents applies to profiles that never changed their settings:
+// ranking and analytics run until the user objects, marketing needs an explicit opt-in
+func DefaultProcessingConsents() ProcessingConsents {
+	return ProcessingConsents{PersonalizedRanking: true, Analytics: true, Marketing: false}
+}
+
+// Allows reports whether processing for the given purpose is permitted
+func (c ProcessingConsents) Allows(purpose string) bool {
+	switch purpose {
+	case PurposePersonalizedRanking:
+		return c.PersonalizedRanking
+	case PurposeAnalytics:
+		return c.Analytics
+	case PurposeMarketing:
+		return c.Marketing
+	default:
+		return false
+	}
+}
+
+// Apply merges a partial update into the consents
+func (c *ProcessingConsents) Apply(update ProcessingConsentsUpdate) {
+	if update.PersonalizedRanking != nil {
+		c.PersonalizedRanking = *update.PersonalizedRanking
+	}
+	if update.Analytics != nil {
+		c.Analytics = *update.Analytics
+	}
+	if update.Marketing != nil {
+		c.Marketing = *update.Marketing
+	}
+}
+
+// EffectiveConsents returns the stored consents, or the defaults when none were saved
+func (p *UserProfile) EffectiveConsents() ProcessingConsents {
+	if p.Consents == nil {
+		return DefaultProcessingConsents()
+	}
+	return *p.Consents
+}
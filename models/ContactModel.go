@@ -0,0 +1,36 @@
+package models
+
+import "regexp"
+
+// ContactsTable holds the hashed phone contacts each user synced
+// PK: userhandle, SK: contactIndex
+var ContactsTable = "Contacts"
+
+// ContactIndexIndex is the GSI (PK contactIndex) listing who has a number in their contacts; the
+// Users table has a GSI of the same name mapping a number to its owner
+const ContactIndexIndex = "contactIndex-index"
+
+// MaxSyncedContacts caps the contacts one sync may upload
+const MaxSyncedContacts = 5000
+
+// ContactHashPattern is the format clients upload: the lowercase hex SHA-256 of the number in
+// E.164 form (leading + and digits only), so raw numbers never leave the device
+var ContactHashPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// ContactSettings are the user's contact sync modes, stored on the profile
+type ContactSettings struct {
+	HideContacts          bool   `dynamodbav:"hideContacts,omitempty" json:"hideContacts"`                   // Never show me my contacts, or them me
+	ShowMutualConnections bool   `dynamodbav:"showMutualConnections,omitempty" json:"showMutualConnections"` // Show mutual connections on cards (both users must opt in)
+	ContactCount          int    `dynamodbav:"contactCount,omitempty" json:"contactCount"`                   // Contacts stored by the last sync
+	SyncedAt              string `dynamodbav:"syncedAt,omitempty" json:"syncedAt,omitempty"`                 // RFC3339 time of the last sync
+}
+
+// HidesContacts reports whether the user asked never to be shown to or shown their contacts
+func (s *ContactSettings) HidesContacts() bool {
+	return s != nil && s.HideContacts
+}
+
+// ShowsMutualConnections reports whether the user shares and sees mutual connection counts
+func (s *ContactSettings) ShowsMutualConnections() bool {
+	return s != nil && s.ShowMutualConnections && s.ContactCount > 0
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConversationExportsTable is the append-only log of transcript exports, for support and abuse review
+// PK: userhandle, SK: "<RFC3339Nano>#<exportId>"
+// Entries outlive deleted accounts and expire after ConversationExportRetentionDays.
+var ConversationExportsTable = "ConversationExports"
+
+// ConversationExportRetentionDays is how long export log entries live before DynamoDB TTL removes them
+const ConversationExportRetentionDays = 365
+
+// ✅ Export limits
+const (
+	DailyExportLimit  = 5 // Transcripts a user may export per UTC day
+	QuotaDailyExports = "daily_exports"
+)
+
+// ✅ Exportable conversations
+const (
+	ConversationKindMatch = "match"
+	ConversationKindGroup = "group"
+)
+
+// ✅ Transcript formats
+const (
+	ExportFormatJSON = "json"
+	ExportFormatText = "text"
+)
+
+// ExportedMessage is one message of a transcript, decrypted; media is linked, not embedded
+type ExportedMessage struct {
+	MessageID        string `json:"messageId"`
+	SenderID         string `json:"senderId"`
+	CreatedAt        string `json:"createdAt"`
+	Content          string `json:"content,omitempty"`
+	MediaURL         string `json:"mediaUrl,omitempty"` // ✅ Uploaded media is presigned and expires; other links are passed through
+	ReplyToMessageID string `json:"replyToMessageId,omitempty"`
+	EndToEnd         bool   `json:"endToEnd,omitempty"` // ✅ End-to-end encrypted; only the participants' devices hold the content
+}
+
+// ConversationExport is the transcript of one match or group, oldest message first
+type ConversationExport struct {
+	ExportID       string            `json:"exportId"`
+	Kind           string            `json:"kind"`
+	ConversationID string            `json:"conversationId"`
+	ExportedBy     string            `json:"exportedBy"`
+	ExportedAt     string            `json:"exportedAt"`
+	Messages       []ExportedMessage `json:"messages"`
+}
+
+// Text renders the transcript as plain text, one message per line
+func (e *ConversationExport) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Vibin %s conversation %s\nExported by %s at %s\n\n", e.Kind, e.ConversationID, e.ExportedBy, e.ExportedAt)
+	for _, message := range e.Messages {
+		content := message.Content
+		if message.EndToEnd {
+			content = "[end-to-end encrypted message]"
+		}
+		fmt.Fprintf(&b, "[%s] %s: %s", message.CreatedAt, message.SenderID, content)
+		if message.MediaURL != "" {
+			fmt.Fprintf(&b, " (media: %s)", message.MediaURL)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// ConversationExportLogEntry records one export
+type ConversationExportLogEntry struct {
+	UserHandle     string `dynamodbav:"userhandle" json:"userhandle"`
+	SK             string `dynamodbav:"SK" json:"-"`
+	ExportID       string `dynamodbav:"exportId" json:"exportId"`
+	Kind           string `dynamodbav:"kind" json:"kind"`
+	ConversationID string `dynamodbav:"conversationId" json:"conversationId"`
+	Format         string `dynamodbav:"format" json:"format"`
+	MessageCount   int    `dynamodbav:"messageCount" json:"messageCount"`
+	At             string `dynamodbav:"at" json:"at"`       // RFC3339
+	ExpiresAt      int64  `dynamodbav:"expiresAt" json:"-"` // ✅ TTL attribute (epoch seconds)
+}
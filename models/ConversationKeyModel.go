@@ -0,0 +1,14 @@
+package models
+
+// ConversationKey is one version of a conversation's KMS-wrapped data key
+type ConversationKey struct {
+	ConversationID   string `dynamodbav:"conversationId" json:"conversationId"`     // ✅ Partition Key (matchId or groupId)
+	KeyVersion       int    `dynamodbav:"keyVersion" json:"keyVersion"`             // ✅ Sort Key (latest is used for new messages)
+	EncryptedDataKey []byte `dynamodbav:"encryptedDataKey" json:"-"`                // KMS ciphertext blob of the AES-256 key
+	KMSKeyID         string `dynamodbav:"kmsKeyId" json:"kmsKeyId"`                 // KMS key that wrapped the data key
+	CreatedAt        string `dynamodbav:"createdAt" json:"createdAt"`               // Timestamp of key creation
+	RotatedFrom      int    `dynamodbav:"rotatedFrom,omitempty" json:"rotatedFrom"` // Previous version, 0 for the first key
+}
+
+// ConversationKeysTable is the DynamoDB table holding wrapped conversation data keys
+var ConversationKeysTable = "ConversationKeys"
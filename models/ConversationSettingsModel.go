@@ -0,0 +1,13 @@
+package models
+
+// ConversationSettingsTable stores each participant's preferences for a conversation
+// PK: conversationId (matchId), SK: userhandle
+var ConversationSettingsTable = "ConversationSettings"
+
+// ConversationSettings is one participant's preferences for a single conversation
+type ConversationSettings struct {
+	ConversationID string `dynamodbav:"conversationId" json:"conversationId"`
+	UserHandle     string `dynamodbav:"userhandle" json:"userhandle"`
+	TextOnly       bool   `dynamodbav:"textOnly" json:"textOnly"` // ✅ Refuse images/voice sent to this user here
+	UpdatedAt      string `dynamodbav:"updatedAt,omitempty" json:"updatedAt,omitempty"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import "strings"
+
+// ConversationSummariesTable holds per-match aggregates maintained by the DynamoDB Streams worker
+// PK: "matchId"
+var ConversationSummariesTable = "ConversationSummaries"
+
+// UnreadFromAttrPrefix prefixes the per-sender unread counters ("unreadFrom#<senderHandle>"). Counting
+// by sender lets a message insert update the summary without knowing who the receiver is.
+const UnreadFromAttrPrefix = "unreadFrom#"
+
+// ConversationSummary is the denormalized state of one match: its latest message and unread counters
+type ConversationSummary struct {
+	MatchID       string         `dynamodbav:"matchId" json:"matchId"`                               // ✅ Partition Key
+	Participants  []string       `dynamodbav:"participants,stringset,omitempty" json:"participants"` // Both user handles, from the match records
+	LastMessage   *Message       `dynamodbav:"lastMessage,omitempty" json:"lastMessage,omitempty"`   // Stored as in the Messages table (possibly encrypted)
+	LastMessageAt string         `dynamodbav:"lastMessageAt,omitempty" json:"lastMessageAt"`         // Sort key of LastMessage; guards against out-of-order updates
+	RebuiltAt     int64          `dynamodbav:"rebuiltAt,omitempty" json:"-"`                         // Epoch seconds of the last full recount; stream changes before it are skipped
+	UpdatedAt     string         `dynamodbav:"updatedAt" json:"updatedAt"`                           // RFC3339
+	UnreadFrom    map[string]int `dynamodbav:"-" json:"unreadFrom"`                                  // senderHandle → unread messages they sent
+}
+
+// UnreadFor returns how many messages in the conversation userHandle hasn't read
+func (c *ConversationSummary) UnreadFor(userHandle string) int {
+	unread := 0
+	for sender, count := range c.UnreadFrom {
+		if sender != userHandle && count > 0 {
+			unread += count
+		}
+	}
+	return unread
+}
+
+// SetUnreadFromAttributes fills UnreadFrom from the raw "unreadFrom#<sender>" attribute names
+func (c *ConversationSummary) SetUnreadFromAttributes(counts map[string]int) {
+	c.UnreadFrom = map[string]int{}
+	for name, count := range counts {
+		if sender, ok := strings.CutPrefix(name, UnreadFromAttrPrefix); ok {
+			c.UnreadFrom[sender] = count
+		}
+	}
+}
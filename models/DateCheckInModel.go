@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+// DateCheckInsTable holds planned dates and their safety check-ins
+// PK: userhandle, SK: checkInId; GSI status-nextActionAt-index lists active check-ins by when they are due
+var DateCheckInsTable = "DateCheckIns"
+
+// DateCheckInDueIndex is the GSI (PK status, SK nextActionAt) the scheduler reads due check-ins from
+const DateCheckInDueIndex = "status-nextActionAt-index"
+
+// ✅ Check-in states
+const (
+	DateCheckInActive    = "active"    // Waiting for the check-in time or the user's answer
+	DateCheckInSafe      = "safe"      // The user confirmed they're safe
+	DateCheckInEscalated = "escalated" // No answer: the emergency contact was texted
+	DateCheckInCancelled = "cancelled"
+)
+
+// ✅ Check-in schedule and limits
+const (
+	DefaultDateCheckInDelay   = 2 * time.Hour       // First prompt after the date starts, unless the user picks a time
+	MaxDateCheckInDelay       = 24 * time.Hour      // Latest check-in time after the date starts
+	MaxDatePlanAhead          = 30 * 24 * time.Hour // How far ahead a date can be registered
+	DateCheckInPrompts        = 2                   // Prompts sent before the contact is texted
+	DateCheckInPromptInterval = 15 * time.Minute    // Time the user has to answer each prompt
+	DateCheckInRetryInterval  = 5 * time.Minute     // Wait before retrying a failed escalation
+	DateCheckInRetentionDays  = 30                  // Days after the check-in time before DynamoDB TTL removes it
+	MaxActiveDateCheckIns     = 3
+	MaxDatePlaceLength        = 200
+	MaxContactNameLength      = 100
+)
+
+// DateCheckIn is a planned date the user asked to be checked on
+type DateCheckIn struct {
+	UserHandle    string `dynamodbav:"userhandle" json:"userhandle"`
+	CheckInID     string `dynamodbav:"checkInId" json:"checkInId"`
+	MatchID       string `dynamodbav:"matchId" json:"matchId"`
+	PartnerHandle string `dynamodbav:"partnerHandle" json:"partnerHandle"`
+	ScheduledAt   string `dynamodbav:"scheduledAt" json:"scheduledAt"` // RFC3339: when the date starts
+	Place         string `dynamodbav:"place" json:"place"`
+	CheckInAt     string `dynamodbav:"checkInAt" json:"checkInAt"` // RFC3339: when the first prompt is sent
+	ContactName   string `dynamodbav:"contactName" json:"contactName"`
+	ContactPhone  string `dynamodbav:"contactPhone" json:"contactPhone"`
+	Status        string `dynamodbav:"status" json:"status"`
+	NextActionAt  string `dynamodbav:"nextActionAt" json:"-"` // RFC3339: next prompt or escalation
+	PromptsSent   int    `dynamodbav:"promptsSent" json:"promptsSent"`
+	CreatedAt     string `dynamodbav:"createdAt" json:"createdAt"`
+	ResolvedAt    string `dynamodbav:"resolvedAt,omitempty" json:"resolvedAt,omitempty"`
+
+	// ✅ Set when ContactPhone holds ciphertext sealed with the PII data key
+	Encrypted  bool `dynamodbav:"encrypted,omitempty" json:"-"`
+	KeyVersion int  `dynamodbav:"keyVersion,omitempty" json:"-"`
+
+	ExpiresAt int64 `dynamodbav:"expiresAt" json:"-"` // ✅ TTL attribute (epoch seconds)
+}
+
+// DateCheckInRequest registers a planned date
+type DateCheckInRequest struct {
+	UserHandle   string `json:"userhandle"`
+	MatchID      string `json:"matchId"`
+	ScheduledAt  string `json:"scheduledAt"`         // RFC3339
+	Place        string `json:"place"`               // Venue name or address
+	CheckInAt    string `json:"checkInAt,omitempty"` // RFC3339; defaults to DefaultDateCheckInDelay after ScheduledAt
+	ContactName  string `json:"contactName"`
+	ContactPhone string `json:"contactPhone"` // E.164, e.g. "+14155550123"
+}
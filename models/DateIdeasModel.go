@@ -0,0 +1,36 @@
+package models
+
+// DateIdea is one activity from the static date-idea catalog
+type DateIdea struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Category    string   `json:"category"`    // food, outdoors, culture, active, nightlife, relaxed
+	Interests   []string `json:"interests"`   // Catalog interest IDs this idea suits
+	SearchQuery string   `json:"searchQuery"` // What to search for near the meeting point in a maps/places app
+	Outdoor     bool     `json:"outdoor"`
+	PriceLevel  int      `json:"priceLevel"` // 0 (free) – 3 (pricey)
+}
+
+// MeetingPoint is the geographic midpoint between the two matched users
+type MeetingPoint struct {
+	Latitude          float64 `json:"latitude"`
+	Longitude         float64 `json:"longitude"`
+	DistanceBetweenKm float64 `json:"distanceBetweenKm"` // How far apart the two users are
+}
+
+// DateIdeaSuggestion is a catalog idea scored for a specific match
+type DateIdeaSuggestion struct {
+	DateIdea
+	Score            int      `json:"score"`
+	MatchedInterests []string `json:"matchedInterests,omitempty"` // Shared interests that led to this idea
+}
+
+// DateIdeasResponse is returned by GET /api/matches/{matchId}/date-ideas
+type DateIdeasResponse struct {
+	MatchID         string               `json:"matchId"`
+	SharedInterests []string             `json:"sharedInterests"`
+	MeetingPoint    *MeetingPoint        `json:"meetingPoint,omitempty"` // Nil when either location is unknown
+	Personalized    bool                 `json:"personalized"`           // False when either user objected to personalized ranking
+	Ideas           []DateIdeaSuggestion `json:"ideas"`
+}
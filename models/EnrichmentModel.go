@@ -0,0 +1,16 @@
+package models
+
+// ✅ Reasons an entry could not be enriched with profile data
+const (
+	EnrichmentReasonProfileNotFound    = "profile_not_found"
+	EnrichmentReasonProfileFetchFailed = "profile_fetch_failed"
+	EnrichmentReasonLastMessageFailed  = "last_message_fetch_failed"
+	EnrichmentReasonUnreadCountFailed  = "unread_count_failed"
+)
+
+// RetryHint tells clients whether and when a placeholder entry is worth re-fetching
+type RetryHint struct {
+	Reason            string `json:"reason"`
+	Retryable         bool   `json:"retryable"`
+	RetryAfterSeconds int    `json:"retryAfterSeconds,omitempty"`
+}
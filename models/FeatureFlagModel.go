@@ -0,0 +1,32 @@
+package models
+
+// FeatureFlagsTable holds remote-config flags
+// PK: "flagKey"
+var FeatureFlagsTable = "FeatureFlags"
+
+// ✅ Flags the server and clients check
+const (
+	FlagGroupChat  = "group_chat"
+	FlagSuperlikes = "superlikes"
+)
+
+// FlagAIOpeners gates generated conversation openers; turning it off is the kill switch
+const FlagAIOpeners = "ai_openers"
+
+// FeatureFlag turns a feature on for everyone, a percentage of users, or an allow-list
+type FeatureFlag struct {
+	Key            string            `dynamodbav:"flagKey" json:"key"`                                   // ✅ Partition Key
+	Description    string            `dynamodbav:"description,omitempty" json:"description,omitempty"`   // What the flag controls
+	Enabled        bool              `dynamodbav:"enabled" json:"enabled"`                               // Kill switch; false = off for everyone
+	RolloutPercent int               `dynamodbav:"rolloutPercent" json:"rolloutPercent"`                 // 0-100 of users, bucketed by userHandle
+	AllowHandles   []string          `dynamodbav:"allowHandles,omitempty" json:"allowHandles,omitempty"` // Always on for these users (testers)
+	Config         map[string]string `dynamodbav:"config,omitempty" json:"config,omitempty"`             // Remote config sent to clients that have the flag
+	UpdatedBy      string            `dynamodbav:"updatedBy" json:"updatedBy"`                           // Admin who last changed the flag
+	UpdatedAt      string            `dynamodbav:"updatedAt" json:"updatedAt"`                           // RFC3339
+}
+
+// ClientConfig is what GET /api/config returns for one user
+type ClientConfig struct {
+	Flags  map[string]bool              `json:"flags"`  // flagKey → on for this user
+	Config map[string]map[string]string `json:"config"` // flagKey → remote config (only flags that are on)
+}
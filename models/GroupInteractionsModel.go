@@ -4,19 +4,84 @@ import "time"
 
 // GroupInteraction represents a group invite or an approved group in DynamoDB
 type GroupInteraction struct {
-	PK              string              `dynamodbav:"PK" json:"PK"`                                   // "USER#<userHandle>"
-	SK              string              `dynamodbav:"SK" json:"SK"`                                   // "GROUP_INVITE#<inviteeHandle>" OR "GROUP#<groupId>"
-	InteractionType string              `dynamodbav:"interactionType" json:"interactionType"`         // "group_invite" or "group_chat"
-	Status          string              `dynamodbav:"status" json:"status"`                           // "pending", "approved", "active"
-	GroupID         *string             `dynamodbav:"groupId,omitempty" json:"groupId,omitempty"`     // Assigned when approved
-	GroupName       *string             `dynamodbav:"groupName,omitempty" json:"groupName,omitempty"` // Group name
-	InviterHandle   string              `dynamodbav:"inviterHandle" json:"inviterHandle"`             // User who initiated the invite
-	ApproverHandle  string              `dynamodbav:"approverHandle" json:"approverHandle"`           // User who needs to approve
-	InviteeHandle   string              `dynamodbav:"inviteeHandle" json:"inviteeHandle"`             // User who will be added
-	Members         []string            `dynamodbav:"members" json:"members"`                         // List of users in the group
-	CreatedAt       time.Time           `dynamodbav:"createdAt" json:"createdAt"`                     // Timestamp of invite creation
-	LastUpdated     time.Time           `dynamodbav:"lastUpdated" json:"lastUpdated"`                 // Timestamp of last update
-	InviteeProfile  *InviteeUserDetails `json:"inviteeProfile,omitempty"`                             // Invitee's profile details
+	PK              string              `dynamodbav:"PK" json:"PK"`                                             // "USER#<userHandle>"
+	SK              string              `dynamodbav:"SK" json:"SK"`                                             // "GROUP_INVITE#<inviteeHandle>" OR "GROUP#<groupId>"
+	InteractionType string              `dynamodbav:"interactionType" json:"interactionType"`                   // "group_invite" or "group_chat"
+	Status          string              `dynamodbav:"status" json:"status"`                                     // "pending", "approved", "active"
+	GroupID         *string             `dynamodbav:"groupId,omitempty" json:"groupId,omitempty"`               // Assigned when approved
+	GroupName       *string             `dynamodbav:"groupName,omitempty" json:"groupName,omitempty"`           // Group name
+	InviterHandle   string              `dynamodbav:"inviterHandle" json:"inviterHandle"`                       // User who initiated the invite
+	ApproverHandle  string              `dynamodbav:"approverHandle" json:"approverHandle"`                     // User who needs to approve
+	InviteeHandle   string              `dynamodbav:"inviteeHandle" json:"inviteeHandle"`                       // User who will be added
+	Members         []string            `dynamodbav:"members" json:"members"`                                   // List of users in the group
+	Approvers       []string            `dynamodbav:"approvers,omitempty" json:"approvers,omitempty"`           // Everyone who must decide (nil = just ApproverHandle)
+	ApprovalPolicy  string              `dynamodbav:"approvalPolicy,omitempty" json:"approvalPolicy,omitempty"` // "all" (default) or "majority"
+	Decisions       map[string]string   `dynamodbav:"decisions,omitempty" json:"decisions,omitempty"`           // approver → "approved"/"declined"
+	CreatedAt       time.Time           `dynamodbav:"createdAt" json:"createdAt"`                               // Timestamp of invite creation
+	LastUpdated     time.Time           `dynamodbav:"lastUpdated" json:"lastUpdated"`                           // Timestamp of last update
+	LastActivityAt  string              `dynamodbav:"lastActivityAt,omitempty" json:"lastActivityAt,omitempty"` // Membership records: newest message (RFC3339 UTC), kept up to date by CreateGroupMessage
+	ActiveMember    string              `dynamodbav:"activeMember,omitempty" json:"-"`                          // Membership records: "USER#<userHandle>" while active (ActiveGroupsIndex key)
+	InviteeProfile  *InviteeUserDetails `json:"inviteeProfile,omitempty"`                                       // Invitee's profile details
+	EnrichmentError bool                `dynamodbav:"-" json:"enrichmentError,omitempty"`                       // Invitee profile could not be loaded
+	RetryHint       *RetryHint          `dynamodbav:"-" json:"retryHint,omitempty"`                             // When to retry enrichment
+}
+
+// ✅ Approval policies for invites with several approvers
+const (
+	ApprovalPolicyAll      = "all"      // Every approver must approve; one decline rejects
+	ApprovalPolicyMajority = "majority" // More than half must approve
+)
+
+// InteractionTypeGroupApproval marks the per-approver pointer rows that surface a multi-approver
+// invite in each additional approver's pending list (SK: GROUP_APPROVAL#<inviter>#<invitee>)
+const InteractionTypeGroupApproval = "group_approval"
+
+// GroupApprovalSK builds the sort key of an approver's pointer row for an invite
+func GroupApprovalSK(inviterHandle, inviteeHandle string) string {
+	return "GROUP_APPROVAL#" + inviterHandle + "#" + inviteeHandle
+}
+
+// RequiredApprovers returns who must decide on the invite (legacy invites have a single approver)
+func (g *GroupInteraction) RequiredApprovers() []string {
+	if len(g.Approvers) > 0 {
+		return g.Approvers
+	}
+	return []string{g.ApproverHandle}
+}
+
+// ApprovalOutcome applies the approval policy to the recorded decisions and returns
+// "approved", "declined", or "pending" while the outcome is still open
+func (g *GroupInteraction) ApprovalOutcome() string {
+	approvers := g.RequiredApprovers()
+	approved, declined := 0, 0
+	for _, approver := range approvers {
+		switch g.Decisions[approver] {
+		case "approved":
+			approved++
+		case "declined":
+			declined++
+		}
+	}
+
+	total := len(approvers)
+	if g.ApprovalPolicy == ApprovalPolicyMajority {
+		needed := total/2 + 1
+		switch {
+		case approved >= needed:
+			return "approved"
+		case total-declined < needed:
+			return "declined"
+		}
+		return "pending"
+	}
+
+	switch {
+	case declined > 0:
+		return "declined"
+	case approved == total:
+		return "approved"
+	}
+	return "pending"
 }
 
 // MatchedUserDetails represents the necessary data for a matched user
@@ -32,8 +97,12 @@ type InviteeUserDetails struct {
 }
 
 // Table Name for DynamoDB
-const GroupInteractionsTable = "GroupInteractions"
+var GroupInteractionsTable = "GroupInteractions"
 
 // GSI Index Names
 const InviteStatusIndex = "inviterHandle-status-index" // GSI for querying invite status
 const ApprovalIndex = "approverHandle-status-index"    // GSI for querying pending approvals
+
+// ActiveGroupsIndex is a sparse GSI over active membership records, ordered by last activity
+// PK: activeMember ("USER#<userHandle>", removed when the member leaves), SK: lastActivityAt
+const ActiveGroupsIndex = "activeMember-lastActivityAt-index"
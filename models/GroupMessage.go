@@ -2,18 +2,131 @@ package models
 
 // GroupMessage represents a group chat message stored in DynamoDB
 type GroupMessage struct {
-	GroupID     string          `dynamodbav:"groupId" json:"groupId"`     // ✅ Partition Key (Group Identifier)
-	CreatedAt   string          `dynamodbav:"createdAt" json:"createdAt"` // ✅ Sort Key (Timestamp)
-	MessageID   string          `dynamodbav:"messageId" json:"messageId"` // ✅ Unique message ID (UUID-based)
-	SenderID    string          `dynamodbav:"senderId" json:"senderId"`   // ✅ User who sent the message
-	Content     string          `dynamodbav:"content,omitempty" json:"content,omitempty"`
-	ImageURL    *string         `dynamodbav:"imageUrl,omitempty" json:"imageUrl,omitempty"` // ✅ Optional Image URL
-	IsRead      map[string]bool `dynamodbav:"isRead" json:"isRead"`                         // ✅ Tracks read status per user
-	Likes       map[string]bool `dynamodbav:"likes" json:"likes"`                           // ✅ Tracks likes per user
-	ReadCount   int             `dynamodbav:"readCount" json:"readCount"`                   // ✅ Number of users who have read the message
-	LikeCount   int             `dynamodbav:"likeCount" json:"likeCount"`                   // ✅ Number of users who liked the message
-	MemberCount int             `dynamodbav:"memberCount" json:"memberCount"`               // ✅ Total members in the group
+	GroupID     string            `dynamodbav:"groupId" json:"groupId"`     // ✅ Partition Key (Group Identifier)
+	CreatedAt   string            `dynamodbav:"createdAt" json:"createdAt"` // ✅ Sort Key (Timestamp)
+	MessageID   string            `dynamodbav:"messageId" json:"messageId"` // ✅ Unique message ID (UUID-based)
+	SenderID    string            `dynamodbav:"senderId" json:"senderId"`   // ✅ User who sent the message
+	Content     string            `dynamodbav:"content,omitempty" json:"content,omitempty"`
+	ImageURL    *string           `dynamodbav:"imageUrl,omitempty" json:"imageUrl,omitempty"`       // ✅ Optional Image URL
+	IsRead      map[string]bool   `dynamodbav:"isRead" json:"isRead"`                               // ✅ Tracks read status per user
+	ReadAt      map[string]string `dynamodbav:"readAt,omitempty" json:"readAt,omitempty"`           // ✅ When each user read the message (RFC3339)
+	ReadByAll   bool              `dynamodbav:"-" json:"readByAll"`                                 // ✅ Computed: every member has read it
+	Likes       map[string]bool   `dynamodbav:"likes,omitempty" json:"likes"`                       // ✅ Legacy likes; responses mirror the ❤️ reaction here
+	ReadCount   int               `dynamodbav:"readCount" json:"readCount"`                         // ✅ Number of users who have read the message
+	LikeCount   int               `dynamodbav:"likeCount,omitempty" json:"likeCount"`               // ✅ Legacy like count; responses mirror the ❤️ reaction here
+	MemberCount int               `dynamodbav:"memberCount" json:"memberCount"`                     // ✅ Total members in the group
+	Encrypted   bool              `dynamodbav:"encrypted,omitempty" json:"-"`                       // ✅ Content holds ciphertext
+	KeyVersion  int               `dynamodbav:"keyVersion,omitempty" json:"-"`                      // ✅ Data key version used for Content
+	MessageType string            `dynamodbav:"messageType,omitempty" json:"messageType,omitempty"` // ✅ "system" for activity log entries (empty = user message)
+	Event       *GroupSystemEvent `dynamodbav:"event,omitempty" json:"event,omitempty"`             // ✅ Structured details of a system entry
+
+	// ✅ Quote-reply: clients set the quoted message's id and createdAt; the server fills in its sender and snippet
+	ReplyToMessageID string `dynamodbav:"replyToMessageId,omitempty" json:"replyToMessageId,omitempty"`
+	ReplyToCreatedAt string `dynamodbav:"replyToCreatedAt,omitempty" json:"replyToCreatedAt,omitempty"`
+	ReplyToSenderID  string `dynamodbav:"replyToSenderId,omitempty" json:"replyToSenderId,omitempty"`
+	ReplySnippet     string `dynamodbav:"replySnippet,omitempty" json:"replySnippet,omitempty"`
+	ReplyKeyVersion  int    `dynamodbav:"replyKeyVersion,omitempty" json:"-"` // ✅ Data key version of an encrypted ReplySnippet
+
+	// ✅ Emoji reactions: stored as emoji -> string set of reactor handles, returned as summaries
+	ReactionSets map[string][]string `dynamodbav:"reactions,omitempty" json:"-"`
+	Reactions    []GroupReaction     `dynamodbav:"-" json:"reactions"`
+
+	// ✅ Set by the safety classifier, as on match messages: SafetyFlag shows members a banner;
+	// Hidden messages are returned without content
+	SafetyFlag string `dynamodbav:"safetyFlag,omitempty" json:"safetyFlag,omitempty"`
+	Hidden     bool   `dynamodbav:"hidden,omitempty" json:"hidden,omitempty"`
+}
+
+// WithholdContent strips the content of a group message hidden by moderation, keeping its safety flag
+func (m *GroupMessage) WithholdContent() {
+	m.Content, m.ImageURL, m.ReplySnippet = "", nil, ""
+}
+
+// GroupMessageTypeSystem marks activity log entries written by the server, not by a member.
+// System entries carry no isRead map and are left out of unread counts.
+const GroupMessageTypeSystem = "system"
+
+// GroupSystemSenderID is the senderId stored on system entries
+const GroupSystemSenderID = "system"
+
+// ✅ Group activity event types
+const (
+	GroupEventMemberJoined = "member_joined"
+	GroupEventMemberLeft   = "member_left"
+	GroupEventGroupRenamed = "group_renamed"
+)
+
+// GroupSystemEvent describes a membership or metadata change in a group
+type GroupSystemEvent struct {
+	Type     string `dynamodbav:"type" json:"type"`                             // One of the GroupEvent* constants
+	Actor    string `dynamodbav:"actor" json:"actor"`                           // Member who caused the change
+	Target   string `dynamodbav:"target,omitempty" json:"target,omitempty"`     // Member affected (joins)
+	OldValue string `dynamodbav:"oldValue,omitempty" json:"oldValue,omitempty"` // Previous value (renames)
+	NewValue string `dynamodbav:"newValue,omitempty" json:"newValue,omitempty"` // New value (renames)
+}
+
+// Describe returns the plain-text line shown for clients that don't render events specially
+func (e GroupSystemEvent) Describe() string {
+	switch e.Type {
+	case GroupEventMemberJoined:
+		if e.Target != "" && e.Target != e.Actor {
+			return e.Actor + " added " + e.Target + " to the group"
+		}
+		return e.Actor + " joined the group"
+	case GroupEventMemberLeft:
+		return e.Actor + " left the group"
+	case GroupEventGroupRenamed:
+		return e.Actor + " renamed the group to \"" + e.NewValue + "\""
+	}
+	return e.Actor + " updated the group"
 }
 
 // Table Name for DynamoDB
-const GroupMessageTable = "GroupMessages"
+var GroupMessageTable = "GroupMessages"
+
+// ComputeReadByAll reports whether every tracked member has read the message
+func (m *GroupMessage) ComputeReadByAll() bool {
+	if len(m.IsRead) == 0 {
+		return false
+	}
+	for _, read := range m.IsRead {
+		if !read {
+			return false
+		}
+	}
+	return true
+}
+
+// ReadReceipt records when one member read a group message
+type ReadReceipt struct {
+	UserHandle string `json:"userHandle"`
+	ReadAt     string `json:"readAt,omitempty"` // Empty for reads recorded before timestamps were kept
+}
+
+// GroupMessageReceipts lists who has and has not read a group message
+type GroupMessageReceipts struct {
+	GroupID     string        `json:"groupId"`
+	MessageID   string        `json:"messageId"`
+	CreatedAt   string        `json:"createdAt"`
+	Readers     []ReadReceipt `json:"readers"` // Oldest read first
+	Pending     []string      `json:"pending"` // Members who have not read it yet
+	ReadCount   int           `json:"readCount"`
+	MemberCount int           `json:"memberCount"`
+	ReadByAll   bool          `json:"readByAll"`
+}
+
+// GroupMessageWindow selects the part of a group's history to read. At most one of Before, After
+// and Around is set; none reads the latest messages.
+type GroupMessageWindow struct {
+	Before string // Cursor: messages older than it
+	After  string // Cursor: messages newer than it
+	Around string // RFC3339: jump to this moment, with messages on both sides of it
+	Limit  int
+}
+
+// GroupMessagePage is a window of a group's history, oldest first, with cursors to keep browsing
+type GroupMessagePage struct {
+	Messages     []GroupMessage
+	BeforeCursor string // Older messages (empty once the start of the history is reached)
+	AfterCursor  string // Newer messages (set whenever messages were returned, so clients can poll)
+}
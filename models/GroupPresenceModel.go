@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// GroupPresenceTable holds the ephemeral who-is-online and who-is-typing state of group chats
+// PK: groupId, SK: userhandle
+var GroupPresenceTable = "GroupPresence"
+
+// ✅ How long presence signals last without a fresh heartbeat
+const (
+	GroupOnlineWindow = 60 * time.Second // A member is online while heartbeats keep arriving
+	GroupTypingWindow = 6 * time.Second  // Typing clears unless the client repeats it
+
+	GroupPresencePollInterval = 3 * time.Second // How often clients should poll or send heartbeats
+)
+
+// GroupPresence is a member's latest heartbeat in a group
+type GroupPresence struct {
+	GroupID     string `dynamodbav:"groupId" json:"groupId"`
+	UserHandle  string `dynamodbav:"userhandle" json:"userhandle"`
+	LastSeenAt  int64  `dynamodbav:"lastSeenAt" json:"lastSeenAt"`                       // Unix milliseconds
+	TypingUntil int64  `dynamodbav:"typingUntil,omitempty" json:"typingUntil,omitempty"` // Unix milliseconds
+	ExpiresAt   int64  `dynamodbav:"expiresAt" json:"-"`                                 // ✅ TTL attribute (epoch seconds)
+}
+
+// GroupPresenceState is who else is online and typing in a group right now
+type GroupPresenceState struct {
+	GroupID             string   `json:"groupId"`
+	Online              []string `json:"online"`
+	Typing              []string `json:"typing"`
+	PollIntervalSeconds int      `json:"pollIntervalSeconds"`
+}
@@ -0,0 +1,22 @@
+package models
+
+// ✅ Dependency and overall health states
+const (
+	HealthStatusOK   = "ok"
+	HealthStatusFail = "fail"
+)
+
+// DependencyHealth is the result of checking one dependency
+type DependencyHealth struct {
+	Name      string `json:"name"`            // e.g. "dynamodb", "s3"
+	Status    string `json:"status"`          // ok / fail
+	LatencyMS int64  `json:"latencyMs"`       // Time the check took
+	Error     string `json:"error,omitempty"` // Why the check failed
+}
+
+// HealthReport is the readiness response for load balancers
+type HealthReport struct {
+	Status       string             `json:"status"` // ok only when every dependency is ok
+	CheckedAt    string             `json:"checkedAt"`
+	Dependencies []DependencyHealth `json:"dependencies"`
+}
@@ -0,0 +1,42 @@
+package models
+
+// ✅ Kinds of inbox conversations
+const (
+	InboxKindMatch = "match" // 1:1 conversation; ConversationID is the matchId
+	InboxKindGroup = "group" // Group chat; ConversationID is the groupId
+)
+
+// MaxInboxParticipantPreviews caps the member previews on a group entry; ParticipantCount has the total
+const MaxInboxParticipantPreviews = 4
+
+// InboxParticipant previews another member of a conversation
+type InboxParticipant struct {
+	UserHandle string `json:"userHandle"`
+	Name       string `json:"name,omitempty"`
+	Photo      string `json:"photo,omitempty"`
+}
+
+// InboxLastMessage previews the newest message of a conversation (already decrypted)
+type InboxLastMessage struct {
+	SenderHandle string `json:"senderHandle"`
+	Content      string `json:"content"`
+	HasImage     bool   `json:"hasImage,omitempty"`
+	System       bool   `json:"system,omitempty"` // Group activity log entry (member joined, group renamed, ...)
+	CreatedAt    string `json:"createdAt"`
+}
+
+// InboxEntry is one conversation in the unified inbox: a 1:1 match or a group chat
+type InboxEntry struct {
+	Kind             string             `json:"kind"` // InboxKindMatch or InboxKindGroup
+	ConversationID   string             `json:"conversationId"`
+	Title            string             `json:"title"`            // Group name, or the matched user's name
+	Participants     []InboxParticipant `json:"participants"`     // Other members, at most MaxInboxParticipantPreviews
+	ParticipantCount int                `json:"participantCount"` // Other members in total
+	LastMessage      *InboxLastMessage  `json:"lastMessage,omitempty"`
+	LastActivityAt   string             `json:"lastActivityAt"` // RFC3339 UTC: newest message, else when the conversation started
+	UnreadCount      int                `json:"unreadCount"`
+
+	// Set when profile, last-message or unread enrichment failed and the entry is partial
+	EnrichmentError bool       `json:"enrichmentError,omitempty"`
+	RetryHint       *RetryHint `json:"retryHint,omitempty"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import "strings"
+
+// InteractionAuditTable is the append-only history of interaction status changes, for support
+// PK: pairKey (both handles, sorted, "#"-joined), SK: "<RFC3339Nano>#<eventId>"
+// Entries are never updated; they expire after InteractionAuditRetentionDays (also for deleted accounts).
+var InteractionAuditTable = "InteractionAudit"
+
+// InteractionAuditRetentionDays is how long audit entries live before DynamoDB TTL removes them
+const InteractionAuditRetentionDays = 730
+
+// AuditStatusDeleted is the toStatus of an interaction that was removed (rewind, reconsider, unsave)
+const AuditStatusDeleted = "deleted"
+
+// ✅ Why a status changed, besides the interaction actions (like, dislike, save, ping, approve, reject)
+const (
+	AuditReasonMutualLike   = "mutual_like"   // The other user liked back
+	AuditReasonPingApproved = "ping_approved" // The recipient approved the ping
+	AuditReasonPingDeclined = "ping_declined" // The recipient declined the ping
+	AuditReasonRewind       = "rewind"        // The sender undid their dislike
+	AuditReasonReconsider   = "reconsider"    // The sender flipped an older dislike back from the passed list
+	AuditReasonUnsave       = "unsave"        // The sender removed the profile from their saved list
+)
+
+// InteractionCause is who changed an interaction and why
+type InteractionCause struct {
+	Actor  string
+	Reason string
+}
+
+// InteractionAuditEntry is one status change of senderHandle's interaction with receiverHandle
+type InteractionAuditEntry struct {
+	PairKey         string `dynamodbav:"pairKey" json:"-"`
+	SK              string `dynamodbav:"SK" json:"-"`
+	EventID         string `dynamodbav:"eventId" json:"eventId"`
+	SenderHandle    string `dynamodbav:"senderHandle" json:"senderHandle"`
+	ReceiverHandle  string `dynamodbav:"receiverHandle" json:"receiverHandle"`
+	InteractionType string `dynamodbav:"interactionType,omitempty" json:"interactionType,omitempty"`
+	Actor           string `dynamodbav:"actor" json:"actor"`                               // Who caused the change
+	FromStatus      string `dynamodbav:"fromStatus,omitempty" json:"fromStatus,omitempty"` // Empty when the interaction was created
+	ToStatus        string `dynamodbav:"toStatus" json:"toStatus"`                         // AuditStatusDeleted when removed
+	Reason          string `dynamodbav:"reason" json:"reason"`                             // Action or AuditReason*
+	MatchID         string `dynamodbav:"matchId,omitempty" json:"matchId,omitempty"`
+	At              string `dynamodbav:"at" json:"at"`       // RFC3339
+	ExpiresAt       int64  `dynamodbav:"expiresAt" json:"-"` // ✅ TTL attribute (epoch seconds)
+}
+
+// AuditPairKey is the InteractionAuditTable partition of two users, whichever order they are given in
+func AuditPairKey(a, b string) string {
+	if strings.Compare(a, b) > 0 {
+		a, b = b, a
+	}
+	return a + "#" + b
+}
@@ -20,6 +20,16 @@ type InteractionWithProfile struct {
 	Bio             string   `json:"bio,omitempty"`
 	Interests       []string `json:"interests,omitempty"`
 	DistanceBetween float64  `json:"distanceBetween,omitempty"` // Computed distance (not stored in DB)
+
+	Prompts []ProfilePrompt `json:"prompts,omitempty"` // Conversation starters from the profile
+	Spotify *SpotifyProfile `json:"spotify,omitempty"` // Top artists and anthem, when connected
+
+	// Set when profile enrichment failed and the entry is a placeholder
+	EnrichmentError bool       `json:"enrichmentError,omitempty"`
+	RetryHint       *RetryHint `json:"retryHint,omitempty"`
+
+	// Set when the sender is hidden until the user upgrades (see who liked you)
+	Locked bool `json:"locked,omitempty"`
 }
 
 // MatchedUserDetails represents the necessary data for a matched user
@@ -28,6 +38,10 @@ type MatchedUserDetails struct {
 	UserHandle string `json:"userHandle"`
 	Photo      string `json:"photo"`
 	MatchID    string `json:"matchId"`
+
+	Prompts []ProfilePrompt `json:"prompts,omitempty"` // Conversation starters for the first message
+
+	SharedInterests []string `json:"sharedInterests,omitempty"` // Catalog interest IDs both users picked
 }
 
 // MatchedUserDetailsForConnections represents a matched user with last message info
@@ -39,4 +53,32 @@ type MatchedUserDetailsForConnections struct {
 	LastMessage       string `json:"lastMessage"`
 	LastMessageSender string `json:"lastMessageSender"`
 	LastMessageIsRead bool   `json:"lastMessageIsRead"`
+	LastActivityAt    string `json:"lastActivityAt,omitempty"` // Newest message (RFC3339 UTC); matches are ordered by it
+
+	Prompts []ProfilePrompt `json:"prompts,omitempty"` // Conversation starters from the matched profile
+	Spotify *SpotifyProfile `json:"spotify,omitempty"` // Top artists and anthem, when connected
+
+	SharedInterests []string `json:"sharedInterests,omitempty"` // Catalog interest IDs both users picked
+
+	// Set when profile or last-message enrichment failed and the entry is a placeholder
+	EnrichmentError bool       `json:"enrichmentError,omitempty"`
+	RetryHint       *RetryHint `json:"retryHint,omitempty"`
+}
+
+// MatchDetail is everything the conversation screen needs about one match, in one document
+type MatchDetail struct {
+	MatchID        string       `json:"matchId"`
+	UserHandle     string       `json:"userHandle"` // The viewer
+	MatchedAt      string       `json:"matchedAt"`
+	LastActivityAt string       `json:"lastActivityAt,omitempty"`
+	Participant    *UserProfile `json:"participant,omitempty"` // The other user's profile, private fields removed
+	PinnedMessages []Message    `json:"pinnedMessages"`        // Most recently pinned first
+	UnreadCount    int          `json:"unreadCount"`
+
+	Settings     *ConversationSettings `json:"settings"`     // The viewer's settings for this conversation
+	CanSendMedia bool                  `json:"canSendMedia"` // False when the other user restricted the conversation to text
+
+	// Set when the other user's profile could not be loaded
+	EnrichmentError bool       `json:"enrichmentError,omitempty"`
+	RetryHint       *RetryHint `json:"retryHint,omitempty"`
 }
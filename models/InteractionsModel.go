@@ -1,20 +1,41 @@
 package models
 
 type Interaction struct {
-	PK              string  `dynamodbav:"PK" json:"PK"`                               // ✅ Partition Key: "USER#sender"
-	SK              string  `dynamodbav:"SK" json:"SK"`                               // ✅ Sort Key: "INTERACTION#receiver"
-	SenderHandle    string  `dynamodbav:"senderHandle" json:"senderHandle"`           // ✅ Who initiated the interaction
-	ReceiverHandle  string  `dynamodbav:"receiverHandle" json:"receiverHandle"`       // ✅ Target user
-	InteractionType string  `dynamodbav:"interactionType" json:"interactionType"`     // ✅ like, ping, invite
-	Status          string  `dynamodbav:"status" json:"status"`                       // ✅ pending, match, seen
-	MatchID         *string `dynamodbav:"matchId,omitempty" json:"matchId,omitempty"` // ✅ Assigned when matched
-	Message         *string `dynamodbav:"message,omitempty" json:"message,omitempty"` // ✅ Optional, only for pings or invites
-	CreatedAt       string  `dynamodbav:"createdAt" json:"createdAt"`                 // ✅ Timestamp of creation
-	LastUpdated     string  `dynamodbav:"lastUpdated" json:"lastUpdated"`             // ✅ Updated when status changes
+	PK              string  `dynamodbav:"PK" json:"PK"`                                     // ✅ Partition Key: "USER#sender"
+	SK              string  `dynamodbav:"SK" json:"SK"`                                     // ✅ Sort Key: "INTERACTION#receiver"
+	SenderHandle    string  `dynamodbav:"senderHandle" json:"senderHandle"`                 // ✅ Who initiated the interaction
+	ReceiverHandle  string  `dynamodbav:"receiverHandle" json:"receiverHandle"`             // ✅ Target user
+	InteractionType string  `dynamodbav:"interactionType" json:"interactionType"`           // ✅ like, ping, invite
+	Status          string  `dynamodbav:"status" json:"status"`                             // ✅ pending, match, seen
+	MatchID         *string `dynamodbav:"matchId,omitempty" json:"matchId,omitempty"`       // ✅ Assigned when matched
+	Message         *string `dynamodbav:"message,omitempty" json:"message,omitempty"`       // ✅ Optional, only for pings or invites
+	PhotoIndex      *int    `dynamodbav:"photoIndex,omitempty" json:"photoIndex,omitempty"` // ✅ Photo on screen when the like/dislike happened
+	CreatedAt       string  `dynamodbav:"createdAt" json:"createdAt"`                       // ✅ Timestamp of creation
+	LastUpdated     string  `dynamodbav:"lastUpdated" json:"lastUpdated"`                   // ✅ Updated when status changes
+	ExpiresAt       int64   `dynamodbav:"expiresAt,omitempty" json:"-"`                     // ✅ TTL attribute (epoch seconds), set while declined
+
+	SecondLookAt string `dynamodbav:"secondLookAt,omitempty" json:"secondLookAt,omitempty"` // ✅ When the receiver engaged with the resurfaced like (RFC3339)
+
+	LastActivityAt string `dynamodbav:"lastActivityAt,omitempty" json:"lastActivityAt,omitempty"` // ✅ Match records only: match time, then newest message (RFC3339 UTC); MatchActivityIndex key
+}
+
+// ActivityAt is when a match was last active: its newest message, or when it was made for records that
+// predate lastActivityAt
+func (i *Interaction) ActivityAt() string {
+	if i.LastActivityAt != "" {
+		return i.LastActivityAt
+	}
+	return i.LastUpdated
+}
+
+// InteractionFilter narrows the sent and received feeds; an empty list matches everything
+type InteractionFilter struct {
+	Types    []string // interactionType values, e.g. ["ping"]
+	Statuses []string // status values, e.g. ["pending"]
 }
 
 // ✅ Define table name
-const InteractionsTable = "Interactions"
+var InteractionsTable = "Interactions"
 
 // ✅ Define GSI for querying interactions where the user is the receiver
 const ReceiverHandleIndex = "receiverHandle-index" // PK: receiverHandle
@@ -22,3 +43,7 @@ const ReceiverHandleIndex = "receiverHandle-index" // PK: receiverHandle
 const StatusIndex = "status-index"
 
 const InteractionTypeIndex = "interactionType-index"
+
+// MatchActivityIndex is a sparse GSI over match records, ordered by last activity
+// PK: PK ("USER#<userHandle>"), SK: lastActivityAt (set while matched, removed on any other status)
+const MatchActivityIndex = "PK-lastActivityAt-index"
@@ -0,0 +1,9 @@
+package models
+
+// Interest is one entry of the static interest catalog. Profiles store its ID, so "Hiking" and
+// "hiking" are the same interest.
+type Interest struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Category string `json:"category"` // outdoors, active, food_drink, arts, music, entertainment, lifestyle, learning
+}
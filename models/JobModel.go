@@ -0,0 +1,100 @@
+package models
+
+import "encoding/json"
+
+// ✅ Background job types run by the job queue workers
+const (
+	JobTrackEvent     = "analytics.track"      // payload: TrackEventJob
+	JobPhotoSwipe     = "insights.photo_swipe" // payload: PhotoSwipeJob
+	JobFirstMessage   = "chat.first_message"   // payload: FirstMessageJob
+	JobPublishWebhook = "webhook.publish"      // payload: PublishWebhookJob
+	JobProcessPhoto   = "photos.process"       // payload: ProcessPhotoJob
+	JobDeleteObjects  = "s3.delete_objects"    // payload: DeleteObjectsJob
+	JobScreenMessage  = "chat.screen_message"  // payload: ScreenMessageJob
+	JobInitialMessage = "chat.initial_message" // payload: InitialMessageJob
+)
+
+// Job is the SQS message body: a typed payload plus the request it came from
+type Job struct {
+	JobID      string          `json:"jobId"`
+	Type       string          `json:"type"`                // One of the Job* constants
+	Payload    json.RawMessage `json:"payload"`             // Type-specific JSON
+	RequestID  string          `json:"requestId,omitempty"` // Carried into the worker's log lines
+	EnqueuedAt string          `json:"enqueuedAt"`          // RFC3339 (UTC)
+}
+
+// TrackEventJob records one funnel event
+type TrackEventJob struct {
+	EventType  string            `json:"eventType"`
+	UserHandle string            `json:"userhandle"`
+	Properties map[string]string `json:"properties,omitempty"`
+	EventID    string            `json:"eventId,omitempty"`    // Set when tracked, so a retried job rewrites the same event
+	OccurredAt string            `json:"occurredAt,omitempty"` // RFC3339Nano (UTC)
+}
+
+// PhotoSwipeJob feeds per-photo insights for the swiped user
+type PhotoSwipeJob struct {
+	UserHandle string `json:"userhandle"`
+	PhotoIndex int    `json:"photoIndex"`
+	Liked      bool   `json:"liked"`
+	SwipeID    string `json:"swipeId,omitempty"` // Receipt key, so a retried job counts the swipe once
+}
+
+// InitialMessageJob posts the opening message of a new match
+type InitialMessageJob struct {
+	MatchID   string `json:"matchId"`
+	Sender    string `json:"sender"`
+	Receiver  string `json:"receiver"`
+	IsPing    bool   `json:"isPing"`    // Opens with the ping's text instead of the match bot message
+	MessageID string `json:"messageId"` // Fixed when queued, so a retried job writes the same message
+	CreatedAt string `json:"createdAt"`
+}
+
+// FirstMessageJob checks whether a stored message was the sender's first in the match
+type FirstMessageJob struct {
+	MatchID   string `json:"matchId"`
+	SenderID  string `json:"senderId"`
+	CreatedAt string `json:"createdAt"` // Only messages up to this one count, so a late job sees the same history
+}
+
+// ScreenMessageJob runs the safety classifier on a stored message: a match message, or a group
+// message when GroupID is set
+type ScreenMessageJob struct {
+	MatchID   string `json:"matchId,omitempty"`
+	GroupID   string `json:"groupId,omitempty"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// PublishWebhookJob fans one platform event out to the subscribed webhooks
+type PublishWebhookJob struct {
+	EventType  string                 `json:"eventType"`
+	Data       map[string]interface{} `json:"data"`
+	DeliveryID string                 `json:"deliveryId,omitempty"` // Stays the same across retries so receivers can drop repeats
+	CreatedAt  string                 `json:"createdAt,omitempty"`  // RFC3339 (UTC)
+}
+
+// ProcessPhotoJob generates the renditions of an uploaded photo
+type ProcessPhotoJob struct {
+	UserHandle string `json:"userhandle"`
+	Key        string `json:"key"` // S3 key of the original
+}
+
+// DeleteObjectsJob removes media that is no longer referenced (a deleted photo and its renditions)
+type DeleteObjectsJob struct {
+	Keys []string `json:"keys"`
+}
+
+// JobReceiptsTable records counter updates already applied by a job, so a retried job doesn't count
+// twice
+// PK: receiptKey ("<job type>#<id>"), TTL: expiresAt
+var JobReceiptsTable = "JobReceipts"
+
+// JobReceiptRetentionDays outlasts the queue's message retention (at most 14 days in SQS)
+const JobReceiptRetentionDays = 15
+
+// JobReceipt marks one applied update
+type JobReceipt struct {
+	ReceiptKey string `dynamodbav:"receiptKey"`
+	AppliedAt  string `dynamodbav:"appliedAt"` // RFC3339 (UTC)
+	ExpiresAt  int64  `dynamodbav:"expiresAt"` // TTL (epoch seconds)
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// KeyBundlesTable holds the public keys each device published for end-to-end encryption
+// PK: userhandle, SK: deviceId
+var KeyBundlesTable = "KeyBundles"
+
+// OneTimePreKeysTable holds each device's unused one-time prekeys; fetching a bundle claims one
+// PK: userhandle, SK: preKeyId ("<deviceId>#<keyId>", see OneTimePreKeySortKey)
+var OneTimePreKeysTable = "OneTimePreKeys"
+
+// ✅ Key distribution limits
+const (
+	MaxDevicesPerUser       = 5
+	MaxOneTimePreKeysUpload = 100   // Per publish or replenish
+	MaxPublicKeyLength      = 256   // Base64 characters of a key or signature
+	MaxE2ECiphertextLength  = 65536 // Base64 characters of one device's ciphertext
+	LowOneTimePreKeyCount   = 10    // Clients should replenish below this
+	DailyKeyFetchLimit      = 100   // Bundle fetches (each claims prekeys) per requester and UTC day
+	QuotaDailyKeyFetches    = "daily_key_fetches"
+)
+
+// DeviceIDPattern keeps device ids usable in sort keys and ciphertext addresses
+var DeviceIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// SignedPreKey is a medium-term prekey signed with the device's identity key
+type SignedPreKey struct {
+	KeyID     int    `dynamodbav:"keyId" json:"keyId"`
+	PublicKey string `dynamodbav:"publicKey" json:"publicKey"`
+	Signature string `dynamodbav:"signature" json:"signature"`
+}
+
+// OneTimePreKey is a prekey handed out to at most one peer
+type OneTimePreKey struct {
+	KeyID     int    `dynamodbav:"keyId" json:"keyId"`
+	PublicKey string `dynamodbav:"publicKey" json:"publicKey"`
+}
+
+// KeyBundle is what a peer needs to start an end-to-end encrypted session with one device. Keys are
+// opaque base64 to the server; private keys never leave the device.
+type KeyBundle struct {
+	UserHandle     string         `dynamodbav:"userhandle" json:"userhandle"`
+	DeviceID       string         `dynamodbav:"deviceId" json:"deviceId"`
+	RegistrationID int            `dynamodbav:"registrationId" json:"registrationId"`
+	IdentityKey    string         `dynamodbav:"identityKey" json:"identityKey"`
+	SignedPreKey   SignedPreKey   `dynamodbav:"signedPreKey" json:"signedPreKey"`
+	OneTimePreKey  *OneTimePreKey `dynamodbav:"-" json:"oneTimePreKey,omitempty"` // ✅ Claimed for the fetcher; absent once the device ran out
+	UpdatedAt      string         `dynamodbav:"updatedAt" json:"updatedAt"`
+}
+
+// StoredOneTimePreKey is a OneTimePreKeys item
+type StoredOneTimePreKey struct {
+	UserHandle string `dynamodbav:"userhandle"`
+	PreKeyID   string `dynamodbav:"preKeyId"`
+	DeviceID   string `dynamodbav:"deviceId"`
+	KeyID      int    `dynamodbav:"keyId"`
+	PublicKey  string `dynamodbav:"publicKey"`
+}
+
+// OneTimePreKeySortKey builds the preKeyId of a device's prekey; key ids are zero-padded so a
+// device's keys sort in upload order
+func OneTimePreKeySortKey(deviceID string, keyID int) string {
+	return fmt.Sprintf("%s#%010d", deviceID, keyID)
+}
+
+// E2EEnvelope carries an end-to-end encrypted message: one ciphertext per recipient device (and
+// per other device of the sender), passed through without the server reading or altering it
+type E2EEnvelope struct {
+	SenderDeviceID string            `dynamodbav:"senderDeviceId" json:"senderDeviceId"`
+	Ciphertexts    map[string]string `dynamodbav:"ciphertexts" json:"ciphertexts"`                     // ✅ "<userhandle>:<deviceId>" → base64 ciphertext
+	MessageType    int               `dynamodbav:"messageType,omitempty" json:"messageType,omitempty"` // ✅ Protocol message type, e.g. prekey vs. whisper
+}
+
+// E2EAddress is the Ciphertexts key of a device
+func E2EAddress(userHandle, deviceID string) string {
+	return userHandle + ":" + deviceID
+}
@@ -0,0 +1,16 @@
+package models
+
+// ✅ Location update rules
+const (
+	GeohashPrecision     = 5   // ~4.9km x 4.9km cells for discovery
+	LocationJitterMeters = 100 // Smaller moves are GPS noise and are not stored
+)
+
+// LocationUpdate is the result of POST /profile/location
+type LocationUpdate struct {
+	Latitude          float64 `json:"latitude"`
+	Longitude         float64 `json:"longitude"`
+	Geohash           string  `json:"geohash"`
+	LocationUpdatedAt string  `json:"locationUpdatedAt,omitempty"`
+	Moved             bool    `json:"moved"` // False when the move was under LocationJitterMeters and nothing was stored
+}
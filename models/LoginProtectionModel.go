@@ -0,0 +1,75 @@
+package models
+
+import "time"
+
+// LoginAuditTable records every sign-in decision (allowed, stepped up, failed, locked out)
+// PK: userhandle, SK: createdAt (RFC3339Nano)
+// Records outlive deleted accounts and expire after LoginAuditRetentionDays.
+var LoginAuditTable = "LoginAudit"
+
+// LoginAuditRetentionDays is how long sign-in decisions are kept
+const LoginAuditRetentionDays = 365
+
+// ✅ Sign-in decisions
+const (
+	LoginDecisionAllowed        = "allowed"          // Session started (before sign-in codes were required)
+	LoginDecisionStepUpRequired = "step_up_required" // A sign-in code was sent
+	LoginDecisionStepUpPassed   = "step_up_passed"   // The code was right; session started
+	LoginDecisionStepUpFailed   = "step_up_failed"   // Wrong code, or no way to send one
+	LoginDecisionLockedOut      = "locked_out"       // Too many failures for the user or the IP
+)
+
+// ✅ Why a sign-in was risky
+const (
+	LoginRiskNewDevice   = "new_device"
+	LoginRiskNewCountry  = "new_country"
+	LoginRiskFirstSignIn = "first_sign_in" // No verified session to compare with
+)
+
+// ✅ How a sign-in code was sent
+const (
+	StepUpEmail = "email" // To the account's email
+	StepUpSMS   = "sms"   // To the account's phone, for accounts without an email
+)
+
+// ✅ Brute-force limits. Failures (unknown userhandles, wrong sign-in codes) are counted per user and
+// per client IP in fixed windows; reaching the limit locks sign-in for LoginLockoutDuration. Every
+// sign-in needs a code, so wrong guesses against a real account lock it too.
+const (
+	LoginFailureWindow    = 15 * time.Minute
+	LoginLockoutDuration  = 15 * time.Minute
+	MaxUserLoginFailures  = 5
+	MaxIPLoginFailures    = 20
+	StepUpCodeTTL         = 10 * time.Minute
+	MaxStepUpCodeAttempts = 3 // Wrong codes before the pending session is discarded
+)
+
+// ✅ Sign-in code sends are capped per user and per client IP in fixed windows, so the sign-in
+// endpoint can't be used to flood an inbox or run up the SMS bill
+const (
+	CodeSendWindow   = time.Hour
+	MaxUserCodeSends = 5
+	MaxIPCodeSends   = 20
+)
+
+// ✅ Counters and quota names for sign-in failures and code sends (QuotaCounters rows; IPs are keyed "ip#<address>")
+const (
+	QuotaLoginFailures = "login_failures"
+	QuotaLoginLockout  = "login_lockout"
+	QuotaCodeSends     = "code_sends"
+)
+
+// LoginAuditEntry is one sign-in decision
+type LoginAuditEntry struct {
+	UserHandle  string `dynamodbav:"userhandle" json:"userhandle"`
+	CreatedAt   string `dynamodbav:"createdAt" json:"createdAt"`
+	Decision    string `dynamodbav:"decision" json:"decision"`
+	Reason      string `dynamodbav:"reason,omitempty" json:"reason,omitempty"` // Risk or failure detail
+	SessionID   string `dynamodbav:"sessionId,omitempty" json:"sessionId,omitempty"`
+	IP          string `dynamodbav:"ip" json:"ip"`
+	Country     string `dynamodbav:"country,omitempty" json:"country,omitempty"`
+	Platform    string `dynamodbav:"platform,omitempty" json:"platform,omitempty"`
+	DeviceModel string `dynamodbav:"deviceModel,omitempty" json:"deviceModel,omitempty"`
+	DeviceID    string `dynamodbav:"deviceId,omitempty" json:"deviceId,omitempty"`
+	ExpiresAt   int64  `dynamodbav:"expiresAt" json:"-"` // ✅ TTL attribute (epoch seconds)
+}
@@ -0,0 +1,66 @@
+package models
+
+// MessageReviewsTable holds messages flagged by the safety classifier until an admin reviews them
+// PK: matchId, SK: createdAt (the flagged message's key); GSI status-createdAt-index lists the queue oldest first
+var MessageReviewsTable = "MessageReviews"
+
+// MessageReviewStatusIndex is the GSI (PK status, SK createdAt) the review queue is read from
+const MessageReviewStatusIndex = "status-createdAt-index"
+
+// GroupReviewPrefix prefixes the matchId key of reviews of group messages: "GROUP#<groupId>"
+const GroupReviewPrefix = "GROUP#"
+
+// ✅ Violation categories the safety classifier reports
+const (
+	SafetyCategoryHarassment        = "harassment"
+	SafetyCategoryMinorSolicitation = "minor_solicitation" // Sexual solicitation of minors
+	SafetyCategoryScam              = "scam"
+)
+
+// SafetyCategories lists every category the classifier may report
+var SafetyCategories = []string{SafetyCategoryHarassment, SafetyCategoryMinorSolicitation, SafetyCategoryScam}
+
+// ✅ Classifier confidence (0-1) at which a message is flagged (banner + review) or also hidden
+const (
+	SafetyFlagConfidence = 0.6
+	SafetyHideConfidence = 0.9
+)
+
+// ✅ Message review states
+const (
+	MessageReviewPending   = "pending"
+	MessageReviewUpheld    = "upheld"    // The message stays (or becomes) hidden
+	MessageReviewDismissed = "dismissed" // The banner is cleared and the message shown again
+)
+
+// SafetyClassification is the classifier's verdict on one message
+type SafetyClassification struct {
+	Category   string  `json:"category"`   // One of SafetyCategories
+	Confidence float64 `json:"confidence"` // 0-1
+}
+
+// MessageReview is one flagged message. The content is never stored here; the queue decrypts it
+// from the message when listing.
+type MessageReview struct {
+	MatchID     string  `dynamodbav:"matchId" json:"matchId"`                     // GroupReviewPrefix + GroupID for group messages
+	GroupID     string  `dynamodbav:"groupId,omitempty" json:"groupId,omitempty"` // Set for group messages
+	CreatedAt   string  `dynamodbav:"createdAt" json:"createdAt"`                 // The message's createdAt
+	MessageID   string  `dynamodbav:"messageId" json:"messageId"`
+	SenderID    string  `dynamodbav:"senderId" json:"senderId"`
+	RecipientID string  `dynamodbav:"recipientId" json:"recipientId"` // Empty for group messages
+	Category    string  `dynamodbav:"category" json:"category"`
+	Confidence  float64 `dynamodbav:"confidence" json:"confidence"`
+	Hidden      bool    `dynamodbav:"hidden" json:"hidden"` // Auto-hidden at high confidence
+	Status      string  `dynamodbav:"status" json:"status"`
+	FlaggedAt   string  `dynamodbav:"flaggedAt" json:"flaggedAt"`
+	ReviewedAt  string  `dynamodbav:"reviewedAt,omitempty" json:"reviewedAt,omitempty"`
+	ReviewedBy  string  `dynamodbav:"reviewedBy,omitempty" json:"reviewedBy,omitempty"`
+
+	Content string `dynamodbav:"-" json:"content,omitempty"` // ✅ Filled in for the admin queue
+}
+
+// WithholdContent strips the content of a message hidden by moderation; the safety flag stays so
+// clients can show a banner in its place
+func (m *Message) WithholdContent() {
+	m.Content, m.ImageURL, m.ReplySnippet = "", "", ""
+}
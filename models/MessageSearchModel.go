@@ -0,0 +1,24 @@
+package models
+
+// MessageSearchQuery describes a keyword search over one conversation's history
+type MessageSearchQuery struct {
+	Keyword     string // Case-insensitive substring to look for in message content
+	From        string // Inclusive lower bound on createdAt (RFC3339); empty means no bound
+	To          string // Inclusive upper bound on createdAt (RFC3339); empty means no bound
+	ContextSize int    // Messages to include before and after each hit
+	Limit       int    // Maximum number of hits to return (newest first)
+}
+
+// MessageSearchResult is a 1:1 message that matched a search plus the messages around it
+type MessageSearchResult struct {
+	Message Message   `json:"message"`
+	Before  []Message `json:"before"` // Oldest first
+	After   []Message `json:"after"`  // Oldest first
+}
+
+// GroupMessageSearchResult is a group message that matched a search plus the messages around it
+type GroupMessageSearchResult struct {
+	Message GroupMessage   `json:"message"`
+	Before  []GroupMessage `json:"before"` // Oldest first
+	After   []GroupMessage `json:"after"`  // Oldest first
+}
@@ -12,16 +12,74 @@ type Message struct {
 	MessageID string `dynamodbav:"messageId" json:"messageId"`
 	SenderID  string `dynamodbav:"senderId" json:"senderId"`
 	ImageURL  string `dynamodbav:"imageUrl,omitempty" json:"imageUrl,omitempty"` // ✅ New Field for Image Messages
+
+	// ✅ Set when Content holds ciphertext sealed with the conversation's data key
+	Encrypted  bool `dynamodbav:"encrypted,omitempty" json:"-"`
+	KeyVersion int  `dynamodbav:"keyVersion,omitempty" json:"-"`
+
+	ExpiresAt int64 `dynamodbav:"expiresAt,omitempty" json:"-"` // ✅ TTL attribute (epoch seconds), set once the pair unmatches
+
+	// ✅ Quote-reply: clients set the quoted message's id and createdAt; the server fills in its sender and snippet
+	ReplyToMessageID string `dynamodbav:"replyToMessageId,omitempty" json:"replyToMessageId,omitempty"`
+	ReplyToCreatedAt string `dynamodbav:"replyToCreatedAt,omitempty" json:"replyToCreatedAt,omitempty"`
+	ReplyToSenderID  string `dynamodbav:"replyToSenderId,omitempty" json:"replyToSenderId,omitempty"`
+	ReplySnippet     string `dynamodbav:"replySnippet,omitempty" json:"replySnippet,omitempty"`
+	ReplyKeyVersion  int    `dynamodbav:"replyKeyVersion,omitempty" json:"-"` // ✅ Data key version of an encrypted ReplySnippet
+
+	// ✅ Receipts acknowledged by the recipient's client (RFC3339); Status is computed for responses
+	DeliveredAt string `dynamodbav:"deliveredAt,omitempty" json:"deliveredAt,omitempty"`
+	ReadAt      string `dynamodbav:"readAt,omitempty" json:"readAt,omitempty"`
+	Status      string `dynamodbav:"-" json:"status,omitempty"`
+
+	E2E *E2EEnvelope `dynamodbav:"e2e,omitempty" json:"e2e,omitempty"` // ✅ End-to-end encrypted payload; Content stays empty
+
+	// ✅ Set by the safety classifier: SafetyFlag (a SafetyCategories value) shows the recipient a banner;
+	// Hidden messages are returned without content
+	SafetyFlag string `dynamodbav:"safetyFlag,omitempty" json:"safetyFlag,omitempty"`
+	Hidden     bool   `dynamodbav:"hidden,omitempty" json:"hidden,omitempty"`
+
+	// ✅ Pinned to the top of the conversation by either participant (RFC3339); PinnedMessagesIndex key
+	PinnedAt string `dynamodbav:"pinnedAt,omitempty" json:"pinnedAt,omitempty"`
+	PinnedBy string `dynamodbav:"pinnedBy,omitempty" json:"pinnedBy,omitempty"`
+
+	AudioURL string `dynamodbav:"audioUrl,omitempty" json:"audioUrl,omitempty"` // ✅ S3 key of a voice message; refused like images in text-only conversations
 }
 
+// MaxPinnedMessages caps how many messages a conversation can have pinned at once
+const MaxPinnedMessages = 10
+
+// PinnedMessagesIndex is a sparse GSI over pinned messages, so reading them doesn't walk the conversation
+// PK: matchId, SK: pinnedAt (set on pin, removed on unpin)
+const PinnedMessagesIndex = "matchId-pinnedAt-index"
+
+// ✅ Delivery states of a message, as its sender sees them
+const (
+	MessageStatusSent      = "sent"
+	MessageStatusDelivered = "delivered"
+	MessageStatusRead      = "read"
+)
+
 // MessagesTable is the DynamoDB table name
-const MessagesTable = "Message"
+var MessagesTable = "Message"
 
 // ✅ Convert `isUnread` to boolean in Go
 func (m *Message) IsUnreadBool() bool {
 	return strings.ToLower(m.IsUnread) == "true"
 }
 
+// DeliveryStatus is how far the message got: read once the recipient read it (including reads from
+// before receipts were timestamped), delivered once their client acknowledged it, else sent
+func (m *Message) DeliveryStatus() string {
+	switch {
+	case m.ReadAt != "" || !m.IsUnreadBool():
+		return MessageStatusRead
+	case m.DeliveredAt != "":
+		return MessageStatusDelivered
+	default:
+		return MessageStatusSent
+	}
+}
+
 // ✅ Convert boolean back to string before saving to DB
 func (m *Message) SetIsUnread(value bool) {
 	if value {
@@ -30,3 +88,18 @@ func (m *Message) SetIsUnread(value bool) {
 		m.IsUnread = "false"
 	}
 }
+
+// MaxReplySnippetLength caps the quoted excerpt stored with a reply, in characters
+const MaxReplySnippetLength = 120
+
+// ReplySnippet builds the excerpt of a quoted message that is stored with replies to it
+func ReplySnippet(content string, hasImage bool) string {
+	runes := []rune(strings.TrimSpace(content))
+	if len(runes) > MaxReplySnippetLength {
+		return string(runes[:MaxReplySnippetLength]) + "…"
+	}
+	if len(runes) == 0 && hasImage {
+		return "📷 Photo"
+	}
+	return string(runes)
+}
@@ -0,0 +1,38 @@
+package models
+
+// ModerationRuleSet is one version of the keyword/regex rules used to screen user content
+type ModerationRuleSet struct {
+	RuleSetID    string              `dynamodbav:"ruleSetId" json:"ruleSetId"`                           // ✅ Partition Key (always ActiveRuleSetID for now)
+	Version      int                 `dynamodbav:"version" json:"version"`                               // ✅ Sort Key (monotonic, latest wins)
+	Keywords     []string            `dynamodbav:"keywords" json:"keywords"`                             // Case-insensitive whole-word matches
+	Patterns     []string            `dynamodbav:"patterns" json:"patterns"`                             // Go regular expressions
+	UpdatedBy    string              `dynamodbav:"updatedBy" json:"updatedBy"`                           // Admin who published this version
+	CreatedAt    string              `dynamodbav:"createdAt" json:"createdAt"`                           // Timestamp of publication
+	FirstMessage *FirstMessagePolicy `dynamodbav:"firstMessage,omitempty" json:"firstMessage,omitempty"` // Limits for new/unverified senders (nil = default)
+}
+
+// ModerationRulesTable is the DynamoDB table holding versioned rule sets
+var ModerationRulesTable = "ModerationRules"
+
+// ActiveRuleSetID is the partition holding the rule set applied to chat messages
+const ActiveRuleSetID = "chat"
+
+// FirstMessagePolicy restricts messages from new or unverified accounts until the recipient replies
+type FirstMessagePolicy struct {
+	NewAccountDays    int  `dynamodbav:"newAccountDays" json:"newAccountDays"`       // Accounts younger than this are restricted (0 disables)
+	ApplyToUnverified bool `dynamodbav:"applyToUnverified" json:"applyToUnverified"` // Restrict accounts without a verified email
+	MaxLength         int  `dynamodbav:"maxLength" json:"maxLength"`                 // Character cap (0 = no cap)
+	BlockLinks        bool `dynamodbav:"blockLinks" json:"blockLinks"`               // Reject URLs and bare domains
+	BlockImages       bool `dynamodbav:"blockImages" json:"blockImages"`             // Reject image/media messages
+}
+
+// DefaultFirstMessagePolicy applies until an admin publishes a rule set with its own policy
+func DefaultFirstMessagePolicy() FirstMessagePolicy {
+	return FirstMessagePolicy{
+		NewAccountDays:    7,
+		ApplyToUnverified: true,
+		MaxLength:         300,
+		BlockLinks:        true,
+		BlockImages:       true,
+	}
+}
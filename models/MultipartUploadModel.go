@@ -0,0 +1,29 @@
+package models
+
+// ✅ Profile video upload limits
+const (
+	VideoPartSize       = 8 << 20   // Bytes per part (S3 requires at least 5 MB for every part but the last)
+	MaxVideoBytes       = 200 << 20 // Largest accepted profile video
+	MaxPartURLsPerBatch = 100       // Part URLs presigned per request
+	MaxUploadPartNumber = 10000     // S3's part number limit
+)
+
+// MultipartUpload is a started multipart upload and how the client should split the file
+type MultipartUpload struct {
+	Key       string `json:"key"`
+	UploadID  string `json:"uploadId"`
+	PartSize  int64  `json:"partSize"`  // Every part but the last must be exactly this size
+	PartCount int    `json:"partCount"` // Parts numbered 1..partCount
+}
+
+// PartUploadURL is a presigned PUT for one part
+type PartUploadURL struct {
+	PartNumber int32  `json:"partNumber"`
+	URL        string `json:"url"`
+}
+
+// UploadedPart is a part the client has PUT, identified by the ETag header S3 returned for it
+type UploadedPart struct {
+	PartNumber int32  `json:"partNumber"`
+	ETag       string `json:"etag"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// NetworkBlocksTable holds admin-managed network blocks
+// PK: "blockId". Temporary blocks expire via DynamoDB TTL on expiresAt.
+var NetworkBlocksTable = "NetworkBlocks"
+
+// ✅ What a network block matches on
+const (
+	NetworkBlockCIDR    = "cidr"    // Client IP inside a CIDR range (a bare IP blocks just that address)
+	NetworkBlockASN     = "asn"     // Client's autonomous system number, from the ASN header the edge sets
+	NetworkBlockCountry = "country" // Client's ISO country, from the country header the edge sets
+)
+
+// NetworkBlockKinds lists the accepted block kinds
+var NetworkBlockKinds = []string{NetworkBlockCIDR, NetworkBlockASN, NetworkBlockCountry}
+
+// NetworkThrottleWindow is the fixed window per-IP and per-ASN request rates are counted in
+const NetworkThrottleWindow = time.Minute
+
+// ✅ Quota names of network throttling errors
+const (
+	QuotaIPRate  = "ip_rate"
+	QuotaASNRate = "asn_rate"
+)
+
+// MaxNetworkBlockReasonLength caps the admin note on a block
+const MaxNetworkBlockReasonLength = 500
+
+// NetworkBlock refuses API requests from a network range, ASN or country
+type NetworkBlock struct {
+	BlockID   string `dynamodbav:"blockId" json:"blockId"` // ✅ Partition Key
+	Kind      string `dynamodbav:"kind" json:"kind"`       // One of NetworkBlockKinds
+	Value     string `dynamodbav:"value" json:"value"`     // Canonical CIDR, ASN number or upper-case ISO country code
+	Reason    string `dynamodbav:"reason,omitempty" json:"reason,omitempty"`
+	CreatedBy string `dynamodbav:"createdBy" json:"createdBy"`             // Admin who added the block
+	CreatedAt string `dynamodbav:"createdAt" json:"createdAt"`             // RFC3339
+	Until     string `dynamodbav:"until,omitempty" json:"until,omitempty"` // RFC3339; permanent when empty
+	ExpiresAt int64  `dynamodbav:"expiresAt,omitempty" json:"-"`           // ✅ TTL attribute (epoch seconds) mirroring Until
+}
+
+// NetworkBlockRequest adds a block (admin)
+type NetworkBlockRequest struct {
+	Kind      string `json:"kind"`
+	Value     string `json:"value"` // e.g. "203.0.113.0/24", "AS64500" or "KP"
+	Reason    string `json:"reason,omitempty"`
+	Until     string `json:"until,omitempty"` // RFC3339; omit for a permanent block
+	CreatedBy string `json:"-"`               // The admin's session user
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// NewUserWindow is how long after sign-up a profile counts as new (and gets the ranking boost)
+const NewUserWindow = 72 * time.Hour
+
+// IsNewUser reports whether the profile was created within NewUserWindow of now; legacy profiles
+// without createdAt are never new
+func (p *UserProfile) IsNewUser(now time.Time) bool {
+	createdAt, err := time.Parse(time.RFC3339, p.CreatedAt)
+	return err == nil && now.Sub(createdAt) < NewUserWindow
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// ConversationOpenersTable caches the openers generated for each side of a match
+// PK: matchId, SK: userhandle
+var ConversationOpenersTable = "ConversationOpeners"
+
+// ✅ Opener generation limits
+const (
+	MinOpeners       = 2
+	MaxOpeners       = 3
+	MaxOpenerLength  = 200 // Characters; longer suggestions are dropped
+	OpenerCacheTTL   = 7 * 24 * time.Hour
+	maxOpenerPrompts = 3 // Profile prompts per user sent to the model
+)
+
+// ConversationOpeners are suggested first messages for userHandle to send in a match. They are
+// regenerated when either profile changes (tracked by ProfileVersions) or the cache expires.
+type ConversationOpeners struct {
+	MatchID         string   `dynamodbav:"matchId" json:"matchId"`
+	UserHandle      string   `dynamodbav:"userhandle" json:"userhandle"`
+	Openers         []string `dynamodbav:"openers" json:"openers"`
+	ProfileVersions string   `dynamodbav:"profileVersions" json:"-"` // ✅ "<mine>:<theirs>" when generated
+	GeneratedAt     string   `dynamodbav:"generatedAt" json:"generatedAt"`
+	ExpiresAt       int64    `dynamodbav:"expiresAt" json:"-"` // ✅ TTL attribute (epoch seconds)
+	Cached          bool     `dynamodbav:"-" json:"cached"`
+}
+
+// OpenerProfile is what the model sees of one user: no handles, contact details or locations
+type OpenerProfile struct {
+	Name      string          `json:"name,omitempty"`
+	Bio       string          `json:"bio,omitempty"`
+	Interests []string        `json:"interests,omitempty"`
+	Prompts   []ProfilePrompt `json:"prompts,omitempty"`
+}
+
+// OpenerProfileOf picks the parts of a profile openers are written from; hidden names are left out
+func OpenerProfileOf(p *UserProfile) OpenerProfile {
+	profile := OpenerProfile{Bio: p.Bio, Interests: p.Interests}
+	if !p.HideName {
+		profile.Name = p.UserName
+	}
+	for _, prompt := range p.Prompts {
+		if len(profile.Prompts) == maxOpenerPrompts {
+			break
+		}
+		profile.Prompts = append(profile.Prompts, ProfilePrompt{Question: prompt.Question, Answer: prompt.Answer})
+	}
+	return profile
+}
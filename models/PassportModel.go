@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// MaxPassportDays caps how long a virtual location lasts before suggestions revert to GPS
+const MaxPassportDays = 7
+
+// PassportLocation is a temporary virtual location premium users browse suggestions from
+type PassportLocation struct {
+	City      string  `dynamodbav:"city" json:"city"`
+	Latitude  float64 `dynamodbav:"latitude" json:"latitude"`
+	Longitude float64 `dynamodbav:"longitude" json:"longitude"`
+	ExpiresAt string  `dynamodbav:"expiresAt" json:"expiresAt"` // RFC3339; the GPS location applies again afterwards
+	SetAt     string  `dynamodbav:"setAt" json:"setAt"`
+}
+
+// IsActive reports whether the passport location has not yet expired
+func (p *PassportLocation) IsActive(now time.Time) bool {
+	if p == nil {
+		return false
+	}
+	expiresAt, err := time.Parse(time.RFC3339, p.ExpiresAt)
+	return err == nil && now.Before(expiresAt)
+}
+
+// DiscoveryLocation is where suggestions are searched from: an active passport location while the
+// user still has the entitlement, otherwise their GPS coordinates
+func (p *UserProfile) DiscoveryLocation(now time.Time) (latitude, longitude float64) {
+	if p.Passport.IsActive(now) && p.EffectiveSubscription().Has(EntitlementPassport) {
+		return p.Passport.Latitude, p.Passport.Longitude
+	}
+	return p.Latitude, p.Longitude
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// MaxPauseDays caps how far ahead an auto-resume date may be set
+const MaxPauseDays = 90
+
+// IsPaused reports whether the user is snoozed at now; a pause with a passed auto-resume date has
+// ended even though the flag is still stored
+func (p *UserProfile) IsPaused(now time.Time) bool {
+	if !p.Paused {
+		return false
+	}
+	if p.PausedUntil == "" {
+		return true
+	}
+	until, err := time.Parse(time.RFC3339, p.PausedUntil)
+	return err != nil || now.Before(until)
+}
@@ -0,0 +1,30 @@
+package models
+
+// PhotoInsightsTable stores swipe counters per profile photo
+// PK: userhandle (owner of the photo), SK: photoIndex
+var PhotoInsightsTable = "PhotoInsights"
+
+// PhotoSwipeStats is the stored counter row for one photo slot
+type PhotoSwipeStats struct {
+	UserHandle string `dynamodbav:"userhandle" json:"userhandle"`
+	PhotoIndex int    `dynamodbav:"photoIndex" json:"photoIndex"`
+	Likes      int    `dynamodbav:"likes" json:"likes"`
+	Dislikes   int    `dynamodbav:"dislikes" json:"dislikes"`
+}
+
+// PhotoPerformance is one photo's swipe performance as shown in insights
+type PhotoPerformance struct {
+	PhotoIndex  int     `json:"photoIndex"`
+	Photo       string  `json:"photo,omitempty"`
+	Likes       int     `json:"likes"`
+	Dislikes    int     `json:"dislikes"`
+	Impressions int     `json:"impressions"` // Likes + dislikes recorded on this photo
+	LikeRate    float64 `json:"likeRate"`    // Likes / impressions (0 with no impressions)
+}
+
+// PhotoInsights is the per-photo performance report for a user's profile
+type PhotoInsights struct {
+	UserHandle     string             `json:"userhandle"`
+	Photos         []PhotoPerformance `json:"photos"`
+	SuggestedOrder []int              `json:"suggestedOrder"` // Photo indexes, best performing first
+}
@@ -0,0 +1,40 @@
+package models
+
+// ✅ Rendition sizes generated for every uploaded photo (longest edge, in pixels)
+const (
+	PhotoThumbnail = "thumbnail"
+	PhotoMedium    = "medium"
+	PhotoLarge     = "large"
+)
+
+// PhotoRenditionSizes maps each rendition to its longest edge; smaller originals are not upscaled
+var PhotoRenditionSizes = map[string]int{
+	PhotoThumbnail: 200,
+	PhotoMedium:    640,
+	PhotoLarge:     1280,
+}
+
+// PhotoRenditions are the resized copies of one uploaded photo, stored on the profile under the
+// original's key so list screens can load a thumbnail instead of the full-resolution image
+type PhotoRenditions struct {
+	Original    string `dynamodbav:"original" json:"original"`       // S3 key of the uploaded photo
+	Thumbnail   string `dynamodbav:"thumbnail" json:"thumbnail"`     // S3 key of the thumbnail JPEG
+	Medium      string `dynamodbav:"medium" json:"medium"`           // S3 key of the medium JPEG
+	Large       string `dynamodbav:"large" json:"large"`             // S3 key of the large JPEG
+	Width       int    `dynamodbav:"width" json:"width"`             // Original width in pixels
+	Height      int    `dynamodbav:"height" json:"height"`           // Original height in pixels
+	ProcessedAt string `dynamodbav:"processedAt" json:"processedAt"` // RFC3339
+}
+
+// PhotoRenditionsMap holds a profile's renditions keyed by the original's S3 key
+type PhotoRenditionsMap map[string]PhotoRenditions
+
+// ProfilePhotos is a profile's photo list after a photo management change. Photos[0] is the
+// primary photo shown on cards and match lists.
+type ProfilePhotos struct {
+	UserHandle      string             `json:"userhandle"`
+	Photos          []string           `json:"photos"`
+	PhotoCaptions   map[string]string  `json:"photoCaptions,omitempty"`
+	PhotoRenditions PhotoRenditionsMap `json:"photoRenditions,omitempty"`
+	ProfileVersion  int                `json:"profileVersion"`
+}
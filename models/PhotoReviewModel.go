@@ -0,0 +1,51 @@
+package models
+
+import "slices"
+
+// PhotoReviewsTable holds photos flagged by automated moderation until an admin reviews them
+// PK: photoKey; GSI status-createdAt-index lists the review queue oldest first
+var PhotoReviewsTable = "PhotoReviews"
+
+// PhotoReviewStatusIndex is the GSI (PK status, SK createdAt) the review queue is read from
+const PhotoReviewStatusIndex = "status-createdAt-index"
+
+// ✅ Photo review states
+const (
+	PhotoReviewPending  = "pending"  // Quarantined: hidden from suggestions
+	PhotoReviewApproved = "approved" // Released back onto the profile
+	PhotoReviewRejected = "rejected" // Removed from the profile and deleted
+)
+
+// PhotoReview is one flagged photo and the labels that flagged it
+type PhotoReview struct {
+	PhotoKey   string            `dynamodbav:"photoKey" json:"photoKey"`
+	UserHandle string            `dynamodbav:"userhandle" json:"userhandle"`
+	Status     string            `dynamodbav:"status" json:"status"`
+	Labels     []ModerationLabel `dynamodbav:"labels" json:"labels"`
+	CreatedAt  string            `dynamodbav:"createdAt" json:"createdAt"`
+	ReviewedAt string            `dynamodbav:"reviewedAt,omitempty" json:"reviewedAt,omitempty"`
+	ReviewedBy string            `dynamodbav:"reviewedBy,omitempty" json:"reviewedBy,omitempty"`
+}
+
+// ModerationLabel is one unsafe-content label detected in a photo
+type ModerationLabel struct {
+	Name       string  `dynamodbav:"name" json:"name"`
+	ParentName string  `dynamodbav:"parentName,omitempty" json:"parentName,omitempty"`
+	Confidence float64 `dynamodbav:"confidence" json:"confidence"` // 0-100
+}
+
+// PhotoModerationCategories are the top-level label categories that quarantine a photo
+var PhotoModerationCategories = []string{"Explicit Nudity", "Explicit", "Violence", "Visually Disturbing"}
+
+// HideQuarantinedPhotos removes photos awaiting review from a profile shown to other users
+func (p *UserProfile) HideQuarantinedPhotos() {
+	if len(p.QuarantinedPhotos) == 0 {
+		return
+	}
+	p.Photos = slices.DeleteFunc(p.Photos, func(photo string) bool { return slices.Contains(p.QuarantinedPhotos, photo) })
+	for _, photo := range p.QuarantinedPhotos {
+		delete(p.PhotoCaptions, photo)
+		delete(p.PhotoRenditions, photo)
+	}
+	p.QuarantinedPhotos = nil
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// QuotaCountersTable counts rate-limited actions per user and UTC day (sign-in failures per window)
+// PK: userhandle (or "ip#<address>"), SK: counter ("<quota>#<YYYY-MM-DD>")
+var QuotaCountersTable = "QuotaCounters"
+
+// ✅ Ping limits
+const (
+	DailyPingLimit      = 5                  // Pings a user may send per UTC day
+	PingDeclineCooldown = 7 * 24 * time.Hour // How long after a declined ping the same recipient can't be pinged again
+)
+
+// ✅ Quotas named in quota errors
+const (
+	QuotaDailyPings   = "daily_pings"
+	QuotaPingCooldown = "ping_cooldown"
+)
+
+// QuotaCounter is one user's usage of a quota on one UTC day
+type QuotaCounter struct {
+	UserHandle string `dynamodbav:"userhandle"`
+	Counter    string `dynamodbav:"counter"`
+	Count      int    `dynamodbav:"count"`
+	ExpiresAt  int64  `dynamodbav:"expiresAt"` // ✅ TTL attribute (epoch seconds), the day after
+}
@@ -0,0 +1,46 @@
+package models
+
+import "strings"
+
+// ✅ GSIs on the Users table for browsing by named place (keys derived from Place)
+const (
+	CityKeyIndex   = "cityKey-index"
+	RegionKeyIndex = "regionKey-index"
+)
+
+// ✅ Browse scopes: the user's own city, or the wider region for low-density areas
+const (
+	BrowseScopeCity   = "city"
+	BrowseScopeRegion = "region"
+)
+
+// Place is the named area a location falls in, from reverse geocoding
+type Place struct {
+	City    string `dynamodbav:"city,omitempty" json:"city,omitempty"`
+	Region  string `dynamodbav:"region,omitempty" json:"region,omitempty"` // State, province, etc.
+	Country string `dynamodbav:"country" json:"country"`                   // ISO 3166-1 alpha-3 code
+}
+
+// CityKey is the cityKey-index value; empty when the city is unknown
+func (p *Place) CityKey() string {
+	if p == nil || p.City == "" {
+		return ""
+	}
+	return placeKey(p.Country, p.Region, p.City)
+}
+
+// RegionKey is the regionKey-index value; empty when the region is unknown
+func (p *Place) RegionKey() string {
+	if p == nil || p.Region == "" {
+		return ""
+	}
+	return placeKey(p.Country, p.Region)
+}
+
+// placeKey joins place names case-insensitively
+func placeKey(parts ...string) string {
+	for i, part := range parts {
+		parts[i] = strings.ToLower(strings.TrimSpace(part))
+	}
+	return strings.Join(parts, "#")
+}
@@ -0,0 +1,32 @@
+package models
+
+// ✅ Profile sections counted by the completeness score
+const (
+	CompletenessPhotos        = "photos"
+	CompletenessBio           = "bio"
+	CompletenessInterests     = "interests"
+	CompletenessQuestionnaire = "questionnaire"
+	CompletenessVerification  = "verification"
+)
+
+// CompletenessWeights is each section's share of the score; they add up to 100
+var CompletenessWeights = map[string]int{
+	CompletenessPhotos:        30,
+	CompletenessBio:           15,
+	CompletenessInterests:     15,
+	CompletenessQuestionnaire: 20,
+	CompletenessVerification:  20,
+}
+
+// ✅ What a section needs to earn its full weight (fewer photos/interests/answers earn a share)
+const (
+	CompletePhotoCount         = 3
+	CompleteInterestCount      = 3
+	CompleteQuestionnaireCount = 5
+)
+
+// CompletenessScore is how complete a profile is and what the user could add
+type CompletenessScore struct {
+	Score   int      `json:"score"`   // 0-100
+	Missing []string `json:"missing"` // Sections not yet complete, highest weight first
+}
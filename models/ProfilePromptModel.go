@@ -0,0 +1,33 @@
+package models
+
+// PromptQuestion is one question from the static prompt catalog users pick their prompts from
+type PromptQuestion struct {
+	ID       string `json:"id"`
+	Text     string `json:"text"`
+	Category string `json:"category"` // about_me, getting_personal, date_vibes, my_type
+}
+
+// ProfilePrompt is a prompt the user answered on their profile; the question text is copied so
+// cards render without a catalog lookup
+type ProfilePrompt struct {
+	PromptID string `dynamodbav:"promptId" json:"promptId"`
+	Question string `dynamodbav:"question" json:"question"`
+	Answer   string `dynamodbav:"answer" json:"answer"`
+}
+
+// ProfilePromptAnswer is one entry of a PUT /profile/prompts request
+type ProfilePromptAnswer struct {
+	PromptID string `json:"promptId"`
+	Answer   string `json:"answer"`
+}
+
+// MaxAudioPromptSeconds caps the length of a voice answer
+const MaxAudioPromptSeconds = 30
+
+// AudioPrompt is a short voice answer to one catalog prompt
+type AudioPrompt struct {
+	PromptID        string  `dynamodbav:"promptId" json:"promptId"`
+	Question        string  `dynamodbav:"question" json:"question"`
+	Key             string  `dynamodbav:"key" json:"key"` // S3 key of the recording
+	DurationSeconds float64 `dynamodbav:"durationSeconds" json:"durationSeconds"`
+}
@@ -0,0 +1,26 @@
+package models
+
+// ProfileViewsTable stores one row per viewer per viewed profile per day
+// PK: viewedHandle, SK: "<YYYY-MM-DD>#<viewerHandle>" (the day prefix deduplicates repeat views)
+var ProfileViewsTable = "ProfileViews"
+
+// ProfileViewRetentionDays is how long view rows live before DynamoDB TTL removes them
+const ProfileViewRetentionDays = 30
+
+// ProfileView is a stored profile view event
+type ProfileView struct {
+	ViewedHandle string `dynamodbav:"viewedHandle" json:"viewedHandle"`
+	SK           string `dynamodbav:"SK" json:"-"`
+	ViewerHandle string `dynamodbav:"viewerHandle" json:"viewerHandle"`
+	ViewedAt     string `dynamodbav:"viewedAt" json:"viewedAt"`
+	ExpiresAt    int64  `dynamodbav:"expiresAt" json:"-"` // ✅ TTL attribute (epoch seconds)
+}
+
+// ProfileViewer is one entry in the "who viewed my profile" list
+type ProfileViewer struct {
+	UserHandle   string `json:"userHandle"`
+	Name         string `json:"name,omitempty"`
+	Photo        string `json:"photo,omitempty"`
+	LastViewedAt string `json:"lastViewedAt"`
+	ViewDays     int    `json:"viewDays"` // Distinct days this viewer opened the profile in the retention window
+}
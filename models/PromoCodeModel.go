@@ -0,0 +1,44 @@
+package models
+
+// PromoCodesTable holds admin-managed promo codes (PK: code, stored upper-case)
+var PromoCodesTable = "PromoCodes"
+
+// PromoRedemptionsTable records who redeemed which code (PK: code, SK: userhandle)
+var PromoRedemptionsTable = "PromoRedemptions"
+
+// ✅ What a promo code grants
+const (
+	GrantTypePremiumDays = "premium_days" // Premium entitlements for GrantDays days
+)
+
+// PromoCode is one campaign code created from the admin console
+type PromoCode struct {
+	Code           string `dynamodbav:"code" json:"code"`                                       // ✅ Partition Key
+	Campaign       string `dynamodbav:"campaign,omitempty" json:"campaign,omitempty"`           // Marketing campaign the code belongs to
+	GrantType      string `dynamodbav:"grantType" json:"grantType"`                             // One of the GrantType* constants
+	GrantDays      int    `dynamodbav:"grantDays" json:"grantDays"`                             // Length of the grant
+	MaxRedemptions int    `dynamodbav:"maxRedemptions" json:"maxRedemptions"`                   // Total uses allowed (0 = unlimited)
+	Redemptions    int    `dynamodbav:"redemptions" json:"redemptions"`                         // Uses so far
+	ExpiresAt      string `dynamodbav:"expiresAt,omitempty" json:"expiresAt,omitempty"`         // RFC3339 (UTC); empty = never
+	Active         bool   `dynamodbav:"active" json:"active"`                                   // Deactivated codes can't be redeemed
+	CreatedBy      string `dynamodbav:"createdBy" json:"createdBy"`                             // Admin who created the code
+	CreatedAt      string `dynamodbav:"createdAt" json:"createdAt"`                             // Timestamp of creation
+	DeactivatedAt  string `dynamodbav:"deactivatedAt,omitempty" json:"deactivatedAt,omitempty"` // Set when an admin turns the code off
+}
+
+// PromoRedemption records a single user's use of a code
+type PromoRedemption struct {
+	Code       string `dynamodbav:"code" json:"code"`             // ✅ Partition Key
+	UserHandle string `dynamodbav:"userhandle" json:"userhandle"` // ✅ Sort Key (one redemption per user)
+	GrantType  string `dynamodbav:"grantType" json:"grantType"`
+	GrantDays  int    `dynamodbav:"grantDays" json:"grantDays"`
+	RedeemedAt string `dynamodbav:"redeemedAt" json:"redeemedAt"`
+}
+
+// PromoRedemptionResult is returned to the user after a successful redemption
+type PromoRedemptionResult struct {
+	Code         string       `json:"code"`
+	GrantType    string       `json:"grantType"`
+	GrantDays    int          `json:"grantDays"`
+	Subscription Subscription `json:"subscription"`
+}
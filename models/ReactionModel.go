@@ -0,0 +1,85 @@
+package models
+
+import (
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+// LegacyLikeReaction is the reaction that group message likes from before reactions are read as
+const LegacyLikeReaction = "❤️"
+
+// ✅ Reaction limits
+const (
+	MaxReactionBytes       = 32 // One emoji, including modifiers and ZWJ sequences
+	MaxReactionsPerMessage = 20 // Distinct emoji on one message
+)
+
+// GroupReaction is one emoji on a group message and who reacted with it
+type GroupReaction struct {
+	Emoji    string   `json:"emoji"`
+	Count    int      `json:"count"`
+	Reactors []string `json:"reactors"` // Sorted
+}
+
+// IsValidReaction reports whether value looks like a single emoji: short, free of spaces and
+// control characters, and not plain ASCII text
+func IsValidReaction(value string) bool {
+	if value == "" || len(value) > MaxReactionBytes || !utf8.ValidString(value) {
+		return false
+	}
+	nonASCII := false
+	for _, r := range value {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return false
+		}
+		if r > unicode.MaxASCII {
+			nonASCII = true
+		}
+	}
+	return nonASCII
+}
+
+// SummarizeReactions fills Reactions from the stored sets (most used first), counting legacy likes
+// as LegacyLikeReaction, and mirrors that reaction into Likes and LikeCount for older clients
+func (m *GroupMessage) SummarizeReactions() {
+	reactors := map[string]map[string]bool{}
+	add := func(emoji, handle string) {
+		if reactors[emoji] == nil {
+			reactors[emoji] = map[string]bool{}
+		}
+		reactors[emoji][handle] = true
+	}
+	for emoji, handles := range m.ReactionSets {
+		for _, handle := range handles {
+			add(emoji, handle)
+		}
+	}
+	for handle, liked := range m.Likes {
+		if liked {
+			add(LegacyLikeReaction, handle)
+		}
+	}
+
+	m.Reactions = make([]GroupReaction, 0, len(reactors))
+	for emoji, set := range reactors {
+		reaction := GroupReaction{Emoji: emoji, Count: len(set), Reactors: make([]string, 0, len(set))}
+		for handle := range set {
+			reaction.Reactors = append(reaction.Reactors, handle)
+		}
+		sort.Strings(reaction.Reactors)
+		m.Reactions = append(m.Reactions, reaction)
+	}
+	sort.Slice(m.Reactions, func(i, j int) bool {
+		if m.Reactions[i].Count != m.Reactions[j].Count {
+			return m.Reactions[i].Count > m.Reactions[j].Count
+		}
+		return m.Reactions[i].Emoji < m.Reactions[j].Emoji
+	})
+
+	m.Likes = make(map[string]bool, len(reactors[LegacyLikeReaction]))
+	for handle := range reactors[LegacyLikeReaction] {
+		m.Likes[handle] = true
+	}
+	m.LikeCount = len(m.Likes)
+}
@@ -0,0 +1,109 @@
+package models
+
+import "fmt"
+
+// ReportsTable holds user reports with their evidence until trust & safety handles them
+// PK: reportId; GSI status-queueKey-index lists the queue most severe first, then oldest first
+var ReportsTable = "Reports"
+
+// ReportQueueIndex is the GSI (PK status, SK queueKey) the moderation queue is read from
+const ReportQueueIndex = "status-queueKey-index"
+
+// ✅ Report categories
+const (
+	ReportCategoryHarassment      = "harassment"
+	ReportCategoryFakeProfile     = "fake_profile"
+	ReportCategoryUnderage        = "underage"
+	ReportCategoryScam            = "scam"
+	ReportCategorySexualContent   = "sexual_content"
+	ReportCategoryHateSpeech      = "hate_speech"
+	ReportCategorySpam            = "spam"
+	ReportCategoryOfflineBehavior = "offline_behavior" // Something that happened on a date
+	ReportCategoryOther           = "other"
+)
+
+// ReportCategories lists the categories reporters choose from, in display order
+var ReportCategories = []string{
+	ReportCategoryHarassment, ReportCategoryFakeProfile, ReportCategoryUnderage, ReportCategoryScam, ReportCategorySexualContent,
+	ReportCategoryHateSpeech, ReportCategorySpam, ReportCategoryOfflineBehavior, ReportCategoryOther,
+}
+
+// ✅ Report severities, least to most urgent
+const (
+	ReportSeverityLow      = "low"
+	ReportSeverityMedium   = "medium"
+	ReportSeverityHigh     = "high"
+	ReportSeverityCritical = "critical"
+)
+
+// ReportCategorySeverity is the severity each category is triaged at
+var ReportCategorySeverity = map[string]string{
+	ReportCategoryHarassment:      ReportSeverityHigh,
+	ReportCategoryFakeProfile:     ReportSeverityMedium,
+	ReportCategoryUnderage:        ReportSeverityCritical,
+	ReportCategoryScam:            ReportSeverityHigh,
+	ReportCategorySexualContent:   ReportSeverityHigh,
+	ReportCategoryHateSpeech:      ReportSeverityHigh,
+	ReportCategorySpam:            ReportSeverityLow,
+	ReportCategoryOfflineBehavior: ReportSeverityCritical,
+	ReportCategoryOther:           ReportSeverityLow,
+}
+
+// reportSeverityRank orders severities for the queue
+var reportSeverityRank = map[string]int{
+	ReportSeverityLow:      1,
+	ReportSeverityMedium:   2,
+	ReportSeverityHigh:     3,
+	ReportSeverityCritical: 4,
+}
+
+// ✅ Report states
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusActioned  = "actioned"  // Trust & safety acted on the reported user
+	ReportStatusDismissed = "dismissed" // No violation found
+)
+
+// ✅ Report limits
+const (
+	MaxReportDetailsLength   = 500
+	MaxReportMessageIDs      = 20
+	MaxReportScreenshots     = 5
+	ReportScreenshotMaxBytes = 10 << 20
+)
+
+// ReportRequest is what a reporter submits: a category, optional details and optional evidence
+type ReportRequest struct {
+	UserHandle     string   `json:"userhandle"`
+	ReportedHandle string   `json:"reportedHandle"`
+	Category       string   `json:"category"`
+	Details        string   `json:"details,omitempty"`
+	MatchID        string   `json:"matchId,omitempty"`        // Required with MessageIDs
+	MessageIDs     []string `json:"messageIds,omitempty"`     // Messages the reported user sent in MatchID
+	ScreenshotKeys []string `json:"screenshotKeys,omitempty"` // Keys the reporter uploaded through the presigned upload flow
+}
+
+// Report is one stored report
+type Report struct {
+	ReportID       string   `dynamodbav:"reportId" json:"reportId"`
+	ReporterHandle string   `dynamodbav:"reporterHandle" json:"reporterHandle"`
+	ReportedHandle string   `dynamodbav:"reportedHandle" json:"reportedHandle"`
+	Category       string   `dynamodbav:"category" json:"category"`
+	Severity       string   `dynamodbav:"severity" json:"severity"`
+	Details        string   `dynamodbav:"details,omitempty" json:"details,omitempty"`
+	MatchID        string   `dynamodbav:"matchId,omitempty" json:"matchId,omitempty"`
+	MessageIDs     []string `dynamodbav:"messageIds,omitempty" json:"messageIds,omitempty"`
+	ScreenshotKeys []string `dynamodbav:"screenshotKeys,omitempty" json:"screenshotKeys,omitempty"`
+	Status         string   `dynamodbav:"status" json:"status"`
+	QueueKey       string   `dynamodbav:"queueKey" json:"-"`
+	CreatedAt      string   `dynamodbav:"createdAt" json:"createdAt"`
+	ReviewedAt     string   `dynamodbav:"reviewedAt,omitempty" json:"reviewedAt,omitempty"`
+	ReviewedBy     string   `dynamodbav:"reviewedBy,omitempty" json:"reviewedBy,omitempty"`
+
+	ScreenshotURLs []string `dynamodbav:"-" json:"screenshotUrls,omitempty"` // ✅ Presigned for the admin queue
+}
+
+// ReportQueueKey sorts the queue by severity (most severe first), then by age (oldest first)
+func ReportQueueKey(severity, createdAt string) string {
+	return fmt.Sprintf("%d#%s", len(reportSeverityRank)-reportSeverityRank[severity], createdAt)
+}
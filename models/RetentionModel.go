@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// RetentionPolicy sets how long data no longer in use is kept before DynamoDB TTL removes it.
+// The tables' TTL attribute must be expiresAt; a zero period keeps the data indefinitely.
+type RetentionPolicy struct {
+	DeclinedInteractionDays int // Declined and rejected interactions, counted from the decline
+	UnmatchedMessageDays    int // Messages of a conversation, counted from the unmatch
+}
+
+// ExpiresAt is the TTL value (epoch seconds) for data kept the given number of days from now,
+// or 0 when the period is disabled
+func (RetentionPolicy) ExpiresAt(now time.Time, days int) int64 {
+	if days <= 0 {
+		return 0
+	}
+	return now.AddDate(0, 0, days).Unix()
+}
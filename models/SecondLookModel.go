@@ -0,0 +1,7 @@
+package models
+
+// ✅ "Second look": received likes the user never acted on, resurfaced after a while
+const (
+	DefaultSecondLookDays = 7  // Likes at least this old are resurfaced unless ?olderThanDays= says otherwise
+	MaxSecondLookDays     = 90 // Largest olderThanDays accepted
+)
@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// SessionsTable holds one row per signed-in device
+// PK: sessionId; GSI userhandle-lastSeenAt-index lists a user's devices, most recently used first.
+// Rows expire SessionIdleDays after the device was last seen.
+var SessionsTable = "Sessions"
+
+// SessionUserIndex is the GSI (PK userhandle, SK lastSeenAt) a user's devices are listed from
+const SessionUserIndex = "userhandle-lastSeenAt-index"
+
+// SessionIdleDays is how long an unused session stays valid
+const SessionIdleDays = 90
+
+// SessionTouchInterval throttles lastSeenAt writes: a session is touched at most this often unless
+// its IP changes
+const SessionTouchInterval = 5 * time.Minute
+
+// ✅ Client platforms a session can be started from
+const (
+	SessionPlatformIOS     = "ios"
+	SessionPlatformAndroid = "android"
+	SessionPlatformWeb     = "web"
+)
+
+// SessionPlatforms lists the accepted platforms
+var SessionPlatforms = []string{SessionPlatformIOS, SessionPlatformAndroid, SessionPlatformWeb}
+
+// MaxSessionDeviceFieldLength caps the free-form device fields (model, OS and app version)
+const MaxSessionDeviceFieldLength = 100
+
+// Session is a signed-in device
+type Session struct {
+	SessionID   string `dynamodbav:"sessionId" json:"sessionId"`
+	UserHandle  string `dynamodbav:"userhandle" json:"userhandle"`
+	TokenHash   string `dynamodbav:"tokenHash" json:"-"` // SHA-256 (hex) of the token's secret; the token itself is never stored
+	Platform    string `dynamodbav:"platform" json:"platform"`
+	DeviceModel string `dynamodbav:"deviceModel,omitempty" json:"deviceModel,omitempty"` // e.g. "iPhone15,2"
+	OSVersion   string `dynamodbav:"osVersion,omitempty" json:"osVersion,omitempty"`
+	AppVersion  string `dynamodbav:"appVersion,omitempty" json:"appVersion,omitempty"`
+	CreatedAt   string `dynamodbav:"createdAt" json:"createdAt"`
+	LastSeenAt  string `dynamodbav:"lastSeenAt" json:"lastSeenAt"`
+	LastIP      string `dynamodbav:"lastIp" json:"lastIp"`
+	Country     string `dynamodbav:"country,omitempty" json:"country,omitempty"` // ISO country the session started from, when the edge reports it
+	DeviceID    string `dynamodbav:"deviceId,omitempty" json:"-"`                // Client-generated install ID; never returned, so it can't be copied from the device list
+	ExpiresAt   int64  `dynamodbav:"expiresAt" json:"-"`                         // TTL attribute (epoch seconds), pushed back on every touch
+	Current     bool   `dynamodbav:"-" json:"current,omitempty"`                 // The session the list was requested with (not stored in DB)
+
+	// ✅ Sign-in verification: set while the session waits for its code (no token yet)
+	Pending      bool   `dynamodbav:"pending,omitempty" json:"-"`
+	CodeHash     string `dynamodbav:"codeHash,omitempty" json:"-"`
+	CodeAttempts int    `dynamodbav:"codeAttempts,omitempty" json:"-"`
+}
+
+// SessionRequest starts a session on a device
+type SessionRequest struct {
+	UserHandle  string `json:"userhandle"`
+	Platform    string `json:"platform"`
+	DeviceModel string `json:"deviceModel,omitempty"`
+	OSVersion   string `json:"osVersion,omitempty"`
+	AppVersion  string `json:"appVersion,omitempty"`
+	DeviceID    string `json:"deviceId,omitempty"` // Stable per install; sign-ins from unknown devices may need step-up verification
+}
+
+// NewSession is returned once, when the session starts; its token can't be shown again. Sign-in
+// returns it with StepUp set and no token yet: the token comes from POST /sessions/{sessionId}/verify.
+type NewSession struct {
+	Session
+	Token    string `json:"token,omitempty"`    // Sent as "Authorization: Bearer <token>"
+	StepUp   string `json:"stepUp,omitempty"`   // StepUpEmail or StepUpSMS: where the code was sent
+	VerifyBy string `json:"verifyBy,omitempty"` // When the code expires (RFC3339)
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// ShownProfilesTable remembers which profiles each user's suggestion feed served recently
+// PK: userhandle, SK: shownHandle
+var ShownProfilesTable = "ShownProfiles"
+
+// ShownProfileRetention is how long a served profile is remembered before DynamoDB TTL removes it
+const ShownProfileRetention = 24 * time.Hour
+
+// ShownProfile records that a suggestion batch served shownHandle to the user
+type ShownProfile struct {
+	UserHandle  string `dynamodbav:"userhandle" json:"userhandle"`
+	ShownHandle string `dynamodbav:"shownHandle" json:"shownHandle"`
+	ServedAt    int64  `dynamodbav:"servedAt" json:"servedAt"` // Unix milliseconds; every profile of a batch shares it
+	ExpiresAt   int64  `dynamodbav:"expiresAt" json:"-"`       // ✅ TTL attribute (epoch seconds)
+}
@@ -0,0 +1,84 @@
+package models
+
+// SingleTable is the consolidated DynamoDB table that holds interactions, matches,
+// messages and group records under one PK/SK layout:
+//
+//	PK              SK                              Entity
+//	USER#<handle>   INTERACTION#<receiver>          interaction sent by <handle>
+//	USER#<handle>   MATCH#<matchId>                 match membership for <handle>
+//	USER#<handle>   GROUP_INVITE#<invitee>          group invite created by <handle>
+//	USER#<handle>   GROUP#<groupId>                 group membership for <handle>
+//	MATCH#<matchId> META                            match summary (participants)
+//	MATCH#<matchId> MSG#<createdAt>#<messageId>     1:1 chat message
+//	GROUP#<groupId> META                            group summary (members, name)
+//	GROUP#<groupId> MSG#<createdAt>#<messageId>     group chat message
+//
+// "Everything for user X" is a single Query on PK = USER#X and "everything for
+// match Y" is a single Query on PK = MATCH#Y. GSI1 inverts the relationship
+// (e.g. interactions received by a user, invites awaiting an approver).
+var SingleTable = "VibinData"
+
+// SingleTableGSI1 is the inverted index on GSI1PK/GSI1SK
+const SingleTableGSI1 = "GSI1"
+
+// ✅ Key prefixes for the consolidated layout
+const (
+	KeyPrefixUser        = "USER#"
+	KeyPrefixMatch       = "MATCH#"
+	KeyPrefixGroup       = "GROUP#"
+	KeyPrefixInteraction = "INTERACTION#"
+	KeyPrefixGroupInvite = "GROUP_INVITE#"
+	KeyPrefixMessage     = "MSG#"
+	KeyMeta              = "META"
+)
+
+// ✅ Entity types stored in the `entityType` attribute
+const (
+	EntityInteraction = "interaction"
+	EntityMatch       = "match"
+	EntityMatchMember = "match_member"
+	EntityMessage     = "message"
+	EntityGroupInvite = "group_invite"
+	EntityGroupMember = "group_member"
+	EntityGroup       = "group"
+	EntityGroupMsg    = "group_message"
+)
+
+// SingleTableKeys holds the key attributes every item in the consolidated table carries
+type SingleTableKeys struct {
+	PK         string `dynamodbav:"PK" json:"PK"`
+	SK         string `dynamodbav:"SK" json:"SK"`
+	GSI1PK     string `dynamodbav:"GSI1PK,omitempty" json:"GSI1PK,omitempty"`
+	GSI1SK     string `dynamodbav:"GSI1SK,omitempty" json:"GSI1SK,omitempty"`
+	EntityType string `dynamodbav:"entityType" json:"entityType"`
+}
+
+// MatchSummary is the META record stored under MATCH#<matchId>
+type MatchSummary struct {
+	MatchID      string   `dynamodbav:"matchId" json:"matchId"`
+	Participants []string `dynamodbav:"participants" json:"participants"`
+	CreatedAt    string   `dynamodbav:"createdAt" json:"createdAt"`
+}
+
+// ItemCollection is the result of a single-partition query on the consolidated table
+type ItemCollection struct {
+	PK    string                   `json:"PK"`
+	Items []map[string]interface{} `json:"items"`
+}
+
+// ✅ Key builders
+
+func UserPK(userHandle string) string { return KeyPrefixUser + userHandle }
+
+func MatchPK(matchID string) string { return KeyPrefixMatch + matchID }
+
+func GroupPK(groupID string) string { return KeyPrefixGroup + groupID }
+
+func InteractionSK(receiver string) string { return KeyPrefixInteraction + receiver }
+
+func GroupInviteSK(invitee string) string { return KeyPrefixGroupInvite + invitee }
+
+// MessageSK keeps messages ordered by time while staying unique per message
+func MessageSK(createdAt, messageID string) string {
+	return KeyPrefixMessage + createdAt + "#" + messageID
+}
@@ -0,0 +1,25 @@
+package models
+
+// SpotifyProfile is the music taste shown on a profile, copied from Spotify when the user connects
+type SpotifyProfile struct {
+	TopArtists  []SpotifyArtist `dynamodbav:"topArtists" json:"topArtists"`
+	Anthem      *SpotifyTrack   `dynamodbav:"anthem,omitempty" json:"anthem,omitempty"` // The user's top track
+	ConnectedAt string          `dynamodbav:"connectedAt" json:"connectedAt"`
+}
+
+// SpotifyArtist is one of the user's top artists
+type SpotifyArtist struct {
+	ID       string   `dynamodbav:"id" json:"id"`
+	Name     string   `dynamodbav:"name" json:"name"`
+	ImageURL string   `dynamodbav:"imageUrl,omitempty" json:"imageUrl,omitempty"`
+	Genres   []string `dynamodbav:"genres,omitempty" json:"genres,omitempty"`
+}
+
+// SpotifyTrack is a track shown as the user's anthem
+type SpotifyTrack struct {
+	ID         string   `dynamodbav:"id" json:"id"`
+	Name       string   `dynamodbav:"name" json:"name"`
+	Artists    []string `dynamodbav:"artists" json:"artists"`
+	ImageURL   string   `dynamodbav:"imageUrl,omitempty" json:"imageUrl,omitempty"`
+	PreviewURL string   `dynamodbav:"previewUrl,omitempty" json:"previewUrl,omitempty"` // 30s clip; not every track has one
+}
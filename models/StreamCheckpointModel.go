@@ -0,0 +1,14 @@
+package models
+
+// StreamCheckpointsTable tracks DynamoDB Streams shard progress and which instance holds each shard
+// PK: "shardKey" ("<table>#<shardId>")
+var StreamCheckpointsTable = "StreamCheckpoints"
+
+// StreamCheckpoint is the lease and progress of one stream shard
+type StreamCheckpoint struct {
+	ShardKey       string `dynamodbav:"shardKey" json:"shardKey"`                                 // ✅ Partition Key
+	SequenceNumber string `dynamodbav:"sequenceNumber,omitempty" json:"sequenceNumber,omitempty"` // Last record fully processed
+	Finished       bool   `dynamodbav:"finished" json:"finished"`                                 // Shard closed and drained; children may start
+	LeaseOwner     string `dynamodbav:"leaseOwner,omitempty" json:"leaseOwner,omitempty"`         // Instance currently reading the shard
+	LeaseExpiresAt int64  `dynamodbav:"leaseExpiresAt" json:"leaseExpiresAt"`                     // Epoch seconds; others may take over after this
+}
@@ -0,0 +1,95 @@
+package models
+
+import "time"
+
+// ✅ Plans
+const (
+	PlanFree    = "free"
+	PlanPremium = "premium"
+)
+
+// ✅ Entitlements unlocked by a plan
+const (
+	EntitlementSeeWhoLikedYou = "see_who_liked_you" // Received likes show the sender's profile
+	EntitlementUnlimitedLikes = "unlimited_likes"   // No daily like cap
+	EntitlementRewind         = "rewind"            // Undo the last dislike
+	EntitlementPassport       = "passport"          // Browse suggestions from another city
+)
+
+// FreeDailyLikeLimit is how many likes a user without EntitlementUnlimitedLikes may send per UTC day
+const FreeDailyLikeLimit = 50
+
+// PlanEntitlements lists what each plan unlocks
+func PlanEntitlements(plan string) []string {
+	if plan == PlanPremium {
+		return []string{EntitlementSeeWhoLikedYou, EntitlementUnlimitedLikes, EntitlementRewind, EntitlementPassport}
+	}
+	return []string{}
+}
+
+// Subscription is the billing state stored on the user profile, kept in sync by Stripe webhooks
+type Subscription struct {
+	Plan                 string   `dynamodbav:"plan" json:"plan"`                                               // "free" or "premium"
+	Status               string   `dynamodbav:"status" json:"status"`                                           // Stripe subscription status (active, trialing, past_due, canceled, ...)
+	Entitlements         []string `dynamodbav:"entitlements" json:"entitlements"`                               // Features unlocked while the subscription is in good standing
+	StripeCustomerID     string   `dynamodbav:"stripeCustomerId,omitempty" json:"-"`                            // Reused for later checkouts
+	StripeSubscriptionID string   `dynamodbav:"stripeSubscriptionId,omitempty" json:"-"`                        // Stripe subscription backing the plan
+	CurrentPeriodEnd     string   `dynamodbav:"currentPeriodEnd,omitempty" json:"currentPeriodEnd,omitempty"`   // End of the paid period (RFC3339)
+	CancelAtPeriodEnd    bool     `dynamodbav:"cancelAtPeriodEnd,omitempty" json:"cancelAtPeriodEnd,omitempty"` // Set when the user cancelled but the period hasn't ended
+	LastEventCreated     int64    `dynamodbav:"lastEventCreated,omitempty" json:"-"`                            // Stripe event time; older events are ignored
+	PromoPremiumUntil    string   `dynamodbav:"promoPremiumUntil,omitempty" json:"promoPremiumUntil,omitempty"` // Premium granted by a promo code until this time (RFC3339)
+	UpdatedAt            string   `dynamodbav:"updatedAt" json:"updatedAt"`                                     // When the webhook last changed this record
+}
+
+// FreeSubscription is the state of users who never subscribed
+func FreeSubscription() Subscription {
+	return Subscription{Plan: PlanFree, Status: "none", Entitlements: PlanEntitlements(PlanFree)}
+}
+
+// IsActive reports whether the subscription is paid up and within its period
+func (s Subscription) IsActive() bool {
+	if s.Status != "active" && s.Status != "trialing" {
+		return false
+	}
+	if s.CurrentPeriodEnd == "" {
+		return true // ✅ Checkout completed; the period arrives with the subscription event
+	}
+	periodEnd, err := time.Parse(time.RFC3339, s.CurrentPeriodEnd)
+	return err == nil && time.Now().Before(periodEnd) // ✅ A corrupt period end is not a paid-up one
+}
+
+// HasPromoPremium reports whether a promo code grant is still running
+func (s Subscription) HasPromoPremium() bool {
+	if s.PromoPremiumUntil == "" {
+		return false
+	}
+	until, err := time.Parse(time.RFC3339, s.PromoPremiumUntil)
+	return err == nil && time.Now().Before(until)
+}
+
+// Has reports whether the subscription or a running promo grant currently grants the entitlement
+func (s Subscription) Has(entitlement string) bool {
+	entitlements := s.Entitlements
+	switch {
+	case s.HasPromoPremium():
+		entitlements = PlanEntitlements(PlanPremium)
+	case !s.IsActive():
+		return false
+	default:
+		entitlements = append(PlanEntitlements(s.Plan), entitlements...) // ✅ Includes entitlements added to the plan since the last webhook
+	}
+	for _, e := range entitlements {
+		if e == entitlement {
+			return true
+		}
+	}
+	return false
+}
+
+// EffectiveSubscription returns the stored subscription, or the free plan if there is none
+func (p *UserProfile) EffectiveSubscription() Subscription {
+	if p.Subscription == nil {
+		return FreeSubscription()
+	}
+	return *p.Subscription
+}
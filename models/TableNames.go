@@ -0,0 +1,55 @@
+package models
+
+// tableNames lists every DynamoDB table variable so the environment prefix is applied in one place
+var tableNames = []*string{
+	&UserProfilesTable,
+	&InteractionsTable,
+	&MessagesTable,
+	&GroupInteractionsTable,
+	&GroupMessageTable,
+	&SingleTable,
+	&ModerationRulesTable,
+	&ConversationKeysTable,
+	&ConversationSettingsTable,
+	&PhotoInsightsTable,
+	&ProfileViewsTable,
+	&PromoCodesTable,
+	&PromoRedemptionsTable,
+	&AnalyticsEventsTable,
+	&FeatureFlagsTable,
+	&WebhooksTable,
+	&ConversationSummariesTable,
+	&StreamCheckpointsTable,
+	&PhotoReviewsTable,
+	&MessageReviewsTable,
+	&AgeVerificationsTable,
+	&BlocksTable,
+	&ContactsTable,
+	&ShownProfilesTable,
+	&QuotaCountersTable,
+	&InteractionAuditTable,
+	&GroupPresenceTable,
+	&KeyBundlesTable,
+	&OneTimePreKeysTable,
+	&ConversationExportsTable,
+	&CallLogTable,
+	&DateCheckInsTable,
+	&ReportsTable,
+	&AccountActionsTable,
+	&SessionsTable,
+	&LoginAuditTable,
+	&NetworkBlocksTable,
+	&ConversationOpenersTable,
+	&JobReceiptsTable,
+}
+
+// ApplyTablePrefix prefixes every table name (e.g. "staging-Users"); call once at startup, before
+// any service touches DynamoDB
+func ApplyTablePrefix(prefix string) {
+	if prefix == "" {
+		return
+	}
+	for _, name := range tableNames {
+		*name = prefix + *name
+	}
+}
@@ -0,0 +1,20 @@
+package models
+
+// UnreadCounts reports how many unread messages a user has, per conversation and in aggregate
+type UnreadCounts struct {
+	Conversations       map[string]int `json:"conversations"`       // matchId or groupId → unread messages
+	UnreadConversations int            `json:"unreadConversations"` // Conversations with at least one unread message
+	Total               int            `json:"total"`               // Unread messages across all conversations
+}
+
+// Add records the unread count for one conversation
+func (u *UnreadCounts) Add(conversationID string, unread int) {
+	if u.Conversations == nil {
+		u.Conversations = map[string]int{}
+	}
+	u.Conversations[conversationID] = unread
+	u.Total += unread
+	if unread > 0 {
+		u.UnreadConversations++
+	}
+}
@@ -0,0 +1,42 @@
+package models
+
+import "strings"
+
+// ImageUploadTypes are the image content types clients may upload, with the extensions allowed for each.
+// Only formats the rendition generator can decode are listed.
+var ImageUploadTypes = map[string][]string{
+	"image/jpeg": {".jpg", ".jpeg"},
+	"image/png":  {".png"},
+	"image/webp": {".webp"},
+}
+
+// VideoUploadTypes are the video content types clients may upload, with the extensions allowed for each
+var VideoUploadTypes = map[string][]string{
+	"video/mp4":       {".mp4"},
+	"video/quicktime": {".mov"},
+	"video/webm":      {".webm"},
+}
+
+// AudioUploadTypes are the audio content types clients may upload (voice answers to prompts)
+var AudioUploadTypes = map[string][]string{
+	"audio/mp4":  {".m4a"},
+	"audio/aac":  {".aac"},
+	"audio/mpeg": {".mp3"},
+	"audio/ogg":  {".ogg", ".opus"},
+}
+
+// MaxImageUploadBytes caps a single presigned image upload (matches what rendition generation accepts)
+const MaxImageUploadBytes = 20 << 20
+
+// MaxAudioUploadBytes caps a voice prompt upload; 2MB is ample for MaxAudioPromptSeconds of compressed speech
+const MaxAudioUploadBytes = 2 << 20
+
+// UserUploadPrefix is the key prefix every upload made for userHandle is stored under
+func UserUploadPrefix(userHandle string) string {
+	return "users/" + userHandle + "/"
+}
+
+// OwnsUploadKey reports whether key was issued to userHandle
+func OwnsUploadKey(userHandle, key string) bool {
+	return strings.HasPrefix(key, UserUploadPrefix(userHandle))
+}
@@ -2,28 +2,106 @@ package models
 
 // UserProfile defines the structure for user profiles
 type UserProfile struct {
-	UserHandle          string            `dynamodbav:"userhandle" json:"userhandle"`                                       // ✅ Partition Key
-	EmailID             string            `dynamodbav:"emailId,omitempty" json:"emailId,omitempty"`                         // Indexed via GSI
-	EmailIDVerified     bool              `dynamodbav:"emailIdVerified,omitempty" json:"emailIdVerified,omitempty"`         // Email verification status
-	PhoneNumber         string            `dynamodbav:"phoneNumber,omitempty" json:"phoneNumber,omitempty"`                 // User's phone number
-	Name                string            `dynamodbav:"name,omitempty" json:"name,omitempty"`                               // Full name of the user
-	UserName            string            `dynamodbav:"username,omitempty" json:"username,omitempty"`                       // Display name
-	HideName            bool              `dynamodbav:"hideName,omitempty" json:"hideName,omitempty"`                       // Flag to hide real name on profile
-	Bio                 string            `dynamodbav:"bio,omitempty" json:"bio,omitempty"`                                 // Short biography
-	Desires             []string          `dynamodbav:"desires,omitempty" json:"desires,omitempty"`                         // User's desires
-	DOB                 string            `dynamodbav:"dob,omitempty" json:"dob,omitempty"`                                 // Date of Birth
-	Age                 int               `dynamodbav:"age,omitempty" json:"age,omitempty"`                                 // Calculated age
-	Gender              string            `dynamodbav:"gender,omitempty" json:"gender,omitempty"`                           // Gender
-	Interests           []string          `dynamodbav:"interests,omitempty" json:"interests,omitempty"`                     // User's interests
-	Latitude            float64           `dynamodbav:"latitude,omitempty" json:"latitude,omitempty"`                       // Latitude of the user's location
-	Longitude           float64           `dynamodbav:"longitude,omitempty" json:"longitude,omitempty"`                     // Longitude of the user's location
-	LookingFor          string            `dynamodbav:"lookingFor,omitempty" json:"lookingFor,omitempty"`                   // What the user is looking for
-	Orientation         string            `dynamodbav:"orientation,omitempty" json:"orientation,omitempty"`                 // User's orientation
-	ShowGenderOnProfile bool              `dynamodbav:"showGenderOnProfile,omitempty" json:"showGenderOnProfile,omitempty"` // Show gender on profile or not
-	Photos              []string          `dynamodbav:"photos,omitempty" json:"photos,omitempty"`                           // User photos
-	DistanceBetween     float64           `json:"distanceBetween" dynamodbav:"-"`                                           // Computed distance (not stored in DB)
-	Questionnaire       map[string]string `dynamodbav:"questionnaire,omitempty" json:"questionnaire,omitempty"`             // Questionnaire responses
+	UserHandle          string              `dynamodbav:"userhandle" json:"userhandle"`                                       // ✅ Partition Key
+	EmailID             string              `dynamodbav:"emailId,omitempty" json:"emailId,omitempty"`                         // Indexed via GSI
+	EmailIDVerified     bool                `dynamodbav:"emailIdVerified,omitempty" json:"emailIdVerified,omitempty"`         // Email verification status
+	PhoneNumber         string              `dynamodbav:"phoneNumber,omitempty" json:"phoneNumber,omitempty"`                 // User's phone number
+	Name                string              `dynamodbav:"name,omitempty" json:"name,omitempty"`                               // Full name of the user
+	UserName            string              `dynamodbav:"username,omitempty" json:"username,omitempty"`                       // Display name
+	HideName            bool                `dynamodbav:"hideName,omitempty" json:"hideName,omitempty"`                       // Flag to hide real name on profile
+	Bio                 string              `dynamodbav:"bio,omitempty" json:"bio,omitempty"`                                 // Short biography
+	Desires             []string            `dynamodbav:"desires,omitempty" json:"desires,omitempty"`                         // User's desires
+	DOB                 string              `dynamodbav:"dob,omitempty" json:"dob,omitempty"`                                 // Date of Birth
+	Age                 int                 `dynamodbav:"age,omitempty" json:"age,omitempty"`                                 // Calculated age
+	Gender              string              `dynamodbav:"gender,omitempty" json:"gender,omitempty"`                           // Gender
+	Interests           []string            `dynamodbav:"interests,omitempty" json:"interests,omitempty"`                     // Display names of the interests (free-form on older profiles)
+	InterestIDs         []string            `dynamodbav:"interestIds,omitempty" json:"interestIds,omitempty"`                 // Catalog IDs of the interests (see GET /profile/interests/catalog)
+	Latitude            float64             `dynamodbav:"latitude,omitempty" json:"latitude,omitempty"`                       // Latitude of the user's location
+	Longitude           float64             `dynamodbav:"longitude,omitempty" json:"longitude,omitempty"`                     // Longitude of the user's location
+	Geohash             string              `dynamodbav:"geohash,omitempty" json:"geohash,omitempty"`                         // Discovery cell of the location (see GeohashPrecision)
+	LocationUpdatedAt   string              `dynamodbav:"locationUpdatedAt,omitempty" json:"locationUpdatedAt,omitempty"`     // When the location last moved
+	Place               *Place              `dynamodbav:"place,omitempty" json:"place,omitempty"`                             // City/region of the location (reverse geocoded)
+	Passport            *PassportLocation   `dynamodbav:"passport,omitempty" json:"passport,omitempty"`                       // Virtual location for suggestions (premium, expires)
+	Paused              bool                `dynamodbav:"paused,omitempty" json:"paused,omitempty"`                           // Hidden from discovery and pings (snooze)
+	PausedUntil         string              `dynamodbav:"pausedUntil,omitempty" json:"pausedUntil,omitempty"`                 // Auto-resume time (RFC3339); empty = until resumed
+	DeletedAt           string              `dynamodbav:"deletedAt,omitempty" json:"deletedAt,omitempty"`                     // Set while the account is deleted but restorable
+	PurgeAfter          string              `dynamodbav:"purgeAfter,omitempty" json:"purgeAfter,omitempty"`                   // When the sweeper erases the account
+	PendingDeletion     string              `dynamodbav:"pendingDeletion,omitempty" json:"-"`                                 // PendingDeletionIndex key; set with DeletedAt
+	AgeStatus           string              `dynamodbav:"ageStatus,omitempty" json:"ageStatus,omitempty"`                     // Age verification state while disputed or rejected (hidden from discovery)
+	AccountStatus       string              `dynamodbav:"accountStatus,omitempty" json:"accountStatus,omitempty"`             // Set while suspended or banned (read-only, hidden from discovery)
+	SuspendedUntil      string              `dynamodbav:"suspendedUntil,omitempty" json:"suspendedUntil,omitempty"`           // End of a suspension (RFC3339)
+	Shadowbanned        bool                `dynamodbav:"shadowbanned,omitempty" json:"-"`                                    // Likes/pings silently undelivered and never suggested (never shown to anyone)
+	Contacts            *ContactSettings    `dynamodbav:"contacts,omitempty" json:"contacts,omitempty"`                       // Contact sync modes (nil = never synced)
+	LookingFor          string              `dynamodbav:"lookingFor,omitempty" json:"lookingFor,omitempty"`                   // What the user is looking for
+	Orientation         string              `dynamodbav:"orientation,omitempty" json:"orientation,omitempty"`                 // User's orientation
+	ShowGenderOnProfile bool                `dynamodbav:"showGenderOnProfile,omitempty" json:"showGenderOnProfile,omitempty"` // Show gender on profile or not
+	Photos              []string            `dynamodbav:"photos,omitempty" json:"photos,omitempty"`                           // User photos
+	PhotoCaptions       map[string]string   `dynamodbav:"photoCaptions,omitempty" json:"photoCaptions,omitempty"`             // Captions keyed by photo
+	PhotoRenditions     PhotoRenditionsMap  `dynamodbav:"photoRenditions,omitempty" json:"photoRenditions,omitempty"`         // Resized copies of the photos
+	Videos              []string            `dynamodbav:"videos,omitempty" json:"videos,omitempty"`                           // S3 keys of profile videos
+	DistanceBetween     float64             `json:"distanceBetween" dynamodbav:"-"`                                           // Computed distance (not stored in DB)
+	MutualConnections   int                 `json:"mutualConnections,omitempty" dynamodbav:"-"`                               // Contacts shared with the viewer (both opted in; not stored in DB)
+	SharedInterests     []string            `json:"sharedInterests,omitempty" dynamodbav:"-"`                                 // Interest IDs shared with the viewer (not stored in DB)
+	Completeness        *CompletenessScore  `json:"completeness,omitempty" dynamodbav:"-"`                                    // Computed on fetch (not stored in DB)
+	Questionnaire       map[string]string   `dynamodbav:"questionnaire,omitempty" json:"questionnaire,omitempty"`             // Questionnaire responses
+	Prompts             []ProfilePrompt     `dynamodbav:"prompts,omitempty" json:"prompts,omitempty"`                         // Answered prompts shown on the card
+	AudioPrompt         *AudioPrompt        `dynamodbav:"audioPrompt,omitempty" json:"audioPrompt,omitempty"`                 // Voice answer to a prompt
+	Spotify             *SpotifyProfile     `dynamodbav:"spotify,omitempty" json:"spotify,omitempty"`                         // Top artists and anthem (nil = not connected)
+	AudioPromptURL      string              `json:"audioPromptUrl,omitempty" dynamodbav:"-"`                                  // Presigned read URL of the voice answer (not stored in DB)
+	Consents            *ProcessingConsents `dynamodbav:"consents,omitempty" json:"consents,omitempty"`                       // Processing the user allows (nil = defaults)
+	Subscription        *Subscription       `dynamodbav:"subscription,omitempty" json:"subscription,omitempty"`               // Billing plan and entitlements (nil = free)
+	ProfileVersion      int                 `dynamodbav:"profileVersion,omitempty" json:"profileVersion,omitempty"`           // Bumped on every profile update
+	CreatedAt           string              `dynamodbav:"createdAt,omitempty" json:"createdAt,omitempty"`                     // Account creation time (unset on legacy profiles)
+	UpdatedAt           string              `dynamodbav:"updatedAt,omitempty" json:"updatedAt,omitempty"`                     // Time of the last profile update
+	CachedAt            string              `json:"cachedAt,omitempty" dynamodbav:"-"`                                        // When this snapshot was read (suggestion cards)
+	StaleAfter          string              `json:"staleAfter,omitempty" dynamodbav:"-"`                                      // Clients should re-check the version after this
+
+	// ✅ PII protection: emailId/phoneNumber hold ciphertext when PIIEncrypted is set
+	EmailIDIndex     string `dynamodbav:"emailIdIndex,omitempty" json:"-"`     // Blind index (HMAC) of the normalized email
+	PhoneNumberIndex string `dynamodbav:"phoneNumberIndex,omitempty" json:"-"` // Blind index (HMAC) of the normalized phone
+	ContactIndex     string `dynamodbav:"contactIndex,omitempty" json:"-"`     // Keyed hash matching synced contacts (see PIIService.ContactIndex)
+	PIIEncrypted     bool   `dynamodbav:"piiEncrypted,omitempty" json:"-"`     // emailId/phoneNumber are encrypted
+	PIIKeyVersion    int    `dynamodbav:"piiKeyVersion,omitempty" json:"-"`    // Data key version used for PII
+
+	// ✅ City/region browsing: GSI keys derived from Place
+	CityKey   string `dynamodbav:"cityKey,omitempty" json:"-"`   // See CityKeyIndex
+	RegionKey string `dynamodbav:"regionKey,omitempty" json:"-"` // See RegionKeyIndex
+
+	// ✅ Photo moderation: flagged photos stay hidden from suggestions until an admin reviews them
+	QuarantinedPhotos []string `dynamodbav:"quarantinedPhotos,omitempty,stringset" json:"quarantinedPhotos,omitempty"`
+}
+
+// ProfileVersionInfo is the cheap freshness check for a cached profile snapshot
+type ProfileVersionInfo struct {
+	UserHandle     string `dynamodbav:"userhandle" json:"userhandle"`
+	ProfileVersion int    `dynamodbav:"profileVersion" json:"profileVersion"`
+	UpdatedAt      string `dynamodbav:"updatedAt" json:"updatedAt,omitempty"`
 }
 
 // UserProfilesTable is the DynamoDB table name for user profiles
-const UserProfilesTable = "Users"
+var UserProfilesTable = "Users"
+
+// ✅ GSIs used for profile lookups
+const (
+	EmailIndex            = "emailId-index"          // Legacy plaintext email lookups
+	EmailBlindIndex       = "emailIdIndex-index"     // Blind-index email lookups
+	PhoneNumberBlindIndex = "phoneNumberIndex-index" // Blind-index phone lookups
+)
+
+// ProfileUpdate edits the fields users manage themselves (PATCH /profile); nil fields are left as
+// they are and empty values clear the field. Photos, location, interests, prompts and everything the
+// server manages (moderation, billing, age verification) have their own routes.
+type ProfileUpdate struct {
+	Name                *string            `json:"name,omitempty"`
+	UserName            *string            `json:"username,omitempty"`
+	HideName            *bool              `json:"hideName,omitempty"`
+	Bio                 *string            `json:"bio,omitempty"`
+	DOB                 *string            `json:"dob,omitempty"` // Locked while the age is disputed or rejected
+	Desires             *[]string          `json:"desires,omitempty"`
+	Gender              *string            `json:"gender,omitempty"`
+	LookingFor          *string            `json:"lookingFor,omitempty"`
+	Orientation         *string            `json:"orientation,omitempty"`
+	ShowGenderOnProfile *bool              `json:"showGenderOnProfile,omitempty"`
+	Videos              *[]string          `json:"videos,omitempty"` // S3 keys uploaded for the user
+	Questionnaire       *map[string]string `json:"questionnaire,omitempty"`
+}
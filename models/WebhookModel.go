@@ -0,0 +1,42 @@
+package models
+
+// WebhooksTable holds outbound webhook subscriptions
+// PK: "webhookId"
+var WebhooksTable = "Webhooks"
+
+// ✅ Platform events delivered to webhooks
+const (
+	WebhookUserCreated    = "user.created"    // data: userhandle
+	WebhookMatchCreated   = "match.created"   // data: matchId, userhandles
+	WebhookMessageFlagged = "message.flagged" // data: senderHandle, matchId or groupId, reason
+	WebhookUserReported   = "user.reported"   // data: reporterHandle, reportedHandle, reportId, category (also as reason), severity
+
+	WebhookLikeSecondLook = "like.second_look" // data: senderHandle, receiverHandle (re-notify the sender)
+	WebhookMessageStatus  = "message.status"   // data: matchId, senderHandle, recipientHandle, status (delivered/read), messageIds
+
+	WebhookCheckInDue       = "safety.check_in_due" // data: userhandle, checkInId, prompt (1-based); ask the user to confirm they're safe
+	WebhookCheckInEscalated = "safety.escalated"    // data: userhandle, checkInId, matchId, partnerHandle, place
+)
+
+// WebhookEventTypes lists every event a webhook can subscribe to
+var WebhookEventTypes = []string{WebhookUserCreated, WebhookMatchCreated, WebhookMessageFlagged, WebhookUserReported, WebhookLikeSecondLook, WebhookMessageStatus, WebhookCheckInDue, WebhookCheckInEscalated}
+
+// Webhook is a registered endpoint and the events it receives
+type Webhook struct {
+	WebhookID   string   `dynamodbav:"webhookId" json:"webhookId"`                         // ✅ Partition Key
+	URL         string   `dynamodbav:"url" json:"url"`                                     // HTTPS endpoint that receives POSTs
+	Secret      string   `dynamodbav:"secret" json:"secret,omitempty"`                     // HMAC-SHA256 key; only returned when the webhook is created
+	Events      []string `dynamodbav:"events" json:"events"`                               // Subscribed event types
+	Description string   `dynamodbav:"description,omitempty" json:"description,omitempty"` // e.g. "Trust & safety queue"
+	Active      bool     `dynamodbav:"active" json:"active"`                               // Inactive webhooks receive nothing
+	CreatedBy   string   `dynamodbav:"createdBy" json:"createdBy"`                         // Admin who registered it
+	CreatedAt   string   `dynamodbav:"createdAt" json:"createdAt"`                         // RFC3339
+}
+
+// WebhookDelivery is the JSON body POSTed to a webhook
+type WebhookDelivery struct {
+	DeliveryID string                 `json:"id"`        // Same across retries, so receivers can de-duplicate
+	EventType  string                 `json:"type"`      // One of WebhookEventTypes
+	CreatedAt  string                 `json:"createdAt"` // RFC3339 (UTC), when the event happened
+	Data       map[string]interface{} `json:"data"`      // Event-specific details
+}
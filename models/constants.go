@@ -6,8 +6,12 @@ const (
 	InteractionTypeDislike = "dislike"
 	InteractionTypePing    = "ping"
 	InteractionTypeInvite  = "invite"
+	InteractionTypeSave    = "save" // ✅ "Maybe later": private, never shown to the other user
 )
 
+// InteractionTypes lists every interaction type, e.g. for validating feed filters
+var InteractionTypes = []string{InteractionTypeLike, InteractionTypeDislike, InteractionTypePing, InteractionTypeInvite, InteractionTypeSave}
+
 // ✅ Chat Types (private, group)
 const (
 	ChatTypePrivate = "private"
@@ -22,4 +26,8 @@ const (
 	StatusDeclined = "declined"
 	StatusApproved = "approved"
 	StatusRejected = "rejected"
+	StatusSaved    = "saved"
 )
+
+// InteractionStatuses lists every interaction status, e.g. for validating feed filters
+var InteractionStatuses = []string{StatusPending, StatusMatch, StatusSeen, StatusDeclined, StatusApproved, StatusRejected, StatusSaved}
@@ -0,0 +1,164 @@
+package routes
+
+import (
+	"net/http"
+	"strings"
+	"vibin_server/helpers"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+// ✅ API versions mounted under /api/{version}
+const (
+	APIVersionV1 = "v1"
+	APIVersionV2 = "v2"
+)
+
+// APIServices bundles the services the versioned route groups are built from
+type APIServices struct {
+	UserProfile      *services.UserProfileService
+	Chat             *services.ChatService
+	Interaction      *services.InteractionService
+	GroupInteraction *services.GroupInteractionService
+	GroupChat        *services.GroupChatService
+	SingleTable      *services.SingleTableService
+	PhotoInsights    *services.PhotoInsightsService
+	DateIdeas        *services.DateIdeasService
+	ProfileView      *services.ProfileViewService
+	Billing          *services.BillingService
+	PromoCode        *services.PromoCodeService
+	FeatureFlag      *services.FeatureFlagService
+	Moderation       *services.ModerationService
+	Encryption       *services.EncryptionService
+	Analytics        *services.AnalyticsService
+	Webhook          *services.WebhookService
+	S3               *services.S3Service
+	Photo            *services.PhotoService
+	PhotoModeration  *services.PhotoModerationService
+	MessageSafety    *services.MessageSafetyService
+	Call             *services.CallService
+	DateCheckIn      *services.DateCheckInService
+	Spotify          *services.SpotifyService
+	Passport         *services.PassportService
+	AccountDeletion  *services.AccountDeletionService
+	AgeVerification  *services.AgeVerificationService
+	Block            *services.BlockService
+	Report           *services.ReportService
+	AccountStanding  *services.AccountStandingService
+	Session          *services.SessionService
+	Captcha          *services.CaptchaService
+	Spam             *services.SpamDetector
+	NetworkGuard     *services.NetworkGuardService
+	Contact          *services.ContactService
+	Key              *services.KeyService
+	Export           *services.ConversationExportService
+	Opener           *services.OpenerService
+	BioSuggestion    *services.BioSuggestionService
+	Inbox            *services.InboxService
+	Badge            *services.BadgeService
+
+	AdminUserHandles []string // Users whose sessions may call /admin
+}
+
+// RegisterAPIRoutes mounts /api/v1 and /api/v2. Unversioned /api/... paths stay as a compatibility
+// shim that serves v1 with a Deprecation header, so existing clients keep working.
+func RegisterAPIRoutes(r *mux.Router, s APIServices) {
+	// ✅ Versioned groups first: the /api shim would otherwise swallow /api/v1/... paths
+	v1 := r.PathPrefix("/api/" + APIVersionV1).Subrouter()
+	v1.Use(helpers.APIVersionMiddleware(APIVersionV1))
+	v1.Use(helpers.SessionMiddleware(s.Session.Authenticate, s.Session.Required, sessionExempt("/api/"+APIVersionV1), adminPath("/api/"+APIVersionV1)))
+	v1.Use(helpers.AccountStandingMiddleware(s.UserProfile.CheckStanding, standingExempt("/api/"+APIVersionV1)))
+	registerV1Routes(v1, s)
+
+	v2 := r.PathPrefix("/api/" + APIVersionV2).Subrouter()
+	v2.Use(helpers.APIVersionMiddleware(APIVersionV2))
+	v2.Use(helpers.SessionMiddleware(s.Session.Authenticate, s.Session.Required, sessionExempt("/api/"+APIVersionV2), adminPath("/api/"+APIVersionV2)))
+	v2.Use(helpers.AccountStandingMiddleware(s.UserProfile.CheckStanding, standingExempt("/api/"+APIVersionV2)))
+	registerV2Routes(v2, s)
+
+	legacy := r.PathPrefix("/api").Subrouter()
+	legacy.Use(helpers.LegacyAPIMiddleware(APIVersionV1))
+	legacy.Use(helpers.SessionMiddleware(s.Session.Authenticate, s.Session.Required, sessionExempt("/api"), adminPath("/api")))
+	legacy.Use(helpers.AccountStandingMiddleware(s.UserProfile.CheckStanding, standingExempt("/api")))
+	registerV1Routes(legacy, s)
+}
+
+// sessionExempt skips session checks under prefix for starting and verifying a session, which is how
+// a device gets its token
+func sessionExempt(prefix string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		path := strings.TrimPrefix(r.URL.Path, prefix)
+		if r.Method != http.MethodPost {
+			return false
+		}
+		return path == "/sessions" || (strings.HasPrefix(path, "/sessions/") && strings.HasSuffix(path, "/verify"))
+	}
+}
+
+// adminPath matches the admin tools under prefix, where an admin's session acts on other users
+func adminPath(prefix string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		path := strings.TrimPrefix(r.URL.Path, prefix)
+		return path == "/admin" || strings.HasPrefix(path, "/admin/")
+	}
+}
+
+// standingExemptRoutes are the route groups suspended and banned users can still write to: admin
+// tools, safety features (blocking, reporting, date check-ins), signing in and out and deleting their
+// account
+var standingExemptRoutes = []string{"/admin", "/account", "/blocks", "/reports", "/safety", "/sessions"}
+
+// standingExempt matches request paths under prefix against standingExemptRoutes
+func standingExempt(prefix string) func(path string) bool {
+	return func(path string) bool {
+		path = strings.TrimPrefix(path, prefix)
+		for _, route := range standingExemptRoutes {
+			if path == route || strings.HasPrefix(path, route+"/") {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// registerV1Routes mounts the current API surface
+func registerV1Routes(r *mux.Router, s APIServices) {
+	RegisterUserProfileRoutes(r, s.UserProfile, s.Captcha)
+	RegisterChatRoutes(r, s.Chat)
+	RegisterInteractionsRoutes(r, s.Interaction, s.Captcha, s.Spam)
+	RegisterGroupInteractionRoutes(r, s.GroupInteraction)
+	RegisterGroupChatRoutes(r, s.GroupChat)
+	RegisterInboxRoutes(r, s.Inbox)
+	RegisterBadgeRoutes(r, s.Badge)
+	RegisterInsightsRoutes(r, s.PhotoInsights)
+	RegisterDateIdeasRoutes(r, s.DateIdeas, s.Opener)
+	RegisterProfileViewRoutes(r, s.ProfileView)
+	RegisterBillingRoutes(r, s.Billing)
+	RegisterPromoRoutes(r, s.PromoCode)
+	RegisterConfigRoutes(r, s.FeatureFlag)
+	RegisterAdminRoutes(r, s)
+	RegisterS3Routes(r, s.S3, s.UserProfile, s.PhotoModeration)
+	RegisterPhotoRoutes(r, s.Photo)
+	RegisterVideoRoutes(r, s.S3, s.UserProfile)
+	RegisterSpotifyRoutes(r, s.Spotify)
+	RegisterPassportRoutes(r, s.Passport)
+	RegisterAccountDeletionRoutes(r, s.AccountDeletion, s.AccountStanding)
+	RegisterAgeVerificationRoutes(r, s.AgeVerification)
+	RegisterBlockRoutes(r, s.Block)
+	RegisterReportRoutes(r, s.Report)
+	RegisterContactRoutes(r, s.Contact)
+	RegisterKeyRoutes(r, s.Key)
+	RegisterConversationExportRoutes(r, s.Export)
+	RegisterBioSuggestionRoutes(r, s.BioSuggestion)
+	RegisterCallRoutes(r, s.Call)
+	RegisterSafetyRoutes(r, s.DateCheckIn)
+	RegisterSessionRoutes(r, s.Session)
+}
+
+// registerV2Routes mounts v2: v1 plus the routes whose responses changed, registered first so they
+// win over their v1 counterparts. Breaking changes go here without touching v1.
+func registerV2Routes(r *mux.Router, s APIServices) {
+	RegisterGroupInteractionV2Routes(r, s.GroupInteraction)
+	registerV1Routes(r, s)
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"vibin_server/controllers"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterAccountDeletionRoutes registers the account deletion, restore and standing routes
+func RegisterAccountDeletionRoutes(r *mux.Router, accountDeletionService *services.AccountDeletionService, accountStandingService *services.AccountStandingService) {
+	controller := controllers.NewAccountDeletionController(accountDeletionService)
+	standingController := controllers.NewAccountStandingController(accountStandingService)
+
+	accountRouter := r.PathPrefix("/account").Subrouter()
+	accountRouter.HandleFunc("", controller.DeleteAccount).Methods("DELETE")             // ✅ ?userhandle=; restorable for 30 days
+	accountRouter.HandleFunc("/restore", controller.RestoreAccount).Methods("POST")      // ✅ Undo a pending deletion
+	accountRouter.HandleFunc("/standing", standingController.GetStanding).Methods("GET") // ✅ ?userhandle=; status and warnings/suspensions/bans, for appeals
+}
@@ -0,0 +1,67 @@
+package routes
+
+import (
+	"expvar"
+	"vibin_server/controllers"
+	"vibin_server/helpers"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterAdminRoutes registers internal admin routes, which only admins' sessions may call
+func RegisterAdminRoutes(r *mux.Router, s APIServices) {
+	moderationController := controllers.NewModerationController(s.Moderation)
+	encryptionController := controllers.NewEncryptionController(s.Encryption)
+	promoCodeController := controllers.NewPromoCodeController(s.PromoCode)
+	analyticsController := controllers.NewAnalyticsController(s.Analytics)
+	featureFlagController := controllers.NewFeatureFlagController(s.FeatureFlag)
+	webhookController := controllers.NewWebhookController(s.Webhook)
+	photoReviewController := controllers.NewPhotoReviewController(s.PhotoModeration)
+	ageVerificationController := controllers.NewAgeVerificationController(s.AgeVerification)
+	interactionAuditController := controllers.NewInteractionAuditController(s.Interaction)
+	exportController := controllers.NewConversationExportController(s.Export)
+	messageReviewController := controllers.NewMessageReviewController(s.MessageSafety)
+	callController := controllers.NewCallController(s.Call)
+	reportController := controllers.NewReportController(s.Report)
+	accountStandingController := controllers.NewAccountStandingController(s.AccountStanding)
+	sessionController := controllers.NewSessionController(s.Session)
+	networkBlockController := controllers.NewNetworkBlockController(s.NetworkGuard)
+	collectionController := controllers.NewCollectionController(s.SingleTable)
+
+	adminRouter := r.PathPrefix("/admin").Subrouter()
+	adminRouter.Use(helpers.AdminAuthMiddleware(s.AdminUserHandles))
+	adminRouter.HandleFunc("/moderation/rules", moderationController.GetRules).Methods("GET")    // ✅ Current rule set
+	adminRouter.HandleFunc("/moderation/rules", moderationController.UpdateRules).Methods("PUT") // ✅ Publish new version
+	adminRouter.HandleFunc("/conversations/{conversationId}/rotate-key", encryptionController.RotateConversationKey).Methods("POST")
+	adminRouter.HandleFunc("/promo-codes", promoCodeController.ListPromoCodes).Methods("GET")                           // ✅ All codes with usage
+	adminRouter.HandleFunc("/promo-codes", promoCodeController.CreatePromoCode).Methods("POST")                         // ✅ New campaign code
+	adminRouter.HandleFunc("/promo-codes/{code}/deactivate", promoCodeController.DeactivatePromoCode).Methods("POST")   // ✅ Stop redemptions
+	adminRouter.HandleFunc("/analytics/counts", analyticsController.GetEventCounts).Methods("GET")                      // ✅ Funnel event counts
+	adminRouter.HandleFunc("/flags", featureFlagController.ListFlags).Methods("GET")                                    // ✅ All feature flags
+	adminRouter.HandleFunc("/flags/{key}", featureFlagController.SaveFlag).Methods("PUT")                               // ✅ Create or replace a flag
+	adminRouter.HandleFunc("/webhooks", webhookController.ListWebhooks).Methods("GET")                                  // ✅ Registered webhooks (no secrets)
+	adminRouter.HandleFunc("/webhooks", webhookController.CreateWebhook).Methods("POST")                                // ✅ Register URL + events
+	adminRouter.HandleFunc("/webhooks/{webhookId}", webhookController.DeleteWebhook).Methods("DELETE")                  // ✅ Stop deliveries
+	adminRouter.HandleFunc("/photo-reviews", photoReviewController.ListReviews).Methods("GET")                          // ✅ Quarantined photo queue
+	adminRouter.HandleFunc("/photo-reviews/resolve", photoReviewController.ResolveReview).Methods("POST")               // ✅ Approve or reject a photo
+	adminRouter.HandleFunc("/message-reviews", messageReviewController.ListReviews).Methods("GET")                      // ✅ Flagged message queue
+	adminRouter.HandleFunc("/message-reviews/resolve", messageReviewController.ResolveReview).Methods("POST")           // ✅ Uphold or dismiss a flag
+	adminRouter.HandleFunc("/reports", reportController.ListReports).Methods("GET")                                     // ✅ Report queue by severity
+	adminRouter.HandleFunc("/reports/resolve", reportController.ResolveReport).Methods("POST")                          // ✅ Action or dismiss a report
+	adminRouter.HandleFunc("/users/{userhandle}/actions", accountStandingController.TakeAction).Methods("POST")         // ✅ Warn, suspend, ban or reinstate
+	adminRouter.HandleFunc("/users/{userhandle}/actions", accountStandingController.ListActions).Methods("GET")         // ✅ Moderation history, for appeals
+	adminRouter.HandleFunc("/users/{userhandle}/login-audit", sessionController.ListLoginAudit).Methods("GET")          // ✅ Sign-in decisions (lockouts, step-ups)
+	adminRouter.HandleFunc("/network-blocks", networkBlockController.ListBlocks).Methods("GET")                         // ✅ CIDR, ASN and country blocks
+	adminRouter.HandleFunc("/network-blocks", networkBlockController.AddBlock).Methods("POST")                          // ✅ Block a range, ASN or country
+	adminRouter.HandleFunc("/network-blocks/{blockId}", networkBlockController.RemoveBlock).Methods("DELETE")           // ✅ Lift a block
+	adminRouter.HandleFunc("/age-verifications", ageVerificationController.ListVerifications).Methods("GET")            // ✅ Age dispute queue
+	adminRouter.HandleFunc("/age-verifications/dispute", ageVerificationController.DisputeAge).Methods("POST")          // ✅ Hide a user reported as underage
+	adminRouter.HandleFunc("/age-verifications/resolve", ageVerificationController.ResolveVerification).Methods("POST") // ✅ Approve or reject a document
+	adminRouter.HandleFunc("/interactions/audit", interactionAuditController.GetAudit).Methods("GET")                   // ✅ Status history between two users
+	adminRouter.HandleFunc("/conversation-exports", exportController.ListExports).Methods("GET")                        // ✅ Transcripts a user exported
+	adminRouter.HandleFunc("/calls", callController.ListCalls).Methods("GET")                                           // ✅ Call tokens issued in a match
+	adminRouter.HandleFunc("/collections/users/{userHandle}", collectionController.GetUserCollection).Methods("GET")    // ✅ A user's single-table partition, paged
+	adminRouter.HandleFunc("/collections/matches/{matchId}", collectionController.GetMatchCollection).Methods("GET")    // ✅ A match's partition, paged
+	adminRouter.HandleFunc("/collections/groups/{groupId}", collectionController.GetGroupCollection).Methods("GET")     // ✅ A group's partition, paged
+	adminRouter.Handle("/debug/vars", expvar.Handler()).Methods("GET")                                                  // ✅ Runtime, retry and enrichment metrics
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"vibin_server/controllers"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterAgeVerificationRoutes registers the user side of age disputes; admins review them under /admin
+func RegisterAgeVerificationRoutes(r *mux.Router, ageVerificationService *services.AgeVerificationService) {
+	controller := controllers.NewAgeVerificationController(ageVerificationService)
+
+	ageRouter := r.PathPrefix("/profile/age-verification").Subrouter()
+	ageRouter.HandleFunc("", controller.GetVerification).Methods("GET") // ✅ ?userhandle=; dispute status
+	ageRouter.HandleFunc("", controller.SubmitDocument).Methods("POST") // ✅ Submit an ID document for review
+}
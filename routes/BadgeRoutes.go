@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"vibin_server/controllers"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterBadgeRoutes registers the aggregated badge counts route
+func RegisterBadgeRoutes(r *mux.Router, badgeService *services.BadgeService) {
+	controller := controllers.NewBadgeController(badgeService)
+
+	r.HandleFunc("/badges", controller.GetBadges).Methods("GET") // ✅ ?userhandle= — unread, likes, pings and group approvals in one call
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"vibin_server/controllers"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterBillingRoutes registers premium subscription routes
+func RegisterBillingRoutes(r *mux.Router, billingService *services.BillingService) {
+	controller := controllers.NewBillingController(billingService)
+
+	billingRouter := r.PathPrefix("/billing").Subrouter()
+	billingRouter.HandleFunc("/checkout", controller.CreateCheckoutSession).Methods("POST") // ✅ Start a premium checkout
+	billingRouter.HandleFunc("/subscription", controller.GetSubscription).Methods("GET")    // ✅ Current plan and entitlements
+	billingRouter.HandleFunc("/webhook", controller.HandleWebhook).Methods("POST")          // ✅ Stripe webhook (signature verified)
+}
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"vibin_server/controllers"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterBioSuggestionRoutes registers the bio drafting route
+func RegisterBioSuggestionRoutes(r *mux.Router, bioSuggestionService *services.BioSuggestionService) {
+	controller := controllers.NewBioSuggestionController(bioSuggestionService)
+
+	r.HandleFunc("/profile/bio/suggest", controller.SuggestBios).Methods("POST") // ✅ Drafts from interests/keywords (daily limit)
+}
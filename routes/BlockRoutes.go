@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"vibin_server/controllers"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterBlockRoutes registers the block routes
+func RegisterBlockRoutes(r *mux.Router, blockService *services.BlockService) {
+	controller := controllers.NewBlockController(blockService)
+
+	r.HandleFunc("/blocks", controller.ListBlocks).Methods("GET") // ✅ ?userhandle=
+	r.HandleFunc("/blocks", controller.BlockUser).Methods("POST") // ✅ Hide a user both ways
+	r.HandleFunc("/blocks", controller.Unblock).Methods("DELETE") // ✅ ?userhandle=&blockedHandle=
+}
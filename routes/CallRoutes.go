@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"vibin_server/controllers"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterCallRoutes registers the audio/video call routes
+func RegisterCallRoutes(r *mux.Router, callService *services.CallService) {
+	controller := controllers.NewCallController(callService)
+
+	r.HandleFunc("/calls/token", controller.IssueToken).Methods("POST") // ✅ Join a match's call
+}
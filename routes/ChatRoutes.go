@@ -11,9 +11,17 @@ import (
 func RegisterChatRoutes(r *mux.Router, chatService *services.ChatService) {
 	controller := controllers.NewChatController(chatService)
 
-	chatRouter := r.PathPrefix("/api/chat").Subrouter()
+	chatRouter := r.PathPrefix("/chat").Subrouter()
 	chatRouter.HandleFunc("/message", controller.HandleSendMessage).Methods("POST")                      // ✅ Send message
 	chatRouter.HandleFunc("/messages", controller.HandleGetMessages).Methods("GET")                      // ✅ Get messages
 	chatRouter.HandleFunc("/messages/mark-as-read", controller.HandleMarkMessagesAsRead).Methods("POST") // ✅ Mark messages as read
 	chatRouter.HandleFunc("/messages/like", controller.HandleLikeMessage).Methods("POST")                // ✅ Like/Unlike a message
+	chatRouter.HandleFunc("/messages/pin", controller.HandlePinMessage).Methods("POST")                  // ✅ Pin/Unpin a message
+	chatRouter.HandleFunc("/messages/search", controller.HandleSearchMessages).Methods("GET")            // ✅ Search messages by keyword/date
+	chatRouter.HandleFunc("/settings", controller.HandleGetConversationSettings).Methods("GET")          // ✅ Get conversation settings
+	chatRouter.HandleFunc("/settings/text-only", controller.HandleSetTextOnly).Methods("PUT")            // ✅ Restrict conversation to text
+	chatRouter.HandleFunc("/unread-counts", controller.HandleGetUnreadCounts).Methods("GET")             // ✅ Unread counts per match
+
+	// ✅ Delivery receipts: the recipient's client acknowledges messages as they arrive
+	chatRouter.HandleFunc("/messages/mark-as-delivered", controller.HandleMarkMessagesDelivered).Methods("POST")
 }
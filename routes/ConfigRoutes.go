@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"vibin_server/controllers"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterConfigRoutes registers the client remote-config route (flag management lives in AdminRoutes)
+func RegisterConfigRoutes(r *mux.Router, featureFlagService *services.FeatureFlagService) {
+	controller := controllers.NewFeatureFlagController(featureFlagService)
+
+	r.HandleFunc("/config", controller.GetClientConfig).Methods("GET") // ✅ Flags evaluated for ?userhandle=
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"vibin_server/controllers"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterContactRoutes registers the contact sync routes
+func RegisterContactRoutes(r *mux.Router, contactService *services.ContactService) {
+	controller := controllers.NewContactController(contactService)
+
+	contactRouter := r.PathPrefix("/contacts").Subrouter()
+	contactRouter.HandleFunc("", controller.SyncContacts).Methods("PUT")            // ✅ Replace with hashed numbers
+	contactRouter.HandleFunc("", controller.DeleteContacts).Methods("DELETE")       // ✅ ?userhandle=
+	contactRouter.HandleFunc("/settings", controller.GetSettings).Methods("GET")    // ✅ ?userhandle=
+	contactRouter.HandleFunc("/settings", controller.UpdateSettings).Methods("PUT") // ✅ Hide contacts / mutual connections
+}
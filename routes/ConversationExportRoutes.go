@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"vibin_server/controllers"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterConversationExportRoutes registers the transcript export route
+func RegisterConversationExportRoutes(r *mux.Router, exportService *services.ConversationExportService) {
+	controller := controllers.NewConversationExportController(exportService)
+
+	r.HandleFunc("/conversations/export", controller.ExportConversation).Methods("GET") // ✅ ?userhandle=&matchId=|groupId=&format=json|text
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"vibin_server/controllers"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterDateIdeasRoutes registers date idea and opener routes for matches
+func RegisterDateIdeasRoutes(r *mux.Router, dateIdeasService *services.DateIdeasService, openerService *services.OpenerService) {
+	controller := controllers.NewDateIdeasController(dateIdeasService)
+	openerController := controllers.NewOpenerController(openerService)
+
+	matchRouter := r.PathPrefix("/matches").Subrouter()
+	matchRouter.HandleFunc("/{matchId}/date-ideas", controller.GetDateIdeas).Methods("GET")  // ✅ Activity ideas + meeting point
+	matchRouter.HandleFunc("/{matchId}/openers", openerController.GetOpeners).Methods("GET") // ✅ Generated first messages (ai_openers flag)
+}
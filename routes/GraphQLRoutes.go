@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"log"
+	"net/http"
+	"vibin_server/controllers"
+	"vibin_server/graph"
+	"vibin_server/helpers"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterGraphQLRoutes mounts POST /graphql for aggregated screens (connections: matches, profiles,
+// last messages and unread counts in one round trip). It sits outside the /api routers, so the session
+// middleware is applied here; the resolvers check each query's userHandle against the session.
+func RegisterGraphQLRoutes(r *mux.Router, userProfileService *services.UserProfileService, interactionService *services.InteractionService, chatService *services.ChatService, sessionService *services.SessionService) {
+	schema, err := graph.NewSchema(&graph.Resolver{
+		UserProfileService: userProfileService,
+		InteractionService: interactionService,
+		ChatService:        chatService,
+	})
+	if err != nil {
+		log.Fatalf("❌ Invalid GraphQL schema: %v", err)
+	}
+	controller := controllers.NewGraphQLController(schema, userProfileService)
+	never := func(*http.Request) bool { return false }
+	withSession := helpers.SessionMiddleware(sessionService.Authenticate, sessionService.Required, never, never)
+
+	r.Handle("/graphql", withSession(http.HandlerFunc(controller.HandleQuery))).Methods("POST")
+}
@@ -11,7 +11,7 @@ import (
 func RegisterGroupInteractionRoutes(r *mux.Router, groupInteractionService *services.GroupInteractionService) {
 	controller := controllers.NewGroupInteractionController(groupInteractionService)
 
-	groupRouter := r.PathPrefix("/api/groupinteractions").Subrouter()
+	groupRouter := r.PathPrefix("/groupinteractions").Subrouter()
 
 	// ✅ Create group invite (User A invites User C)
 	groupRouter.HandleFunc("/invite", controller.CreateGroupInvite).Methods("POST")
@@ -25,4 +25,17 @@ func RegisterGroupInteractionRoutes(r *mux.Router, groupInteractionService *serv
 	// ✅ Approve or decline an invite
 	groupRouter.HandleFunc("/approve", controller.ApproveOrDeclineInvite).Methods("POST")
 	groupRouter.HandleFunc("/active/{userHandle}", controller.GetActiveGroups).Methods("GET")
+
+	// ✅ Membership and metadata changes (logged in the group timeline)
+	groupRouter.HandleFunc("/groups/{groupId}/leave", controller.LeaveGroup).Methods("POST")
+	groupRouter.HandleFunc("/groups/{groupId}/name", controller.RenameGroup).Methods("PUT")
+}
+
+// RegisterGroupInteractionV2Routes registers the v2 group routes whose responses differ from v1;
+// mount them before the v1 routes so they take precedence
+func RegisterGroupInteractionV2Routes(r *mux.Router, groupInteractionService *services.GroupInteractionService) {
+	controller := controllers.NewGroupInteractionController(groupInteractionService)
+
+	groupRouter := r.PathPrefix("/groupinteractions").Subrouter()
+	groupRouter.HandleFunc("/active/{userHandle}", controller.GetActiveGroupsPage).Methods("GET") // ✅ {groups, nextCursor}
 }
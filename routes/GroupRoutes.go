@@ -11,8 +11,17 @@ import (
 func RegisterGroupChatRoutes(r *mux.Router, groupChatService *services.GroupChatService) {
 	controller := controllers.NewGroupChatController(groupChatService)
 
-	groupRouter := r.PathPrefix("/api/groupchat").Subrouter()
-	groupRouter.HandleFunc("/message", controller.HandleCreateGroupMessage).Methods("POST") // ✅ Create a new group message
-	groupRouter.HandleFunc("/messages", controller.HandleGetGroupMessages).Methods("GET")   // ✅ Fetch group messages
+	groupRouter := r.PathPrefix("/groupchat").Subrouter()
+	groupRouter.HandleFunc("/message", controller.HandleCreateGroupMessage).Methods("POST")                   // ✅ Create a new group message
+	groupRouter.HandleFunc("/messages", controller.HandleGetGroupMessages).Methods("GET")                     // ✅ Fetch group messages
+	groupRouter.HandleFunc("/messages/mark-as-read", controller.HandleMarkGroupMessageAsRead).Methods("POST") // ✅ Mark a message as read
+	groupRouter.HandleFunc("/messages/receipts", controller.HandleGetReadReceipts).Methods("GET")             // ✅ Who has read a message
+	groupRouter.HandleFunc("/messages/search", controller.HandleSearchMessages).Methods("GET")                // ✅ Search messages by keyword/date
+	groupRouter.HandleFunc("/messages/reactions", controller.HandleAddReaction).Methods("POST")               // ✅ React with an emoji
+	groupRouter.HandleFunc("/messages/reactions", controller.HandleRemoveReaction).Methods("DELETE")          // ✅ ?groupId=&createdAt=&userHandle=&emoji=
+	groupRouter.HandleFunc("/unread-counts", controller.HandleGetUnreadCounts).Methods("GET")                 // ✅ Unread counts per group
+	groupRouter.HandleFunc("/presence", controller.HandleUpdatePresence).Methods("POST")                      // ✅ Heartbeat, optionally typing
+	groupRouter.HandleFunc("/presence", controller.HandleGetPresence).Methods("GET")                          // ✅ Who else is online / typing
+	groupRouter.HandleFunc("/presence", controller.HandleClearPresence).Methods("DELETE")                     // ✅ Go offline right away
 
 }
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"net/http"
+	"vibin_server/controllers"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterHealthRoutes registers the load balancer probes
+func RegisterHealthRoutes(r *mux.Router, healthService *services.HealthService) {
+	controller := controllers.NewHealthController(healthService)
+
+	r.HandleFunc("/health", controller.Livez).Methods("GET")  // ✅ Kept for existing monitors (same as /livez)
+	r.HandleFunc("/livez", controller.Livez).Methods("GET")   // ✅ Process is up
+	r.HandleFunc("/readyz", controller.Readyz).Methods("GET") // ✅ DynamoDB and S3 reachable
+}
+
+// IsHealthProbe matches the load balancer's health checks, which network guards must never refuse
+func IsHealthProbe(r *http.Request) bool {
+	return r.URL.Path == "/health" || r.URL.Path == "/livez" || r.URL.Path == "/readyz"
+}
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"vibin_server/controllers"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterInboxRoutes registers the unified inbox route
+func RegisterInboxRoutes(r *mux.Router, inboxService *services.InboxService) {
+	controller := controllers.NewInboxController(inboxService)
+
+	r.HandleFunc("/inbox", controller.GetInbox).Methods("GET") // ✅ ?userhandle=&limit=&cursor= — matches and groups by last activity
+}
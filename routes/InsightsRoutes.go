@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"vibin_server/controllers"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterInsightsRoutes registers profile insight routes
+func RegisterInsightsRoutes(r *mux.Router, photoInsightsService *services.PhotoInsightsService) {
+	controller := controllers.NewInsightsController(photoInsightsService)
+
+	insightsRouter := r.PathPrefix("/insights").Subrouter()
+	insightsRouter.HandleFunc("/photos", controller.GetPhotoInsights).Methods("GET") // ✅ Per-photo swipe performance
+}
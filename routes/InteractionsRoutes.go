@@ -7,10 +7,10 @@ import (
 	"github.com/gorilla/mux"
 )
 
-func RegisterInteractionsRoutes(router *mux.Router, interactionService *services.InteractionService) {
-	controller := &controllers.InteractionController{InteractionService: interactionService}
+func RegisterInteractionsRoutes(router *mux.Router, interactionService *services.InteractionService, captchaService *services.CaptchaService, spamDetector *services.SpamDetector) {
+	controller := &controllers.InteractionController{InteractionService: interactionService, Captcha: captchaService, Spam: spamDetector}
 
-	interactionRouter := router.PathPrefix("/api/interactions").Subrouter()
+	interactionRouter := router.PathPrefix("/interactions").Subrouter()
 
 	// Existing Routes
 	interactionRouter.HandleFunc("", controller.CreateInteractionHandler).Methods("POST")
@@ -18,7 +18,25 @@ func RegisterInteractionsRoutes(router *mux.Router, interactionService *services
 	interactionRouter.HandleFunc("/received", controller.GetReceivedInteractionsHandler).Methods("GET")
 	interactionRouter.HandleFunc("/matches", controller.GetMutualMatchesHandler).Methods("GET")
 
+	// ✅ One match in one document: profile, pinned messages, unread count and settings (?userHandle=)
+	router.HandleFunc("/matches/{matchId}", controller.GetMatchDetailHandler).Methods("GET")
+
 	// ✅ New Ping Handling Routes
 	interactionRouter.HandleFunc("/ping/approve", controller.ApprovePingHandler).Methods("POST")
 	interactionRouter.HandleFunc("/ping/decline", controller.DeclinePingHandler).Methods("POST")
+
+	// ✅ Second look: likes the user never acted on, resurfaced; engaging re-notifies the sender
+	interactionRouter.HandleFunc("/second-look", controller.GetSecondLookHandler).Methods("GET")
+	interactionRouter.HandleFunc("/second-look/engage", controller.EngageSecondLookHandler).Methods("POST")
+
+	// ✅ Premium: undo the last dislike
+	interactionRouter.HandleFunc("/rewind", controller.RewindHandler).Methods("POST")
+
+	// ✅ Saved profiles: "maybe later", never shown to the other user
+	interactionRouter.HandleFunc("/saved", controller.GetSavedHandler).Methods("GET")
+	interactionRouter.HandleFunc("/saved", controller.RemoveSavedHandler).Methods("DELETE") // ✅ ?userHandle=&receiverHandle=
+
+	// ✅ Passed profiles: older dislikes, which premium users can flip back to neutral
+	interactionRouter.HandleFunc("/passed", controller.GetPassedHandler).Methods("GET")
+	interactionRouter.HandleFunc("/passed/reconsider", controller.ReconsiderHandler).Methods("POST")
 }
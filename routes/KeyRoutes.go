@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"vibin_server/controllers"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterKeyRoutes registers the end-to-end encryption key distribution routes
+func RegisterKeyRoutes(r *mux.Router, keyService *services.KeyService) {
+	controller := controllers.NewKeyController(keyService)
+
+	keyRouter := r.PathPrefix("/keys").Subrouter()
+	keyRouter.HandleFunc("/bundle", controller.PublishBundle).Methods("PUT")         // ✅ Identity key, signed prekey, one-time prekeys
+	keyRouter.HandleFunc("/bundle", controller.RemoveDevice).Methods("DELETE")       // ✅ ?userhandle=&deviceId=
+	keyRouter.HandleFunc("/bundles", controller.FetchBundles).Methods("GET")         // ✅ ?userhandle=&peerHandle= (claims prekeys)
+	keyRouter.HandleFunc("/prekeys", controller.AddPreKeys).Methods("POST")          // ✅ Replenish one-time prekeys
+	keyRouter.HandleFunc("/prekeys/count", controller.GetPreKeyCount).Methods("GET") // ✅ ?userhandle=&deviceId=
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"vibin_server/controllers"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterPassportRoutes registers the travel (passport) mode routes
+func RegisterPassportRoutes(r *mux.Router, passportService *services.PassportService) {
+	controller := controllers.NewPassportController(passportService)
+
+	passportRouter := r.PathPrefix("/passport").Subrouter()
+	passportRouter.HandleFunc("", controller.SetPassport).Methods("PUT")      // ✅ Browse from another city (premium)
+	passportRouter.HandleFunc("", controller.ClearPassport).Methods("DELETE") // ✅ ?userhandle=; back to GPS
+}
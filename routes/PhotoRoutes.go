@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"vibin_server/controllers"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterPhotoRoutes registers profile photo processing and management routes
+func RegisterPhotoRoutes(r *mux.Router, photoService *services.PhotoService) {
+	controller := controllers.NewPhotoController(photoService)
+
+	photoRouter := r.PathPrefix("/photos").Subrouter()
+	photoRouter.HandleFunc("", controller.AddPhoto).Methods("POST")               // ✅ Add an uploaded photo; renditions follow
+	photoRouter.HandleFunc("", controller.DeletePhoto).Methods("DELETE")          // ✅ ?userhandle=&key=; also removes the S3 objects
+	photoRouter.HandleFunc("/process", controller.ProcessPhoto).Methods("POST")   // ✅ Generate thumbnail/medium/large renditions
+	photoRouter.HandleFunc("/order", controller.ReorderPhotos).Methods("PUT")     // ✅ New order of the current photos
+	photoRouter.HandleFunc("/primary", controller.SetPrimaryPhoto).Methods("PUT") // ✅ Move a photo to the front
+	photoRouter.HandleFunc("/caption", controller.SetPhotoCaption).Methods("PUT") // ✅ Set or clear a caption
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"vibin_server/controllers"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterProfileViewRoutes registers "who viewed my profile" routes
+func RegisterProfileViewRoutes(r *mux.Router, profileViewService *services.ProfileViewService) {
+	controller := controllers.NewProfileViewController(profileViewService)
+
+	profileRouter := r.PathPrefix("/profile").Subrouter()
+	profileRouter.HandleFunc("/views", controller.RecordView).Methods("POST")        // ✅ Record a profile open
+	profileRouter.HandleFunc("/viewers", controller.GetRecentViewers).Methods("GET") // ✅ Recent viewers
+}
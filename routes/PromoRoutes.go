@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"vibin_server/controllers"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterPromoRoutes registers the user-facing promo code routes (admin routes live in AdminRoutes)
+func RegisterPromoRoutes(r *mux.Router, promoCodeService *services.PromoCodeService) {
+	controller := controllers.NewPromoCodeController(promoCodeService)
+
+	promoRouter := r.PathPrefix("/promo").Subrouter()
+	promoRouter.HandleFunc("/redeem", controller.RedeemPromoCode).Methods("POST") // ✅ Redeem a campaign code
+}
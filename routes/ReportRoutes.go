@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"vibin_server/controllers"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterReportRoutes registers the safety center report route
+func RegisterReportRoutes(r *mux.Router, reportService *services.ReportService) {
+	controller := controllers.NewReportController(reportService)
+
+	r.HandleFunc("/reports", controller.ReportUser).Methods("POST") // ✅ Block and file a categorized report with evidence
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"vibin_server/controllers"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterSafetyRoutes registers the date safety check-in routes
+func RegisterSafetyRoutes(r *mux.Router, checkInService *services.DateCheckInService) {
+	controller := controllers.NewDateCheckInController(checkInService)
+
+	safetyRouter := r.PathPrefix("/safety/check-ins").Subrouter()
+	safetyRouter.HandleFunc("", controller.CreateCheckIn).Methods("POST")                // ✅ Register a date + emergency contact
+	safetyRouter.HandleFunc("", controller.ListCheckIns).Methods("GET")                  // ✅ ?userhandle=
+	safetyRouter.HandleFunc("/{checkInId}/safe", controller.ConfirmSafe).Methods("POST") // ✅ Answer a check-in prompt
+	safetyRouter.HandleFunc("/{checkInId}", controller.CancelCheckIn).Methods("DELETE")  // ✅ ?userhandle=
+}
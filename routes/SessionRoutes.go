@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"vibin_server/controllers"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterSessionRoutes registers the session (signed-in device) routes
+func RegisterSessionRoutes(r *mux.Router, sessionService *services.SessionService) {
+	controller := controllers.NewSessionController(sessionService)
+
+	sessionRouter := r.PathPrefix("/sessions").Subrouter()
+	sessionRouter.HandleFunc("", controller.CreateSession).Methods("POST")                    // ✅ Start a session; sends a sign-in code
+	sessionRouter.HandleFunc("", controller.ListSessions).Methods("GET")                      // ✅ ?userhandle=; devices, most recently used first
+	sessionRouter.HandleFunc("/{sessionId}/verify", controller.VerifySession).Methods("POST") // ✅ Check the code; returns the bearer token once
+	sessionRouter.HandleFunc("/{sessionId}", controller.RevokeSession).Methods("DELETE")      // ✅ ?userhandle=; remote sign-out
+}
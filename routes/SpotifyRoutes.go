@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"vibin_server/controllers"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterSpotifyRoutes registers the Spotify connect/disconnect routes
+func RegisterSpotifyRoutes(r *mux.Router, spotifyService *services.SpotifyService) {
+	controller := controllers.NewSpotifyController(spotifyService)
+
+	spotifyRouter := r.PathPrefix("/spotify").Subrouter()
+	spotifyRouter.HandleFunc("/connect", controller.Connect).Methods("POST") // ✅ Exchange an OAuth code, store top artists
+	spotifyRouter.HandleFunc("", controller.Disconnect).Methods("DELETE")    // ✅ ?userhandle=; removes the data
+}
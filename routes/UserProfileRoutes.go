@@ -8,16 +8,49 @@ import (
 )
 
 // RegisterUserProfileRoutes sets up routes related to user profiles
-func RegisterUserProfileRoutes(r *mux.Router, userProfileService *services.UserProfileService) {
-	controller := controllers.NewUserProfileController(userProfileService)
+func RegisterUserProfileRoutes(r *mux.Router, userProfileService *services.UserProfileService, captchaService *services.CaptchaService) {
+	controller := controllers.NewUserProfileController(userProfileService, captchaService)
 
-	profileRouter := r.PathPrefix("/api/profile").Subrouter()
-	profileRouter.HandleFunc("", controller.CreateUserProfile).Methods("POST")
+	profileRouter := r.PathPrefix("/profile").Subrouter()
+	profileRouter.HandleFunc("", controller.CreateUserProfile).Methods("POST")     // ✅ Create only; 409 if the userhandle has a profile
+	profileRouter.HandleFunc("", controller.UpdateUserProfile).Methods("PATCH")    // ✅ Edit the fields present in the body
+	profileRouter.HandleFunc("", controller.GetUserProfile).Methods("GET", "HEAD") // ✅ ?userhandle=; ETag / If-None-Match
 	profileRouter.HandleFunc("/by-email", controller.GetUserProfileByEmail).Methods("POST")
 	profileRouter.HandleFunc("/check-userhandle", controller.CheckUserHandleAvailability).Methods("GET")
 	profileRouter.HandleFunc("/check-email", controller.CheckEmailAvailability).Methods("POST")
 	profileRouter.HandleFunc("/fetch-userhandle", controller.GetUserHandleByEmail).Methods("GET")
 
-	// ✅ New route to fetch suggested profiles based on gender
+	// ✅ Suggested profiles compatible with the requester (orientation and lookingFor, both ways)
 	profileRouter.HandleFunc("/suggestions", controller.GetUserSuggestions).Methods("POST")
+	profileRouter.HandleFunc("/suggestions", controller.ListUserSuggestions).Methods("GET") // ✅ ?userhandle=&genders=; ETag / If-None-Match
+
+	// ✅ Browse by named city or region, for areas where radius suggestions find nobody
+	profileRouter.HandleFunc("/browse", controller.BrowsePlace).Methods("GET")
+
+	// ✅ Cheap staleness check for cached suggestion cards
+	profileRouter.HandleFunc("/version", controller.CheckProfileVersion).Methods("GET", "HEAD")
+
+	// ✅ Processing consents (right to object)
+	profileRouter.HandleFunc("/consents", controller.GetProcessingConsents).Methods("GET")
+	profileRouter.HandleFunc("/consents", controller.UpdateProcessingConsents).Methods("PUT")
+
+	// ✅ Location updates from the app (ignores GPS jitter, maintains the discovery geohash)
+	profileRouter.HandleFunc("/location", controller.UpdateLocation).Methods("POST")
+
+	// ✅ Snooze: leave discovery without losing matches
+	profileRouter.HandleFunc("/pause", controller.PauseProfile).Methods("PUT")
+	profileRouter.HandleFunc("/pause", controller.ResumeProfile).Methods("DELETE")
+
+	// ✅ Completeness score and the sections still missing
+	profileRouter.HandleFunc("/completeness", controller.GetProfileCompleteness).Methods("GET")
+
+	// ✅ Interest catalog and the user's interests (catalog IDs)
+	profileRouter.HandleFunc("/interests/catalog", controller.GetInterestCatalog).Methods("GET")
+	profileRouter.HandleFunc("/interests", controller.UpdateProfileInterests).Methods("PUT")
+
+	// ✅ Prompt catalog and the user's answered prompts
+	profileRouter.HandleFunc("/prompts/questions", controller.GetPromptQuestions).Methods("GET")
+	profileRouter.HandleFunc("/prompts", controller.UpdateProfilePrompts).Methods("PUT")
+	profileRouter.HandleFunc("/prompts/audio", controller.SetAudioPrompt).Methods("PUT")
+	profileRouter.HandleFunc("/prompts/audio", controller.DeleteAudioPrompt).Methods("DELETE") // ✅ ?userhandle=
 }
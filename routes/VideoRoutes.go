@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"vibin_server/controllers"
+	"vibin_server/services"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterVideoRoutes registers the multipart upload routes for profile videos
+func RegisterVideoRoutes(r *mux.Router, s3Service *services.S3Service, userProfileService *services.UserProfileService) {
+	controller := controllers.NewVideoController(s3Service, userProfileService)
+
+	uploadRouter := r.PathPrefix("/videos/uploads").Subrouter()
+	uploadRouter.HandleFunc("", controller.CreateUpload).Methods("POST")            // ✅ Start a multipart upload
+	uploadRouter.HandleFunc("/parts", controller.GetPartURLs).Methods("POST")       // ✅ Presign a batch of part PUTs
+	uploadRouter.HandleFunc("/complete", controller.CompleteUpload).Methods("POST") // ✅ Assemble parts, add to profile
+	uploadRouter.HandleFunc("/abort", controller.AbortUpload).Methods("POST")       // ✅ Discard an unfinished upload
+}
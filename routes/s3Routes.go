@@ -1,13 +1,30 @@
 package routes
 
 import (
+	"net/http"
 	"vibin_server/controllers"
+	"vibin_server/helpers"
+	"vibin_server/services"
 
 	"github.com/gorilla/mux"
 )
 
 // RegisterS3Routes sets up routes for S3-related operations
-func RegisterS3Routes(r *mux.Router) {
-	r.HandleFunc("/generate-presigned-url", controllers.GeneratePresignedURL).Methods("POST")
-	r.HandleFunc("/get-presigned-read-url", controllers.GetPresignedReadURL).Methods("POST")
+func RegisterS3Routes(r *mux.Router, s3Service *services.S3Service, userProfileService *services.UserProfileService, photoModeration *services.PhotoModerationService) {
+	controller := controllers.NewS3Controller(s3Service, userProfileService, photoModeration)
+
+	r.HandleFunc("/generate-presigned-url", controller.GeneratePresignedURL).Methods("POST")
+	r.HandleFunc("/get-presigned-read-url", controller.GetPresignedReadURL).Methods("POST")
+}
+
+// RegisterLegacyS3Routes sets up the root-level presigned URL routes older clients still call. They sit
+// outside the /api routers, so the session middleware is applied here: uploads are namespaced under the
+// session's user, and reads are limited to what that user may see.
+func RegisterLegacyS3Routes(r *mux.Router, s3Service *services.S3Service, userProfileService *services.UserProfileService, photoModeration *services.PhotoModerationService, sessionService *services.SessionService) {
+	controller := controllers.NewS3Controller(s3Service, userProfileService, photoModeration)
+	never := func(*http.Request) bool { return false }
+	withSession := helpers.SessionMiddleware(sessionService.Authenticate, sessionService.Required, never, never)
+
+	r.Handle("/generate-presigned-url", withSession(http.HandlerFunc(controller.GeneratePresignedURL))).Methods("POST")
+	r.Handle("/get-presigned-read-url", withSession(http.HandlerFunc(controller.GetPresignedReadURL))).Methods("POST")
 }
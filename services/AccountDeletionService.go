@@ -0,0 +1,358 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// s3DeleteBatch is the most keys one DeleteObjects call accepts
+const s3DeleteBatch = 1000
+
+// AccountDeletionService soft-deletes accounts: a deleted profile is hidden everywhere but can be
+// restored for AccountDeletionGraceDays, after which the sweeper erases it and its data.
+type AccountDeletionService struct {
+	Dynamo *DynamoService
+	S3     *S3Service
+}
+
+// DeleteAccount starts the grace period for the user's account
+func (s *AccountDeletionService) DeleteAccount(ctx context.Context, userHandle string) (*models.AccountDeletion, error) {
+	now := time.Now().UTC()
+	deletion := &models.AccountDeletion{
+		UserHandle: userHandle,
+		DeletedAt:  now.Format(time.RFC3339),
+		PurgeAfter: now.AddDate(0, 0, models.AccountDeletionGraceDays).Format(time.RFC3339),
+	}
+	_, err := s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(models.UserProfilesTable),
+		Key:                 profileKey(userHandle),
+		UpdateExpression:    aws.String("SET deletedAt = :now, purgeAfter = :purgeAfter, pendingDeletion = :pending, updatedAt = :now ADD profileVersion :one"),
+		ConditionExpression: aws.String("attribute_exists(userhandle) AND attribute_not_exists(deletedAt)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":now":        &types.AttributeValueMemberS{Value: deletion.DeletedAt},
+			":purgeAfter": &types.AttributeValueMemberS{Value: deletion.PurgeAfter},
+			":pending":    &types.AttributeValueMemberS{Value: models.PendingDeletionMarker},
+			":one":        &types.AttributeValueMemberN{Value: "1"},
+		},
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		if len(conditionFailed.Item) == 0 {
+			return nil, notFoundError("profile not found")
+		}
+		return nil, conflictError("account is already deleted")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete account %s: %w", userHandle, err)
+	}
+
+	utils.Logf(ctx, "🗑️ Deleted account %s; restorable until %s", userHandle, deletion.PurgeAfter)
+	return deletion, nil
+}
+
+// RestoreAccount cancels a deletion that is still within its grace period
+func (s *AccountDeletionService) RestoreAccount(ctx context.Context, userHandle string) (*models.AccountDeletion, error) {
+	_, err := s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(models.UserProfilesTable),
+		Key:                 profileKey(userHandle),
+		UpdateExpression:    aws.String("REMOVE deletedAt, purgeAfter, pendingDeletion SET updatedAt = :now ADD profileVersion :one"),
+		ConditionExpression: aws.String("attribute_exists(deletedAt) AND purgeAfter > :now"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":now": &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)},
+			":one": &types.AttributeValueMemberN{Value: "1"},
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return nil, notFoundError("no restorable deletion for this account")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore account %s: %w", userHandle, err)
+	}
+
+	utils.Logf(ctx, "✅ Restored account %s", userHandle)
+	return &models.AccountDeletion{UserHandle: userHandle, Restored: true}, nil
+}
+
+// StartSweeper purges accounts whose grace period ended, now and then every interval until ctx is
+// cancelled. Purging is idempotent, so several instances may sweep at once.
+func (s *AccountDeletionService) StartSweeper(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := s.SweepDeletedAccounts(ctx); err != nil {
+				utils.Logf(ctx, "⚠️ Account deletion sweep failed: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// SweepDeletedAccounts purges every account past its purgeAfter time
+func (s *AccountDeletionService) SweepDeletedAccounts(ctx context.Context) error {
+	items, err := s.Dynamo.QueryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.UserProfilesTable),
+		IndexName:              aws.String(models.PendingDeletionIndex),
+		KeyConditionExpression: aws.String("pendingDeletion = :pending AND purgeAfter <= :now"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pending": &types.AttributeValueMemberS{Value: models.PendingDeletionMarker},
+			":now":     &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	var profiles []models.UserProfile
+	if err := attributevalue.UnmarshalListOfMaps(items, &profiles); err != nil {
+		return fmt.Errorf("failed to parse deleted profiles: %w", err)
+	}
+	for _, profile := range profiles {
+		if err := s.purgeAccount(ctx, &profile); err != nil {
+			utils.Logf(ctx, "❌ Failed to purge %s, will retry next sweep: %v", profile.UserHandle, err)
+		}
+	}
+	return nil
+}
+
+// purgeAccount erases the user's uploads, interactions, photo stats, profile views and contacts,
+// then the profile itself (last, so a failed purge is retried). Chat history stays with the other
+// participant of each conversation.
+func (s *AccountDeletionService) purgeAccount(ctx context.Context, profile *models.UserProfile) error {
+	handle := profile.UserHandle
+	utils.Logf(ctx, "🗑️ Purging account %s", handle)
+
+	// ✅ Uploads under the user's prefix, plus older keys stored on the profile
+	keys, err := s.S3.ListKeys(ctx, models.UserUploadPrefix(handle))
+	if err != nil {
+		return err
+	}
+	keys = append(keys, profile.Photos...)
+	keys = append(keys, profile.Videos...)
+	for _, renditions := range profile.PhotoRenditions {
+		keys = append(keys, renditions.Thumbnail, renditions.Medium, renditions.Large)
+	}
+	if profile.AudioPrompt != nil {
+		keys = append(keys, profile.AudioPrompt.Key)
+	}
+	keys = nonEmptyUnique(keys)
+	for i := 0; i < len(keys); i += s3DeleteBatch {
+		if err := s.S3.DeleteObjects(ctx, keys[i:min(i+s3DeleteBatch, len(keys))]); err != nil {
+			return err
+		}
+	}
+
+	// ✅ Photo moderation reviews are keyed by photo
+	var reviewKeys []map[string]types.AttributeValue
+	for _, photo := range nonEmptyUnique(append(profile.Photos, profile.QuarantinedPhotos...)) {
+		reviewKeys = append(reviewKeys, map[string]types.AttributeValue{"photoKey": &types.AttributeValueMemberS{Value: photo}})
+	}
+	if err := s.deleteKeys(ctx, models.PhotoReviewsTable, reviewKeys); err != nil {
+		return err
+	}
+
+	// ✅ Interactions the user sent (their partition) and received (receiver index)
+	for _, input := range []*dynamodb.QueryInput{
+		{
+			TableName:              aws.String(models.InteractionsTable),
+			KeyConditionExpression: aws.String("PK = :pk"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk": &types.AttributeValueMemberS{Value: "USER#" + handle},
+			},
+		},
+		{
+			TableName:              aws.String(models.InteractionsTable),
+			IndexName:              aws.String(models.ReceiverHandleIndex),
+			KeyConditionExpression: aws.String("receiverHandle = :handle"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":handle": &types.AttributeValueMemberS{Value: handle},
+			},
+		},
+	} {
+		if err := s.deleteQueried(ctx, input, "PK", "SK"); err != nil {
+			return err
+		}
+	}
+
+	// ✅ Per-user rows keyed by the handle
+	if err := s.deleteQueried(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.PhotoInsightsTable),
+		KeyConditionExpression: aws.String("userhandle = :handle"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":handle": &types.AttributeValueMemberS{Value: handle},
+		},
+	}, "userhandle", "photoIndex"); err != nil {
+		return err
+	}
+	if err := s.deleteQueried(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.ProfileViewsTable),
+		KeyConditionExpression: aws.String("viewedHandle = :handle"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":handle": &types.AttributeValueMemberS{Value: handle},
+		},
+	}, "viewedHandle", "SK"); err != nil {
+		return err
+	}
+
+	// ✅ Age disputes (their documents live under the upload prefix, deleted above)
+	if err := s.deleteKeys(ctx, models.AgeVerificationsTable, []map[string]types.AttributeValue{profileKey(handle)}); err != nil {
+		return err
+	}
+
+	// ✅ Synced contacts
+	if err := s.deleteQueried(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.ContactsTable),
+		KeyConditionExpression: aws.String("userhandle = :handle"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":handle": &types.AttributeValueMemberS{Value: handle},
+		},
+	}, "userhandle", "contactIndex"); err != nil {
+		return err
+	}
+
+	// ✅ Date check-ins (they hold an emergency contact's phone number)
+	if err := s.deleteQueried(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.DateCheckInsTable),
+		KeyConditionExpression: aws.String("userhandle = :handle"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":handle": &types.AttributeValueMemberS{Value: handle},
+		},
+	}, "userhandle", "checkInId"); err != nil {
+		return err
+	}
+
+	// ✅ Signed-in devices
+	if err := s.deleteQueried(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.SessionsTable),
+		IndexName:              aws.String(models.SessionUserIndex),
+		KeyConditionExpression: aws.String("userhandle = :handle"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":handle": &types.AttributeValueMemberS{Value: handle},
+		},
+	}, "sessionId"); err != nil {
+		return err
+	}
+
+	// ✅ Suggestion history (also expires by itself)
+	if err := s.deleteQueried(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.ShownProfilesTable),
+		KeyConditionExpression: aws.String("userhandle = :handle"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":handle": &types.AttributeValueMemberS{Value: handle},
+		},
+	}, "userhandle", "shownHandle"); err != nil {
+		return err
+	}
+
+	// ✅ Quota counters (also expire by themselves)
+	if err := s.deleteQueried(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.QuotaCountersTable),
+		KeyConditionExpression: aws.String("userhandle = :handle"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":handle": &types.AttributeValueMemberS{Value: handle},
+		},
+	}, "userhandle", "counter"); err != nil {
+		return err
+	}
+
+	// ✅ End-to-end encryption keys of every device
+	for _, table := range []struct{ name, sortKey string }{
+		{models.KeyBundlesTable, "deviceId"},
+		{models.OneTimePreKeysTable, "preKeyId"},
+	} {
+		if err := s.deleteQueried(ctx, &dynamodb.QueryInput{
+			TableName:              aws.String(table.name),
+			KeyConditionExpression: aws.String("userhandle = :handle"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":handle": &types.AttributeValueMemberS{Value: handle},
+			},
+		}, "userhandle", table.sortKey); err != nil {
+			return err
+		}
+	}
+
+	// ✅ The profile goes last; the condition keeps an account restored meanwhile
+	_, err = s.Dynamo.Client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(models.UserProfilesTable),
+		Key:                 profileKey(handle),
+		ConditionExpression: aws.String("purgeAfter <= :now"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":now": &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)},
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		utils.Logf(ctx, "ℹ️ %s was restored during the purge; keeping the profile", handle)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete profile %s: %w", handle, err)
+	}
+
+	utils.Logf(ctx, "✅ Purged account %s (%d objects)", handle, len(keys))
+	return nil
+}
+
+// deleteQueried deletes every item the query returns, keyed by the given attributes
+func (s *AccountDeletionService) deleteQueried(ctx context.Context, input *dynamodb.QueryInput, keyAttributes ...string) error {
+	items, err := s.Dynamo.QueryAll(ctx, input)
+	if err != nil {
+		return err
+	}
+	keys := make([]map[string]types.AttributeValue, 0, len(items))
+	for _, item := range items {
+		key := make(map[string]types.AttributeValue, len(keyAttributes))
+		for _, attribute := range keyAttributes {
+			key[attribute] = item[attribute]
+		}
+		keys = append(keys, key)
+	}
+	return s.deleteKeys(ctx, aws.ToString(input.TableName), keys)
+}
+
+// deleteKeys batch-deletes items by key
+func (s *AccountDeletionService) deleteKeys(ctx context.Context, tableName string, keys []map[string]types.AttributeValue) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	requests := make([]types.WriteRequest, 0, len(keys))
+	for _, key := range keys {
+		requests = append(requests, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: key}})
+	}
+	return s.Dynamo.BatchWriteItems(ctx, tableName, requests)
+}
+
+// profileKey is the primary key of a user profile
+func profileKey(userHandle string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"userhandle": &types.AttributeValueMemberS{Value: userHandle},
+	}
+}
+
+// nonEmptyUnique drops empty and repeated strings, keeping the first occurrence
+func nonEmptyUnique(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" && !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+)
+
+// AccountStandingService applies trust & safety's warnings, suspensions and bans. The profile's
+// accountStatus is what gets enforced (see UserProfileService.CheckStanding); every action is also
+// recorded in AccountActionsTable, which the user can read back to appeal.
+type AccountStandingService struct {
+	Dynamo *DynamoService
+}
+
+// TakeAction applies an action to the profile and records it in the history, atomically
+func (s *AccountStandingService) TakeAction(ctx context.Context, userHandle string, request models.AccountActionRequest) (*models.AccountAction, error) {
+	now := time.Now().UTC()
+	action := models.AccountAction{
+		UserHandle: userHandle,
+		CreatedAt:  now.Format(time.RFC3339Nano),
+		ActionID:   uuid.New().String(),
+		Type:       request.Type,
+		Reason:     request.Reason,
+		ReportID:   request.ReportID,
+		IssuedBy:   request.IssuedBy,
+	}
+
+	if request.Type == models.AccountActionSuspension {
+		until, err := time.Parse(time.RFC3339, request.Until)
+		if err != nil {
+			return nil, validationError("until must be an RFC3339 time")
+		}
+		if !until.After(now) || until.After(now.AddDate(0, 0, models.MaxSuspensionDays)) {
+			return nil, validationError(fmt.Sprintf("until must be in the next %d days", models.MaxSuspensionDays))
+		}
+		action.Until = until.UTC().Format(time.RFC3339)
+	}
+
+	profileOp, conflict, err := s.profileUpdate(action, now)
+	if err != nil {
+		return nil, err
+	}
+	item, err := attributevalue.MarshalMap(action)
+	if err != nil {
+		return nil, err
+	}
+	err = s.Dynamo.TransactWriteItems(ctx, []types.TransactWriteItem{
+		profileOp,
+		{Put: &types.Put{TableName: aws.String(models.AccountActionsTable), Item: item}},
+	})
+	var cancelled *types.TransactionCanceledException
+	if errors.As(err, &cancelled) && len(cancelled.CancellationReasons) > 0 && aws.ToString(cancelled.CancellationReasons[0].Code) == "ConditionalCheckFailed" {
+		if len(cancelled.CancellationReasons[0].Item) == 0 {
+			return nil, notFoundError("profile not found")
+		}
+		return nil, conflictError(conflict)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to record %s for %s: %w", action.Type, userHandle, err)
+	}
+
+	utils.Logf(ctx, "🔨 %s issued a %s to %s", action.IssuedBy, action.Type, userHandle)
+	return &action, nil
+}
+
+// profileUpdate builds the profile side of an action, with the conflict message for its condition.
+// A warning only checks that the profile exists; a suspension can't shorten or replace a ban.
+func (s *AccountStandingService) profileUpdate(action models.AccountAction, now time.Time) (types.TransactWriteItem, string, error) {
+	values := map[string]types.AttributeValue{
+		":now": &types.AttributeValueMemberS{Value: now.Format(time.RFC3339)},
+		":one": &types.AttributeValueMemberN{Value: "1"},
+	}
+	var update, condition, conflict string
+	switch action.Type {
+	case models.AccountActionWarning:
+		return types.TransactWriteItem{
+			ConditionCheck: &types.ConditionCheck{
+				TableName:           aws.String(models.UserProfilesTable),
+				Key:                 profileKey(action.UserHandle),
+				ConditionExpression: aws.String("attribute_exists(userhandle)"),
+			},
+		}, "", nil
+	case models.AccountActionSuspension:
+		update = "SET accountStatus = :suspended, suspendedUntil = :until, updatedAt = :now ADD profileVersion :one"
+		condition = "attribute_exists(userhandle) AND (attribute_not_exists(accountStatus) OR accountStatus <> :banned)"
+		conflict = "account is banned; reinstate it first"
+		values[":suspended"] = &types.AttributeValueMemberS{Value: models.AccountStatusSuspended}
+		values[":until"] = &types.AttributeValueMemberS{Value: action.Until}
+		values[":banned"] = &types.AttributeValueMemberS{Value: models.AccountStatusBanned}
+	case models.AccountActionBan:
+		update = "SET accountStatus = :banned, updatedAt = :now REMOVE suspendedUntil ADD profileVersion :one"
+		condition = "attribute_exists(userhandle) AND (attribute_not_exists(accountStatus) OR accountStatus <> :banned)"
+		conflict = "account is already banned"
+		values[":banned"] = &types.AttributeValueMemberS{Value: models.AccountStatusBanned}
+	case models.AccountActionReinstatement:
+		update = "SET updatedAt = :now REMOVE accountStatus, suspendedUntil ADD profileVersion :one"
+		condition = "attribute_exists(userhandle) AND attribute_exists(accountStatus)"
+		conflict = "account is not suspended or banned"
+	case models.AccountActionShadowban:
+		update = "SET shadowbanned = :true, updatedAt = :now"
+		condition = "attribute_exists(userhandle) AND attribute_not_exists(shadowbanned)"
+		conflict = "account is already shadowbanned"
+		values[":true"] = &types.AttributeValueMemberBOOL{Value: true}
+	case models.AccountActionShadowbanLift:
+		update = "SET updatedAt = :now REMOVE shadowbanned"
+		condition = "attribute_exists(userhandle) AND attribute_exists(shadowbanned)"
+		conflict = "account is not shadowbanned"
+	default:
+		return types.TransactWriteItem{}, "", validationError("unknown action type")
+	}
+
+	return types.TransactWriteItem{
+		Update: &types.Update{
+			TableName:                           aws.String(models.UserProfilesTable),
+			Key:                                 profileKey(action.UserHandle),
+			UpdateExpression:                    aws.String(update),
+			ConditionExpression:                 aws.String(condition),
+			ExpressionAttributeValues:           values,
+			ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+		},
+	}, conflict, nil
+}
+
+// ListActions returns one page of the user's moderation history, newest first
+func (s *AccountStandingService) ListActions(ctx context.Context, userHandle string, limit int32, cursor string) ([]models.AccountAction, string, error) {
+	items, nextCursor, err := s.Dynamo.QueryPage(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.AccountActionsTable),
+		KeyConditionExpression: aws.String("userhandle = :handle"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":handle": &types.AttributeValueMemberS{Value: userHandle},
+		},
+		ScanIndexForward: aws.Bool(false),
+	}, limit, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	actions := []models.AccountAction{}
+	if err := attributevalue.UnmarshalListOfMaps(items, &actions); err != nil {
+		return nil, "", fmt.Errorf("failed to parse account actions: %w", err)
+	}
+	return actions, nextCursor, nil
+}
+
+// GetStanding returns the user's current status and history, without the issuing admins or any
+// shadow actions
+func (s *AccountStandingService) GetStanding(ctx context.Context, userHandle string) (*models.AccountStanding, error) {
+	item, err := s.Dynamo.GetItemAttributes(ctx, models.UserProfilesTable, profileKey(userHandle), "accountStatus", "suspendedUntil")
+	if errors.Is(err, ErrNotFound) {
+		return nil, notFoundError("profile not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	var profile models.UserProfile
+	if err := attributevalue.UnmarshalMap(item, &profile); err != nil {
+		return nil, fmt.Errorf("failed to parse profile: %w", err)
+	}
+
+	items, err := s.Dynamo.QueryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.AccountActionsTable),
+		KeyConditionExpression: aws.String("userhandle = :handle"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":handle": &types.AttributeValueMemberS{Value: userHandle},
+		},
+		ScanIndexForward: aws.Bool(false),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load account actions: %w", err)
+	}
+	var actions []models.AccountAction
+	if err := attributevalue.UnmarshalListOfMaps(items, &actions); err != nil {
+		return nil, fmt.Errorf("failed to parse account actions: %w", err)
+	}
+	standing := &models.AccountStanding{Status: models.AccountStatusActive, Actions: []models.AccountAction{}}
+	for _, action := range actions {
+		if action.IsShadow() {
+			continue
+		}
+		action.IssuedBy = ""
+		standing.Actions = append(standing.Actions, action)
+	}
+
+	switch profile.AccountRestriction(time.Now()) {
+	case models.AccountStatusSuspended:
+		standing.Status = models.AccountStatusSuspended
+		standing.SuspendedUntil = profile.SuspendedUntil
+	case models.AccountStatusBanned:
+		standing.Status = models.AccountStatusBanned
+	}
+	return standing, nil
+}
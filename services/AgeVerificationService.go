@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// AgeVerificationService handles disputed ages. A disputed profile is hidden from discovery until
+// the user uploads an ID document and an admin reviews it; a rejected profile stays blocked.
+// Documents are deleted from S3 as soon as they are reviewed.
+type AgeVerificationService struct {
+	Dynamo *DynamoService
+	S3     *S3Service
+}
+
+// DisputeAge opens a dispute (admin or trust & safety), hiding the profile until it is resolved.
+// A user already confirmed adult can be disputed again.
+func (s *AgeVerificationService) DisputeAge(ctx context.Context, userHandle, reason, reportedBy string) (*models.AgeVerification, error) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	verification := models.AgeVerification{
+		UserHandle: userHandle,
+		Status:     models.AgeVerificationDisputed,
+		Reason:     reason,
+		ReportedBy: reportedBy,
+		CreatedAt:  now,
+	}
+	item, err := attributevalue.MarshalMap(verification)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.Dynamo.TransactWriteItems(ctx, []types.TransactWriteItem{
+		s.profileAgeStatusUpdate(userHandle, models.AgeVerificationDisputed, now, nil),
+		{
+			Put: &types.Put{
+				TableName:           aws.String(models.AgeVerificationsTable),
+				Item:                item,
+				ConditionExpression: aws.String("attribute_not_exists(userhandle) OR #status = :approved"),
+				ExpressionAttributeNames: map[string]string{
+					"#status": "status",
+				},
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":approved": &types.AttributeValueMemberS{Value: models.AgeVerificationApproved},
+				},
+			},
+		},
+	})
+	if err := ageTransactionFailure(err, "profile not found", "age is already disputed"); err != nil {
+		return nil, err
+	}
+
+	utils.Logf(ctx, "🚩 Age of %s disputed by %s", userHandle, reportedBy)
+	return &verification, nil
+}
+
+// SubmitDocument attaches an uploaded ID document (an image under the user's upload prefix) to an
+// open dispute and queues it for review
+func (s *AgeVerificationService) SubmitDocument(ctx context.Context, userHandle, documentKey string) (*models.AgeVerification, error) {
+	if !models.OwnsUploadKey(userHandle, documentKey) {
+		return nil, validationError("documentKey was not issued to this user")
+	}
+	size, contentType, err := s.S3.HeadObject(ctx, documentKey)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := models.ImageUploadTypes[contentType]; !ok {
+		return nil, validationError(fmt.Sprintf("upload type %q is not an allowed image type", contentType))
+	}
+	if size > models.MaxImageUploadBytes {
+		return nil, validationError(fmt.Sprintf("document must be at most %d bytes", models.MaxImageUploadBytes))
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	err = s.Dynamo.TransactWriteItems(ctx, []types.TransactWriteItem{
+		s.profileAgeStatusUpdate(userHandle, models.AgeVerificationPending, now, nil),
+		{
+			Update: &types.Update{
+				TableName:           aws.String(models.AgeVerificationsTable),
+				Key:                 ageVerificationKey(userHandle),
+				UpdateExpression:    aws.String("SET #status = :pending, documentKey = :documentKey, submittedAt = :now"),
+				ConditionExpression: aws.String("#status = :disputed"),
+				ExpressionAttributeNames: map[string]string{
+					"#status": "status",
+				},
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":pending":     &types.AttributeValueMemberS{Value: models.AgeVerificationPending},
+					":disputed":    &types.AttributeValueMemberS{Value: models.AgeVerificationDisputed},
+					":documentKey": &types.AttributeValueMemberS{Value: documentKey},
+					":now":         &types.AttributeValueMemberS{Value: now},
+				},
+			},
+		},
+	})
+	if err := ageTransactionFailure(err, "profile not found", "no open age dispute awaits a document"); err != nil {
+		return nil, err
+	}
+
+	utils.Logf(ctx, "📄 %s submitted an age document for review", userHandle)
+	return s.GetVerification(ctx, userHandle)
+}
+
+// GetVerification returns the user's latest age dispute
+func (s *AgeVerificationService) GetVerification(ctx context.Context, userHandle string) (*models.AgeVerification, error) {
+	item, err := s.Dynamo.GetItem(ctx, models.AgeVerificationsTable, ageVerificationKey(userHandle))
+	if errors.Is(err, ErrNotFound) {
+		return nil, notFoundError("no age verification for this user")
+	}
+	if err != nil {
+		return nil, err
+	}
+	var verification models.AgeVerification
+	if err := attributevalue.UnmarshalMap(item, &verification); err != nil {
+		return nil, fmt.Errorf("failed to parse age verification: %w", err)
+	}
+	return &verification, nil
+}
+
+// ListVerifications returns one page of verifications in the given status, oldest first
+func (s *AgeVerificationService) ListVerifications(ctx context.Context, status string, limit int32, cursor string) ([]models.AgeVerification, string, error) {
+	items, nextCursor, err := s.Dynamo.QueryPage(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.AgeVerificationsTable),
+		IndexName:              aws.String(models.AgeVerificationStatusIndex),
+		KeyConditionExpression: aws.String("#status = :status"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":status": &types.AttributeValueMemberS{Value: status},
+		},
+	}, limit, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	verifications := []models.AgeVerification{}
+	if err := attributevalue.UnmarshalListOfMaps(items, &verifications); err != nil {
+		return nil, "", fmt.Errorf("failed to parse age verifications: %w", err)
+	}
+	return verifications, nextCursor, nil
+}
+
+// ResolveVerification records an admin's decision on a submitted document. Approval makes the
+// profile visible again, correcting the date of birth when dob is given (the caller validates
+// it); rejection blocks the account. The document is deleted either way.
+func (s *AgeVerificationService) ResolveVerification(ctx context.Context, userHandle, decision, reviewedBy, dob string) (*models.AgeVerification, error) {
+	if decision != models.AgeVerificationApproved && decision != models.AgeVerificationRejected {
+		return nil, validationError("decision must be approved or rejected")
+	}
+	verification, err := s.GetVerification(ctx, userHandle)
+	if err != nil {
+		return nil, err
+	}
+	if verification.Status != models.AgeVerificationPending {
+		return nil, conflictError("no submitted document awaits review")
+	}
+
+	now := time.Now().UTC()
+	var profileUpdate types.TransactWriteItem
+	if decision == models.AgeVerificationApproved {
+		var dobValues map[string]types.AttributeValue
+		if dob != "" {
+			born, err := utils.ParseDOB(dob)
+			if err != nil {
+				return nil, validationError("dob must be a YYYY-MM-DD date")
+			}
+			dobValues = map[string]types.AttributeValue{
+				":dob": &types.AttributeValueMemberS{Value: dob},
+				":age": &types.AttributeValueMemberN{Value: strconv.Itoa(utils.AgeOn(born, now))},
+			}
+		}
+		profileUpdate = s.profileAgeStatusUpdate(userHandle, "", now.Format(time.RFC3339), dobValues)
+	} else {
+		profileUpdate = s.profileAgeStatusUpdate(userHandle, models.AgeVerificationRejected, now.Format(time.RFC3339), nil)
+	}
+
+	verification.Status = decision
+	verification.ReviewedAt = now.Format(time.RFC3339)
+	verification.ReviewedBy = reviewedBy
+	documentKey := verification.DocumentKey
+	verification.DocumentKey = ""
+	err = s.Dynamo.TransactWriteItems(ctx, []types.TransactWriteItem{
+		profileUpdate,
+		{
+			Update: &types.Update{
+				TableName:           aws.String(models.AgeVerificationsTable),
+				Key:                 ageVerificationKey(userHandle),
+				UpdateExpression:    aws.String("SET #status = :decision, reviewedAt = :now, reviewedBy = :reviewedBy REMOVE documentKey"),
+				ConditionExpression: aws.String("#status = :pending"),
+				ExpressionAttributeNames: map[string]string{
+					"#status": "status",
+				},
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":decision":   &types.AttributeValueMemberS{Value: decision},
+					":pending":    &types.AttributeValueMemberS{Value: models.AgeVerificationPending},
+					":now":        &types.AttributeValueMemberS{Value: verification.ReviewedAt},
+					":reviewedBy": &types.AttributeValueMemberS{Value: reviewedBy},
+				},
+			},
+		},
+	})
+	if err := ageTransactionFailure(err, "profile not found", "age verification was already resolved"); err != nil {
+		return nil, err
+	}
+
+	// ✅ ID documents are not kept once reviewed
+	if documentKey != "" {
+		if err := s.S3.DeleteObjects(ctx, []string{documentKey}); err != nil {
+			utils.Logf(ctx, "⚠️ Failed to delete age document of %s: %v", userHandle, err)
+		}
+	}
+
+	utils.Logf(ctx, "✅ Age verification of %s %s by %s", userHandle, decision, reviewedBy)
+	return verification, nil
+}
+
+// profileAgeStatusUpdate sets the profile's ageStatus (removing it when empty) on an existing
+// profile, optionally with a corrected dob/age (values :dob and :age)
+func (s *AgeVerificationService) profileAgeStatusUpdate(userHandle, ageStatus, now string, dobValues map[string]types.AttributeValue) types.TransactWriteItem {
+	values := map[string]types.AttributeValue{
+		":now": &types.AttributeValueMemberS{Value: now},
+		":one": &types.AttributeValueMemberN{Value: "1"},
+	}
+	set := "SET updatedAt = :now"
+	if ageStatus != "" {
+		set += ", ageStatus = :ageStatus"
+		values[":ageStatus"] = &types.AttributeValueMemberS{Value: ageStatus}
+	}
+	if dobValues != nil {
+		set += ", dob = :dob, age = :age"
+		for name, value := range dobValues {
+			values[name] = value
+		}
+	}
+	update := set + " ADD profileVersion :one"
+	if ageStatus == "" {
+		update = "REMOVE ageStatus " + update
+	}
+
+	return types.TransactWriteItem{
+		Update: &types.Update{
+			TableName:                 aws.String(models.UserProfilesTable),
+			Key:                       profileKey(userHandle),
+			UpdateExpression:          aws.String(update),
+			ConditionExpression:       aws.String("attribute_exists(userhandle)"),
+			ExpressionAttributeValues: values,
+		},
+	}
+}
+
+// ageTransactionFailure maps the cancellation reasons of a profile-then-verification transaction
+func ageTransactionFailure(err error, profileMessage, verificationMessage string) error {
+	if err == nil {
+		return nil
+	}
+	var cancelled *types.TransactionCanceledException
+	if errors.As(err, &cancelled) {
+		reasons := cancelled.CancellationReasons
+		if len(reasons) > 0 && aws.ToString(reasons[0].Code) == "ConditionalCheckFailed" {
+			return notFoundError(profileMessage)
+		}
+		if len(reasons) > 1 && aws.ToString(reasons[1].Code) == "ConditionalCheckFailed" {
+			return conflictError(verificationMessage)
+		}
+	}
+	return fmt.Errorf("age verification update failed: %w", err)
+}
+
+// ageVerificationKey builds the AgeVerifications primary key
+func ageVerificationKey(userHandle string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"userhandle": &types.AttributeValueMemberS{Value: userHandle},
+	}
+}
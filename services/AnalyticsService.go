@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
+)
+
+// analyticsDayFormat is the day suffix of an event partition
+const analyticsDayFormat = "2006-01-02"
+
+// analyticsMaxRangeDays caps the counts API (one query per event type per day)
+const analyticsMaxRangeDays = 92
+
+// analyticsCountConcurrency bounds the parallel count queries
+const analyticsCountConcurrency = 8
+
+// AnalyticsService records server-side funnel events and answers aggregate counts
+type AnalyticsService struct {
+	Dynamo             *DynamoService
+	UserProfileService *UserProfileService
+	Jobs               *JobQueue // ✅ Set by RegisterJobs; events are written inline without it
+}
+
+// Track records one funnel event. It never fails the caller: with a job queue the write happens in
+// the background, users who object to analytics are skipped and write errors are only logged.
+func (s *AnalyticsService) Track(ctx context.Context, eventType, userHandle string, properties map[string]string) {
+	if s == nil {
+		return
+	}
+	job := models.TrackEventJob{
+		EventType:  eventType,
+		UserHandle: userHandle,
+		Properties: properties,
+		EventID:    uuid.New().String(),
+		OccurredAt: time.Now().UTC().Format(time.RFC3339Nano),
+	}
+	if s.Jobs != nil {
+		s.Jobs.Enqueue(ctx, models.JobTrackEvent, job)
+		return
+	}
+	if err := s.record(ctx, job); err != nil {
+		utils.Logf(ctx, "⚠️ Failed to track %s for %s: %v", eventType, userHandle, err)
+	}
+}
+
+// RegisterJobs routes Track through the job queue
+func (s *AnalyticsService) RegisterJobs(queue *JobQueue) {
+	s.Jobs = queue
+	queue.Handle(models.JobTrackEvent, func(ctx context.Context, payload json.RawMessage) error {
+		job, err := decodeJob[models.TrackEventJob](payload)
+		if err != nil {
+			return err
+		}
+		return s.record(ctx, job)
+	})
+}
+
+// record stores one funnel event unless the user objects to analytics
+func (s *AnalyticsService) record(ctx context.Context, job models.TrackEventJob) error {
+	if !s.UserProfileService.AllowsProcessing(ctx, job.UserHandle, models.PurposeAnalytics) {
+		utils.Logf(ctx, "ℹ️ Not tracking %s for %s: analytics objection", job.EventType, job.UserHandle)
+		return nil
+	}
+
+	// ✅ The ID and time come from Track, so a retried job overwrites the same item
+	now, err := time.Parse(time.RFC3339Nano, job.OccurredAt)
+	if err != nil {
+		now = time.Now().UTC() // Queued before events carried their time
+	}
+	eventID := job.EventID
+	if eventID == "" {
+		eventID = uuid.New().String()
+	}
+	event := models.AnalyticsEvent{
+		Bucket:     analyticsBucket(job.EventType, now),
+		EventKey:   now.Format(time.RFC3339Nano) + "#" + eventID,
+		EventID:    eventID,
+		EventType:  job.EventType,
+		UserHandle: job.UserHandle,
+		Properties: job.Properties,
+		CreatedAt:  now.Format(time.RFC3339),
+		ExpiresAt:  now.AddDate(0, 0, models.AnalyticsRetentionDays).Unix(),
+	}
+	return s.Dynamo.PutItem(ctx, models.AnalyticsEventsTable, event)
+}
+
+// CountEvents counts events per UTC day between from and to (inclusive) for the given event types
+func (s *AnalyticsService) CountEvents(ctx context.Context, eventTypes []string, from, to time.Time) (*models.AnalyticsCounts, error) {
+	from, to = from.UTC().Truncate(24*time.Hour), to.UTC().Truncate(24*time.Hour)
+	if to.Before(from) {
+		return nil, validationError("to must not be before from")
+	}
+	days := int(to.Sub(from).Hours()/24) + 1
+	if days > analyticsMaxRangeDays {
+		return nil, validationError(fmt.Sprintf("range must not exceed %d days", analyticsMaxRangeDays))
+	}
+	if len(eventTypes) == 0 {
+		eventTypes = models.AnalyticsEventTypes
+	}
+
+	utils.Logf(ctx, "🔍 Counting %v events from %s to %s", eventTypes, from.Format(analyticsDayFormat), to.Format(analyticsDayFormat))
+
+	counts := make([][]int, len(eventTypes))
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(analyticsCountConcurrency)
+	for i, eventType := range eventTypes {
+		counts[i] = make([]int, days)
+		for day := 0; day < days; day++ {
+			group.Go(func() error {
+				bucket := analyticsBucket(eventType, from.AddDate(0, 0, day))
+				count, err := s.Dynamo.CountItems(groupCtx, &dynamodb.QueryInput{
+					TableName:              aws.String(models.AnalyticsEventsTable),
+					KeyConditionExpression: aws.String("bucket = :bucket"),
+					ExpressionAttributeValues: map[string]types.AttributeValue{
+						":bucket": &types.AttributeValueMemberS{Value: bucket},
+					},
+				})
+				if err != nil {
+					return fmt.Errorf("failed to count %s: %w", bucket, err)
+				}
+				counts[i][day] = count
+				return nil
+			})
+		}
+	}
+	if err := group.Wait(); err != nil {
+		utils.Logf(ctx, "❌ Error counting analytics events: %v", err)
+		return nil, err
+	}
+
+	result := &models.AnalyticsCounts{
+		From:   from.Format(analyticsDayFormat),
+		To:     to.Format(analyticsDayFormat),
+		Daily:  map[string]map[string]int{},
+		Totals: map[string]int{},
+	}
+	for i, eventType := range eventTypes {
+		result.Daily[eventType] = map[string]int{}
+		for day, count := range counts[i] {
+			result.Daily[eventType][from.AddDate(0, 0, day).Format(analyticsDayFormat)] = count
+			result.Totals[eventType] += count
+		}
+	}
+	return result, nil
+}
+
+// IsAnalyticsEventType reports whether eventType is one the server records
+func IsAnalyticsEventType(eventType string) bool {
+	for _, known := range models.AnalyticsEventTypes {
+		if known == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// analyticsBucket builds the partition key for an event type on a UTC day
+func analyticsBucket(eventType string, day time.Time) string {
+	return eventType + "#" + day.UTC().Format(analyticsDayFormat)
+}
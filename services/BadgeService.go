@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// BadgeService gathers the tab badge counts, so the app needs one round trip instead of four
+type BadgeService struct {
+	Chat        *ChatService
+	GroupChat   *GroupChatService
+	Interaction *InteractionService
+	Groups      *GroupInteractionService
+}
+
+// GetBadgeCounts counts the user's unread messages, new likes, pending pings and pending group
+// approvals concurrently
+func (s *BadgeService) GetBadgeCounts(ctx context.Context, userHandle string) (*models.BadgeCounts, error) {
+	utils.Logf(ctx, "🔍 Counting badges for user: %s", userHandle)
+
+	var badges models.BadgeCounts
+	var matchUnread, groupUnread *models.UnreadCounts
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.Go(func() (err error) {
+		matchUnread, err = s.Chat.GetUnreadCounts(groupCtx, userHandle)
+		return err
+	})
+	group.Go(func() (err error) {
+		groupUnread, err = s.GroupChat.GetUnreadCounts(groupCtx, userHandle)
+		return err
+	})
+	group.Go(func() (err error) {
+		badges.NewLikes, badges.PendingPings, err = s.Interaction.CountPendingReceived(groupCtx, userHandle)
+		return err
+	})
+	group.Go(func() (err error) {
+		badges.PendingGroupApprovals, err = s.Groups.CountPendingApprovals(groupCtx, userHandle)
+		return err
+	})
+	if err := group.Wait(); err != nil {
+		utils.Logf(ctx, "❌ Error counting badges for %s: %v", userHandle, err)
+		return nil, err
+	}
+	badges.UnreadMessages = matchUnread.Total + groupUnread.Total
+
+	utils.Logf(ctx, "✅ Badges for %s: %+v", userHandle, badges)
+	return &badges, nil
+}
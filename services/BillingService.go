@@ -0,0 +1,381 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ErrBillingNotConfigured is returned when the Stripe keys or price are not set
+var ErrBillingNotConfigured = errors.New("billing_not_configured")
+
+// ErrInvalidWebhookSignature is returned when a webhook's Stripe-Signature header doesn't verify
+var ErrInvalidWebhookSignature = errors.New("invalid_webhook_signature")
+
+// ErrPremiumRequired is returned by gated features when the user lacks the entitlement
+var ErrPremiumRequired = errors.New("premium_required")
+
+// stripeAPIBase is the Stripe REST endpoint used for checkout sessions
+const stripeAPIBase = "https://api.stripe.com/v1"
+
+// webhookTolerance is how old a signed webhook timestamp may be before it's rejected as a replay
+const webhookTolerance = 5 * time.Minute
+
+// BillingService creates Stripe checkout sessions and applies Stripe webhooks to user subscriptions
+type BillingService struct {
+	Dynamo             *DynamoService
+	UserProfileService *UserProfileService
+	StripeSecretKey    string
+	WebhookSecret      string
+	PremiumPriceID     string
+	HTTPClient         *http.Client
+}
+
+// Enabled reports whether Stripe is configured
+func (s *BillingService) Enabled() bool {
+	return s != nil && s.StripeSecretKey != "" && s.WebhookSecret != "" && s.PremiumPriceID != ""
+}
+
+// stripeEvent is the envelope of a Stripe webhook
+type stripeEvent struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Created int64  `json:"created"`
+	Data    struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+// stripeCheckoutSession holds the checkout.session fields we use
+type stripeCheckoutSession struct {
+	ID                string            `json:"id"`
+	URL               string            `json:"url"`
+	ClientReferenceID string            `json:"client_reference_id"`
+	Customer          string            `json:"customer"`
+	Subscription      string            `json:"subscription"`
+	Metadata          map[string]string `json:"metadata"`
+}
+
+// stripeSubscription holds the subscription fields we use
+type stripeSubscription struct {
+	ID                string            `json:"id"`
+	Customer          string            `json:"customer"`
+	Status            string            `json:"status"`
+	CurrentPeriodEnd  int64             `json:"current_period_end"`
+	CancelAtPeriodEnd bool              `json:"cancel_at_period_end"`
+	Metadata          map[string]string `json:"metadata"`
+}
+
+// CreateCheckoutSession starts a Stripe Checkout for the premium plan and returns its URL
+func (s *BillingService) CreateCheckoutSession(ctx context.Context, userHandle, successURL, cancelURL string) (string, error) {
+	if !s.Enabled() {
+		return "", ErrBillingNotConfigured
+	}
+	utils.Logf(ctx, "🔍 Creating checkout session for user: %s", userHandle)
+
+	profile, err := s.UserProfileService.GetUserProfileByHandle(ctx, userHandle)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("mode", "subscription")
+	form.Set("line_items[0][price]", s.PremiumPriceID)
+	form.Set("line_items[0][quantity]", "1")
+	form.Set("success_url", successURL)
+	form.Set("cancel_url", cancelURL)
+	form.Set("client_reference_id", userHandle)
+	form.Set("metadata[userhandle]", userHandle)
+	form.Set("subscription_data[metadata][userhandle]", userHandle) // ✅ Lets subscription events find the user
+	if sub := profile.EffectiveSubscription(); sub.StripeCustomerID != "" {
+		form.Set("customer", sub.StripeCustomerID)
+	}
+
+	var session stripeCheckoutSession
+	if err := s.stripePost(ctx, "/checkout/sessions", form, &session); err != nil {
+		utils.Logf(ctx, "❌ Failed to create checkout session for %s: %v", userHandle, err)
+		return "", err
+	}
+
+	utils.Logf(ctx, "✅ Checkout session %s created for %s", session.ID, userHandle)
+	return session.URL, nil
+}
+
+// HandleWebhook verifies a Stripe webhook and applies it to the user's subscription
+func (s *BillingService) HandleWebhook(ctx context.Context, payload []byte, signatureHeader string) error {
+	if !s.Enabled() {
+		return ErrBillingNotConfigured
+	}
+	if err := verifyStripeSignature(payload, signatureHeader, s.WebhookSecret, time.Now()); err != nil {
+		utils.Logf(ctx, "🚫 Rejected Stripe webhook: %v", err)
+		return ErrInvalidWebhookSignature
+	}
+
+	var event stripeEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return fmt.Errorf("failed to parse webhook event: %w", err)
+	}
+	utils.Logf(ctx, "📩 Stripe webhook %s (%s)", event.ID, event.Type)
+
+	switch event.Type {
+	case "checkout.session.completed":
+		var session stripeCheckoutSession
+		if err := json.Unmarshal(event.Data.Object, &session); err != nil {
+			return fmt.Errorf("failed to parse checkout session: %w", err)
+		}
+		userHandle := session.ClientReferenceID
+		if userHandle == "" {
+			userHandle = session.Metadata["userhandle"]
+		}
+		return s.applySubscription(ctx, userHandle, event.Created, func(sub *models.Subscription) {
+			sub.Plan = models.PlanPremium
+			sub.Status = "active"
+			sub.StripeCustomerID = session.Customer
+			sub.StripeSubscriptionID = session.Subscription
+		})
+
+	case "customer.subscription.created", "customer.subscription.updated", "customer.subscription.deleted":
+		var stripeSub stripeSubscription
+		if err := json.Unmarshal(event.Data.Object, &stripeSub); err != nil {
+			return fmt.Errorf("failed to parse subscription: %w", err)
+		}
+		return s.applySubscription(ctx, stripeSub.Metadata["userhandle"], event.Created, func(sub *models.Subscription) {
+			sub.Plan = models.PlanPremium
+			sub.Status = stripeSub.Status
+			sub.StripeCustomerID = stripeSub.Customer
+			sub.StripeSubscriptionID = stripeSub.ID
+			sub.CancelAtPeriodEnd = stripeSub.CancelAtPeriodEnd
+			if stripeSub.CurrentPeriodEnd > 0 {
+				sub.CurrentPeriodEnd = time.Unix(stripeSub.CurrentPeriodEnd, 0).UTC().Format(time.RFC3339)
+			}
+			if event.Type == "customer.subscription.deleted" {
+				sub.Plan = models.PlanFree
+				sub.Status = "canceled"
+			}
+		})
+	}
+
+	utils.Logf(ctx, "ℹ️ Ignoring Stripe event type %s", event.Type)
+	return nil
+}
+
+// GetSubscription returns the user's subscription, or the free plan if they never subscribed
+func (s *BillingService) GetSubscription(ctx context.Context, userHandle string) (*models.Subscription, error) {
+	profile, err := s.UserProfileService.GetUserProfileByHandle(ctx, userHandle)
+	if err != nil {
+		return nil, err
+	}
+	sub := profile.EffectiveSubscription()
+	return &sub, nil
+}
+
+// HasEntitlement is the gate premium features check. Without billing configured nobody could buy
+// premium, so the gates are open; otherwise lookup failures deny the feature.
+func (s *BillingService) HasEntitlement(ctx context.Context, userHandle, entitlement string) bool {
+	if !s.Enabled() {
+		return true
+	}
+	sub, err := s.GetSubscription(ctx, userHandle)
+	if err != nil {
+		utils.Logf(ctx, "⚠️ Could not load subscription for %s, denying %s: %v", userHandle, entitlement, err)
+		return false
+	}
+	return sub.Has(entitlement)
+}
+
+// applySubscription loads the user's subscription, applies the change and stores it, skipping
+// events older than the last one applied (Stripe does not guarantee delivery order)
+func (s *BillingService) applySubscription(ctx context.Context, userHandle string, eventCreated int64, change func(*models.Subscription)) error {
+	if userHandle == "" {
+		utils.Logf(ctx, "⚠️ Stripe event has no userhandle; ignoring")
+		return nil
+	}
+
+	sub, err := s.GetSubscription(ctx, userHandle)
+	if err != nil {
+		return fmt.Errorf("failed to load subscription for %s: %w", userHandle, err)
+	}
+	if eventCreated < sub.LastEventCreated {
+		utils.Logf(ctx, "ℹ️ Skipping stale Stripe event for %s", userHandle)
+		return nil
+	}
+
+	change(sub)
+	sub.Entitlements = models.PlanEntitlements(sub.Plan)
+	sub.LastEventCreated = eventCreated
+	return s.storeSubscription(ctx, userHandle, sub)
+}
+
+// PremiumGrantUpdate builds the profile write that extends the user's promo premium by days
+// (stacking on a grant that is still running) without touching the Stripe-managed fields. The
+// update only sets subscription.promoPremiumUntil, conditioned on the value it was computed from,
+// so callers can put it in a transaction and retry when another grant got there first.
+func (s *BillingService) PremiumGrantUpdate(ctx context.Context, userHandle string, days int) (*models.Subscription, *types.Update, error) {
+	profile, err := s.UserProfileService.GetUserProfileByHandle(ctx, userHandle)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load subscription for %s: %w", userHandle, err)
+	}
+	sub := profile.EffectiveSubscription()
+	previous := sub.PromoPremiumUntil
+
+	start := time.Now().UTC()
+	if sub.HasPromoPremium() {
+		start, _ = time.Parse(time.RFC3339, sub.PromoPremiumUntil)
+	}
+	sub.PromoPremiumUntil = start.AddDate(0, 0, days).UTC().Format(time.RFC3339)
+	sub.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	update := &types.Update{
+		TableName: aws.String(models.UserProfilesTable),
+		Key: map[string]types.AttributeValue{
+			"userhandle": &types.AttributeValueMemberS{Value: userHandle},
+		},
+		ExpressionAttributeNames: map[string]string{
+			"#subscription": "subscription",
+		},
+	}
+	if profile.Subscription == nil {
+		// ✅ Free users have no subscription map yet, so the grant writes the whole map
+		subAV, err := attributevalue.Marshal(sub)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal subscription: %w", err)
+		}
+		update.UpdateExpression = aws.String("SET #subscription = :subscription")
+		update.ConditionExpression = aws.String("attribute_exists(userhandle) AND attribute_not_exists(#subscription)")
+		update.ExpressionAttributeValues = map[string]types.AttributeValue{":subscription": subAV}
+		return &sub, update, nil
+	}
+
+	update.UpdateExpression = aws.String("SET #subscription.#until = :until, #subscription.#updatedAt = :updatedAt")
+	update.ExpressionAttributeNames["#until"] = "promoPremiumUntil"
+	update.ExpressionAttributeNames["#updatedAt"] = "updatedAt"
+	update.ExpressionAttributeValues = map[string]types.AttributeValue{
+		":until":     &types.AttributeValueMemberS{Value: sub.PromoPremiumUntil},
+		":updatedAt": &types.AttributeValueMemberS{Value: sub.UpdatedAt},
+	}
+	if previous == "" {
+		update.ConditionExpression = aws.String("attribute_exists(#subscription) AND attribute_not_exists(#subscription.#until)")
+	} else {
+		update.ConditionExpression = aws.String("#subscription.#until = :previous")
+		update.ExpressionAttributeValues[":previous"] = &types.AttributeValueMemberS{Value: previous}
+	}
+	return &sub, update, nil
+}
+
+// storeSubscription writes the whole subscription map onto the user profile
+func (s *BillingService) storeSubscription(ctx context.Context, userHandle string, sub *models.Subscription) error {
+	sub.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	subAV, err := attributevalue.Marshal(sub)
+	if err != nil {
+		return fmt.Errorf("failed to marshal subscription: %w", err)
+	}
+	key := map[string]types.AttributeValue{
+		"userhandle": &types.AttributeValueMemberS{Value: userHandle},
+	}
+	_, err = s.Dynamo.UpdateItem(ctx, models.UserProfilesTable, "SET #subscription = :subscription", key,
+		map[string]types.AttributeValue{":subscription": subAV},
+		map[string]string{"#subscription": "subscription"},
+	)
+	if err != nil {
+		utils.Logf(ctx, "❌ Error storing subscription for %s: %v", userHandle, err)
+		return fmt.Errorf("failed to update subscription: %w", err)
+	}
+
+	utils.Logf(ctx, "✅ Subscription for %s is now %s (%s)", userHandle, sub.Plan, sub.Status)
+	return nil
+}
+
+// stripePost sends a form-encoded request to the Stripe API and decodes the JSON response
+func (s *BillingService) stripePost(ctx context.Context, path string, form url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, stripeAPIBase+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(s.StripeSecretKey, "")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := s.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("stripe request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read stripe response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		var stripeErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		_ = json.Unmarshal(body, &stripeErr)
+		return fmt.Errorf("stripe returned %d: %s", resp.StatusCode, stripeErr.Error.Message)
+	}
+	return json.Unmarshal(body, out)
+}
+
+// verifyStripeSignature checks the Stripe-Signature header ("t=<unix>,v1=<hex hmac>,...")
+// against HMAC-SHA256(secret, "<t>.<payload>")
+func verifyStripeSignature(payload []byte, header, secret string, now time.Time) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return errors.New("missing timestamp or signature")
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("invalid timestamp")
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > webhookTolerance || age < -webhookTolerance {
+		return errors.New("timestamp outside tolerance")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	expected := mac.Sum(nil)
+
+	for _, signature := range signatures {
+		decoded, err := hex.DecodeString(signature)
+		if err == nil && hmac.Equal(decoded, expected) {
+			return nil
+		}
+	}
+	return errors.New("no matching signature")
+}
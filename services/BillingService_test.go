@@ -0,0 +1,60 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// stripeV1 is the hex HMAC-SHA256 Stripe sends as v1 for a payload signed at timestamp
+func stripeV1(payload, secret string, timestamp int64) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "." + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyStripeSignature(t *testing.T) {
+	const payload = `{"id":"evt_1","type":"customer.subscription.updated"}`
+	const secret = "whsec_test"
+	now := time.Unix(1_700_000_000, 0)
+	signedAt := now.Unix()
+	valid := stripeV1(payload, secret, signedAt)
+	ts := func(seconds int64) string { return "t=" + strconv.FormatInt(seconds, 10) }
+
+	tests := []struct {
+		name    string
+		payload string
+		header  string
+		wantErr bool
+	}{
+		{"valid", payload, ts(signedAt) + ",v1=" + valid, false},
+		{"spaces around parts", payload, ts(signedAt) + ", v1=" + valid, false},
+		{"v0 ignored", payload, ts(signedAt) + ",v0=deadbeef,v1=" + valid, false},
+		{"second v1 matches", payload, ts(signedAt) + ",v1=" + stripeV1(payload, "whsec_old", signedAt) + ",v1=" + valid, false},
+		{"first v1 matches", payload, ts(signedAt) + ",v1=" + valid + ",v1=" + stripeV1(payload, "whsec_old", signedAt), false},
+		{"malformed v1 before a match", payload, ts(signedAt) + ",v1=not-hex,v1=" + valid, false},
+		{"no v1 matches", payload, ts(signedAt) + ",v1=" + stripeV1(payload, "whsec_old", signedAt) + ",v1=00ff", true},
+		{"wrong secret", payload, ts(signedAt) + ",v1=" + stripeV1(payload, "whsec_other", signedAt), true},
+		{"tampered payload", `{"id":"evt_2"}`, ts(signedAt) + ",v1=" + valid, true},
+		{"signature for another timestamp", payload, ts(signedAt-1) + ",v1=" + valid, true},
+		{"at the tolerance in the past", payload, ts(signedAt-300) + ",v1=" + stripeV1(payload, secret, signedAt-300), false},
+		{"past the tolerance", payload, ts(signedAt-301) + ",v1=" + stripeV1(payload, secret, signedAt-301), true},
+		{"at the tolerance in the future", payload, ts(signedAt+300) + ",v1=" + stripeV1(payload, secret, signedAt+300), false},
+		{"beyond the tolerance in the future", payload, ts(signedAt+301) + ",v1=" + stripeV1(payload, secret, signedAt+301), true},
+		{"missing timestamp", payload, "v1=" + valid, true},
+		{"missing v1", payload, ts(signedAt), true},
+		{"non-numeric timestamp", payload, "t=soon,v1=" + valid, true},
+		{"empty header", payload, "", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := verifyStripeSignature([]byte(test.payload), test.header, secret, now)
+			if (err != nil) != test.wantErr {
+				t.Errorf("verifyStripeSignature(%q) error = %v, want error %v", test.header, err, test.wantErr)
+			}
+		})
+	}
+}
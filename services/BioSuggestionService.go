@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"vibin_server/models"
+	"vibin_server/utils"
+)
+
+// ErrBioSuggestionsUnavailable is returned when no LLM is configured
+var ErrBioSuggestionsUnavailable = errors.New("bio_suggestions_unavailable")
+
+// bioSystemPrompt tells the model what to write and how to answer
+const bioSystemPrompt = `You help people write the bio on their dating profile. You get their interests and a few keywords as JSON.
+Write 3 distinct bios in the first person, warm and specific, each in a different tone (playful, sincere, short and punchy). Use only what you were given; don't invent jobs, places or facts.
+Nothing sexual, no profanity, at most 500 characters each.
+Answer with a JSON array of strings and nothing else.`
+
+// BioSuggestionService drafts profile bios from interests and keywords. Requests count against a
+// daily quota, and drafts (and the terms they're written from) are screened by the moderation rules.
+type BioSuggestionService struct {
+	Dynamo     *DynamoService
+	Moderation *ModerationService
+	LLM        LLMProvider // ✅ nil when no provider is configured
+}
+
+// SuggestBios returns up to BioDrafts drafts written from the request's interests and keywords
+func (s *BioSuggestionService) SuggestBios(ctx context.Context, request models.BioSuggestionRequest) (*models.BioSuggestions, error) {
+	if s.LLM == nil {
+		return nil, ErrBioSuggestionsUnavailable
+	}
+	for _, term := range append(append([]string{}, request.Interests...), request.Keywords...) {
+		if err := s.Moderation.CheckContent(term); err != nil {
+			return nil, validationError("keywords must follow the content rules")
+		}
+	}
+	if err := reserveDailyQuota(ctx, s.Dynamo, request.UserHandle, models.QuotaDailyBioSuggestions, models.DailyBioSuggestionLimit,
+		fmt.Sprintf("You can ask for bio suggestions %d times per day. Try again tomorrow.", models.DailyBioSuggestionLimit)); err != nil {
+		return nil, err
+	}
+
+	prompt, err := json.Marshal(map[string][]string{"interests": request.Interests, "keywords": request.Keywords})
+	if err != nil {
+		return nil, err
+	}
+	completion, err := s.LLM.Complete(ctx, bioSystemPrompt, string(prompt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate bios: %w", err)
+	}
+	suggestions, err := parseStringArray(completion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse generated bios: %w", err)
+	}
+
+	drafts := []string{}
+	for _, suggestion := range suggestions {
+		draft := strings.TrimSpace(suggestion)
+		if draft == "" || len([]rune(draft)) > models.MaxBioDraftLength {
+			continue
+		}
+		if err := s.Moderation.CheckContent(draft); err != nil {
+			utils.Logf(ctx, "🚫 Dropped a generated bio for %s that failed the content rules", request.UserHandle)
+			continue
+		}
+		drafts = append(drafts, draft)
+		if len(drafts) == models.BioDrafts {
+			break
+		}
+	}
+	if len(drafts) == 0 {
+		return nil, fmt.Errorf("model returned no usable bios")
+	}
+
+	utils.Logf(ctx, "✨ Generated %d bio drafts for %s", len(drafts), request.UserHandle)
+	return &models.BioSuggestions{Drafts: drafts}, nil
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"golang.org/x/sync/errgroup"
+)
+
+// BlockService stores blocks (and the blocks reports make), and builds the set of users a feed must never show
+type BlockService struct {
+	Dynamo   *DynamoService
+	Webhooks *WebhookService
+}
+
+// BlockUser hides target from the user, and the user from target, everywhere
+func (s *BlockService) BlockUser(ctx context.Context, userHandle, target string) (*models.Block, error) {
+	return s.putBlock(ctx, userHandle, target, models.BlockKindBlock, "")
+}
+
+// putBlock stores a block; a report replaces a plain block but a block never downgrades a report
+func (s *BlockService) putBlock(ctx context.Context, userHandle, target, kind, reason string) (*models.Block, error) {
+	if userHandle == target {
+		return nil, validationError("you cannot block yourself")
+	}
+	if _, err := s.Dynamo.GetItemAttributes(ctx, models.UserProfilesTable, profileKey(target), "userhandle"); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, notFoundError("profile not found")
+		}
+		return nil, err
+	}
+
+	block := models.Block{
+		UserHandle:    userHandle,
+		BlockedHandle: target,
+		Kind:          kind,
+		Reason:        reason,
+		CreatedAt:     time.Now().UTC().Format(time.RFC3339),
+	}
+	item, err := attributevalue.MarshalMap(block)
+	if err != nil {
+		return nil, err
+	}
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(models.BlocksTable),
+		Item:      item,
+	}
+	if kind == models.BlockKindBlock {
+		input.ConditionExpression = aws.String("attribute_not_exists(userhandle) OR kind = :block")
+		input.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":block": &types.AttributeValueMemberS{Value: models.BlockKindBlock},
+		}
+	}
+	_, err = s.Dynamo.Client.PutItem(ctx, input)
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return nil, conflictError("user is already reported")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to block %s: %w", target, err)
+	}
+
+	utils.Logf(ctx, "🚫 %s %sed %s", userHandle, kind, target)
+	return &block, nil
+}
+
+// Unblock removes a block or report; reports were already published to trust & safety webhooks
+func (s *BlockService) Unblock(ctx context.Context, userHandle, target string) error {
+	_, err := s.Dynamo.Client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(models.BlocksTable),
+		Key:                 blockKey(userHandle, target),
+		ConditionExpression: aws.String("attribute_exists(userhandle)"),
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return notFoundError("user is not blocked")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to unblock %s: %w", target, err)
+	}
+
+	utils.Logf(ctx, "✅ %s unblocked %s", userHandle, target)
+	return nil
+}
+
+// ListBlocks returns the users the user blocked or reported
+func (s *BlockService) ListBlocks(ctx context.Context, userHandle string) ([]models.Block, error) {
+	items, err := s.Dynamo.QueryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.BlocksTable),
+		KeyConditionExpression: aws.String("userhandle = :handle"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":handle": &types.AttributeValueMemberS{Value: userHandle},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	blocks := []models.Block{}
+	if err := attributevalue.UnmarshalListOfMaps(items, &blocks); err != nil {
+		return nil, fmt.Errorf("failed to parse blocks: %w", err)
+	}
+	return blocks, nil
+}
+
+// IsBlocked reports whether either user blocked the other. Lookup failures count as not blocked,
+// so a DynamoDB hiccup never blocks an interaction.
+func (s *BlockService) IsBlocked(ctx context.Context, userHandle, other string) bool {
+	items, err := s.Dynamo.BatchGetItems(ctx, models.BlocksTable, []map[string]types.AttributeValue{
+		blockKey(userHandle, other),
+		blockKey(other, userHandle),
+	})
+	if err != nil {
+		utils.Logf(ctx, "⚠️ Could not check blocks between %s and %s: %v", userHandle, other, err)
+		return false
+	}
+	return len(items) > 0
+}
+
+// BlockedHandles returns everyone the user blocked or was blocked by
+func (s *BlockService) BlockedHandles(ctx context.Context, userHandle string) (map[string]bool, error) {
+	excluded := make(map[string]bool)
+	if err := s.collect(ctx, excluded, s.blockQueries(userHandle)); err != nil {
+		return nil, err
+	}
+	return excluded, nil
+}
+
+// DiscoveryExclusions is every user suggestions must skip: blocks in either direction, anyone the
+// user already liked, passed on or pinged (including matches and declined pings), and anyone whose
+// like or ping the user matched, declined or rejected. Profiles saved for later stay in the deck.
+func (s *BlockService) DiscoveryExclusions(ctx context.Context, userHandle string) (map[string]bool, error) {
+	queries := append(s.blockQueries(userHandle),
+		exclusionQuery{
+			attribute: "receiverHandle",
+			input: &dynamodb.QueryInput{
+				TableName:              aws.String(models.InteractionsTable),
+				KeyConditionExpression: aws.String("PK = :pk"),
+				FilterExpression:       aws.String("interactionType <> :save"),
+				ProjectionExpression:   aws.String("receiverHandle"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":pk":   &types.AttributeValueMemberS{Value: "USER#" + userHandle},
+					":save": &types.AttributeValueMemberS{Value: models.InteractionTypeSave},
+				},
+			},
+		},
+		exclusionQuery{
+			attribute: "senderHandle",
+			input: &dynamodb.QueryInput{
+				TableName:              aws.String(models.InteractionsTable),
+				IndexName:              aws.String(models.ReceiverHandleIndex),
+				KeyConditionExpression: aws.String("receiverHandle = :handle"),
+				FilterExpression:       aws.String("#status IN (:match, :declined, :rejected)"),
+				ProjectionExpression:   aws.String("senderHandle, #status"),
+				ExpressionAttributeNames: map[string]string{
+					"#status": "status",
+				},
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":handle":   &types.AttributeValueMemberS{Value: userHandle},
+					":match":    &types.AttributeValueMemberS{Value: models.StatusMatch},
+					":declined": &types.AttributeValueMemberS{Value: models.StatusDeclined},
+					":rejected": &types.AttributeValueMemberS{Value: models.StatusRejected},
+				},
+			},
+		},
+	)
+
+	excluded := make(map[string]bool)
+	if err := s.collect(ctx, excluded, queries); err != nil {
+		return nil, err
+	}
+	utils.Logf(ctx, "✅ %d users excluded from discovery for %s", len(excluded), userHandle)
+	return excluded, nil
+}
+
+// exclusionQuery reads the handles in attribute from every item a query returns
+type exclusionQuery struct {
+	input     *dynamodb.QueryInput
+	attribute string
+}
+
+// blockQueries list the users the user blocked and the users who blocked them
+func (s *BlockService) blockQueries(userHandle string) []exclusionQuery {
+	values := map[string]types.AttributeValue{
+		":handle": &types.AttributeValueMemberS{Value: userHandle},
+	}
+	return []exclusionQuery{
+		{
+			attribute: "blockedHandle",
+			input: &dynamodb.QueryInput{
+				TableName:                 aws.String(models.BlocksTable),
+				KeyConditionExpression:    aws.String("userhandle = :handle"),
+				ProjectionExpression:      aws.String("blockedHandle"),
+				ExpressionAttributeValues: values,
+			},
+		},
+		{
+			attribute: "userhandle",
+			input: &dynamodb.QueryInput{
+				TableName:                 aws.String(models.BlocksTable),
+				IndexName:                 aws.String(models.BlockedHandleIndex),
+				KeyConditionExpression:    aws.String("blockedHandle = :handle"),
+				ProjectionExpression:      aws.String("userhandle"),
+				ExpressionAttributeValues: values,
+			},
+		},
+	}
+}
+
+// collect runs the queries concurrently and adds every handle they return to excluded
+func (s *BlockService) collect(ctx context.Context, excluded map[string]bool, queries []exclusionQuery) error {
+	results := make([][]map[string]types.AttributeValue, len(queries))
+	g, gctx := errgroup.WithContext(ctx)
+	for i, query := range queries {
+		g.Go(func() error {
+			items, err := s.Dynamo.QueryAll(gctx, query.input)
+			if err != nil {
+				return fmt.Errorf("failed to query %s: %w", aws.ToString(query.input.TableName), err)
+			}
+			results[i] = items
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	for i, items := range results {
+		for _, item := range items {
+			if handle, ok := item[queries[i].attribute].(*types.AttributeValueMemberS); ok {
+				excluded[handle.Value] = true
+			}
+		}
+	}
+	return nil
+}
+
+// blockKey builds the Blocks primary key
+func blockKey(userHandle, blockedHandle string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"userhandle":    &types.AttributeValueMemberS{Value: userHandle},
+		"blockedHandle": &types.AttributeValueMemberS{Value: blockedHandle},
+	}
+}
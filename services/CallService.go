@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ErrCallsUnavailable is returned when no RTC provider is configured
+var ErrCallsUnavailable = errors.New("calls_unavailable")
+
+// CallService issues short-lived RTC tokens for a match's audio/video call. Only the two matched
+// users get tokens for the match's channel, and every token issued is logged in CallLogTable.
+type CallService struct {
+	Dynamo   *DynamoService
+	Provider RTCTokenProvider // ✅ nil when no provider is configured
+}
+
+// IssueToken returns a token that lets the user join the match's call for CallTokenTTL. Anyone
+// outside the match gets not found.
+func (s *CallService) IssueToken(ctx context.Context, matchID, userHandle string) (*models.CallToken, error) {
+	if s.Provider == nil {
+		return nil, ErrCallsUnavailable
+	}
+	partner, err := findMatchPartner(ctx, s.Dynamo, matchID, userHandle)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	channel := callChannel(matchID)
+	token, err := s.Provider.MintToken(channel, userHandle, now, models.CallTokenTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mint %s token: %w", s.Provider.Name(), err)
+	}
+	call := &models.CallToken{
+		Provider:  s.Provider.Name(),
+		AppID:     s.Provider.ClientAppID(),
+		Channel:   channel,
+		Identity:  userHandle,
+		Token:     token,
+		ExpiresAt: now.Add(models.CallTokenTTL).Format(time.RFC3339),
+	}
+
+	// ✅ A token that can't be logged is not handed out
+	entry := models.CallLogEntry{
+		MatchID:       matchID,
+		SK:            now.Format(time.RFC3339Nano) + "#" + userHandle,
+		UserHandle:    userHandle,
+		PartnerHandle: partner,
+		Provider:      call.Provider,
+		Channel:       channel,
+		IssuedAt:      now.Format(time.RFC3339),
+		TokenExpires:  call.ExpiresAt,
+		ExpiresAt:     now.AddDate(0, 0, models.CallLogRetentionDays).Unix(),
+	}
+	if err := s.Dynamo.PutItem(ctx, models.CallLogTable, entry); err != nil {
+		return nil, fmt.Errorf("failed to log call token: %w", err)
+	}
+
+	utils.Logf(ctx, "📞 Issued %s call token to %s for matchId %s", call.Provider, userHandle, matchID)
+	return call, nil
+}
+
+// ListCalls returns one page of a match's call log, newest first
+func (s *CallService) ListCalls(ctx context.Context, matchID string, limit int32, cursor string) ([]models.CallLogEntry, string, error) {
+	items, nextCursor, err := s.Dynamo.QueryPage(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.CallLogTable),
+		KeyConditionExpression: aws.String("matchId = :matchId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":matchId": &types.AttributeValueMemberS{Value: matchID},
+		},
+		ScanIndexForward: aws.Bool(false),
+	}, limit, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	entries := []models.CallLogEntry{}
+	if err := attributevalue.UnmarshalListOfMaps(items, &entries); err != nil {
+		return nil, "", fmt.Errorf("failed to parse call log: %w", err)
+	}
+	return entries, nextCursor, nil
+}
+
+// callChannel derives a match's channel name: a hash, so the matchId isn't shared with the provider
+// and the name fits every provider's length and character limits
+func callChannel(matchID string) string {
+	sum := sha256.Sum256([]byte(matchID))
+	return "match_" + hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+)
+
+// ErrCaptchaRequired is returned when an action needs a challenge and the request carried no token
+var ErrCaptchaRequired = forbiddenError("captcha_required")
+
+// ErrCaptchaFailed is returned for a token the provider rejected (invalid, expired or reused)
+var ErrCaptchaFailed = forbiddenError("captcha_failed")
+
+// captchaVerifyURLs are the providers' siteverify endpoints
+var captchaVerifyURLs = map[string]string{
+	models.CaptchaProviderHCaptcha:  "https://api.hcaptcha.com/siteverify",
+	models.CaptchaProviderTurnstile: "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+}
+
+// CaptchaService verifies hCaptcha / Turnstile tokens server-side for the configured actions. A nil
+// service or one without a provider requires nothing, so environments can leave challenges off.
+type CaptchaService struct {
+	Provider   string   // models.CaptchaProviderHCaptcha or models.CaptchaProviderTurnstile
+	Secret     string   // The provider's secret key
+	Actions    []string // Which models.CaptchaAction* values need a challenge
+	HTTPClient *http.Client
+}
+
+// Requires reports whether action needs a challenge in this environment
+func (s *CaptchaService) Requires(action string) bool {
+	return s != nil && s.Provider != "" && slices.Contains(s.Actions, action)
+}
+
+// Verify checks the token the client solved for action; it's a no-op unless Requires(action)
+func (s *CaptchaService) Verify(ctx context.Context, action, token, ip string) error {
+	if !s.Requires(action) {
+		return nil
+	}
+	if token == "" {
+		return ErrCaptchaRequired
+	}
+
+	form := url.Values{"secret": {s.Secret}, "response": {token}}
+	if ip != "" {
+		form.Set("remoteip", ip)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, captchaVerifyURLs[s.Provider], strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := s.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s siteverify failed: %w", s.Provider, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s siteverify returned %d", s.Provider, resp.StatusCode)
+	}
+
+	var result struct {
+		Success    bool     `json:"success"`
+		ErrorCodes []string `json:"error-codes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to parse %s siteverify response: %w", s.Provider, err)
+	}
+	if !result.Success {
+		utils.Logf(ctx, "🤖 %s challenge for %s failed: %v", s.Provider, action, result.ErrorCodes)
+		return ErrCaptchaFailed
+	}
+	return nil
+}
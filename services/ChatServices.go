@@ -2,23 +2,41 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"strconv"
+	"strings"
+	"time"
 	"vibin_server/models"
+	"vibin_server/utils"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"golang.org/x/sync/errgroup"
 )
 
 // ChatService struct
 type ChatService struct {
-	Dynamo *DynamoService
+	Dynamo             *DynamoService
+	Moderation         *ModerationService
+	Encryption         *EncryptionService
+	UserProfileService *UserProfileService
+	Analytics          *AnalyticsService
+	Webhooks           *WebhookService
+	Jobs               *JobQueue                   // ✅ Set by RegisterJobs; first-message tracking runs inline without it
+	Summaries          *ConversationSummaryService // ✅ Stream-maintained counters; messages are counted directly without it
+	Safety             *MessageSafetyService       // ✅ Screens stored messages when a classifier is configured
+	Retention          models.RetentionPolicy
 }
 
 // GetMessagesByMatchID fetches the latest messages for a given matchId sorted by createdAt (latest first),
 // then reverses the order before returning, so the latest message appears at the bottom in UI.
-func (s *ChatService) GetMessagesByMatchID(ctx context.Context, matchID string, limit int) ([]models.Message, error) {
-	log.Printf("🔍 Fetching latest %d messages for matchId: %s", limit, matchID)
+// Pass the returned cursor back to load the next (older) page.
+func (s *ChatService) GetMessagesByMatchID(ctx context.Context, matchID string, limit int, cursor string) ([]models.Message, string, error) {
+	utils.Logf(ctx, "🔍 Fetching latest %d messages for matchId: %s", limit, matchID)
 
 	// ✅ Define key condition expression for filtering by matchId
 	keyCondition := "#matchId = :matchId"
@@ -30,18 +48,24 @@ func (s *ChatService) GetMessagesByMatchID(ctx context.Context, matchID string,
 	}
 
 	// ✅ Query DynamoDB (Retrieve latest messages first)
-	items, err := s.Dynamo.QueryItemsWithOptions(ctx, models.MessagesTable, keyCondition, expressionValues, expressionNames, int32(limit), true)
+	items, nextCursor, err := s.Dynamo.QueryPage(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(models.MessagesTable),
+		KeyConditionExpression:    aws.String(keyCondition),
+		ExpressionAttributeValues: expressionValues,
+		ExpressionAttributeNames:  expressionNames,
+		ScanIndexForward:          aws.Bool(false),
+	}, int32(limit), cursor)
 	if err != nil {
-		log.Printf("❌ Error querying messages: %v", err)
-		return nil, fmt.Errorf("failed to fetch messages: %w", err)
+		utils.Logf(ctx, "❌ Error querying messages: %v", err)
+		return nil, "", err
 	}
 
 	// ✅ Unmarshal results
 	var messages []models.Message
 	err = attributevalue.UnmarshalListOfMaps(items, &messages)
 	if err != nil {
-		log.Printf("❌ Error unmarshalling messages: %v", err)
-		return nil, fmt.Errorf("failed to parse messages: %w", err)
+		utils.Logf(ctx, "❌ Error unmarshalling messages: %v", err)
+		return nil, "", fmt.Errorf("failed to parse messages: %w", err)
 	}
 
 	// ✅ Reverse the messages so latest appears at the bottom in UI
@@ -49,8 +73,13 @@ func (s *ChatService) GetMessagesByMatchID(ctx context.Context, matchID string,
 		messages[i], messages[j] = messages[j], messages[i]
 	}
 
-	log.Printf("✅ Found %d messages for matchId: %s, returning in UI-friendly order", len(messages), matchID)
-	return messages, nil
+	for i := range messages {
+		s.decryptMessage(ctx, &messages[i])
+		messages[i].Status = messages[i].DeliveryStatus()
+	}
+
+	utils.Logf(ctx, "✅ Found %d messages for matchId: %s, returning in UI-friendly order", len(messages), matchID)
+	return messages, nextCursor, nil
 }
 
 // SendMessage stores a new message in the Messages table
@@ -58,22 +87,143 @@ func (s *ChatService) SendMessage(ctx context.Context, message models.Message) e
 	// ✅ Ensure `isUnread` is stored as a string
 	message.SetIsUnread(true) // Default new messages to unread
 
-	log.Printf("📩 Storing message: %+v", message)
+	// ✅ Delivery and read receipts only come from the recipient; safety flags only from the classifier
+	message.DeliveredAt, message.ReadAt = "", ""
+	message.SafetyFlag, message.Hidden = "", false
+	message.PinnedAt, message.PinnedBy = "", ""
+
+	// ✅ Suspended and banned users can read their conversations but not write
+	if err := s.UserProfileService.CheckStanding(ctx, message.SenderID); err != nil {
+		return err
+	}
+
+	// ✅ End-to-end encrypted messages are relayed as-is; only their shape can be checked
+	if message.E2E != nil {
+		if err := validateE2E(message); err != nil {
+			return err
+		}
+	}
+
+	// ✅ Screen content against the live moderation rules
+	if err := s.Moderation.CheckContent(message.Content); err != nil {
+		utils.Logf(ctx, "🚫 Message from %s rejected by moderation rules", message.SenderID)
+		s.Webhooks.Publish(ctx, models.WebhookMessageFlagged, map[string]interface{}{
+			"senderHandle": message.SenderID,
+			"matchId":      message.MatchID,
+			"reason":       "moderation_rules",
+			"rulesVersion": s.Moderation.CurrentVersion(),
+		})
+		return err
+	}
+
+	// ✅ New/unverified senders are limited until the other person replies
+	if err := s.checkFirstMessage(ctx, message); err != nil {
+		utils.Logf(ctx, "🚫 Message from %s rejected by first-message limits: %v", message.SenderID, err)
+		return err
+	}
+
+	// ✅ Honour the recipient's text-only setting for this conversation
+	if message.ImageURL != "" || message.AudioURL != "" {
+		if err := s.checkMediaAllowed(ctx, message.MatchID, message.SenderID); err != nil {
+			if errors.Is(err, ErrMediaNotAllowed) {
+				utils.Logf(ctx, "🚫 Media from %s rejected: matchId %s is text-only", message.SenderID, message.MatchID)
+			}
+			return err
+		}
+	}
+
+	// ✅ Replies must quote an earlier message of the same conversation
+	if err := s.resolveReply(ctx, &message); err != nil {
+		return err
+	}
+
+	// ✅ Encrypt content (and any quoted snippet) at rest with the conversation's data key; end-to-end
+	// ciphertext is already unreadable to the server
+	if s.Encryption.Enabled() && message.E2E == nil {
+		ciphertext, version, err := s.Encryption.Encrypt(ctx, message.MatchID, message.Content)
+		if err != nil {
+			utils.Logf(ctx, "❌ Failed to encrypt message: %v", err)
+			return fmt.Errorf("failed to encrypt message: %w", err)
+		}
+		message.Content, message.Encrypted, message.KeyVersion = ciphertext, true, version
+
+		if message.ReplySnippet != "" {
+			snippet, version, err := s.Encryption.Encrypt(ctx, message.MatchID, message.ReplySnippet)
+			if err != nil {
+				utils.Logf(ctx, "❌ Failed to encrypt reply snippet: %v", err)
+				return fmt.Errorf("failed to encrypt message: %w", err)
+			}
+			message.ReplySnippet, message.ReplyKeyVersion = snippet, version
+		}
+	}
+
+	utils.Logf(ctx, "📩 Storing message: %+v", message)
 
 	// ✅ Save message to DynamoDB
 	err := s.Dynamo.PutItem(ctx, models.MessagesTable, message)
 	if err != nil {
-		log.Printf("❌ Failed to store message: %v", err)
+		utils.Logf(ctx, "❌ Failed to store message: %v", err)
 		return fmt.Errorf("failed to store message: %w", err)
 	}
 
-	log.Printf("✅ Message stored successfully")
+	utils.Logf(ctx, "✅ Message stored successfully")
+	s.touchMatchActivity(ctx, message)
+	s.trackFirstMessage(ctx, message)
+	s.Safety.Screen(ctx, message)
 	return nil
 }
 
+// touchMatchActivity moves lastActivityAt on both match records up to the message's time, so match
+// lists can be ordered by activity without reading messages. Best effort: a failure only affects ordering.
+func (s *ChatService) touchMatchActivity(ctx context.Context, message models.Message) {
+	at := activityTime(message.CreatedAt)
+	if at == "" {
+		return
+	}
+
+	other, err := findMatchPartner(ctx, s.Dynamo, message.MatchID, message.SenderID)
+	if err != nil {
+		utils.Logf(ctx, "⚠️ Could not find match records of %s to update activity: %v", message.MatchID, err)
+		return
+	}
+
+	for _, pair := range [][2]string{{message.SenderID, other}, {other, message.SenderID}} {
+		_, err := s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+			TableName: aws.String(models.InteractionsTable),
+			Key: map[string]types.AttributeValue{
+				"PK": &types.AttributeValueMemberS{Value: "USER#" + pair[0]},
+				"SK": &types.AttributeValueMemberS{Value: "INTERACTION#" + pair[1]},
+			},
+			UpdateExpression:    aws.String("SET lastActivityAt = :at"),
+			ConditionExpression: aws.String("#status = :match AND (attribute_not_exists(lastActivityAt) OR lastActivityAt < :at)"), // ✅ Matches only, never moving backwards
+			ExpressionAttributeNames: map[string]string{
+				"#status": "status",
+			},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":at":    &types.AttributeValueMemberS{Value: at},
+				":match": &types.AttributeValueMemberS{Value: models.StatusMatch},
+			},
+		})
+		var conditionFailed *types.ConditionalCheckFailedException
+		if err != nil && !errors.As(err, &conditionFailed) {
+			utils.Logf(ctx, "⚠️ Failed to update activity of %s for %s: %v", message.MatchID, pair[0], err)
+		}
+	}
+}
+
+// activityTime normalizes an RFC3339 timestamp to UTC seconds so activity times compare correctly as
+// strings; unparseable values sort last
+func activityTime(timestamp string) string {
+	parsed, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return ""
+	}
+	return parsed.UTC().Format(time.RFC3339)
+}
+
 // ✅ MarkMessagesAsRead - Marks only the messages received by user as read
 func (s *ChatService) MarkMessagesAsRead(ctx context.Context, matchID string, userHandle string) error {
-	log.Printf("🔄 Marking messages as read for matchId: %s where receiver is %s", matchID, userHandle)
+	utils.Logf(ctx, "🔄 Marking messages as read for matchId: %s where receiver is %s", matchID, userHandle)
 
 	// ✅ Step 1: Query all messages for the given matchId
 	keyCondition := "matchId = :matchId"
@@ -84,7 +234,7 @@ func (s *ChatService) MarkMessagesAsRead(ctx context.Context, matchID string, us
 	// ✅ Fetch all messages
 	items, err := s.Dynamo.QueryItems(ctx, models.MessagesTable, keyCondition, expressionValues, nil, 100)
 	if err != nil {
-		log.Printf("❌ Error fetching messages: %v", err)
+		utils.Logf(ctx, "❌ Error fetching messages: %v", err)
 		return fmt.Errorf("failed to fetch messages: %w", err)
 	}
 
@@ -94,7 +244,7 @@ func (s *ChatService) MarkMessagesAsRead(ctx context.Context, matchID string, us
 		var message models.Message
 		err := attributevalue.UnmarshalMap(item, &message)
 		if err != nil {
-			log.Printf("⚠️ Warning: Failed to parse message: %v", err)
+			utils.Logf(ctx, "⚠️ Warning: Failed to parse message: %v", err)
 			continue
 		}
 
@@ -104,7 +254,9 @@ func (s *ChatService) MarkMessagesAsRead(ctx context.Context, matchID string, us
 		}
 	}
 
-	// ✅ Step 3: Batch update each message's `isUnread` status to "false"
+	// ✅ Step 3: Batch update each message's `isUnread` status to "false", stamping the read receipt
+	now := time.Now().UTC().Format(time.RFC3339)
+	var updated []models.Message
 	for _, message := range messagesToUpdate {
 		// ✅ Define update key
 		key := map[string]types.AttributeValue{
@@ -112,26 +264,139 @@ func (s *ChatService) MarkMessagesAsRead(ctx context.Context, matchID string, us
 			"createdAt": &types.AttributeValueMemberS{Value: message.CreatedAt}, // ✅ Ensure we use the correct sort key
 		}
 
-		// ✅ Update Expression
-		updateExpression := "SET isUnread = :false"
+		// ✅ Update Expression (a read message was delivered too)
+		updateExpression := "SET isUnread = :false, readAt = :now, deliveredAt = if_not_exists(deliveredAt, :now)"
 		expressionValues := map[string]types.AttributeValue{
 			":false": &types.AttributeValueMemberS{Value: "false"}, // Ensure it's stored as string
+			":now":   &types.AttributeValueMemberS{Value: now},
 		}
 
 		// ✅ Perform update
 		_, err := s.Dynamo.UpdateItem(ctx, models.MessagesTable, updateExpression, key, expressionValues, nil)
 		if err != nil {
-			log.Printf("❌ Failed to update message %s: %v", message.MessageID, err)
+			utils.Logf(ctx, "❌ Failed to update message %s: %v", message.MessageID, err)
+			continue
 		}
+		updated = append(updated, message)
 	}
+	s.publishDeliveryStatus(ctx, matchID, userHandle, models.MessageStatusRead, updated)
 
-	log.Printf("✅ Successfully marked %d messages as read for matchId: %s where receiver is %s", len(messagesToUpdate), matchID, userHandle)
+	utils.Logf(ctx, "✅ Successfully marked %d messages as read for matchId: %s where receiver is %s", len(messagesToUpdate), matchID, userHandle)
+	return nil
+}
+
+// MarkMessagesDelivered records that the user's client received the messages sent to them in a
+// match, up to and including createdAt upTo (every message when empty), and tells their senders
+func (s *ChatService) MarkMessagesDelivered(ctx context.Context, matchID, userHandle, upTo string) (int, error) {
+	keyCondition := "matchId = :matchId"
+	values := map[string]types.AttributeValue{
+		":matchId": &types.AttributeValueMemberS{Value: matchID},
+		":user":    &types.AttributeValueMemberS{Value: userHandle},
+	}
+	if upTo != "" {
+		keyCondition += " AND createdAt <= :upTo"
+		values[":upTo"] = &types.AttributeValueMemberS{Value: upTo}
+	}
+	items, err := s.Dynamo.QueryAll(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(models.MessagesTable),
+		KeyConditionExpression:    aws.String(keyCondition),
+		FilterExpression:          aws.String("senderId <> :user AND attribute_not_exists(deliveredAt)"),
+		ExpressionAttributeValues: values,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch messages: %w", err)
+	}
+	var pending []models.Message
+	if err := attributevalue.UnmarshalListOfMaps(items, &pending); err != nil {
+		return 0, fmt.Errorf("failed to parse messages: %w", err)
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	var delivered []models.Message
+	var conditionFailed *types.ConditionalCheckFailedException
+	for _, message := range pending {
+		_, err := s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+			TableName: aws.String(models.MessagesTable),
+			Key: map[string]types.AttributeValue{
+				"matchId":   &types.AttributeValueMemberS{Value: message.MatchID},
+				"createdAt": &types.AttributeValueMemberS{Value: message.CreatedAt},
+			},
+			UpdateExpression:    aws.String("SET deliveredAt = :now"),
+			ConditionExpression: aws.String("attribute_not_exists(deliveredAt)"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":now": &types.AttributeValueMemberS{Value: now},
+			},
+		})
+		if errors.As(err, &conditionFailed) {
+			continue // ✅ Another device acknowledged it first
+		}
+		if err != nil {
+			utils.Logf(ctx, "❌ Failed to mark message %s delivered: %v", message.MessageID, err)
+			continue
+		}
+		delivered = append(delivered, message)
+	}
+	s.publishDeliveryStatus(ctx, matchID, userHandle, models.MessageStatusDelivered, delivered)
+
+	utils.Logf(ctx, "📬 %d messages delivered to %s in matchId: %s", len(delivered), userHandle, matchID)
+	return len(delivered), nil
+}
+
+// publishDeliveryStatus tells each sender (via message.status) which of their messages reached the
+// recipient's client or were read, so their client can update the ticks
+func (s *ChatService) publishDeliveryStatus(ctx context.Context, matchID, recipient, status string, messages []models.Message) {
+	bySender := map[string][]string{}
+	for _, message := range messages {
+		bySender[message.SenderID] = append(bySender[message.SenderID], message.MessageID)
+	}
+	for sender, messageIDs := range bySender {
+		s.Webhooks.Publish(ctx, models.WebhookMessageStatus, map[string]interface{}{
+			"matchId":         matchID,
+			"senderHandle":    sender,
+			"recipientHandle": recipient,
+			"status":          status,
+			"messageIds":      messageIDs,
+		})
+	}
+}
+
+// ExpireConversation sets the unmatched-conversation TTL on every message of the match. It is a
+// no-op when that retention is disabled.
+func (s *ChatService) ExpireConversation(ctx context.Context, matchID string) error {
+	expiresAt := s.Retention.ExpiresAt(time.Now(), s.Retention.UnmatchedMessageDays)
+	if expiresAt == 0 {
+		return nil
+	}
+
+	items, err := s.Dynamo.QueryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.MessagesTable),
+		KeyConditionExpression: aws.String("matchId = :matchId"),
+		ProjectionExpression:   aws.String("matchId, createdAt"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":matchId": &types.AttributeValueMemberS{Value: matchID},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch messages: %w", err)
+	}
+
+	expressionValues := map[string]types.AttributeValue{
+		":expiresAt": &types.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt, 10)},
+	}
+	for _, item := range items {
+		key := map[string]types.AttributeValue{"matchId": item["matchId"], "createdAt": item["createdAt"]}
+		if _, err := s.Dynamo.UpdateItem(ctx, models.MessagesTable, "SET expiresAt = :expiresAt", key, expressionValues, nil); err != nil {
+			return err
+		}
+	}
+
+	utils.Logf(ctx, "🗓️ %d messages of matchId %s expire at %s", len(items), matchID, time.Unix(expiresAt, 0).UTC().Format(time.RFC3339))
 	return nil
 }
 
 // UpdateMessageLikeStatus - Updates the `liked` status of a message
 func (s *ChatService) UpdateMessageLikeStatus(ctx context.Context, matchID string, createdAt string, liked bool) error {
-	log.Printf("💖 Updating like status for Message at %s in MatchID: %s to %v", createdAt, matchID, liked)
+	utils.Logf(ctx, "💖 Updating like status for Message at %s in MatchID: %s to %v", createdAt, matchID, liked)
 
 	// ✅ Define the update key (Primary Key: matchId, Sort Key: createdAt)
 	key := map[string]types.AttributeValue{
@@ -148,16 +413,16 @@ func (s *ChatService) UpdateMessageLikeStatus(ctx context.Context, matchID strin
 	// ✅ Perform the update
 	_, err := s.Dynamo.UpdateItem(ctx, models.MessagesTable, updateExpression, key, expressionValues, nil)
 	if err != nil {
-		log.Printf("❌ Failed to update like status: %v", err)
+		utils.Logf(ctx, "❌ Failed to update like status: %v", err)
 		return fmt.Errorf("failed to update like status: %w", err)
 	}
 
-	log.Printf("✅ Successfully updated like status for message at %s", createdAt)
+	utils.Logf(ctx, "✅ Successfully updated like status for message at %s", createdAt)
 	return nil
 }
 
 func (s *ChatService) GetLastMessageByMatchID(ctx context.Context, matchID string) (*models.Message, error) {
-	log.Printf("🔍 Fetching last message for matchId: %s", matchID)
+	utils.Logf(ctx, "🔍 Fetching last message for matchId: %s", matchID)
 
 	// Define key condition to get messages by matchId, sorted by `createdAt` (descending order)
 	keyCondition := "#matchId = :matchId"
@@ -171,13 +436,13 @@ func (s *ChatService) GetLastMessageByMatchID(ctx context.Context, matchID strin
 	// Query DynamoDB using the matchId as the key and sorting by createdAt (latest first)
 	items, err := s.Dynamo.QueryItemsWithOptions(ctx, models.MessagesTable, keyCondition, expressionValues, expressionNames, 1, true) // `true` -> Descending order
 	if err != nil {
-		log.Printf("❌ Error fetching last message: %v", err)
+		utils.Logf(ctx, "❌ Error fetching last message: %v", err)
 		return nil, fmt.Errorf("failed to fetch last message: %w", err)
 	}
 
 	// If no message is found, return nil
 	if len(items) == 0 {
-		log.Printf("ℹ️ No messages found for matchId: %s", matchID)
+		utils.Logf(ctx, "ℹ️ No messages found for matchId: %s", matchID)
 		return nil, nil
 	}
 
@@ -185,10 +450,348 @@ func (s *ChatService) GetLastMessageByMatchID(ctx context.Context, matchID strin
 	var lastMessage models.Message
 	err = attributevalue.UnmarshalMap(items[0], &lastMessage)
 	if err != nil {
-		log.Printf("❌ Error unmarshalling last message: %v", err)
+		utils.Logf(ctx, "❌ Error unmarshalling last message: %v", err)
 		return nil, fmt.Errorf("failed to parse last message: %w", err)
 	}
+	s.decryptMessage(ctx, &lastMessage)
+	lastMessage.Status = lastMessage.DeliveryStatus()
 
-	log.Printf("✅ Last message for matchId %s: %+v", matchID, lastMessage)
+	utils.Logf(ctx, "✅ Last message for matchId %s: %+v", matchID, lastMessage)
 	return &lastMessage, nil
 }
+
+// decryptMessage prepares a stored message for display: messages hidden by moderation lose their
+// content, everything else is decrypted
+func (s *ChatService) decryptMessage(ctx context.Context, message *models.Message) {
+	if message.Hidden {
+		message.WithholdContent()
+		return
+	}
+	s.decryptContent(ctx, message)
+}
+
+// decryptContent replaces ciphertext content and reply snippet with plaintext; undecryptable content is blanked
+func (s *ChatService) decryptContent(ctx context.Context, message *models.Message) {
+	if !message.Encrypted {
+		return
+	}
+	if !s.Encryption.Enabled() {
+		utils.Logf(ctx, "⚠️ Message %s is encrypted but encryption is not configured", message.MessageID)
+		message.Content, message.ReplySnippet = "", ""
+		return
+	}
+
+	plaintext, err := s.Encryption.Decrypt(ctx, message.MatchID, message.Content, message.KeyVersion)
+	if err != nil {
+		utils.Logf(ctx, "❌ Failed to decrypt message %s: %v", message.MessageID, err)
+		plaintext = ""
+	}
+	message.Content = plaintext
+
+	if message.ReplySnippet != "" {
+		snippet, err := s.Encryption.Decrypt(ctx, message.MatchID, message.ReplySnippet, message.ReplyKeyVersion)
+		if err != nil {
+			utils.Logf(ctx, "❌ Failed to decrypt reply snippet of message %s: %v", message.MessageID, err)
+			snippet = ""
+		}
+		message.ReplySnippet = snippet
+	}
+}
+
+// resolveReply checks that a reply quotes an existing message of the same conversation and fills
+// in the quoted sender and snippet; anything a client sent for those is overwritten
+func (s *ChatService) resolveReply(ctx context.Context, message *models.Message) error {
+	message.ReplyToSenderID, message.ReplySnippet, message.ReplyKeyVersion = "", "", 0
+	if message.ReplyToMessageID == "" && message.ReplyToCreatedAt == "" {
+		return nil
+	}
+	if message.ReplyToMessageID == "" || message.ReplyToCreatedAt == "" {
+		return validationError("replyToMessageId and replyToCreatedAt must be given together")
+	}
+
+	item, err := s.Dynamo.GetItem(ctx, models.MessagesTable, map[string]types.AttributeValue{
+		"matchId":   &types.AttributeValueMemberS{Value: message.MatchID},
+		"createdAt": &types.AttributeValueMemberS{Value: message.ReplyToCreatedAt},
+	})
+	if errors.Is(err, ErrNotFound) {
+		return validationError("the message being replied to does not exist in this conversation")
+	}
+	if err != nil {
+		return err
+	}
+	var quoted models.Message
+	if err := attributevalue.UnmarshalMap(item, &quoted); err != nil {
+		return fmt.Errorf("failed to parse quoted message: %w", err)
+	}
+	if quoted.MessageID != message.ReplyToMessageID {
+		return validationError("the message being replied to does not exist in this conversation")
+	}
+
+	s.decryptMessage(ctx, &quoted)
+	message.ReplyToSenderID = quoted.SenderID
+	message.ReplySnippet = models.ReplySnippet(quoted.Content, quoted.ImageURL != "")
+	return nil
+}
+
+// unreadCountConcurrency bounds parallel per-conversation unread count queries
+const unreadCountConcurrency = 10
+
+// GetUnreadCounts returns, per match and in aggregate, how many messages the user has not read
+func (s *ChatService) GetUnreadCounts(ctx context.Context, userHandle string) (*models.UnreadCounts, error) {
+	utils.Logf(ctx, "🔍 Counting unread messages for user: %s", userHandle)
+
+	matchIDs, err := s.getMatchIDsForUser(ctx, userHandle)
+	if err != nil {
+		utils.Logf(ctx, "❌ Error fetching matches for unread counts: %v", err)
+		return nil, fmt.Errorf("failed to fetch matches: %w", err)
+	}
+
+	counts, err := s.countUnread(ctx, userHandle, matchIDs)
+	if err != nil {
+		utils.Logf(ctx, "❌ Error counting unread messages: %v", err)
+		return nil, err
+	}
+
+	unread := &models.UnreadCounts{Conversations: map[string]int{}}
+	for i, matchID := range matchIDs {
+		unread.Add(matchID, counts[i])
+	}
+
+	utils.Logf(ctx, "✅ User %s has %d unread messages across %d matches", userHandle, unread.Total, unread.UnreadConversations)
+	return unread, nil
+}
+
+// countUnread returns how many messages userHandle hasn't read in each match, in matchIDs order
+func (s *ChatService) countUnread(ctx context.Context, userHandle string, matchIDs []string) ([]int, error) {
+	// ✅ Stream-maintained summaries first; only matches without one are counted from messages
+	counts := make([]int, len(matchIDs))
+	summaries := s.getSummaries(ctx, matchIDs)
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(unreadCountConcurrency)
+	for i, matchID := range matchIDs {
+		if summary, ok := summaries[matchID]; ok {
+			counts[i] = summary.UnreadFor(userHandle)
+			continue
+		}
+		group.Go(func() error {
+			count, err := s.Dynamo.CountItems(groupCtx, &dynamodb.QueryInput{
+				TableName:              aws.String(models.MessagesTable),
+				KeyConditionExpression: aws.String("matchId = :matchId"),
+				FilterExpression:       aws.String("senderId <> :user AND isUnread = :true"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":matchId": &types.AttributeValueMemberS{Value: matchID},
+					":user":    &types.AttributeValueMemberS{Value: userHandle},
+					":true":    &types.AttributeValueMemberS{Value: "true"},
+				},
+			})
+			if err != nil {
+				return fmt.Errorf("failed to count unread messages for matchId %s: %w", matchID, err)
+			}
+			counts[i] = count
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// getSummaries reads the conversation summaries of the given matches; a failed read returns none so
+// callers fall back to querying messages
+func (s *ChatService) getSummaries(ctx context.Context, matchIDs []string) map[string]*models.ConversationSummary {
+	if s.Summaries == nil {
+		return nil
+	}
+	summaries, err := s.Summaries.GetSummaries(ctx, matchIDs)
+	if err != nil {
+		utils.Logf(ctx, "⚠️ Failed to read conversation summaries, querying messages instead: %v", err)
+		return nil
+	}
+	return summaries
+}
+
+// getMatchIDsForUser lists every matchId the user participates in
+func (s *ChatService) getMatchIDsForUser(ctx context.Context, userHandle string) ([]string, error) {
+	interactions, err := s.getMatchesForUser(ctx, userHandle)
+	if err != nil {
+		return nil, err
+	}
+
+	matchIDs := make([]string, len(interactions))
+	for i, interaction := range interactions {
+		matchIDs[i] = *interaction.MatchID
+	}
+	return matchIDs, nil
+}
+
+// getMatchesForUser lists the user's match records; records without a matchId are skipped
+func (s *ChatService) getMatchesForUser(ctx context.Context, userHandle string) ([]models.Interaction, error) {
+	items, err := s.Dynamo.QueryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.InteractionsTable),
+		IndexName:              aws.String(models.StatusIndex),
+		KeyConditionExpression: aws.String("#PK = :user AND #status = :matchStatus"),
+		ExpressionAttributeNames: map[string]string{
+			"#PK":     "PK",
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":user":        &types.AttributeValueMemberS{Value: "USER#" + userHandle},
+			":matchStatus": &types.AttributeValueMemberS{Value: models.StatusMatch},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []models.Interaction
+	for _, item := range items {
+		var interaction models.Interaction
+		if err := attributevalue.UnmarshalMap(item, &interaction); err != nil || interaction.MatchID == nil {
+			continue
+		}
+		matches = append(matches, interaction)
+	}
+	return matches, nil
+}
+
+// SearchMessages finds messages in a match containing a keyword within an optional date range,
+// returning the newest hits first with up to ContextSize messages on either side
+func (s *ChatService) SearchMessages(ctx context.Context, matchID string, query models.MessageSearchQuery) ([]models.MessageSearchResult, error) {
+	utils.Logf(ctx, "🔍 Searching messages for matchId: %s (from=%q, to=%q)", matchID, query.From, query.To)
+
+	search := conversationSearch[models.Message]{
+		dynamo:  s.Dynamo,
+		table:   models.MessagesTable,
+		pkAttr:  "matchId",
+		pkValue: matchID,
+		decrypt: func(m *models.Message) { s.decryptMessage(ctx, m); m.Status = m.DeliveryStatus() },
+		content: func(m *models.Message) string { return m.Content },
+	}
+	hits, err := search.run(ctx, query)
+	if err != nil {
+		utils.Logf(ctx, "❌ Error searching messages: %v", err)
+		return nil, err
+	}
+
+	results := make([]models.MessageSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		results = append(results, models.MessageSearchResult{Message: hit.message, Before: hit.before, After: hit.after})
+	}
+
+	utils.Logf(ctx, "✅ Found %d matching messages for matchId: %s", len(results), matchID)
+	return results, nil
+}
+
+// checkFirstMessage enforces the moderation first-message policy for restricted senders.
+// A failed profile lookup lets the message through; content rules still apply.
+func (s *ChatService) checkFirstMessage(ctx context.Context, message models.Message) error {
+	if s.UserProfileService == nil {
+		return nil
+	}
+
+	profile, err := s.UserProfileService.GetUserProfileByHandle(ctx, message.SenderID)
+	if err != nil {
+		utils.Logf(ctx, "⚠️ Could not load sender profile for first-message check: %v", err)
+		return nil
+	}
+	if !s.Moderation.IsRestrictedSender(profile) {
+		return nil
+	}
+
+	replies, err := s.Dynamo.CountItems(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.MessagesTable),
+		KeyConditionExpression: aws.String("matchId = :matchId"),
+		FilterExpression:       aws.String("senderId <> :sender"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":matchId": &types.AttributeValueMemberS{Value: message.MatchID},
+			":sender":  &types.AttributeValueMemberS{Value: message.SenderID},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to check conversation history: %w", err)
+	}
+	if replies > 0 {
+		return nil
+	}
+
+	if message.E2E != nil {
+		return &FirstMessageError{Reason: "New accounts must send unencrypted messages until the other person replies"}
+	}
+	return s.Moderation.CheckFirstMessage(message.Content, message.ImageURL != "")
+}
+
+// trackFirstMessage records the first_message funnel event when this is the sender's first message in the match
+func (s *ChatService) trackFirstMessage(ctx context.Context, message models.Message) {
+	if s.Analytics == nil {
+		return
+	}
+	job := models.FirstMessageJob{MatchID: message.MatchID, SenderID: message.SenderID, CreatedAt: message.CreatedAt}
+	if s.Jobs != nil {
+		s.Jobs.Enqueue(ctx, models.JobFirstMessage, job)
+		return
+	}
+	if err := s.checkFirstMessageSent(ctx, job); err != nil {
+		utils.Logf(ctx, "⚠️ Failed to count messages for first_message tracking: %v", err)
+	}
+}
+
+// RegisterJobs moves first-message tracking (a count query per message) onto the job queue
+func (s *ChatService) RegisterJobs(queue *JobQueue) {
+	s.Jobs = queue
+	queue.Handle(models.JobFirstMessage, func(ctx context.Context, payload json.RawMessage) error {
+		job, err := decodeJob[models.FirstMessageJob](payload)
+		if err != nil {
+			return err
+		}
+		return s.checkFirstMessageSent(ctx, job)
+	})
+}
+
+// checkFirstMessageSent tracks first_message if the sender had no earlier message in the match
+func (s *ChatService) checkFirstMessageSent(ctx context.Context, job models.FirstMessageJob) error {
+	sent, err := s.Dynamo.CountItems(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.MessagesTable),
+		KeyConditionExpression: aws.String("matchId = :matchId AND createdAt <= :createdAt"),
+		FilterExpression:       aws.String("senderId = :sender"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":matchId":   &types.AttributeValueMemberS{Value: job.MatchID},
+			":createdAt": &types.AttributeValueMemberS{Value: job.CreatedAt},
+			":sender":    &types.AttributeValueMemberS{Value: job.SenderID},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if sent == 1 {
+		s.Analytics.Track(ctx, models.EventFirstMessage, job.SenderID, map[string]string{"matchId": job.MatchID})
+	}
+	return nil
+}
+
+// validateE2E checks an end-to-end encrypted message: the server can't read it, so only its shape
+func validateE2E(message models.Message) error {
+	envelope := message.E2E
+	if message.Content != "" {
+		return validationError("content must be empty when e2e is set")
+	}
+	if !models.DeviceIDPattern.MatchString(envelope.SenderDeviceID) {
+		return validationError("e2e.senderDeviceId is invalid")
+	}
+	if len(envelope.Ciphertexts) == 0 {
+		return validationError("e2e.ciphertexts must have at least one entry")
+	}
+	if len(envelope.Ciphertexts) > 2*models.MaxDevicesPerUser {
+		return validationError(fmt.Sprintf("e2e.ciphertexts must have at most %d entries", 2*models.MaxDevicesPerUser))
+	}
+	for address, ciphertext := range envelope.Ciphertexts {
+		handle, device, ok := strings.Cut(address, ":")
+		if !ok || handle == "" || !models.DeviceIDPattern.MatchString(device) {
+			return validationError("e2e.ciphertexts keys must be <userhandle>:<deviceId>")
+		}
+		if ciphertext == "" || len(ciphertext) > models.MaxE2ECiphertextLength {
+			return validationError(fmt.Sprintf("e2e ciphertexts must be 1 to %d characters", models.MaxE2ECiphertextLength))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"golang.org/x/sync/errgroup"
+)
+
+// mutualConnectionQueries caps the contact lists read in parallel when counting mutual connections
+const mutualConnectionQueries = 8
+
+// ContactService stores hashed phone contacts and applies the contact sync modes to discovery.
+// Clients upload SHA-256 hashes of their contacts' numbers; only a keyed hash of each is stored
+// (PIIService.ContactIndex), matched against the same hash of every user's own number.
+type ContactService struct {
+	Dynamo *DynamoService
+	PII    *PIIService
+}
+
+// SyncContacts replaces the user's contacts with the uploaded hashes
+func (s *ContactService) SyncContacts(ctx context.Context, userHandle string, hashes []string) (*models.ContactSettings, error) {
+	wanted := make(map[string]bool, len(hashes))
+	for _, hash := range hashes {
+		wanted[s.PII.ContactIndex(hash)] = true
+	}
+	stored, err := s.contactIndexes(ctx, userHandle)
+	if err != nil {
+		return nil, err
+	}
+
+	var requests []types.WriteRequest
+	for index := range wanted {
+		if !stored[index] {
+			requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: contactKey(userHandle, index)}})
+		}
+	}
+	for index := range stored {
+		if !wanted[index] {
+			requests = append(requests, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: contactKey(userHandle, index)}})
+		}
+	}
+	if len(requests) > 0 {
+		if err := s.Dynamo.BatchWriteItems(ctx, models.ContactsTable, requests); err != nil {
+			return nil, fmt.Errorf("failed to store contacts of %s: %w", userHandle, err)
+		}
+	}
+
+	err = s.updateSettings(ctx, userHandle, "SET contacts.contactCount = :count, contacts.syncedAt = :now", map[string]types.AttributeValue{
+		":count": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", len(wanted))},
+		":now":   &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Logf(ctx, "📇 %s synced %d contacts (%d changes)", userHandle, len(wanted), len(requests))
+	return s.GetSettings(ctx, userHandle)
+}
+
+// DeleteContacts removes every synced contact; the modes are kept but have nothing to act on
+func (s *ContactService) DeleteContacts(ctx context.Context, userHandle string) error {
+	stored, err := s.contactIndexes(ctx, userHandle)
+	if err != nil {
+		return err
+	}
+	requests := make([]types.WriteRequest, 0, len(stored))
+	for index := range stored {
+		requests = append(requests, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: contactKey(userHandle, index)}})
+	}
+	if len(requests) > 0 {
+		if err := s.Dynamo.BatchWriteItems(ctx, models.ContactsTable, requests); err != nil {
+			return fmt.Errorf("failed to delete contacts of %s: %w", userHandle, err)
+		}
+	}
+
+	if err := s.updateSettings(ctx, userHandle, "SET contacts.contactCount = :zero REMOVE contacts.syncedAt", map[string]types.AttributeValue{
+		":zero": &types.AttributeValueMemberN{Value: "0"},
+	}); err != nil {
+		return err
+	}
+
+	utils.Logf(ctx, "🗑️ Deleted %d contacts of %s", len(requests), userHandle)
+	return nil
+}
+
+// GetSettings returns the user's contact sync modes
+func (s *ContactService) GetSettings(ctx context.Context, userHandle string) (*models.ContactSettings, error) {
+	item, err := s.Dynamo.GetItemAttributes(ctx, models.UserProfilesTable, profileKey(userHandle), "contacts")
+	if errors.Is(err, ErrNotFound) {
+		return nil, notFoundError("profile not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	settings := models.ContactSettings{}
+	if value, ok := item["contacts"]; ok {
+		if err := attributevalue.Unmarshal(value, &settings); err != nil {
+			return nil, fmt.Errorf("failed to parse contact settings: %w", err)
+		}
+	}
+	return &settings, nil
+}
+
+// UpdateSettings sets the contact sync modes
+func (s *ContactService) UpdateSettings(ctx context.Context, userHandle string, hideContacts, showMutualConnections bool) (*models.ContactSettings, error) {
+	err := s.updateSettings(ctx, userHandle, "SET contacts.hideContacts = :hide, contacts.showMutualConnections = :mutual", map[string]types.AttributeValue{
+		":hide":   &types.AttributeValueMemberBOOL{Value: hideContacts},
+		":mutual": &types.AttributeValueMemberBOOL{Value: showMutualConnections},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Logf(ctx, "✅ Contact settings of %s: hide=%t mutual=%t", userHandle, hideContacts, showMutualConnections)
+	return s.GetSettings(ctx, userHandle)
+}
+
+// ContactGraph is what discovery needs to know about the viewer's contacts
+type ContactGraph struct {
+	hideContacts bool
+	contacts     map[string]bool // contactIndex values the viewer synced
+	knownBy      map[string]bool // users who have the viewer's number in their contacts
+}
+
+// DiscoveryGraph loads the viewer's contacts (when a mode needs them) and who has the viewer in theirs
+func (s *ContactService) DiscoveryGraph(ctx context.Context, viewer *models.UserProfile) (*ContactGraph, error) {
+	graph := &ContactGraph{
+		hideContacts: viewer.Contacts.HidesContacts(),
+		contacts:     map[string]bool{},
+		knownBy:      map[string]bool{},
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	if graph.hideContacts || viewer.Contacts.ShowsMutualConnections() {
+		g.Go(func() error {
+			contacts, err := s.contactIndexes(gctx, viewer.UserHandle)
+			graph.contacts = contacts
+			return err
+		})
+	}
+	if viewer.ContactIndex != "" {
+		g.Go(func() error {
+			items, err := s.Dynamo.QueryAll(gctx, &dynamodb.QueryInput{
+				TableName:              aws.String(models.ContactsTable),
+				IndexName:              aws.String(models.ContactIndexIndex),
+				KeyConditionExpression: aws.String("contactIndex = :index"),
+				ProjectionExpression:   aws.String("userhandle"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":index": &types.AttributeValueMemberS{Value: viewer.ContactIndex},
+				},
+			})
+			if err != nil {
+				return fmt.Errorf("failed to query contacts of %s: %w", viewer.UserHandle, err)
+			}
+			for _, item := range items {
+				if handle, ok := item["userhandle"].(*types.AttributeValueMemberS); ok {
+					graph.knownBy[handle.Value] = true
+				}
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return graph, nil
+}
+
+// Hides reports whether a contact sync mode keeps profile out of the viewer's discovery: the viewer
+// hides their contacts and profile's number is one, or profile hides theirs and has the viewer's
+func (g *ContactGraph) Hides(profile *models.UserProfile) bool {
+	if g.hideContacts && profile.ContactIndex != "" && g.contacts[profile.ContactIndex] {
+		return true
+	}
+	return profile.Contacts.HidesContacts() && g.knownBy[profile.UserHandle]
+}
+
+// MutualConnections counts the contacts the viewer shares with each user. Counting is best effort:
+// a user whose contacts can't be read is left out.
+func (s *ContactService) MutualConnections(ctx context.Context, graph *ContactGraph, userHandles []string) map[string]int {
+	counts := make([]int, len(userHandles))
+	var g errgroup.Group
+	g.SetLimit(mutualConnectionQueries)
+	for i, handle := range userHandles {
+		g.Go(func() error {
+			contacts, err := s.contactIndexes(ctx, handle)
+			if err != nil {
+				utils.Logf(ctx, "⚠️ Could not count mutual connections with %s: %v", handle, err)
+				return nil
+			}
+			for index := range contacts {
+				if graph.contacts[index] {
+					counts[i]++
+				}
+			}
+			return nil
+		})
+	}
+	g.Wait()
+
+	result := make(map[string]int, len(userHandles))
+	for i, handle := range userHandles {
+		if counts[i] > 0 {
+			result[handle] = counts[i]
+		}
+	}
+	return result
+}
+
+// contactIndexes returns the contactIndex values the user synced
+func (s *ContactService) contactIndexes(ctx context.Context, userHandle string) (map[string]bool, error) {
+	items, err := s.Dynamo.QueryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.ContactsTable),
+		KeyConditionExpression: aws.String("userhandle = :handle"),
+		ProjectionExpression:   aws.String("contactIndex"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":handle": &types.AttributeValueMemberS{Value: userHandle},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query contacts of %s: %w", userHandle, err)
+	}
+	indexes := make(map[string]bool, len(items))
+	for _, item := range items {
+		if index, ok := item["contactIndex"].(*types.AttributeValueMemberS); ok {
+			indexes[index.Value] = true
+		}
+	}
+	return indexes, nil
+}
+
+// updateSettings applies an update to the profile's contacts map, creating it first if needed
+// (DynamoDB can't set a nested attribute of a missing map)
+func (s *ContactService) updateSettings(ctx context.Context, userHandle, update string, values map[string]types.AttributeValue) error {
+	var conditionFailed *types.ConditionalCheckFailedException
+	_, err := s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(models.UserProfilesTable),
+		Key:                 profileKey(userHandle),
+		UpdateExpression:    aws.String("SET contacts = :empty"),
+		ConditionExpression: aws.String("attribute_exists(userhandle) AND attribute_not_exists(contacts)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":empty": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{}},
+		},
+	})
+	if err != nil && !errors.As(err, &conditionFailed) {
+		return fmt.Errorf("failed to update contact settings of %s: %w", userHandle, err)
+	}
+
+	_, err = s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(models.UserProfilesTable),
+		Key:                       profileKey(userHandle),
+		UpdateExpression:          aws.String(update),
+		ConditionExpression:       aws.String("attribute_exists(userhandle)"),
+		ExpressionAttributeValues: values,
+	})
+	if errors.As(err, &conditionFailed) {
+		return notFoundError("profile not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update contact settings of %s: %w", userHandle, err)
+	}
+	return nil
+}
+
+// contactKey builds the Contacts primary key (also the whole item)
+func contactKey(userHandle, contactIndex string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"userhandle":   &types.AttributeValueMemberS{Value: userHandle},
+		"contactIndex": &types.AttributeValueMemberS{Value: contactIndex},
+	}
+}
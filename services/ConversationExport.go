@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+)
+
+// ConversationExportService builds transcripts of one match or group for a participant. Exports
+// count against a daily quota and every one is logged in ConversationExportsTable.
+type ConversationExportService struct {
+	Dynamo    *DynamoService
+	Chat      *ChatService
+	GroupChat *GroupChatService
+	S3        *S3Service // ✅ Presigns uploaded media; links are passed through without it
+}
+
+// ExportConversation returns the transcript of a match (kind "match") or group (kind "group") the
+// user takes part in. Anyone else gets not found, so exports can't probe for conversations.
+func (s *ConversationExportService) ExportConversation(ctx context.Context, userHandle, kind, conversationID, format string) (*models.ConversationExport, error) {
+	if err := s.checkParticipant(ctx, userHandle, kind, conversationID); err != nil {
+		return nil, err
+	}
+	if err := reserveDailyQuota(ctx, s.Dynamo, userHandle, models.QuotaDailyExports, models.DailyExportLimit,
+		fmt.Sprintf("You can export %d conversations per day. Try again tomorrow.", models.DailyExportLimit)); err != nil {
+		return nil, err
+	}
+
+	var messages []models.ExportedMessage
+	var err error
+	if kind == models.ConversationKindGroup {
+		messages, err = s.groupMessages(ctx, conversationID)
+	} else {
+		messages, err = s.matchMessages(ctx, conversationID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	export := &models.ConversationExport{
+		ExportID:       uuid.New().String(),
+		Kind:           kind,
+		ConversationID: conversationID,
+		ExportedBy:     userHandle,
+		ExportedAt:     now.Format(time.RFC3339),
+		Messages:       messages,
+	}
+	if err := s.logExport(ctx, export, format, now); err != nil {
+		return nil, err
+	}
+
+	utils.Logf(ctx, "📤 %s exported %s %s (%d messages, %s)", userHandle, kind, conversationID, len(messages), format)
+	return export, nil
+}
+
+// ListExports returns one page of the user's exports, newest first
+func (s *ConversationExportService) ListExports(ctx context.Context, userHandle string, limit int32, cursor string) ([]models.ConversationExportLogEntry, string, error) {
+	items, nextCursor, err := s.Dynamo.QueryPage(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.ConversationExportsTable),
+		KeyConditionExpression: aws.String("userhandle = :handle"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":handle": &types.AttributeValueMemberS{Value: userHandle},
+		},
+		ScanIndexForward: aws.Bool(false),
+	}, limit, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	entries := []models.ConversationExportLogEntry{}
+	if err := attributevalue.UnmarshalListOfMaps(items, &entries); err != nil {
+		return nil, "", fmt.Errorf("failed to parse conversation exports: %w", err)
+	}
+	return entries, nextCursor, nil
+}
+
+// checkParticipant returns not found unless the user is matched in the match or an active member of the group
+func (s *ConversationExportService) checkParticipant(ctx context.Context, userHandle, kind, conversationID string) error {
+	if kind == models.ConversationKindGroup {
+		return s.GroupChat.checkActiveMember(ctx, conversationID, userHandle)
+	}
+
+	matchIDs, err := s.Chat.getMatchIDsForUser(ctx, userHandle)
+	if err != nil {
+		return fmt.Errorf("failed to list matches of %s: %w", userHandle, err)
+	}
+	if !slices.Contains(matchIDs, conversationID) {
+		return notFoundError("conversation not found")
+	}
+	return nil
+}
+
+// matchMessages reads and decrypts every message of a match, oldest first
+func (s *ConversationExportService) matchMessages(ctx context.Context, matchID string) ([]models.ExportedMessage, error) {
+	items, err := s.Dynamo.QueryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.MessagesTable),
+		KeyConditionExpression: aws.String("matchId = :matchId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":matchId": &types.AttributeValueMemberS{Value: matchID},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query messages: %w", err)
+	}
+	var messages []models.Message
+	if err := attributevalue.UnmarshalListOfMaps(items, &messages); err != nil {
+		return nil, fmt.Errorf("failed to parse messages: %w", err)
+	}
+
+	exported := make([]models.ExportedMessage, 0, len(messages))
+	for i := range messages {
+		message := &messages[i]
+		s.Chat.decryptMessage(ctx, message)
+		exported = append(exported, models.ExportedMessage{
+			MessageID:        message.MessageID,
+			SenderID:         message.SenderID,
+			CreatedAt:        message.CreatedAt,
+			Content:          message.Content,
+			MediaURL:         s.mediaURL(ctx, message.SenderID, message.ImageURL),
+			ReplyToMessageID: message.ReplyToMessageID,
+			EndToEnd:         message.E2E != nil,
+		})
+	}
+	return exported, nil
+}
+
+// groupMessages reads and decrypts every member message of a group, oldest first; the activity
+// log entries are left out
+func (s *ConversationExportService) groupMessages(ctx context.Context, groupID string) ([]models.ExportedMessage, error) {
+	items, err := s.Dynamo.QueryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.GroupMessageTable),
+		KeyConditionExpression: aws.String("groupId = :groupId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":groupId": &types.AttributeValueMemberS{Value: groupID},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query group messages: %w", err)
+	}
+	var messages []models.GroupMessage
+	if err := attributevalue.UnmarshalListOfMaps(items, &messages); err != nil {
+		return nil, fmt.Errorf("failed to parse group messages: %w", err)
+	}
+
+	exported := make([]models.ExportedMessage, 0, len(messages))
+	for i := range messages {
+		message := &messages[i]
+		if message.MessageType == models.GroupMessageTypeSystem {
+			continue
+		}
+		s.GroupChat.decryptGroupMessage(ctx, message)
+		exported = append(exported, models.ExportedMessage{
+			MessageID:        message.MessageID,
+			SenderID:         message.SenderID,
+			CreatedAt:        message.CreatedAt,
+			Content:          message.Content,
+			MediaURL:         s.mediaURL(ctx, message.SenderID, derefString(message.ImageURL)),
+			ReplyToMessageID: message.ReplyToMessageID,
+		})
+	}
+	return exported, nil
+}
+
+// mediaURL presigns media the sender uploaded (stored as an upload key); anything else is already a link
+func (s *ConversationExportService) mediaURL(ctx context.Context, senderID, media string) string {
+	if media == "" || s.S3 == nil || !models.OwnsUploadKey(senderID, media) {
+		return media
+	}
+	url, err := s.S3.GenerateReadURL(ctx, media)
+	if err != nil {
+		utils.Logf(ctx, "⚠️ Failed to presign exported media %s: %v", media, err)
+		return media
+	}
+	return url
+}
+
+// logExport appends the export to the log; an export that can't be logged is not returned
+func (s *ConversationExportService) logExport(ctx context.Context, export *models.ConversationExport, format string, now time.Time) error {
+	entry := models.ConversationExportLogEntry{
+		UserHandle:     export.ExportedBy,
+		SK:             now.Format(time.RFC3339Nano) + "#" + export.ExportID,
+		ExportID:       export.ExportID,
+		Kind:           export.Kind,
+		ConversationID: export.ConversationID,
+		Format:         format,
+		MessageCount:   len(export.Messages),
+		At:             export.ExportedAt,
+		ExpiresAt:      now.AddDate(0, 0, models.ConversationExportRetentionDays).Unix(),
+	}
+	if err := s.Dynamo.PutItem(ctx, models.ConversationExportsTable, entry); err != nil {
+		return fmt.Errorf("failed to log conversation export: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ErrMediaNotAllowed is returned when a recipient has restricted the conversation to text only
+var ErrMediaNotAllowed = errors.New("media_not_allowed")
+
+// GetConversationSettings returns the user's settings for a conversation (defaults if never set)
+func (s *ChatService) GetConversationSettings(ctx context.Context, matchID, userHandle string) (*models.ConversationSettings, error) {
+	items, err := s.Dynamo.QueryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.ConversationSettingsTable),
+		KeyConditionExpression: aws.String("conversationId = :conversationId AND userhandle = :user"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":conversationId": &types.AttributeValueMemberS{Value: matchID},
+			":user":           &types.AttributeValueMemberS{Value: userHandle},
+		},
+	})
+	if err != nil {
+		utils.Logf(ctx, "❌ Error fetching conversation settings: %v", err)
+		return nil, fmt.Errorf("failed to fetch conversation settings: %w", err)
+	}
+
+	settings := &models.ConversationSettings{ConversationID: matchID, UserHandle: userHandle}
+	if len(items) > 0 {
+		if err := attributevalue.UnmarshalMap(items[0], settings); err != nil {
+			return nil, fmt.Errorf("failed to parse conversation settings: %w", err)
+		}
+	}
+	return settings, nil
+}
+
+// SetTextOnly lets a recipient refuse images/voice in one conversation
+func (s *ChatService) SetTextOnly(ctx context.Context, matchID, userHandle string, textOnly bool) (*models.ConversationSettings, error) {
+	utils.Logf(ctx, "🔄 Setting textOnly=%v for %s in matchId: %s", textOnly, userHandle, matchID)
+
+	settings := models.ConversationSettings{
+		ConversationID: matchID,
+		UserHandle:     userHandle,
+		TextOnly:       textOnly,
+		UpdatedAt:      time.Now().Format(time.RFC3339),
+	}
+	if err := s.Dynamo.PutItem(ctx, models.ConversationSettingsTable, settings); err != nil {
+		utils.Logf(ctx, "❌ Error saving conversation settings: %v", err)
+		return nil, fmt.Errorf("failed to save conversation settings: %w", err)
+	}
+
+	utils.Logf(ctx, "✅ Conversation settings saved for %s in matchId: %s", userHandle, matchID)
+	return &settings, nil
+}
+
+// checkMediaAllowed rejects media when the recipient has made the conversation text-only
+func (s *ChatService) checkMediaAllowed(ctx context.Context, matchID, senderID string) error {
+	recipient, err := findMatchPartner(ctx, s.Dynamo, matchID, senderID)
+	if err != nil {
+		return err
+	}
+	item, err := s.Dynamo.GetItem(ctx, models.ConversationSettingsTable, map[string]types.AttributeValue{
+		"conversationId": &types.AttributeValueMemberS{Value: matchID},
+		"userhandle":     &types.AttributeValueMemberS{Value: recipient},
+	})
+	if errors.Is(err, ErrNotFound) {
+		return nil // ✅ Never set: media is allowed
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check conversation settings: %w", err)
+	}
+	var settings models.ConversationSettings
+	if err := attributevalue.UnmarshalMap(item, &settings); err != nil {
+		return fmt.Errorf("failed to parse conversation settings: %w", err)
+	}
+	if settings.TextOnly {
+		return ErrMediaNotAllowed
+	}
+	return nil
+}
@@ -0,0 +1,336 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	streamtypes "github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+)
+
+// ConversationSummaryService maintains per-match aggregates (latest message, unread counters) from the
+// Messages and Interactions streams, so list screens read one item per match instead of querying messages.
+// Both tables need streams with NEW_AND_OLD_IMAGES.
+type ConversationSummaryService struct {
+	Dynamo *DynamoService
+}
+
+///// 🔹🔹🔹 Stream handlers 🔹🔹🔹 /////
+
+// HandleMessageRecord applies one Messages stream record to the match's summary
+func (s *ConversationSummaryService) HandleMessageRecord(ctx context.Context, record streamtypes.Record) error {
+	oldMessage, newMessage, err := messageImages(record)
+	if err != nil {
+		return err
+	}
+	message := newMessage
+	if message == nil {
+		message = oldMessage
+	}
+	if message == nil || message.MatchID == "" {
+		return nil
+	}
+
+	// ✅ Unread delta for the sender: +1 for a new unread message, -1 when read or deleted
+	delta := 0
+	if newMessage != nil && newMessage.IsUnreadBool() {
+		delta++
+	}
+	if oldMessage != nil && oldMessage.IsUnreadBool() {
+		delta--
+	}
+
+	applied, err := s.addUnread(ctx, message.MatchID, message.SenderID, delta, recordTime(record))
+	if err != nil || !applied {
+		return err
+	}
+
+	switch {
+	case record.EventName == streamtypes.OperationTypeRemove:
+		return s.refreshLastMessage(ctx, message.MatchID, message.CreatedAt)
+	default:
+		return s.setLastMessage(ctx, *newMessage)
+	}
+}
+
+// HandleInteractionRecord records the participants once an interaction becomes a match
+func (s *ConversationSummaryService) HandleInteractionRecord(ctx context.Context, record streamtypes.Record) error {
+	if record.Dynamodb == nil || record.Dynamodb.NewImage == nil {
+		return nil
+	}
+	image, err := attributevalue.FromDynamoDBStreamsMap(record.Dynamodb.NewImage)
+	if err != nil {
+		return fmt.Errorf("failed to convert interaction image: %w", err)
+	}
+	var interaction models.Interaction
+	if err := attributevalue.UnmarshalMap(image, &interaction); err != nil {
+		return fmt.Errorf("failed to parse interaction image: %w", err)
+	}
+	if interaction.Status != models.StatusMatch || interaction.MatchID == nil {
+		return nil
+	}
+
+	_, err = s.Dynamo.UpdateItem(ctx, models.ConversationSummariesTable,
+		"ADD participants :participants SET updatedAt = :now",
+		summaryKey(*interaction.MatchID),
+		map[string]types.AttributeValue{
+			":participants": &types.AttributeValueMemberSS{Value: []string{interaction.SenderHandle, interaction.ReceiverHandle}},
+			":now":          &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)},
+		}, nil)
+	return err
+}
+
+// addUnread adjusts the sender's counter. It reports false when the record was already covered by a
+// rebuild (the summary was missing, or rebuilt after this change happened).
+func (s *ConversationSummaryService) addUnread(ctx context.Context, matchID, sender string, delta int, changedAt int64) (bool, error) {
+	_, err := s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(models.ConversationSummariesTable),
+		Key:                 summaryKey(matchID),
+		UpdateExpression:    aws.String("SET updatedAt = :now ADD #unread :delta"),
+		ConditionExpression: aws.String("attribute_exists(rebuiltAt) AND rebuiltAt <= :changedAt"),
+		ExpressionAttributeNames: map[string]string{
+			"#unread": models.UnreadFromAttrPrefix + sender,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":now":       &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)},
+			":delta":     &types.AttributeValueMemberN{Value: strconv.Itoa(delta)},
+			":changedAt": &types.AttributeValueMemberN{Value: strconv.FormatInt(changedAt, 10)},
+		},
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	switch {
+	case err == nil:
+		return true, nil
+	case !errors.As(err, &conditionFailed):
+		return false, fmt.Errorf("failed to update unread counter for %s: %w", matchID, err)
+	case conditionFailed.Item["rebuiltAt"] != nil:
+		return false, nil // ✅ Already reflected by the rebuild
+	default:
+		return false, s.RebuildSummary(ctx, matchID)
+	}
+}
+
+// setLastMessage stores message as the latest one unless a newer message is already recorded
+func (s *ConversationSummaryService) setLastMessage(ctx context.Context, message models.Message) error {
+	stored, err := attributevalue.Marshal(message)
+	if err != nil {
+		return err
+	}
+	_, err = s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(models.ConversationSummariesTable),
+		Key:                 summaryKey(message.MatchID),
+		UpdateExpression:    aws.String("SET lastMessage = :message, lastMessageAt = :createdAt"),
+		ConditionExpression: aws.String("attribute_not_exists(lastMessageAt) OR lastMessageAt <= :createdAt"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":message":   stored,
+			":createdAt": &types.AttributeValueMemberS{Value: message.CreatedAt},
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if err != nil && !errors.As(err, &conditionFailed) {
+		return fmt.Errorf("failed to update last message for %s: %w", message.MatchID, err)
+	}
+	return nil
+}
+
+// refreshLastMessage re-reads the latest message after the recorded one was deleted
+func (s *ConversationSummaryService) refreshLastMessage(ctx context.Context, matchID, deletedAt string) error {
+	summary, err := s.Dynamo.GetItemAttributes(ctx, models.ConversationSummariesTable, summaryKey(matchID), "lastMessageAt")
+	if err != nil {
+		return err
+	}
+	if lastMessageAt, ok := summary["lastMessageAt"].(*types.AttributeValueMemberS); !ok || lastMessageAt.Value != deletedAt {
+		return nil
+	}
+
+	latest, err := s.latestMessage(ctx, matchID)
+	if err != nil {
+		return err
+	}
+	if latest == nil {
+		_, err = s.Dynamo.UpdateItem(ctx, models.ConversationSummariesTable, "REMOVE lastMessage, lastMessageAt", summaryKey(matchID), nil, nil)
+		return err
+	}
+	stored, err := attributevalue.Marshal(*latest)
+	if err != nil {
+		return err
+	}
+	_, err = s.Dynamo.UpdateItem(ctx, models.ConversationSummariesTable, "SET lastMessage = :message, lastMessageAt = :createdAt", summaryKey(matchID),
+		map[string]types.AttributeValue{
+			":message":   stored,
+			":createdAt": &types.AttributeValueMemberS{Value: latest.CreatedAt},
+		}, nil)
+	return err
+}
+
+// RebuildSummary recomputes a match's summary from its messages. The stream calls it the first time a
+// match is seen, so conversations that predate the worker start with correct counters.
+func (s *ConversationSummaryService) RebuildSummary(ctx context.Context, matchID string) error {
+	rebuiltAt := time.Now().Unix()
+	items, err := s.Dynamo.QueryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.MessagesTable),
+		KeyConditionExpression: aws.String("matchId = :matchId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":matchId": &types.AttributeValueMemberS{Value: matchID},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read messages for %s: %w", matchID, err)
+	}
+
+	unread := map[string]int{}
+	var latest *models.Message
+	for _, item := range items {
+		var message models.Message
+		if err := attributevalue.UnmarshalMap(item, &message); err != nil {
+			continue
+		}
+		if _, ok := unread[message.SenderID]; !ok {
+			unread[message.SenderID] = 0
+		}
+		if message.IsUnreadBool() {
+			unread[message.SenderID]++
+		}
+		if latest == nil || message.CreatedAt >= latest.CreatedAt {
+			latest = &message
+		}
+	}
+
+	sets := []string{"rebuiltAt = :rebuiltAt", "updatedAt = :now"}
+	names := map[string]string{}
+	values := map[string]types.AttributeValue{
+		":rebuiltAt": &types.AttributeValueMemberN{Value: strconv.FormatInt(rebuiltAt, 10)},
+		":now":       &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)},
+	}
+	i := 0
+	for sender, count := range unread {
+		names[fmt.Sprintf("#unread%d", i)] = models.UnreadFromAttrPrefix + sender
+		values[fmt.Sprintf(":unread%d", i)] = &types.AttributeValueMemberN{Value: strconv.Itoa(count)}
+		sets = append(sets, fmt.Sprintf("#unread%d = :unread%d", i, i))
+		i++
+	}
+	if latest != nil {
+		stored, err := attributevalue.Marshal(*latest)
+		if err != nil {
+			return err
+		}
+		values[":message"] = stored
+		values[":createdAt"] = &types.AttributeValueMemberS{Value: latest.CreatedAt}
+		sets = append(sets, "lastMessage = :message", "lastMessageAt = :createdAt")
+	}
+	if len(names) == 0 {
+		names = nil
+	}
+
+	utils.Logf(ctx, "🔄 Rebuilding conversation summary for %s from %d messages", matchID, len(items))
+	_, err = s.Dynamo.UpdateItem(ctx, models.ConversationSummariesTable, "SET "+strings.Join(sets, ", "), summaryKey(matchID), values, names)
+	return err
+}
+
+///// 🔹🔹🔹 Reads 🔹🔹🔹 /////
+
+// GetSummaries batch-reads summaries by matchId. Matches without a (rebuilt) summary are left out;
+// callers fall back to querying messages for those.
+func (s *ConversationSummaryService) GetSummaries(ctx context.Context, matchIDs []string) (map[string]*models.ConversationSummary, error) {
+	summaries := map[string]*models.ConversationSummary{}
+	if len(matchIDs) == 0 {
+		return summaries, nil
+	}
+
+	keys := make([]map[string]types.AttributeValue, len(matchIDs))
+	for i, matchID := range matchIDs {
+		keys[i] = summaryKey(matchID)
+	}
+	items, err := s.Dynamo.BatchGetItems(ctx, models.ConversationSummariesTable, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		if item["rebuiltAt"] == nil {
+			continue // ✅ Only participants known so far
+		}
+		var summary models.ConversationSummary
+		if err := attributevalue.UnmarshalMap(item, &summary); err != nil {
+			utils.Logf(ctx, "⚠️ Skipping unreadable conversation summary: %v", err)
+			continue
+		}
+		counts := map[string]int{}
+		for name, value := range item {
+			if number, ok := value.(*types.AttributeValueMemberN); ok && strings.HasPrefix(name, models.UnreadFromAttrPrefix) {
+				counts[name], _ = strconv.Atoi(number.Value)
+			}
+		}
+		summary.SetUnreadFromAttributes(counts)
+		summaries[summary.MatchID] = &summary
+	}
+	return summaries, nil
+}
+
+///// 🔹🔹🔹 Helpers 🔹🔹🔹 /////
+
+func summaryKey(matchID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"matchId": &types.AttributeValueMemberS{Value: matchID},
+	}
+}
+
+// latestMessage returns the newest stored message of a match, or nil
+func (s *ConversationSummaryService) latestMessage(ctx context.Context, matchID string) (*models.Message, error) {
+	items, err := s.Dynamo.QueryItemsWithOptions(ctx, models.MessagesTable, "matchId = :matchId",
+		map[string]types.AttributeValue{":matchId": &types.AttributeValueMemberS{Value: matchID}}, nil, 1, true)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	var message models.Message
+	if err := attributevalue.UnmarshalMap(items[0], &message); err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// messageImages decodes the old and new images of a Messages stream record (either may be nil)
+func messageImages(record streamtypes.Record) (*models.Message, *models.Message, error) {
+	if record.Dynamodb == nil {
+		return nil, nil, nil
+	}
+	decode := func(image map[string]streamtypes.AttributeValue) (*models.Message, error) {
+		if image == nil {
+			return nil, nil
+		}
+		converted, err := attributevalue.FromDynamoDBStreamsMap(image)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert message image: %w", err)
+		}
+		var message models.Message
+		if err := attributevalue.UnmarshalMap(converted, &message); err != nil {
+			return nil, fmt.Errorf("failed to parse message image: %w", err)
+		}
+		return &message, nil
+	}
+
+	oldMessage, err := decode(record.Dynamodb.OldImage)
+	if err != nil {
+		return nil, nil, err
+	}
+	newMessage, err := decode(record.Dynamodb.NewImage)
+	return oldMessage, newMessage, err
+}
+
+// recordTime is when the change happened (epoch seconds), or now if the stream didn't say
+func recordTime(record streamtypes.Record) int64 {
+	if record.Dynamodb != nil && record.Dynamodb.ApproximateCreationDateTime != nil {
+		return record.Dynamodb.ApproximateCreationDateTime.Unix()
+	}
+	return time.Now().Unix()
+}
@@ -0,0 +1,420 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+)
+
+// ErrCheckInsUnavailable is returned when no SMS sender is configured, so a contact couldn't be reached
+var ErrCheckInsUnavailable = errors.New("check_ins_unavailable")
+
+// e164Pattern matches an E.164 phone number
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+
+// DateCheckInService schedules safety check-ins for planned dates. Once the check-in time passes the
+// user is texted on their profile phone number (and the safety.check_in_due webhook fires)
+// DateCheckInPrompts times; without an answer their emergency contact is texted and the
+// safety.escalated webhook fires. Prompts go out from SweepDueCheckIns, run by StartSweeper on the
+// server and by the scheduled event in the Lambda function.
+type DateCheckInService struct {
+	Dynamo     *DynamoService
+	Encryption *EncryptionService // ✅ Seals the contact's phone number when configured
+	Webhooks   *WebhookService
+	SMS        SMSSender // ✅ nil when no SMS provider is configured; check-ins are unavailable then
+	Profiles   *UserProfileService
+}
+
+// CreateCheckIn registers a planned date with a match and the contact to text if the user goes quiet
+func (s *DateCheckInService) CreateCheckIn(ctx context.Context, request models.DateCheckInRequest) (*models.DateCheckIn, error) {
+	if s.SMS == nil {
+		return nil, ErrCheckInsUnavailable
+	}
+	now := time.Now().UTC()
+	scheduledAt, err := time.Parse(time.RFC3339, request.ScheduledAt)
+	if err != nil {
+		return nil, validationError("scheduledAt must be an RFC3339 time")
+	}
+	if scheduledAt.After(now.Add(models.MaxDatePlanAhead)) {
+		return nil, validationError("dates can be registered up to 30 days ahead")
+	}
+	checkInAt := scheduledAt.Add(models.DefaultDateCheckInDelay)
+	if request.CheckInAt != "" {
+		if checkInAt, err = time.Parse(time.RFC3339, request.CheckInAt); err != nil {
+			return nil, validationError("checkInAt must be an RFC3339 time")
+		}
+		if checkInAt.Before(scheduledAt) || checkInAt.After(scheduledAt.Add(models.MaxDateCheckInDelay)) {
+			return nil, validationError("checkInAt must be within 24 hours after scheduledAt")
+		}
+	}
+	if !checkInAt.After(now) {
+		return nil, validationError("the check-in time has already passed")
+	}
+	if !e164Pattern.MatchString(request.ContactPhone) {
+		return nil, validationError("contactPhone must be an E.164 number, e.g. +14155550123")
+	}
+
+	if _, err := s.promptPhone(ctx, request.UserHandle); err != nil {
+		return nil, err
+	}
+	partner, err := findMatchPartner(ctx, s.Dynamo, request.MatchID, request.UserHandle)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.ListCheckIns(ctx, request.UserHandle)
+	if err != nil {
+		return nil, err
+	}
+	active := 0
+	for _, checkIn := range existing {
+		if checkIn.Status == models.DateCheckInActive {
+			active++
+		}
+	}
+	if active >= models.MaxActiveDateCheckIns {
+		return nil, conflictError(fmt.Sprintf("you can have at most %d upcoming check-ins", models.MaxActiveDateCheckIns))
+	}
+
+	checkIn := &models.DateCheckIn{
+		UserHandle:    request.UserHandle,
+		CheckInID:     uuid.New().String(),
+		MatchID:       request.MatchID,
+		PartnerHandle: partner,
+		ScheduledAt:   scheduledAt.UTC().Format(time.RFC3339),
+		Place:         request.Place,
+		CheckInAt:     checkInAt.UTC().Format(time.RFC3339),
+		ContactName:   request.ContactName,
+		ContactPhone:  request.ContactPhone,
+		Status:        models.DateCheckInActive,
+		NextActionAt:  checkInAt.UTC().Format(time.RFC3339),
+		CreatedAt:     now.Format(time.RFC3339),
+		ExpiresAt:     checkInAt.AddDate(0, 0, models.DateCheckInRetentionDays).Unix(),
+	}
+	stored := *checkIn
+	if s.Encryption.Enabled() {
+		ciphertext, version, err := s.Encryption.Encrypt(ctx, piiKeyScope, checkIn.ContactPhone)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt contact phone: %w", err)
+		}
+		stored.ContactPhone, stored.Encrypted, stored.KeyVersion = ciphertext, true, version
+	}
+	if err := s.Dynamo.PutItem(ctx, models.DateCheckInsTable, stored); err != nil {
+		return nil, fmt.Errorf("failed to store check-in: %w", err)
+	}
+
+	utils.Logf(ctx, "🛡️ %s registered a date in matchId %s; check-in at %s", request.UserHandle, request.MatchID, checkIn.CheckInAt)
+	return checkIn, nil
+}
+
+// ListCheckIns returns the user's check-ins, soonest date first
+func (s *DateCheckInService) ListCheckIns(ctx context.Context, userHandle string) ([]models.DateCheckIn, error) {
+	items, err := s.Dynamo.QueryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.DateCheckInsTable),
+		KeyConditionExpression: aws.String("userhandle = :handle"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":handle": &types.AttributeValueMemberS{Value: userHandle},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query check-ins: %w", err)
+	}
+
+	checkIns := []models.DateCheckIn{}
+	if err := attributevalue.UnmarshalListOfMaps(items, &checkIns); err != nil {
+		return nil, fmt.Errorf("failed to parse check-ins: %w", err)
+	}
+	for i := range checkIns {
+		s.decryptContact(ctx, &checkIns[i])
+	}
+	sort.Slice(checkIns, func(i, j int) bool { return checkIns[i].ScheduledAt < checkIns[j].ScheduledAt })
+	return checkIns, nil
+}
+
+// ConfirmSafe records that the user is safe. After an escalation the contact is told the user checked in.
+func (s *DateCheckInService) ConfirmSafe(ctx context.Context, userHandle, checkInID string) (*models.DateCheckIn, error) {
+	checkIn, previous, err := s.resolve(ctx, userHandle, checkInID, models.DateCheckInSafe, models.DateCheckInActive, models.DateCheckInEscalated)
+	if err != nil {
+		return nil, err
+	}
+	if previous == models.DateCheckInEscalated && s.SMS != nil {
+		body := fmt.Sprintf("Vibin: %s has checked in and says they're safe. Thanks for being their emergency contact.", checkIn.UserHandle)
+		if err := s.SMS.SendSMS(ctx, checkIn.ContactPhone, body); err != nil {
+			utils.Logf(ctx, "⚠️ Failed to tell the contact of %s they're safe: %v", userHandle, err)
+		}
+	}
+
+	utils.Logf(ctx, "✅ %s confirmed they're safe (check-in %s)", userHandle, checkInID)
+	return checkIn, nil
+}
+
+// CancelCheckIn stops an upcoming check-in
+func (s *DateCheckInService) CancelCheckIn(ctx context.Context, userHandle, checkInID string) (*models.DateCheckIn, error) {
+	checkIn, _, err := s.resolve(ctx, userHandle, checkInID, models.DateCheckInCancelled, models.DateCheckInActive)
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Logf(ctx, "🗑️ %s cancelled check-in %s", userHandle, checkInID)
+	return checkIn, nil
+}
+
+// resolve moves a check-in from one of the given states to its final state, returning the updated
+// check-in and the state it left
+func (s *DateCheckInService) resolve(ctx context.Context, userHandle, checkInID, status string, from ...string) (*models.DateCheckIn, string, error) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	values := map[string]types.AttributeValue{
+		":status": &types.AttributeValueMemberS{Value: status},
+		":now":    &types.AttributeValueMemberS{Value: now},
+	}
+	condition := "attribute_exists(checkInId) AND #status IN ("
+	for i, state := range from {
+		placeholder := fmt.Sprintf(":from%d", i)
+		values[placeholder] = &types.AttributeValueMemberS{Value: state}
+		if i > 0 {
+			condition += ", "
+		}
+		condition += placeholder
+	}
+	condition += ")"
+
+	output, err := s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(models.DateCheckInsTable),
+		Key: map[string]types.AttributeValue{
+			"userhandle": &types.AttributeValueMemberS{Value: userHandle},
+			"checkInId":  &types.AttributeValueMemberS{Value: checkInID},
+		},
+		UpdateExpression:                    aws.String("SET #status = :status, resolvedAt = :now REMOVE nextActionAt"),
+		ConditionExpression:                 aws.String(condition),
+		ExpressionAttributeNames:            map[string]string{"#status": "status"},
+		ExpressionAttributeValues:           values,
+		ReturnValues:                        types.ReturnValueAllOld,
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		if len(conditionFailed.Item) == 0 {
+			return nil, "", notFoundError("check-in not found")
+		}
+		return nil, "", conflictError("check-in is no longer " + from[0])
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to update check-in %s: %w", checkInID, err)
+	}
+
+	var checkIn models.DateCheckIn
+	if err := attributevalue.UnmarshalMap(output.Attributes, &checkIn); err != nil {
+		return nil, "", fmt.Errorf("failed to parse check-in: %w", err)
+	}
+	s.decryptContact(ctx, &checkIn)
+	previous := checkIn.Status
+	checkIn.Status, checkIn.ResolvedAt = status, now
+	return &checkIn, previous, nil
+}
+
+// StartSweeper prompts and escalates due check-ins, now and then every interval until ctx is
+// cancelled. Each step is claimed with a conditional write, so several instances may sweep at once.
+func (s *DateCheckInService) StartSweeper(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := s.SweepDueCheckIns(ctx); err != nil {
+				utils.Logf(ctx, "⚠️ Date check-in sweep failed: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// SweepDueCheckIns sends the next prompt, or escalates, for every active check-in that is due
+func (s *DateCheckInService) SweepDueCheckIns(ctx context.Context) error {
+	now := time.Now().UTC()
+	items, err := s.Dynamo.QueryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.DateCheckInsTable),
+		IndexName:              aws.String(models.DateCheckInDueIndex),
+		KeyConditionExpression: aws.String("#status = :active AND nextActionAt <= :now"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":active": &types.AttributeValueMemberS{Value: models.DateCheckInActive},
+			":now":    &types.AttributeValueMemberS{Value: now.Format(time.RFC3339)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	var checkIns []models.DateCheckIn
+	if err := attributevalue.UnmarshalListOfMaps(items, &checkIns); err != nil {
+		return fmt.Errorf("failed to parse due check-ins: %w", err)
+	}
+	for i := range checkIns {
+		checkIn := &checkIns[i]
+		if checkIn.PromptsSent < models.DateCheckInPrompts {
+			err = s.prompt(ctx, checkIn, now)
+		} else {
+			err = s.escalate(ctx, checkIn, now)
+		}
+		if err != nil {
+			utils.Logf(ctx, "❌ Failed to process check-in %s of %s, will retry next sweep: %v", checkIn.CheckInID, checkIn.UserHandle, err)
+		}
+	}
+	return nil
+}
+
+// prompt asks the user to confirm they're safe and gives them DateCheckInPromptInterval to answer
+func (s *DateCheckInService) prompt(ctx context.Context, checkIn *models.DateCheckIn, now time.Time) error {
+	claimed, err := s.claim(ctx, checkIn, now.Add(models.DateCheckInPromptInterval), checkIn.PromptsSent+1)
+	if err != nil || !claimed {
+		return err
+	}
+	s.Webhooks.Publish(ctx, models.WebhookCheckInDue, map[string]interface{}{
+		"userhandle": checkIn.UserHandle,
+		"checkInId":  checkIn.CheckInID,
+		"prompt":     checkIn.PromptsSent + 1,
+	})
+
+	// ✅ The prompt is claimed before the text goes out, so a failed text is not resent; the next
+	// prompt (or the escalation) still follows
+	phone, err := s.promptPhone(ctx, checkIn.UserHandle)
+	if err == nil {
+		body := fmt.Sprintf("Vibin check-in: how did your date at %s go? Open Vibin and tap \"I'm safe\", or we'll text %s.",
+			checkIn.Place, checkIn.ContactName)
+		err = s.SMS.SendSMS(ctx, phone, body)
+	}
+	if err != nil {
+		utils.Logf(ctx, "⚠️ Failed to text check-in prompt to %s: %v", checkIn.UserHandle, err)
+	}
+
+	utils.Logf(ctx, "🔔 Prompted %s to check in (%d of %d)", checkIn.UserHandle, checkIn.PromptsSent+1, models.DateCheckInPrompts)
+	return nil
+}
+
+// promptPhone is the number check-in prompts are texted to: the phone number on the user's profile
+func (s *DateCheckInService) promptPhone(ctx context.Context, userHandle string) (string, error) {
+	if s.SMS == nil {
+		return "", ErrCheckInsUnavailable
+	}
+	profile, err := s.Profiles.GetUserProfileByHandle(ctx, userHandle)
+	if err != nil {
+		return "", err
+	}
+	if !e164Pattern.MatchString(profile.PhoneNumber) {
+		return "", validationError("add a phone number to your profile so check-in prompts can reach you")
+	}
+	return profile.PhoneNumber, nil
+}
+
+// escalate texts the emergency contact. The step is claimed for DateCheckInRetryInterval first, so
+// a failed text is retried by a later sweep and concurrent sweepers don't text twice.
+func (s *DateCheckInService) escalate(ctx context.Context, checkIn *models.DateCheckIn, now time.Time) error {
+	claimed, err := s.claim(ctx, checkIn, now.Add(models.DateCheckInRetryInterval), checkIn.PromptsSent)
+	if err != nil || !claimed {
+		return err
+	}
+	if s.SMS == nil {
+		return fmt.Errorf("no SMS sender configured")
+	}
+	s.decryptContact(ctx, checkIn)
+	if checkIn.ContactPhone == "" {
+		return fmt.Errorf("contact phone is unavailable")
+	}
+
+	body := fmt.Sprintf("Vibin safety alert for %s: %s hasn't checked in after their date at %s (started %s). Please try to reach them.",
+		checkIn.ContactName, checkIn.UserHandle, checkIn.Place, checkIn.ScheduledAt)
+	if err := s.SMS.SendSMS(ctx, checkIn.ContactPhone, body); err != nil {
+		return fmt.Errorf("failed to text emergency contact: %w", err)
+	}
+
+	_, err = s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(models.DateCheckInsTable),
+		Key: map[string]types.AttributeValue{
+			"userhandle": &types.AttributeValueMemberS{Value: checkIn.UserHandle},
+			"checkInId":  &types.AttributeValueMemberS{Value: checkIn.CheckInID},
+		},
+		UpdateExpression:    aws.String("SET #status = :escalated, resolvedAt = :now REMOVE nextActionAt"),
+		ConditionExpression: aws.String("#status = :active"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":escalated": &types.AttributeValueMemberS{Value: models.DateCheckInEscalated},
+			":active":    &types.AttributeValueMemberS{Value: models.DateCheckInActive},
+			":now":       &types.AttributeValueMemberS{Value: now.Format(time.RFC3339)},
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if err != nil && !errors.As(err, &conditionFailed) { // ✅ Answered while the text went out; the contact was still texted
+		return fmt.Errorf("failed to mark check-in escalated: %w", err)
+	}
+	s.Webhooks.Publish(ctx, models.WebhookCheckInEscalated, map[string]interface{}{
+		"userhandle":    checkIn.UserHandle,
+		"checkInId":     checkIn.CheckInID,
+		"matchId":       checkIn.MatchID,
+		"partnerHandle": checkIn.PartnerHandle,
+		"place":         checkIn.Place,
+	})
+
+	utils.Logf(ctx, "🚨 Escalated check-in %s of %s to their emergency contact", checkIn.CheckInID, checkIn.UserHandle)
+	return nil
+}
+
+// claim moves an active check-in's next action time forward, only if no other sweeper did first
+func (s *DateCheckInService) claim(ctx context.Context, checkIn *models.DateCheckIn, next time.Time, promptsSent int) (bool, error) {
+	_, err := s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(models.DateCheckInsTable),
+		Key: map[string]types.AttributeValue{
+			"userhandle": &types.AttributeValueMemberS{Value: checkIn.UserHandle},
+			"checkInId":  &types.AttributeValueMemberS{Value: checkIn.CheckInID},
+		},
+		UpdateExpression:    aws.String("SET nextActionAt = :next, promptsSent = :prompts"),
+		ConditionExpression: aws.String("#status = :active AND nextActionAt = :due"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":next":    &types.AttributeValueMemberS{Value: next.Format(time.RFC3339)},
+			":prompts": &types.AttributeValueMemberN{Value: strconv.Itoa(promptsSent)},
+			":active":  &types.AttributeValueMemberS{Value: models.DateCheckInActive},
+			":due":     &types.AttributeValueMemberS{Value: checkIn.NextActionAt},
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to claim check-in %s: %w", checkIn.CheckInID, err)
+	}
+	return true, nil
+}
+
+// decryptContact replaces a sealed contact phone with plaintext; an undecryptable one is blanked
+func (s *DateCheckInService) decryptContact(ctx context.Context, checkIn *models.DateCheckIn) {
+	if !checkIn.Encrypted {
+		return
+	}
+	phone, err := s.Encryption.Decrypt(ctx, piiKeyScope, checkIn.ContactPhone, checkIn.KeyVersion)
+	if err != nil {
+		utils.Logf(ctx, "❌ Failed to decrypt contact phone of check-in %s: %v", checkIn.CheckInID, err)
+		phone = ""
+	}
+	checkIn.ContactPhone, checkIn.Encrypted = phone, false
+}
@@ -0,0 +1,25 @@
+package services
+
+import "vibin_server/models"
+
+// dateIdeaCatalog is the static dataset DateIdeasService ranks for each match
+var dateIdeaCatalog = []models.DateIdea{
+	{ID: "coffee", Title: "Coffee and a walk", Description: "Grab coffee and stroll the neighbourhood — easy, low-pressure first date.", Category: "relaxed", Interests: []string{"coffee", "walking", "reading"}, SearchQuery: "coffee shop", PriceLevel: 1},
+	{ID: "brunch", Title: "Weekend brunch", Description: "Share a long brunch somewhere with good pancakes.", Category: "food", Interests: []string{"food", "cooking", "coffee"}, SearchQuery: "brunch", PriceLevel: 2},
+	{ID: "street-food", Title: "Street food crawl", Description: "Try a few stalls and rate them together.", Category: "food", Interests: []string{"food", "travel", "cooking"}, SearchQuery: "street food market", PriceLevel: 1},
+	{ID: "cooking-class", Title: "Cooking class", Description: "Learn a new dish side by side.", Category: "food", Interests: []string{"cooking", "food"}, SearchQuery: "cooking class", PriceLevel: 3},
+	{ID: "hike", Title: "Sunrise hike", Description: "Pick a short trail with a view at the top.", Category: "outdoors", Interests: []string{"hiking", "nature", "fitness", "photography"}, SearchQuery: "hiking trail", Outdoor: true},
+	{ID: "picnic", Title: "Picnic in the park", Description: "Bring snacks, a blanket and a playlist.", Category: "outdoors", Interests: []string{"nature", "music", "food"}, SearchQuery: "park", Outdoor: true, PriceLevel: 1},
+	{ID: "cycling", Title: "Bike ride", Description: "Rent bikes and ride along a scenic route.", Category: "active", Interests: []string{"cycling", "fitness", "nature"}, SearchQuery: "bike rental", Outdoor: true, PriceLevel: 1},
+	{ID: "climbing", Title: "Bouldering session", Description: "Beginner-friendly climbing gym — lots of high fives.", Category: "active", Interests: []string{"fitness", "climbing", "sports"}, SearchQuery: "bouldering gym", PriceLevel: 2},
+	{ID: "museum", Title: "Museum visit", Description: "Wander an exhibition and pick favourites.", Category: "culture", Interests: []string{"art", "history", "photography"}, SearchQuery: "museum", PriceLevel: 1},
+	{ID: "gallery", Title: "Gallery hop", Description: "Visit a couple of small galleries and talk about what you see.", Category: "culture", Interests: []string{"art", "design", "photography"}, SearchQuery: "art gallery", PriceLevel: 0},
+	{ID: "bookstore", Title: "Bookstore date", Description: "Pick a book for each other, then read the first page over tea.", Category: "relaxed", Interests: []string{"reading", "writing", "coffee"}, SearchQuery: "bookstore cafe", PriceLevel: 1},
+	{ID: "live-music", Title: "Live music", Description: "Catch a small gig or open mic night.", Category: "nightlife", Interests: []string{"music", "dancing", "concerts"}, SearchQuery: "live music venue", PriceLevel: 2},
+	{ID: "comedy", Title: "Comedy night", Description: "Laughing together is a great icebreaker.", Category: "nightlife", Interests: []string{"comedy", "movies"}, SearchQuery: "comedy club", PriceLevel: 2},
+	{ID: "board-games", Title: "Board game cafe", Description: "Friendly competition over a board game and snacks.", Category: "relaxed", Interests: []string{"gaming", "board-games", "coffee"}, SearchQuery: "board game cafe", PriceLevel: 1},
+	{ID: "movie", Title: "Movie night", Description: "See something new at an indie cinema.", Category: "culture", Interests: []string{"movies"}, SearchQuery: "cinema", PriceLevel: 2},
+	{ID: "yoga", Title: "Outdoor yoga", Description: "Join a drop-in yoga class, then smoothies.", Category: "active", Interests: []string{"yoga", "fitness", "meditation"}, SearchQuery: "yoga studio", Outdoor: true, PriceLevel: 1},
+	{ID: "karaoke", Title: "Karaoke", Description: "Book a private room and pick each other's songs.", Category: "nightlife", Interests: []string{"music", "singing", "dancing"}, SearchQuery: "karaoke", PriceLevel: 2},
+	{ID: "farmers-market", Title: "Farmers market", Description: "Browse stalls and pick ingredients for a shared snack.", Category: "food", Interests: []string{"food", "cooking", "nature"}, SearchQuery: "farmers market", Outdoor: true, PriceLevel: 1},
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ErrMatchNotFound is returned when the user is not part of the requested match
+var ErrMatchNotFound = notFoundError("match_not_found")
+
+// DateIdeasService suggests activities and a meeting point for a match from a static catalog
+type DateIdeasService struct {
+	Dynamo             *DynamoService
+	UserProfileService *UserProfileService
+}
+
+// SuggestDateIdeas ranks catalog ideas by the pair's shared (then individual) interests and
+// returns the midpoint between their locations for the client to search nearby venues
+func (s *DateIdeasService) SuggestDateIdeas(ctx context.Context, matchID, userHandle string, limit int) (*models.DateIdeasResponse, error) {
+	utils.Logf(ctx, "🔍 Suggesting date ideas for matchId: %s (requested by %s)", matchID, userHandle)
+
+	partner, err := findMatchPartner(ctx, s.Dynamo, matchID, userHandle)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles, err := s.UserProfileService.GetUserProfilesByHandles(ctx, []string{userHandle, partner})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch profiles: %w", err)
+	}
+	me, them := profiles[userHandle], profiles[partner]
+	if me == nil || them == nil {
+		return nil, ErrMatchNotFound
+	}
+
+	// ✅ Interests and locations are only used when neither user objected to personalization
+	personalized := me.EffectiveConsents().Allows(models.PurposePersonalizedRanking) &&
+		them.EffectiveConsents().Allows(models.PurposePersonalizedRanking)
+
+	response := &models.DateIdeasResponse{MatchID: matchID, SharedInterests: []string{}, Personalized: personalized}
+	var myInterests, theirInterests map[string]bool
+	if personalized {
+		myInterests, theirInterests = interestSet(profileInterestIDs(me)), interestSet(profileInterestIDs(them))
+		response.SharedInterests = append(response.SharedInterests, sharedInterests(me, them)...)
+		sort.Strings(response.SharedInterests)
+		response.MeetingPoint = meetingPoint(me, them)
+	}
+
+	suggestions := make([]models.DateIdeaSuggestion, 0, len(dateIdeaCatalog))
+	for _, idea := range dateIdeaCatalog {
+		suggestion := models.DateIdeaSuggestion{DateIdea: idea}
+		for _, interest := range idea.Interests {
+			switch {
+			case myInterests[interest] && theirInterests[interest]:
+				suggestion.Score += 3
+				suggestion.MatchedInterests = append(suggestion.MatchedInterests, interest)
+			case myInterests[interest] || theirInterests[interest]:
+				suggestion.Score++
+			}
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Score > suggestions[j].Score
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	response.Ideas = suggestions
+
+	utils.Logf(ctx, "✅ Suggested %d date ideas for matchId: %s (%d shared interests)", len(response.Ideas), matchID, len(response.SharedInterests))
+	return response, nil
+}
+
+// findMatchPartner returns the other participant of a match the user belongs to
+func findMatchPartner(ctx context.Context, dynamo *DynamoService, matchID, userHandle string) (string, error) {
+	match, err := findMatchRecord(ctx, dynamo, matchID, userHandle)
+	if err != nil {
+		return "", err
+	}
+	return otherParticipant(*match, userHandle), nil
+}
+
+// findMatchRecord returns the user's own record of a match they belong to
+func findMatchRecord(ctx context.Context, dynamo *DynamoService, matchID, userHandle string) (*models.Interaction, error) {
+	items, err := dynamo.QueryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.InteractionsTable),
+		IndexName:              aws.String(models.StatusIndex),
+		KeyConditionExpression: aws.String("#PK = :user AND #status = :matchStatus"),
+		FilterExpression:       aws.String("matchId = :matchId"),
+		ExpressionAttributeNames: map[string]string{
+			"#PK":     "PK",
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":user":        &types.AttributeValueMemberS{Value: "USER#" + userHandle},
+			":matchStatus": &types.AttributeValueMemberS{Value: models.StatusMatch},
+			":matchId":     &types.AttributeValueMemberS{Value: matchID},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up match: %w", err)
+	}
+
+	for _, interaction := range unmarshalInteractions(items) {
+		if otherParticipant(interaction, userHandle) != "" {
+			return &interaction, nil
+		}
+	}
+	return nil, ErrMatchNotFound
+}
+
+// interestSet indexes catalog interest IDs
+func interestSet(ids []string) map[string]bool {
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+// meetingPoint is the midpoint of the two locations; nil when either is missing
+func meetingPoint(a, b *models.UserProfile) *models.MeetingPoint {
+	if a.Latitude == 0 && a.Longitude == 0 || b.Latitude == 0 && b.Longitude == 0 {
+		return nil
+	}
+	return &models.MeetingPoint{
+		Latitude:          (a.Latitude + b.Latitude) / 2,
+		Longitude:         (a.Longitude + b.Longitude) / 2,
+		DistanceBetweenKm: haversine(a.Latitude, a.Longitude, b.Latitude, b.Longitude),
+	}
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"math/rand"
+	"time"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/smithy-go"
+)
+
+// dynamoRetryMetrics exposes retry counters on /admin/debug/vars: "<operation>.retries" counts extra
+// attempts, "<operation>.recovered" calls that succeeded after retrying and "<operation>.exhausted"
+// calls that still failed after the last attempt
+var dynamoRetryMetrics = expvar.NewMap("dynamo_retries")
+
+// DynamoRetryPolicy controls the retries DynamoService adds on top of the SDK's own
+type DynamoRetryPolicy struct {
+	MaxAttempts int           // Total attempts per call, including the first (default 3)
+	BaseDelay   time.Duration // Backoff before the first retry, doubled each time (default 50ms)
+	MaxDelay    time.Duration // Backoff cap (default 1s)
+}
+
+// withDefaults fills zero fields so an unset policy still retries sensibly
+func (p DynamoRetryPolicy) withDefaults() DynamoRetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 3
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = 50 * time.Millisecond
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = time.Second
+	}
+	return p
+}
+
+// backoff returns a full-jitter delay for the given retry (1-based)
+func (p DynamoRetryPolicy) backoff(retryNumber int) time.Duration {
+	ceiling := p.BaseDelay << (retryNumber - 1)
+	if ceiling <= 0 || ceiling > p.MaxDelay {
+		ceiling = p.MaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(ceiling)) + 1)
+}
+
+// retryDynamo runs call until it succeeds, fails permanently, runs out of attempts or ctx ends.
+// Throttling is always retried (the request was rejected, so nothing was applied); other
+// transient failures are only retried when the operation is idempotent.
+func retryDynamo[T any](ctx context.Context, ds *DynamoService, operation string, idempotent bool, call func() (T, error)) (T, error) {
+	policy := ds.Retry.withDefaults()
+
+	var result T
+	var err error
+	for attempt := 1; ; attempt++ {
+		result, err = call()
+		if err == nil {
+			if attempt > 1 {
+				dynamoRetryMetrics.Add(operation+".recovered", 1)
+			}
+			return result, nil
+		}
+		if !retryableDynamoError(err, idempotent) || ctx.Err() != nil {
+			return result, err
+		}
+		if attempt >= policy.MaxAttempts {
+			dynamoRetryMetrics.Add(operation+".exhausted", 1)
+			utils.Logf(ctx, "❌ DynamoDB %s failed after %d attempts: %v", operation, attempt, err)
+			return result, err
+		}
+
+		delay := policy.backoff(attempt)
+		dynamoRetryMetrics.Add(operation+".retries", 1)
+		utils.Logf(ctx, "🔄 DynamoDB %s attempt %d failed (%v), retrying in %s", operation, attempt, err, delay)
+
+		if !sleepContext(ctx, delay) {
+			return result, err
+		}
+	}
+}
+
+// sleepContext waits for d, returning false early if ctx ends first
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// retryableDynamoError classifies throttling and transient service errors
+func retryableDynamoError(err error, idempotent bool) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		if _, throttled := retry.DefaultThrottleErrorCodes[apiErr.ErrorCode()]; throttled {
+			return true
+		}
+		if !idempotent {
+			return false
+		}
+		if _, transient := retry.DefaultRetryableErrorCodes[apiErr.ErrorCode()]; transient {
+			return true
+		}
+		switch apiErr.ErrorCode() {
+		case "InternalServerError", "ServiceUnavailable":
+			return true
+		}
+	}
+	if !idempotent {
+		return false
+	}
+	var statusErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &statusErr) && statusErr.HTTPStatusCode() >= 500 {
+		return true
+	}
+	var timeoutErr interface{ Timeout() bool }
+	return errors.As(err, &timeoutErr) && timeoutErr.Timeout()
+}
+
+// query runs a Query with retries
+func (ds *DynamoService) query(ctx context.Context, input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	return retryDynamo(ctx, ds, "Query", true, func() (*dynamodb.QueryOutput, error) {
+		return ds.Client.Query(ctx, input)
+	})
+}
+
+// scan runs a Scan with retries
+func (ds *DynamoService) scan(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+	return retryDynamo(ctx, ds, "Scan", true, func() (*dynamodb.ScanOutput, error) {
+		return ds.Client.Scan(ctx, input)
+	})
+}
+
+// getItem runs a GetItem with retries
+func (ds *DynamoService) getItem(ctx context.Context, input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	return retryDynamo(ctx, ds, "GetItem", true, func() (*dynamodb.GetItemOutput, error) {
+		return ds.Client.GetItem(ctx, input)
+	})
+}
+
+// putItem runs a PutItem with retries (a repeated put writes the same item)
+func (ds *DynamoService) putItem(ctx context.Context, input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	return retryDynamo(ctx, ds, "PutItem", input.ConditionExpression == nil, func() (*dynamodb.PutItemOutput, error) {
+		return ds.Client.PutItem(ctx, input)
+	})
+}
+
+// updateItem runs an UpdateItem with retries; only throttling is retried since updates like ADD
+// are not safe to apply twice
+func (ds *DynamoService) updateItem(ctx context.Context, input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+	return retryDynamo(ctx, ds, "UpdateItem", false, func() (*dynamodb.UpdateItemOutput, error) {
+		return ds.Client.UpdateItem(ctx, input)
+	})
+}
@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"os"
+	"strings"
+	"vibin_server/utils"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
@@ -15,20 +17,22 @@ import (
 
 type DynamoService struct {
 	Client *dynamodb.Client
+	Retry  DynamoRetryPolicy // ✅ Extra retries for Query/GetItem/PutItem/UpdateItem (zero value = defaults)
 }
 
 // InitializeDynamoDBClient initializes the DynamoDB client
-func InitializeDynamoDBClient() *dynamodb.Client {
-	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion(os.Getenv("AWS_REGION")))
+func InitializeDynamoDBClient(region string) *dynamodb.Client {
+	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion(region))
 	if err != nil {
 		log.Fatalf("Failed to load AWS config: %v", err)
 	}
+	cfg.APIOptions = append(cfg.APIOptions, addDynamoTracing) // ✅ One span per DynamoDB call
 	return dynamodb.NewFromConfig(cfg)
 }
 
 func (d *DynamoService) QueryItemsWithQueryInput(ctx context.Context, input *dynamodb.QueryInput) ([]map[string]types.AttributeValue, error) {
 	// Execute DynamoDB Query
-	result, err := d.Client.Query(ctx, input)
+	result, err := d.query(ctx, input)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query DynamoDB: %w", err)
 	}
@@ -102,14 +106,35 @@ func stringJoin(parts []string, delimiter string) string {
 	return result
 }
 
-// BatchWriteItems writes multiple items to DynamoDB in batches
+// ErrBatchWritePartial is matched (via errors.Is) by BatchWriteError
+var ErrBatchWritePartial = errors.New("batch_write_partial")
+
+// BatchWriteError reports the writes DynamoDB still hadn't processed after every retry;
+// the other writes were applied
+type BatchWriteError struct {
+	TableName   string
+	Written     int
+	Unprocessed []types.WriteRequest
+}
+
+func (e *BatchWriteError) Error() string {
+	return fmt.Sprintf("batch write to table '%s' incomplete: %d written, %d unprocessed", e.TableName, e.Written, len(e.Unprocessed))
+}
+
+func (e *BatchWriteError) Unwrap() error { return ErrBatchWritePartial }
+
+// BatchWriteItems writes multiple items to DynamoDB in batches, retrying UnprocessedItems with
+// backoff. If some writes are still unprocessed it returns a *BatchWriteError listing them.
 func (ds *DynamoService) BatchWriteItems(
 	ctx context.Context,
 	tableName string,
 	writeRequests []types.WriteRequest,
 ) error {
 	const maxBatchSize = 25
+	const maxUnprocessedRetries = 5
+	policy := ds.Retry.withDefaults()
 
+	var unprocessed []types.WriteRequest
 	// Process requests in batches of 25
 	for i := 0; i < len(writeRequests); i += maxBatchSize {
 		end := i + maxBatchSize
@@ -117,20 +142,36 @@ func (ds *DynamoService) BatchWriteItems(
 			end = len(writeRequests)
 		}
 
-		// Create batch input
-		batchInput := &dynamodb.BatchWriteItemInput{
-			RequestItems: map[string][]types.WriteRequest{
-				tableName: writeRequests[i:end],
-			},
-		}
-
-		// Execute batch write
-		_, err := ds.Client.BatchWriteItem(ctx, batchInput)
-		if err != nil {
-			return fmt.Errorf("failed to batch write items to table '%s': %w", tableName, err)
+		pending := writeRequests[i:end]
+		for attempt := 0; len(pending) > 0; attempt++ {
+			if attempt > 0 {
+				if attempt > maxUnprocessedRetries {
+					dynamoRetryMetrics.Add("BatchWriteItem.exhausted", 1)
+					break
+				}
+				dynamoRetryMetrics.Add("BatchWriteItem.retries", 1)
+				utils.Logf(ctx, "🔄 %d writes to '%s' unprocessed, retry %d", len(pending), tableName, attempt)
+				if !sleepContext(ctx, policy.backoff(attempt)) {
+					break
+				}
+			}
+
+			// Execute batch write
+			output, err := ds.Client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+				RequestItems: map[string][]types.WriteRequest{tableName: pending},
+			})
+			if err != nil {
+				return fmt.Errorf("failed to batch write items to table '%s': %w", tableName, err)
+			}
+			pending = output.UnprocessedItems[tableName]
 		}
+		unprocessed = append(unprocessed, pending...)
 	}
 
+	if len(unprocessed) > 0 {
+		utils.Logf(ctx, "❌ %d of %d writes to '%s' left unprocessed", len(unprocessed), len(writeRequests), tableName)
+		return &BatchWriteError{TableName: tableName, Written: len(writeRequests) - len(unprocessed), Unprocessed: unprocessed}
+	}
 	return nil
 }
 
@@ -144,7 +185,7 @@ func (ds *DynamoService) QueryItemsWithIndex(
 	expressionAttributeNames map[string]string,
 	limit int32,
 ) ([]map[string]types.AttributeValue, error) {
-	log.Printf("🔍 Querying GSI: %s in table: %s", indexName, tableName)
+	utils.Logf(ctx, "🔍 Querying GSI: %s in table: %s", indexName, tableName)
 
 	queryInput := &dynamodb.QueryInput{
 		TableName:                 &tableName,
@@ -155,12 +196,12 @@ func (ds *DynamoService) QueryItemsWithIndex(
 		Limit:                     &limit,
 	}
 
-	output, err := ds.Client.Query(ctx, queryInput)
+	output, err := ds.query(ctx, queryInput)
 	if err != nil {
-		log.Printf("❌ Error querying GSI: %v", err)
+		utils.Logf(ctx, "❌ Error querying GSI: %v", err)
 		return nil, fmt.Errorf("failed to query GSI '%s': %w", indexName, err)
 	}
-	log.Printf("✅ Query successful. Retrieved %d items.", len(output.Items))
+	utils.Logf(ctx, "✅ Query successful. Retrieved %d items.", len(output.Items))
 	return output.Items, nil
 }
 
@@ -174,7 +215,7 @@ func (ds *DynamoService) QueryItemsWithOptions(
 	limit int32,
 	latestFirst bool,
 ) ([]map[string]types.AttributeValue, error) {
-	log.Printf("🔍 Querying table '%s' with sorting: %v, limit: %d", tableName, latestFirst, limit)
+	utils.Logf(ctx, "🔍 Querying table '%s' with sorting: %v, limit: %d", tableName, latestFirst, limit)
 
 	scanIndexForward := latestFirst == false // `false` = latest first
 	queryInput := &dynamodb.QueryInput{
@@ -186,13 +227,13 @@ func (ds *DynamoService) QueryItemsWithOptions(
 		ScanIndexForward:          &scanIndexForward,
 	}
 
-	output, err := ds.Client.Query(ctx, queryInput)
+	output, err := ds.query(ctx, queryInput)
 	if err != nil {
-		log.Printf("❌ Failed to query DynamoDB table '%s': %v", tableName, err)
+		utils.Logf(ctx, "❌ Failed to query DynamoDB table '%s': %v", tableName, err)
 		return nil, fmt.Errorf("failed to query table '%s': %w", tableName, err)
 	}
 
-	log.Printf("✅ Retrieved %d items from table '%s'", len(output.Items), tableName)
+	utils.Logf(ctx, "✅ Retrieved %d items from table '%s'", len(output.Items), tableName)
 	return output.Items, nil
 }
 
@@ -205,23 +246,25 @@ func (ds *DynamoService) QueryItems(
 	expressionAttributeNames map[string]string,
 	limit int32,
 ) ([]map[string]types.AttributeValue, error) {
-	log.Printf("🔍 Querying table '%s' with KeyCondition: %s", tableName, keyConditionExpression)
+	utils.Logf(ctx, "🔍 Querying table '%s' with KeyCondition: %s", tableName, keyConditionExpression)
 
 	queryInput := &dynamodb.QueryInput{
 		TableName:                 &tableName,
 		KeyConditionExpression:    &keyConditionExpression,
 		ExpressionAttributeValues: expressionAttributeValues,
 		ExpressionAttributeNames:  expressionAttributeNames,
-		Limit:                     &limit,
+	}
+	if limit > 0 { // ✅ 0 means no limit (DynamoDB rejects Limit: 0)
+		queryInput.Limit = &limit
 	}
 
-	output, err := ds.Client.Query(ctx, queryInput)
+	output, err := ds.query(ctx, queryInput)
 	if err != nil {
-		log.Printf("❌ Failed to query table '%s': %v", tableName, err)
+		utils.Logf(ctx, "❌ Failed to query table '%s': %v", tableName, err)
 		return nil, fmt.Errorf("query error: %w", err)
 	}
 
-	log.Printf("✅ Retrieved %d items from table '%s'", len(output.Items), tableName)
+	utils.Logf(ctx, "✅ Retrieved %d items from table '%s'", len(output.Items), tableName)
 	return output.Items, nil
 }
 
@@ -236,7 +279,7 @@ func (ds *DynamoService) QueryItemsWithIndexWithFilters(
 	filterExpression string,
 	limit int32,
 ) ([]map[string]types.AttributeValue, error) {
-	log.Printf("🔍 Querying GSI '%s' on table '%s'", indexName, tableName)
+	utils.Logf(ctx, "🔍 Querying GSI '%s' on table '%s'", indexName, tableName)
 
 	queryInput := &dynamodb.QueryInput{
 		TableName:                 &tableName,
@@ -250,62 +293,92 @@ func (ds *DynamoService) QueryItemsWithIndexWithFilters(
 	// ✅ Apply FilterExpression if provided
 	if filterExpression != "" {
 		queryInput.FilterExpression = &filterExpression
-		log.Printf("📌 Applying FilterExpression: %s", filterExpression)
+		utils.Logf(ctx, "📌 Applying FilterExpression: %s", filterExpression)
 	}
 
-	output, err := ds.Client.Query(ctx, queryInput)
+	output, err := ds.query(ctx, queryInput)
 	if err != nil {
-		log.Printf("❌ Failed to query GSI '%s': %v", indexName, err)
+		utils.Logf(ctx, "❌ Failed to query GSI '%s': %v", indexName, err)
 		return nil, fmt.Errorf("GSI query error: %w", err)
 	}
 
-	log.Printf("✅ Query successful. Retrieved %d items.", len(output.Items))
+	utils.Logf(ctx, "✅ Query successful. Retrieved %d items.", len(output.Items))
 	return output.Items, nil
 }
 
 // ✅ Get Item from DynamoDB
 func (ds *DynamoService) GetItem(ctx context.Context, tableName string, key map[string]types.AttributeValue) (map[string]types.AttributeValue, error) {
-	log.Printf("🔍 Fetching item from table '%s'", tableName)
+	utils.Logf(ctx, "🔍 Fetching item from table '%s'", tableName)
 
-	output, err := ds.Client.GetItem(ctx, &dynamodb.GetItemInput{
+	output, err := ds.getItem(ctx, &dynamodb.GetItemInput{
 		TableName: &tableName,
 		Key:       key,
 	})
 	if err != nil {
-		log.Printf("❌ Failed to get item: %v", err)
+		utils.Logf(ctx, "❌ Failed to get item: %v", err)
 		return nil, fmt.Errorf("get item error: %w", err)
 	}
 
 	if output.Item == nil {
-		log.Println("⚠️ Item not found")
-		return nil, errors.New("item not found")
+		utils.Logln(ctx, "⚠️ Item not found")
+		return nil, ErrNotFound
 	}
 
-	log.Println("✅ Item retrieved successfully")
+	utils.Logln(ctx, "✅ Item retrieved successfully")
+	return output.Item, nil
+}
+
+// ✅ Get only the listed attributes of an item (cheap existence/version checks)
+func (ds *DynamoService) GetItemAttributes(ctx context.Context, tableName string, key map[string]types.AttributeValue, attributes ...string) (map[string]types.AttributeValue, error) {
+	utils.Logf(ctx, "🔍 Fetching %v from table '%s'", attributes, tableName)
+
+	projection := make([]string, len(attributes))
+	names := make(map[string]string, len(attributes))
+	for i, attribute := range attributes {
+		placeholder := fmt.Sprintf("#a%d", i)
+		projection[i] = placeholder
+		names[placeholder] = attribute
+	}
+
+	output, err := ds.getItem(ctx, &dynamodb.GetItemInput{
+		TableName:                &tableName,
+		Key:                      key,
+		ProjectionExpression:     aws.String(strings.Join(projection, ", ")),
+		ExpressionAttributeNames: names,
+	})
+	if err != nil {
+		utils.Logf(ctx, "❌ Failed to get item attributes: %v", err)
+		return nil, fmt.Errorf("get item error: %w", err)
+	}
+
+	if output.Item == nil {
+		utils.Logln(ctx, "⚠️ Item not found")
+		return nil, ErrNotFound
+	}
 	return output.Item, nil
 }
 
 // ✅ Put Item into DynamoDB
 func (ds *DynamoService) PutItem(ctx context.Context, tableName string, item interface{}) error {
-	log.Printf("📝 Marshalling item for table '%s'...", tableName)
+	utils.Logf(ctx, "📝 Marshalling item for table '%s'...", tableName)
 
 	marshaledItem, err := attributevalue.MarshalMap(item)
 	if err != nil {
-		log.Printf("❌ Failed to marshal item: %v", err)
+		utils.Logf(ctx, "❌ Failed to marshal item: %v", err)
 		return fmt.Errorf("marshal error: %w", err)
 	}
 
-	log.Printf("🚀 Inserting item into table '%s'...", tableName)
-	_, err = ds.Client.PutItem(ctx, &dynamodb.PutItemInput{
+	utils.Logf(ctx, "🚀 Inserting item into table '%s'...", tableName)
+	_, err = ds.putItem(ctx, &dynamodb.PutItemInput{
 		TableName: &tableName,
 		Item:      marshaledItem,
 	})
 	if err != nil {
-		log.Printf("❌ Failed to insert item: %v", err)
-		return fmt.Errorf("put item error: %w", err)
+		utils.Logf(ctx, "❌ Failed to insert item: %v", err)
+		return fmt.Errorf("put item error: %w", classifyDynamoError(err))
 	}
 
-	log.Println("✅ Item successfully inserted.")
+	utils.Logln(ctx, "✅ Item successfully inserted.")
 	return nil
 }
 
@@ -318,7 +391,7 @@ func (ds *DynamoService) UpdateItem(
 	expressionAttributeValues map[string]types.AttributeValue,
 	expressionAttributeNames map[string]string,
 ) (map[string]types.AttributeValue, error) {
-	log.Printf("🔄 Updating item in table '%s'", tableName)
+	utils.Logf(ctx, "🔄 Updating item in table '%s'", tableName)
 
 	updateInput := &dynamodb.UpdateItemInput{
 		TableName:                 &tableName,
@@ -329,29 +402,258 @@ func (ds *DynamoService) UpdateItem(
 		ReturnValues:              types.ReturnValueAllNew,
 	}
 
-	output, err := ds.Client.UpdateItem(ctx, updateInput)
+	output, err := ds.updateItem(ctx, updateInput)
 	if err != nil {
-		log.Printf("❌ Update failed: %v", err)
-		return nil, fmt.Errorf("update error: %w", err)
+		utils.Logf(ctx, "❌ Update failed: %v", err)
+		return nil, fmt.Errorf("update error: %w", classifyDynamoError(err))
 	}
 
-	log.Println("✅ Item updated successfully")
+	utils.Logln(ctx, "✅ Item updated successfully")
 	return output.Attributes, nil
 }
 
 // ✅ Delete Item from DynamoDB
 func (ds *DynamoService) DeleteItem(ctx context.Context, tableName string, key map[string]types.AttributeValue) error {
-	log.Printf("🗑️ Deleting item from table '%s'", tableName)
+	utils.Logf(ctx, "🗑️ Deleting item from table '%s'", tableName)
 
 	_, err := ds.Client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
 		TableName: &tableName,
 		Key:       key,
 	})
 	if err != nil {
-		log.Printf("❌ Failed to delete item: %v", err)
-		return fmt.Errorf("delete item error: %w", err)
+		utils.Logf(ctx, "❌ Failed to delete item: %v", err)
+		return fmt.Errorf("delete item error: %w", classifyDynamoError(err))
 	}
 
-	log.Println("✅ Item deleted successfully")
+	utils.Logln(ctx, "✅ Item deleted successfully")
+	return nil
+}
+
+// ✅ ScanAll reads every item of a table, following LastEvaluatedKey until the scan is exhausted
+func (ds *DynamoService) ScanAll(ctx context.Context, tableName string) ([]map[string]types.AttributeValue, error) {
+	utils.Logf(ctx, "🔍 Scanning all items from table '%s'", tableName)
+
+	var items []map[string]types.AttributeValue
+	var startKey map[string]types.AttributeValue
+	for {
+		output, err := ds.Client.Scan(ctx, &dynamodb.ScanInput{
+			TableName:         &tableName,
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			utils.Logf(ctx, "❌ Failed to scan table '%s': %v", tableName, err)
+			return nil, fmt.Errorf("failed to scan table '%s': %w", tableName, err)
+		}
+
+		items = append(items, output.Items...)
+		if len(output.LastEvaluatedKey) == 0 {
+			break
+		}
+		startKey = output.LastEvaluatedKey
+	}
+
+	utils.Logf(ctx, "✅ Scanned %d items from table '%s'", len(items), tableName)
+	return items, nil
+}
+
+// ✅ ScanPage reads one page of a table starting after cursor ("" for the first) and returns the cursor
+// for the next page ("" when the scan is done), so long scans can be processed and resumed page by page
+func (ds *DynamoService) ScanPage(ctx context.Context, tableName string, cursor string) ([]map[string]types.AttributeValue, string, error) {
+	startKey, err := utils.DecodeCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	output, err := ds.scan(ctx, &dynamodb.ScanInput{
+		TableName:         &tableName,
+		ExclusiveStartKey: startKey,
+	})
+	if err != nil {
+		utils.Logf(ctx, "❌ Failed to scan table '%s': %v", tableName, err)
+		return nil, "", fmt.Errorf("failed to scan table '%s': %w", tableName, err)
+	}
+
+	nextCursor, err := utils.EncodeCursor(output.LastEvaluatedKey)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return output.Items, nextCursor, nil
+}
+
+// ✅ QueryAll runs a query and follows LastEvaluatedKey so the whole partition is returned
+func (ds *DynamoService) QueryAll(ctx context.Context, input *dynamodb.QueryInput) ([]map[string]types.AttributeValue, error) {
+	var items []map[string]types.AttributeValue
+	for {
+		output, err := ds.query(ctx, input)
+		if err != nil {
+			utils.Logf(ctx, "❌ Failed to query table '%s': %v", *input.TableName, err)
+			return nil, fmt.Errorf("query error: %w", err)
+		}
+
+		items = append(items, output.Items...)
+		if len(output.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+
+	utils.Logf(ctx, "✅ Retrieved %d items from table '%s'", len(items), *input.TableName)
+	return items, nil
+}
+
+// ✅ BatchGetItems fetches items by key in chunks of 100, retrying any UnprocessedKeys
+func (ds *DynamoService) BatchGetItems(
+	ctx context.Context,
+	tableName string,
+	keys []map[string]types.AttributeValue,
+) ([]map[string]types.AttributeValue, error) {
+	const maxBatchSize = 100
+	const maxUnprocessedRetries = 3
+
+	utils.Logf(ctx, "🔍 Batch fetching %d items from table '%s'", len(keys), tableName)
+
+	var items []map[string]types.AttributeValue
+	for i := 0; i < len(keys); i += maxBatchSize {
+		end := i + maxBatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		requestItems := map[string]types.KeysAndAttributes{
+			tableName: {Keys: keys[i:end]},
+		}
+
+		for attempt := 0; len(requestItems) > 0; attempt++ {
+			if attempt > maxUnprocessedRetries {
+				return nil, fmt.Errorf("failed to batch get items from table '%s': unprocessed keys remain after %d retries", tableName, maxUnprocessedRetries)
+			}
+
+			output, err := ds.Client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{RequestItems: requestItems})
+			if err != nil {
+				utils.Logf(ctx, "❌ Failed to batch get items: %v", err)
+				return nil, fmt.Errorf("failed to batch get items from table '%s': %w", tableName, err)
+			}
+
+			items = append(items, output.Responses[tableName]...)
+			requestItems = output.UnprocessedKeys
+		}
+	}
+
+	utils.Logf(ctx, "✅ Batch retrieved %d items from table '%s'", len(items), tableName)
+	return items, nil
+}
+
+// ✅ Query a GSI one page at a time, starting after startKey and returning LastEvaluatedKey for the next page
+func (ds *DynamoService) QueryItemsWithIndexPaginated(
+	ctx context.Context,
+	tableName string,
+	indexName string,
+	keyConditionExpression string,
+	expressionAttributeValues map[string]types.AttributeValue,
+	expressionAttributeNames map[string]string,
+	limit int32,
+	startKey map[string]types.AttributeValue,
+) ([]map[string]types.AttributeValue, map[string]types.AttributeValue, error) {
+	utils.Logf(ctx, "🔍 Querying GSI: %s in table: %s (paginated, limit %d)", indexName, tableName, limit)
+
+	queryInput := &dynamodb.QueryInput{
+		TableName:                 &tableName,
+		IndexName:                 &indexName,
+		KeyConditionExpression:    &keyConditionExpression,
+		ExpressionAttributeValues: expressionAttributeValues,
+		ExpressionAttributeNames:  expressionAttributeNames,
+		Limit:                     &limit,
+		ExclusiveStartKey:         startKey,
+	}
+
+	output, err := ds.query(ctx, queryInput)
+	if err != nil {
+		utils.Logf(ctx, "❌ Error querying GSI: %v", err)
+		return nil, nil, fmt.Errorf("failed to query GSI '%s': %w", indexName, err)
+	}
+
+	utils.Logf(ctx, "✅ Query successful. Retrieved %d items (more: %v).", len(output.Items), len(output.LastEvaluatedKey) > 0)
+	return output.Items, output.LastEvaluatedKey, nil
+}
+
+// ✅ QueryPage runs one page of a query starting after cursor (from a previous page, "" for the first)
+// and returns the cursor for the next page ("" when there are no more). Bad cursors return utils.ErrInvalidCursor.
+func (ds *DynamoService) QueryPage(ctx context.Context, input *dynamodb.QueryInput, limit int32, cursor string) ([]map[string]types.AttributeValue, string, error) {
+	startKey, err := utils.DecodeCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	input.ExclusiveStartKey = startKey
+	if limit > 0 {
+		input.Limit = &limit
+	}
+
+	output, err := ds.query(ctx, input)
+	if err != nil {
+		utils.Logf(ctx, "❌ Failed to query table '%s': %v", *input.TableName, err)
+		return nil, "", fmt.Errorf("query error: %w", err)
+	}
+
+	nextCursor, err := utils.EncodeCursor(output.LastEvaluatedKey)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	utils.Logf(ctx, "✅ Retrieved %d items from table '%s' (more: %v)", len(output.Items), *input.TableName, nextCursor != "")
+	return output.Items, nextCursor, nil
+}
+
+// maxFillReads bounds how many query pages fillPage reads for one response
+const maxFillReads = 5
+
+// fillPage builds one page of a filtered listing from fetch, which reads a query page of at most
+// limit items starting after cursor and returns what survives its filters. Query pages are read
+// until limit results are collected or the query is exhausted; limiting each read to what is still
+// missing means no result is ever dropped past the returned cursor. After maxFillReads reads it
+// returns what it has (possibly nothing) with the cursor to continue from, so clients keep paging
+// until the cursor is "", not until a page comes back short or empty.
+func fillPage[T any](limit int32, cursor string, fetch func(limit int32, cursor string) ([]T, string, error)) ([]T, string, error) {
+	results := []T{}
+	for reads := 0; reads < maxFillReads; reads++ {
+		page, nextCursor, err := fetch(limit-int32(len(results)), cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		results = append(results, page...)
+		cursor = nextCursor
+		if cursor == "" || int32(len(results)) >= limit {
+			break
+		}
+	}
+	return results, cursor, nil
+}
+
+// ✅ CountItems runs a query with Select=COUNT across all pages and returns the matching item count
+func (ds *DynamoService) CountItems(ctx context.Context, input *dynamodb.QueryInput) (int, error) {
+	input.Select = types.SelectCount
+
+	total := 0
+	for {
+		output, err := ds.query(ctx, input)
+		if err != nil {
+			utils.Logf(ctx, "❌ Failed to count items in table '%s': %v", *input.TableName, err)
+			return 0, fmt.Errorf("count query error: %w", err)
+		}
+
+		total += int(output.Count)
+		if len(output.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+	return total, nil
+}
+
+// ✅ TransactWriteItems applies all writes atomically; a failed condition cancels the whole transaction
+func (ds *DynamoService) TransactWriteItems(ctx context.Context, items []types.TransactWriteItem) error {
+	_, err := ds.Client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: items})
+	if err != nil {
+		utils.Logf(ctx, "❌ Transaction failed: %v", err)
+		return fmt.Errorf("transaction error: %w", classifyDynamoError(err))
+	}
 	return nil
 }
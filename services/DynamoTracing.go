@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go/middleware"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// dynamoTracer is resolved per call so it picks up the provider installed at startup
+func dynamoTracer() trace.Tracer {
+	return otel.Tracer("vibin_server/dynamodb")
+}
+
+// addDynamoTracing wraps every DynamoDB operation (including direct Client calls) in a client span
+// carrying the table, index and item counts
+func addDynamoTracing(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("VibinDynamoTracing",
+		func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			operation := awsmiddleware.GetOperationName(ctx)
+			ctx, span := dynamoTracer().Start(ctx, "DynamoDB."+operation, trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					attribute.String("db.system", "dynamodb"),
+					attribute.String("db.operation", operation),
+				))
+			defer span.End()
+			span.SetAttributes(dynamoInputAttributes(in.Parameters)...)
+
+			out, metadata, err := next.HandleInitialize(ctx, in)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return out, metadata, err
+			}
+			span.SetAttributes(dynamoOutputAttributes(out.Result)...)
+			return out, metadata, err
+		}), middleware.After)
+}
+
+// dynamoInputAttributes describes what the operation targets
+func dynamoInputAttributes(input interface{}) []attribute.KeyValue {
+	var tables []string
+	var attrs []attribute.KeyValue
+	switch in := input.(type) {
+	case *dynamodb.GetItemInput:
+		tables = append(tables, derefString(in.TableName))
+	case *dynamodb.PutItemInput:
+		tables = append(tables, derefString(in.TableName))
+	case *dynamodb.UpdateItemInput:
+		tables = append(tables, derefString(in.TableName))
+	case *dynamodb.DeleteItemInput:
+		tables = append(tables, derefString(in.TableName))
+	case *dynamodb.DescribeTableInput:
+		tables = append(tables, derefString(in.TableName))
+	case *dynamodb.QueryInput:
+		tables = append(tables, derefString(in.TableName))
+		if in.IndexName != nil {
+			attrs = append(attrs, attribute.String("aws.dynamodb.index_name", *in.IndexName))
+		}
+		if in.Limit != nil {
+			attrs = append(attrs, attribute.Int("aws.dynamodb.limit", int(*in.Limit)))
+		}
+	case *dynamodb.ScanInput:
+		tables = append(tables, derefString(in.TableName))
+		if in.IndexName != nil {
+			attrs = append(attrs, attribute.String("aws.dynamodb.index_name", *in.IndexName))
+		}
+	case *dynamodb.BatchGetItemInput:
+		keys := 0
+		for table, request := range in.RequestItems {
+			tables = append(tables, table)
+			keys += len(request.Keys)
+		}
+		attrs = append(attrs, attribute.Int("aws.dynamodb.request_items", keys))
+	case *dynamodb.BatchWriteItemInput:
+		writes := 0
+		for table, requests := range in.RequestItems {
+			tables = append(tables, table)
+			writes += len(requests)
+		}
+		attrs = append(attrs, attribute.Int("aws.dynamodb.request_items", writes))
+	case *dynamodb.TransactWriteItemsInput:
+		seen := map[string]bool{}
+		for _, item := range in.TransactItems {
+			if name := derefString(transactTable(item)); name != "" && !seen[name] {
+				seen[name] = true
+				tables = append(tables, name)
+			}
+		}
+		attrs = append(attrs, attribute.Int("aws.dynamodb.request_items", len(in.TransactItems)))
+	}
+	if len(tables) > 0 {
+		attrs = append(attrs, attribute.StringSlice("aws.dynamodb.table_names", tables))
+	}
+	return attrs
+}
+
+// dynamoOutputAttributes records how much the operation returned
+func dynamoOutputAttributes(output interface{}) []attribute.KeyValue {
+	switch out := output.(type) {
+	case *dynamodb.GetItemOutput:
+		return []attribute.KeyValue{attribute.Bool("aws.dynamodb.item_found", out.Item != nil)}
+	case *dynamodb.QueryOutput:
+		return []attribute.KeyValue{
+			attribute.Int("aws.dynamodb.count", int(out.Count)),
+			attribute.Int("aws.dynamodb.scanned_count", int(out.ScannedCount)),
+			attribute.Bool("aws.dynamodb.has_more", out.LastEvaluatedKey != nil),
+		}
+	case *dynamodb.ScanOutput:
+		return []attribute.KeyValue{
+			attribute.Int("aws.dynamodb.count", int(out.Count)),
+			attribute.Int("aws.dynamodb.scanned_count", int(out.ScannedCount)),
+			attribute.Bool("aws.dynamodb.has_more", out.LastEvaluatedKey != nil),
+		}
+	case *dynamodb.BatchGetItemOutput:
+		count, unprocessed := 0, 0
+		for _, items := range out.Responses {
+			count += len(items)
+		}
+		for _, request := range out.UnprocessedKeys {
+			unprocessed += len(request.Keys)
+		}
+		return []attribute.KeyValue{attribute.Int("aws.dynamodb.count", count), attribute.Int("aws.dynamodb.unprocessed", unprocessed)}
+	case *dynamodb.BatchWriteItemOutput:
+		unprocessed := 0
+		for _, requests := range out.UnprocessedItems {
+			unprocessed += len(requests)
+		}
+		return []attribute.KeyValue{attribute.Int("aws.dynamodb.unprocessed", unprocessed)}
+	}
+	return nil
+}
+
+// transactTable returns the table a transaction item writes to
+func transactTable(item types.TransactWriteItem) *string {
+	switch {
+	case item.Put != nil:
+		return item.Put.TableName
+	case item.Update != nil:
+		return item.Update.TableName
+	case item.Delete != nil:
+		return item.Delete.TableName
+	case item.ConditionCheck != nil:
+		return item.ConditionCheck.TableName
+	}
+	return nil
+}
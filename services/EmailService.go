@@ -0,0 +1,92 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// EmailSender delivers plain-text emails
+type EmailSender interface {
+	SendEmail(ctx context.Context, to, subject, body string) error
+}
+
+// SESEmailSender sends email through the Amazon SES v2 API, signed with the default AWS credentials
+type SESEmailSender struct {
+	Region      string
+	From        string // A verified SES identity
+	Credentials aws.CredentialsProvider
+	HTTPClient  *http.Client
+}
+
+// InitializeSESEmailSender loads the default AWS credentials for sending from an SES identity
+func InitializeSESEmailSender(region, from string) *SESEmailSender {
+	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion(region))
+	if err != nil {
+		log.Fatalf("Failed to load AWS config: %v", err)
+	}
+	return &SESEmailSender{Region: region, From: from, Credentials: cfg.Credentials}
+}
+
+// SendEmail sends one message and returns once SES accepted it
+func (s *SESEmailSender) SendEmail(ctx context.Context, to, subject, body string) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"FromEmailAddress": s.From,
+		"Destination":      map[string][]string{"ToAddresses": {to}},
+		"Content": map[string]interface{}{
+			"Simple": map[string]interface{}{
+				"Subject": map[string]string{"Data": subject, "Charset": "UTF-8"},
+				"Body":    map[string]interface{}{"Text": map[string]string{"Data": body, "Charset": "UTF-8"}},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("https://email.%s.amazonaws.com/v2/email/outbound-emails", s.Region)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	credentials, err := s.Credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load AWS credentials: %w", err)
+	}
+	payloadHash := sha256.Sum256(payload)
+	if err := v4.NewSigner().SignHTTP(ctx, credentials, req, hex.EncodeToString(payloadHash[:]), "ses", s.Region, time.Now()); err != nil {
+		return fmt.Errorf("failed to sign SES request: %w", err)
+	}
+
+	client := s.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ses request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		raw, _ := io.ReadAll(resp.Body)
+		var sesErr struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(raw, &sesErr)
+		return fmt.Errorf("ses returned %d: %s", resp.StatusCode, sesErr.Message)
+	}
+	return nil
+}
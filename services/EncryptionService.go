@@ -0,0 +1,312 @@
+package services
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	kmstypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// EncryptionService encrypts message content with per-conversation data keys wrapped by KMS
+// (envelope encryption). Plaintext data keys live only in memory.
+type EncryptionService struct {
+	Dynamo   *DynamoService
+	KMS      *kms.Client
+	KMSKeyID string
+
+	mu       sync.RWMutex
+	dataKeys map[string][]byte       // "<conversationId>#<version>" → plaintext data key
+	latest   map[string]latestKeyRef // conversationId → latest key version
+}
+
+// latestKeyRef caches which key version is current; it is re-checked after latestKeyTTL
+// so rotations made by other instances are picked up
+type latestKeyRef struct {
+	version   int
+	checkedAt time.Time
+}
+
+const latestKeyTTL = 5 * time.Minute
+
+// InitializeKMSClient initializes the KMS client
+func InitializeKMSClient(region string) *kms.Client {
+	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion(region))
+	if err != nil {
+		log.Fatalf("Failed to load AWS config: %v", err)
+	}
+	return kms.NewFromConfig(cfg)
+}
+
+// Enabled reports whether message encryption is configured
+func (s *EncryptionService) Enabled() bool {
+	return s != nil && s.KMS != nil && s.KMSKeyID != ""
+}
+
+// Encrypt seals plaintext with the conversation's latest data key, creating the first key if needed
+func (s *EncryptionService) Encrypt(ctx context.Context, conversationID, plaintext string) (string, int, error) {
+	version, err := s.EncryptFields(ctx, conversationID, &plaintext)
+	if err != nil {
+		return "", 0, err
+	}
+	return plaintext, version, nil
+}
+
+// EncryptFields seals every value in place under one data key version, so values stored together
+// share the single version recorded next to them
+func (s *EncryptionService) EncryptFields(ctx context.Context, conversationID string, values ...*string) (int, error) {
+	version, dataKey, err := s.latestDataKey(ctx, conversationID)
+	if err != nil {
+		return 0, err
+	}
+
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return 0, err
+	}
+
+	sealed := make([]string, len(values))
+	for i, value := range values {
+		nonce := make([]byte, gcm.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return 0, fmt.Errorf("failed to generate nonce: %w", err)
+		}
+		sealed[i] = base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(*value), []byte(conversationID)))
+	}
+	for i, value := range values {
+		*value = sealed[i]
+	}
+	return version, nil
+}
+
+// Decrypt opens ciphertext produced by Encrypt using the recorded key version
+func (s *EncryptionService) Decrypt(ctx context.Context, conversationID, ciphertext string, version int) (string, error) {
+	dataKey, err := s.dataKey(ctx, conversationID, version)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil || len(sealed) < gcm.NonceSize() {
+		return "", errors.New("malformed ciphertext")
+	}
+
+	nonce, body := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, body, []byte(conversationID))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt content: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// RotateKey creates a new data key version for a conversation. New messages use it;
+// older messages keep decrypting with the version recorded on them. Concurrent rotations settle
+// on the one version that was stored first.
+func (s *EncryptionService) RotateKey(ctx context.Context, conversationID string) (*models.ConversationKey, error) {
+	current, err := s.getLatestKeyRecord(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := 0
+	if current != nil {
+		previous = current.KeyVersion
+	}
+
+	utils.Logf(ctx, "🔑 Rotating data key for conversation %s (v%d → v%d)", conversationID, previous, previous+1)
+	return s.createDataKey(ctx, conversationID, previous+1, previous)
+}
+
+///// 🔹🔹🔹 Key management helpers 🔹🔹🔹 /////
+
+func (s *EncryptionService) latestDataKey(ctx context.Context, conversationID string) (int, []byte, error) {
+	s.mu.RLock()
+	ref, ok := s.latest[conversationID]
+	s.mu.RUnlock()
+
+	version := ref.version
+	if !ok || time.Since(ref.checkedAt) > latestKeyTTL {
+		record, err := s.getLatestKeyRecord(ctx, conversationID)
+		if err != nil {
+			return 0, nil, err
+		}
+		if record == nil {
+			record, err = s.createDataKey(ctx, conversationID, 1, 0)
+			if err != nil {
+				return 0, nil, err
+			}
+		}
+		version = record.KeyVersion
+		s.setLatest(conversationID, version)
+	}
+
+	dataKey, err := s.dataKey(ctx, conversationID, version)
+	return version, dataKey, err
+}
+
+func (s *EncryptionService) dataKey(ctx context.Context, conversationID string, version int) ([]byte, error) {
+	cacheKey := conversationID + "#" + strconv.Itoa(version)
+
+	s.mu.RLock()
+	dataKey, ok := s.dataKeys[cacheKey]
+	s.mu.RUnlock()
+	if ok {
+		return dataKey, nil
+	}
+
+	item, err := s.Dynamo.GetItem(ctx, models.ConversationKeysTable, conversationKeyKey(conversationID, version))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load data key v%d for %s: %w", version, conversationID, err)
+	}
+
+	var record models.ConversationKey
+	if err := attributevalue.UnmarshalMap(item, &record); err != nil {
+		return nil, fmt.Errorf("failed to parse data key: %w", err)
+	}
+
+	output, err := s.KMS.Decrypt(ctx, &kms.DecryptInput{
+		CiphertextBlob:    record.EncryptedDataKey,
+		KeyId:             aws.String(record.KMSKeyID),
+		EncryptionContext: map[string]string{"conversationId": conversationID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+
+	s.cacheDataKey(cacheKey, output.Plaintext)
+	return output.Plaintext, nil
+}
+
+func (s *EncryptionService) createDataKey(ctx context.Context, conversationID string, version, rotatedFrom int) (*models.ConversationKey, error) {
+	output, err := s.KMS.GenerateDataKey(ctx, &kms.GenerateDataKeyInput{
+		KeyId:             aws.String(s.KMSKeyID),
+		KeySpec:           kmstypes.DataKeySpecAes256,
+		EncryptionContext: map[string]string{"conversationId": conversationID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	record := models.ConversationKey{
+		ConversationID:   conversationID,
+		KeyVersion:       version,
+		EncryptedDataKey: output.CiphertextBlob,
+		KMSKeyID:         s.KMSKeyID,
+		CreatedAt:        time.Now().Format(time.RFC3339),
+		RotatedFrom:      rotatedFrom,
+	}
+	item, err := attributevalue.MarshalMap(record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal data key: %w", err)
+	}
+	// ✅ Never overwrite a stored version: messages may already be encrypted under it
+	_, err = s.Dynamo.Client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(models.ConversationKeysTable),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(keyVersion)"),
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		utils.Logf(ctx, "ℹ️ Data key v%d for conversation %s was created concurrently; using the stored one", version, conversationID)
+		return s.storedKeyRecord(ctx, conversationID, version)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to store data key: %w", err)
+	}
+
+	s.cacheDataKey(conversationID+"#"+strconv.Itoa(version), output.Plaintext)
+	s.setLatest(conversationID, version)
+
+	utils.Logf(ctx, "✅ Created data key v%d for conversation %s", version, conversationID)
+	return &record, nil
+}
+
+// storedKeyRecord reads one version of a conversation's key record, marking it the latest
+func (s *EncryptionService) storedKeyRecord(ctx context.Context, conversationID string, version int) (*models.ConversationKey, error) {
+	item, err := s.Dynamo.GetItem(ctx, models.ConversationKeysTable, conversationKeyKey(conversationID, version))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load data key v%d for %s: %w", version, conversationID, err)
+	}
+	var record models.ConversationKey
+	if err := attributevalue.UnmarshalMap(item, &record); err != nil {
+		return nil, fmt.Errorf("failed to parse conversation key: %w", err)
+	}
+	s.setLatest(conversationID, version)
+	return &record, nil
+}
+
+func (s *EncryptionService) getLatestKeyRecord(ctx context.Context, conversationID string) (*models.ConversationKey, error) {
+	keyCondition := "conversationId = :conversationId"
+	expressionValues := map[string]types.AttributeValue{
+		":conversationId": &types.AttributeValueMemberS{Value: conversationID},
+	}
+
+	items, err := s.Dynamo.QueryItemsWithOptions(ctx, models.ConversationKeysTable, keyCondition, expressionValues, nil, 1, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch conversation key: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	var record models.ConversationKey
+	if err := attributevalue.UnmarshalMap(items[0], &record); err != nil {
+		return nil, fmt.Errorf("failed to parse conversation key: %w", err)
+	}
+	return &record, nil
+}
+
+func (s *EncryptionService) cacheDataKey(cacheKey string, dataKey []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dataKeys == nil {
+		s.dataKeys = map[string][]byte{}
+	}
+	s.dataKeys[cacheKey] = dataKey
+}
+
+func (s *EncryptionService) setLatest(conversationID string, version int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.latest == nil {
+		s.latest = map[string]latestKeyRef{}
+	}
+	s.latest[conversationID] = latestKeyRef{version: version, checkedAt: time.Now()}
+}
+
+// conversationKeyKey builds the ConversationKeys primary key of one key version
+func conversationKeyKey(conversationID string, version int) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"conversationId": &types.AttributeValueMemberS{Value: conversationID},
+		"keyVersion":     &types.AttributeValueMemberN{Value: strconv.Itoa(version)},
+	}
+}
+
+func newGCM(dataKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
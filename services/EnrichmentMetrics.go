@@ -0,0 +1,33 @@
+package services
+
+import (
+	"expvar"
+	"log"
+	"vibin_server/models"
+)
+
+// enrichmentMetrics exposes per-feed enrichment counters on /admin/debug/vars:
+// "<feed>.entries" counts entries returned and "<feed>.failed" counts placeholders.
+var enrichmentMetrics = expvar.NewMap("enrichment")
+
+// profileFetchRetryAfterSeconds is the retry hint sent when the profile lookup itself failed
+const profileFetchRetryAfterSeconds = 5
+
+// recordEnrichment updates the counters for one list response and logs the drop rate
+func recordEnrichment(feed string, entries, failed int) {
+	enrichmentMetrics.Add(feed+".entries", int64(entries))
+	enrichmentMetrics.Add(feed+".failed", int64(failed))
+	if failed > 0 {
+		log.Printf("⚠️ Enrichment for %s: %d of %d entries returned as placeholders", feed, failed, entries)
+	}
+}
+
+// enrichmentRetryHint builds the retry hint for a given failure reason
+func enrichmentRetryHint(reason string) *models.RetryHint {
+	switch reason {
+	case models.EnrichmentReasonProfileNotFound:
+		return &models.RetryHint{Reason: reason, Retryable: false}
+	default:
+		return &models.RetryHint{Reason: reason, Retryable: true, RetryAfterSeconds: profileFetchRetryAfterSeconds}
+	}
+}
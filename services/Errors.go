@@ -0,0 +1,55 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Error categories shared by every service; helpers.WriteError maps them to HTTP status codes
+var (
+	ErrNotFound     = errors.New("not_found")         // 404: the item does not exist
+	ErrConflict     = errors.New("conflict")          // 409: a condition or uniqueness check failed
+	ErrValidation   = errors.New("validation_failed") // 400: the caller sent something invalid
+	ErrUnauthorized = errors.New("unauthorized")      // 401: no valid session
+	ErrForbidden    = errors.New("forbidden")         // 403: the caller may not do this right now
+)
+
+// ServiceError is a specific error that also matches one of the categories via errors.Is.
+// Its message is safe to show to clients; wrapped DynamoDB errors are not.
+type ServiceError struct {
+	Kind    error
+	Message string
+}
+
+func (e *ServiceError) Error() string { return e.Message }
+func (e *ServiceError) Unwrap() error { return e.Kind }
+
+func notFoundError(message string) error { return &ServiceError{Kind: ErrNotFound, Message: message} }
+func conflictError(message string) error { return &ServiceError{Kind: ErrConflict, Message: message} }
+func unauthorizedError(message string) error {
+	return &ServiceError{Kind: ErrUnauthorized, Message: message}
+}
+func forbiddenError(message string) error { return &ServiceError{Kind: ErrForbidden, Message: message} }
+func validationError(message string) error {
+	return &ServiceError{Kind: ErrValidation, Message: message}
+}
+
+// classifyDynamoError tags failed condition checks as ErrConflict, keeping the SDK error in the chain
+func classifyDynamoError(err error) error {
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return fmt.Errorf("%w: %w", ErrConflict, err)
+	}
+	var cancelled *types.TransactionCanceledException
+	if errors.As(err, &cancelled) {
+		for _, reason := range cancelled.CancellationReasons {
+			if aws.ToString(reason.Code) == "ConditionalCheckFailed" {
+				return fmt.Errorf("%w: %w", ErrConflict, err)
+			}
+		}
+	}
+	return err
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"sync"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+)
+
+// flagKeyPattern is what admins may use as a flag key
+var flagKeyPattern = regexp.MustCompile(`^[a-z0-9_]{2,64}$`)
+
+// FeatureFlagService serves DynamoDB-backed flags from an in-memory cache that is refreshed periodically
+type FeatureFlagService struct {
+	Dynamo *DynamoService
+
+	mu    sync.RWMutex
+	flags map[string]models.FeatureFlag
+}
+
+// StartFlagReloader loads the flags now and then every interval until ctx is cancelled
+func (s *FeatureFlagService) StartFlagReloader(ctx context.Context, interval time.Duration) {
+	if err := s.ReloadFlags(ctx); err != nil {
+		utils.Logf(ctx, "⚠️ Initial feature flag load failed: %v", err)
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.ReloadFlags(ctx); err != nil {
+					utils.Logf(ctx, "⚠️ Feature flag reload failed, keeping cached flags: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// ReloadFlags replaces the cache with the flags currently stored
+func (s *FeatureFlagService) ReloadFlags(ctx context.Context) error {
+	flags, err := s.ListFlags(ctx)
+	if err != nil {
+		return err
+	}
+
+	cache := make(map[string]models.FeatureFlag, len(flags))
+	for _, flag := range flags {
+		cache[flag.Key] = flag
+	}
+	s.mu.Lock()
+	s.flags = cache
+	s.mu.Unlock()
+	return nil
+}
+
+// ListFlags reads every flag from DynamoDB (the table is small and admin-managed)
+func (s *FeatureFlagService) ListFlags(ctx context.Context) ([]models.FeatureFlag, error) {
+	items, err := s.Dynamo.ScanAll(ctx, models.FeatureFlagsTable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feature flags: %w", err)
+	}
+	flags := []models.FeatureFlag{}
+	if err := attributevalue.UnmarshalListOfMaps(items, &flags); err != nil {
+		return nil, fmt.Errorf("failed to parse feature flags: %w", err)
+	}
+	return flags, nil
+}
+
+// SaveFlag validates and stores a flag, then refreshes this instance's cache
+// (other instances pick the change up on their next reload)
+func (s *FeatureFlagService) SaveFlag(ctx context.Context, flag models.FeatureFlag) (*models.FeatureFlag, error) {
+	if !flagKeyPattern.MatchString(flag.Key) {
+		return nil, validationError("key must be 2-64 lowercase letters, digits or '_'")
+	}
+	if flag.RolloutPercent < 0 || flag.RolloutPercent > 100 {
+		return nil, validationError("rolloutPercent must be between 0 and 100")
+	}
+	flag.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	utils.Logf(ctx, "📝 Saving feature flag %s (enabled=%t, rollout=%d%%) by %s", flag.Key, flag.Enabled, flag.RolloutPercent, flag.UpdatedBy)
+	if err := s.Dynamo.PutItem(ctx, models.FeatureFlagsTable, flag); err != nil {
+		utils.Logf(ctx, "❌ Failed to save feature flag %s: %v", flag.Key, err)
+		return nil, fmt.Errorf("failed to save feature flag: %w", err)
+	}
+
+	if err := s.ReloadFlags(ctx); err != nil {
+		utils.Logf(ctx, "⚠️ Feature flag %s saved but cache reload failed: %v", flag.Key, err)
+	}
+	return &flag, nil
+}
+
+// IsEnabled reports whether a flag is on for a user; unknown flags are off
+func (s *FeatureFlagService) IsEnabled(flagKey, userHandle string) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.RLock()
+	flag, ok := s.flags[flagKey]
+	s.mu.RUnlock()
+	return ok && flagOn(flag, userHandle)
+}
+
+// ClientConfig returns every flag evaluated for a user, plus the remote config of the flags that are on
+func (s *FeatureFlagService) ClientConfig(userHandle string) models.ClientConfig {
+	config := models.ClientConfig{Flags: map[string]bool{}, Config: map[string]map[string]string{}}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for key, flag := range s.flags {
+		on := flagOn(flag, userHandle)
+		config.Flags[key] = on
+		if on && len(flag.Config) > 0 {
+			config.Config[key] = flag.Config
+		}
+	}
+	return config
+}
+
+// flagOn applies the kill switch, the allow-list and then the percentage rollout
+func flagOn(flag models.FeatureFlag, userHandle string) bool {
+	if !flag.Enabled {
+		return false
+	}
+	for _, handle := range flag.AllowHandles {
+		if handle == userHandle {
+			return true
+		}
+	}
+	if flag.RolloutPercent >= 100 {
+		return true
+	}
+	if userHandle == "" || flag.RolloutPercent <= 0 {
+		return false
+	}
+	return rolloutBucket(flag.Key, userHandle) < flag.RolloutPercent
+}
+
+// rolloutBucket maps a user to 0-99, stable per flag so raising the percentage only adds users
+// and different flags don't always pick the same users
+func rolloutBucket(flagKey, userHandle string) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(flagKey + ":" + userHandle))
+	return int(hash.Sum32() % 100)
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"vibin_server/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/location"
+)
+
+// GeocodingService names the city and region of a location with an Amazon Location Service place
+// index. It is disabled (ReverseGeocode returns nil) when no place index is configured.
+type GeocodingService struct {
+	Client     *location.Client
+	PlaceIndex string
+}
+
+// InitializeLocationClient initializes the Amazon Location Service client
+func InitializeLocationClient(region string) *location.Client {
+	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion(region))
+	if err != nil {
+		log.Fatalf("Failed to load AWS config: %v", err)
+	}
+	return location.NewFromConfig(cfg)
+}
+
+// Enabled reports whether reverse geocoding is configured
+func (s *GeocodingService) Enabled() bool {
+	return s != nil && s.Client != nil && s.PlaceIndex != ""
+}
+
+// ReverseGeocode returns the place containing the coordinates, or nil when geocoding is disabled or
+// the position matches no place
+func (s *GeocodingService) ReverseGeocode(ctx context.Context, latitude, longitude float64) (*models.Place, error) {
+	if !s.Enabled() {
+		return nil, nil
+	}
+	output, err := s.Client.SearchPlaceIndexForPosition(ctx, &location.SearchPlaceIndexForPositionInput{
+		IndexName:  aws.String(s.PlaceIndex),
+		Position:   []float64{longitude, latitude},
+		MaxResults: aws.Int32(1),
+		Language:   aws.String("en"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to reverse geocode: %w", err)
+	}
+	if len(output.Results) == 0 || output.Results[0].Place == nil {
+		return nil, nil
+	}
+	place := output.Results[0].Place
+	if aws.ToString(place.Country) == "" {
+		return nil, nil
+	}
+	return &models.Place{
+		City:    aws.ToString(place.Municipality),
+		Region:  aws.ToString(place.Region),
+		Country: aws.ToString(place.Country),
+	}, nil
+}
@@ -2,72 +2,275 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 	"vibin_server/models"
+	"vibin_server/utils"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
 )
 
 // GroupChatService struct
 type GroupChatService struct {
-	Dynamo *DynamoService
+	Dynamo     *DynamoService
+	Moderation *ModerationService
+	Encryption *EncryptionService
+	Webhooks   *WebhookService
+	Safety     *MessageSafetyService // ✅ Classifies new messages after they are stored
 }
 
 // CreateGroupMessage stores a new group message in the GroupMessages table
 func (s *GroupChatService) CreateGroupMessage(ctx context.Context, message models.GroupMessage) error {
-	log.Printf("📩 Storing group message: %+v", message)
+	message.SafetyFlag, message.Hidden = "", false // ✅ Safety flags only come from the classifier
+
+	// ✅ Screen content against the live moderation rules
+	if err := s.Moderation.CheckContent(message.Content); err != nil {
+		utils.Logf(ctx, "🚫 Group message from %s rejected by moderation rules", message.SenderID)
+		s.Webhooks.Publish(ctx, models.WebhookMessageFlagged, map[string]interface{}{
+			"senderHandle": message.SenderID,
+			"groupId":      message.GroupID,
+			"reason":       "moderation_rules",
+			"rulesVersion": s.Moderation.CurrentVersion(),
+		})
+		return err
+	}
+
+	// ✅ Replies must quote an earlier message of the same group
+	if err := s.resolveReply(ctx, &message); err != nil {
+		return err
+	}
+
+	// ✅ Encrypt content (and any quoted snippet) at rest with the group's data key
+	if s.Encryption.Enabled() {
+		ciphertext, version, err := s.Encryption.Encrypt(ctx, message.GroupID, message.Content)
+		if err != nil {
+			utils.Logf(ctx, "❌ Failed to encrypt group message: %v", err)
+			return fmt.Errorf("failed to encrypt group message: %w", err)
+		}
+		message.Content, message.Encrypted, message.KeyVersion = ciphertext, true, version
+
+		if message.ReplySnippet != "" {
+			snippet, version, err := s.Encryption.Encrypt(ctx, message.GroupID, message.ReplySnippet)
+			if err != nil {
+				utils.Logf(ctx, "❌ Failed to encrypt reply snippet: %v", err)
+				return fmt.Errorf("failed to encrypt group message: %w", err)
+			}
+			message.ReplySnippet, message.ReplyKeyVersion = snippet, version
+		}
+	}
+
+	utils.Logf(ctx, "📩 Storing group message: %+v", message)
 
 	// ✅ Save message to DynamoDB
 	err := s.Dynamo.PutItem(ctx, models.GroupMessageTable, message)
 	if err != nil {
-		log.Printf("❌ Failed to store group message: %v", err)
+		utils.Logf(ctx, "❌ Failed to store group message: %v", err)
 		return fmt.Errorf("failed to store group message: %w", err)
 	}
 
-	log.Printf("✅ Group message stored successfully")
+	s.touchGroupActivity(ctx, message)
+	s.Safety.ScreenGroupMessage(ctx, message)
+	utils.Logf(ctx, "✅ Group message stored successfully")
 	return nil
 }
 
-// GetMessagesByGroupID fetches the latest messages for a given groupId sorted by createdAt (latest first),
-// then reverses the order before returning, so the latest message appears at the bottom in UI.
-func (s *GroupChatService) GetMessagesByGroupID(ctx context.Context, groupID string, limit int) ([]models.GroupMessage, error) {
-	log.Printf("🔍 Fetching latest %d messages for groupId: %s", limit, groupID)
+// touchGroupActivity moves lastActivityAt on every active member's record up to the message's time,
+// so the active groups list can page ActiveGroupsIndex. The message is already stored, so failures
+// are only logged.
+func (s *GroupChatService) touchGroupActivity(ctx context.Context, message models.GroupMessage) {
+	at := activityTime(message.CreatedAt)
+	if at == "" {
+		return
+	}
+
+	item, err := s.Dynamo.GetItemAttributes(ctx, models.GroupInteractionsTable, map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: "USER#" + message.SenderID},
+		"SK": &types.AttributeValueMemberS{Value: "GROUP#" + message.GroupID},
+	}, "members")
+	if err != nil {
+		utils.Logf(ctx, "⚠️ Could not find the members of groupId %s to update activity: %v", message.GroupID, err)
+		return
+	}
+	var members []string
+	if err := attributevalue.Unmarshal(item["members"], &members); err != nil {
+		utils.Logf(ctx, "⚠️ Could not read the members of groupId %s: %v", message.GroupID, err)
+		return
+	}
+
+	for _, member := range members {
+		_, err := s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+			TableName: aws.String(models.GroupInteractionsTable),
+			Key: map[string]types.AttributeValue{
+				"PK": &types.AttributeValueMemberS{Value: "USER#" + member},
+				"SK": &types.AttributeValueMemberS{Value: "GROUP#" + message.GroupID},
+			},
+			UpdateExpression:    aws.String("SET lastActivityAt = :at"),
+			ConditionExpression: aws.String("attribute_exists(activeMember) AND (attribute_not_exists(lastActivityAt) OR lastActivityAt < :at)"), // ✅ Active members only; never move backwards
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":at": &types.AttributeValueMemberS{Value: at},
+			},
+		})
+		var conditionFailed *types.ConditionalCheckFailedException
+		if err != nil && !errors.As(err, &conditionFailed) {
+			utils.Logf(ctx, "⚠️ Failed to update activity of groupId %s for %s: %v", message.GroupID, member, err)
+		}
+	}
+}
+
+// RecordSystemEvent writes an activity log entry into the group timeline. System entries skip
+// moderation and encryption, and have no read state so they never count as unread.
+func (s *GroupChatService) RecordSystemEvent(ctx context.Context, groupID string, event models.GroupSystemEvent) error {
+	message := models.GroupMessage{
+		GroupID:     groupID,
+		CreatedAt:   time.Now().UTC().Format(time.RFC3339Nano), // ✅ Sub-second precision so back-to-back events don't collide
+		MessageID:   uuid.New().String(),
+		SenderID:    models.GroupSystemSenderID,
+		Content:     event.Describe(),
+		IsRead:      map[string]bool{}, // ✅ Present but empty: marking it read is harmless, counting skips it
+		Likes:       map[string]bool{},
+		MessageType: models.GroupMessageTypeSystem,
+		Event:       &event,
+	}
+
+	utils.Logf(ctx, "📩 Recording %s event in groupId %s", event.Type, groupID)
+	if err := s.Dynamo.PutItem(ctx, models.GroupMessageTable, message); err != nil {
+		utils.Logf(ctx, "❌ Failed to record group event: %v", err)
+		return fmt.Errorf("failed to record group event: %w", err)
+	}
+	return nil
+}
 
-	// ✅ Define key condition expression for filtering by groupId
+// GetMessagesByGroupID fetches a window of a group's messages, returned oldest first so the latest
+// message appears at the bottom in UI: the latest page by default, the page older than
+// window.Before or newer than window.After, or the messages around window.Around.
+func (s *GroupChatService) GetMessagesByGroupID(ctx context.Context, groupID string, window models.GroupMessageWindow) (*models.GroupMessagePage, error) {
+	utils.Logf(ctx, "🔍 Fetching %d messages for groupId: %s", window.Limit, groupID)
+
+	page := &models.GroupMessagePage{}
+	switch {
+	case window.After != "":
+		messages, _, err := s.queryGroupMessages(ctx, groupID, true, "", "", window.Limit, window.After)
+		if err != nil {
+			return nil, err
+		}
+		page.Messages = messages
+		if len(messages) == 0 {
+			page.AfterCursor = window.After // ✅ Nothing newer yet; keep polling from the same place
+			return page, nil
+		}
+		if page.BeforeCursor, err = groupMessageCursor(messages[0]); err != nil {
+			return nil, err
+		}
+
+	case window.Around != "":
+		// ✅ The older half includes the moment itself
+		older, beforeCursor, err := s.queryGroupMessages(ctx, groupID, false, "createdAt <= :at", window.Around, window.Limit-window.Limit/2, "")
+		if err != nil {
+			return nil, err
+		}
+		page.Messages = older
+		page.BeforeCursor = beforeCursor
+		if window.Limit/2 > 0 {
+			newer, _, err := s.queryGroupMessages(ctx, groupID, true, "createdAt > :at", window.Around, window.Limit/2, "")
+			if err != nil {
+				return nil, err
+			}
+			page.Messages = append(page.Messages, newer...)
+		}
+
+	default:
+		messages, beforeCursor, err := s.queryGroupMessages(ctx, groupID, false, "", "", window.Limit, window.Before)
+		if err != nil {
+			return nil, err
+		}
+		page.Messages = messages
+		page.BeforeCursor = beforeCursor
+	}
+
+	if page.Messages == nil {
+		page.Messages = []models.GroupMessage{}
+	}
+	if len(page.Messages) > 0 {
+		var err error
+		if page.AfterCursor, err = groupMessageCursor(page.Messages[len(page.Messages)-1]); err != nil {
+			return nil, err
+		}
+	}
+
+	utils.Logf(ctx, "✅ Found %d messages for groupId: %s, returning in UI-friendly order", len(page.Messages), groupID)
+	return page, nil
+}
+
+// queryGroupMessages reads one page of a group's messages walking forward (oldest first) or
+// backward from cursor, optionally bounded by a createdAt condition on :at. Messages are returned
+// oldest first either way; the cursor continues in the same direction.
+func (s *GroupChatService) queryGroupMessages(ctx context.Context, groupID string, forward bool, createdAtCondition, at string, limit int, cursor string) ([]models.GroupMessage, string, error) {
 	keyCondition := "groupId = :groupId"
 	expressionValues := map[string]types.AttributeValue{
 		":groupId": &types.AttributeValueMemberS{Value: groupID},
 	}
+	if createdAtCondition != "" {
+		keyCondition += " AND " + createdAtCondition
+		expressionValues[":at"] = &types.AttributeValueMemberS{Value: at}
+	}
 
-	// ✅ Query DynamoDB (Retrieve latest messages first)
-	items, err := s.Dynamo.QueryItemsWithOptions(ctx, models.GroupMessageTable, keyCondition, expressionValues, nil, int32(limit), true)
+	items, nextCursor, err := s.Dynamo.QueryPage(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(models.GroupMessageTable),
+		KeyConditionExpression:    aws.String(keyCondition),
+		ExpressionAttributeValues: expressionValues,
+		ScanIndexForward:          aws.Bool(forward),
+	}, int32(limit), cursor)
 	if err != nil {
-		log.Printf("❌ Error querying group messages: %v", err)
-		return nil, fmt.Errorf("failed to fetch group messages: %w", err)
+		utils.Logf(ctx, "❌ Error querying group messages: %v", err)
+		return nil, "", err
 	}
 
 	// ✅ Unmarshal results
 	var messages []models.GroupMessage
 	err = attributevalue.UnmarshalListOfMaps(items, &messages)
 	if err != nil {
-		log.Printf("❌ Error unmarshalling group messages: %v", err)
-		return nil, fmt.Errorf("failed to parse group messages: %w", err)
+		utils.Logf(ctx, "❌ Error unmarshalling group messages: %v", err)
+		return nil, "", fmt.Errorf("failed to parse group messages: %w", err)
 	}
 
-	// ✅ Reverse the messages so latest appears at the bottom in UI
-	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
-		messages[i], messages[j] = messages[j], messages[i]
+	// ✅ Backward pages come latest first; reverse them so the latest appears at the bottom in UI
+	if !forward {
+		slices.Reverse(messages)
 	}
 
-	log.Printf("✅ Found %d messages for groupId: %s, returning in UI-friendly order", len(messages), groupID)
-	return messages, nil
+	for i := range messages {
+		s.decryptGroupMessage(ctx, &messages[i])
+		messages[i].ReadByAll = messages[i].ComputeReadByAll()
+		messages[i].SummarizeReactions()
+	}
+	return messages, nextCursor, nil
+}
+
+// groupMessageCursor is the cursor that continues a query from message, in either direction
+func groupMessageCursor(message models.GroupMessage) (string, error) {
+	cursor, err := utils.EncodeCursor(map[string]types.AttributeValue{
+		"groupId":   &types.AttributeValueMemberS{Value: message.GroupID},
+		"createdAt": &types.AttributeValueMemberS{Value: message.CreatedAt},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return cursor, nil
 }
 
 // MarkGroupMessageAsRead updates the read status of a message for a specific user
 func (s *GroupChatService) MarkGroupMessageAsRead(ctx context.Context, groupID, createdAt, userID string) error {
-	log.Printf("🔄 Marking message as read for groupId: %s, createdAt: %s by user: %s", groupID, createdAt, userID)
+	utils.Logf(ctx, "🔄 Marking message as read for groupId: %s, createdAt: %s by user: %s", groupID, createdAt, userID)
 
 	// ✅ Define update key
 	key := map[string]types.AttributeValue{
@@ -75,10 +278,19 @@ func (s *GroupChatService) MarkGroupMessageAsRead(ctx context.Context, groupID,
 		"createdAt": &types.AttributeValueMemberS{Value: createdAt},
 	}
 
-	// ✅ Update `isRead` map for the user
-	updateExpression := "SET isRead.#userId = :true, readCount = readCount + :increment"
+	// ✅ Messages stored before read timestamps existed have no `readAt` map yet
+	_, err := s.Dynamo.UpdateItem(ctx, models.GroupMessageTable, "SET readAt = if_not_exists(readAt, :empty)", key,
+		map[string]types.AttributeValue{":empty": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{}}}, nil)
+	if err != nil {
+		utils.Logf(ctx, "❌ Failed to prepare read timestamps: %v", err)
+		return fmt.Errorf("failed to update read status: %w", err)
+	}
+
+	// ✅ Update `isRead` map for the user, keeping the first read time
+	updateExpression := "SET isRead.#userId = :true, readAt.#userId = if_not_exists(readAt.#userId, :now), readCount = readCount + :increment"
 	expressionValues := map[string]types.AttributeValue{
 		":true":      &types.AttributeValueMemberBOOL{Value: true},
+		":now":       &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)},
 		":increment": &types.AttributeValueMemberN{Value: "1"},
 	}
 	expressionNames := map[string]string{
@@ -86,77 +298,176 @@ func (s *GroupChatService) MarkGroupMessageAsRead(ctx context.Context, groupID,
 	}
 
 	// ✅ Perform update
-	_, err := s.Dynamo.UpdateItem(ctx, models.GroupMessageTable, updateExpression, key, expressionValues, expressionNames)
+	_, err = s.Dynamo.UpdateItem(ctx, models.GroupMessageTable, updateExpression, key, expressionValues, expressionNames)
 	if err != nil {
-		log.Printf("❌ Failed to update read status: %v", err)
+		utils.Logf(ctx, "❌ Failed to update read status: %v", err)
 		return fmt.Errorf("failed to update read status: %w", err)
 	}
 
-	log.Printf("✅ Message marked as read by %s", userID)
+	utils.Logf(ctx, "✅ Message marked as read by %s", userID)
 	return nil
 }
 
-// LikeGroupMessage allows a user to like or unlike a message
+// LikeGroupMessage toggles the user's LegacyLikeReaction on a group message, for clients that
+// predate reactions
 func (s *GroupChatService) LikeGroupMessage(ctx context.Context, groupID, createdAt, userID string) error {
-	log.Printf("💖 Updating like status for message at %s in groupId: %s by user: %s", createdAt, groupID, userID)
+	utils.Logf(ctx, "💖 Toggling like for message at %s in groupId: %s by user: %s", createdAt, groupID, userID)
 
-	// ✅ Define update key
-	key := map[string]types.AttributeValue{
-		"groupId":   &types.AttributeValueMemberS{Value: groupID},
-		"createdAt": &types.AttributeValueMemberS{Value: createdAt},
+	item, err := s.Dynamo.GetItemAttributes(ctx, models.GroupMessageTable, groupMessageKey(groupID, createdAt), "likes", "reactions")
+	if errors.Is(err, ErrNotFound) {
+		return notFoundError("message not found")
 	}
-
-	// ✅ Fetch current message to check if user already liked it
-	item, err := s.Dynamo.GetItem(ctx, models.GroupMessageTable, key)
 	if err != nil {
-		log.Printf("❌ Error fetching message: %v", err)
-		return fmt.Errorf("failed to fetch message: %w", err)
+		return err
 	}
-
 	var message models.GroupMessage
-	err = attributevalue.UnmarshalMap(item, &message)
-	if err != nil {
-		log.Printf("❌ Error unmarshalling message: %v", err)
+	if err := attributevalue.UnmarshalMap(item, &message); err != nil {
 		return fmt.Errorf("failed to parse message: %w", err)
 	}
+	message.SummarizeReactions()
 
-	// ✅ Toggle like status
-	liked := false
-	if _, exists := message.Likes[userID]; !exists {
-		liked = true
+	if message.Likes[userID] {
+		_, err = s.RemoveReaction(ctx, groupID, createdAt, userID, models.LegacyLikeReaction)
+	} else {
+		_, err = s.AddReaction(ctx, groupID, createdAt, userID, models.LegacyLikeReaction)
 	}
+	return err
+}
 
-	// ✅ Update `likes` map
-	updateExpression := "SET likes.#userId = :liked, likeCount = likeCount + :increment"
-	expressionValues := map[string]types.AttributeValue{
-		":liked": &types.AttributeValueMemberBOOL{Value: liked},
-		":increment": &types.AttributeValueMemberN{
-			Value: "1",
+// AddReaction adds the member's emoji reaction to a group message (a member may use several
+// emoji on one message) and returns the message's reactions
+func (s *GroupChatService) AddReaction(ctx context.Context, groupID, createdAt, userHandle, emoji string) ([]models.GroupReaction, error) {
+	if !models.IsValidReaction(emoji) {
+		return nil, validationError("reaction must be a single emoji")
+	}
+	if err := s.checkActiveMember(ctx, groupID, userHandle); err != nil {
+		return nil, err
+	}
+	key := groupMessageKey(groupID, createdAt)
+
+	// ✅ Messages stored before reactions existed have no `reactions` map yet; system entries take none
+	var conditionFailed *types.ConditionalCheckFailedException
+	_, err := s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(models.GroupMessageTable),
+		Key:                 key,
+		UpdateExpression:    aws.String("SET reactions = if_not_exists(reactions, :empty)"),
+		ConditionExpression: aws.String("attribute_exists(createdAt) AND (attribute_not_exists(messageType) OR messageType <> :system)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":empty":  &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{}},
+			":system": &types.AttributeValueMemberS{Value: models.GroupMessageTypeSystem},
 		},
+	})
+	if errors.As(err, &conditionFailed) {
+		return nil, notFoundError("message not found")
 	}
-	if !liked {
-		updateExpression = "REMOVE likes.#userId SET likeCount = likeCount - :decrement"
-		expressionValues[":decrement"] = &types.AttributeValueMemberN{Value: "1"}
+	if err != nil {
+		return nil, fmt.Errorf("failed to add reaction: %w", err)
 	}
 
-	expressionNames := map[string]string{
-		"#userId": userID,
+	output, err := s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(models.GroupMessageTable),
+		Key:                 key,
+		UpdateExpression:    aws.String("ADD reactions.#emoji :user"),
+		ConditionExpression: aws.String("attribute_exists(reactions.#emoji) OR size(reactions) < :max"),
+		ExpressionAttributeNames: map[string]string{
+			"#emoji": emoji,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":user": &types.AttributeValueMemberSS{Value: []string{userHandle}},
+			":max":  &types.AttributeValueMemberN{Value: strconv.Itoa(models.MaxReactionsPerMessage)},
+		},
+		ReturnValues: types.ReturnValueAllNew,
+	})
+	if errors.As(err, &conditionFailed) {
+		return nil, conflictError(fmt.Sprintf("a message can have at most %d different reactions", models.MaxReactionsPerMessage))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to add reaction: %w", err)
 	}
 
-	// ✅ Perform update
-	_, err = s.Dynamo.UpdateItem(ctx, models.GroupMessageTable, updateExpression, key, expressionValues, expressionNames)
+	utils.Logf(ctx, "💖 %s reacted %s to message at %s in groupId: %s", userHandle, emoji, createdAt, groupID)
+	return reactionsOf(output.Attributes)
+}
+
+// RemoveReaction takes the member's emoji reaction off a group message (a legacy like too, for
+// LegacyLikeReaction) and returns the message's reactions
+func (s *GroupChatService) RemoveReaction(ctx context.Context, groupID, createdAt, userHandle, emoji string) ([]models.GroupReaction, error) {
+	if !models.IsValidReaction(emoji) {
+		return nil, validationError("reaction must be a single emoji")
+	}
+	if err := s.checkActiveMember(ctx, groupID, userHandle); err != nil {
+		return nil, err
+	}
+	key := groupMessageKey(groupID, createdAt)
+
+	item, err := s.Dynamo.GetItemAttributes(ctx, models.GroupMessageTable, key, "likes", "reactions")
+	if errors.Is(err, ErrNotFound) {
+		return nil, notFoundError("message not found")
+	}
 	if err != nil {
-		log.Printf("❌ Failed to update like status: %v", err)
-		return fmt.Errorf("failed to update like status: %w", err)
+		return nil, err
+	}
+	var message models.GroupMessage
+	if err := attributevalue.UnmarshalMap(item, &message); err != nil {
+		return nil, fmt.Errorf("failed to parse message: %w", err)
 	}
 
-	log.Printf("✅ Successfully updated like status for message at %s", createdAt)
-	return nil
+	// ✅ Deleting the last handle from a set removes the emoji; REMOVE needs the legacy map to exist
+	var updates []string
+	names := map[string]string{}
+	values := map[string]types.AttributeValue{}
+	if slices.Contains(message.ReactionSets[emoji], userHandle) {
+		updates = append(updates, "DELETE reactions.#emoji :user")
+		names["#emoji"] = emoji
+		values[":user"] = &types.AttributeValueMemberSS{Value: []string{userHandle}}
+	}
+	if emoji == models.LegacyLikeReaction && message.Likes[userHandle] {
+		updates = append(updates, "REMOVE likes.#userId SET likeCount = likeCount - :one")
+		names["#userId"] = userHandle
+		values[":one"] = &types.AttributeValueMemberN{Value: "1"}
+	}
+	if len(updates) == 0 {
+		message.SummarizeReactions()
+		return message.Reactions, nil
+	}
+
+	output, err := s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(models.GroupMessageTable),
+		Key:                       key,
+		UpdateExpression:          aws.String(strings.Join(updates, " ")),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+		ReturnValues:              types.ReturnValueAllNew,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove reaction: %w", err)
+	}
+
+	utils.Logf(ctx, "✅ %s removed %s from message at %s in groupId: %s", userHandle, emoji, createdAt, groupID)
+	return reactionsOf(output.Attributes)
+}
+
+// reactionsOf summarizes the reactions of a stored group message
+func reactionsOf(item map[string]types.AttributeValue) ([]models.GroupReaction, error) {
+	var message models.GroupMessage
+	if err := attributevalue.UnmarshalMap(item, &message); err != nil {
+		return nil, fmt.Errorf("failed to parse message: %w", err)
+	}
+	message.SummarizeReactions()
+	return message.Reactions, nil
+}
+
+// groupMessageKey builds the GroupMessages primary key
+func groupMessageKey(groupID, createdAt string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"groupId":   &types.AttributeValueMemberS{Value: groupID},
+		"createdAt": &types.AttributeValueMemberS{Value: createdAt},
+	}
 }
 
 // GetLastMessageByGroupID fetches the most recent message in a group
 func (s *GroupChatService) GetLastMessageByGroupID(ctx context.Context, groupID string) (*models.GroupMessage, error) {
-	log.Printf("🔍 Fetching last message for groupId: %s", groupID)
+	utils.Logf(ctx, "🔍 Fetching last message for groupId: %s", groupID)
 
 	// ✅ Define key condition
 	keyCondition := "groupId = :groupId"
@@ -167,13 +478,13 @@ func (s *GroupChatService) GetLastMessageByGroupID(ctx context.Context, groupID
 	// ✅ Query DynamoDB for the latest message
 	items, err := s.Dynamo.QueryItemsWithOptions(ctx, models.GroupMessageTable, keyCondition, expressionValues, nil, 1, true) // Descending order
 	if err != nil {
-		log.Printf("❌ Error fetching last message: %v", err)
+		utils.Logf(ctx, "❌ Error fetching last message: %v", err)
 		return nil, fmt.Errorf("failed to fetch last message: %w", err)
 	}
 
 	// ✅ If no message is found, return nil
 	if len(items) == 0 {
-		log.Printf("ℹ️ No messages found for groupId: %s", groupID)
+		utils.Logf(ctx, "ℹ️ No messages found for groupId: %s", groupID)
 		return nil, nil
 	}
 
@@ -181,10 +492,255 @@ func (s *GroupChatService) GetLastMessageByGroupID(ctx context.Context, groupID
 	var lastMessage models.GroupMessage
 	err = attributevalue.UnmarshalMap(items[0], &lastMessage)
 	if err != nil {
-		log.Printf("❌ Error unmarshalling last message: %v", err)
+		utils.Logf(ctx, "❌ Error unmarshalling last message: %v", err)
 		return nil, fmt.Errorf("failed to parse last message: %w", err)
 	}
+	s.decryptGroupMessage(ctx, &lastMessage)
+	lastMessage.SummarizeReactions()
 
-	log.Printf("✅ Last message for groupId %s: %+v", groupID, lastMessage)
+	utils.Logf(ctx, "✅ Last message for groupId %s: %+v", groupID, lastMessage)
 	return &lastMessage, nil
 }
+
+// decryptGroupMessage prepares a stored group message for display: messages hidden by moderation lose
+// their content, everything else is decrypted
+func (s *GroupChatService) decryptGroupMessage(ctx context.Context, message *models.GroupMessage) {
+	if message.Hidden {
+		message.WithholdContent()
+		return
+	}
+	s.decryptGroupContent(ctx, message)
+}
+
+// decryptGroupContent replaces ciphertext content and reply snippet with plaintext; undecryptable content is blanked
+func (s *GroupChatService) decryptGroupContent(ctx context.Context, message *models.GroupMessage) {
+	if !message.Encrypted {
+		return
+	}
+	if !s.Encryption.Enabled() {
+		utils.Logf(ctx, "⚠️ Group message %s is encrypted but encryption is not configured", message.MessageID)
+		message.Content, message.ReplySnippet = "", ""
+		return
+	}
+
+	plaintext, err := s.Encryption.Decrypt(ctx, message.GroupID, message.Content, message.KeyVersion)
+	if err != nil {
+		utils.Logf(ctx, "❌ Failed to decrypt group message %s: %v", message.MessageID, err)
+		plaintext = ""
+	}
+	message.Content = plaintext
+
+	if message.ReplySnippet != "" {
+		snippet, err := s.Encryption.Decrypt(ctx, message.GroupID, message.ReplySnippet, message.ReplyKeyVersion)
+		if err != nil {
+			utils.Logf(ctx, "❌ Failed to decrypt reply snippet of group message %s: %v", message.MessageID, err)
+			snippet = ""
+		}
+		message.ReplySnippet = snippet
+	}
+}
+
+// resolveReply checks that a reply quotes an existing member message of the same group and fills
+// in the quoted sender and snippet; anything a client sent for those is overwritten
+func (s *GroupChatService) resolveReply(ctx context.Context, message *models.GroupMessage) error {
+	message.ReplyToSenderID, message.ReplySnippet, message.ReplyKeyVersion = "", "", 0
+	if message.ReplyToMessageID == "" && message.ReplyToCreatedAt == "" {
+		return nil
+	}
+	if message.ReplyToMessageID == "" || message.ReplyToCreatedAt == "" {
+		return validationError("replyToMessageId and replyToCreatedAt must be given together")
+	}
+
+	item, err := s.Dynamo.GetItem(ctx, models.GroupMessageTable, map[string]types.AttributeValue{
+		"groupId":   &types.AttributeValueMemberS{Value: message.GroupID},
+		"createdAt": &types.AttributeValueMemberS{Value: message.ReplyToCreatedAt},
+	})
+	if errors.Is(err, ErrNotFound) {
+		return validationError("the message being replied to does not exist in this group")
+	}
+	if err != nil {
+		return err
+	}
+	var quoted models.GroupMessage
+	if err := attributevalue.UnmarshalMap(item, &quoted); err != nil {
+		return fmt.Errorf("failed to parse quoted group message: %w", err)
+	}
+	if quoted.MessageID != message.ReplyToMessageID {
+		return validationError("the message being replied to does not exist in this group")
+	}
+	if quoted.MessageType == models.GroupMessageTypeSystem {
+		return validationError("activity entries cannot be replied to")
+	}
+
+	s.decryptGroupMessage(ctx, &quoted)
+	message.ReplyToSenderID = quoted.SenderID
+	message.ReplySnippet = models.ReplySnippet(quoted.Content, quoted.ImageURL != nil && *quoted.ImageURL != "")
+	return nil
+}
+
+// GetUnreadCounts returns, per group and in aggregate, how many group messages the user has not read
+func (s *GroupChatService) GetUnreadCounts(ctx context.Context, userHandle string) (*models.UnreadCounts, error) {
+	utils.Logf(ctx, "🔍 Counting unread group messages for user: %s", userHandle)
+
+	groupIDs, err := s.getGroupIDsForUser(ctx, userHandle)
+	if err != nil {
+		utils.Logf(ctx, "❌ Error fetching groups for unread counts: %v", err)
+		return nil, fmt.Errorf("failed to fetch groups: %w", err)
+	}
+
+	counts, err := s.countUnread(ctx, userHandle, groupIDs)
+	if err != nil {
+		utils.Logf(ctx, "❌ Error counting unread group messages: %v", err)
+		return nil, err
+	}
+
+	unread := &models.UnreadCounts{Conversations: map[string]int{}}
+	for i, groupID := range groupIDs {
+		unread.Add(groupID, counts[i])
+	}
+
+	utils.Logf(ctx, "✅ User %s has %d unread group messages across %d groups", userHandle, unread.Total, unread.UnreadConversations)
+	return unread, nil
+}
+
+// countUnread returns how many user messages userHandle hasn't read in each group, in groupIDs order
+func (s *GroupChatService) countUnread(ctx context.Context, userHandle string, groupIDs []string) ([]int, error) {
+	counts := make([]int, len(groupIDs))
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(unreadCountConcurrency)
+	for i, groupID := range groupIDs {
+		group.Go(func() error {
+			count, err := s.Dynamo.CountItems(groupCtx, &dynamodb.QueryInput{
+				TableName:              aws.String(models.GroupMessageTable),
+				KeyConditionExpression: aws.String("groupId = :groupId"),
+				FilterExpression:       aws.String("isRead.#userId = :false AND (attribute_not_exists(messageType) OR messageType <> :system)"),
+				ExpressionAttributeNames: map[string]string{
+					"#userId": userHandle,
+				},
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":groupId": &types.AttributeValueMemberS{Value: groupID},
+					":false":   &types.AttributeValueMemberBOOL{Value: false},
+					":system":  &types.AttributeValueMemberS{Value: models.GroupMessageTypeSystem},
+				},
+			})
+			if err != nil {
+				return fmt.Errorf("failed to count unread messages for groupId %s: %w", groupID, err)
+			}
+			counts[i] = count
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// getGroupIDsForUser lists every active group the user belongs to
+func (s *GroupChatService) getGroupIDsForUser(ctx context.Context, userHandle string) ([]string, error) {
+	items, err := s.Dynamo.QueryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.GroupInteractionsTable),
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :groupPrefix)"),
+		FilterExpression:       aws.String("#status = :active"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":          &types.AttributeValueMemberS{Value: "USER#" + userHandle},
+			":groupPrefix": &types.AttributeValueMemberS{Value: "GROUP#"},
+			":active":      &types.AttributeValueMemberS{Value: "active"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var groupIDs []string
+	for _, item := range items {
+		var group models.GroupInteraction
+		if err := attributevalue.UnmarshalMap(item, &group); err != nil || group.GroupID == nil {
+			continue
+		}
+		groupIDs = append(groupIDs, *group.GroupID)
+	}
+	return groupIDs, nil
+}
+
+// SearchMessages finds group messages containing a keyword within an optional date range,
+// returning the newest hits first with up to ContextSize messages on either side
+func (s *GroupChatService) SearchMessages(ctx context.Context, groupID string, query models.MessageSearchQuery) ([]models.GroupMessageSearchResult, error) {
+	utils.Logf(ctx, "🔍 Searching messages for groupId: %s (from=%q, to=%q)", groupID, query.From, query.To)
+
+	search := conversationSearch[models.GroupMessage]{
+		dynamo:  s.Dynamo,
+		table:   models.GroupMessageTable,
+		pkAttr:  "groupId",
+		pkValue: groupID,
+		decrypt: func(m *models.GroupMessage) { s.decryptGroupMessage(ctx, m); m.SummarizeReactions() },
+		content: func(m *models.GroupMessage) string { return m.Content },
+	}
+	hits, err := search.run(ctx, query)
+	if err != nil {
+		utils.Logf(ctx, "❌ Error searching group messages: %v", err)
+		return nil, err
+	}
+
+	results := make([]models.GroupMessageSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		results = append(results, models.GroupMessageSearchResult{Message: hit.message, Before: hit.before, After: hit.after})
+	}
+
+	utils.Logf(ctx, "✅ Found %d matching messages for groupId: %s", len(results), groupID)
+	return results, nil
+}
+
+// GetReadReceipts lists which members have read a group message (with timestamps) and who is pending.
+// Returns nil when the message does not exist.
+func (s *GroupChatService) GetReadReceipts(ctx context.Context, groupID, createdAt string) (*models.GroupMessageReceipts, error) {
+	utils.Logf(ctx, "🔍 Fetching read receipts for groupId: %s, createdAt: %s", groupID, createdAt)
+
+	key := map[string]types.AttributeValue{
+		"groupId":   &types.AttributeValueMemberS{Value: groupID},
+		"createdAt": &types.AttributeValueMemberS{Value: createdAt},
+	}
+	item, err := s.Dynamo.GetItemAttributes(ctx, models.GroupMessageTable, key, "groupId", "createdAt", "messageId", "isRead", "readAt", "readCount", "memberCount")
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch message: %w", err)
+	}
+
+	var message models.GroupMessage
+	if err := attributevalue.UnmarshalMap(item, &message); err != nil {
+		return nil, fmt.Errorf("failed to parse message: %w", err)
+	}
+
+	receipts := &models.GroupMessageReceipts{
+		GroupID:     message.GroupID,
+		MessageID:   message.MessageID,
+		CreatedAt:   message.CreatedAt,
+		Readers:     []models.ReadReceipt{},
+		Pending:     []string{},
+		ReadCount:   message.ReadCount,
+		MemberCount: message.MemberCount,
+		ReadByAll:   message.ComputeReadByAll(),
+	}
+	for member, read := range message.IsRead {
+		if read {
+			receipts.Readers = append(receipts.Readers, models.ReadReceipt{UserHandle: member, ReadAt: message.ReadAt[member]})
+		} else {
+			receipts.Pending = append(receipts.Pending, member)
+		}
+	}
+	sort.Slice(receipts.Readers, func(i, j int) bool {
+		if receipts.Readers[i].ReadAt != receipts.Readers[j].ReadAt {
+			return receipts.Readers[i].ReadAt < receipts.Readers[j].ReadAt
+		}
+		return receipts.Readers[i].UserHandle < receipts.Readers[j].UserHandle
+	})
+	sort.Strings(receipts.Pending)
+
+	utils.Logf(ctx, "✅ Message %s read by %d, pending %d", message.MessageID, len(receipts.Readers), len(receipts.Pending))
+	return receipts, nil
+}
@@ -3,57 +3,99 @@ package services
 import (
 	"context"
 	"errors"
-	"log"
+	"fmt"
+	"strings"
 	"time"
 	"vibin_server/models"
+	"vibin_server/utils"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/google/uuid"
 )
 
+// ErrNotAnApprover is returned when someone outside the invite's approver list tries to decide on it
+var ErrNotAnApprover = errors.New("not_an_approver")
+
+// ErrGroupNotFound is returned when the user has no active membership in the group
+var ErrGroupNotFound = notFoundError("group_not_found")
+
+// ErrInviteResolved is returned when a decision arrives after the invite was approved or declined
+var ErrInviteResolved = conflictError("invite_already_resolved")
+
+// ErrInvalidInvitee is returned when the invitee handle has no profile
+var ErrInvalidInvitee = notFoundError("invalid_invitee_handle")
+
 // GroupInteractionService handles operations related to group invites and approvals
 type GroupInteractionService struct {
 	Dynamo             *DynamoService
 	UserProfileService *UserProfileService
+	GroupChat          *GroupChatService // ✅ Writes membership/metadata changes into the group timeline
 }
 
 // ✅ CreateGroupInvite - Adds a new group invite to DynamoDB after validating the InviteeHandle
 func (s *GroupInteractionService) CreateGroupInvite(ctx context.Context, invite models.GroupInteraction) error {
-	log.Printf("🔍 Validating invitee handle: %s", invite.InviteeHandle)
+	utils.Logf(ctx, "🔍 Validating invitee handle: %s", invite.InviteeHandle)
 
 	// ✅ Step 1: Validate InviteeHandle (Check if user exists)
 	isAvailable, err := s.UserProfileService.IsUserHandleAvailable(ctx, invite.InviteeHandle)
 	if err != nil {
-		log.Printf("❌ Failed to validate invitee handle '%s': %v", invite.InviteeHandle, err)
+		utils.Logf(ctx, "❌ Failed to validate invitee handle '%s': %v", invite.InviteeHandle, err)
 		return errors.New("failed to validate invitee handle") // Keep it generic for logging purposes
 	}
 
 	// If the handle is available (i.e., user does not exist), reject the invite
 	if isAvailable {
-		log.Printf("🚫 Invalid invitee handle: '%s' does not exist in the system", invite.InviteeHandle)
-		return errors.New("invalid_invitee_handle") // Use a specific error for better handling in the controller
+		utils.Logf(ctx, "🚫 Invalid invitee handle: '%s' does not exist in the system", invite.InviteeHandle)
+		return ErrInvalidInvitee
 	}
 
 	// ✅ Step 2: Store the invite in DynamoDB (only if validation succeeds)
-	log.Printf("✅ Invitee handle '%s' is valid. Proceeding to store the invite in DynamoDB.", invite.InviteeHandle)
+	utils.Logf(ctx, "✅ Invitee handle '%s' is valid. Proceeding to store the invite in DynamoDB.", invite.InviteeHandle)
 	err = s.Dynamo.PutItem(ctx, models.GroupInteractionsTable, invite)
 	if err != nil {
-		log.Printf("❌ Failed to store group invite for '%s' in DynamoDB: %v", invite.InviteeHandle, err)
+		utils.Logf(ctx, "❌ Failed to store group invite for '%s' in DynamoDB: %v", invite.InviteeHandle, err)
+		return errors.New("failed to store group invite")
+	}
+
+	// ✅ Step 3: Surface the invite in every additional approver's pending list
+	if err := s.syncApprovalPointers(ctx, invite); err != nil {
+		utils.Logf(ctx, "❌ Failed to store approval entries for invite to '%s': %v", invite.InviteeHandle, err)
 		return errors.New("failed to store group invite")
 	}
 
-	log.Printf("✅ Successfully stored group invite for '%s' in DynamoDB.", invite.InviteeHandle)
+	utils.Logf(ctx, "✅ Successfully stored group invite for '%s' in DynamoDB.", invite.InviteeHandle)
 	return nil
 }
 
-// ✅ GetSentInvites - Fetches invites created by User A
-func (s *GroupInteractionService) GetSentInvites(ctx context.Context, userHandle string) ([]models.GroupInteraction, error) {
-	return s.queryGroupInteractions(ctx, "USER#"+userHandle)
+// ✅ GetSentInvites - Fetches one page of invites created by User A (pass the returned cursor back for more)
+func (s *GroupInteractionService) GetSentInvites(ctx context.Context, userHandle string, limit int32, cursor string) ([]models.GroupInteraction, string, error) {
+	// ✅ Approval rows live in the approver's partition but are not invites they sent
+	items, nextCursor, err := s.Dynamo.QueryPage(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.GroupInteractionsTable),
+		KeyConditionExpression: aws.String("PK = :pk"),
+		FilterExpression:       aws.String("attribute_not_exists(interactionType) OR interactionType <> :approval"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":       &types.AttributeValueMemberS{Value: "USER#" + userHandle},
+			":approval": &types.AttributeValueMemberS{Value: models.InteractionTypeGroupApproval},
+		},
+	}, limit, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var sent []models.GroupInteraction
+	if err := attributevalue.UnmarshalListOfMaps(items, &sent); err != nil {
+		return nil, "", err
+	}
+	return sent, nextCursor, nil
 }
 
-func (s *GroupInteractionService) GetPendingApprovals(ctx context.Context, approverHandle string) ([]models.GroupInteraction, error) {
-	log.Printf("🔍 Fetching pending approvals for approverHandle: %s", approverHandle)
+// GetPendingApprovals fetches one page of invites waiting on this approver (pass the returned cursor back for more)
+func (s *GroupInteractionService) GetPendingApprovals(ctx context.Context, approverHandle string, limit int32, cursor string) ([]models.GroupInteraction, string, error) {
+	utils.Logf(ctx, "🔍 Fetching pending approvals for approverHandle: %s", approverHandle)
 
 	keyCondition := "approverHandle = :approver AND #status = :status"
 	expressionValues := map[string]types.AttributeValue{
@@ -66,33 +108,63 @@ func (s *GroupInteractionService) GetPendingApprovals(ctx context.Context, appro
 		"#status": "status",
 	}
 
-	log.Printf("📌 DynamoDB Query - Table: %s, Index: %s, KeyCondition: %s, Values: %+v",
+	utils.Logf(ctx, "📌 DynamoDB Query - Table: %s, Index: %s, KeyCondition: %s, Values: %+v",
 		models.GroupInteractionsTable, models.ApprovalIndex, keyCondition, expressionValues)
 
 	// ✅ Query DynamoDB
-	items, err := s.Dynamo.QueryItemsWithIndex(ctx, models.GroupInteractionsTable, models.ApprovalIndex, keyCondition, expressionValues, expressionNames, 100)
+	items, nextCursor, err := s.Dynamo.QueryPage(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(models.GroupInteractionsTable),
+		IndexName:                 aws.String(models.ApprovalIndex),
+		KeyConditionExpression:    aws.String(keyCondition),
+		ExpressionAttributeValues: expressionValues,
+		ExpressionAttributeNames:  expressionNames,
+	}, limit, cursor)
 	if err != nil {
-		log.Printf("❌ Error querying DynamoDB: %v", err)
-		return nil, err
+		utils.Logf(ctx, "❌ Error querying DynamoDB: %v", err)
+		return nil, "", err
 	}
 
-	log.Printf("✅ Query successful. Items retrieved: %d", len(items))
+	utils.Logf(ctx, "✅ Query successful. Items retrieved: %d", len(items))
 
 	var pendingInvites []models.GroupInteraction
 	if err := attributevalue.UnmarshalListOfMaps(items, &pendingInvites); err != nil {
-		log.Printf("❌ Error unmarshaling DynamoDB items: %v", err)
-		return nil, err
+		utils.Logf(ctx, "❌ Error unmarshaling DynamoDB items: %v", err)
+		return nil, "", err
+	}
+
+	// ✅ Hide invites this approver has already decided on (multi-approver invites stay pending)
+	undecided := pendingInvites[:0]
+	for _, invite := range pendingInvites {
+		if _, decided := invite.Decisions[approverHandle]; !decided {
+			undecided = append(undecided, invite)
+		}
+	}
+	pendingInvites = undecided
+
+	// ✅ Batch fetch user profiles for all invitees
+	inviteeHandles := make([]string, 0, len(pendingInvites))
+	for _, invite := range pendingInvites {
+		inviteeHandles = append(inviteeHandles, invite.InviteeHandle)
+	}
+	missingReason := models.EnrichmentReasonProfileNotFound
+	profiles, err := s.UserProfileService.GetUserProfilesByHandles(ctx, inviteeHandles)
+	if err != nil {
+		utils.Logf(ctx, "❌ Error fetching invitee profiles, returning placeholders: %v", err)
+		profiles = map[string]*models.UserProfile{}
+		missingReason = models.EnrichmentReasonProfileFetchFailed
 	}
 
-	// ✅ Fetch user profiles for invitees
+	failed := 0
 	for i, invite := range pendingInvites {
 		inviteeHandle := invite.InviteeHandle
 
-		// Fetch profile for each invitee
-		profile, err := s.UserProfileService.GetUserProfileByHandle(ctx, inviteeHandle)
-		if err != nil {
-			log.Printf("⚠️ Failed to fetch user profile for %s: %v", inviteeHandle, err)
-			continue // Skip this invitee if profile fetch fails
+		profile, ok := profiles[inviteeHandle]
+		if !ok {
+			utils.Logf(ctx, "⚠️ Profile unavailable for invitee %s (%s)", inviteeHandle, missingReason)
+			failed++
+			pendingInvites[i].EnrichmentError = true
+			pendingInvites[i].RetryHint = enrichmentRetryHint(missingReason)
+			continue // Keep the invite visible without a profile
 		}
 
 		// Extract photo
@@ -113,74 +185,132 @@ func (s *GroupInteractionService) GetPendingApprovals(ctx context.Context, appro
 			Orientation: profile.Orientation,
 		}
 
-		log.Printf("✅ Fetched user profile for invitee %s: %+v", inviteeHandle, invite.InviteeProfile)
+		utils.Logf(ctx, "✅ Fetched user profile for invitee %s: %+v", inviteeHandle, invite.InviteeProfile)
 
 		// Update the invite entry
 		pendingInvites[i] = invite
 	}
 
-	log.Printf("✅ Successfully retrieved %d pending invites with enriched invitee profiles", len(pendingInvites))
-	return pendingInvites, nil
+	recordEnrichment("pending_approvals", len(pendingInvites), failed)
+	utils.Logf(ctx, "✅ Successfully retrieved %d pending invites with enriched invitee profiles", len(pendingInvites))
+	return pendingInvites, nextCursor, nil
+}
+
+// CountPendingApprovals counts the invites waiting on this approver's decision
+func (s *GroupInteractionService) CountPendingApprovals(ctx context.Context, approverHandle string) (int, error) {
+	count, err := s.Dynamo.CountItems(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.GroupInteractionsTable),
+		IndexName:              aws.String(models.ApprovalIndex),
+		KeyConditionExpression: aws.String("approverHandle = :approver AND #status = :status"),
+		FilterExpression:       aws.String("attribute_not_exists(decisions.#approver)"), // ✅ Multi-approver invites stay pending after this approver decided
+		ExpressionAttributeNames: map[string]string{
+			"#status":   "status",
+			"#approver": approverHandle,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":approver": &types.AttributeValueMemberS{Value: approverHandle},
+			":status":   &types.AttributeValueMemberS{Value: "pending"},
+		},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pending approvals: %w", err)
+	}
+	return count, nil
 }
 
 // ✅ ApproveOrDeclineInvite - Approves or declines a pending invite
 func (s *GroupInteractionService) ApproveOrDeclineInvite(ctx context.Context, approverHandle, inviterHandle, inviteeHandle, status string) error {
-	log.Printf("🔍 ApproveOrDeclineInvite: Processing request for Approver: %s, Inviter: %s, Invitee: %s, Status: %s", approverHandle, inviterHandle, inviteeHandle, status)
+	utils.Logf(ctx, "🔍 ApproveOrDeclineInvite: Processing request for Approver: %s, Inviter: %s, Invitee: %s, Status: %s", approverHandle, inviterHandle, inviteeHandle, status)
 
 	// ✅ Validate status
 	if status != "approved" && status != "declined" {
-		log.Printf("❌ Invalid status value: %s. Expected 'approved' or 'declined'.", status)
-		return errors.New("invalid status value")
+		utils.Logf(ctx, "❌ Invalid status value: %s. Expected 'approved' or 'declined'.", status)
+		return validationError("invalid status value")
 	}
 
 	// ✅ Fetch the existing invite
 	pk := "USER#" + inviterHandle
 	sk := "GROUP_INVITE#" + inviteeHandle
 
-	log.Printf("📌 Fetching pending invite from GroupInteractions - PK: %s, SK: %s", pk, sk)
+	utils.Logf(ctx, "📌 Fetching pending invite from GroupInteractions - PK: %s, SK: %s", pk, sk)
 	invite, err := s.getGroupInteraction(ctx, pk, sk)
 	if err != nil {
-		log.Printf("❌ Error fetching invite for Inviter: %s, Invitee: %s - Error: %v", inviterHandle, inviteeHandle, err)
+		utils.Logf(ctx, "❌ Error fetching invite for Inviter: %s, Invitee: %s - Error: %v", inviterHandle, inviteeHandle, err)
 		return err
 	}
 	if invite == nil {
-		log.Printf("⚠️ Invite not found for Inviter: %s, Invitee: %s", inviterHandle, inviteeHandle)
-		return errors.New("invite not found")
+		utils.Logf(ctx, "⚠️ Invite not found for Inviter: %s, Invitee: %s", inviterHandle, inviteeHandle)
+		return notFoundError("invite not found")
 	}
 
-	log.Printf("✅ Invite found: %+v", invite)
+	utils.Logf(ctx, "✅ Invite found: %+v", invite)
 
-	// ✅ If approved, generate a group ID (if not already present)
-	var groupId *string
-	if invite.GroupID != nil {
-		groupId = invite.GroupID
-	} else if status == "approved" {
-		newGroupId := uuid.New().String()
-		groupId = &newGroupId
-		log.Printf("✅ Approved! Assigning new GroupID: %s", *groupId)
+	// ✅ Only the invite's approvers may decide, and each decides once
+	if !contains(invite.RequiredApprovers(), approverHandle) {
+		utils.Logf(ctx, "🚫 %s is not an approver of the invite from %s to %s", approverHandle, inviterHandle, inviteeHandle)
+		return ErrNotAnApprover
+	}
+	if invite.Status != "pending" {
+		utils.Logf(ctx, "⚠️ Invite from %s to %s is already %s", inviterHandle, inviteeHandle, invite.Status)
+		return ErrInviteResolved
 	}
 
-	// ✅ Update the invite status
-	invite.Status = status
-	invite.GroupID = groupId
-	invite.LastUpdated = time.Now()
-
-	log.Printf("📤 Saving updated invite in DynamoDB: %+v", invite)
-	if err := s.updateGroupInteraction(ctx, *invite); err != nil {
-		log.Printf("❌ Error updating invite in DynamoDB: %v", err)
+	// ✅ Record this approver's decision atomically; the outcome comes from every decision stored so far
+	invite, err = s.recordInviteDecision(ctx, pk, sk, approverHandle, status)
+	if err != nil {
+		utils.Logf(ctx, "❌ Error recording decision of %s on invite to %s: %v", approverHandle, inviteeHandle, err)
 		return err
 	}
+	outcome := invite.ApprovalOutcome()
+
+	if outcome != "pending" {
+		// ✅ If approved, generate a group ID (if not already present)
+		groupId := invite.GroupID
+		if groupId == nil && outcome == "approved" {
+			newGroupId := uuid.New().String()
+			groupId = &newGroupId
+			utils.Logf(ctx, "✅ Approved! Assigning new GroupID: %s", *groupId)
+		}
+		resolved, err := s.resolveInvite(ctx, pk, sk, outcome, groupId)
+		if err != nil {
+			utils.Logf(ctx, "❌ Error resolving invite from %s to %s: %v", inviterHandle, inviteeHandle, err)
+			return err
+		}
+		if !resolved {
+			utils.Logf(ctx, "ℹ️ Invite from %s to %s was resolved by a concurrent decision", inviterHandle, inviteeHandle)
+			return nil
+		}
+		invite.Status = outcome
+		invite.GroupID = groupId
+	}
+	if err := s.syncApprovalPointers(ctx, *invite); err != nil {
+		utils.Logf(ctx, "❌ Error updating approval entries: %v", err)
+		return err
+	}
+	groupId := invite.GroupID
+
+	// ✅ Still waiting on other approvers
+	if outcome == "pending" {
+		utils.Logf(ctx, "ℹ️ Recorded %s from %s; invite to %s awaits remaining approvers", status, approverHandle, inviteeHandle)
+		return nil
+	}
 
 	// ✅ If declined, return early
-	if status == "declined" {
-		log.Printf("🚫 Invite declined. No group record created.")
+	if outcome == "declined" {
+		utils.Logf(ctx, "🚫 Invite declined. No group record created.")
 		return nil
 	}
 
-	// ✅ Create separate records for Approver, Inviter, and Invitee
-	members := []string{approverHandle, inviterHandle, inviteeHandle}
+	// ✅ Create separate records for every approver, the Inviter, and the Invitee
+	var members []string
+	for _, member := range append(invite.RequiredApprovers(), inviterHandle, inviteeHandle) {
+		if !contains(members, member) {
+			members = append(members, member)
+		}
+	}
 
 	// ✅ Prepare batch write request
+	now := time.Now()
 	var groupRecords []models.GroupInteraction
 	for _, member := range members {
 		groupRecords = append(groupRecords, models.GroupInteraction{
@@ -189,62 +319,219 @@ func (s *GroupInteractionService) ApproveOrDeclineInvite(ctx context.Context, ap
 			InteractionType: "group_chat",
 			Status:          "active",
 			GroupID:         groupId,
+			GroupName:       invite.GroupName,
 			InviterHandle:   inviterHandle,
-			ApproverHandle:  approverHandle,
+			ApproverHandle:  invite.ApproverHandle,
 			InviteeHandle:   inviteeHandle,
 			Members:         members,
-			CreatedAt:       time.Now(),
-			LastUpdated:     time.Now(),
+			Approvers:       invite.Approvers,
+			ApprovalPolicy:  invite.ApprovalPolicy,
+			Decisions:       invite.Decisions,
+			CreatedAt:       now,
+			LastUpdated:     now,
+			LastActivityAt:  now.UTC().Format(time.RFC3339),
+			ActiveMember:    "USER#" + member,
 		})
 	}
 
-	log.Printf("📌 Creating group records for Approver, Inviter, and Invitee")
+	utils.Logf(ctx, "📌 Creating group records for %d members", len(members))
 	if err := s.createBatchGroupInteractions(ctx, groupRecords); err != nil {
-		log.Printf("❌ Error creating group records: %v", err)
+		utils.Logf(ctx, "❌ Error creating group records: %v", err)
 		return err
 	}
 
-	log.Printf("✅ Successfully processed invite for Approver: %s, Inviter: %s, Invitee: %s with Status: %s", approverHandle, inviterHandle, inviteeHandle, status)
+	s.recordGroupEvent(ctx, *groupId, models.GroupSystemEvent{
+		Type:   models.GroupEventMemberJoined,
+		Actor:  inviterHandle,
+		Target: inviteeHandle,
+	})
+
+	utils.Logf(ctx, "✅ Successfully processed invite for Approver: %s, Inviter: %s, Invitee: %s with Status: %s", approverHandle, inviterHandle, inviteeHandle, outcome)
 	return nil
 }
 
-func (s *GroupInteractionService) GetActiveGroups(ctx context.Context, userHandle string) ([]models.GroupInteraction, error) {
-	log.Printf("🔍 Searching for active groups where user '%s' is a participant", userHandle)
+// ✅ LeaveGroup - Removes the user from the group's member list on every remaining member's record
+func (s *GroupInteractionService) LeaveGroup(ctx context.Context, groupID, userHandle string) error {
+	utils.Logf(ctx, "🔍 LeaveGroup: %s leaving groupId %s", userHandle, groupID)
 
-	// ✅ Query groups for the given user handle
-	keyCondition := "PK = :pk"
-	expressionValues := map[string]types.AttributeValue{
-		":pk": &types.AttributeValueMemberS{Value: "USER#" + userHandle},
+	membership, err := s.getActiveMembership(ctx, groupID, userHandle)
+	if err != nil {
+		return err
 	}
 
-	// 🔍 Query DynamoDB
-	items, err := s.Dynamo.QueryItems(ctx, models.GroupInteractionsTable, keyCondition, expressionValues, nil, 100)
+	var remaining []string
+	for _, member := range membership.Members {
+		if member != userHandle {
+			remaining = append(remaining, member)
+		}
+	}
+	if remaining == nil {
+		remaining = []string{}
+	}
+	membersValue, err := attributevalue.Marshal(remaining)
 	if err != nil {
-		log.Printf("❌ Error querying active groups for user '%s': %v", userHandle, err)
-		return nil, err
+		return err
 	}
 
-	// ✅ Convert to Go struct
-	var allGroups []models.GroupInteraction
-	if err := attributevalue.UnmarshalListOfMaps(items, &allGroups); err != nil {
-		log.Printf("❌ Error unmarshaling groups for '%s': %v", userHandle, err)
-		return nil, err
+	now := &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339Nano)}
+
+	// ✅ The leaver keeps their record for history, marked as left
+	if err := s.updateMemberRecord(ctx, userHandle, groupID, "SET #status = :left, lastUpdated = :now REMOVE activeMember",
+		map[string]types.AttributeValue{":left": &types.AttributeValueMemberS{Value: "left"}, ":now": now},
+		map[string]string{"#status": "status"}); err != nil {
+		utils.Logf(ctx, "❌ Error marking %s as left in groupId %s: %v", userHandle, groupID, err)
+		return err
 	}
 
-	// ✅ Step 3: Filter for active `group_chat` interactions
-	var activeGroups []models.GroupInteraction
-	for _, group := range allGroups {
-		log.Printf("🔍 Checking group %s with status %s", group.GroupID, group.Status)
-
-		// ✅ Directly use `group.Members` (it is already []string)
-		if group.Status == "active" &&
-			group.InteractionType == "group_chat" &&
-			contains(group.Members, userHandle) { // Directly check in []string
-			activeGroups = append(activeGroups, group)
+	for _, member := range remaining {
+		if err := s.updateMemberRecord(ctx, member, groupID, "SET members = :members, lastUpdated = :now",
+			map[string]types.AttributeValue{":members": membersValue, ":now": now}, nil); err != nil {
+			utils.Logf(ctx, "❌ Error updating members of groupId %s for %s: %v", groupID, member, err)
+			return err
+		}
+	}
+
+	s.recordGroupEvent(ctx, groupID, models.GroupSystemEvent{
+		Type:  models.GroupEventMemberLeft,
+		Actor: userHandle,
+	})
+
+	utils.Logf(ctx, "✅ %s left groupId %s (%d members remain)", userHandle, groupID, len(remaining))
+	return nil
+}
+
+// ✅ RenameGroup - Updates the group name on every member's record
+func (s *GroupInteractionService) RenameGroup(ctx context.Context, groupID, userHandle, groupName string) error {
+	utils.Logf(ctx, "🔍 RenameGroup: %s renaming groupId %s to %q", userHandle, groupID, groupName)
+
+	membership, err := s.getActiveMembership(ctx, groupID, userHandle)
+	if err != nil {
+		return err
+	}
+
+	oldName := ""
+	if membership.GroupName != nil {
+		oldName = *membership.GroupName
+	}
+	if oldName == groupName {
+		utils.Logf(ctx, "ℹ️ groupId %s already named %q", groupID, groupName)
+		return nil
+	}
+
+	values := map[string]types.AttributeValue{
+		":name": &types.AttributeValueMemberS{Value: groupName},
+		":now":  &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339Nano)},
+	}
+	for _, member := range membership.Members {
+		if err := s.updateMemberRecord(ctx, member, groupID, "SET groupName = :name, lastUpdated = :now", values, nil); err != nil {
+			utils.Logf(ctx, "❌ Error renaming groupId %s for %s: %v", groupID, member, err)
+			return err
 		}
 	}
 
-	log.Printf("✅ Found %d active groups for user '%s'", len(activeGroups), userHandle)
+	s.recordGroupEvent(ctx, groupID, models.GroupSystemEvent{
+		Type:     models.GroupEventGroupRenamed,
+		Actor:    userHandle,
+		OldValue: oldName,
+		NewValue: groupName,
+	})
+
+	utils.Logf(ctx, "✅ groupId %s renamed to %q", groupID, groupName)
+	return nil
+}
+
+// GetActiveGroups returns one page of the user's active group chats, most recently active first.
+// Pages are read straight from ActiveGroupsIndex.
+func (s *GroupInteractionService) GetActiveGroups(ctx context.Context, userHandle string, limit int, cursor string) ([]models.GroupInteraction, string, error) {
+	utils.Logf(ctx, "🔍 Searching for active groups where user '%s' is a participant", userHandle)
+
+	items, nextCursor, err := s.Dynamo.QueryPage(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.GroupInteractionsTable),
+		IndexName:              aws.String(models.ActiveGroupsIndex),
+		KeyConditionExpression: aws.String("activeMember = :member"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":member": &types.AttributeValueMemberS{Value: "USER#" + userHandle},
+		},
+		ScanIndexForward: aws.Bool(false), // ✅ Most recent activity first
+	}, int32(limit), cursor)
+	if err != nil {
+		utils.Logf(ctx, "❌ Error querying active groups for user '%s': %v", userHandle, err)
+		return nil, "", err
+	}
+
+	activeGroups := []models.GroupInteraction{}
+	if err := attributevalue.UnmarshalListOfMaps(items, &activeGroups); err != nil {
+		return nil, "", err
+	}
+
+	utils.Logf(ctx, "✅ Returning %d active groups for user '%s'", len(activeGroups), userHandle)
+	return activeGroups, nextCursor, nil
+}
+
+// BackfillActiveGroupsIndex adds the ActiveGroupsIndex keys to active membership records written
+// before the index existed, taking lastActivityAt from lastUpdated. Returns how many were updated.
+func (s *GroupInteractionService) BackfillActiveGroupsIndex(ctx context.Context) (int, error) {
+	items, err := s.Dynamo.ScanAll(ctx, models.GroupInteractionsTable)
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for _, item := range items {
+		var record models.GroupInteraction
+		if err := attributevalue.UnmarshalMap(item, &record); err != nil {
+			utils.Logf(ctx, "⚠️ Skipping unreadable group record: %v", err)
+			continue
+		}
+		if record.Status != "active" || record.InteractionType != "group_chat" || record.ActiveMember != "" || record.GroupID == nil {
+			continue
+		}
+		member := strings.TrimPrefix(record.PK, "USER#")
+		activity := record.LastActivityAt
+		if activity == "" {
+			activity = record.LastUpdated.UTC().Format(time.RFC3339)
+		}
+		if err := s.updateMemberRecord(ctx, member, *record.GroupID, "SET activeMember = :member, lastActivityAt = if_not_exists(lastActivityAt, :activity)",
+			map[string]types.AttributeValue{
+				":member":   &types.AttributeValueMemberS{Value: record.PK},
+				":activity": &types.AttributeValueMemberS{Value: activity},
+			}, nil); err != nil {
+			return updated, fmt.Errorf("failed to backfill %s in groupId %s: %w", member, *record.GroupID, err)
+		}
+		updated++
+	}
+
+	utils.Logf(ctx, "✅ Backfilled %d active group records", updated)
+	return updated, nil
+}
+
+// listActiveGroups returns every active group chat the user is a member of
+func (s *GroupInteractionService) listActiveGroups(ctx context.Context, userHandle string) ([]models.GroupInteraction, error) {
+	// ✅ Only membership records (SK = GROUP#...) that are active group chats including the user
+	items, err := s.Dynamo.QueryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.GroupInteractionsTable),
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :groupPrefix)"),
+		FilterExpression:       aws.String("#status = :active AND interactionType = :groupChat AND contains(members, :user)"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":          &types.AttributeValueMemberS{Value: "USER#" + userHandle},
+			":groupPrefix": &types.AttributeValueMemberS{Value: "GROUP#"},
+			":active":      &types.AttributeValueMemberS{Value: "active"},
+			":groupChat":   &types.AttributeValueMemberS{Value: "group_chat"},
+			":user":        &types.AttributeValueMemberS{Value: userHandle},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// ✅ Convert to Go struct
+	var activeGroups []models.GroupInteraction
+	if err := attributevalue.UnmarshalListOfMaps(items, &activeGroups); err != nil {
+		return nil, err
+	}
 	return activeGroups, nil
 }
 
@@ -283,7 +570,7 @@ func (s *GroupInteractionService) getGroupInteraction(ctx context.Context, pk, s
 	}
 
 	if item == nil {
-		return nil, errors.New("group interaction not found")
+		return nil, notFoundError("group interaction not found")
 	}
 
 	var interaction models.GroupInteraction
@@ -294,9 +581,91 @@ func (s *GroupInteractionService) getGroupInteraction(ctx context.Context, pk, s
 	return &interaction, nil
 }
 
-// ✅ updateGroupInteraction - Updates a group interaction in DynamoDB
-func (s *GroupInteractionService) updateGroupInteraction(ctx context.Context, interaction models.GroupInteraction) error {
-	return s.Dynamo.PutItem(ctx, models.GroupInteractionsTable, interaction)
+// recordInviteDecision stores one approver's decision on a still pending invite and returns the
+// invite with every decision recorded so far
+func (s *GroupInteractionService) recordInviteDecision(ctx context.Context, pk, sk, approverHandle, status string) (*models.GroupInteraction, error) {
+	key := map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: pk},
+		"SK": &types.AttributeValueMemberS{Value: sk},
+	}
+
+	// ✅ Decisions are set per approver, so the map has to exist first
+	_, err := s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(models.GroupInteractionsTable),
+		Key:                 key,
+		UpdateExpression:    aws.String("SET decisions = :empty"),
+		ConditionExpression: aws.String("attribute_exists(PK) AND attribute_not_exists(decisions)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":empty": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{}},
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if err != nil && !errors.As(err, &conditionFailed) {
+		return nil, fmt.Errorf("failed to prepare invite decisions: %w", err)
+	}
+
+	output, err := s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(models.GroupInteractionsTable),
+		Key:                 key,
+		UpdateExpression:    aws.String("SET decisions.#approver = :decision, lastUpdated = :now"),
+		ConditionExpression: aws.String("#status = :pending"),
+		ExpressionAttributeNames: map[string]string{
+			"#approver": approverHandle,
+			"#status":   "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":decision": &types.AttributeValueMemberS{Value: status},
+			":pending":  &types.AttributeValueMemberS{Value: "pending"},
+			":now":      &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339Nano)},
+		},
+		ReturnValues: types.ReturnValueAllNew,
+	})
+	if errors.As(err, &conditionFailed) {
+		return nil, ErrInviteResolved
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to record invite decision: %w", err)
+	}
+
+	var invite models.GroupInteraction
+	if err := attributevalue.UnmarshalMap(output.Attributes, &invite); err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+// resolveInvite moves a pending invite to its outcome; false means a concurrent decision already did
+func (s *GroupInteractionService) resolveInvite(ctx context.Context, pk, sk, outcome string, groupId *string) (bool, error) {
+	expression := "SET #status = :outcome, lastUpdated = :now"
+	values := map[string]types.AttributeValue{
+		":outcome": &types.AttributeValueMemberS{Value: outcome},
+		":pending": &types.AttributeValueMemberS{Value: "pending"},
+		":now":     &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339Nano)},
+	}
+	if groupId != nil {
+		expression += ", groupId = :groupId"
+		values[":groupId"] = &types.AttributeValueMemberS{Value: *groupId}
+	}
+
+	_, err := s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(models.GroupInteractionsTable),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: pk},
+			"SK": &types.AttributeValueMemberS{Value: sk},
+		},
+		UpdateExpression:          aws.String(expression),
+		ConditionExpression:       aws.String("#status = :pending"),
+		ExpressionAttributeNames:  map[string]string{"#status": "status"},
+		ExpressionAttributeValues: values,
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve invite: %w", err)
+	}
+	return true, nil
 }
 
 // ✅ createGroupInteractionForInvitee - Adds a new group record for an invitee
@@ -327,6 +696,70 @@ func contains(members []string, userHandle string) bool {
 	return false
 }
 
+// ✅ getActiveMembership - Fetches the user's record for a group, or ErrGroupNotFound if they aren't an active member
+func (s *GroupInteractionService) getActiveMembership(ctx context.Context, groupID, userHandle string) (*models.GroupInteraction, error) {
+	membership, err := s.getGroupInteraction(ctx, "USER#"+userHandle, "GROUP#"+groupID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrGroupNotFound
+		}
+		utils.Logf(ctx, "❌ Error fetching membership of %s in groupId %s: %v", userHandle, groupID, err)
+		return nil, err
+	}
+	if membership.Status != "active" {
+		return nil, ErrGroupNotFound
+	}
+	return membership, nil
+}
+
+// ✅ updateMemberRecord - Applies an update expression to one member's record of a group
+func (s *GroupInteractionService) updateMemberRecord(ctx context.Context, member, groupID, updateExpression string, values map[string]types.AttributeValue, names map[string]string) error {
+	key := map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: "USER#" + member},
+		"SK": &types.AttributeValueMemberS{Value: "GROUP#" + groupID},
+	}
+	_, err := s.Dynamo.UpdateItem(ctx, models.GroupInteractionsTable, updateExpression, key, values, names)
+	return err
+}
+
+// ✅ recordGroupEvent - Adds an activity log entry; the change itself already succeeded, so failures are only logged
+func (s *GroupInteractionService) recordGroupEvent(ctx context.Context, groupID string, event models.GroupSystemEvent) {
+	if s.GroupChat == nil {
+		return
+	}
+	if err := s.GroupChat.RecordSystemEvent(ctx, groupID, event); err != nil {
+		utils.Logf(ctx, "⚠️ Failed to record %s event for groupId %s: %v", event.Type, groupID, err)
+	}
+}
+
+// ✅ syncApprovalPointers - Writes one row per additional approver so the invite shows up in their
+// ApprovalIndex pending list; the row mirrors the invite and carries the approver's own status
+func (s *GroupInteractionService) syncApprovalPointers(ctx context.Context, invite models.GroupInteraction) error {
+	approvers := invite.RequiredApprovers()
+	if len(approvers) < 2 {
+		return nil
+	}
+
+	var pointers []models.GroupInteraction
+	for _, approver := range approvers {
+		if approver == invite.ApproverHandle {
+			continue // ✅ The invite row itself is indexed under the primary approver
+		}
+		status := invite.Status
+		if decision, ok := invite.Decisions[approver]; ok && status == "pending" {
+			status = decision
+		}
+		pointer := invite
+		pointer.PK = "USER#" + approver
+		pointer.SK = models.GroupApprovalSK(invite.InviterHandle, invite.InviteeHandle)
+		pointer.InteractionType = models.InteractionTypeGroupApproval
+		pointer.ApproverHandle = approver
+		pointer.Status = status
+		pointers = append(pointers, pointer)
+	}
+	return s.createBatchGroupInteractions(ctx, pointers)
+}
+
 // ✅ createBatchGroupInteractions - Adds multiple group records in a single batch write
 func (s *GroupInteractionService) createBatchGroupInteractions(ctx context.Context, groupRecords []models.GroupInteraction) error {
 	var writeRequests []types.WriteRequest
@@ -334,7 +767,7 @@ func (s *GroupInteractionService) createBatchGroupInteractions(ctx context.Conte
 	for _, record := range groupRecords {
 		item, err := attributevalue.MarshalMap(record)
 		if err != nil {
-			log.Printf("❌ Error marshalling group interaction record: %v", err)
+			utils.Logf(ctx, "❌ Error marshalling group interaction record: %v", err)
 			return err
 		}
 
@@ -345,10 +778,10 @@ func (s *GroupInteractionService) createBatchGroupInteractions(ctx context.Conte
 
 	err := s.Dynamo.BatchWriteItems(ctx, models.GroupInteractionsTable, writeRequests)
 	if err != nil {
-		log.Printf("❌ Error in batch write: %v", err)
+		utils.Logf(ctx, "❌ Error in batch write: %v", err)
 		return err
 	}
 
-	log.Printf("✅ Successfully inserted %d group records", len(groupRecords))
+	utils.Logf(ctx, "✅ Successfully inserted %d group records", len(groupRecords))
 	return nil
 }
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Group presence is polled: members send a heartbeat (optionally flagged as typing) every few
+// seconds and read back who else is around. Entries outlive their windows only until DynamoDB TTL
+// catches up, so reads filter on the timestamps instead of trusting TTL.
+
+// UpdatePresence records a heartbeat from a member, typing or not, and returns the group's presence
+func (s *GroupChatService) UpdatePresence(ctx context.Context, groupID, userHandle string, typing bool) (*models.GroupPresenceState, error) {
+	if err := s.checkActiveMember(ctx, groupID, userHandle); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	presence := models.GroupPresence{
+		GroupID:    groupID,
+		UserHandle: userHandle,
+		LastSeenAt: now.UnixMilli(),
+		ExpiresAt:  now.Add(models.GroupOnlineWindow).Unix(),
+	}
+	if typing {
+		presence.TypingUntil = now.Add(models.GroupTypingWindow).UnixMilli()
+	}
+	if err := s.Dynamo.PutItem(ctx, models.GroupPresenceTable, presence); err != nil {
+		return nil, fmt.Errorf("failed to store presence of %s in %s: %w", userHandle, groupID, err)
+	}
+
+	return s.presenceState(ctx, groupID, userHandle, now)
+}
+
+// GetPresence returns who else is online and typing in a group the user belongs to
+func (s *GroupChatService) GetPresence(ctx context.Context, groupID, userHandle string) (*models.GroupPresenceState, error) {
+	if err := s.checkActiveMember(ctx, groupID, userHandle); err != nil {
+		return nil, err
+	}
+	return s.presenceState(ctx, groupID, userHandle, time.Now())
+}
+
+// ClearPresence marks the user offline in a group right away (e.g. when the app is backgrounded)
+func (s *GroupChatService) ClearPresence(ctx context.Context, groupID, userHandle string) error {
+	_, err := s.Dynamo.Client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(models.GroupPresenceTable),
+		Key: map[string]types.AttributeValue{
+			"groupId":    &types.AttributeValueMemberS{Value: groupID},
+			"userhandle": &types.AttributeValueMemberS{Value: userHandle},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to clear presence of %s in %s: %w", userHandle, groupID, err)
+	}
+	return nil
+}
+
+// presenceState lists the members other than userHandle whose heartbeats are still live
+func (s *GroupChatService) presenceState(ctx context.Context, groupID, userHandle string, now time.Time) (*models.GroupPresenceState, error) {
+	items, err := s.Dynamo.QueryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.GroupPresenceTable),
+		KeyConditionExpression: aws.String("groupId = :groupId"),
+		FilterExpression:       aws.String("lastSeenAt > :since AND userhandle <> :self"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":groupId": &types.AttributeValueMemberS{Value: groupID},
+			":since":   &types.AttributeValueMemberN{Value: strconv.FormatInt(now.Add(-models.GroupOnlineWindow).UnixMilli(), 10)},
+			":self":    &types.AttributeValueMemberS{Value: userHandle},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query presence of %s: %w", groupID, err)
+	}
+	var entries []models.GroupPresence
+	if err := attributevalue.UnmarshalListOfMaps(items, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse presence: %w", err)
+	}
+
+	state := &models.GroupPresenceState{
+		GroupID:             groupID,
+		Online:              []string{},
+		Typing:              []string{},
+		PollIntervalSeconds: int(models.GroupPresencePollInterval / time.Second),
+	}
+	for _, entry := range entries {
+		state.Online = append(state.Online, entry.UserHandle)
+		if entry.TypingUntil > now.UnixMilli() {
+			state.Typing = append(state.Typing, entry.UserHandle)
+		}
+	}
+	slices.Sort(state.Online)
+	slices.Sort(state.Typing)
+
+	utils.Logf(ctx, "👀 groupId %s: %d online, %d typing", groupID, len(state.Online), len(state.Typing))
+	return state, nil
+}
+
+// checkActiveMember returns ErrGroupNotFound unless the user is an active member of the group
+func (s *GroupChatService) checkActiveMember(ctx context.Context, groupID, userHandle string) error {
+	item, err := s.Dynamo.GetItemAttributes(ctx, models.GroupInteractionsTable, map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: "USER#" + userHandle},
+		"SK": &types.AttributeValueMemberS{Value: "GROUP#" + groupID},
+	}, "status")
+	if errors.Is(err, ErrNotFound) {
+		return ErrGroupNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if status, ok := item["status"].(*types.AttributeValueMemberS); !ok || status.Value != "active" {
+		return ErrGroupNotFound
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// healthCheckTimeout bounds each dependency check so a hung dependency fails readiness quickly
+const healthCheckTimeout = 2 * time.Second
+
+// HealthService checks the dependencies the server needs to take traffic
+type HealthService struct {
+	Dynamo *DynamoService
+	S3     *S3Service
+}
+
+// CheckReadiness runs every dependency check in parallel
+func (s *HealthService) CheckReadiness(ctx context.Context) models.HealthReport {
+	checks := []struct {
+		name  string
+		check func(context.Context) error
+	}{
+		{"dynamodb", s.checkDynamo},
+		{"s3", s.checkS3},
+	}
+
+	results := make([]models.DependencyHealth, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			defer cancel()
+
+			started := time.Now()
+			err := c.check(checkCtx)
+			results[i] = models.DependencyHealth{Name: c.name, Status: models.HealthStatusOK, LatencyMS: time.Since(started).Milliseconds()}
+			if err != nil {
+				utils.Logf(ctx, "⚠️ Readiness check %s failed: %v", c.name, err)
+				results[i].Status, results[i].Error = models.HealthStatusFail, err.Error()
+			}
+		}()
+	}
+	wg.Wait()
+
+	report := models.HealthReport{Status: models.HealthStatusOK, CheckedAt: time.Now().UTC().Format(time.RFC3339), Dependencies: results}
+	for _, result := range results {
+		if result.Status != models.HealthStatusOK {
+			report.Status = models.HealthStatusFail
+		}
+	}
+	return report
+}
+
+// checkDynamo describes the Users table: cheap, and proves credentials, network and table name
+func (s *HealthService) checkDynamo(ctx context.Context) error {
+	output, err := s.Dynamo.Client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(models.UserProfilesTable)})
+	if err != nil {
+		return err
+	}
+	if output.Table == nil || output.Table.TableStatus == "" {
+		return errors.New("table description is empty")
+	}
+	if status := string(output.Table.TableStatus); status != "ACTIVE" && status != "UPDATING" {
+		return errors.New("table " + models.UserProfilesTable + " is " + status)
+	}
+	return nil
+}
+
+// checkS3 confirms the media bucket is configured and reachable with our credentials
+func (s *HealthService) checkS3(ctx context.Context) error {
+	if s.S3 == nil || s.S3.Client == nil || s.S3.Bucket == "" {
+		return errors.New("s3 bucket is not configured")
+	}
+	_, err := s.S3.Client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(s.S3.Bucket)})
+	return err
+}
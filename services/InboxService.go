@@ -0,0 +1,307 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"golang.org/x/sync/errgroup"
+)
+
+// InboxService merges a user's 1:1 matches and group chats into one list ordered by last activity,
+// so clients no longer fetch and interleave the two feeds themselves
+type InboxService struct {
+	Chat               *ChatService
+	GroupChat          *GroupChatService
+	Groups             *GroupInteractionService
+	UserProfileService *UserProfileService
+}
+
+// GetInbox returns one page of the user's conversations, most recently active first. Ordering needs
+// every conversation's last message; profiles and unread counts are only fetched for the page.
+func (s *InboxService) GetInbox(ctx context.Context, userHandle string, limit int, cursor string) ([]models.InboxEntry, string, error) {
+	utils.Logf(ctx, "🔍 Building inbox for user: %s (limit %d)", userHandle, limit)
+
+	after, err := utils.DecodeCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// ✅ Both conversation lists in parallel
+	var matches []models.Interaction
+	var groups []models.GroupInteraction
+	load, loadCtx := errgroup.WithContext(ctx)
+	load.Go(func() (err error) {
+		matches, err = s.Chat.getMatchesForUser(loadCtx, userHandle)
+		return err
+	})
+	load.Go(func() (err error) {
+		groups, err = s.Groups.listActiveGroups(loadCtx, userHandle)
+		return err
+	})
+	if err := load.Wait(); err != nil {
+		utils.Logf(ctx, "❌ Error listing conversations for %s: %v", userHandle, err)
+		return nil, "", err
+	}
+
+	entries := make([]models.InboxEntry, 0, len(matches)+len(groups))
+	for _, match := range matches {
+		entries = append(entries, models.InboxEntry{
+			Kind:           models.InboxKindMatch,
+			ConversationID: *match.MatchID,
+			Participants:   []models.InboxParticipant{{UserHandle: otherParticipant(match, userHandle)}},
+			LastActivityAt: activityTime(match.ActivityAt()),
+		})
+	}
+	for _, group := range groups {
+		if group.GroupID == nil {
+			continue
+		}
+		entry := models.InboxEntry{
+			Kind:           models.InboxKindGroup,
+			ConversationID: *group.GroupID,
+			Title:          derefString(group.GroupName),
+			Participants:   []models.InboxParticipant{},
+			LastActivityAt: group.LastUpdated.UTC().Format(time.RFC3339),
+		}
+		for _, member := range group.Members {
+			if member != userHandle {
+				entry.Participants = append(entry.Participants, models.InboxParticipant{UserHandle: member})
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	s.attachLastMessages(ctx, entries)
+
+	// ✅ Most recent activity first; conversationId breaks ties so pages are stable
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].LastActivityAt != entries[j].LastActivityAt {
+			return entries[i].LastActivityAt > entries[j].LastActivityAt
+		}
+		return entries[i].ConversationID < entries[j].ConversationID
+	})
+
+	// ✅ Skip everything up to and including the cursor position
+	start := 0
+	if after != nil {
+		var position struct {
+			LastActivityAt string `dynamodbav:"lastActivityAt"`
+			ConversationID string `dynamodbav:"conversationId"`
+		}
+		if err := attributevalue.UnmarshalMap(after, &position); err != nil {
+			return nil, "", utils.ErrInvalidCursor
+		}
+		start = sort.Search(len(entries), func(i int) bool {
+			if entries[i].LastActivityAt != position.LastActivityAt {
+				return entries[i].LastActivityAt < position.LastActivityAt
+			}
+			return entries[i].ConversationID > position.ConversationID
+		})
+	}
+
+	end := min(start+limit, len(entries))
+	page := entries[start:end]
+
+	nextCursor := ""
+	if end < len(entries) {
+		last := page[len(page)-1]
+		nextCursor, err = utils.EncodeCursor(map[string]types.AttributeValue{
+			"lastActivityAt": &types.AttributeValueMemberS{Value: last.LastActivityAt},
+			"conversationId": &types.AttributeValueMemberS{Value: last.ConversationID},
+		})
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	s.attachParticipants(ctx, userHandle, page)
+	s.attachUnreadCounts(ctx, userHandle, page)
+
+	failed := 0
+	for _, entry := range page {
+		if entry.EnrichmentError {
+			failed++
+		}
+	}
+	recordEnrichment("inbox", len(page), failed)
+	utils.Logf(ctx, "✅ Returning %d of %d inbox conversations for %s", len(page), len(entries), userHandle)
+	return page, nextCursor, nil
+}
+
+// attachLastMessages sets each entry's last message and moves LastActivityAt up to it. Matches read the
+// stream-maintained summaries first; a failed lookup degrades that entry only.
+func (s *InboxService) attachLastMessages(ctx context.Context, entries []models.InboxEntry) {
+	var matchIDs []string
+	for _, entry := range entries {
+		if entry.Kind == models.InboxKindMatch {
+			matchIDs = append(matchIDs, entry.ConversationID)
+		}
+	}
+	summaries := s.Chat.getSummaries(ctx, matchIDs)
+
+	// 🔍 Each goroutine writes only its own entry
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(lastMessageFetchConcurrency)
+	for i := range entries {
+		entry := &entries[i]
+		if summary, ok := summaries[entry.ConversationID]; ok && entry.Kind == models.InboxKindMatch {
+			if summary.LastMessage != nil {
+				lastMessage := *summary.LastMessage
+				s.Chat.decryptMessage(ctx, &lastMessage)
+				setLastMessage(entry, matchMessagePreview(lastMessage))
+			}
+			continue
+		}
+		group.Go(func() error {
+			var preview *models.InboxLastMessage
+			var err error
+			if entry.Kind == models.InboxKindMatch {
+				var lastMessage *models.Message
+				if lastMessage, err = s.Chat.GetLastMessageByMatchID(groupCtx, entry.ConversationID); lastMessage != nil {
+					preview = matchMessagePreview(*lastMessage)
+				}
+			} else {
+				var lastMessage *models.GroupMessage
+				if lastMessage, err = s.GroupChat.GetLastMessageByGroupID(groupCtx, entry.ConversationID); lastMessage != nil {
+					preview = groupMessagePreview(*lastMessage)
+				}
+			}
+			if err != nil {
+				utils.Logf(ctx, "⚠️ Error fetching last message for %s %s: %v", entry.Kind, entry.ConversationID, err)
+				markEnrichmentFailed(entry, models.EnrichmentReasonLastMessageFailed)
+				return nil // Degrade this entry only
+			}
+			if preview != nil {
+				setLastMessage(entry, preview)
+			}
+			return nil
+		})
+	}
+	group.Wait()
+}
+
+// attachParticipants fills in participant names and photos from one batch profile fetch covering
+// every entry; a match's title is the matched user's name
+func (s *InboxService) attachParticipants(ctx context.Context, userHandle string, entries []models.InboxEntry) {
+	seen := map[string]bool{}
+	var handles []string
+	for i := range entries {
+		entry := &entries[i]
+		entry.ParticipantCount = len(entry.Participants)
+		entry.Participants = entry.Participants[:min(len(entry.Participants), models.MaxInboxParticipantPreviews)]
+		for _, participant := range entry.Participants {
+			if !seen[participant.UserHandle] {
+				seen[participant.UserHandle] = true
+				handles = append(handles, participant.UserHandle)
+			}
+		}
+	}
+	if len(handles) == 0 {
+		return
+	}
+
+	// A failed lookup degrades to handle-only previews instead of failing the inbox
+	missingReason := models.EnrichmentReasonProfileNotFound
+	profiles, err := s.UserProfileService.GetUserProfilesByHandles(ctx, handles)
+	if err != nil {
+		utils.Logf(ctx, "❌ Error fetching inbox profiles for %s, returning handles only: %v", userHandle, err)
+		profiles = map[string]*models.UserProfile{}
+		missingReason = models.EnrichmentReasonProfileFetchFailed
+	}
+
+	for i := range entries {
+		entry := &entries[i]
+		for j := range entry.Participants {
+			participant := &entry.Participants[j]
+			profile, ok := profiles[participant.UserHandle]
+			if !ok {
+				// ✅ Departed group members are expected to be missing; a missing match is not
+				if entry.Kind == models.InboxKindMatch || missingReason == models.EnrichmentReasonProfileFetchFailed {
+					markEnrichmentFailed(entry, missingReason)
+				}
+				continue
+			}
+			participant.Name = profile.Name
+			if len(profile.Photos) > 0 {
+				participant.Photo = profile.Photos[0]
+			}
+		}
+		if entry.Kind == models.InboxKindMatch && len(entry.Participants) > 0 {
+			entry.Title = entry.Participants[0].Name
+		}
+	}
+}
+
+// attachUnreadCounts sets how many messages the user hasn't read in each entry
+func (s *InboxService) attachUnreadCounts(ctx context.Context, userHandle string, entries []models.InboxEntry) {
+	byKind := map[string][]int{} // kind → entry indexes
+	for i, entry := range entries {
+		byKind[entry.Kind] = append(byKind[entry.Kind], i)
+	}
+
+	for kind, indexes := range byKind {
+		ids := make([]string, len(indexes))
+		for i, index := range indexes {
+			ids[i] = entries[index].ConversationID
+		}
+		var counts []int
+		var err error
+		if kind == models.InboxKindMatch {
+			counts, err = s.Chat.countUnread(ctx, userHandle, ids)
+		} else {
+			counts, err = s.GroupChat.countUnread(ctx, userHandle, ids)
+		}
+		if err != nil {
+			utils.Logf(ctx, "⚠️ Error counting unread %s messages for %s: %v", kind, userHandle, err)
+			for _, index := range indexes {
+				markEnrichmentFailed(&entries[index], models.EnrichmentReasonUnreadCountFailed)
+			}
+			continue
+		}
+		for i, index := range indexes {
+			entries[index].UnreadCount = counts[i]
+		}
+	}
+}
+
+// matchMessagePreview condenses a 1:1 message for the inbox
+func matchMessagePreview(message models.Message) *models.InboxLastMessage {
+	return &models.InboxLastMessage{
+		SenderHandle: message.SenderID,
+		Content:      message.Content,
+		HasImage:     message.ImageURL != "",
+		CreatedAt:    message.CreatedAt,
+	}
+}
+
+// groupMessagePreview condenses a group message for the inbox
+func groupMessagePreview(message models.GroupMessage) *models.InboxLastMessage {
+	return &models.InboxLastMessage{
+		SenderHandle: message.SenderID,
+		Content:      message.Content,
+		HasImage:     message.ImageURL != nil && *message.ImageURL != "",
+		System:       message.MessageType == models.GroupMessageTypeSystem,
+		CreatedAt:    message.CreatedAt,
+	}
+}
+
+// setLastMessage records an entry's last message, moving its activity time up to it
+func setLastMessage(entry *models.InboxEntry, preview *models.InboxLastMessage) {
+	entry.LastMessage = preview
+	if latest := activityTime(preview.CreatedAt); latest > entry.LastActivityAt {
+		entry.LastActivityAt = latest
+	}
+}
+
+// markEnrichmentFailed flags a partial entry, keeping the first failure's retry hint
+func markEnrichmentFailed(entry *models.InboxEntry, reason string) {
+	if !entry.EnrichmentError {
+		entry.EnrichmentError = true
+		entry.RetryHint = enrichmentRetryHint(reason)
+	}
+}
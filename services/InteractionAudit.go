@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+)
+
+// recordTransition appends a status change of sender's interaction with receiver to the audit log.
+// Unchanged statuses are skipped; failures are logged and never fail the interaction.
+func (s *InteractionService) recordTransition(ctx context.Context, sender, receiver, interactionType, fromStatus, toStatus string, matchID *string, cause models.InteractionCause) {
+	if fromStatus == toStatus {
+		return
+	}
+	now := time.Now().UTC()
+	eventID := uuid.New().String()
+	entry := models.InteractionAuditEntry{
+		PairKey:         models.AuditPairKey(sender, receiver),
+		SK:              now.Format(time.RFC3339Nano) + "#" + eventID,
+		EventID:         eventID,
+		SenderHandle:    sender,
+		ReceiverHandle:  receiver,
+		InteractionType: interactionType,
+		Actor:           cause.Actor,
+		FromStatus:      fromStatus,
+		ToStatus:        toStatus,
+		Reason:          cause.Reason,
+		MatchID:         derefString(matchID),
+		At:              now.Format(time.RFC3339),
+		ExpiresAt:       now.AddDate(0, 0, models.InteractionAuditRetentionDays).Unix(),
+	}
+	item, err := attributevalue.MarshalMap(entry)
+	if err == nil {
+		// ✅ Append only: an entry is never overwritten
+		_, err = s.Dynamo.Client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName:           aws.String(models.InteractionAuditTable),
+			Item:                item,
+			ConditionExpression: aws.String("attribute_not_exists(pairKey)"),
+		})
+	}
+	if err != nil {
+		utils.Logf(ctx, "⚠️ Failed to audit %s -> %s (%s -> %s): %v", sender, receiver, fromStatus, toStatus, err)
+	}
+}
+
+// GetInteractionAudit returns one page of the status changes between two users, oldest first
+func (s *InteractionService) GetInteractionAudit(ctx context.Context, userA, userB string, limit int32, cursor string) ([]models.InteractionAuditEntry, string, error) {
+	items, nextCursor, err := s.Dynamo.QueryPage(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.InteractionAuditTable),
+		KeyConditionExpression: aws.String("pairKey = :pair"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pair": &types.AttributeValueMemberS{Value: models.AuditPairKey(userA, userB)},
+		},
+	}, limit, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	entries := []models.InteractionAuditEntry{}
+	if err := attributevalue.UnmarshalListOfMaps(items, &entries); err != nil {
+		return nil, "", fmt.Errorf("failed to parse interaction audit: %w", err)
+	}
+	return entries, nextCursor, nil
+}
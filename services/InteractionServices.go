@@ -2,28 +2,60 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"vibin_server/models"
+	"vibin_server/utils"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
 )
 
+// lastMessageFetchConcurrency bounds parallel per-match last-message lookups
+const lastMessageFetchConcurrency = 10
+
 // InteractionService handles interactions (like, ping, and matches)
 type InteractionService struct {
 	Dynamo             *DynamoService
 	UserProfileService *UserProfileService
 	ChatService        *ChatService
+	PhotoInsights      *PhotoInsightsService
+	Billing            *BillingService // ✅ Premium gates: who liked you, unlimited likes, rewind
+	Analytics          *AnalyticsService
+	Webhooks           *WebhookService
+	Blocks             *BlockService
+	Jobs               *JobQueue // ✅ Set by RegisterJobs; photo insights and opening messages are written inline without it
+	Retention          models.RetentionPolicy
 }
 
+// ErrLikeLimitReached is returned when a free user has used up today's likes
+var ErrLikeLimitReached = errors.New("like_limit_reached")
+
+// ErrNothingToRewind is returned when the user has no dislike to undo
+var ErrNothingToRewind = notFoundError("nothing_to_rewind")
+
+// ErrReceiverPaused is returned when liking or pinging a user who paused their account
+var ErrReceiverPaused = conflictError("user_is_paused")
+
+// ErrReceiverUnavailable is returned when liking or pinging a user whose account is awaiting
+// deletion or age restricted, or who blocked the sender (or was blocked by them)
+var ErrReceiverUnavailable = notFoundError("user_not_found")
+
 // GetInteraction retrieves an interaction between two users
 func (s *InteractionService) GetInteraction(ctx context.Context, sender, receiver string) (*models.Interaction, error) {
-	log.Printf("🔍 Checking if interaction exists: %s -> %s", sender, receiver)
+	utils.Logf(ctx, "🔍 Checking if interaction exists: %s -> %s", sender, receiver)
 
 	key := map[string]types.AttributeValue{
 		"PK": &types.AttributeValueMemberS{Value: "USER#" + sender},
@@ -32,23 +64,23 @@ func (s *InteractionService) GetInteraction(ctx context.Context, sender, receive
 
 	item, err := s.Dynamo.GetItem(ctx, models.InteractionsTable, key)
 	if err != nil {
-		if strings.Contains(err.Error(), "item not found") {
-			log.Printf("ℹ️ No previous interaction found for %s -> %s. Proceeding to create a new one.", sender, receiver)
+		if errors.Is(err, ErrNotFound) {
+			utils.Logf(ctx, "ℹ️ No previous interaction found for %s -> %s. Proceeding to create a new one.", sender, receiver)
 			return nil, nil // ✅ This is expected; allow creation of a new interaction
 		}
-		log.Printf("❌ Unexpected DynamoDB error while fetching interaction: %v", err)
+		utils.Logf(ctx, "❌ Unexpected DynamoDB error while fetching interaction: %v", err)
 		return nil, err
 	}
 
 	if item == nil {
-		log.Printf("ℹ️ No interaction record exists for %s -> %s. Creating a new one.", sender, receiver)
+		utils.Logf(ctx, "ℹ️ No interaction record exists for %s -> %s. Creating a new one.", sender, receiver)
 		return nil, nil
 	}
 
 	var interaction models.Interaction
 	err = attributevalue.UnmarshalMap(item, &interaction)
 	if err != nil {
-		log.Printf("❌ Error unmarshalling interaction: %v", err)
+		utils.Logf(ctx, "❌ Error unmarshalling interaction: %v", err)
 		return nil, err
 	}
 
@@ -56,32 +88,89 @@ func (s *InteractionService) GetInteraction(ctx context.Context, sender, receive
 }
 
 func (s *InteractionService) CreateOrUpdateInteraction(
-	ctx context.Context, sender, receiver, interactionType, action string, message *string) (bool, *models.MatchedUserDetails, error) {
+	ctx context.Context, sender, receiver, interactionType, action string, message *string, photoIndex *int) (bool, *models.MatchedUserDetails, error) {
+
+	utils.Logf(ctx, "🔄 Processing %s from %s -> %s", interactionType, sender, receiver)
 
-	log.Printf("🔄 Processing %s from %s -> %s", interactionType, sender, receiver)
+	// ✅ Suspended and banned users can't like, ping or pass
+	if err := s.UserProfileService.CheckStanding(ctx, sender); err != nil {
+		return false, nil, err
+	}
+
+	// ✅ Photo position is only kept for swipes, and only when the sender allows analytics
+	if photoIndex != nil && (action != "like" && action != "dislike" || !s.UserProfileService.AllowsProcessing(ctx, sender, models.PurposeAnalytics)) {
+		photoIndex = nil
+	}
+
+	// ✅ Paused users keep their matches but receive no new likes or pings; hidden users receive none at all
+	if action == "like" || action == "ping" {
+		paused, hidden := s.UserProfileService.ReceiverState(ctx, receiver)
+		if hidden || s.Blocks.IsBlocked(ctx, sender, receiver) {
+			return false, nil, ErrReceiverUnavailable
+		}
+		if paused {
+			return false, nil, ErrReceiverPaused
+		}
+	}
+
+	// ✅ A shadowbanned sender's likes and pings are stored as usual, so nothing looks different to
+	// them, but they never match and receivers don't see them (see receivedWithProfiles)
+	shadowed := (action == "like" || action == "ping") && s.UserProfileService.IsShadowbanned(ctx, sender)
 
 	// Check if an existing interaction exists
 	existingInteraction, err := s.GetInteraction(ctx, sender, receiver)
 	if err != nil {
-		log.Printf("⚠️ Error fetching interaction: %v", err)
+		utils.Logf(ctx, "⚠️ Error fetching interaction: %v", err)
 		return false, nil, err
 	}
 
+	// ✅ Saving ("maybe later") never replaces a like, ping or match
+	if action == "save" {
+		if existingInteraction != nil && existingInteraction.InteractionType != models.InteractionTypeSave &&
+			(existingInteraction.Status != models.StatusDeclined || existingInteraction.MatchID != nil) {
+			return false, nil, conflictError("you already interacted with this user")
+		}
+		interactionType = models.InteractionTypeSave
+	}
+
+	// ✅ New likes count against the free daily limit (re-liking someone already liked doesn't)
+	if action == "like" && (existingInteraction == nil || existingInteraction.Status == models.StatusDeclined || existingInteraction.InteractionType == models.InteractionTypeSave) {
+		if err := s.checkLikeLimit(ctx, sender); err != nil {
+			return false, nil, err
+		}
+	}
+
+	// ✅ Pings are rate limited: a cooldown after the recipient declined, then a daily quota
+	if action == "ping" {
+		if err := checkPingCooldown(existingInteraction, time.Now()); err != nil {
+			return false, nil, err
+		}
+		if err := s.reservePing(ctx, sender); err != nil {
+			return false, nil, err
+		}
+	}
+
 	var newStatus string
 	var matchID *string
 	isMatch := false // Default value
+	cause := models.InteractionCause{Actor: sender, Reason: action}
 	var matchedUser *models.MatchedUserDetails
 
 	switch action {
 	case "like":
 		newStatus = "pending"
 
-		// ✅ Check if it's a mutual match
-		isMatch, err = s.CheckMutualMatch(ctx, sender, receiver)
-		log.Printf("⚠️ isMatch fetching interaction: %t", isMatch)
+		// ✅ Check if it's a mutual match (never with a shadowbanned user, on either side)
+		if !shadowed {
+			isMatch, err = s.CheckMutualMatch(ctx, sender, receiver)
+			utils.Logf(ctx, "⚠️ isMatch fetching interaction: %t", isMatch)
 
-		if err != nil {
-			return false, nil, err
+			if err != nil {
+				return false, nil, err
+			}
+			if isMatch && s.UserProfileService.IsShadowbanned(ctx, receiver) {
+				isMatch = false
+			}
 		}
 
 		// ✅ If mutual match, update status
@@ -92,30 +181,13 @@ func (s *InteractionService) CreateOrUpdateInteraction(
 				return false, nil, err
 			}
 
-			// ✅ Fetch receiver's profile
-			profile, err := s.UserProfileService.GetUserProfileByHandle(ctx, receiver)
-			if err != nil {
-				log.Printf("⚠️ Failed to fetch user profile for %s: %v", receiver, err)
-			} else {
-				log.Printf("✅ Fetched profile for %s: Name=%s, Photos=%v", receiver, profile.Name, profile.Photos)
-
-				photo := ""
-				if len(profile.Photos) > 0 {
-					photo = profile.Photos[0]
-				}
-
-				matchedUser = &models.MatchedUserDetails{
-					Name:       profile.Name,
-					UserHandle: receiver,
-					Photo:      photo,
-					MatchID:    *matchID,
-				}
-				log.Printf("✅ MatchedUserDetails created: %+v", matchedUser)
-			}
+			matchedUser = s.matchedUserDetails(ctx, sender, receiver, *matchID)
 		}
 
 	case "dislike":
 		newStatus = "declined"
+	case "save":
+		newStatus = models.StatusSaved
 	case "ping":
 		newStatus = "pending"
 	case "approve":
@@ -124,25 +196,7 @@ func (s *InteractionService) CreateOrUpdateInteraction(
 		generatedMatchID := uuid.New().String()
 		matchID = &generatedMatchID
 
-		// ✅ Fetch receiver's profile
-		profile, err := s.UserProfileService.GetUserProfileByHandle(ctx, receiver)
-		if err != nil {
-			log.Printf("⚠️ Failed to fetch user profile for %s: %v", receiver, err)
-		} else {
-			log.Printf("✅ Fetched profile for %s: Name=%s, Photos=%v", receiver, profile.Name, profile.Photos)
-
-			photo := ""
-			if len(profile.Photos) > 0 {
-				photo = profile.Photos[0]
-			}
-
-			matchedUser = &models.MatchedUserDetails{
-				Name:       profile.Name,
-				UserHandle: receiver,
-				Photo:      photo,
-				MatchID:    *matchID,
-			}
-		}
+		matchedUser = s.matchedUserDetails(ctx, sender, receiver, *matchID)
 	case "reject":
 		newStatus = "rejected"
 	default:
@@ -151,26 +205,108 @@ func (s *InteractionService) CreateOrUpdateInteraction(
 
 	// ✅ If the interaction does not exist, create it
 	if existingInteraction == nil {
-		log.Printf("🆕 No existing interaction found. Creating a new interaction for %s -> %s", sender, receiver)
-		err := s.CreateInteraction(ctx, sender, receiver, interactionType, newStatus, matchID, message)
+		utils.Logf(ctx, "🆕 No existing interaction found. Creating a new interaction for %s -> %s", sender, receiver)
+		err := s.CreateInteraction(ctx, sender, receiver, interactionType, newStatus, cause, matchID, message, photoIndex)
 		if err != nil {
-			log.Printf("❌ Failed to create interaction: %v", err)
+			utils.Logf(ctx, "❌ Failed to create interaction: %v", err)
+			if action == "ping" {
+				s.releasePing(ctx, sender)
+			}
 			return false, nil, err
 		}
-		log.Println("✅ New interaction successfully created.")
+		utils.Logln(ctx, "✅ New interaction successfully created.")
+		if !shadowed {
+			s.recordPhotoSwipe(ctx, receiver, photoIndex, action)
+		}
+		s.trackInteraction(ctx, sender, receiver, action, isMatch, matchID)
 		return isMatch, matchedUser, nil
 	}
 
-	// ✅ Otherwise, update existing interaction
-	err = s.UpdateInteractionStatus(ctx, sender, receiver, newStatus, matchID, message, nil)
+	// ✅ Otherwise, update existing interaction (acting on a saved profile replaces the save)
+	var typeUpdate *string
+	if action == "save" || existingInteraction.InteractionType == models.InteractionTypeSave {
+		typeUpdate = &interactionType
+	}
+	err = s.UpdateInteractionStatus(ctx, sender, receiver, newStatus, cause, matchID, message, typeUpdate, photoIndex)
 	if err != nil {
+		if action == "ping" {
+			s.releasePing(ctx, sender)
+		}
 		return false, nil, err
 	}
 
+	// ✅ Disliking a match unmatches the pair; their conversation expires under the retention policy
+	if action == "dislike" && existingInteraction.Status == models.StatusMatch && existingInteraction.MatchID != nil {
+		if err := s.ChatService.ExpireConversation(ctx, *existingInteraction.MatchID); err != nil {
+			utils.Logf(ctx, "⚠️ Failed to set retention on conversation %s: %v", *existingInteraction.MatchID, err)
+		}
+	}
+
+	if !shadowed {
+		s.recordPhotoSwipe(ctx, receiver, photoIndex, action)
+	}
+	s.trackInteraction(ctx, sender, receiver, action, isMatch, matchID)
 	return isMatch, matchedUser, nil
 }
+
+// trackInteraction records the swipe, ping and match funnel events for a stored interaction and
+// publishes match.created
+func (s *InteractionService) trackInteraction(ctx context.Context, sender, receiver, action string, isMatch bool, matchID *string) {
+	switch action {
+	case "like", "dislike", "save":
+		s.Analytics.Track(ctx, models.EventSwipe, sender, map[string]string{"action": action})
+	case "ping":
+		s.Analytics.Track(ctx, models.EventPingSent, sender, nil)
+	}
+	if isMatch && matchID != nil {
+		s.Analytics.Track(ctx, models.EventMatch, sender, map[string]string{"matchId": *matchID})
+		s.Webhooks.Publish(ctx, models.WebhookMatchCreated, map[string]interface{}{
+			"matchId":     *matchID,
+			"userhandles": []string{sender, receiver},
+		})
+	}
+}
+
+// recordPhotoSwipe feeds per-photo insights; failures are logged and never fail the swipe
+func (s *InteractionService) recordPhotoSwipe(ctx context.Context, receiver string, photoIndex *int, action string) {
+	if photoIndex == nil || s.PhotoInsights == nil {
+		return
+	}
+	if s.Jobs != nil {
+		s.Jobs.Enqueue(ctx, models.JobPhotoSwipe, models.PhotoSwipeJob{UserHandle: receiver, PhotoIndex: *photoIndex, Liked: action == "like", SwipeID: uuid.New().String()})
+		return
+	}
+	if err := s.PhotoInsights.RecordSwipe(ctx, receiver, *photoIndex, action == "like", ""); err != nil {
+		utils.Logf(ctx, "⚠️ Failed to record photo swipe for %s: %v", receiver, err)
+	}
+}
+
+// RegisterJobs moves photo-swipe insights and new matches' opening messages onto the job queue
+func (s *InteractionService) RegisterJobs(queue *JobQueue) {
+	s.Jobs = queue
+	queue.Handle(models.JobPhotoSwipe, func(ctx context.Context, payload json.RawMessage) error {
+		job, err := decodeJob[models.PhotoSwipeJob](payload)
+		if err != nil {
+			return err
+		}
+		return s.PhotoInsights.RecordSwipe(ctx, job.UserHandle, job.PhotoIndex, job.Liked, job.SwipeID)
+	})
+	queue.Handle(models.JobInitialMessage, func(ctx context.Context, payload json.RawMessage) error {
+		job, err := decodeJob[models.InitialMessageJob](payload)
+		if err != nil {
+			return err
+		}
+		return s.sendInitialMessage(ctx, job)
+	})
+}
+
 func (s *InteractionService) HandlePingApproval(ctx context.Context, sender, receiver string) error {
-	log.Printf("✅ Handling Ping Approval: %s -> %s", sender, receiver)
+	utils.Logf(ctx, "✅ Handling Ping Approval: %s -> %s", sender, receiver)
+
+	// ✅ Approving starts a conversation, which a restricted receiver can't do
+	if err := s.UserProfileService.CheckStanding(ctx, receiver); err != nil {
+		return err
+	}
 
 	// ✅ Generate a Match ID
 	matchID := uuid.New().String()
@@ -178,7 +314,7 @@ func (s *InteractionService) HandlePingApproval(ctx context.Context, sender, rec
 	// ✅ Fetch existing interaction for sender → receiver
 	interactionData, err := s.GetInteraction(ctx, sender, receiver)
 	if err != nil {
-		log.Printf("❌ Failed to fetch sender interaction: %v", err)
+		utils.Logf(ctx, "❌ Failed to fetch sender interaction: %v", err)
 		return err
 	}
 
@@ -190,39 +326,40 @@ func (s *InteractionService) HandlePingApproval(ctx context.Context, sender, rec
 			message = *interactionData.Message
 		}
 	} else {
-		log.Printf("⚠️ No existing interactionType found for %s -> %s", sender, receiver)
+		utils.Logf(ctx, "⚠️ No existing interactionType found for %s -> %s", sender, receiver)
 		return fmt.Errorf("missing interactionType in sender's record")
 	}
 	// ✅ Update sender → receiver
-	err = s.UpdateInteractionStatus(ctx, sender, receiver, "match", &matchID, &message, nil)
+	approved := models.InteractionCause{Actor: receiver, Reason: models.AuditReasonPingApproved}
+	err = s.UpdateInteractionStatus(ctx, sender, receiver, "match", approved, &matchID, &message, nil, nil)
 	if err != nil {
-		log.Printf("❌ Failed to approve ping: %v", err)
+		utils.Logf(ctx, "❌ Failed to approve ping: %v", err)
 		return err
 	}
 	// #[TODO] we need create for sender -> reciever instead of create
 	// ✅ Update receiver → sender (Now with `interactionType` and `message`)
-	err = s.UpdateInteractionStatus(ctx, receiver, sender, "match", &matchID, &message, &interactionType)
+	err = s.UpdateInteractionStatus(ctx, receiver, sender, "match", approved, &matchID, &message, &interactionType, nil)
 	if err != nil {
-		log.Printf("⚠️ Failed to update reverse ping status: %v", err)
+		utils.Logf(ctx, "⚠️ Failed to update reverse ping status: %v", err)
 	}
 
 	// ✅ Send an initial message (with original ping content)
 	err = s.CreateInitialMessage(ctx, sender, receiver, matchID, true)
 	if err != nil {
-		log.Printf("⚠️ Failed to send initial message: %v", err)
+		utils.Logf(ctx, "⚠️ Failed to send initial message: %v", err)
 	}
 
-	log.Printf("✅ Ping Approved: %s <-> %s", sender, receiver)
+	utils.Logf(ctx, "✅ Ping Approved: %s <-> %s", sender, receiver)
 	return nil
 }
 
 func (s *InteractionService) HandlePingDecline(ctx context.Context, sender, receiver string) error {
-	log.Printf("🚫 Handling Ping Decline: %s -> %s", sender, receiver)
+	utils.Logf(ctx, "🚫 Handling Ping Decline: %s -> %s", sender, receiver)
 
 	// ✅ Fetch the existing interaction to get `interactionType`
 	interactionData, err := s.GetInteraction(ctx, sender, receiver)
 	if err != nil {
-		log.Printf("❌ Failed to fetch sender interaction: %v", err)
+		utils.Logf(ctx, "❌ Failed to fetch sender interaction: %v", err)
 		return err
 	}
 
@@ -231,39 +368,40 @@ func (s *InteractionService) HandlePingDecline(ctx context.Context, sender, rece
 	if interactionData != nil && interactionData.InteractionType != "" {
 		interactionType = &interactionData.InteractionType
 	} else {
-		log.Printf("⚠️ No interactionType found for %s -> %s", sender, receiver)
+		utils.Logf(ctx, "⚠️ No interactionType found for %s -> %s", sender, receiver)
 	}
 
 	// ✅ Update sender → receiver status to "declined"
-	err = s.UpdateInteractionStatus(ctx, sender, receiver, "declined", nil, nil, nil)
+	declined := models.InteractionCause{Actor: receiver, Reason: models.AuditReasonPingDeclined}
+	err = s.UpdateInteractionStatus(ctx, sender, receiver, "declined", declined, nil, nil, nil, nil)
 	if err != nil {
-		log.Printf("❌ Failed to decline ping: %v", err)
+		utils.Logf(ctx, "❌ Failed to decline ping: %v", err)
 		return err
 	}
 
 	// ✅ Update receiver → sender status to "declined" (Now with `interactionType`)
-	err = s.UpdateInteractionStatus(ctx, receiver, sender, "declined", nil, nil, interactionType)
+	err = s.UpdateInteractionStatus(ctx, receiver, sender, "declined", declined, nil, nil, interactionType, nil)
 	if err != nil {
-		log.Printf("⚠️ Failed to update reverse ping status: %v", err)
+		utils.Logf(ctx, "⚠️ Failed to update reverse ping status: %v", err)
 	}
 
-	log.Printf("✅ Ping Declined: %s -> %s", sender, receiver)
+	utils.Logf(ctx, "✅ Ping Declined: %s -> %s", sender, receiver)
 	return nil
 }
 
 func (s *InteractionService) CheckMutualMatch(ctx context.Context, sender, receiver string) (bool, error) {
-	log.Printf("🔍 Checking for mutual match: %s <-> %s", sender, receiver)
+	utils.Logf(ctx, "🔍 Checking for mutual match: %s <-> %s", sender, receiver)
 
 	// Fetch existing interaction (if any) where receiver liked sender
 	mutualLike, err := s.GetInteraction(ctx, receiver, sender)
 	if err != nil {
-		log.Printf("❌ Error fetching interaction for mutual match check: %v", err)
+		utils.Logf(ctx, "❌ Error fetching interaction for mutual match check: %v", err)
 		return false, err
 	}
 
 	// ✅ If the receiver also liked the sender, it's a mutual match
 	if mutualLike != nil && mutualLike.Status == "pending" {
-		log.Printf("🔥 Mutual Match Found! %s <-> %s", sender, receiver)
+		utils.Logf(ctx, "🔥 Mutual Match Found! %s <-> %s", sender, receiver)
 		return true, nil
 	}
 
@@ -271,63 +409,89 @@ func (s *InteractionService) CheckMutualMatch(ctx context.Context, sender, recei
 	return false, nil
 }
 func (s *InteractionService) HandleMutualMatch(ctx context.Context, sender, receiver string) (*string, error) {
-	log.Printf("🔄 Handling mutual match update for: %s <-> %s", sender, receiver)
+	utils.Logf(ctx, "🔄 Handling mutual match update for: %s <-> %s", sender, receiver)
 
 	// Generate a match ID
 	matchID := uuid.New().String()
 
 	// ✅ Update UserB -> UserA interaction to "match"
-	err := s.UpdateInteractionStatus(ctx, receiver, sender, "match", &matchID, nil, nil)
+	err := s.UpdateInteractionStatus(ctx, receiver, sender, "match", models.InteractionCause{Actor: sender, Reason: models.AuditReasonMutualLike}, &matchID, nil, nil, nil)
 	if err != nil {
-		log.Printf("❌ Failed to update mutual match for %s -> %s: %v", receiver, sender, err)
+		utils.Logf(ctx, "❌ Failed to update mutual match for %s -> %s: %v", receiver, sender, err)
 		return nil, err
 	}
 
 	// ✅ Create an initial message (default congratulatory message)
 	err = s.CreateInitialMessage(ctx, sender, receiver, matchID, false)
 	if err != nil {
-		log.Printf("⚠️ Failed to send initial message for match %s: %v", matchID, err)
+		utils.Logf(ctx, "⚠️ Failed to send initial message for match %s: %v", matchID, err)
 	}
 
 	return &matchID, nil
 }
 
+// CreateInitialMessage opens a new match's conversation (with the ping's text for an approved ping).
+// With a job queue the message is posted in the background, off the swipe's request path.
 func (s *InteractionService) CreateInitialMessage(ctx context.Context, sender, receiver, matchID string, isPing bool) error {
-	log.Printf("💬 Creating initial message for matchId: %s between %s & %s", matchID, sender, receiver)
+	job := models.InitialMessageJob{
+		MatchID:   matchID,
+		Sender:    sender,
+		Receiver:  receiver,
+		IsPing:    isPing,
+		MessageID: uuid.New().String(),
+		CreatedAt: time.Now().Format(time.RFC3339),
+	}
+	if s.Jobs != nil {
+		s.Jobs.Enqueue(ctx, models.JobInitialMessage, job)
+		return nil
+	}
+	return s.sendInitialMessage(ctx, job)
+}
 
-	// Determine message content and sender
-	var content string
-	var originalSender string
+// sendInitialMessage posts the opening message unless a previous run of the job already stored it
+func (s *InteractionService) sendInitialMessage(ctx context.Context, job models.InitialMessageJob) error {
+	utils.Logf(ctx, "💬 Creating initial message for matchId: %s between %s & %s", job.MatchID, job.Sender, job.Receiver)
+
+	_, err := s.Dynamo.GetItemAttributes(ctx, models.MessagesTable, map[string]types.AttributeValue{
+		"matchId":   &types.AttributeValueMemberS{Value: job.MatchID},
+		"createdAt": &types.AttributeValueMemberS{Value: job.CreatedAt},
+	}, "messageId")
+	if err == nil {
+		utils.Logf(ctx, "ℹ️ Initial message for matchId %s already sent", job.MatchID)
+		return nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return err
+	}
 
-	if isPing {
+	// Determine message content
+	var content string
+	if job.IsPing {
 		// ✅ Fetch the original ping interaction to get the message content
-		originalInteraction, err := s.GetInteraction(ctx, sender, receiver)
+		originalInteraction, err := s.GetInteraction(ctx, job.Sender, job.Receiver)
 		if err != nil {
-			log.Printf("❌ Failed to fetch original ping interaction: %v", err)
+			utils.Logf(ctx, "❌ Failed to fetch original ping interaction: %v", err)
 			return err
 		}
 
 		if originalInteraction == nil || originalInteraction.Message == nil {
-			log.Printf("⚠️ No original ping message found, using default content")
+			utils.Logf(ctx, "⚠️ No original ping message found, using default content")
 			content = "Hey! I sent you a ping. Let's connect! 😊"
 		} else {
 			content = *originalInteraction.Message // ✅ Use original ping message
 		}
-
-		originalSender = sender // ✅ Keep the original sender
 	} else {
 		// ✅ Default message for mutual like
 		content = "MATCH_BOT"
-		originalSender = sender // 🔹 Empty string to indicate a system message
 	}
 
 	// ✅ Define the first message
 	initialMessage := models.Message{
-		MatchID:   matchID,
-		MessageID: uuid.New().String(),
-		SenderID:  originalSender, // ✅ Keep the original sender
+		MatchID:   job.MatchID,
+		MessageID: job.MessageID,
+		SenderID:  job.Sender, // ✅ Keep the original sender
 		Content:   content,
-		CreatedAt: time.Now().Format(time.RFC3339), // Store timestamp
+		CreatedAt: job.CreatedAt,
 		Liked:     false,
 	}
 
@@ -335,21 +499,20 @@ func (s *InteractionService) CreateInitialMessage(ctx context.Context, sender, r
 	initialMessage.SetIsUnread(true)
 
 	// ✅ Send message using ChatService
-	err := s.ChatService.SendMessage(ctx, initialMessage)
-	if err != nil {
-		log.Printf("❌ Failed to send initial message: %v", err)
+	if err := s.ChatService.SendMessage(ctx, initialMessage); err != nil {
+		utils.Logf(ctx, "❌ Failed to send initial message: %v", err)
 		return err
 	}
 
-	log.Printf("✅ Initial message sent successfully for matchId: %s", matchID)
+	utils.Logf(ctx, "✅ Initial message sent successfully for matchId: %s", job.MatchID)
 	return nil
 }
 
 // CreateInteraction inserts a new interaction into DynamoDB
-func (s *InteractionService) CreateInteraction(ctx context.Context, sender, receiver, interactionType, status string, matchID *string, message *string) error {
-	log.Printf("🆕 Creating a new interaction for %s -> %s", sender, receiver)
+func (s *InteractionService) CreateInteraction(ctx context.Context, sender, receiver, interactionType, status string, cause models.InteractionCause, matchID *string, message *string, photoIndex *int) error {
+	utils.Logf(ctx, "🆕 Creating a new interaction for %s -> %s", sender, receiver)
 
-	now := time.Now().Format(time.RFC3339)
+	now := time.Now()
 	interaction := models.Interaction{
 		PK:              "USER#" + sender,
 		SK:              "INTERACTION#" + receiver,
@@ -359,23 +522,38 @@ func (s *InteractionService) CreateInteraction(ctx context.Context, sender, rece
 		Status:          status,
 		MatchID:         matchID,
 		Message:         message,
-		CreatedAt:       now,
-		LastUpdated:     now,
+		PhotoIndex:      photoIndex,
+		CreatedAt:       now.Format(time.RFC3339),
+		LastUpdated:     now.Format(time.RFC3339),
+		ExpiresAt:       s.declinedExpiry(now, status),
+	}
+	if status == models.StatusMatch {
+		interaction.LastActivityAt = now.UTC().Format(time.RFC3339)
 	}
 
-	log.Printf("📥 Saving new interaction: %+v", interaction)
+	utils.Logf(ctx, "📥 Saving new interaction: %+v", interaction)
 	err := s.Dynamo.PutItem(ctx, models.InteractionsTable, interaction)
 	if err != nil {
-		log.Printf("❌ Error inserting interaction: %v", err)
+		utils.Logf(ctx, "❌ Error inserting interaction: %v", err)
 		return fmt.Errorf("failed to create interaction: %w", err)
 	}
-	log.Println("✅ Interaction successfully created.")
+	s.recordTransition(ctx, sender, receiver, interactionType, "", status, matchID, cause)
+	utils.Logln(ctx, "✅ Interaction successfully created.")
 	return nil
 }
 
+// declinedExpiry is the TTL for an interaction with the given status: set for declined and rejected
+// interactions when their retention is enabled, 0 otherwise
+func (s *InteractionService) declinedExpiry(now time.Time, status string) int64 {
+	if status != models.StatusDeclined && status != models.StatusRejected {
+		return 0
+	}
+	return s.Retention.ExpiresAt(now, s.Retention.DeclinedInteractionDays)
+}
+
 // UpdateInteractionStatus updates the status of an existing interaction and ensures all fields are properly set
-func (s *InteractionService) UpdateInteractionStatus(ctx context.Context, sender, receiver, newStatus string, matchID, message, interactionType *string) error {
-	log.Printf("🔄 Updating interaction %s -> %s to status: %s", sender, receiver, newStatus)
+func (s *InteractionService) UpdateInteractionStatus(ctx context.Context, sender, receiver, newStatus string, cause models.InteractionCause, matchID, message, interactionType *string, photoIndex *int) error {
+	utils.Logf(ctx, "🔄 Updating interaction %s -> %s to status: %s", sender, receiver, newStatus)
 
 	updateExpression := "SET #status = :status, #lastUpdated = :lastUpdated, #senderHandle = :sender, #receiverHandle = :receiver"
 	expressionValues := map[string]types.AttributeValue{
@@ -412,116 +590,379 @@ func (s *InteractionService) UpdateInteractionStatus(ctx context.Context, sender
 		expressionNames["#interactionType"] = "interactionType"
 	}
 
+	// Add photoIndex if provided
+	if photoIndex != nil {
+		updateExpression += ", #photoIndex = :photoIndex"
+		expressionValues[":photoIndex"] = &types.AttributeValueMemberN{Value: strconv.Itoa(*photoIndex)}
+		expressionNames["#photoIndex"] = "photoIndex"
+	}
+
+	// ✅ Only match records carry lastActivityAt, which keeps MatchActivityIndex down to matches
+	var removes []string
+	if newStatus == models.StatusMatch {
+		updateExpression += ", #lastActivityAt = if_not_exists(#lastActivityAt, :lastActivityAt)"
+		expressionValues[":lastActivityAt"] = &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)}
+	} else {
+		removes = append(removes, "#lastActivityAt")
+	}
+	expressionNames["#lastActivityAt"] = "lastActivityAt"
+
+	// ✅ Declined interactions expire under the retention policy; any other status keeps them
+	if expiresAt := s.declinedExpiry(time.Now(), newStatus); expiresAt != 0 {
+		updateExpression += ", #expiresAt = :expiresAt"
+		expressionValues[":expiresAt"] = &types.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt, 10)}
+	} else {
+		removes = append(removes, "#expiresAt")
+	}
+	expressionNames["#expiresAt"] = "expiresAt"
+	if len(removes) > 0 {
+		updateExpression += " REMOVE " + strings.Join(removes, ", ")
+	}
+
 	// Define key for update
 	key := map[string]types.AttributeValue{
 		"PK": &types.AttributeValueMemberS{Value: "USER#" + sender},
 		"SK": &types.AttributeValueMemberS{Value: "INTERACTION#" + receiver},
 	}
 
-	// Execute update
-	_, err := s.Dynamo.UpdateItem(ctx, models.InteractionsTable, updateExpression, key, expressionValues, expressionNames)
+	// Execute update (the old item feeds the audit log)
+	output, err := s.Dynamo.updateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(models.InteractionsTable),
+		Key:                       key,
+		UpdateExpression:          aws.String(updateExpression),
+		ExpressionAttributeValues: expressionValues,
+		ExpressionAttributeNames:  expressionNames,
+		ReturnValues:              types.ReturnValueAllOld,
+	})
 	if err != nil {
-		log.Printf("❌ Error updating interaction status: %v", err)
-		return err
+		utils.Logf(ctx, "❌ Error updating interaction status: %v", err)
+		return fmt.Errorf("update error: %w", classifyDynamoError(err))
 	}
 
-	log.Println("✅ Interaction status successfully updated.")
+	var previous models.Interaction
+	if err := attributevalue.UnmarshalMap(output.Attributes, &previous); err != nil {
+		utils.Logf(ctx, "⚠️ Failed to parse previous interaction %s -> %s: %v", sender, receiver, err)
+	}
+	if interactionType != nil {
+		previous.InteractionType = *interactionType
+	}
+	if matchID == nil {
+		matchID = previous.MatchID
+	}
+	s.recordTransition(ctx, sender, receiver, previous.InteractionType, previous.Status, newStatus, matchID, cause)
+
+	utils.Logln(ctx, "✅ Interaction status successfully updated.")
 	return nil
 }
 
-func (s *InteractionService) GetMutualMatches(ctx context.Context, userHandle string) ([]models.MatchedUserDetailsForConnections, error) {
-	log.Printf("🔍 Fetching mutual matches for user: %s", userHandle)
-
-	// Define the Global Secondary Index (GSI) for querying matches
-	indexName := "status-index" // Ensure this is correctly configured in DynamoDB
-	keyCondition := "#PK = :user AND #status = :matchStatus"
+// matchedUserDetails describes the receiver of a new match to the sender; nil when the receiver's
+// profile can't be fetched
+func (s *InteractionService) matchedUserDetails(ctx context.Context, sender, receiver, matchID string) *models.MatchedUserDetails {
+	// ✅ Fetch both profiles: the receiver's for the card, the sender's for shared interests
+	profiles, err := s.UserProfileService.GetUserProfilesByHandles(ctx, []string{sender, receiver})
+	if err != nil {
+		utils.Logf(ctx, "⚠️ Failed to fetch user profile for %s: %v", receiver, err)
+		return nil
+	}
+	profile, ok := profiles[receiver]
+	if !ok {
+		utils.Logf(ctx, "⚠️ Failed to fetch user profile for %s: profile not found", receiver)
+		return nil
+	}
+	utils.Logf(ctx, "✅ Fetched profile for %s: Name=%s, Photos=%v", receiver, profile.Name, profile.Photos)
 
-	expressionValues := map[string]types.AttributeValue{
-		":user":        &types.AttributeValueMemberS{Value: "USER#" + userHandle},
-		":matchStatus": &types.AttributeValueMemberS{Value: "match"},
+	photo := ""
+	if len(profile.Photos) > 0 {
+		photo = profile.Photos[0]
 	}
 
-	expressionNames := map[string]string{
-		"#PK":     "PK",
-		"#status": "status",
+	matchedUser := &models.MatchedUserDetails{
+		Name:            profile.Name,
+		UserHandle:      receiver,
+		Photo:           photo,
+		MatchID:         matchID,
+		Prompts:         profile.Prompts,
+		SharedInterests: sharedInterests(profiles[sender], profile),
 	}
+	utils.Logf(ctx, "✅ MatchedUserDetails created: %+v", matchedUser)
+	return matchedUser
+}
 
-	// 🔍 Query DynamoDB for mutual matches
-	items, err := s.Dynamo.QueryItemsWithIndex(ctx, models.InteractionsTable, indexName, keyCondition, expressionValues, expressionNames, 100)
+// GetMutualMatches returns one page of matches for a user, most recently active first; pass the returned
+// cursor to fetch the next page. With unreadFirst, matches whose conversation summary shows unread
+// messages move ahead of the rest within the page.
+func (s *InteractionService) GetMutualMatches(ctx context.Context, userHandle string, unreadFirst bool, limit int32, cursor string) ([]models.MatchedUserDetailsForConnections, string, error) {
+	utils.Logf(ctx, "🔍 Fetching mutual matches for user: %s (limit %d, unreadFirst %t)", userHandle, limit, unreadFirst)
+
+	// 🔍 One page of match records from the activity index, newest first
+	items, nextCursor, err := s.Dynamo.QueryPage(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.InteractionsTable),
+		IndexName:              aws.String(models.MatchActivityIndex),
+		KeyConditionExpression: aws.String("#PK = :user"),
+		ExpressionAttributeNames: map[string]string{
+			"#PK": "PK",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":user": &types.AttributeValueMemberS{Value: "USER#" + userHandle},
+		},
+		ScanIndexForward: aws.Bool(false),
+	}, limit, cursor)
 	if err != nil {
-		log.Printf("❌ Error fetching mutual matches from DynamoDB: %v", err)
-		return nil, fmt.Errorf("failed to fetch matches: %w", err)
+		utils.Logf(ctx, "❌ Error fetching mutual matches from DynamoDB: %v", err)
+		return nil, "", fmt.Errorf("failed to fetch matches: %w", err)
 	}
 
-	if len(items) == 0 {
-		log.Printf("⚠️ No mutual matches found for user: %s", userHandle)
-		return []models.MatchedUserDetailsForConnections{}, nil
+	var interactions []models.Interaction
+	for _, item := range items {
+		var interaction models.Interaction
+		if err := attributevalue.UnmarshalMap(item, &interaction); err != nil || interaction.MatchID == nil {
+			continue
+		}
+		interactions = append(interactions, interaction)
+	}
+	if len(interactions) == 0 {
+		utils.Logf(ctx, "⚠️ No mutual matches found for user: %s", userHandle)
+		return []models.MatchedUserDetailsForConnections{}, nextCursor, nil
 	}
 
-	var matchesWithDetails []models.MatchedUserDetailsForConnections
+	matchIDs := make([]string, len(interactions))
+	for i, interaction := range interactions {
+		matchIDs[i] = *interaction.MatchID
+	}
+	// ✅ Stream-maintained summaries give last messages and unread counts without reading messages
+	summaries := s.ChatService.getSummaries(ctx, matchIDs)
+	if unreadFirst {
+		sort.SliceStable(interactions, func(i, j int) bool {
+			return hasUnread(summaries, *interactions[i].MatchID, userHandle) && !hasUnread(summaries, *interactions[j].MatchID, userHandle)
+		})
+	}
+
+	matchedHandles := make([]string, len(interactions))
+	for i, interaction := range interactions {
+		matchedHandles[i] = otherParticipant(interaction, userHandle)
+	}
+
+	// 🔍 Batch fetch profiles for every matched user
+	// A failed lookup degrades to placeholder entries instead of failing the whole list
+	missingReason := models.EnrichmentReasonProfileNotFound
+	profiles, err := s.UserProfileService.GetUserProfilesByHandles(ctx, append(matchedHandles, userHandle)) // ✅ Own profile for shared interests
+	if err != nil {
+		utils.Logf(ctx, "❌ Error fetching matched profiles, returning placeholders: %v", err)
+		profiles = map[string]*models.UserProfile{}
+		missingReason = models.EnrichmentReasonProfileFetchFailed
+	}
 
 	// Process each interaction record
-	for _, item := range items {
-		var interaction models.Interaction
-		err := attributevalue.UnmarshalMap(item, &interaction)
-		if err != nil {
-			log.Printf("⚠️ Skipping item due to unmarshalling error: %v", err)
-			continue
+	matchesWithDetails := make([]models.MatchedUserDetailsForConnections, len(interactions))
+	for i, interaction := range interactions {
+		matchedUserHandle := otherParticipant(interaction, userHandle)
+
+		match := models.MatchedUserDetailsForConnections{
+			UserHandle:        matchedUserHandle,
+			MatchID:           *interaction.MatchID,
+			LastMessageIsRead: true,
+			LastActivityAt:    activityTime(interaction.ActivityAt()),
 		}
 
-		// Determine which handle to fetch profile for
-		matchedUserHandle := interaction.ReceiverHandle
-		if matchedUserHandle == userHandle {
-			matchedUserHandle = interaction.SenderHandle // Reverse if needed
+		if profile, ok := profiles[matchedUserHandle]; ok {
+			match.Name = profile.Name
+			if len(profile.Photos) > 0 {
+				match.Photo = profile.Photos[0]
+			}
+			match.Prompts = profile.Prompts
+			match.Spotify = profile.Spotify
+			match.SharedInterests = sharedInterests(profiles[userHandle], profile)
+		} else {
+			utils.Logf(ctx, "⚠️ Profile unavailable for %s (%s)", matchedUserHandle, missingReason)
+			match.EnrichmentError = true
+			match.RetryHint = enrichmentRetryHint(missingReason)
 		}
 
-		// 🔍 Fetch user profile for the matched user
-		profile, err := s.UserProfileService.GetUserProfileByHandle(ctx, matchedUserHandle)
-		if err != nil {
-			log.Printf("⚠️ Failed to fetch profile for %s: %v", matchedUserHandle, err)
+		matchesWithDetails[i] = match
+	}
+
+	// 🔍 Last messages from the summaries where available; the rest are fetched concurrently, each
+	// goroutine writing only its own slot
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(lastMessageFetchConcurrency)
+	for i := range matchesWithDetails {
+		match := &matchesWithDetails[i]
+		if summary, ok := summaries[match.MatchID]; ok {
+			if summary.LastMessage != nil {
+				lastMessage := *summary.LastMessage
+				s.ChatService.decryptMessage(ctx, &lastMessage)
+				match.LastMessage = lastMessage.Content
+				match.LastMessageSender = lastMessage.SenderID
+				match.LastMessageIsRead = !lastMessage.IsUnreadBool()
+			}
 			continue
 		}
+		group.Go(func() error {
+			lastMessage, err := s.ChatService.GetLastMessageByMatchID(groupCtx, match.MatchID)
+			if err != nil {
+				utils.Logf(ctx, "⚠️ Error fetching last message for matchId: %s: %v", match.MatchID, err)
+				if !match.EnrichmentError {
+					match.EnrichmentError = true
+					match.RetryHint = enrichmentRetryHint(models.EnrichmentReasonLastMessageFailed)
+				}
+				return nil // Degrade this entry only
+			}
+
+			if lastMessage != nil {
+				match.LastMessage = lastMessage.Content
+				match.LastMessageSender = lastMessage.SenderID
+				match.LastMessageIsRead = lastMessage.IsUnread == "false"
+			}
+			return nil
+		})
+	}
+	group.Wait()
 
-		photo := ""
-		if profile.Photos != nil && len(profile.Photos) > 0 {
-			photo = profile.Photos[0]
+	failed := 0
+	for _, match := range matchesWithDetails {
+		if match.EnrichmentError {
+			failed++
 		}
+	}
+
+	recordEnrichment("matches", len(matchesWithDetails), failed)
+	utils.Logf(ctx, "✅ Found %d mutual matches with last messages for %s", len(matchesWithDetails), userHandle)
+	return matchesWithDetails, nextCursor, nil
+}
 
-		// 🔍 Fetch last message for the match
-		lastMessage, err := s.ChatService.GetLastMessageByMatchID(ctx, *interaction.MatchID)
+// GetMatchDetail assembles one match for its participant userHandle: the other user's profile, pinned
+// messages, unread count and conversation settings. Users outside the match get ErrMatchNotFound.
+func (s *InteractionService) GetMatchDetail(ctx context.Context, matchID, userHandle string) (*models.MatchDetail, error) {
+	utils.Logf(ctx, "🔍 Fetching match %s for %s", matchID, userHandle)
+
+	match, err := findMatchRecord(ctx, s.Dynamo, matchID, userHandle)
+	if err != nil {
+		return nil, err
+	}
+	partner := otherParticipant(*match, userHandle)
+	detail := &models.MatchDetail{
+		MatchID:        matchID,
+		UserHandle:     userHandle,
+		MatchedAt:      match.LastUpdated,
+		LastActivityAt: activityTime(match.ActivityAt()),
+	}
+
+	// 🔍 Independent lookups in parallel; each writes only its own fields
+	var profiles map[string]*models.UserProfile
+	var profileErr error
+	var partnerSettings *models.ConversationSettings
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.Go(func() error {
+		// ✅ Own profile for shared interests; a failure degrades to a placeholder below
+		profiles, profileErr = s.UserProfileService.GetUserProfilesByHandles(groupCtx, []string{userHandle, partner})
+		return nil
+	})
+	group.Go(func() (err error) {
+		detail.PinnedMessages, err = s.ChatService.GetPinnedMessages(groupCtx, matchID)
+		return err
+	})
+	group.Go(func() error {
+		counts, err := s.ChatService.countUnread(groupCtx, userHandle, []string{matchID})
 		if err != nil {
-			log.Printf("⚠️ Error fetching last message for matchId: %s: %v", *interaction.MatchID, err)
+			return err
 		}
+		detail.UnreadCount = counts[0]
+		return nil
+	})
+	group.Go(func() (err error) {
+		detail.Settings, err = s.ChatService.GetConversationSettings(groupCtx, matchID, userHandle)
+		return err
+	})
+	group.Go(func() (err error) {
+		partnerSettings, err = s.ChatService.GetConversationSettings(groupCtx, matchID, partner)
+		return err
+	})
+	if err := group.Wait(); err != nil {
+		utils.Logf(ctx, "❌ Error fetching match %s: %v", matchID, err)
+		return nil, err
+	}
+	detail.CanSendMedia = !partnerSettings.TextOnly
+
+	failed := 0
+	missingReason := models.EnrichmentReasonProfileNotFound
+	if profileErr != nil {
+		utils.Logf(ctx, "❌ Error fetching profile of %s, returning a placeholder: %v", partner, profileErr)
+		missingReason = models.EnrichmentReasonProfileFetchFailed
+	}
+	if profile, ok := profiles[partner]; ok {
+		s.UserProfileService.hidePrivateFields(profile)
+		profile.EmailID, profile.PhoneNumber = "", "" // ✅ Contact details are shared in the chat, not by the server
+		profile.Contacts = nil
+		profile.RefreshAge(time.Now())
+		profile.SharedInterests = sharedInterests(profiles[userHandle], profile)
+		detail.Participant = profile
+	} else {
+		utils.Logf(ctx, "⚠️ Profile unavailable for %s (%s)", partner, missingReason)
+		detail.EnrichmentError = true
+		detail.RetryHint = enrichmentRetryHint(missingReason)
+		failed++
+	}
 
-		// Default values for last message fields
-		lastMessageText := ""
-		lastMessageSender := ""
-		lastMessageIsRead := true
+	recordEnrichment("match_detail", 1, failed)
+	utils.Logf(ctx, "✅ Match %s assembled for %s", matchID, userHandle)
+	return detail, nil
+}
 
-		if lastMessage != nil {
-			lastMessageText = lastMessage.Content
-			lastMessageSender = lastMessage.SenderID
-			lastMessageIsRead = lastMessage.IsUnread == "false"
-		}
+// BackfillMatchActivityIndex gives match records written before MatchActivityIndex existed their
+// lastActivityAt (from lastUpdated) and drops it from records that are no longer matches. Returns how
+// many records were updated.
+func (s *InteractionService) BackfillMatchActivityIndex(ctx context.Context) (int, error) {
+	items, err := s.Dynamo.ScanAll(ctx, models.InteractionsTable)
+	if err != nil {
+		return 0, err
+	}
 
-		// ✅ Append to results with all details
-		matchesWithDetails = append(matchesWithDetails, models.MatchedUserDetailsForConnections{
-			Name:              profile.Name,
-			UserHandle:        profile.UserHandle,
-			MatchID:           *interaction.MatchID,
-			Photo:             photo,
-			LastMessage:       lastMessageText,
-			LastMessageSender: lastMessageSender,
-			LastMessageIsRead: lastMessageIsRead,
-		})
+	updated := 0
+	for _, item := range items {
+		var interaction models.Interaction
+		if err := attributevalue.UnmarshalMap(item, &interaction); err != nil {
+			utils.Logf(ctx, "⚠️ Skipping unreadable interaction: %v", err)
+			continue
+		}
+		key := map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: interaction.PK},
+			"SK": &types.AttributeValueMemberS{Value: interaction.SK},
+		}
+		isMatch := interaction.Status == models.StatusMatch && interaction.MatchID != nil
+		switch {
+		case isMatch && interaction.LastActivityAt == "":
+			activity := activityTime(interaction.LastUpdated)
+			if activity == "" {
+				activity = time.Now().UTC().Format(time.RFC3339) // ✅ Index keys can't be empty
+			}
+			_, err = s.Dynamo.UpdateItem(ctx, models.InteractionsTable, "SET lastActivityAt = if_not_exists(lastActivityAt, :activity)", key,
+				map[string]types.AttributeValue{
+					":activity": &types.AttributeValueMemberS{Value: activity},
+				}, nil)
+		case !isMatch && interaction.LastActivityAt != "":
+			_, err = s.Dynamo.UpdateItem(ctx, models.InteractionsTable, "REMOVE lastActivityAt", key, nil, nil)
+		default:
+			continue
+		}
+		if err != nil {
+			return updated, fmt.Errorf("failed to backfill %s %s: %w", interaction.PK, interaction.SK, err)
+		}
+		updated++
 	}
 
-	log.Printf("✅ Found %d mutual matches with last messages for %s", len(matchesWithDetails), userHandle)
-	return matchesWithDetails, nil
+	utils.Logf(ctx, "✅ Backfilled %d interaction records", updated)
+	return updated, nil
+}
+
+// hasUnread reports whether the summary of matchID shows unread messages for userHandle; matches the
+// stream has not summarized yet count as read
+func hasUnread(summaries map[string]*models.ConversationSummary, matchID, userHandle string) bool {
+	summary, ok := summaries[matchID]
+	return ok && summary.UnreadFor(userHandle) > 0
 }
 
 func (s *InteractionService) GetInteractedUsers(ctx context.Context, userHandle string, interactionTypes []string) ([]string, error) {
-	log.Printf("🔍 Fetching interacted users for: %s with types: %v", userHandle, interactionTypes)
+	utils.Logf(ctx, "🔍 Fetching interacted users for: %s with types: %v", userHandle, interactionTypes)
 
 	// ✅ Ensure the correct GSI name is used
 	indexName := models.InteractionTypeIndex
@@ -544,7 +985,7 @@ func (s *InteractionService) GetInteractedUsers(ctx context.Context, userHandle
 		// ✅ Use "OR" alternative: Query multiple times if needed
 		var interactedUsers []string
 		for _, interactionType := range interactionTypes {
-			log.Printf("🔄 Querying for interaction type: %s", interactionType)
+			utils.Logf(ctx, "🔄 Querying for interaction type: %s", interactionType)
 
 			tempExpressionValues := map[string]types.AttributeValue{
 				":userHandle":      expressionValues[":userHandle"],
@@ -557,7 +998,7 @@ func (s *InteractionService) GetInteractedUsers(ctx context.Context, userHandle
 				tempExpressionValues, expressionNames, 50,
 			)
 			if err != nil {
-				log.Printf("❌ Error querying interactionType '%s': %v", interactionType, err)
+				utils.Logf(ctx, "❌ Error querying interactionType '%s': %v", interactionType, err)
 				continue // Skip this type but continue others
 			}
 
@@ -568,15 +1009,15 @@ func (s *InteractionService) GetInteractedUsers(ctx context.Context, userHandle
 				}
 			}
 		}
-		log.Printf("✅ Total Interacted Users Found: %d", len(interactedUsers))
+		utils.Logf(ctx, "✅ Total Interacted Users Found: %d", len(interactedUsers))
 		return interactedUsers, nil
 	}
 
 	// ✅ Query with the correct key conditions
-	log.Printf("🔍 Querying GSI '%s' with condition: %s", indexName, keyConditions[0])
+	utils.Logf(ctx, "🔍 Querying GSI '%s' with condition: %s", indexName, keyConditions[0])
 	items, err := s.Dynamo.QueryItemsWithIndex(ctx, models.InteractionsTable, indexName, keyConditions[0], expressionValues, expressionNames, 50)
 	if err != nil {
-		log.Printf("❌ Error querying interacted users: %v", err)
+		utils.Logf(ctx, "❌ Error querying interacted users: %v", err)
 		return nil, fmt.Errorf("failed to fetch interacted users: %w", err)
 	}
 
@@ -589,38 +1030,233 @@ func (s *InteractionService) GetInteractedUsers(ctx context.Context, userHandle
 		}
 	}
 
-	log.Printf("✅ Found %d interacted users for %s", len(users), userHandle)
+	utils.Logf(ctx, "✅ Found %d interacted users for %s", len(users), userHandle)
 	return users, nil
 }
 
-func (s *InteractionService) GetUserInteractions(ctx context.Context, userHandle string) ([]models.InteractionWithProfile, error) {
-	log.Printf("🔍 Fetching interactions SENT by user: %s", userHandle)
+// GetUserInteractions fetches one page of interactions SENT by a user, narrowed by filter; pass the
+// returned cursor back for the next page. Filtered pages are filled as fillPage describes.
+func (s *InteractionService) GetUserInteractions(ctx context.Context, userHandle string, filter models.InteractionFilter, limit int32, cursor string) ([]models.InteractionWithProfile, string, error) {
+	return fillPage(limit, cursor, func(limit int32, cursor string) ([]models.InteractionWithProfile, string, error) {
+		return s.userInteractionsPage(ctx, userHandle, filter, limit, cursor)
+	})
+}
+
+// userInteractionsPage reads one query page of sent interactions
+func (s *InteractionService) userInteractionsPage(ctx context.Context, userHandle string, filter models.InteractionFilter, limit int32, cursor string) ([]models.InteractionWithProfile, string, error) {
+	utils.Logf(ctx, "🔍 Fetching interactions SENT by user: %s (types=%v, statuses=%v)", userHandle, filter.Types, filter.Statuses)
 
 	keyCondition := "PK = :user"
 	expressionValues := map[string]types.AttributeValue{
 		":user": &types.AttributeValueMemberS{Value: "USER#" + userHandle},
 	}
+	expressionNames := map[string]string{}
+	filterExpression := interactionFilterExpression(filter, nil, expressionNames, expressionValues)
+
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(models.InteractionsTable),
+		KeyConditionExpression:    aws.String(keyCondition),
+		ExpressionAttributeValues: expressionValues,
+	}
+	if len(filterExpression) > 0 {
+		input.FilterExpression = aws.String(strings.Join(filterExpression, " AND "))
+	}
+	if len(expressionNames) > 0 {
+		input.ExpressionAttributeNames = expressionNames
+	}
+	items, nextCursor, err := s.Dynamo.QueryPage(ctx, input, limit, cursor)
+	if err != nil {
+		utils.Logf(ctx, "❌ Error querying interactions: %v", err)
+		return nil, "", err
+	}
+
+	interactionsWithProfiles := s.sentWithProfiles(ctx, unmarshalInteractions(items), "sent_interactions")
+	utils.Logf(ctx, "✅ Found %d interactions sent by %s", len(interactionsWithProfiles), userHandle)
+	return interactionsWithProfiles, nextCursor, nil
+}
+
+// GetPassedProfiles fetches one page of the profiles the user disliked ("passed"), while their
+// declined interactions are retained; unmatches and pings are not included. Pages are filled as
+// fillPage describes.
+func (s *InteractionService) GetPassedProfiles(ctx context.Context, userHandle string, limit int32, cursor string) ([]models.InteractionWithProfile, string, error) {
+	return fillPage(limit, cursor, func(limit int32, cursor string) ([]models.InteractionWithProfile, string, error) {
+		return s.passedProfilesPage(ctx, userHandle, limit, cursor)
+	})
+}
+
+// passedProfilesPage reads one query page of passed profiles
+func (s *InteractionService) passedProfilesPage(ctx context.Context, userHandle string, limit int32, cursor string) ([]models.InteractionWithProfile, string, error) {
+	items, nextCursor, err := s.Dynamo.QueryPage(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.InteractionsTable),
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :prefix)"),
+		FilterExpression:       aws.String("#status = :declined AND interactionType <> :ping AND attribute_not_exists(matchId)"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":       &types.AttributeValueMemberS{Value: "USER#" + userHandle},
+			":prefix":   &types.AttributeValueMemberS{Value: "INTERACTION#"},
+			":declined": &types.AttributeValueMemberS{Value: models.StatusDeclined},
+			":ping":     &types.AttributeValueMemberS{Value: models.InteractionTypePing},
+		},
+	}, limit, cursor)
+	if err != nil {
+		utils.Logf(ctx, "❌ Error querying passed profiles: %v", err)
+		return nil, "", err
+	}
+
+	// ✅ Blocked and reported users can't be reconsidered
+	blocked, err := s.Blocks.BlockedHandles(ctx, userHandle)
+	if err != nil {
+		utils.Logf(ctx, "❌ Error fetching blocks: %v", err)
+		return nil, "", err
+	}
+	interactions := slices.DeleteFunc(unmarshalInteractions(items), func(interaction models.Interaction) bool {
+		return blocked[interaction.ReceiverHandle]
+	})
+
+	interactionsWithProfiles := s.sentWithProfiles(ctx, interactions, "passed_profiles")
+	if interactionsWithProfiles == nil {
+		interactionsWithProfiles = []models.InteractionWithProfile{}
+	}
+	utils.Logf(ctx, "✅ Found %d passed profiles for %s", len(interactionsWithProfiles), userHandle)
+	return interactionsWithProfiles, nextCursor, nil
+}
+
+// ReconsiderPass flips the user's dislike of target back to neutral, so target can be suggested
+// again (premium, like rewind, which only reaches the latest dislike)
+func (s *InteractionService) ReconsiderPass(ctx context.Context, userHandle, target string) error {
+	if !s.Billing.HasEntitlement(ctx, userHandle, models.EntitlementRewind) {
+		return ErrPremiumRequired
+	}
+	if s.Blocks.IsBlocked(ctx, userHandle, target) {
+		return notFoundError("no pass on this user")
+	}
 
-	items, err := s.Dynamo.QueryItems(ctx, models.InteractionsTable, keyCondition, expressionValues, nil, 100)
+	output, err := s.Dynamo.Client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(models.InteractionsTable),
+		Key:                 interactionKey(userHandle, target),
+		ConditionExpression: aws.String("#status = :declined AND interactionType <> :ping AND attribute_not_exists(matchId)"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":declined": &types.AttributeValueMemberS{Value: models.StatusDeclined},
+			":ping":     &types.AttributeValueMemberS{Value: models.InteractionTypePing},
+		},
+		ReturnValues: types.ReturnValueAllOld,
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return notFoundError("no pass on this user")
+	}
 	if err != nil {
-		log.Printf("❌ Error querying interactions: %v", err)
-		return nil, fmt.Errorf("failed to fetch interactions: %w", err)
+		return fmt.Errorf("failed to reconsider %s: %w", target, err)
 	}
 
+	var passed models.Interaction
+	if err := attributevalue.UnmarshalMap(output.Attributes, &passed); err != nil {
+		utils.Logf(ctx, "⚠️ Failed to parse reconsidered interaction %s -> %s: %v", userHandle, target, err)
+	}
+	s.recordTransition(ctx, userHandle, target, passed.InteractionType, models.StatusDeclined, models.AuditStatusDeleted, nil,
+		models.InteractionCause{Actor: userHandle, Reason: models.AuditReasonReconsider})
+
+	utils.Logf(ctx, "✅ %s reconsidered %s", userHandle, target)
+	return nil
+}
+
+// GetSavedProfiles fetches one page of the profiles the user saved for later ("maybe later"). Saves
+// are private: the other user is never told. Pages are filled as fillPage describes.
+func (s *InteractionService) GetSavedProfiles(ctx context.Context, userHandle string, limit int32, cursor string) ([]models.InteractionWithProfile, string, error) {
+	return fillPage(limit, cursor, func(limit int32, cursor string) ([]models.InteractionWithProfile, string, error) {
+		return s.savedProfilesPage(ctx, userHandle, limit, cursor)
+	})
+}
+
+// savedProfilesPage reads one query page of saved profiles
+func (s *InteractionService) savedProfilesPage(ctx context.Context, userHandle string, limit int32, cursor string) ([]models.InteractionWithProfile, string, error) {
+	items, nextCursor, err := s.Dynamo.QueryPage(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.InteractionsTable),
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :prefix)"),
+		FilterExpression:       aws.String("interactionType = :save"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":     &types.AttributeValueMemberS{Value: "USER#" + userHandle},
+			":prefix": &types.AttributeValueMemberS{Value: "INTERACTION#"},
+			":save":   &types.AttributeValueMemberS{Value: models.InteractionTypeSave},
+		},
+	}, limit, cursor)
+	if err != nil {
+		utils.Logf(ctx, "❌ Error querying saved profiles: %v", err)
+		return nil, "", err
+	}
+
+	blocked, err := s.Blocks.BlockedHandles(ctx, userHandle)
+	if err != nil {
+		utils.Logf(ctx, "❌ Error fetching blocks: %v", err)
+		return nil, "", err
+	}
+	interactions := slices.DeleteFunc(unmarshalInteractions(items), func(interaction models.Interaction) bool {
+		return blocked[interaction.ReceiverHandle]
+	})
+
+	interactionsWithProfiles := s.sentWithProfiles(ctx, interactions, "saved_profiles")
+	if interactionsWithProfiles == nil {
+		interactionsWithProfiles = []models.InteractionWithProfile{}
+	}
+	utils.Logf(ctx, "✅ Found %d saved profiles for %s", len(interactionsWithProfiles), userHandle)
+	return interactionsWithProfiles, nextCursor, nil
+}
+
+// RemoveSaved takes target off the user's saved list; target stays in the deck
+func (s *InteractionService) RemoveSaved(ctx context.Context, userHandle, target string) error {
+	_, err := s.Dynamo.Client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(models.InteractionsTable),
+		Key:                 interactionKey(userHandle, target),
+		ConditionExpression: aws.String("interactionType = :save"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":save": &types.AttributeValueMemberS{Value: models.InteractionTypeSave},
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return notFoundError("user is not saved")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove saved %s: %w", target, err)
+	}
+
+	s.recordTransition(ctx, userHandle, target, models.InteractionTypeSave, models.StatusSaved, models.AuditStatusDeleted, nil,
+		models.InteractionCause{Actor: userHandle, Reason: models.AuditReasonUnsave})
+
+	utils.Logf(ctx, "✅ %s removed %s from their saved profiles", userHandle, target)
+	return nil
+}
+
+// sentWithProfiles attaches each receiver's profile to sent interactions and records the
+// enrichment outcome under feed
+func (s *InteractionService) sentWithProfiles(ctx context.Context, interactions []models.Interaction, feed string) []models.InteractionWithProfile {
 	var interactionsWithProfiles []models.InteractionWithProfile
 
-	for _, item := range items {
-		var interaction models.Interaction
-		err := attributevalue.UnmarshalMap(item, &interaction)
-		if err != nil {
-			log.Printf("⚠️ Skipping item due to unmarshalling error: %v", err)
-			continue
-		}
+	// 🔍 Batch fetch receiver profiles
+	receiverHandles := make([]string, 0, len(interactions))
+	for _, interaction := range interactions {
+		receiverHandles = append(receiverHandles, interaction.ReceiverHandle)
+	}
+	missingReason := models.EnrichmentReasonProfileNotFound
+	profiles, err := s.UserProfileService.GetUserProfilesByHandles(ctx, receiverHandles)
+	if err != nil {
+		utils.Logf(ctx, "❌ Error fetching receiver profiles, returning placeholders: %v", err)
+		profiles = map[string]*models.UserProfile{}
+		missingReason = models.EnrichmentReasonProfileFetchFailed
+	}
 
-		// Fetch user profile for receiver
-		profile, err := s.UserProfileService.GetUserProfileByHandle(ctx, interaction.ReceiverHandle)
-		if err != nil {
-			log.Printf("⚠️ Failed to fetch profile for %s: %v", interaction.ReceiverHandle, err)
+	failed := 0
+	for _, interaction := range interactions {
+		profile, ok := profiles[interaction.ReceiverHandle]
+		if !ok {
+			utils.Logf(ctx, "⚠️ Profile unavailable for %s (%s)", interaction.ReceiverHandle, missingReason)
+			failed++
+			interactionsWithProfiles = append(interactionsWithProfiles, placeholderInteraction(interaction, missingReason))
 			continue
 		}
 
@@ -629,7 +1265,7 @@ func (s *InteractionService) GetUserInteractions(ctx context.Context, userHandle
 			ReceiverHandle:  interaction.ReceiverHandle,
 			SenderHandle:    interaction.SenderHandle,
 			InteractionType: interaction.InteractionType,
-			Message:         *interaction.Message,
+			Message:         derefString(interaction.Message),
 			Status:          interaction.Status,
 			CreatedAt:       interaction.CreatedAt,
 
@@ -642,15 +1278,27 @@ func (s *InteractionService) GetUserInteractions(ctx context.Context, userHandle
 			Photos:      profile.Photos,
 			Bio:         profile.Bio,
 			Interests:   profile.Interests,
+			Prompts:     profile.Prompts,
+			Spotify:     profile.Spotify,
 		})
 	}
 
-	log.Printf("✅ Found %d interactions sent by %s", len(interactionsWithProfiles), userHandle)
-	return interactionsWithProfiles, nil
+	recordEnrichment(feed, len(interactionsWithProfiles), failed)
+	return interactionsWithProfiles
 }
 
-func (s *InteractionService) GetReceivedInteractions(ctx context.Context, userHandle string) ([]models.InteractionWithProfile, error) {
-	log.Printf("🔍 Fetching interactions RECEIVED by user: %s", userHandle)
+// GetReceivedInteractions fetches one page of interactions RECEIVED by a user, narrowed by filter
+// (e.g. only pending pings); pass the returned cursor back for the next page. Pages are filled as
+// fillPage describes.
+func (s *InteractionService) GetReceivedInteractions(ctx context.Context, userHandle string, filter models.InteractionFilter, limit int32, cursor string) ([]models.InteractionWithProfile, string, error) {
+	return fillPage(limit, cursor, func(limit int32, cursor string) ([]models.InteractionWithProfile, string, error) {
+		return s.receivedInteractionsPage(ctx, userHandle, filter, limit, cursor)
+	})
+}
+
+// receivedInteractionsPage reads one query page of received interactions
+func (s *InteractionService) receivedInteractionsPage(ctx context.Context, userHandle string, filter models.InteractionFilter, limit int32, cursor string) ([]models.InteractionWithProfile, string, error) {
+	utils.Logf(ctx, "🔍 Fetching interactions RECEIVED by user: %s (types=%v, statuses=%v)", userHandle, filter.Types, filter.Statuses)
 
 	indexName := models.ReceiverHandleIndex
 	keyCondition := "#receiverHandle = :receiver"
@@ -660,26 +1308,234 @@ func (s *InteractionService) GetReceivedInteractions(ctx context.Context, userHa
 	}
 	expressionNames := map[string]string{"#receiverHandle": "receiverHandle"}
 
-	items, err := s.Dynamo.QueryItemsWithIndex(ctx, models.InteractionsTable, indexName, keyCondition, expressionValues, expressionNames, 100)
+	// ✅ Saves are private to the user who saved
+	expressionValues[":save"] = &types.AttributeValueMemberS{Value: models.InteractionTypeSave}
+	filterExpression := interactionFilterExpression(filter, []string{"interactionType <> :save"}, expressionNames, expressionValues)
+
+	items, nextCursor, err := s.Dynamo.QueryPage(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(models.InteractionsTable),
+		IndexName:                 aws.String(indexName),
+		KeyConditionExpression:    aws.String(keyCondition),
+		FilterExpression:          aws.String(strings.Join(filterExpression, " AND ")),
+		ExpressionAttributeValues: expressionValues,
+		ExpressionAttributeNames:  expressionNames,
+	}, limit, cursor)
+	if err != nil {
+		utils.Logf(ctx, "❌ Error querying received interactions: %v", err)
+		return nil, "", err
+	}
+
+	// ✅ Blocked and reported users drop out of the feed, whichever side blocked
+	blocked, err := s.Blocks.BlockedHandles(ctx, userHandle)
+	if err != nil {
+		utils.Logf(ctx, "❌ Error fetching blocks: %v", err)
+		return nil, "", err
+	}
+	interactions := slices.DeleteFunc(unmarshalInteractions(items), func(interaction models.Interaction) bool {
+		return blocked[interaction.SenderHandle]
+	})
+
+	interactionsWithProfiles := s.receivedWithProfiles(ctx, userHandle, interactions, "received_interactions")
+	utils.Logf(ctx, "✅ Found %d received interactions for %s", len(interactionsWithProfiles), userHandle)
+	return interactionsWithProfiles, nextCursor, nil
+}
+
+// CountPendingReceived counts the likes and pings the user received and hasn't acted on yet, leaving
+// out blocked and hidden senders the same way the received feed does
+func (s *InteractionService) CountPendingReceived(ctx context.Context, userHandle string) (likes, pings int, err error) {
+	items, err := s.Dynamo.QueryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.InteractionsTable),
+		IndexName:              aws.String(models.ReceiverHandleIndex),
+		KeyConditionExpression: aws.String("receiverHandle = :receiver"),
+		FilterExpression:       aws.String("interactionType IN (:like, :ping) AND #status = :pending"),
+		ProjectionExpression:   aws.String("senderHandle, interactionType"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":receiver": &types.AttributeValueMemberS{Value: userHandle},
+			":like":     &types.AttributeValueMemberS{Value: models.InteractionTypeLike},
+			":ping":     &types.AttributeValueMemberS{Value: models.InteractionTypePing},
+			":pending":  &types.AttributeValueMemberS{Value: models.StatusPending},
+		},
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to query pending interactions: %w", err)
+	}
+
+	blocked, err := s.Blocks.BlockedHandles(ctx, userHandle)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to fetch blocks: %w", err)
+	}
+	interactions := slices.DeleteFunc(unmarshalInteractions(items), func(interaction models.Interaction) bool {
+		return blocked[interaction.SenderHandle]
+	})
+	senderHandles := make([]string, 0, len(interactions))
+	for _, interaction := range interactions {
+		senderHandles = append(senderHandles, interaction.SenderHandle)
+	}
+	profiles, err := s.UserProfileService.GetUserProfilesByHandles(ctx, senderHandles)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to fetch sender profiles: %w", err)
+	}
+	for _, interaction := range interactions {
+		if hiddenSender(profiles, interaction.SenderHandle) {
+			continue
+		}
+		switch interaction.InteractionType {
+		case models.InteractionTypeLike:
+			likes++
+		case models.InteractionTypePing:
+			pings++
+		}
+	}
+	return likes, pings, nil
+}
+
+// GetSecondLookLikes fetches one page of likes the user received at least olderThan ago and never
+// acted on (no like, pass or ping back, not yet engaged with); pages are filled as fillPage describes
+func (s *InteractionService) GetSecondLookLikes(ctx context.Context, userHandle string, olderThan time.Duration, limit int32, cursor string) ([]models.InteractionWithProfile, string, error) {
+	return fillPage(limit, cursor, func(limit int32, cursor string) ([]models.InteractionWithProfile, string, error) {
+		return s.secondLookLikesPage(ctx, userHandle, olderThan, limit, cursor)
+	})
+}
+
+// secondLookLikesPage reads one query page of likes for a second look
+func (s *InteractionService) secondLookLikesPage(ctx context.Context, userHandle string, olderThan time.Duration, limit int32, cursor string) ([]models.InteractionWithProfile, string, error) {
+	cutoff := time.Now().UTC().Add(-olderThan).Format(time.RFC3339)
+	items, nextCursor, err := s.Dynamo.QueryPage(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.InteractionsTable),
+		IndexName:              aws.String(models.ReceiverHandleIndex),
+		KeyConditionExpression: aws.String("receiverHandle = :receiver"),
+		FilterExpression:       aws.String("interactionType = :like AND #status IN (:pending, :seen) AND createdAt <= :cutoff AND attribute_not_exists(secondLookAt)"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":receiver": &types.AttributeValueMemberS{Value: userHandle},
+			":like":     &types.AttributeValueMemberS{Value: models.InteractionTypeLike},
+			":pending":  &types.AttributeValueMemberS{Value: models.StatusPending},
+			":seen":     &types.AttributeValueMemberS{Value: models.StatusSeen},
+			":cutoff":   &types.AttributeValueMemberS{Value: cutoff},
+		},
+	}, limit, cursor)
+	if err != nil {
+		utils.Logf(ctx, "❌ Error querying second look likes: %v", err)
+		return nil, "", err
+	}
+	interactions := unmarshalInteractions(items)
+	if len(interactions) == 0 {
+		return []models.InteractionWithProfile{}, nextCursor, nil
+	}
+
+	// ✅ Drop blocked users, and senders the user already answered with an interaction of their own
+	blocked, err := s.Blocks.BlockedHandles(ctx, userHandle)
+	if err != nil {
+		utils.Logf(ctx, "❌ Error fetching blocks: %v", err)
+		return nil, "", err
+	}
+	keys := make([]map[string]types.AttributeValue, 0, len(interactions))
+	for _, interaction := range interactions {
+		keys = append(keys, interactionKey(userHandle, interaction.SenderHandle))
+	}
+	answers, err := s.Dynamo.BatchGetItems(ctx, models.InteractionsTable, keys)
 	if err != nil {
-		log.Printf("❌ Error querying received interactions: %v", err)
-		return nil, fmt.Errorf("failed to fetch received interactions: %w", err)
+		utils.Logf(ctx, "❌ Error fetching interactions of %s: %v", userHandle, err)
+		return nil, "", err
+	}
+	answered := make(map[string]bool, len(answers))
+	for _, answer := range unmarshalInteractions(answers) {
+		answered[answer.ReceiverHandle] = true
 	}
+	interactions = slices.DeleteFunc(interactions, func(interaction models.Interaction) bool {
+		return blocked[interaction.SenderHandle] || answered[interaction.SenderHandle]
+	})
 
+	interactionsWithProfiles := s.receivedWithProfiles(ctx, userHandle, interactions, "second_look_likes")
+	utils.Logf(ctx, "✅ Found %d likes for a second look by %s", len(interactionsWithProfiles), userHandle)
+	return interactionsWithProfiles, nextCursor, nil
+}
+
+// EngageSecondLook records that the user opened a resurfaced like from sender and publishes
+// like.second_look so the sender can be notified again; each like is resurfaced only once
+func (s *InteractionService) EngageSecondLook(ctx context.Context, userHandle, sender string) error {
+	if s.Blocks.IsBlocked(ctx, userHandle, sender) {
+		return notFoundError("like not found")
+	}
+	_, err := s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(models.InteractionsTable),
+		Key:                 interactionKey(sender, userHandle),
+		UpdateExpression:    aws.String("SET secondLookAt = :now"),
+		ConditionExpression: aws.String("interactionType = :like AND #status IN (:pending, :seen) AND attribute_not_exists(secondLookAt)"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":now":     &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)},
+			":like":    &types.AttributeValueMemberS{Value: models.InteractionTypeLike},
+			":pending": &types.AttributeValueMemberS{Value: models.StatusPending},
+			":seen":    &types.AttributeValueMemberS{Value: models.StatusSeen},
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return conflictError("no pending like from this user awaits a second look")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record second look at %s: %w", sender, err)
+	}
+
+	s.Webhooks.Publish(ctx, models.WebhookLikeSecondLook, map[string]interface{}{
+		"senderHandle":   sender,
+		"receiverHandle": userHandle,
+	})
+	utils.Logf(ctx, "👀 %s took a second look at the like from %s", userHandle, sender)
+	return nil
+}
+
+// receivedWithProfiles attaches each sender's profile to received interactions (pending likes stay
+// locked teasers without premium) and records the enrichment outcome under feed
+func (s *InteractionService) receivedWithProfiles(ctx context.Context, userHandle string, interactions []models.Interaction, feed string) []models.InteractionWithProfile {
 	var interactionsWithProfiles []models.InteractionWithProfile
 
-	for _, item := range items {
-		var interaction models.Interaction
-		err := attributevalue.UnmarshalMap(item, &interaction)
-		if err != nil {
-			log.Printf("⚠️ Skipping item due to unmarshalling error: %v", err)
+	// 🔍 Batch fetch sender profiles
+	senderHandles := make([]string, 0, len(interactions))
+	for _, interaction := range interactions {
+		senderHandles = append(senderHandles, interaction.SenderHandle)
+	}
+	missingReason := models.EnrichmentReasonProfileNotFound
+	profiles, err := s.UserProfileService.GetUserProfilesByHandles(ctx, senderHandles)
+	if err != nil {
+		utils.Logf(ctx, "❌ Error fetching sender profiles, returning placeholders: %v", err)
+		profiles = map[string]*models.UserProfile{}
+		missingReason = models.EnrichmentReasonProfileFetchFailed
+	}
+
+	// ✅ Without premium, pending likes are shown as locked teasers instead of profiles
+	canSeeLikes := s.Billing.HasEntitlement(ctx, userHandle, models.EntitlementSeeWhoLikedYou)
+
+	failed := 0
+	for _, interaction := range interactions {
+		// ✅ Likes and pings from hidden senders are never delivered, not even as locked teasers
+		if missingReason == models.EnrichmentReasonProfileNotFound && hiddenSender(profiles, interaction.SenderHandle) {
+			continue
+		}
+		if !canSeeLikes && interaction.InteractionType == models.InteractionTypeLike && interaction.Status == models.StatusPending {
+			interactionsWithProfiles = append(interactionsWithProfiles, models.InteractionWithProfile{
+				ReceiverHandle:  interaction.ReceiverHandle,
+				InteractionType: interaction.InteractionType,
+				Status:          interaction.Status,
+				CreatedAt:       interaction.CreatedAt,
+				Locked:          true,
+			})
 			continue
 		}
 
-		// Fetch sender's profile
-		profile, err := s.UserProfileService.GetUserProfileByHandle(ctx, interaction.SenderHandle)
-		if err != nil {
-			log.Printf("⚠️ Failed to fetch profile for %s: %v", interaction.SenderHandle, err)
+		profile, ok := profiles[interaction.SenderHandle]
+		if !ok {
+			utils.Logf(ctx, "⚠️ Profile unavailable for %s (%s)", interaction.SenderHandle, missingReason)
+			failed++
+			interactionsWithProfiles = append(interactionsWithProfiles, placeholderInteraction(interaction, missingReason))
 			continue
 		}
 
@@ -687,14 +1543,9 @@ func (s *InteractionService) GetReceivedInteractions(ctx context.Context, userHa
 			ReceiverHandle:  interaction.ReceiverHandle,
 			SenderHandle:    interaction.SenderHandle,
 			InteractionType: interaction.InteractionType,
-			Message: func() string {
-				if interaction.Message != nil {
-					return *interaction.Message
-				}
-				return ""
-			}(),
-			Status:    interaction.Status,
-			CreatedAt: interaction.CreatedAt,
+			Message:         derefString(interaction.Message),
+			Status:          interaction.Status,
+			CreatedAt:       interaction.CreatedAt,
 
 			// Extracted profile fields
 			Name:        profile.Name,
@@ -705,9 +1556,181 @@ func (s *InteractionService) GetReceivedInteractions(ctx context.Context, userHa
 			Photos:      profile.Photos,
 			Bio:         profile.Bio,
 			Interests:   profile.Interests,
+			Prompts:     profile.Prompts,
+			Spotify:     profile.Spotify,
 		})
 	}
 
-	log.Printf("✅ Found %d received interactions for %s", len(interactionsWithProfiles), userHandle)
-	return interactionsWithProfiles, nil
+	recordEnrichment(feed, len(interactionsWithProfiles), failed)
+	return interactionsWithProfiles
+}
+
+// hiddenSender reports whether likes and pings from sender are left out of the received feed and its
+// counts: shadowbanned and age-restricted senders, and deleted ones, which profiles (from
+// GetUserProfilesByHandles) never holds
+func hiddenSender(profiles map[string]*models.UserProfile, sender string) bool {
+	profile, ok := profiles[sender]
+	return !ok || profile.Shadowbanned || profile.IsAgeRestricted()
+}
+
+// RewindLastDislike undoes the user's most recent dislike so the profile can be shown again.
+// Premium only; returns the handle of the profile that was restored.
+func (s *InteractionService) RewindLastDislike(ctx context.Context, userHandle string) (string, error) {
+	utils.Logf(ctx, "🔄 Rewinding last dislike for user: %s", userHandle)
+
+	if !s.Billing.HasEntitlement(ctx, userHandle, models.EntitlementRewind) {
+		return "", ErrPremiumRequired
+	}
+
+	items, err := s.Dynamo.QueryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.InteractionsTable),
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :prefix)"),
+		FilterExpression:       aws.String("#status = :declined AND interactionType <> :ping"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":       &types.AttributeValueMemberS{Value: "USER#" + userHandle},
+			":prefix":   &types.AttributeValueMemberS{Value: "INTERACTION#"},
+			":declined": &types.AttributeValueMemberS{Value: models.StatusDeclined},
+			":ping":     &types.AttributeValueMemberS{Value: models.InteractionTypePing},
+		},
+	})
+	if err != nil {
+		utils.Logf(ctx, "❌ Error fetching dislikes for %s: %v", userHandle, err)
+		return "", fmt.Errorf("failed to fetch dislikes: %w", err)
+	}
+
+	var latest *models.Interaction
+	for _, interaction := range unmarshalInteractions(items) {
+		if latest == nil || interaction.LastUpdated > latest.LastUpdated {
+			latest = &interaction
+		}
+	}
+	if latest == nil {
+		return "", ErrNothingToRewind
+	}
+
+	key := map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: latest.PK},
+		"SK": &types.AttributeValueMemberS{Value: latest.SK},
+	}
+	if err := s.Dynamo.DeleteItem(ctx, models.InteractionsTable, key); err != nil {
+		utils.Logf(ctx, "❌ Error deleting dislike %s -> %s: %v", userHandle, latest.ReceiverHandle, err)
+		return "", fmt.Errorf("failed to rewind dislike: %w", err)
+	}
+	s.recordTransition(ctx, userHandle, latest.ReceiverHandle, latest.InteractionType, latest.Status, models.AuditStatusDeleted, latest.MatchID,
+		models.InteractionCause{Actor: userHandle, Reason: models.AuditReasonRewind})
+
+	utils.Logf(ctx, "✅ Rewound dislike %s -> %s", userHandle, latest.ReceiverHandle)
+	return latest.ReceiverHandle, nil
+}
+
+// checkLikeLimit returns ErrLikeLimitReached when a user without unlimited likes has already
+// sent FreeDailyLikeLimit likes today
+func (s *InteractionService) checkLikeLimit(ctx context.Context, sender string) error {
+	if s.Billing.HasEntitlement(ctx, sender, models.EntitlementUnlimitedLikes) {
+		return nil
+	}
+
+	now := time.Now()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).Format(time.RFC3339)
+	count, err := s.Dynamo.CountItems(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.InteractionsTable),
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :prefix)"),
+		FilterExpression:       aws.String("interactionType = :like AND #status <> :declined AND lastUpdated >= :dayStart"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":       &types.AttributeValueMemberS{Value: "USER#" + sender},
+			":prefix":   &types.AttributeValueMemberS{Value: "INTERACTION#"},
+			":like":     &types.AttributeValueMemberS{Value: models.InteractionTypeLike},
+			":declined": &types.AttributeValueMemberS{Value: models.StatusDeclined},
+			":dayStart": &types.AttributeValueMemberS{Value: dayStart},
+		},
+	})
+	if err != nil {
+		utils.Logf(ctx, "❌ Error counting today's likes for %s: %v", sender, err)
+		return fmt.Errorf("failed to check like limit: %w", err)
+	}
+	if count >= models.FreeDailyLikeLimit {
+		utils.Logf(ctx, "🚫 %s reached the free daily like limit (%d)", sender, models.FreeDailyLikeLimit)
+		return ErrLikeLimitReached
+	}
+	return nil
+}
+
+// interactionFilterExpression appends filter's type and status conditions to conditions, adding
+// the names and values they use
+func interactionFilterExpression(filter models.InteractionFilter, conditions []string, names map[string]string, values map[string]types.AttributeValue) []string {
+	in := func(attribute, prefix string, allowed []string) string {
+		placeholders := make([]string, len(allowed))
+		for i, value := range allowed {
+			placeholder := fmt.Sprintf(":%s%d", prefix, i)
+			values[placeholder] = &types.AttributeValueMemberS{Value: value}
+			placeholders[i] = placeholder
+		}
+		return attribute + " IN (" + strings.Join(placeholders, ", ") + ")"
+	}
+	if len(filter.Types) > 0 {
+		conditions = append(conditions, in("interactionType", "filterType", filter.Types))
+	}
+	if len(filter.Statuses) > 0 {
+		names["#filterStatus"] = "status"
+		conditions = append(conditions, in("#filterStatus", "filterStatus", filter.Statuses))
+	}
+	return conditions
+}
+
+// interactionKey builds the Interactions primary key of sender's interaction with receiver
+func interactionKey(sender, receiver string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: "USER#" + sender},
+		"SK": &types.AttributeValueMemberS{Value: "INTERACTION#" + receiver},
+	}
+}
+
+// otherParticipant returns the handle on the other side of an interaction from userHandle
+func otherParticipant(interaction models.Interaction, userHandle string) string {
+	if interaction.ReceiverHandle == userHandle {
+		return interaction.SenderHandle
+	}
+	return interaction.ReceiverHandle
+}
+
+// unmarshalInteractions decodes interaction items, skipping any that fail to parse
+func unmarshalInteractions(items []map[string]types.AttributeValue) []models.Interaction {
+	interactions := make([]models.Interaction, 0, len(items))
+	for _, item := range items {
+		var interaction models.Interaction
+		if err := attributevalue.UnmarshalMap(item, &interaction); err != nil {
+			log.Printf("⚠️ Skipping item due to unmarshalling error: %v", err)
+			continue
+		}
+		interactions = append(interactions, interaction)
+	}
+	return interactions
+}
+
+// placeholderInteraction keeps an interaction visible when its profile could not be loaded
+func placeholderInteraction(interaction models.Interaction, reason string) models.InteractionWithProfile {
+	return models.InteractionWithProfile{
+		ReceiverHandle:  interaction.ReceiverHandle,
+		SenderHandle:    interaction.SenderHandle,
+		InteractionType: interaction.InteractionType,
+		Message:         derefString(interaction.Message),
+		Status:          interaction.Status,
+		CreatedAt:       interaction.CreatedAt,
+		EnrichmentError: true,
+		RetryHint:       enrichmentRetryHint(reason),
+	}
+}
+
+// derefString returns the pointed-to string or "" for nil
+func derefString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
 }
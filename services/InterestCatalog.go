@@ -0,0 +1,151 @@
+package services
+
+import (
+	"strings"
+	"vibin_server/models"
+)
+
+// interestCatalog is the static set of interests users pick from. IDs are stored on profiles (and
+// referenced by dateIdeaCatalog), so retire an interest by removing it here rather than reusing its ID.
+var interestCatalog = []models.Interest{
+	{ID: "hiking", Name: "Hiking", Category: "outdoors"},
+	{ID: "camping", Name: "Camping", Category: "outdoors"},
+	{ID: "nature", Name: "Nature", Category: "outdoors"},
+	{ID: "travel", Name: "Travel", Category: "outdoors"},
+	{ID: "beach", Name: "Beach", Category: "outdoors"},
+	{ID: "fitness", Name: "Fitness", Category: "active"},
+	{ID: "running", Name: "Running", Category: "active"},
+	{ID: "cycling", Name: "Cycling", Category: "active"},
+	{ID: "climbing", Name: "Climbing", Category: "active"},
+	{ID: "yoga", Name: "Yoga", Category: "active"},
+	{ID: "sports", Name: "Sports", Category: "active"},
+	{ID: "dancing", Name: "Dancing", Category: "active"},
+	{ID: "food", Name: "Food", Category: "food_drink"},
+	{ID: "cooking", Name: "Cooking", Category: "food_drink"},
+	{ID: "baking", Name: "Baking", Category: "food_drink"},
+	{ID: "coffee", Name: "Coffee", Category: "food_drink"},
+	{ID: "wine", Name: "Wine", Category: "food_drink"},
+	{ID: "art", Name: "Art", Category: "arts"},
+	{ID: "photography", Name: "Photography", Category: "arts"},
+	{ID: "design", Name: "Design", Category: "arts"},
+	{ID: "writing", Name: "Writing", Category: "arts"},
+	{ID: "reading", Name: "Reading", Category: "arts"},
+	{ID: "music", Name: "Music", Category: "music"},
+	{ID: "concerts", Name: "Concerts", Category: "music"},
+	{ID: "singing", Name: "Singing", Category: "music"},
+	{ID: "movies", Name: "Movies", Category: "entertainment"},
+	{ID: "comedy", Name: "Comedy", Category: "entertainment"},
+	{ID: "gaming", Name: "Gaming", Category: "entertainment"},
+	{ID: "board-games", Name: "Board games", Category: "entertainment"},
+	{ID: "walking", Name: "Walking", Category: "lifestyle"},
+	{ID: "meditation", Name: "Meditation", Category: "lifestyle"},
+	{ID: "pets", Name: "Pets", Category: "lifestyle"},
+	{ID: "fashion", Name: "Fashion", Category: "lifestyle"},
+	{ID: "volunteering", Name: "Volunteering", Category: "lifestyle"},
+	{ID: "history", Name: "History", Category: "learning"},
+	{ID: "science", Name: "Science", Category: "learning"},
+	{ID: "technology", Name: "Technology", Category: "learning"},
+	{ID: "languages", Name: "Languages", Category: "learning"},
+}
+
+// interestAliases maps common free-form spellings (from profiles created before the catalog) to IDs
+var interestAliases = map[string]string{
+	"hike":          "hiking",
+	"hikes":         "hiking",
+	"trekking":      "hiking",
+	"film":          "movies",
+	"films":         "movies",
+	"cinema":        "movies",
+	"games":         "gaming",
+	"video games":   "gaming",
+	"boardgames":    "board-games",
+	"gym":           "fitness",
+	"workout":       "fitness",
+	"working out":   "fitness",
+	"run":           "running",
+	"jogging":       "running",
+	"bike":          "cycling",
+	"biking":        "cycling",
+	"bouldering":    "climbing",
+	"rock climbing": "climbing",
+	"dance":         "dancing",
+	"books":         "reading",
+	"foodie":        "food",
+	"eating out":    "food",
+	"cook":          "cooking",
+	"live music":    "concerts",
+	"gigs":          "concerts",
+	"karaoke":       "singing",
+	"dogs":          "pets",
+	"cats":          "pets",
+	"animals":       "pets",
+	"tech":          "technology",
+	"coding":        "technology",
+	"programming":   "technology",
+	"photos":        "photography",
+	"painting":      "art",
+	"drawing":       "art",
+}
+
+// interestLookup resolves IDs, lowercase names and aliases to catalog entries
+var interestLookup = func() map[string]models.Interest {
+	lookup := make(map[string]models.Interest, 2*len(interestCatalog)+len(interestAliases))
+	for _, interest := range interestCatalog {
+		lookup[interest.ID] = interest
+		lookup[strings.ToLower(interest.Name)] = interest
+	}
+	for alias, id := range interestAliases {
+		lookup[alias] = lookup[id]
+	}
+	return lookup
+}()
+
+// interestByID looks a catalog interest up by ID
+func interestByID(id string) (models.Interest, bool) {
+	interest, ok := interestLookup[id]
+	return interest, ok && interest.ID == id
+}
+
+// canonicalInterestIDs maps free-form interests ("Hiking ", "board_games", "films") to catalog IDs,
+// dropping unknown ones and repeats
+func canonicalInterestIDs(values []string) []string {
+	ids := make([]string, 0, len(values))
+	for _, value := range values {
+		normalized := strings.Join(strings.Fields(strings.ToLower(strings.ReplaceAll(value, "_", " "))), " ")
+		interest, ok := interestLookup[normalized]
+		if !ok {
+			interest, ok = interestLookup[strings.ReplaceAll(normalized, " ", "-")]
+		}
+		if ok {
+			ids = append(ids, interest.ID)
+		}
+	}
+	return nonEmptyUnique(ids)
+}
+
+// profileInterestIDs returns the profile's interest IDs, deriving them from the free-form interests
+// of profiles that predate the catalog
+func profileInterestIDs(profile *models.UserProfile) []string {
+	if len(profile.InterestIDs) > 0 {
+		return profile.InterestIDs
+	}
+	return canonicalInterestIDs(profile.Interests)
+}
+
+// sharedInterests returns the interest IDs both profiles list, in a's order
+func sharedInterests(a, b *models.UserProfile) []string {
+	if a == nil || b == nil {
+		return nil
+	}
+	theirs := make(map[string]bool)
+	for _, id := range profileInterestIDs(b) {
+		theirs[id] = true
+	}
+	var shared []string
+	for _, id := range profileInterestIDs(a) {
+		if theirs[id] {
+			shared = append(shared, id)
+		}
+	}
+	return shared
+}
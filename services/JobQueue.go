@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/google/uuid"
+)
+
+// ✅ Worker limits
+const (
+	jobReceiveBatch    = 10               // SQS maximum per ReceiveMessage
+	jobReceiveWait     = 20               // Long-poll seconds
+	jobHandlerTimeout  = 30 * time.Second // Keep below the queue's visibility timeout
+	jobReceiveBackoff  = 5 * time.Second  // Pause after a failed ReceiveMessage
+	jobReceiveAttrName = "ApproximateReceiveCount"
+)
+
+// JobHandler runs one job; returning an error leaves the message on the queue to be retried
+// (and eventually moved to the dead-letter queue by the queue's redrive policy)
+type JobHandler func(ctx context.Context, payload json.RawMessage) error
+
+// JobQueue moves non-critical side effects (analytics, insights, webhooks) off the request path.
+// With an SQS queue configured, jobs are sent there and run by the worker loop; without one they
+// run in a background goroutine of the enqueuing instance (or inline, with Inline set).
+type JobQueue struct {
+	SQS      *sqs.Client
+	QueueURL string
+	Inline   bool // Run unqueued jobs before returning; for Lambda, which freezes background goroutines
+
+	mu       sync.RWMutex
+	handlers map[string]JobHandler
+}
+
+// InitializeSQSClient initializes the SQS client
+func InitializeSQSClient(region string) *sqs.Client {
+	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion(region))
+	if err != nil {
+		log.Fatalf("Failed to load AWS config: %v", err)
+	}
+	return sqs.NewFromConfig(cfg)
+}
+
+// Handle registers the handler for a job type; services call it from their RegisterJobs
+func (q *JobQueue) Handle(jobType string, handler JobHandler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.handlers == nil {
+		q.handlers = map[string]JobHandler{}
+	}
+	q.handlers[jobType] = handler
+}
+
+// Enqueue schedules a job and returns immediately. It never fails the caller: if SQS is unavailable
+// the job runs in-process instead.
+func (q *JobQueue) Enqueue(ctx context.Context, jobType string, payload interface{}) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		utils.Logf(ctx, "❌ Failed to encode %s job: %v", jobType, err)
+		return
+	}
+	job := models.Job{
+		JobID:      uuid.New().String(),
+		Type:       jobType,
+		Payload:    raw,
+		RequestID:  utils.RequestIDFromContext(ctx),
+		EnqueuedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	if q.SQS != nil && q.QueueURL != "" {
+		body, err := json.Marshal(job)
+		if err == nil {
+			_, err = q.SQS.SendMessage(ctx, &sqs.SendMessageInput{
+				QueueUrl:    aws.String(q.QueueURL),
+				MessageBody: aws.String(string(body)),
+			})
+		}
+		if err == nil {
+			return
+		}
+		utils.Logf(ctx, "⚠️ Failed to queue %s job %s, running it in-process: %v", jobType, job.JobID, err)
+	}
+
+	if q.Inline {
+		q.run(ctx, job)
+		return
+	}
+	go q.run(context.WithoutCancel(ctx), job) // ✅ Keep the request ID, outlive the request
+}
+
+// StartWorkers polls the queue with the given number of workers until ctx is cancelled.
+// It does nothing when no queue is configured.
+func (q *JobQueue) StartWorkers(ctx context.Context, workers int) {
+	if q.SQS == nil || q.QueueURL == "" {
+		return
+	}
+	for i := 0; i < workers; i++ {
+		go q.poll(ctx)
+	}
+	utils.Logf(ctx, "✅ Started %d job queue workers", workers)
+}
+
+// poll long-polls SQS and runs each received job, deleting it once it succeeds
+func (q *JobQueue) poll(ctx context.Context) {
+	for ctx.Err() == nil {
+		output, err := q.SQS.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:                    aws.String(q.QueueURL),
+			MaxNumberOfMessages:         jobReceiveBatch,
+			WaitTimeSeconds:             jobReceiveWait,
+			MessageSystemAttributeNames: []sqstypes.MessageSystemAttributeName{jobReceiveAttrName},
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			utils.Logf(ctx, "⚠️ Failed to receive jobs, retrying in %s: %v", jobReceiveBackoff, err)
+			time.Sleep(jobReceiveBackoff)
+			continue
+		}
+
+		for _, message := range output.Messages {
+			var job models.Job
+			if err := json.Unmarshal([]byte(aws.ToString(message.Body)), &job); err != nil {
+				utils.Logf(ctx, "❌ Dropping malformed job message %s: %v", aws.ToString(message.MessageId), err)
+				q.delete(ctx, message)
+				continue
+			}
+			if q.run(ctx, job) {
+				q.delete(ctx, message)
+			} else {
+				utils.Logf(ctx, "⚠️ Job %s (%s) left for retry, receive count %s", job.JobID, job.Type, message.Attributes[jobReceiveAttrName])
+			}
+		}
+	}
+}
+
+// run executes a job with its handler and reports whether it succeeded
+func (q *JobQueue) run(ctx context.Context, job models.Job) bool {
+	ctx = utils.WithRequestID(ctx, job.RequestID)
+	q.mu.RLock()
+	handler, ok := q.handlers[job.Type]
+	q.mu.RUnlock()
+	if !ok {
+		// ✅ Likely queued by a newer deployment; leave it for an instance that knows the type
+		utils.Logf(ctx, "⚠️ No handler for job %s of type %s", job.JobID, job.Type)
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, jobHandlerTimeout)
+	defer cancel()
+	if err := handler(ctx, job.Payload); err != nil {
+		utils.Logf(ctx, "❌ Job %s (%s) failed: %v", job.JobID, job.Type, err)
+		return false
+	}
+	return true
+}
+
+// delete removes a handled message; if it fails the job may run again, so handlers must tolerate repeats
+func (q *JobQueue) delete(ctx context.Context, message sqstypes.Message) {
+	_, err := q.SQS.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(q.QueueURL),
+		ReceiptHandle: message.ReceiptHandle,
+	})
+	if err != nil {
+		utils.Logf(ctx, "⚠️ Failed to delete job message %s: %v", aws.ToString(message.MessageId), err)
+	}
+}
+
+// decodeJob unmarshals a job payload into its typed struct
+func decodeJob[T any](payload json.RawMessage) (T, error) {
+	var job T
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return job, fmt.Errorf("invalid job payload: %w", err)
+	}
+	return job, nil
+}
+
+// applyOnce runs update in one transaction with a receipt for receiptKey, so a job that is retried
+// after the update went through (e.g. the message delete failed) doesn't apply it again. It reports
+// false when the receipt was already there.
+func applyOnce(ctx context.Context, dynamo *DynamoService, receiptKey string, update types.Update) (bool, error) {
+	now := time.Now().UTC()
+	receipt, err := attributevalue.MarshalMap(models.JobReceipt{
+		ReceiptKey: receiptKey,
+		AppliedAt:  now.Format(time.RFC3339),
+		ExpiresAt:  now.AddDate(0, 0, models.JobReceiptRetentionDays).Unix(),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	err = dynamo.TransactWriteItems(ctx, []types.TransactWriteItem{
+		{
+			Put: &types.Put{
+				TableName:           aws.String(models.JobReceiptsTable),
+				Item:                receipt,
+				ConditionExpression: aws.String("attribute_not_exists(receiptKey)"),
+			},
+		},
+		{Update: &update},
+	})
+	var cancelled *types.TransactionCanceledException
+	if errors.As(err, &cancelled) && len(cancelled.CancellationReasons) > 0 && aws.ToString(cancelled.CancellationReasons[0].Code) == "ConditionalCheckFailed" {
+		utils.Logf(ctx, "ℹ️ %s was already applied", receiptKey)
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
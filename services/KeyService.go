@@ -0,0 +1,295 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// preKeyClaimAttempts bounds the retries when concurrent fetchers race for the same one-time prekey
+const preKeyClaimAttempts = 3
+
+// KeyService distributes the public keys clients need for end-to-end encrypted chats (X3DH-style
+// prekey bundles). The server only stores and hands out public keys; it never sees private keys or
+// plaintext, and each one-time prekey is given to at most one peer.
+type KeyService struct {
+	Dynamo *DynamoService
+	Blocks *BlockService
+}
+
+// PublishBundle stores a device's identity key and signed prekey, adding any one-time prekeys.
+// A new identity key discards the device's old one-time prekeys, which were made for the old one.
+// Returns the device's remaining one-time prekeys.
+func (s *KeyService) PublishBundle(ctx context.Context, bundle models.KeyBundle, preKeys []models.OneTimePreKey) (int, error) {
+	existing, err := s.deviceBundles(ctx, bundle.UserHandle)
+	if err != nil {
+		return 0, err
+	}
+	var previous *models.KeyBundle
+	for i := range existing {
+		if existing[i].DeviceID == bundle.DeviceID {
+			previous = &existing[i]
+		}
+	}
+	if previous == nil && len(existing) >= models.MaxDevicesPerUser {
+		return 0, conflictError(fmt.Sprintf("at most %d devices can publish keys; remove one first", models.MaxDevicesPerUser))
+	}
+
+	bundle.OneTimePreKey = nil
+	bundle.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	if err := s.Dynamo.PutItem(ctx, models.KeyBundlesTable, bundle); err != nil {
+		return 0, fmt.Errorf("failed to store key bundle: %w", err)
+	}
+	if previous != nil && previous.IdentityKey != bundle.IdentityKey {
+		if err := s.deletePreKeys(ctx, bundle.UserHandle, bundle.DeviceID); err != nil {
+			return 0, err
+		}
+	}
+	if err := s.putPreKeys(ctx, bundle.UserHandle, bundle.DeviceID, preKeys); err != nil {
+		return 0, err
+	}
+
+	utils.Logf(ctx, "🔑 %s published keys for device %s (%d prekeys)", bundle.UserHandle, bundle.DeviceID, len(preKeys))
+	return s.PreKeyCount(ctx, bundle.UserHandle, bundle.DeviceID)
+}
+
+// AddPreKeys replenishes a published device's one-time prekeys and returns how many it has
+func (s *KeyService) AddPreKeys(ctx context.Context, userHandle, deviceID string, preKeys []models.OneTimePreKey) (int, error) {
+	if _, err := s.Dynamo.GetItemAttributes(ctx, models.KeyBundlesTable, keyBundleKey(userHandle, deviceID), "deviceId"); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return 0, notFoundError("device has not published a key bundle")
+		}
+		return 0, err
+	}
+	if err := s.putPreKeys(ctx, userHandle, deviceID, preKeys); err != nil {
+		return 0, err
+	}
+
+	utils.Logf(ctx, "🔑 %s added %d prekeys for device %s", userHandle, len(preKeys), deviceID)
+	return s.PreKeyCount(ctx, userHandle, deviceID)
+}
+
+// PreKeyCount returns how many unclaimed one-time prekeys a device has left
+func (s *KeyService) PreKeyCount(ctx context.Context, userHandle, deviceID string) (int, error) {
+	count, err := s.Dynamo.CountItems(ctx, s.preKeyQuery(userHandle, deviceID))
+	if err != nil {
+		return 0, fmt.Errorf("failed to count prekeys of %s: %w", userHandle, err)
+	}
+	return count, nil
+}
+
+// FetchBundles returns a bundle for every device of peer, each with a freshly claimed one-time
+// prekey while the device has any. Only the user's own devices and matches can be fetched, and
+// fetches count against a daily quota so no one can drain a peer's prekeys.
+func (s *KeyService) FetchBundles(ctx context.Context, userHandle, peer string) ([]models.KeyBundle, error) {
+	if userHandle != peer {
+		matched, err := s.areMatched(ctx, userHandle, peer)
+		if err != nil {
+			return nil, err
+		}
+		if !matched || s.Blocks.IsBlocked(ctx, userHandle, peer) {
+			return nil, notFoundError("no keys published for this user")
+		}
+	}
+	if err := reserveDailyQuota(ctx, s.Dynamo, userHandle, models.QuotaDailyKeyFetches, models.DailyKeyFetchLimit,
+		fmt.Sprintf("You can fetch keys %d times per day. Try again tomorrow.", models.DailyKeyFetchLimit)); err != nil {
+		return nil, err
+	}
+	bundles, err := s.deviceBundles(ctx, peer)
+	if err != nil {
+		return nil, err
+	}
+	if len(bundles) == 0 {
+		return nil, notFoundError("no keys published for this user")
+	}
+
+	for i := range bundles {
+		preKey, err := s.claimPreKey(ctx, peer, bundles[i].DeviceID)
+		if err != nil {
+			return nil, err
+		}
+		bundles[i].OneTimePreKey = preKey
+	}
+
+	utils.Logf(ctx, "🔑 %s fetched %d key bundles of %s", userHandle, len(bundles), peer)
+	return bundles, nil
+}
+
+// RemoveDevice deletes a device's bundle and prekeys, e.g. on logout or when the device is lost
+func (s *KeyService) RemoveDevice(ctx context.Context, userHandle, deviceID string) error {
+	_, err := s.Dynamo.Client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(models.KeyBundlesTable),
+		Key:                 keyBundleKey(userHandle, deviceID),
+		ConditionExpression: aws.String("attribute_exists(deviceId)"),
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return notFoundError("device has not published a key bundle")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove key bundle: %w", err)
+	}
+	if err := s.deletePreKeys(ctx, userHandle, deviceID); err != nil {
+		return err
+	}
+
+	utils.Logf(ctx, "🗑️ Removed keys of %s device %s", userHandle, deviceID)
+	return nil
+}
+
+// areMatched reports whether userHandle's interaction with peer is a match
+func (s *KeyService) areMatched(ctx context.Context, userHandle, peer string) (bool, error) {
+	item, err := s.Dynamo.GetItemAttributes(ctx, models.InteractionsTable, interactionKey(userHandle, peer), "status")
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to look up match: %w", err)
+	}
+	status, _ := item["status"].(*types.AttributeValueMemberS)
+	return status != nil && status.Value == models.StatusMatch, nil
+}
+
+// deviceBundles returns the bundles of every device of the user
+func (s *KeyService) deviceBundles(ctx context.Context, userHandle string) ([]models.KeyBundle, error) {
+	items, err := s.Dynamo.QueryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.KeyBundlesTable),
+		KeyConditionExpression: aws.String("userhandle = :handle"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":handle": &types.AttributeValueMemberS{Value: userHandle},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query key bundles of %s: %w", userHandle, err)
+	}
+	bundles := []models.KeyBundle{}
+	if err := attributevalue.UnmarshalListOfMaps(items, &bundles); err != nil {
+		return nil, fmt.Errorf("failed to parse key bundles: %w", err)
+	}
+	return bundles, nil
+}
+
+// claimPreKey takes the device's oldest one-time prekey, deleting it so no one else gets it;
+// returns nil once the device has none left (sessions then fall back to the signed prekey)
+func (s *KeyService) claimPreKey(ctx context.Context, userHandle, deviceID string) (*models.OneTimePreKey, error) {
+	for attempt := 0; attempt < preKeyClaimAttempts; attempt++ {
+		input := s.preKeyQuery(userHandle, deviceID)
+		input.Limit = aws.Int32(1)
+		output, err := s.Dynamo.query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query prekeys of %s: %w", userHandle, err)
+		}
+		if len(output.Items) == 0 {
+			return nil, nil
+		}
+		var stored models.StoredOneTimePreKey
+		if err := attributevalue.UnmarshalMap(output.Items[0], &stored); err != nil {
+			return nil, fmt.Errorf("failed to parse prekey: %w", err)
+		}
+
+		_, err = s.Dynamo.Client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+			TableName:           aws.String(models.OneTimePreKeysTable),
+			Key:                 preKeyKey(userHandle, stored.PreKeyID),
+			ConditionExpression: aws.String("attribute_exists(preKeyId)"),
+		})
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			continue // ✅ Claimed by a concurrent fetch; take the next one
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to claim prekey: %w", err)
+		}
+		return &models.OneTimePreKey{KeyID: stored.KeyID, PublicKey: stored.PublicKey}, nil
+	}
+	utils.Logf(ctx, "⚠️ Could not claim a prekey of %s device %s; returning the bundle without one", userHandle, deviceID)
+	return nil, nil
+}
+
+// putPreKeys stores one-time prekeys; a re-uploaded key id replaces the stored key
+func (s *KeyService) putPreKeys(ctx context.Context, userHandle, deviceID string, preKeys []models.OneTimePreKey) error {
+	requests := make([]types.WriteRequest, 0, len(preKeys))
+	seen := make(map[int]bool, len(preKeys))
+	for _, preKey := range preKeys {
+		if seen[preKey.KeyID] {
+			continue // ✅ A batch can't write the same key twice
+		}
+		seen[preKey.KeyID] = true
+		item, err := attributevalue.MarshalMap(models.StoredOneTimePreKey{
+			UserHandle: userHandle,
+			PreKeyID:   models.OneTimePreKeySortKey(deviceID, preKey.KeyID),
+			DeviceID:   deviceID,
+			KeyID:      preKey.KeyID,
+			PublicKey:  preKey.PublicKey,
+		})
+		if err != nil {
+			return err
+		}
+		requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: item}})
+	}
+	if len(requests) == 0 {
+		return nil
+	}
+	if err := s.Dynamo.BatchWriteItems(ctx, models.OneTimePreKeysTable, requests); err != nil {
+		return fmt.Errorf("failed to store prekeys of %s: %w", userHandle, err)
+	}
+	return nil
+}
+
+// deletePreKeys removes every one-time prekey of a device
+func (s *KeyService) deletePreKeys(ctx context.Context, userHandle, deviceID string) error {
+	input := s.preKeyQuery(userHandle, deviceID)
+	input.ProjectionExpression = aws.String("preKeyId")
+	items, err := s.Dynamo.QueryAll(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to query prekeys of %s: %w", userHandle, err)
+	}
+	requests := make([]types.WriteRequest, 0, len(items))
+	for _, item := range items {
+		if id, ok := item["preKeyId"].(*types.AttributeValueMemberS); ok {
+			requests = append(requests, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: preKeyKey(userHandle, id.Value)}})
+		}
+	}
+	if len(requests) == 0 {
+		return nil
+	}
+	if err := s.Dynamo.BatchWriteItems(ctx, models.OneTimePreKeysTable, requests); err != nil {
+		return fmt.Errorf("failed to delete prekeys of %s: %w", userHandle, err)
+	}
+	return nil
+}
+
+// preKeyQuery selects a device's one-time prekeys, oldest first
+func (s *KeyService) preKeyQuery(userHandle, deviceID string) *dynamodb.QueryInput {
+	return &dynamodb.QueryInput{
+		TableName:              aws.String(models.OneTimePreKeysTable),
+		KeyConditionExpression: aws.String("userhandle = :handle AND begins_with(preKeyId, :device)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":handle": &types.AttributeValueMemberS{Value: userHandle},
+			":device": &types.AttributeValueMemberS{Value: deviceID + "#"},
+		},
+	}
+}
+
+// keyBundleKey builds the KeyBundles primary key
+func keyBundleKey(userHandle, deviceID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"userhandle": &types.AttributeValueMemberS{Value: userHandle},
+		"deviceId":   &types.AttributeValueMemberS{Value: deviceID},
+	}
+}
+
+// preKeyKey builds the OneTimePreKeys primary key
+func preKeyKey(userHandle, preKeyID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"userhandle": &types.AttributeValueMemberS{Value: userHandle},
+		"preKeyId":   &types.AttributeValueMemberS{Value: preKeyID},
+	}
+}
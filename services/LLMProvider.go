@@ -0,0 +1,105 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// LLMProvider completes a prompt with a large language model. Implementations must treat the
+// prompt as user data: it is sent to a third party and never logged.
+type LLMProvider interface {
+	Complete(ctx context.Context, system, prompt string) (string, error)
+}
+
+// OpenAICompatibleProvider calls a chat completions API in the OpenAI format (OpenAI, Azure
+// OpenAI, and most self-hosted model servers)
+type OpenAICompatibleProvider struct {
+	Endpoint   string // Full chat completions URL
+	APIKey     string // Sent as a bearer token when set
+	Model      string
+	HTTPClient *http.Client
+	Greedy     bool // Sample at temperature 0 (classification) instead of 0.9 (varied suggestions)
+}
+
+// chatMessage is one message of a chat completions request or response
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Complete sends the system and user prompts and returns the first choice's text
+func (p *OpenAICompatibleProvider) Complete(ctx context.Context, system, prompt string) (string, error) {
+	temperature := 0.9
+	if p.Greedy {
+		temperature = 0
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"model": p.Model,
+		"messages": []chatMessage{
+			{Role: "system", Content: system},
+			{Role: "user", Content: prompt},
+		},
+		"temperature": temperature,
+	})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.APIKey)
+	}
+
+	client := p.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 20 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("llm request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read llm response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("llm returned %d", resp.StatusCode)
+	}
+	var completion struct {
+		Choices []struct {
+			Message chatMessage `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(raw, &completion); err != nil {
+		return "", fmt.Errorf("failed to decode llm response: %w", err)
+	}
+	if len(completion.Choices) == 0 {
+		return "", fmt.Errorf("llm returned no choices")
+	}
+	return completion.Choices[0].Message.Content, nil
+}
+
+// parseStringArray reads the JSON array of strings a prompt asked for; models sometimes wrap it in
+// prose or code fences
+func parseStringArray(completion string) ([]string, error) {
+	start, end := strings.Index(completion, "["), strings.LastIndex(completion, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no JSON array in completion")
+	}
+	var values []string
+	if err := json.Unmarshal([]byte(completion[start:end+1]), &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
@@ -0,0 +1,394 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ErrNoStepUpPending is returned when verifying a session that isn't waiting for a code (never
+// started, already verified, expired or discarded after too many wrong codes)
+var ErrNoStepUpPending = notFoundError("no verification is pending for this session")
+
+// signInRisk compares a sign-in with the user's verified sessions: a device or country none of them
+// used is risky, and so is a first sign-in with nothing to compare with. The risk is recorded in the
+// sign-in audit; every sign-in needs a code either way.
+func (s *SessionService) signInRisk(ctx context.Context, request models.SessionRequest, country string) (string, error) {
+	sessions, err := s.ListSessions(ctx, request.UserHandle, "")
+	if err != nil {
+		return "", err
+	}
+	if len(sessions) == 0 {
+		return models.LoginRiskFirstSignIn, nil
+	}
+
+	knownDevice, knownCountry, countriesSeen := false, false, false
+	for _, session := range sessions {
+		if request.DeviceID != "" {
+			knownDevice = knownDevice || session.DeviceID == request.DeviceID
+		} else {
+			knownDevice = knownDevice || (session.Platform == request.Platform && session.DeviceModel == request.DeviceModel)
+		}
+		if session.Country != "" {
+			countriesSeen = true
+			knownCountry = knownCountry || session.Country == country
+		}
+	}
+	switch {
+	case !knownDevice:
+		return models.LoginRiskNewDevice, nil
+	case country != "" && countriesSeen && !knownCountry:
+		return models.LoginRiskNewCountry, nil
+	}
+	return "", nil
+}
+
+// startStepUp stores the session as pending and sends the user a one-time code for it, by email
+// when the account has one and a sender is configured, else by SMS
+func (s *SessionService) startStepUp(ctx context.Context, session models.Session, profile models.UserProfile, risk string, entry models.LoginAuditEntry, now time.Time) (*models.NewSession, error) {
+	method := ""
+	switch {
+	case s.Email != nil && profile.EmailID != "":
+		method = models.StepUpEmail
+	case s.SMS != nil && profile.PhoneNumber != "":
+		method = models.StepUpSMS
+	default:
+		entry.Decision = models.LoginDecisionStepUpFailed
+		entry.Reason = "no_code_channel"
+		s.audit(ctx, entry)
+		return nil, ErrSignInUnavailable
+	}
+	if err := s.countCodeSend(ctx, now, session.UserHandle, ipSubject(session.LastIP)); err != nil {
+		entry.Decision = models.LoginDecisionLockedOut
+		entry.Reason = models.QuotaCodeSends
+		s.audit(ctx, entry)
+		return nil, err
+	}
+
+	codeValue, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return nil, err
+	}
+	code := fmt.Sprintf("%06d", codeValue.Int64())
+	verifyBy := now.Add(models.StepUpCodeTTL)
+
+	session.Pending = true
+	session.CodeHash = stepUpCodeHash(session.SessionID, code)
+	session.ExpiresAt = verifyBy.Unix() // ✅ Unverified sessions are cleaned up by TTL
+	if err := s.Dynamo.PutItem(ctx, models.SessionsTable, session); err != nil {
+		return nil, fmt.Errorf("failed to store pending session: %w", err)
+	}
+
+	if method == models.StepUpEmail {
+		body := fmt.Sprintf("Your Vibin sign-in code is %s. It expires in %d minutes.\n\nIf you didn't try to sign in, you can ignore this email.", code, int(models.StepUpCodeTTL.Minutes()))
+		err = s.Email.SendEmail(ctx, profile.EmailID, "Your Vibin sign-in code", body)
+	} else {
+		err = s.SMS.SendSMS(ctx, profile.PhoneNumber, fmt.Sprintf("Your Vibin sign-in code is %s. It expires in %d minutes.", code, int(models.StepUpCodeTTL.Minutes())))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to send sign-in code: %w", err)
+	}
+
+	entry.Decision = models.LoginDecisionStepUpRequired
+	entry.Reason = risk
+	s.audit(ctx, entry)
+
+	utils.Logf(ctx, "🔐 Sign-in of %s needs a code sent by %s (session %s)", session.UserHandle, method, session.SessionID)
+	return &models.NewSession{Session: session, StepUp: method, VerifyBy: verifyBy.Format(time.RFC3339)}, nil
+}
+
+// VerifySession checks the code sent for a pending session and issues its token. Wrong codes
+// count as sign-in failures; after MaxStepUpCodeAttempts the pending session is discarded.
+func (s *SessionService) VerifySession(ctx context.Context, userHandle, sessionID, code, ip string) (*models.NewSession, error) {
+	if err := s.checkLockout(ctx, userHandle, ipSubject(ip)); err != nil {
+		s.audit(ctx, models.LoginAuditEntry{UserHandle: userHandle, Decision: models.LoginDecisionLockedOut, SessionID: sessionID, IP: ip})
+		return nil, err
+	}
+
+	item, err := s.Dynamo.GetItem(ctx, models.SessionsTable, sessionKey(sessionID))
+	if errors.Is(err, ErrNotFound) {
+		return nil, ErrNoStepUpPending
+	}
+	if err != nil {
+		return nil, err
+	}
+	var session models.Session
+	if err := attributevalue.UnmarshalMap(item, &session); err != nil {
+		return nil, fmt.Errorf("failed to parse session: %w", err)
+	}
+	now := time.Now().UTC()
+	if !session.Pending || session.UserHandle != userHandle || now.Unix() >= session.ExpiresAt {
+		return nil, ErrNoStepUpPending
+	}
+	entry := models.LoginAuditEntry{
+		UserHandle:  userHandle,
+		SessionID:   sessionID,
+		IP:          ip,
+		Country:     session.Country,
+		Platform:    session.Platform,
+		DeviceModel: session.DeviceModel,
+		DeviceID:    session.DeviceID,
+	}
+
+	if subtle.ConstantTimeCompare([]byte(session.CodeHash), []byte(stepUpCodeHash(sessionID, code))) != 1 {
+		s.recordLoginFailure(ctx, userHandle, ipSubject(ip))
+		entry.Decision = models.LoginDecisionStepUpFailed
+		entry.Reason = "wrong_code"
+		s.audit(ctx, entry)
+		if err := s.countWrongCode(ctx, sessionID); err != nil {
+			utils.Logf(ctx, "⚠️ Failed to count wrong code for session %s: %v", sessionID, err)
+		}
+		return nil, validationError("incorrect code")
+	}
+
+	token, err := s.issueToken(&session, now)
+	if err != nil {
+		return nil, err
+	}
+	session.Pending, session.CodeHash, session.CodeAttempts = false, "", 0
+	session.LastSeenAt, session.LastIP = now.Format(time.RFC3339), ip
+	_, err = s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(models.SessionsTable),
+		Key:                 sessionKey(sessionID),
+		UpdateExpression:    aws.String("SET tokenHash = :tokenHash, expiresAt = :expiresAt, lastSeenAt = :now, lastIp = :ip REMOVE pending, codeHash, codeAttempts"),
+		ConditionExpression: aws.String("codeHash = :codeHash"), // ✅ Each code is used once
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":tokenHash": &types.AttributeValueMemberS{Value: session.TokenHash},
+			":expiresAt": &types.AttributeValueMemberN{Value: strconv.FormatInt(session.ExpiresAt, 10)},
+			":now":       &types.AttributeValueMemberS{Value: session.LastSeenAt},
+			":ip":        &types.AttributeValueMemberS{Value: ip},
+			":codeHash":  &types.AttributeValueMemberS{Value: stepUpCodeHash(sessionID, code)},
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return nil, ErrNoStepUpPending
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to activate session %s: %w", sessionID, err)
+	}
+
+	entry.Decision = models.LoginDecisionStepUpPassed
+	s.audit(ctx, entry)
+
+	utils.Logf(ctx, "🔑 %s verified session %s", userHandle, sessionID)
+	return &models.NewSession{Session: session, Token: token}, nil
+}
+
+// countWrongCode records a wrong code on a pending session and discards the session once
+// MaxStepUpCodeAttempts is reached
+func (s *SessionService) countWrongCode(ctx context.Context, sessionID string) error {
+	output, err := s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(models.SessionsTable),
+		Key:                 sessionKey(sessionID),
+		UpdateExpression:    aws.String("ADD codeAttempts :one"),
+		ConditionExpression: aws.String("attribute_exists(pending)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":one": &types.AttributeValueMemberN{Value: "1"},
+		},
+		ReturnValues: types.ReturnValueUpdatedNew,
+	})
+	if err != nil {
+		return err
+	}
+	var updated struct {
+		CodeAttempts int `dynamodbav:"codeAttempts"`
+	}
+	if err := attributevalue.UnmarshalMap(output.Attributes, &updated); err != nil {
+		return err
+	}
+	if updated.CodeAttempts < models.MaxStepUpCodeAttempts {
+		return nil
+	}
+	_, err = s.Dynamo.Client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(models.SessionsTable),
+		Key:                 sessionKey(sessionID),
+		ConditionExpression: aws.String("attribute_exists(pending)"),
+	})
+	return err
+}
+
+// checkLockout returns a QuotaError while sign-in is locked for any of the subjects (userhandles
+// or ipSubject addresses)
+func (s *SessionService) checkLockout(ctx context.Context, subjects ...string) error {
+	now := time.Now().UTC()
+	for _, subject := range subjects {
+		item, err := s.Dynamo.GetItem(ctx, models.QuotaCountersTable, loginCounterKey(subject, models.QuotaLoginLockout))
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to check sign-in lockout: %w", err)
+		}
+		var lockout struct {
+			LockedUntil string `dynamodbav:"lockedUntil"`
+		}
+		if err := attributevalue.UnmarshalMap(item, &lockout); err != nil {
+			continue
+		}
+		if until, err := time.Parse(time.RFC3339, lockout.LockedUntil); err == nil && now.Before(until) {
+			utils.Logf(ctx, "🔒 Sign-in locked for %s until %s", subject, lockout.LockedUntil)
+			return &QuotaError{
+				Message: "Too many failed sign-in attempts. Try again later.",
+				Quota:   models.QuotaLoginLockout,
+				RetryAt: until,
+			}
+		}
+	}
+	return nil
+}
+
+// countCodeSend counts a sign-in code send against each subject in the current window, returning a
+// QuotaError (without counting further subjects) once one of them is at its limit
+func (s *SessionService) countCodeSend(ctx context.Context, now time.Time, subjects ...string) error {
+	window := now.Truncate(models.CodeSendWindow)
+	for _, subject := range subjects {
+		limit := models.MaxUserCodeSends
+		if isIPSubject(subject) {
+			limit = models.MaxIPCodeSends
+		}
+		_, err := s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+			TableName:           aws.String(models.QuotaCountersTable),
+			Key:                 loginCounterKey(subject, models.QuotaCodeSends+"#"+window.Format(time.RFC3339)),
+			UpdateExpression:    aws.String("SET expiresAt = :expiresAt ADD #count :one"),
+			ConditionExpression: aws.String("attribute_not_exists(#count) OR #count < :limit"),
+			ExpressionAttributeNames: map[string]string{
+				"#count": "count",
+			},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":one":       &types.AttributeValueMemberN{Value: "1"},
+				":limit":     &types.AttributeValueMemberN{Value: strconv.Itoa(limit)},
+				":expiresAt": &types.AttributeValueMemberN{Value: strconv.FormatInt(window.Add(2*models.CodeSendWindow).Unix(), 10)},
+			},
+		})
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			utils.Logf(ctx, "🔒 Sign-in code sends for %s reached %d this window", subject, limit)
+			return &QuotaError{
+				Message: "Too many sign-in codes were requested. Try again later.",
+				Quota:   models.QuotaCodeSends,
+				Limit:   limit,
+				RetryAt: window.Add(models.CodeSendWindow),
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to count sign-in code send: %w", err)
+		}
+	}
+	return nil
+}
+
+// recordLoginFailure counts a failure against each subject in the current window and locks sign-in
+// for subjects that reach their limit; failures are logged and never fail the request
+func (s *SessionService) recordLoginFailure(ctx context.Context, subjects ...string) {
+	now := time.Now().UTC()
+	window := now.Truncate(models.LoginFailureWindow)
+	for _, subject := range subjects {
+		limit := models.MaxUserLoginFailures
+		if isIPSubject(subject) {
+			limit = models.MaxIPLoginFailures
+		}
+		output, err := s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+			TableName:        aws.String(models.QuotaCountersTable),
+			Key:              loginCounterKey(subject, models.QuotaLoginFailures+"#"+window.Format(time.RFC3339)),
+			UpdateExpression: aws.String("SET expiresAt = :expiresAt ADD #count :one"),
+			ExpressionAttributeNames: map[string]string{
+				"#count": "count",
+			},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":one":       &types.AttributeValueMemberN{Value: "1"},
+				":expiresAt": &types.AttributeValueMemberN{Value: strconv.FormatInt(window.Add(2*models.LoginFailureWindow).Unix(), 10)},
+			},
+			ReturnValues: types.ReturnValueUpdatedNew,
+		})
+		if err != nil {
+			utils.Logf(ctx, "⚠️ Failed to count sign-in failure for %s: %v", subject, err)
+			continue
+		}
+		var counter models.QuotaCounter
+		if err := attributevalue.UnmarshalMap(output.Attributes, &counter); err != nil || counter.Count < limit {
+			continue
+		}
+
+		lockedUntil := now.Add(models.LoginLockoutDuration)
+		_, err = s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+			TableName:        aws.String(models.QuotaCountersTable),
+			Key:              loginCounterKey(subject, models.QuotaLoginLockout),
+			UpdateExpression: aws.String("SET lockedUntil = :lockedUntil, expiresAt = :expiresAt"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":lockedUntil": &types.AttributeValueMemberS{Value: lockedUntil.Format(time.RFC3339)},
+				":expiresAt":   &types.AttributeValueMemberN{Value: strconv.FormatInt(lockedUntil.Add(time.Hour).Unix(), 10)},
+			},
+		})
+		if err != nil {
+			utils.Logf(ctx, "⚠️ Failed to lock sign-in for %s: %v", subject, err)
+			continue
+		}
+		utils.Logf(ctx, "🔒 Locked sign-in for %s after %d failures", subject, counter.Count)
+	}
+}
+
+// audit records a sign-in decision; failures are logged and never fail the request
+func (s *SessionService) audit(ctx context.Context, entry models.LoginAuditEntry) {
+	now := time.Now().UTC()
+	entry.CreatedAt = now.Format(time.RFC3339Nano)
+	entry.ExpiresAt = now.AddDate(0, 0, models.LoginAuditRetentionDays).Unix()
+	if err := s.Dynamo.PutItem(ctx, models.LoginAuditTable, entry); err != nil {
+		utils.Logf(ctx, "⚠️ Failed to audit %s sign-in of %s: %v", entry.Decision, entry.UserHandle, err)
+	}
+}
+
+// ListLoginAudit returns one page of the user's sign-in decisions, newest first
+func (s *SessionService) ListLoginAudit(ctx context.Context, userHandle string, limit int32, cursor string) ([]models.LoginAuditEntry, string, error) {
+	items, nextCursor, err := s.Dynamo.QueryPage(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.LoginAuditTable),
+		KeyConditionExpression: aws.String("userhandle = :handle"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":handle": &types.AttributeValueMemberS{Value: userHandle},
+		},
+		ScanIndexForward: aws.Bool(false),
+	}, limit, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	entries := []models.LoginAuditEntry{}
+	if err := attributevalue.UnmarshalListOfMaps(items, &entries); err != nil {
+		return nil, "", fmt.Errorf("failed to parse sign-in audit: %w", err)
+	}
+	return entries, nextCursor, nil
+}
+
+// ipSubject is the QuotaCounters partition of a client IP
+func ipSubject(ip string) string { return "ip#" + ip }
+
+// isIPSubject reports whether subject is an ipSubject rather than a userhandle
+func isIPSubject(subject string) bool { return len(subject) > 3 && subject[:3] == "ip#" }
+
+// loginCounterKey builds the QuotaCounters key of a subject's sign-in counter
+func loginCounterKey(subject, counter string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"userhandle": &types.AttributeValueMemberS{Value: subject},
+		"counter":    &types.AttributeValueMemberS{Value: counter},
+	}
+}
+
+// stepUpCodeHash is what is stored in place of a session's step-up code
+func stepUpCodeHash(sessionID, code string) string {
+	sum := sha256.Sum256([]byte(sessionID + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
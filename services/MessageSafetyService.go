@@ -0,0 +1,386 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// MessageClassifier scores a message against the safety categories. It returns nil when the
+// message violates none of them.
+type MessageClassifier interface {
+	Classify(ctx context.Context, text string) (*models.SafetyClassification, error)
+}
+
+// safetySystemPrompt tells the model what to look for and how to answer
+const safetySystemPrompt = `You review messages sent between adults on a dating app for trust & safety. You get one message.
+Decide whether it is harassment (threats, slurs, sexual harassment, repeated insults), minor_solicitation (sexual solicitation of, or sexual content about, anyone under 18) or scam (requests for money, gift cards, crypto or investments, moving to another app to be paid, phishing links).
+Flirting and consensual sexual talk between adults is not a violation.
+Answer with a JSON object {"category": "harassment" | "minor_solicitation" | "scam" | "none", "confidence": <0 to 1>} and nothing else.`
+
+// LLMMessageClassifier classifies messages with a large language model
+type LLMMessageClassifier struct {
+	LLM LLMProvider
+}
+
+// Classify asks the model for the message's category and its confidence
+func (c *LLMMessageClassifier) Classify(ctx context.Context, text string) (*models.SafetyClassification, error) {
+	completion, err := c.LLM.Complete(ctx, safetySystemPrompt, text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to classify message: %w", err)
+	}
+	start, end := strings.Index(completion, "{"), strings.LastIndex(completion, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no JSON object in classification")
+	}
+	var verdict models.SafetyClassification
+	if err := json.Unmarshal([]byte(completion[start:end+1]), &verdict); err != nil {
+		return nil, fmt.Errorf("failed to parse classification: %w", err)
+	}
+	if !slices.Contains(models.SafetyCategories, verdict.Category) {
+		return nil, nil
+	}
+	return &verdict, nil
+}
+
+// MessageSafetyService screens new match and group messages after they are stored. Flagged messages
+// get a safety banner for the recipients and a review queue entry; high-confidence violations are
+// also hidden until an admin decides. End-to-end encrypted messages can't be read and are skipped.
+type MessageSafetyService struct {
+	Dynamo     *DynamoService
+	Chat       *ChatService
+	GroupChat  *GroupChatService
+	Webhooks   *WebhookService
+	Classifier MessageClassifier // ✅ Screening is off when nil; the review queue still works
+	Jobs       *JobQueue         // ✅ Set by RegisterJobs; screening runs inline without it
+}
+
+// Enabled reports whether new messages are screened
+func (s *MessageSafetyService) Enabled() bool {
+	return s != nil && s.Classifier != nil
+}
+
+// Screen schedules the classification of a stored message
+func (s *MessageSafetyService) Screen(ctx context.Context, message models.Message) {
+	if !s.Enabled() || message.E2E != nil || strings.TrimSpace(message.Content) == "" {
+		return
+	}
+	s.schedule(ctx, models.ScreenMessageJob{MatchID: message.MatchID, CreatedAt: message.CreatedAt})
+}
+
+// ScreenGroupMessage schedules the classification of a stored group message
+func (s *MessageSafetyService) ScreenGroupMessage(ctx context.Context, message models.GroupMessage) {
+	if !s.Enabled() || message.MessageType == models.GroupMessageTypeSystem || strings.TrimSpace(message.Content) == "" {
+		return
+	}
+	s.schedule(ctx, models.ScreenMessageJob{GroupID: message.GroupID, CreatedAt: message.CreatedAt})
+}
+
+// schedule queues a screening job, or runs it inline without a queue
+func (s *MessageSafetyService) schedule(ctx context.Context, job models.ScreenMessageJob) {
+	if s.Jobs != nil {
+		s.Jobs.Enqueue(ctx, models.JobScreenMessage, job)
+		return
+	}
+	if err := s.screenMessage(ctx, job); err != nil {
+		utils.Logf(ctx, "⚠️ Failed to screen message %+v: %v", job, err)
+	}
+}
+
+// RegisterJobs moves classification (a model call per message) onto the job queue
+func (s *MessageSafetyService) RegisterJobs(queue *JobQueue) {
+	s.Jobs = queue
+	queue.Handle(models.JobScreenMessage, func(ctx context.Context, payload json.RawMessage) error {
+		job, err := decodeJob[models.ScreenMessageJob](payload)
+		if err != nil {
+			return err
+		}
+		return s.screenMessage(ctx, job)
+	})
+}
+
+// screenMessage classifies one message and flags it at or above SafetyFlagConfidence. The review is
+// queued before the message is flagged, so a retried job finishes the work and an admin's earlier
+// decision stands.
+func (s *MessageSafetyService) screenMessage(ctx context.Context, job models.ScreenMessageJob) error {
+	if !s.Enabled() {
+		return nil
+	}
+	message, err := s.screenedMessage(ctx, job)
+	if errors.Is(err, ErrNotFound) {
+		return nil // ✅ Deleted (or the match expired) before it was screened
+	}
+	if err != nil {
+		return err
+	}
+	if message.E2E != nil || message.SafetyFlag != "" || strings.TrimSpace(message.Content) == "" {
+		return nil
+	}
+
+	verdict, err := s.Classifier.Classify(ctx, message.Content)
+	if err != nil {
+		return err
+	}
+	if verdict == nil || verdict.Confidence < models.SafetyFlagConfidence {
+		return nil
+	}
+
+	review := models.MessageReview{
+		MatchID:    job.MatchID,
+		GroupID:    job.GroupID,
+		CreatedAt:  job.CreatedAt,
+		MessageID:  message.MessageID,
+		SenderID:   message.SenderID,
+		Category:   verdict.Category,
+		Confidence: verdict.Confidence,
+		Hidden:     verdict.Confidence >= models.SafetyHideConfidence,
+		Status:     models.MessageReviewPending,
+		FlaggedAt:  time.Now().UTC().Format(time.RFC3339),
+	}
+	if job.GroupID != "" {
+		review.MatchID = models.GroupReviewPrefix + job.GroupID
+	} else {
+		review.RecipientID, err = findMatchPartner(ctx, s.Dynamo, job.MatchID, message.SenderID)
+		if err != nil && !errors.Is(err, ErrMatchNotFound) {
+			return err
+		}
+	}
+	item, err := attributevalue.MarshalMap(review)
+	if err != nil {
+		return err
+	}
+	_, err = s.Dynamo.Client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                           aws.String(models.MessageReviewsTable),
+		Item:                                item,
+		ConditionExpression:                 aws.String("attribute_not_exists(matchId)"),
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		var existing models.MessageReview
+		if err := attributevalue.UnmarshalMap(conditionFailed.Item, &existing); err != nil {
+			return fmt.Errorf("failed to parse message review: %w", err)
+		}
+		if existing.Status != models.MessageReviewPending {
+			return nil
+		}
+		review = existing
+	} else if err != nil {
+		return fmt.Errorf("failed to queue review of message %s: %w", message.MessageID, err)
+	}
+
+	if err := s.setFlag(ctx, review, review.Hidden); err != nil {
+		return err
+	}
+	event := map[string]interface{}{
+		"senderHandle": review.SenderID,
+		"reason":       "safety_classifier",
+		"category":     review.Category,
+		"confidence":   review.Confidence,
+		"hidden":       review.Hidden,
+	}
+	if review.GroupID != "" {
+		event["groupId"] = review.GroupID
+	} else {
+		event["recipientHandle"], event["matchId"] = review.RecipientID, review.MatchID
+	}
+	s.Webhooks.Publish(ctx, models.WebhookMessageFlagged, event)
+
+	utils.Logf(ctx, "🚫 Flagged message %s from %s as %s (%.2f, hidden: %t)", review.MessageID, review.SenderID, review.Category, review.Confidence, review.Hidden)
+	return nil
+}
+
+// ListReviews returns one page of reviews in the given status, oldest first, with each message's content
+func (s *MessageSafetyService) ListReviews(ctx context.Context, status string, limit int32, cursor string) ([]models.MessageReview, string, error) {
+	items, nextCursor, err := s.Dynamo.QueryPage(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.MessageReviewsTable),
+		IndexName:              aws.String(models.MessageReviewStatusIndex),
+		KeyConditionExpression: aws.String("#status = :status"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":status": &types.AttributeValueMemberS{Value: status},
+		},
+	}, limit, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	reviews := []models.MessageReview{}
+	if err := attributevalue.UnmarshalListOfMaps(items, &reviews); err != nil {
+		return nil, "", fmt.Errorf("failed to parse message reviews: %w", err)
+	}
+	for i := range reviews {
+		message, err := s.screenedMessage(ctx, models.ScreenMessageJob{MatchID: reviews[i].MatchID, GroupID: reviews[i].GroupID, CreatedAt: reviews[i].CreatedAt})
+		if err != nil {
+			if !errors.Is(err, ErrNotFound) {
+				utils.Logf(ctx, "⚠️ Failed to load flagged message %s: %v", reviews[i].MessageID, err)
+			}
+			continue
+		}
+		reviews[i].Content = message.Content
+	}
+	return reviews, nextCursor, nil
+}
+
+// ResolveReview records an admin's decision on a pending review. Upheld messages stay (or become)
+// hidden; dismissed ones lose their banner and are shown again. The decision is stored last, so a
+// failed update leaves the review pending to be retried.
+func (s *MessageSafetyService) ResolveReview(ctx context.Context, matchID, createdAt, decision, reviewedBy string) (*models.MessageReview, error) {
+	if decision != models.MessageReviewUpheld && decision != models.MessageReviewDismissed {
+		return nil, validationError("decision must be upheld or dismissed")
+	}
+
+	key := map[string]types.AttributeValue{
+		"matchId":   &types.AttributeValueMemberS{Value: matchID},
+		"createdAt": &types.AttributeValueMemberS{Value: createdAt},
+	}
+	item, err := s.Dynamo.GetItem(ctx, models.MessageReviewsTable, key)
+	if errors.Is(err, ErrNotFound) {
+		return nil, notFoundError("message review not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	var review models.MessageReview
+	if err := attributevalue.UnmarshalMap(item, &review); err != nil {
+		return nil, fmt.Errorf("failed to parse message review: %w", err)
+	}
+	if review.Status != models.MessageReviewPending {
+		return nil, conflictError("message review was already resolved")
+	}
+
+	if decision == models.MessageReviewUpheld {
+		err = s.setFlag(ctx, review, true)
+	} else {
+		err = s.clearFlag(ctx, review)
+	}
+	if err != nil && !errors.Is(err, ErrNotFound) { // ✅ The message was deleted meanwhile
+		return nil, err
+	}
+
+	review.Status = decision
+	review.ReviewedAt = time.Now().UTC().Format(time.RFC3339)
+	review.ReviewedBy = reviewedBy
+	_, err = s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(models.MessageReviewsTable),
+		Key:                 key,
+		UpdateExpression:    aws.String("SET #status = :decision, reviewedAt = :now, reviewedBy = :reviewedBy"),
+		ConditionExpression: aws.String("#status = :pending"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":decision":   &types.AttributeValueMemberS{Value: decision},
+			":pending":    &types.AttributeValueMemberS{Value: models.MessageReviewPending},
+			":now":        &types.AttributeValueMemberS{Value: review.ReviewedAt},
+			":reviewedBy": &types.AttributeValueMemberS{Value: reviewedBy},
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return nil, conflictError("message review was already resolved")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve review of message %s: %w", review.MessageID, err)
+	}
+
+	utils.Logf(ctx, "✅ Flagged message %s from %s %s by %s", review.MessageID, review.SenderID, decision, reviewedBy)
+	return &review, nil
+}
+
+// screenedMessage reads the message a screening job (or review) is about, with its content decrypted
+// even when hidden. Group messages are returned in the shape of a match message without a matchId.
+func (s *MessageSafetyService) screenedMessage(ctx context.Context, job models.ScreenMessageJob) (*models.Message, error) {
+	table, key := messageKey(job.MatchID, job.GroupID, job.CreatedAt)
+	item, err := s.Dynamo.GetItem(ctx, table, key)
+	if err != nil {
+		return nil, err
+	}
+	if job.GroupID == "" {
+		var message models.Message
+		if err := attributevalue.UnmarshalMap(item, &message); err != nil {
+			return nil, fmt.Errorf("failed to parse message: %w", err)
+		}
+		s.Chat.decryptContent(ctx, &message)
+		return &message, nil
+	}
+
+	var message models.GroupMessage
+	if err := attributevalue.UnmarshalMap(item, &message); err != nil {
+		return nil, fmt.Errorf("failed to parse group message: %w", err)
+	}
+	s.GroupChat.decryptGroupContent(ctx, &message)
+	return &models.Message{
+		CreatedAt:  message.CreatedAt,
+		MessageID:  message.MessageID,
+		SenderID:   message.SenderID,
+		Content:    message.Content,
+		SafetyFlag: message.SafetyFlag,
+		Hidden:     message.Hidden,
+	}, nil
+}
+
+// messageKey locates a match message, or a group message when groupID is set
+func messageKey(matchID, groupID, createdAt string) (string, map[string]types.AttributeValue) {
+	if groupID != "" {
+		return models.GroupMessageTable, map[string]types.AttributeValue{
+			"groupId":   &types.AttributeValueMemberS{Value: groupID},
+			"createdAt": &types.AttributeValueMemberS{Value: createdAt},
+		}
+	}
+	return models.MessagesTable, map[string]types.AttributeValue{
+		"matchId":   &types.AttributeValueMemberS{Value: matchID},
+		"createdAt": &types.AttributeValueMemberS{Value: createdAt},
+	}
+}
+
+// setFlag marks a reviewed message with its safety category, hiding it when asked; it never un-hides
+func (s *MessageSafetyService) setFlag(ctx context.Context, review models.MessageReview, hidden bool) error {
+	update := "SET safetyFlag = :category"
+	values := map[string]types.AttributeValue{
+		":category": &types.AttributeValueMemberS{Value: review.Category},
+	}
+	if hidden {
+		update += ", hidden = :hidden"
+		values[":hidden"] = &types.AttributeValueMemberBOOL{Value: true}
+	}
+	return s.updateMessage(ctx, review, update, values)
+}
+
+// clearFlag removes the banner and shows the message again
+func (s *MessageSafetyService) clearFlag(ctx context.Context, review models.MessageReview) error {
+	return s.updateMessage(ctx, review, "REMOVE safetyFlag, hidden", nil)
+}
+
+// updateMessage applies an update to the existing message a review is about; a missing message is not found
+func (s *MessageSafetyService) updateMessage(ctx context.Context, review models.MessageReview, update string, values map[string]types.AttributeValue) error {
+	table, key := messageKey(review.MatchID, review.GroupID, review.CreatedAt)
+	_, err := s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(table),
+		Key:                       key,
+		UpdateExpression:          aws.String(update),
+		ConditionExpression:       aws.String("attribute_exists(createdAt)"),
+		ExpressionAttributeValues: values,
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update safety flag of message %s: %w", review.MessageID, err)
+	}
+	return nil
+}
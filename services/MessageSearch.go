@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"vibin_server/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// searchHit is a matching message and its neighbours, oldest first on both sides
+type searchHit[T any] struct {
+	message T
+	before  []T
+	after   []T
+}
+
+// conversationSearch describes where a conversation's messages live and how to read them
+type conversationSearch[T any] struct {
+	dynamo  *DynamoService
+	table   string
+	pkAttr  string // Partition key attribute (matchId / groupId)
+	pkValue string
+	decrypt func(*T)
+	content func(*T) string
+}
+
+// run narrows the conversation to the date range with a key condition on createdAt, then matches
+// the keyword in memory. Content may be encrypted at rest and DynamoDB's contains() is
+// case-sensitive, so the keyword cannot be pushed down as a filter expression.
+func (c conversationSearch[T]) run(ctx context.Context, query models.MessageSearchQuery) ([]searchHit[T], error) {
+	keyCondition := "#pk = :pk"
+	values := map[string]types.AttributeValue{
+		":pk": &types.AttributeValueMemberS{Value: c.pkValue},
+	}
+	names := map[string]string{"#pk": c.pkAttr}
+
+	switch {
+	case query.From != "" && query.To != "":
+		keyCondition += " AND #createdAt BETWEEN :from AND :to"
+		values[":from"] = &types.AttributeValueMemberS{Value: query.From}
+		values[":to"] = &types.AttributeValueMemberS{Value: query.To}
+		names["#createdAt"] = "createdAt"
+	case query.From != "":
+		keyCondition += " AND #createdAt >= :from"
+		values[":from"] = &types.AttributeValueMemberS{Value: query.From}
+		names["#createdAt"] = "createdAt"
+	case query.To != "":
+		keyCondition += " AND #createdAt <= :to"
+		values[":to"] = &types.AttributeValueMemberS{Value: query.To}
+		names["#createdAt"] = "createdAt"
+	}
+
+	items, err := c.dynamo.QueryAll(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(c.table),
+		KeyConditionExpression:    aws.String(keyCondition),
+		ExpressionAttributeValues: values,
+		ExpressionAttributeNames:  names,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query messages: %w", err)
+	}
+
+	messages, err := c.unmarshal(items)
+	if err != nil {
+		return nil, err
+	}
+
+	// ✅ Walk newest → oldest so the limit keeps the most recent hits
+	keyword := strings.ToLower(query.Keyword)
+	var hitIndexes []int
+	for i := len(messages) - 1; i >= 0 && len(hitIndexes) < query.Limit; i-- {
+		if strings.Contains(strings.ToLower(c.content(&messages[i])), keyword) {
+			hitIndexes = append(hitIndexes, i)
+		}
+	}
+	if len(hitIndexes) == 0 {
+		return nil, nil
+	}
+
+	// ✅ Hits near the edges of the range borrow context from just outside it
+	var earlier, later []T
+	if query.ContextSize > 0 && query.From != "" && hitIndexes[len(hitIndexes)-1] < query.ContextSize {
+		if earlier, err = c.adjacent(ctx, query.From, true, query.ContextSize); err != nil {
+			return nil, err
+		}
+	}
+	if query.ContextSize > 0 && query.To != "" && hitIndexes[0] >= len(messages)-query.ContextSize {
+		if later, err = c.adjacent(ctx, query.To, false, query.ContextSize); err != nil {
+			return nil, err
+		}
+	}
+	window := append(append(earlier, messages...), later...)
+	offset := len(earlier)
+
+	hits := make([]searchHit[T], 0, len(hitIndexes))
+	for _, i := range hitIndexes {
+		pos := i + offset
+		start := max(0, pos-query.ContextSize)
+		end := min(len(window), pos+1+query.ContextSize)
+		hits = append(hits, searchHit[T]{
+			message: window[pos],
+			before:  append([]T{}, window[start:pos]...),
+			after:   append([]T{}, window[pos+1:end]...),
+		})
+	}
+	return hits, nil
+}
+
+// adjacent fetches up to n messages strictly before (or after) a createdAt bound, oldest first
+func (c conversationSearch[T]) adjacent(ctx context.Context, bound string, before bool, n int) ([]T, error) {
+	operator := ">"
+	if before {
+		operator = "<"
+	}
+	keyCondition := "#pk = :pk AND #createdAt " + operator + " :bound"
+	values := map[string]types.AttributeValue{
+		":pk":    &types.AttributeValueMemberS{Value: c.pkValue},
+		":bound": &types.AttributeValueMemberS{Value: bound},
+	}
+	names := map[string]string{"#pk": c.pkAttr, "#createdAt": "createdAt"}
+
+	items, err := c.dynamo.QueryItemsWithOptions(ctx, c.table, keyCondition, values, names, int32(n), before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query context messages: %w", err)
+	}
+	messages, err := c.unmarshal(items)
+	if err != nil {
+		return nil, err
+	}
+
+	// ✅ Messages before the range come back newest first
+	if before {
+		for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+			messages[i], messages[j] = messages[j], messages[i]
+		}
+	}
+	return messages, nil
+}
+
+func (c conversationSearch[T]) unmarshal(items []map[string]types.AttributeValue) ([]T, error) {
+	var messages []T
+	if err := attributevalue.UnmarshalListOfMaps(items, &messages); err != nil {
+		return nil, fmt.Errorf("failed to parse messages: %w", err)
+	}
+	for i := range messages {
+		c.decrypt(&messages[i])
+	}
+	return messages, nil
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ErrContentRejected is returned when content matches an active moderation rule
+var ErrContentRejected = errors.New("content_rejected")
+
+// ErrRuleSetVersionTaken is returned when another admin published the same rules version first
+var ErrRuleSetVersionTaken = conflictError("moderation rules were published concurrently; reload and try again")
+
+// ErrFirstMessageRestricted is matched (via errors.Is) by FirstMessageError
+var ErrFirstMessageRestricted = errors.New("first_message_restricted")
+
+// FirstMessageError explains which first-message limit a message broke
+type FirstMessageError struct {
+	Reason string
+}
+
+func (e *FirstMessageError) Error() string { return e.Reason }
+
+func (e *FirstMessageError) Unwrap() error { return ErrFirstMessageRestricted }
+
+// linkPattern catches URLs and bare domains in first messages
+var linkPattern = regexp.MustCompile(`(?i)(https?://|www\.|\b[a-z0-9-]+\.(com|net|org|io|me|ly|co|app|link|xyz)\b)`)
+
+// ModerationService screens content against DynamoDB-backed rules that are hot-reloaded
+type ModerationService struct {
+	Dynamo *DynamoService
+
+	mu           sync.RWMutex
+	version      int
+	keywords     []*regexp.Regexp
+	patterns     []*regexp.Regexp
+	firstMessage *models.FirstMessagePolicy
+}
+
+// StartRuleReloader loads the latest rules now and then every interval until ctx is cancelled
+func (s *ModerationService) StartRuleReloader(ctx context.Context, interval time.Duration) {
+	if err := s.ReloadRules(ctx); err != nil {
+		utils.Logf(ctx, "⚠️ Initial moderation rule load failed: %v", err)
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.ReloadRules(ctx); err != nil {
+					utils.Logf(ctx, "⚠️ Moderation rule reload failed, keeping version %d: %v", s.CurrentVersion(), err)
+				}
+			}
+		}
+	}()
+}
+
+// ReloadRules fetches the latest rule set and swaps it in if the version changed
+func (s *ModerationService) ReloadRules(ctx context.Context) error {
+	ruleSet, err := s.GetLatestRuleSet(ctx)
+	if err != nil {
+		return err
+	}
+	if ruleSet == nil || ruleSet.Version == s.CurrentVersion() {
+		return nil
+	}
+
+	keywords, patterns, err := compileRules(ruleSet.Keywords, ruleSet.Patterns)
+	if err != nil {
+		return fmt.Errorf("rule set version %d is invalid: %w", ruleSet.Version, err)
+	}
+
+	s.mu.Lock()
+	s.version = ruleSet.Version
+	s.keywords = keywords
+	s.patterns = patterns
+	s.firstMessage = ruleSet.FirstMessage
+	s.mu.Unlock()
+
+	utils.Logf(ctx, "✅ Loaded moderation rules version %d (%d keywords, %d patterns)", ruleSet.Version, len(keywords), len(patterns))
+	return nil
+}
+
+// CurrentVersion returns the version of the rules currently in memory (0 if none loaded)
+func (s *ModerationService) CurrentVersion() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.version
+}
+
+// CheckContent returns ErrContentRejected if the text matches any loaded rule
+func (s *ModerationService) CheckContent(content string) error {
+	if s == nil || content == "" {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, re := range s.keywords {
+		if re.MatchString(content) {
+			return ErrContentRejected
+		}
+	}
+	for _, re := range s.patterns {
+		if re.MatchString(content) {
+			return ErrContentRejected
+		}
+	}
+	return nil
+}
+
+// FirstMessagePolicy returns the loaded first-message policy, or the default if none is published
+func (s *ModerationService) FirstMessagePolicy() models.FirstMessagePolicy {
+	if s == nil {
+		return models.DefaultFirstMessagePolicy()
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.firstMessage == nil {
+		return models.DefaultFirstMessagePolicy()
+	}
+	return *s.firstMessage
+}
+
+// IsRestrictedSender reports whether the first-message policy applies to this account
+func (s *ModerationService) IsRestrictedSender(profile *models.UserProfile) bool {
+	policy := s.FirstMessagePolicy()
+	if policy.ApplyToUnverified && !profile.EmailIDVerified {
+		return true
+	}
+	if policy.NewAccountDays <= 0 || profile.CreatedAt == "" {
+		return false // ✅ Legacy profiles without a creation time count as established
+	}
+	createdAt, err := time.Parse(time.RFC3339, profile.CreatedAt)
+	if err != nil {
+		return false
+	}
+	return time.Since(createdAt) < time.Duration(policy.NewAccountDays)*24*time.Hour
+}
+
+// CheckFirstMessage applies the first-message limits to a message from a restricted sender
+func (s *ModerationService) CheckFirstMessage(content string, hasMedia bool) error {
+	policy := s.FirstMessagePolicy()
+	if policy.BlockImages && hasMedia {
+		return &FirstMessageError{Reason: "New accounts can't send images until the other person replies"}
+	}
+	if policy.MaxLength > 0 && len([]rune(content)) > policy.MaxLength {
+		return &FirstMessageError{Reason: fmt.Sprintf("New accounts can send at most %d characters until the other person replies", policy.MaxLength)}
+	}
+	if policy.BlockLinks && linkPattern.MatchString(content) {
+		return &FirstMessageError{Reason: "New accounts can't send links until the other person replies"}
+	}
+	return nil
+}
+
+// GetLatestRuleSet returns the highest version of the active rule set, or nil if none exists
+func (s *ModerationService) GetLatestRuleSet(ctx context.Context) (*models.ModerationRuleSet, error) {
+	keyCondition := "ruleSetId = :ruleSetId"
+	expressionValues := map[string]types.AttributeValue{
+		":ruleSetId": &types.AttributeValueMemberS{Value: models.ActiveRuleSetID},
+	}
+
+	items, err := s.Dynamo.QueryItemsWithOptions(ctx, models.ModerationRulesTable, keyCondition, expressionValues, nil, 1, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch moderation rules: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	var ruleSet models.ModerationRuleSet
+	if err := attributevalue.UnmarshalMap(items[0], &ruleSet); err != nil {
+		return nil, fmt.Errorf("failed to parse moderation rules: %w", err)
+	}
+	return &ruleSet, nil
+}
+
+// PublishRuleSet validates and stores a new rule set version, then applies it locally
+func (s *ModerationService) PublishRuleSet(ctx context.Context, keywords, patterns []string, firstMessage *models.FirstMessagePolicy, updatedBy string) (*models.ModerationRuleSet, error) {
+	if _, _, err := compileRules(keywords, patterns); err != nil {
+		return nil, err
+	}
+	if firstMessage != nil && (firstMessage.NewAccountDays < 0 || firstMessage.MaxLength < 0) {
+		return nil, validationError("firstMessage limits must not be negative")
+	}
+
+	latest, err := s.GetLatestRuleSet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nextVersion := 1
+	if latest != nil {
+		nextVersion = latest.Version + 1
+	}
+
+	ruleSet := models.ModerationRuleSet{
+		RuleSetID:    models.ActiveRuleSetID,
+		Version:      nextVersion,
+		Keywords:     keywords,
+		Patterns:     patterns,
+		UpdatedBy:    updatedBy,
+		FirstMessage: firstMessage,
+		CreatedAt:    time.Now().Format(time.RFC3339),
+	}
+
+	utils.Logf(ctx, "📝 Publishing moderation rules version %d by %s", nextVersion, updatedBy)
+	item, err := attributevalue.MarshalMap(ruleSet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal moderation rules: %w", err)
+	}
+	// ✅ Versions are never overwritten: of two concurrent publishes, the second is rejected
+	_, err = s.Dynamo.Client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(models.ModerationRulesTable),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(version)"),
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return nil, ErrRuleSetVersionTaken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to store moderation rules: %w", err)
+	}
+
+	if err := s.ReloadRules(ctx); err != nil {
+		utils.Logf(ctx, "⚠️ Published rules but local reload failed: %v", err)
+	}
+	return &ruleSet, nil
+}
+
+// compileRules turns keywords into whole-word, case-insensitive matchers and compiles patterns
+func compileRules(keywords, patterns []string) ([]*regexp.Regexp, []*regexp.Regexp, error) {
+	var compiledKeywords []*regexp.Regexp
+	for _, keyword := range keywords {
+		keyword = strings.TrimSpace(keyword)
+		if keyword == "" {
+			continue
+		}
+		compiledKeywords = append(compiledKeywords, regexp.MustCompile(`(?i)\b`+regexp.QuoteMeta(keyword)+`\b`))
+	}
+
+	var compiledPatterns []*regexp.Regexp
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, nil, validationError(fmt.Sprintf("invalid pattern %q: %v", pattern, err))
+		}
+		compiledPatterns = append(compiledPatterns, re)
+	}
+
+	return compiledKeywords, compiledPatterns, nil
+}
@@ -0,0 +1,273 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+)
+
+// NetworkGuardService is network-level abuse control: admin-managed CIDR/ASN/country blocks served
+// from an in-memory cache that is refreshed periodically, and per-IP / per-ASN request throttling.
+// Rates are counted per instance, so the effective limit scales with the number of instances.
+type NetworkGuardService struct {
+	Dynamo   *DynamoService
+	IPLimit  int // Requests per NetworkThrottleWindow from one IP; 0 disables
+	ASNLimit int // Requests per NetworkThrottleWindow from one ASN; 0 disables
+
+	mu        sync.RWMutex
+	prefixes  []blockedPrefix
+	asns      map[string]models.NetworkBlock
+	countries map[string]models.NetworkBlock
+
+	counterMu sync.Mutex
+	window    time.Time
+	ipCounts  map[string]int
+	asnCounts map[string]int
+}
+
+// blockedPrefix is a parsed CIDR block
+type blockedPrefix struct {
+	prefix netip.Prefix
+	block  models.NetworkBlock
+}
+
+// StartBlockReloader loads the blocks now and then every interval until ctx is cancelled
+func (s *NetworkGuardService) StartBlockReloader(ctx context.Context, interval time.Duration) {
+	if err := s.ReloadBlocks(ctx); err != nil {
+		utils.Logf(ctx, "⚠️ Initial network block load failed: %v", err)
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.ReloadBlocks(ctx); err != nil {
+					utils.Logf(ctx, "⚠️ Network block reload failed, keeping cached blocks: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// ReloadBlocks replaces the cache with the blocks currently stored
+func (s *NetworkGuardService) ReloadBlocks(ctx context.Context) error {
+	blocks, err := s.ListBlocks(ctx)
+	if err != nil {
+		return err
+	}
+
+	var prefixes []blockedPrefix
+	asns := map[string]models.NetworkBlock{}
+	countries := map[string]models.NetworkBlock{}
+	for _, block := range blocks {
+		switch block.Kind {
+		case models.NetworkBlockCIDR:
+			prefix, err := netip.ParsePrefix(block.Value)
+			if err != nil {
+				utils.Logf(ctx, "⚠️ Skipping network block %s with invalid CIDR %q", block.BlockID, block.Value)
+				continue
+			}
+			prefixes = append(prefixes, blockedPrefix{prefix: prefix, block: block})
+		case models.NetworkBlockASN:
+			asns[block.Value] = block
+		case models.NetworkBlockCountry:
+			countries[block.Value] = block
+		}
+	}
+	s.mu.Lock()
+	s.prefixes, s.asns, s.countries = prefixes, asns, countries
+	s.mu.Unlock()
+	return nil
+}
+
+// ListBlocks reads every block from DynamoDB (the table is small and admin-managed)
+func (s *NetworkGuardService) ListBlocks(ctx context.Context) ([]models.NetworkBlock, error) {
+	items, err := s.Dynamo.ScanAll(ctx, models.NetworkBlocksTable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list network blocks: %w", err)
+	}
+	blocks := []models.NetworkBlock{}
+	if err := attributevalue.UnmarshalListOfMaps(items, &blocks); err != nil {
+		return nil, fmt.Errorf("failed to parse network blocks: %w", err)
+	}
+	now := time.Now().Unix()
+	return slices.DeleteFunc(blocks, func(block models.NetworkBlock) bool {
+		return block.ExpiresAt != 0 && block.ExpiresAt <= now // ✅ TTL deletes lag behind expiry
+	}), nil
+}
+
+// AddBlock validates and stores a block, then refreshes this instance's cache
+// (other instances pick the change up on their next reload)
+func (s *NetworkGuardService) AddBlock(ctx context.Context, request models.NetworkBlockRequest) (*models.NetworkBlock, error) {
+	value, err := canonicalBlockValue(request.Kind, request.Value)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	block := models.NetworkBlock{
+		BlockID:   uuid.New().String(),
+		Kind:      request.Kind,
+		Value:     value,
+		Reason:    request.Reason,
+		CreatedBy: request.CreatedBy,
+		CreatedAt: now.Format(time.RFC3339),
+	}
+	if request.Until != "" {
+		until, err := time.Parse(time.RFC3339, request.Until)
+		if err != nil || !until.After(now) {
+			return nil, validationError("until must be a future RFC3339 time")
+		}
+		block.Until = until.UTC().Format(time.RFC3339)
+		block.ExpiresAt = until.Unix()
+	}
+
+	if err := s.Dynamo.PutItem(ctx, models.NetworkBlocksTable, block); err != nil {
+		return nil, fmt.Errorf("failed to save network block: %w", err)
+	}
+	utils.Logf(ctx, "🚧 %s blocked %s %s (%s)", block.CreatedBy, block.Kind, block.Value, block.Reason)
+
+	if err := s.ReloadBlocks(ctx); err != nil {
+		utils.Logf(ctx, "⚠️ Network block %s saved but cache reload failed: %v", block.BlockID, err)
+	}
+	return &block, nil
+}
+
+// RemoveBlock deletes a block, then refreshes this instance's cache
+func (s *NetworkGuardService) RemoveBlock(ctx context.Context, blockID string) error {
+	_, err := s.Dynamo.Client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(models.NetworkBlocksTable),
+		Key: map[string]types.AttributeValue{
+			"blockId": &types.AttributeValueMemberS{Value: blockID},
+		},
+		ConditionExpression: aws.String("attribute_exists(blockId)"),
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return notFoundError("network block not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove network block %s: %w", blockID, err)
+	}
+	utils.Logf(ctx, "🚧 Removed network block %s", blockID)
+
+	if err := s.ReloadBlocks(ctx); err != nil {
+		utils.Logf(ctx, "⚠️ Network block %s removed but cache reload failed: %v", blockID, err)
+	}
+	return nil
+}
+
+// Blocked returns the block matching a client, or nil. asn and country may be empty when the edge
+// doesn't report them.
+func (s *NetworkGuardService) Blocked(ip, asn, country string) *models.NetworkBlock {
+	if s == nil {
+		return nil
+	}
+	now := time.Now().Unix()
+	active := func(block models.NetworkBlock) bool { return block.ExpiresAt == 0 || block.ExpiresAt > now }
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if addr, err := netip.ParseAddr(ip); err == nil {
+		addr = addr.Unmap()
+		for _, blocked := range s.prefixes {
+			if blocked.prefix.Contains(addr) && active(blocked.block) {
+				return &blocked.block
+			}
+		}
+	}
+	if block, ok := s.asns[normalizeASN(asn)]; ok && asn != "" && active(block) {
+		return &block
+	}
+	if block, ok := s.countries[strings.ToUpper(country)]; ok && country != "" && active(block) {
+		return &block
+	}
+	return nil
+}
+
+// Throttle counts a request from ip (and asn, when known) and returns a QuotaError once either has
+// exceeded its limit in the current window
+func (s *NetworkGuardService) Throttle(ip, asn string) *QuotaError {
+	if s == nil || (s.IPLimit <= 0 && s.ASNLimit <= 0) {
+		return nil
+	}
+	now := time.Now().UTC()
+	window := now.Truncate(models.NetworkThrottleWindow)
+
+	s.counterMu.Lock()
+	defer s.counterMu.Unlock()
+	if !window.Equal(s.window) { // ✅ A fresh window drops every counter, so memory stays bounded
+		s.window, s.ipCounts, s.asnCounts = window, map[string]int{}, map[string]int{}
+	}
+	retryAt := window.Add(models.NetworkThrottleWindow)
+	if s.IPLimit > 0 {
+		s.ipCounts[ip]++
+		if s.ipCounts[ip] > s.IPLimit {
+			return &QuotaError{Message: "Too many requests. Slow down.", Quota: models.QuotaIPRate, Limit: s.IPLimit, RetryAt: retryAt}
+		}
+	}
+	if asn = normalizeASN(asn); s.ASNLimit > 0 && asn != "" {
+		s.asnCounts[asn]++
+		if s.asnCounts[asn] > s.ASNLimit {
+			return &QuotaError{Message: "Too many requests from your network. Slow down.", Quota: models.QuotaASNRate, Limit: s.ASNLimit, RetryAt: retryAt}
+		}
+	}
+	return nil
+}
+
+// canonicalBlockValue validates a block's value and returns the form it is matched in
+func canonicalBlockValue(kind, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	switch kind {
+	case models.NetworkBlockCIDR:
+		if addr, err := netip.ParseAddr(value); err == nil {
+			addr = addr.Unmap()
+			return netip.PrefixFrom(addr, addr.BitLen()).String(), nil
+		}
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return "", validationError("value must be an IP address or CIDR range")
+		}
+		return network.String(), nil
+	case models.NetworkBlockASN:
+		asn := normalizeASN(value)
+		if _, err := strconv.ParseUint(asn, 10, 32); err != nil {
+			return "", validationError("value must be an AS number, e.g. AS64500")
+		}
+		return asn, nil
+	case models.NetworkBlockCountry:
+		country := strings.ToUpper(value)
+		if len(country) != 2 || country[0] < 'A' || country[0] > 'Z' || country[1] < 'A' || country[1] > 'Z' {
+			return "", validationError("value must be a two-letter ISO country code")
+		}
+		return country, nil
+	}
+	return "", validationError("kind must be one of " + strings.Join(models.NetworkBlockKinds, ", "))
+}
+
+// normalizeASN strips the optional "AS" prefix, so "AS64500" and "64500" match
+func normalizeASN(asn string) string {
+	asn = strings.TrimSpace(asn)
+	if len(asn) > 2 && strings.EqualFold(asn[:2], "AS") {
+		return asn[2:]
+	}
+	return asn
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ErrOpenersUnavailable is returned when no LLM is configured or the ai_openers flag is off for the user
+var ErrOpenersUnavailable = errors.New("openers_unavailable")
+
+// openerSystemPrompt tells the model what to write and how to answer
+const openerSystemPrompt = `You write opening messages for a dating app. You get two profiles as JSON: "me" is the person sending the message, "them" is the person receiving it.
+Write 3 short, friendly, distinct first messages from "me" to "them", each referring to something specific in their profile (ideally something the two share).
+No pickup lines, nothing sexual, no assumptions about appearance, at most 200 characters each.
+Answer with a JSON array of strings and nothing else.`
+
+// OpenerService suggests personalized first messages for a match from both profiles' bios,
+// interests and prompts. Suggestions are cached per side of the match until either profile
+// changes; the ai_openers feature flag is the kill switch.
+type OpenerService struct {
+	Dynamo             *DynamoService
+	UserProfileService *UserProfileService
+	Moderation         *ModerationService
+	FeatureFlags       *FeatureFlagService
+	LLM                LLMProvider // ✅ nil when no provider is configured
+}
+
+// SuggestOpeners returns 2-3 openers for the user to send in a match
+func (s *OpenerService) SuggestOpeners(ctx context.Context, matchID, userHandle string) (*models.ConversationOpeners, error) {
+	if s.LLM == nil || !s.FeatureFlags.IsEnabled(models.FlagAIOpeners, userHandle) {
+		return nil, ErrOpenersUnavailable
+	}
+
+	partner, err := findMatchPartner(ctx, s.Dynamo, matchID, userHandle)
+	if err != nil {
+		return nil, err
+	}
+	profiles, err := s.UserProfileService.GetUserProfilesByHandles(ctx, []string{userHandle, partner})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch profiles: %w", err)
+	}
+	me, them := profiles[userHandle], profiles[partner]
+	if me == nil || them == nil {
+		return nil, ErrMatchNotFound
+	}
+
+	// ✅ Profiles are sent to a third-party model only when neither user objected to personalization
+	if !me.EffectiveConsents().Allows(models.PurposePersonalizedRanking) || !them.EffectiveConsents().Allows(models.PurposePersonalizedRanking) {
+		return nil, conflictError("openers need both users to allow personalized features")
+	}
+
+	versions := strconv.Itoa(me.ProfileVersion) + ":" + strconv.Itoa(them.ProfileVersion)
+	if cached := s.cachedOpeners(ctx, matchID, userHandle, versions); cached != nil {
+		utils.Logf(ctx, "✅ Serving cached openers for %s in matchId %s", userHandle, matchID)
+		return cached, nil
+	}
+
+	openers, err := s.generate(ctx, models.OpenerProfileOf(me), models.OpenerProfileOf(them))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	result := &models.ConversationOpeners{
+		MatchID:         matchID,
+		UserHandle:      userHandle,
+		Openers:         openers,
+		ProfileVersions: versions,
+		GeneratedAt:     now.Format(time.RFC3339),
+		ExpiresAt:       now.Add(models.OpenerCacheTTL).Unix(),
+	}
+	if err := s.Dynamo.PutItem(ctx, models.ConversationOpenersTable, result); err != nil {
+		utils.Logf(ctx, "⚠️ Failed to cache openers for matchId %s: %v", matchID, err)
+	}
+
+	utils.Logf(ctx, "✨ Generated %d openers for %s in matchId %s", len(openers), userHandle, matchID)
+	return result, nil
+}
+
+// cachedOpeners returns the stored openers while both profiles are unchanged and the entry is
+// fresh (TTL deletion can lag by days); lookup failures regenerate
+func (s *OpenerService) cachedOpeners(ctx context.Context, matchID, userHandle, versions string) *models.ConversationOpeners {
+	item, err := s.Dynamo.GetItem(ctx, models.ConversationOpenersTable, map[string]types.AttributeValue{
+		"matchId":    &types.AttributeValueMemberS{Value: matchID},
+		"userhandle": &types.AttributeValueMemberS{Value: userHandle},
+	})
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			utils.Logf(ctx, "⚠️ Could not read cached openers for matchId %s: %v", matchID, err)
+		}
+		return nil
+	}
+	var cached models.ConversationOpeners
+	if err := attributevalue.UnmarshalMap(item, &cached); err != nil {
+		return nil
+	}
+	if cached.ProfileVersions != versions || time.Now().Unix() >= cached.ExpiresAt {
+		return nil
+	}
+	cached.Cached = true
+	return &cached
+}
+
+// generate asks the model for openers and keeps the usable ones: non-empty, short enough, distinct
+// and passing the moderation rules
+func (s *OpenerService) generate(ctx context.Context, me, them models.OpenerProfile) ([]string, error) {
+	prompt, err := json.Marshal(map[string]models.OpenerProfile{"me": me, "them": them})
+	if err != nil {
+		return nil, err
+	}
+	completion, err := s.LLM.Complete(ctx, openerSystemPrompt, string(prompt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate openers: %w", err)
+	}
+
+	suggestions, err := parseStringArray(completion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse generated openers: %w", err)
+	}
+
+	seen := make(map[string]bool, len(suggestions))
+	var openers []string
+	for _, suggestion := range suggestions {
+		opener := strings.TrimSpace(suggestion)
+		key := strings.ToLower(opener)
+		if opener == "" || len([]rune(opener)) > models.MaxOpenerLength || seen[key] {
+			continue
+		}
+		if err := s.Moderation.CheckContent(opener); err != nil {
+			continue
+		}
+		seen[key] = true
+		openers = append(openers, opener)
+		if len(openers) == models.MaxOpeners {
+			break
+		}
+	}
+	if len(openers) < models.MinOpeners {
+		return nil, fmt.Errorf("model returned %d usable openers", len(openers))
+	}
+	return openers, nil
+}
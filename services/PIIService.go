@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+	"vibin_server/models"
+	"vibin_server/utils"
+)
+
+// piiKeyScope is the EncryptionService scope whose data keys protect profile PII
+const piiKeyScope = "pii#profiles"
+
+// PIIService encrypts phone and email on profiles and derives blind indexes for lookups.
+// The blind index is an HMAC of the normalized value, so equality lookups work without
+// storing the plaintext.
+type PIIService struct {
+	Encryption    *EncryptionService
+	BlindIndexKey []byte
+}
+
+// Enabled reports whether PII field encryption is configured
+func (s *PIIService) Enabled() bool {
+	return s != nil && s.Encryption.Enabled() && len(s.BlindIndexKey) > 0
+}
+
+// EmailIndex returns the blind index for an email address
+func (s *PIIService) EmailIndex(email string) string {
+	return s.blindIndex("email", strings.ToLower(strings.TrimSpace(email)))
+}
+
+// PhoneIndex returns the blind index for a phone number (digits and leading + only)
+func (s *PIIService) PhoneIndex(phone string) string {
+	return s.blindIndex("phone", normalizePhone(phone))
+}
+
+// ContactIndex keys a contact hash uploaded by a client (see models.ContactHashPattern), so the
+// stored value can't be reversed by hashing every possible number
+func (s *PIIService) ContactIndex(contactHash string) string {
+	return s.blindIndex("contact", contactHash)
+}
+
+// PhoneContactIndex is the ContactIndex a client holding this number would produce
+func (s *PIIService) PhoneContactIndex(phone string) string {
+	normalized := normalizePhone(phone)
+	if normalized == "" {
+		return ""
+	}
+	hash := sha256.Sum256([]byte(normalized))
+	return s.ContactIndex(hex.EncodeToString(hash[:]))
+}
+
+// ProtectProfile replaces emailId/phoneNumber with ciphertext and sets their blind indexes. Both are
+// sealed under the one key version stored in piiKeyVersion.
+func (s *PIIService) ProtectProfile(ctx context.Context, profile *models.UserProfile) error {
+	if !s.Enabled() || profile.PIIEncrypted {
+		return nil
+	}
+
+	var fields []*string
+	if profile.EmailID != "" {
+		profile.EmailIDIndex = s.EmailIndex(profile.EmailID)
+		fields = append(fields, &profile.EmailID)
+	}
+	if profile.PhoneNumber != "" {
+		profile.PhoneNumberIndex = s.PhoneIndex(profile.PhoneNumber)
+		fields = append(fields, &profile.PhoneNumber)
+	}
+	if len(fields) > 0 {
+		version, err := s.Encryption.EncryptFields(ctx, piiKeyScope, fields...)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt contact details: %w", err)
+		}
+		profile.PIIKeyVersion = version
+	}
+
+	profile.PIIEncrypted = true
+	return nil
+}
+
+// UnprotectProfile decrypts emailId/phoneNumber for owner-facing responses
+func (s *PIIService) UnprotectProfile(ctx context.Context, profile *models.UserProfile) {
+	if profile == nil || !profile.PIIEncrypted {
+		return
+	}
+	if !s.Enabled() {
+		utils.Logf(ctx, "⚠️ Profile %s has encrypted PII but PII encryption is not configured", profile.UserHandle)
+		s.StripProfile(profile)
+		return
+	}
+
+	for _, field := range []*string{&profile.EmailID, &profile.PhoneNumber} {
+		if *field == "" {
+			continue
+		}
+		plaintext, err := s.Encryption.Decrypt(ctx, piiKeyScope, *field, profile.PIIKeyVersion)
+		if err != nil {
+			utils.Logf(ctx, "❌ Failed to decrypt PII for %s: %v", profile.UserHandle, err)
+			*field = ""
+			continue
+		}
+		*field = plaintext
+	}
+	profile.PIIEncrypted = false
+}
+
+// StripProfile blanks encrypted PII so ciphertext never reaches other users
+func (s *PIIService) StripProfile(profile *models.UserProfile) {
+	if profile == nil || !profile.PIIEncrypted {
+		return
+	}
+	profile.EmailID = ""
+	profile.PhoneNumber = ""
+}
+
+func (s *PIIService) blindIndex(kind, normalized string) string {
+	if normalized == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, s.BlindIndexKey)
+	mac.Write([]byte(kind + ":" + normalized))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// normalizePhone keeps the digits and a leading +
+func normalizePhone(phone string) string {
+	var normalized strings.Builder
+	for i, r := range strings.TrimSpace(phone) {
+		if unicode.IsDigit(r) || (i == 0 && r == '+') {
+			normalized.WriteRune(r)
+		}
+	}
+	return normalized.String()
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// PassportService lets premium users browse suggestions from another city. The virtual location is
+// stored on the profile and read by GetUserSuggestions, which falls back to GPS once it expires.
+type PassportService struct {
+	Dynamo  *DynamoService
+	Billing *BillingService
+}
+
+// SetPassport sets the user's virtual location for the given number of days
+func (s *PassportService) SetPassport(ctx context.Context, userHandle, city string, latitude, longitude float64, days int) (*models.PassportLocation, error) {
+	if !s.Billing.HasEntitlement(ctx, userHandle, models.EntitlementPassport) {
+		return nil, ErrPremiumRequired
+	}
+
+	now := time.Now().UTC()
+	passport := models.PassportLocation{
+		City:      city,
+		Latitude:  latitude,
+		Longitude: longitude,
+		ExpiresAt: now.AddDate(0, 0, days).Format(time.RFC3339),
+		SetAt:     now.Format(time.RFC3339),
+	}
+	passportAV, err := attributevalue.Marshal(passport)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal passport location: %w", err)
+	}
+	_, err = s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(models.UserProfilesTable),
+		Key: map[string]types.AttributeValue{
+			"userhandle": &types.AttributeValueMemberS{Value: userHandle},
+		},
+		UpdateExpression:    aws.String("SET passport = :passport"),
+		ConditionExpression: aws.String("attribute_exists(userhandle)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":passport": passportAV,
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return nil, notFoundError("profile not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set passport for %s: %w", userHandle, err)
+	}
+
+	utils.Logf(ctx, "✈️ %s is browsing from %s until %s", userHandle, city, passport.ExpiresAt)
+	return &passport, nil
+}
+
+// ClearPassport returns the user to their GPS location before the passport expires
+func (s *PassportService) ClearPassport(ctx context.Context, userHandle string) error {
+	_, err := s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(models.UserProfilesTable),
+		Key: map[string]types.AttributeValue{
+			"userhandle": &types.AttributeValueMemberS{Value: userHandle},
+		},
+		UpdateExpression:    aws.String("REMOVE passport"),
+		ConditionExpression: aws.String("attribute_exists(userhandle)"),
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return notFoundError("profile not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to clear passport for %s: %w", userHandle, err)
+	}
+
+	utils.Logf(ctx, "✅ %s is browsing from their own location again", userHandle)
+	return nil
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// PhotoInsightsService aggregates which photo was on screen when a card was liked or disliked
+type PhotoInsightsService struct {
+	Dynamo             *DynamoService
+	UserProfileService *UserProfileService
+}
+
+// RecordSwipe increments the like or dislike counter for one of the receiver's photos. With a
+// swipeID the increment is applied at most once, so the swipe job can be retried safely.
+func (s *PhotoInsightsService) RecordSwipe(ctx context.Context, receiver string, photoIndex int, liked bool, swipeID string) error {
+	counter := "dislikes"
+	if liked {
+		counter = "likes"
+	}
+
+	key := map[string]types.AttributeValue{
+		"userhandle": &types.AttributeValueMemberS{Value: receiver},
+		"photoIndex": &types.AttributeValueMemberN{Value: strconv.Itoa(photoIndex)},
+	}
+	var err error
+	if swipeID != "" {
+		_, err = applyOnce(ctx, s.Dynamo, models.JobPhotoSwipe+"#"+swipeID, types.Update{
+			TableName:                 aws.String(models.PhotoInsightsTable),
+			Key:                       key,
+			UpdateExpression:          aws.String("ADD #counter :one"),
+			ExpressionAttributeNames:  map[string]string{"#counter": counter},
+			ExpressionAttributeValues: map[string]types.AttributeValue{":one": &types.AttributeValueMemberN{Value: "1"}},
+		})
+	} else {
+		_, err = s.Dynamo.UpdateItem(ctx, models.PhotoInsightsTable, "ADD #counter :one", key,
+			map[string]types.AttributeValue{":one": &types.AttributeValueMemberN{Value: "1"}},
+			map[string]string{"#counter": counter},
+		)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record photo swipe: %w", err)
+	}
+	return nil
+}
+
+// MovePhotoStats rewrites the per-photo counters after the user reorders or deletes photos, since they
+// are stored by position. oldIndexes[i] is the previous position of the photo now at position i.
+// Swipes recorded while the rewrite runs may be lost; insights are a rough guide, not an audit log.
+func (s *PhotoInsightsService) MovePhotoStats(ctx context.Context, userHandle string, oldIndexes []int) error {
+	stats, err := s.photoStats(ctx, userHandle)
+	if err != nil {
+		return err
+	}
+
+	var writes []types.WriteRequest
+	for newIndex, oldIndex := range oldIndexes {
+		if newIndex == oldIndex {
+			continue
+		}
+		stat, moved := stats[oldIndex]
+		if !moved {
+			if _, exists := stats[newIndex]; exists {
+				writes = append(writes, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: map[string]types.AttributeValue{
+					"userhandle": &types.AttributeValueMemberS{Value: userHandle},
+					"photoIndex": &types.AttributeValueMemberN{Value: strconv.Itoa(newIndex)},
+				}}})
+			}
+			continue
+		}
+		stat.PhotoIndex = newIndex
+		item, err := attributevalue.MarshalMap(stat)
+		if err != nil {
+			return err
+		}
+		writes = append(writes, types.WriteRequest{PutRequest: &types.PutRequest{Item: item}})
+	}
+	for index := range stats {
+		if index >= len(oldIndexes) { // ✅ Slots left empty by a deleted photo
+			writes = append(writes, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: map[string]types.AttributeValue{
+				"userhandle": &types.AttributeValueMemberS{Value: userHandle},
+				"photoIndex": &types.AttributeValueMemberN{Value: strconv.Itoa(index)},
+			}}})
+		}
+	}
+	if len(writes) == 0 {
+		return nil
+	}
+
+	if err := s.Dynamo.BatchWriteItems(ctx, models.PhotoInsightsTable, writes); err != nil {
+		return fmt.Errorf("failed to move photo stats: %w", err)
+	}
+	utils.Logf(ctx, "✅ Moved photo stats for %s (%d rows)", userHandle, len(writes))
+	return nil
+}
+
+// photoStats loads the user's counters keyed by photo index
+func (s *PhotoInsightsService) photoStats(ctx context.Context, userHandle string) (map[int]models.PhotoSwipeStats, error) {
+	items, err := s.Dynamo.QueryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.PhotoInsightsTable),
+		KeyConditionExpression: aws.String("userhandle = :user"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":user": &types.AttributeValueMemberS{Value: userHandle},
+		},
+	})
+	if err != nil {
+		utils.Logf(ctx, "❌ Error fetching photo stats: %v", err)
+		return nil, fmt.Errorf("failed to fetch photo stats: %w", err)
+	}
+
+	var stats []models.PhotoSwipeStats
+	if err := attributevalue.UnmarshalListOfMaps(items, &stats); err != nil {
+		return nil, fmt.Errorf("failed to parse photo stats: %w", err)
+	}
+	byIndex := make(map[int]models.PhotoSwipeStats, len(stats))
+	for _, stat := range stats {
+		byIndex[stat.PhotoIndex] = stat
+	}
+	return byIndex, nil
+}
+
+// GetPhotoInsights reports per-photo performance for the user's current photos and a suggested order
+func (s *PhotoInsightsService) GetPhotoInsights(ctx context.Context, userHandle string) (*models.PhotoInsights, error) {
+	utils.Logf(ctx, "🔍 Fetching photo insights for user: %s", userHandle)
+
+	profile, err := s.UserProfileService.GetUserProfileByHandle(ctx, userHandle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch profile: %w", err)
+	}
+
+	byIndex, err := s.photoStats(ctx, userHandle)
+	if err != nil {
+		return nil, err
+	}
+
+	insights := &models.PhotoInsights{UserHandle: userHandle, Photos: []models.PhotoPerformance{}}
+	for i, photo := range profile.Photos {
+		stat := byIndex[i]
+		performance := models.PhotoPerformance{
+			PhotoIndex:  i,
+			Photo:       photo,
+			Likes:       stat.Likes,
+			Dislikes:    stat.Dislikes,
+			Impressions: stat.Likes + stat.Dislikes,
+		}
+		if performance.Impressions > 0 {
+			performance.LikeRate = float64(stat.Likes) / float64(performance.Impressions)
+		}
+		insights.Photos = append(insights.Photos, performance)
+	}
+	insights.SuggestedOrder = suggestPhotoOrder(insights.Photos)
+
+	utils.Logf(ctx, "✅ Photo insights ready for %s (%d photos)", userHandle, len(insights.Photos))
+	return insights, nil
+}
+
+// suggestPhotoOrder ranks photos by a smoothed like rate, (likes+1)/(impressions+2), so a photo
+// with a handful of swipes does not jump ahead of one with a long track record. Ties keep
+// the user's current order.
+func suggestPhotoOrder(photos []models.PhotoPerformance) []int {
+	score := func(p models.PhotoPerformance) float64 {
+		return float64(p.Likes+1) / float64(p.Impressions+2)
+	}
+
+	order := make([]int, len(photos))
+	for i := range photos {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return score(photos[order[a]]) > score(photos[order[b]])
+	})
+
+	suggested := make([]int, len(order))
+	for i, idx := range order {
+		suggested[i] = photos[idx].PhotoIndex
+	}
+	return suggested
+}
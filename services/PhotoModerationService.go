@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/rekognition"
+	rekognitiontypes "github.com/aws/aws-sdk-go-v2/service/rekognition/types"
+)
+
+// ✅ Rekognition confidence (0-100) at which a detection counts
+const (
+	photoModerationMinConfidence = 80
+	faceMinConfidence            = 90
+)
+
+// PhotoModerationService screens uploaded photos for nudity and violence with Rekognition. Flagged
+// photos are quarantined (hidden from suggestions) and queued for an admin to approve or reject.
+type PhotoModerationService struct {
+	Dynamo             *DynamoService
+	Rekognition        *rekognition.Client // ✅ Screening is off when nil; the review queue still works
+	UserProfileService *UserProfileService
+	Photos             *PhotoService // ✅ Set after construction (rejected photos are deleted through it)
+}
+
+// InitializeRekognitionClient initializes the Rekognition client
+func InitializeRekognitionClient(region string) *rekognition.Client {
+	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion(region))
+	if err != nil {
+		log.Fatalf("Failed to load AWS config: %v", err)
+	}
+	return rekognition.NewFromConfig(cfg)
+}
+
+// Enabled reports whether uploaded photos are screened
+func (s *PhotoModerationService) Enabled() bool {
+	return s != nil && s.Rekognition != nil
+}
+
+// ScreenPhoto runs DetectModerationLabels on a JPEG of the photo and quarantines it when any label
+// falls in a blocked category. It reports whether the photo was flagged.
+func (s *PhotoModerationService) ScreenPhoto(ctx context.Context, userHandle, photoKey string, jpegBytes []byte) (bool, error) {
+	if !s.Enabled() {
+		return false, nil
+	}
+
+	output, err := s.Rekognition.DetectModerationLabels(ctx, &rekognition.DetectModerationLabelsInput{
+		Image:         &rekognitiontypes.Image{Bytes: jpegBytes},
+		MinConfidence: aws.Float32(photoModerationMinConfidence),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to detect moderation labels for %s: %w", photoKey, err)
+	}
+
+	var labels []models.ModerationLabel
+	for _, label := range output.ModerationLabels {
+		name, parent := aws.ToString(label.Name), aws.ToString(label.ParentName)
+		if slices.Contains(models.PhotoModerationCategories, name) || slices.Contains(models.PhotoModerationCategories, parent) {
+			labels = append(labels, models.ModerationLabel{Name: name, ParentName: parent, Confidence: float64(aws.ToFloat32(label.Confidence))})
+		}
+	}
+	if len(labels) == 0 {
+		return false, nil
+	}
+
+	review := models.PhotoReview{
+		PhotoKey:   photoKey,
+		UserHandle: userHandle,
+		Status:     models.PhotoReviewPending,
+		Labels:     labels,
+		CreatedAt:  time.Now().UTC().Format(time.RFC3339),
+	}
+	item, err := attributevalue.MarshalMap(review)
+	if err != nil {
+		return true, err
+	}
+	_, err = s.Dynamo.Client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                           aws.String(models.PhotoReviewsTable),
+		Item:                                item,
+		ConditionExpression:                 aws.String("attribute_not_exists(photoKey)"),
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		// ✅ Screened before (a repeated job): an admin's earlier decision stands
+		if status, ok := conditionFailed.Item["status"].(*types.AttributeValueMemberS); ok && status.Value != models.PhotoReviewPending {
+			return status.Value == models.PhotoReviewRejected, nil
+		}
+	} else if err != nil {
+		return true, fmt.Errorf("failed to queue review of %s: %w", photoKey, err)
+	}
+	if err := s.UserProfileService.SetPhotoQuarantined(ctx, userHandle, photoKey, true); err != nil {
+		return true, err
+	}
+
+	utils.Logf(ctx, "🚫 Quarantined photo %s of %s (%d labels, top: %s)", photoKey, userHandle, len(labels), labels[0].Name)
+	return true, nil
+}
+
+// CheckSingleFace fails with a validation error unless the JPEG shows exactly one face. It passes
+// when screening is off.
+func (s *PhotoModerationService) CheckSingleFace(ctx context.Context, photoKey string, jpegBytes []byte) error {
+	if !s.Enabled() {
+		return nil
+	}
+
+	output, err := s.Rekognition.DetectFaces(ctx, &rekognition.DetectFacesInput{
+		Image: &rekognitiontypes.Image{Bytes: jpegBytes},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to detect faces in %s: %w", photoKey, err)
+	}
+
+	faces := 0
+	for _, face := range output.FaceDetails {
+		if aws.ToFloat32(face.Confidence) >= faceMinConfidence {
+			faces++
+		}
+	}
+	utils.Logf(ctx, "🔍 Found %d faces in %s", faces, photoKey)
+	switch {
+	case faces == 0:
+		return validationError("the primary photo must clearly show your face")
+	case faces > 1:
+		return validationError("the primary photo must show only your face, not a group")
+	}
+	return nil
+}
+
+// ListReviews returns one page of reviews in the given status, oldest first
+func (s *PhotoModerationService) ListReviews(ctx context.Context, status string, limit int32, cursor string) ([]models.PhotoReview, string, error) {
+	items, nextCursor, err := s.Dynamo.QueryPage(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.PhotoReviewsTable),
+		IndexName:              aws.String(models.PhotoReviewStatusIndex),
+		KeyConditionExpression: aws.String("#status = :status"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":status": &types.AttributeValueMemberS{Value: status},
+		},
+	}, limit, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	reviews := []models.PhotoReview{}
+	if err := attributevalue.UnmarshalListOfMaps(items, &reviews); err != nil {
+		return nil, "", fmt.Errorf("failed to parse photo reviews: %w", err)
+	}
+	return reviews, nextCursor, nil
+}
+
+// PendingReview reports whether the photo is waiting for an admin's decision
+func (s *PhotoModerationService) PendingReview(ctx context.Context, photoKey string) (bool, error) {
+	item, err := s.Dynamo.GetItem(ctx, models.PhotoReviewsTable, map[string]types.AttributeValue{
+		"photoKey": &types.AttributeValueMemberS{Value: photoKey},
+	})
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	status, _ := item["status"].(*types.AttributeValueMemberS)
+	return status != nil && status.Value == models.PhotoReviewPending, nil
+}
+
+// ResolveReview records an admin's decision on a pending review. Approved photos are released back
+// onto the profile; rejected ones are removed from it and deleted from S3. The decision is stored
+// last, so a failed side effect leaves the review pending to be retried.
+func (s *PhotoModerationService) ResolveReview(ctx context.Context, photoKey, decision, reviewedBy string) (*models.PhotoReview, error) {
+	if decision != models.PhotoReviewApproved && decision != models.PhotoReviewRejected {
+		return nil, validationError("decision must be approved or rejected")
+	}
+
+	key := map[string]types.AttributeValue{
+		"photoKey": &types.AttributeValueMemberS{Value: photoKey},
+	}
+	item, err := s.Dynamo.GetItem(ctx, models.PhotoReviewsTable, key)
+	if errors.Is(err, ErrNotFound) {
+		return nil, notFoundError("photo review not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	var review models.PhotoReview
+	if err := attributevalue.UnmarshalMap(item, &review); err != nil {
+		return nil, fmt.Errorf("failed to parse photo review: %w", err)
+	}
+	if review.Status != models.PhotoReviewPending {
+		return nil, conflictError("photo review was already resolved")
+	}
+
+	if decision == models.PhotoReviewRejected {
+		_, err := s.Photos.DeletePhoto(ctx, review.UserHandle, photoKey)
+		if err != nil && !errors.Is(err, ErrValidation) && !errors.Is(err, ErrNotFound) { // ✅ Already removed by the user
+			return nil, err
+		}
+	}
+	if err := s.UserProfileService.SetPhotoQuarantined(ctx, review.UserHandle, photoKey, false); err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	review.Status = decision
+	review.ReviewedAt = time.Now().UTC().Format(time.RFC3339)
+	review.ReviewedBy = reviewedBy
+	_, err = s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(models.PhotoReviewsTable),
+		Key:                 key,
+		UpdateExpression:    aws.String("SET #status = :decision, reviewedAt = :now, reviewedBy = :reviewedBy"),
+		ConditionExpression: aws.String("#status = :pending"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":decision":   &types.AttributeValueMemberS{Value: decision},
+			":pending":    &types.AttributeValueMemberS{Value: models.PhotoReviewPending},
+			":now":        &types.AttributeValueMemberS{Value: review.ReviewedAt},
+			":reviewedBy": &types.AttributeValueMemberS{Value: reviewedBy},
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return nil, conflictError("photo review was already resolved")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve review of %s: %w", photoKey, err)
+	}
+
+	utils.Logf(ctx, "✅ Photo %s of %s %s by %s", photoKey, review.UserHandle, decision, reviewedBy)
+	return &review, nil
+}
@@ -0,0 +1,423 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // ✅ Register decoders for the formats clients upload
+	"image/jpeg"
+	_ "image/png"
+	"path"
+	"slices"
+	"strings"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// ✅ Limits on the originals we are willing to decode
+const (
+	maxPhotoBytes        = 20 << 20   // 20 MB upload
+	maxPhotoPixels       = 50_000_000 // ~50 MP; decoding allocates 4 bytes per pixel
+	photoRenditionFormat = "image/jpeg"
+	photoJPEGQuality     = 82
+)
+
+// PhotoService generates resized renditions of uploaded profile photos and manages their order and captions
+type PhotoService struct {
+	S3                 *S3Service
+	UserProfileService *UserProfileService
+	PhotoInsights      *PhotoInsightsService
+	Moderation         *PhotoModerationService
+	Jobs               *JobQueue // ✅ Set by RegisterJobs; photos are processed inline without it
+}
+
+// RequestProcessing schedules rendition generation for a photo the user has finished uploading
+func (s *PhotoService) RequestProcessing(ctx context.Context, userHandle, key string) error {
+	if err := s.checkProfilePhoto(ctx, userHandle, key); err != nil {
+		return err
+	}
+
+	if s.Jobs == nil {
+		_, err := s.ProcessPhoto(ctx, userHandle, key)
+		return err
+	}
+	s.Jobs.Enqueue(ctx, models.JobProcessPhoto, models.ProcessPhotoJob{UserHandle: userHandle, Key: key})
+	utils.Logf(ctx, "🖼️ Queued rendition generation for %s (%s)", key, userHandle)
+	return nil
+}
+
+// RegisterJobs moves rendition generation onto the job queue
+func (s *PhotoService) RegisterJobs(queue *JobQueue) {
+	s.Jobs = queue
+	queue.Handle(models.JobProcessPhoto, func(ctx context.Context, payload json.RawMessage) error {
+		job, err := decodeJob[models.ProcessPhotoJob](payload)
+		if err != nil {
+			return err
+		}
+		_, err = s.ProcessPhoto(ctx, job.UserHandle, job.Key)
+		if errors.Is(err, ErrValidation) {
+			utils.Logf(ctx, "⚠️ Skipping unprocessable photo %s: %v", job.Key, err)
+			return nil // ✅ Retrying won't make the upload decodable
+		}
+		return err
+	})
+	queue.Handle(models.JobDeleteObjects, func(ctx context.Context, payload json.RawMessage) error {
+		job, err := decodeJob[models.DeleteObjectsJob](payload)
+		if err != nil {
+			return err
+		}
+		return s.S3.DeleteObjects(ctx, job.Keys)
+	})
+}
+
+// ProcessPhoto downloads the original, uploads a JPEG per rendition size, screens the photo for unsafe
+// content and records the rendition keys on the profile. Re-running it overwrites the same keys, so
+// repeated jobs are harmless.
+func (s *PhotoService) ProcessPhoto(ctx context.Context, userHandle, key string) (*models.PhotoRenditions, error) {
+	utils.Logf(ctx, "🔄 Generating renditions of %s for %s", key, userHandle)
+
+	// ✅ Checked again here: renditions are written next to the original, so it must be the user's photo
+	if err := s.checkProfilePhoto(ctx, userHandle, key); err != nil {
+		return nil, err
+	}
+	img, config, err := s.decodePhoto(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	renditions := &models.PhotoRenditions{
+		Original:    key,
+		Width:       config.Width,
+		Height:      config.Height,
+		ProcessedAt: time.Now().Format(time.RFC3339),
+	}
+	var large []byte
+	for name, size := range models.PhotoRenditionSizes {
+		encoded, err := encodeRendition(img, size)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s rendition of %s: %w", name, key, err)
+		}
+		if name == models.PhotoLarge {
+			large = encoded
+		}
+		renditionKey := photoRenditionKey(key, name)
+		if err := s.S3.PutObject(ctx, renditionKey, photoRenditionFormat, encoded); err != nil {
+			return nil, err
+		}
+		switch name {
+		case models.PhotoThumbnail:
+			renditions.Thumbnail = renditionKey
+		case models.PhotoMedium:
+			renditions.Medium = renditionKey
+		case models.PhotoLarge:
+			renditions.Large = renditionKey
+		}
+	}
+
+	// ✅ Screen the large JPEG rendition: Rekognition accepts only JPEG/PNG of up to 5 MB
+	if _, err := s.Moderation.ScreenPhoto(ctx, userHandle, key, large); err != nil {
+		return nil, err
+	}
+
+	if err := s.UserProfileService.SetPhotoRenditions(ctx, userHandle, *renditions); err != nil {
+		return nil, err
+	}
+	utils.Logf(ctx, "✅ Generated renditions of %s (%dx%d)", key, config.Width, config.Height)
+	return renditions, nil
+}
+
+// AddPhoto appends an uploaded photo to the user's profile and schedules its renditions. A photo
+// added to a profile without photos becomes the primary photo, so it must pass the face check.
+func (s *PhotoService) AddPhoto(ctx context.Context, userHandle, key string, maxPhotos int) (*models.ProfilePhotos, error) {
+	if err := validatePhotoKey(key); err != nil {
+		return nil, err
+	}
+	if !models.OwnsUploadKey(userHandle, key) {
+		return nil, validationError("key was not uploaded by this user")
+	}
+	profile, err := s.UserProfileService.GetUserProfileByHandle(ctx, userHandle)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(profile.Photos, key) {
+		return nil, conflictError("the photo is already on the profile")
+	}
+	if len(profile.Photos) >= maxPhotos {
+		return nil, validationError(fmt.Sprintf("a profile can have at most %d photos", maxPhotos))
+	}
+	if len(profile.Photos) == 0 {
+		if err := s.checkPrimaryPhoto(ctx, profile, key); err != nil {
+			return nil, err
+		}
+	}
+
+	photos, err := s.replacePhotos(ctx, profile, append(slices.Clone(profile.Photos), key), profile.PhotoCaptions, profile.PhotoRenditions)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.RequestProcessing(ctx, userHandle, key); err != nil {
+		utils.Logf(ctx, "⚠️ Added photo %s of %s but renditions were not scheduled: %v", key, userHandle, err)
+	}
+	return photos, nil
+}
+
+// ReorderPhotos puts the user's photos in the given order, which must list each current photo exactly once
+func (s *PhotoService) ReorderPhotos(ctx context.Context, userHandle string, order []string) (*models.ProfilePhotos, error) {
+	profile, err := s.ownedProfile(ctx, userHandle, order...)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(order))
+	for _, photo := range order {
+		seen[photo] = true
+	}
+	if len(order) != len(profile.Photos) || len(seen) != len(order) {
+		return nil, validationError("photos must list each of the profile's photos exactly once")
+	}
+	if order[0] != profile.Photos[0] {
+		if err := s.checkPrimaryPhoto(ctx, profile, order[0]); err != nil {
+			return nil, err
+		}
+	}
+	return s.replacePhotos(ctx, profile, order, profile.PhotoCaptions, profile.PhotoRenditions)
+}
+
+// SetPrimaryPhoto moves a photo to the front, where cards and match lists pick the primary photo from
+func (s *PhotoService) SetPrimaryPhoto(ctx context.Context, userHandle, key string) (*models.ProfilePhotos, error) {
+	profile, err := s.ownedProfile(ctx, userHandle, key)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkPrimaryPhoto(ctx, profile, key); err != nil {
+		return nil, err
+	}
+	order := append([]string{key}, slices.DeleteFunc(slices.Clone(profile.Photos), func(photo string) bool { return photo == key })...)
+	return s.replacePhotos(ctx, profile, order, profile.PhotoCaptions, profile.PhotoRenditions)
+}
+
+// SetPhotoCaption attaches a caption to one of the user's photos; an empty caption removes it
+func (s *PhotoService) SetPhotoCaption(ctx context.Context, userHandle, key, caption string) (*models.ProfilePhotos, error) {
+	profile, err := s.ownedProfile(ctx, userHandle, key)
+	if err != nil {
+		return nil, err
+	}
+	captions := make(map[string]string, len(profile.PhotoCaptions)+1)
+	for photo, existing := range profile.PhotoCaptions {
+		if slices.Contains(profile.Photos, photo) { // ✅ Drop captions of photos that were removed since
+			captions[photo] = existing
+		}
+	}
+	if caption == "" {
+		delete(captions, key)
+	} else {
+		captions[key] = caption
+	}
+	return s.replacePhotos(ctx, profile, profile.Photos, captions, profile.PhotoRenditions)
+}
+
+// DeletePhoto removes a photo from the profile together with its caption, renditions and insights
+// slot, then deletes the original and rendition objects from S3
+func (s *PhotoService) DeletePhoto(ctx context.Context, userHandle, key string) (*models.ProfilePhotos, error) {
+	profile, err := s.ownedProfile(ctx, userHandle, key)
+	if err != nil {
+		return nil, err
+	}
+
+	order := slices.DeleteFunc(slices.Clone(profile.Photos), func(photo string) bool { return photo == key })
+	captions := make(map[string]string, len(profile.PhotoCaptions))
+	for photo, caption := range profile.PhotoCaptions {
+		if photo != key {
+			captions[photo] = caption
+		}
+	}
+	renditions := make(models.PhotoRenditionsMap, len(profile.PhotoRenditions))
+	for photo, rendition := range profile.PhotoRenditions {
+		if photo != key {
+			renditions[photo] = rendition
+		}
+	}
+	photos, err := s.replacePhotos(ctx, profile, order, captions, renditions)
+	if err != nil {
+		return nil, err
+	}
+
+	// ✅ Also the conventional rendition keys, in case a rendition job wrote objects but not the profile
+	objects := []string{key}
+	for name := range models.PhotoRenditionSizes {
+		objects = append(objects, photoRenditionKey(key, name))
+	}
+	if stored, ok := profile.PhotoRenditions[key]; ok {
+		objects = append(objects, stored.Thumbnail, stored.Medium, stored.Large)
+	}
+	objects = slices.DeleteFunc(objects, func(object string) bool { return object == "" })
+	slices.Sort(objects)
+	s.deleteObjects(ctx, slices.Compact(objects))
+
+	utils.Logf(ctx, "🗑️ Deleted photo %s of %s", key, userHandle)
+	return photos, nil
+}
+
+// deleteObjects removes S3 objects on the job queue so a failed delete is retried, or right away without one
+func (s *PhotoService) deleteObjects(ctx context.Context, keys []string) {
+	if s.Jobs != nil {
+		s.Jobs.Enqueue(ctx, models.JobDeleteObjects, models.DeleteObjectsJob{Keys: keys})
+		return
+	}
+	if err := s.S3.DeleteObjects(ctx, keys); err != nil {
+		utils.Logf(ctx, "⚠️ Failed to delete objects %v: %v", keys, err)
+	}
+}
+
+// checkPrimaryPhoto requires a photo to show exactly one face before it becomes the primary photo.
+// It checks the large rendition when there is one, since Rekognition takes JPEG/PNG of up to 5 MB.
+func (s *PhotoService) checkPrimaryPhoto(ctx context.Context, profile *models.UserProfile, key string) error {
+	if !s.Moderation.Enabled() {
+		return nil
+	}
+
+	var jpegBytes []byte
+	if renditions, ok := profile.PhotoRenditions[key]; ok && renditions.Large != "" {
+		large, err := s.S3.GetObject(ctx, renditions.Large, maxPhotoBytes)
+		if err != nil {
+			return err
+		}
+		jpegBytes = large
+	} else {
+		img, _, err := s.decodePhoto(ctx, key)
+		if err != nil {
+			return err
+		}
+		if jpegBytes, err = encodeRendition(img, models.PhotoRenditionSizes[models.PhotoLarge]); err != nil {
+			return fmt.Errorf("failed to encode %s for the face check: %w", key, err)
+		}
+	}
+	return s.Moderation.CheckSingleFace(ctx, key, jpegBytes)
+}
+
+// checkProfilePhoto requires key to be an upload of the user's that is one of their profile photos
+func (s *PhotoService) checkProfilePhoto(ctx context.Context, userHandle, key string) error {
+	if err := validatePhotoKey(key); err != nil {
+		return err
+	}
+	if !models.OwnsUploadKey(userHandle, key) {
+		return validationError("key was not uploaded by this user")
+	}
+	_, err := s.ownedProfile(ctx, userHandle, key)
+	return err
+}
+
+// ownedProfile loads the profile and checks every key is well-formed and one of its photos
+func (s *PhotoService) ownedProfile(ctx context.Context, userHandle string, keys ...string) (*models.UserProfile, error) {
+	for _, key := range keys {
+		if err := validatePhotoKey(key); err != nil {
+			return nil, err
+		}
+	}
+	profile, err := s.UserProfileService.GetUserProfileByHandle(ctx, userHandle)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if !slices.Contains(profile.Photos, key) {
+			return nil, validationError(fmt.Sprintf("%s is not one of the profile's photos", key))
+		}
+	}
+	return profile, nil
+}
+
+// replacePhotos stores the new photo list and moves the photo insights counters along
+func (s *PhotoService) replacePhotos(ctx context.Context, profile *models.UserProfile, order []string, captions map[string]string, renditions models.PhotoRenditionsMap) (*models.ProfilePhotos, error) {
+	updated, err := s.UserProfileService.ReplacePhotos(ctx, profile.UserHandle, profile.ProfileVersion, order, captions, renditions)
+	if err != nil {
+		return nil, err
+	}
+
+	oldIndexes := make([]int, len(order))
+	for i, photo := range order {
+		oldIndexes[i] = slices.Index(profile.Photos, photo)
+	}
+	if s.PhotoInsights != nil {
+		if err := s.PhotoInsights.MovePhotoStats(ctx, profile.UserHandle, oldIndexes); err != nil {
+			utils.Logf(ctx, "⚠️ Photos of %s reordered but insights not moved: %v", profile.UserHandle, err)
+		}
+	}
+
+	utils.Logf(ctx, "✅ Updated photos of %s (version %d)", profile.UserHandle, updated.ProfileVersion)
+	return &models.ProfilePhotos{
+		UserHandle:      updated.UserHandle,
+		Photos:          updated.Photos,
+		PhotoCaptions:   updated.PhotoCaptions,
+		PhotoRenditions: updated.PhotoRenditions,
+		ProfileVersion:  updated.ProfileVersion,
+	}, nil
+}
+
+// decodePhoto downloads and decodes an original, refusing files too large to decode safely
+func (s *PhotoService) decodePhoto(ctx context.Context, key string) (image.Image, image.Config, error) {
+	original, err := s.S3.GetObject(ctx, key, maxPhotoBytes)
+	var noSuchKey *s3types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return nil, image.Config{}, notFoundError("photo not found")
+	}
+	if err != nil {
+		return nil, image.Config{}, err
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(original))
+	if err != nil {
+		return nil, config, validationError("photo is not a supported image")
+	}
+	if config.Width*config.Height > maxPhotoPixels {
+		return nil, config, validationError("photo dimensions are too large")
+	}
+	img, _, err := image.Decode(bytes.NewReader(original))
+	if err != nil {
+		return nil, config, validationError("photo is not a supported image")
+	}
+	return img, config, nil
+}
+
+// encodeRendition scales img so its longest edge is at most maxEdge and encodes it as JPEG.
+// Transparent areas are flattened onto white, since JPEG has no alpha channel.
+func encodeRendition(img image.Image, maxEdge int) ([]byte, error) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if longest := max(width, height); longest > maxEdge {
+		width = max(1, width*maxEdge/longest)
+		height = max(1, height*maxEdge/longest)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: photoJPEGQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// photoRenditionKey places a rendition beside its original: "a/b/photo.png" -> "a/b/photo_thumbnail.jpg"
+func photoRenditionKey(key, rendition string) string {
+	return strings.TrimSuffix(key, path.Ext(key)) + "_" + rendition + ".jpg"
+}
+
+// validatePhotoKey rejects keys that can't be an uploaded photo
+func validatePhotoKey(key string) error {
+	switch {
+	case key == "" || len(key) > 1024:
+		return validationError("key must be between 1 and 1024 characters")
+	case strings.HasPrefix(key, "/") || strings.Contains(key, ".."):
+		return validationError("key must be a relative S3 key")
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ErrQuotaExceeded is matched (via errors.Is) by QuotaError
+var ErrQuotaExceeded = errors.New("quota_exceeded")
+
+// QuotaError says which quota an action ran into and when it can be retried; it is safe to return
+// to clients as JSON
+type QuotaError struct {
+	Message string    `json:"error"`
+	Quota   string    `json:"quota"`           // One of the models.Quota* names
+	Limit   int       `json:"limit,omitempty"` // Allowed uses per period, for counted quotas
+	RetryAt time.Time `json:"retryAt"`         // When the action is allowed again
+}
+
+func (e *QuotaError) Error() string { return e.Message }
+
+func (e *QuotaError) Unwrap() error { return ErrQuotaExceeded }
+
+// checkPingCooldown refuses a ping to a recipient who declined the sender's ping less than
+// PingDeclineCooldown ago (existing is the sender's interaction with them, nil if none)
+func checkPingCooldown(existing *models.Interaction, now time.Time) error {
+	if existing == nil || existing.InteractionType != models.InteractionTypePing {
+		return nil
+	}
+	if existing.Status != models.StatusDeclined && existing.Status != models.StatusRejected {
+		return nil
+	}
+	declinedAt, err := time.Parse(time.RFC3339, existing.LastUpdated)
+	if err != nil {
+		return nil
+	}
+	if retryAt := declinedAt.Add(models.PingDeclineCooldown); now.Before(retryAt) {
+		return &QuotaError{
+			Message: "This user declined your ping recently. Try again later.",
+			Quota:   models.QuotaPingCooldown,
+			RetryAt: retryAt.UTC(),
+		}
+	}
+	return nil
+}
+
+// reservePing counts a ping against the sender's daily quota, or returns a QuotaError when
+// DailyPingLimit pings were already sent today (UTC)
+func (s *InteractionService) reservePing(ctx context.Context, sender string) error {
+	return reserveDailyQuota(ctx, s.Dynamo, sender, models.QuotaDailyPings, models.DailyPingLimit,
+		fmt.Sprintf("You can send %d pings per day. Try again tomorrow.", models.DailyPingLimit))
+}
+
+// reserveDailyQuota counts one use of a per-UTC-day quota, or returns a QuotaError with message when
+// the user already reached limit today
+func reserveDailyQuota(ctx context.Context, dynamo *DynamoService, userHandle, quota string, limit int, message string) error {
+	now := time.Now().UTC()
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	_, err := dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(models.QuotaCountersTable),
+		Key:                 quotaCounterKey(userHandle, quota, now),
+		UpdateExpression:    aws.String("SET expiresAt = :expiresAt ADD #count :one"),
+		ConditionExpression: aws.String("attribute_not_exists(#count) OR #count < :limit"),
+		ExpressionAttributeNames: map[string]string{
+			"#count": "count",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":one":       &types.AttributeValueMemberN{Value: "1"},
+			":limit":     &types.AttributeValueMemberN{Value: strconv.Itoa(limit)},
+			":expiresAt": &types.AttributeValueMemberN{Value: strconv.FormatInt(tomorrow.Add(24*time.Hour).Unix(), 10)},
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		utils.Logf(ctx, "🚫 %s reached the %s limit (%d)", userHandle, quota, limit)
+		return &QuotaError{
+			Message: message,
+			Quota:   quota,
+			Limit:   limit,
+			RetryAt: tomorrow,
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check %s quota: %w", quota, err)
+	}
+	return nil
+}
+
+// releasePing gives back a ping reserved for a ping that was not stored; failures are logged
+func (s *InteractionService) releasePing(ctx context.Context, sender string) {
+	_, err := s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(models.QuotaCountersTable),
+		Key:                 quotaCounterKey(sender, models.QuotaDailyPings, time.Now().UTC()),
+		UpdateExpression:    aws.String("ADD #count :minusOne"),
+		ConditionExpression: aws.String("#count > :zero"),
+		ExpressionAttributeNames: map[string]string{
+			"#count": "count",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":minusOne": &types.AttributeValueMemberN{Value: "-1"},
+			":zero":     &types.AttributeValueMemberN{Value: "0"},
+		},
+	})
+	if err != nil {
+		utils.Logf(ctx, "⚠️ Failed to release ping quota of %s: %v", sender, err)
+	}
+}
+
+// quotaCounterKey builds the QuotaCounters key of the user's uses of a quota on day's UTC date
+func quotaCounterKey(userHandle, quota string, day time.Time) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"userhandle": &types.AttributeValueMemberS{Value: userHandle},
+		"counter":    &types.AttributeValueMemberS{Value: quota + "#" + day.Format(time.DateOnly)},
+	}
+}
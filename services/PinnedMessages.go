@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ErrTooManyPinnedMessages is returned when a conversation already has MaxPinnedMessages pinned
+var ErrTooManyPinnedMessages = conflictError(fmt.Sprintf("a conversation can have at most %d pinned messages", models.MaxPinnedMessages))
+
+// ErrMessageNotFound is returned when the message to pin doesn't exist in the conversation
+var ErrMessageNotFound = notFoundError("message_not_found")
+
+// GetPinnedMessages returns a conversation's pinned messages, most recently pinned first
+func (s *ChatService) GetPinnedMessages(ctx context.Context, matchID string) ([]models.Message, error) {
+	items, err := s.Dynamo.QueryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.MessagesTable),
+		IndexName:              aws.String(models.PinnedMessagesIndex),
+		KeyConditionExpression: aws.String("matchId = :matchId"),
+		ScanIndexForward:       aws.Bool(false), // ✅ Most recently pinned first
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":matchId": &types.AttributeValueMemberS{Value: matchID},
+		},
+	})
+	if err != nil {
+		utils.Logf(ctx, "❌ Error fetching pinned messages for matchId %s: %v", matchID, err)
+		return nil, fmt.Errorf("failed to fetch pinned messages: %w", err)
+	}
+
+	pinned := []models.Message{}
+	if err := attributevalue.UnmarshalListOfMaps(items, &pinned); err != nil {
+		return nil, fmt.Errorf("failed to parse pinned messages: %w", err)
+	}
+	for i := range pinned {
+		s.decryptMessage(ctx, &pinned[i])
+		pinned[i].Status = pinned[i].DeliveryStatus()
+	}
+	return pinned, nil
+}
+
+// SetMessagePinned pins or unpins a message for both participants; userHandle must be one of them
+func (s *ChatService) SetMessagePinned(ctx context.Context, matchID, createdAt, userHandle string, pinned bool) error {
+	utils.Logf(ctx, "📌 Setting pinned=%v on message at %s in matchId %s by %s", pinned, createdAt, matchID, userHandle)
+
+	if _, err := findMatchPartner(ctx, s.Dynamo, matchID, userHandle); err != nil {
+		return err
+	}
+
+	key := map[string]types.AttributeValue{
+		"matchId":   &types.AttributeValueMemberS{Value: matchID},
+		"createdAt": &types.AttributeValueMemberS{Value: createdAt},
+	}
+	input := &dynamodb.UpdateItemInput{
+		TableName:           aws.String(models.MessagesTable),
+		Key:                 key,
+		UpdateExpression:    aws.String("REMOVE pinnedAt, pinnedBy"),
+		ConditionExpression: aws.String("attribute_exists(matchId)"),
+	}
+	if pinned {
+		current, err := s.GetPinnedMessages(ctx, matchID)
+		if err != nil {
+			return err
+		}
+		for _, message := range current {
+			if message.CreatedAt == createdAt {
+				return nil // ✅ Already pinned
+			}
+		}
+		if len(current) >= models.MaxPinnedMessages {
+			return ErrTooManyPinnedMessages
+		}
+		input.UpdateExpression = aws.String("SET pinnedAt = :now, pinnedBy = :user")
+		input.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":now":  &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)},
+			":user": &types.AttributeValueMemberS{Value: userHandle},
+		}
+	}
+
+	_, err := s.Dynamo.Client.UpdateItem(ctx, input)
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return ErrMessageNotFound
+	}
+	if err != nil {
+		utils.Logf(ctx, "❌ Failed to update pinned status: %v", err)
+		return fmt.Errorf("failed to update pinned status: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package services
+
+import "vibin_server/models"
+
+// promptQuestionCatalog is the static set of questions users answer on their profiles. IDs are
+// stored on profiles, so retire a question by removing it here rather than reusing its ID.
+var promptQuestionCatalog = []models.PromptQuestion{
+	{ID: "simple-pleasures", Text: "My simple pleasures", Category: "about_me"},
+	{ID: "typical-sunday", Text: "A typical Sunday", Category: "about_me"},
+	{ID: "unusual-skills", Text: "Unusual skills", Category: "about_me"},
+	{ID: "go-to-karaoke", Text: "My go-to karaoke song", Category: "about_me"},
+	{ID: "irrational-fear", Text: "My most irrational fear", Category: "getting_personal"},
+	{ID: "change-my-mind", Text: "Change my mind about", Category: "getting_personal"},
+	{ID: "never-shut-up-about", Text: "I won't shut up about", Category: "getting_personal"},
+	{ID: "recent-win", Text: "A recent win I'm proud of", Category: "getting_personal"},
+	{ID: "ideal-first-date", Text: "My ideal first date", Category: "date_vibes"},
+	{ID: "together-we-could", Text: "Together, we could", Category: "date_vibes"},
+	{ID: "way-to-win-me-over", Text: "The way to win me over is", Category: "date_vibes"},
+	{ID: "green-flags", Text: "Green flags I look out for", Category: "my_type"},
+	{ID: "looking-for", Text: "I'm looking for", Category: "my_type"},
+	{ID: "get-along-if", Text: "We'll get along if", Category: "my_type"},
+}
+
+// promptQuestion looks a catalog question up by ID
+func promptQuestion(id string) (models.PromptQuestion, bool) {
+	for _, question := range promptQuestionCatalog {
+		if question.ID == id {
+			return question, true
+		}
+	}
+	return models.PromptQuestion{}, false
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ProfileViewService records profile opens and lists recent viewers
+type ProfileViewService struct {
+	Dynamo             *DynamoService
+	UserProfileService *UserProfileService
+}
+
+// RecordView stores that viewer opened viewed's profile. Repeat views on the same (UTC) day
+// overwrite the same row, so each viewer counts once per day. Returns false when the view
+// was not recorded because the viewer objected to analytics.
+func (s *ProfileViewService) RecordView(ctx context.Context, viewer, viewed string) (bool, error) {
+	if viewer == viewed {
+		return false, nil
+	}
+	if !s.UserProfileService.AllowsProcessing(ctx, viewer, models.PurposeAnalytics) {
+		utils.Logf(ctx, "ℹ️ Not recording profile view by %s: analytics objection", viewer)
+		return false, nil
+	}
+
+	now := time.Now().UTC()
+	view := models.ProfileView{
+		ViewedHandle: viewed,
+		SK:           now.Format(time.DateOnly) + "#" + viewer,
+		ViewerHandle: viewer,
+		ViewedAt:     now.Format(time.RFC3339),
+		ExpiresAt:    now.AddDate(0, 0, models.ProfileViewRetentionDays).Unix(),
+	}
+	if err := s.Dynamo.PutItem(ctx, models.ProfileViewsTable, view); err != nil {
+		utils.Logf(ctx, "❌ Failed to record profile view %s -> %s: %v", viewer, viewed, err)
+		return false, fmt.Errorf("failed to record profile view: %w", err)
+	}
+	return true, nil
+}
+
+// GetRecentViewers lists distinct viewers of the user's profile, most recent first
+func (s *ProfileViewService) GetRecentViewers(ctx context.Context, userHandle string, limit int) ([]models.ProfileViewer, error) {
+	utils.Logf(ctx, "🔍 Fetching recent profile viewers for %s", userHandle)
+
+	cutoff := time.Now().UTC().AddDate(0, 0, -models.ProfileViewRetentionDays).Format(time.DateOnly)
+	items, err := s.Dynamo.QueryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.ProfileViewsTable),
+		KeyConditionExpression: aws.String("viewedHandle = :viewed AND SK >= :cutoff"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":viewed": &types.AttributeValueMemberS{Value: userHandle},
+			":cutoff": &types.AttributeValueMemberS{Value: cutoff},
+		},
+		ScanIndexForward: aws.Bool(false),
+	})
+	if err != nil {
+		utils.Logf(ctx, "❌ Error fetching profile views for %s: %v", userHandle, err)
+		return nil, fmt.Errorf("failed to fetch profile views: %w", err)
+	}
+
+	var views []models.ProfileView
+	if err := attributevalue.UnmarshalListOfMaps(items, &views); err != nil {
+		return nil, fmt.Errorf("failed to parse profile views: %w", err)
+	}
+
+	// ✅ Collapse daily rows into one entry per viewer
+	byViewer := make(map[string]*models.ProfileViewer)
+	for _, view := range views {
+		viewer, ok := byViewer[view.ViewerHandle]
+		if !ok {
+			viewer = &models.ProfileViewer{UserHandle: view.ViewerHandle}
+			byViewer[view.ViewerHandle] = viewer
+		}
+		viewer.ViewDays++
+		if view.ViewedAt > viewer.LastViewedAt {
+			viewer.LastViewedAt = view.ViewedAt
+		}
+	}
+
+	viewers := make([]models.ProfileViewer, 0, len(byViewer))
+	for _, viewer := range byViewer {
+		viewers = append(viewers, *viewer)
+	}
+	sort.Slice(viewers, func(i, j int) bool {
+		return viewers[i].LastViewedAt > viewers[j].LastViewedAt
+	})
+	if len(viewers) > limit {
+		viewers = viewers[:limit]
+	}
+
+	// ✅ Attach name/photo; viewers whose profile is gone keep just their handle
+	handles := make([]string, len(viewers))
+	for i, viewer := range viewers {
+		handles[i] = viewer.UserHandle
+	}
+	profiles, err := s.UserProfileService.GetUserProfilesByHandles(ctx, handles)
+	if err != nil {
+		utils.Logf(ctx, "⚠️ Could not enrich profile viewers: %v", err)
+	} else {
+		for i := range viewers {
+			if profile := profiles[viewers[i].UserHandle]; profile != nil {
+				viewers[i].Name = profile.Name
+				if len(profile.Photos) > 0 {
+					viewers[i].Photo = profile.Photos[0]
+				}
+			}
+		}
+	}
+
+	utils.Logf(ctx, "✅ Found %d recent viewers for %s", len(viewers), userHandle)
+	return viewers, nil
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ✅ Redemption failures the controller maps to user-facing messages
+var (
+	ErrPromoNotFound        = notFoundError("promo_not_found")
+	ErrPromoUnavailable     = errors.New("promo_unavailable") // Inactive, expired or used up
+	ErrPromoAlreadyRedeemed = conflictError("promo_already_redeemed")
+	ErrPromoCodeExists      = conflictError("promo_code_exists")
+
+	errGrantConflict = conflictError("subscription changed during redemption, try again")
+)
+
+// maxGrantAttempts bounds retries when another write changes the subscription mid-redemption
+const maxGrantAttempts = 3
+
+// promoCodePattern is what admins may use as a code (normalised to upper case)
+var promoCodePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,32}$`)
+
+// PromoCodeService manages campaign codes and redeems them into billing grants
+type PromoCodeService struct {
+	Dynamo  *DynamoService
+	Billing *BillingService
+}
+
+// NormalizePromoCode trims and upper-cases a code so lookups are case-insensitive
+func NormalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// CreatePromoCode validates and stores a new code; existing codes are never overwritten
+func (s *PromoCodeService) CreatePromoCode(ctx context.Context, promo models.PromoCode) (*models.PromoCode, error) {
+	promo.Code = NormalizePromoCode(promo.Code)
+	if !promoCodePattern.MatchString(promo.Code) {
+		return nil, validationError("code must be 3-32 letters, digits, '-' or '_'")
+	}
+	if promo.GrantType != models.GrantTypePremiumDays {
+		return nil, validationError(fmt.Sprintf("unsupported grantType %q", promo.GrantType))
+	}
+	if promo.GrantDays <= 0 || promo.MaxRedemptions < 0 {
+		return nil, validationError("grantDays must be positive and maxRedemptions must not be negative")
+	}
+	if promo.ExpiresAt != "" {
+		expiresAt, err := time.Parse(time.RFC3339, promo.ExpiresAt)
+		if err != nil {
+			return nil, validationError("expiresAt must be an RFC3339 timestamp")
+		}
+		promo.ExpiresAt = expiresAt.UTC().Format(time.RFC3339) // ✅ UTC so the redeem condition can compare strings
+	}
+
+	promo.Redemptions = 0
+	promo.Active = true
+	promo.DeactivatedAt = ""
+	promo.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	item, err := attributevalue.MarshalMap(promo)
+	if err != nil {
+		return nil, err
+	}
+	utils.Logf(ctx, "📝 Creating promo code %s (%s, %d days) by %s", promo.Code, promo.GrantType, promo.GrantDays, promo.CreatedBy)
+	_, err = s.Dynamo.Client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                aws.String(models.PromoCodesTable),
+		Item:                     item,
+		ConditionExpression:      aws.String("attribute_not_exists(#code)"),
+		ExpressionAttributeNames: map[string]string{"#code": "code"},
+	})
+	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return nil, ErrPromoCodeExists
+		}
+		utils.Logf(ctx, "❌ Failed to store promo code %s: %v", promo.Code, err)
+		return nil, fmt.Errorf("failed to store promo code: %w", err)
+	}
+	return &promo, nil
+}
+
+// ListPromoCodes returns every promo code (the table is small and admin-only)
+func (s *PromoCodeService) ListPromoCodes(ctx context.Context) ([]models.PromoCode, error) {
+	items, err := s.Dynamo.ScanAll(ctx, models.PromoCodesTable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list promo codes: %w", err)
+	}
+	promos := []models.PromoCode{}
+	if err := attributevalue.UnmarshalListOfMaps(items, &promos); err != nil {
+		return nil, fmt.Errorf("failed to parse promo codes: %w", err)
+	}
+	return promos, nil
+}
+
+// DeactivatePromoCode stops further redemptions of a code
+func (s *PromoCodeService) DeactivatePromoCode(ctx context.Context, code string) (*models.PromoCode, error) {
+	code = NormalizePromoCode(code)
+	output, err := s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(models.PromoCodesTable),
+		Key:                 promoKey(code),
+		UpdateExpression:    aws.String("SET #active = :false, deactivatedAt = :now"),
+		ConditionExpression: aws.String("attribute_exists(#code)"),
+		ExpressionAttributeNames: map[string]string{
+			"#active": "active",
+			"#code":   "code",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":false": &types.AttributeValueMemberBOOL{Value: false},
+			":now":   &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)},
+		},
+		ReturnValues: types.ReturnValueAllNew,
+	})
+	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return nil, ErrPromoNotFound
+		}
+		return nil, fmt.Errorf("failed to deactivate promo code: %w", err)
+	}
+
+	var promo models.PromoCode
+	if err := attributevalue.UnmarshalMap(output.Attributes, &promo); err != nil {
+		return nil, err
+	}
+	utils.Logf(ctx, "🚫 Promo code %s deactivated", code)
+	return &promo, nil
+}
+
+// RedeemPromoCode records the redemption and bumps the usage count in one transaction (so usage
+// limits and one-per-user hold under concurrent redemptions), then applies the grant
+func (s *PromoCodeService) RedeemPromoCode(ctx context.Context, userHandle, code string) (*models.PromoRedemptionResult, error) {
+	code = NormalizePromoCode(code)
+	utils.Logf(ctx, "🔍 %s redeeming promo code %s", userHandle, code)
+
+	promo, err := s.getPromoCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if !promoRedeemable(promo, time.Now()) {
+		return nil, ErrPromoUnavailable
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	redemption, err := attributevalue.MarshalMap(models.PromoRedemption{
+		Code:       code,
+		UserHandle: userHandle,
+		GrantType:  promo.GrantType,
+		GrantDays:  promo.GrantDays,
+		RedeemedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// ✅ The counter, the redemption record and the profile grant commit together; a concurrent
+	// grant on the same profile fails the last condition and the grant is recomputed
+	var subscription *models.Subscription
+	for attempt := 1; ; attempt++ {
+		var grant *types.Update
+		subscription, grant, err = s.Billing.PremiumGrantUpdate(ctx, userHandle, promo.GrantDays)
+		if err != nil {
+			return nil, err
+		}
+		err = s.Dynamo.TransactWriteItems(ctx, []types.TransactWriteItem{
+			{
+				Update: &types.Update{
+					TableName:        aws.String(models.PromoCodesTable),
+					Key:              promoKey(code),
+					UpdateExpression: aws.String("ADD redemptions :one"),
+					ConditionExpression: aws.String("#active = :true" +
+						" AND (attribute_not_exists(expiresAt) OR expiresAt = :empty OR expiresAt > :now)" +
+						" AND (maxRedemptions = :zero OR redemptions < maxRedemptions)"),
+					ExpressionAttributeNames: map[string]string{
+						"#active": "active",
+					},
+					ExpressionAttributeValues: map[string]types.AttributeValue{
+						":one":   &types.AttributeValueMemberN{Value: "1"},
+						":zero":  &types.AttributeValueMemberN{Value: "0"},
+						":true":  &types.AttributeValueMemberBOOL{Value: true},
+						":empty": &types.AttributeValueMemberS{Value: ""},
+						":now":   &types.AttributeValueMemberS{Value: now},
+					},
+				},
+			},
+			{
+				Put: &types.Put{
+					TableName:           aws.String(models.PromoRedemptionsTable),
+					Item:                redemption,
+					ConditionExpression: aws.String("attribute_not_exists(userhandle)"),
+				},
+			},
+			{Update: grant},
+		})
+		if err == nil {
+			break
+		}
+		var cancelled *types.TransactionCanceledException
+		if !errors.As(err, &cancelled) {
+			utils.Logf(ctx, "❌ Failed to redeem promo code %s for %s: %v", code, userHandle, err)
+			return nil, fmt.Errorf("failed to redeem promo code: %w", err)
+		}
+		if failure := redemptionFailure(cancelled); failure != errGrantConflict || attempt == maxGrantAttempts {
+			return nil, failure
+		}
+		utils.Logf(ctx, "⚠️ Subscription for %s changed while redeeming %s; retrying", userHandle, code)
+	}
+
+	utils.Logf(ctx, "✅ %s redeemed %s: premium until %s", userHandle, code, subscription.PromoPremiumUntil)
+	return &models.PromoRedemptionResult{
+		Code:         code,
+		GrantType:    promo.GrantType,
+		GrantDays:    promo.GrantDays,
+		Subscription: *subscription,
+	}, nil
+}
+
+// getPromoCode loads a code or returns ErrPromoNotFound
+func (s *PromoCodeService) getPromoCode(ctx context.Context, code string) (*models.PromoCode, error) {
+	item, err := s.Dynamo.GetItem(ctx, models.PromoCodesTable, promoKey(code))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrPromoNotFound
+		}
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrPromoNotFound
+	}
+
+	var promo models.PromoCode
+	if err := attributevalue.UnmarshalMap(item, &promo); err != nil {
+		return nil, err
+	}
+	return &promo, nil
+}
+
+// promoRedeemable is the fast pre-check; the transaction condition is what actually enforces it
+func promoRedeemable(promo *models.PromoCode, now time.Time) bool {
+	if !promo.Active {
+		return false
+	}
+	if promo.MaxRedemptions > 0 && promo.Redemptions >= promo.MaxRedemptions {
+		return false
+	}
+	if promo.ExpiresAt != "" {
+		expiresAt, err := time.Parse(time.RFC3339, promo.ExpiresAt)
+		if err != nil || !now.Before(expiresAt) {
+			return false
+		}
+	}
+	return true
+}
+
+// redemptionFailure maps the cancellation reasons (code update, redemption put, profile grant)
+func redemptionFailure(cancelled *types.TransactionCanceledException) error {
+	reasons := cancelled.CancellationReasons
+	if len(reasons) > 1 && aws.ToString(reasons[1].Code) == "ConditionalCheckFailed" {
+		return ErrPromoAlreadyRedeemed
+	}
+	if len(reasons) > 0 && aws.ToString(reasons[0].Code) == "ConditionalCheckFailed" {
+		return ErrPromoUnavailable
+	}
+	if len(reasons) > 2 && aws.ToString(reasons[2].Code) == "ConditionalCheckFailed" {
+		return errGrantConflict
+	}
+	return fmt.Errorf("promo redemption cancelled: %s", cancelled.ErrorMessage())
+}
+
+// promoKey builds the PromoCodes primary key
+func promoKey(code string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"code": &types.AttributeValueMemberS{Value: code},
+	}
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+	"vibin_server/models"
+)
+
+// RTCTokenProvider mints join tokens for a third-party real-time audio/video service
+type RTCTokenProvider interface {
+	Name() string        // One of the RTCProvider* constants
+	ClientAppID() string // Public app identifier clients need alongside the token; empty when the token carries it
+	MintToken(channel, identity string, issuedAt time.Time, ttl time.Duration) (string, error)
+}
+
+// AgoraTokenProvider mints Agora AccessToken2 ("007") RTC tokens for a user account
+type AgoraTokenProvider struct {
+	AppID          string
+	AppCertificate string
+}
+
+// ✅ Agora service type and RTC privileges
+const (
+	agoraServiceRTC     = 1
+	agoraPrivilegeJoin  = 1
+	agoraPrivilegeAudio = 2
+	agoraPrivilegeVideo = 3
+	agoraPrivilegeData  = 4
+)
+
+// Name identifies the provider
+func (p *AgoraTokenProvider) Name() string { return models.RTCProviderAgora }
+
+// ClientAppID is the Agora app ID clients initialize the SDK with
+func (p *AgoraTokenProvider) ClientAppID() string { return p.AppID }
+
+// MintToken builds a token that lets identity join and publish in channel until issuedAt+ttl
+func (p *AgoraTokenProvider) MintToken(channel, identity string, issuedAt time.Time, ttl time.Duration) (string, error) {
+	saltValue, err := rand.Int(rand.Reader, big.NewInt(99999999))
+	if err != nil {
+		return "", err
+	}
+	salt := uint32(saltValue.Int64()) + 1
+	issueTs := uint32(issuedAt.Unix())
+	expire := uint32(ttl.Seconds()) // ✅ Relative to issueTs, as are the privileges
+
+	// ✅ Signing key: HMAC(issueTs, certificate), then HMAC(salt, that)
+	signing := hmacSHA256(agoraUint32(issueTs), []byte(p.AppCertificate))
+	signing = hmacSHA256(agoraUint32(salt), signing)
+
+	var data bytes.Buffer
+	agoraPackString(&data, []byte(p.AppID))
+	data.Write(agoraUint32(issueTs))
+	data.Write(agoraUint32(expire))
+	data.Write(agoraUint32(salt))
+	data.Write(agoraUint16(1)) // One service: RTC
+	data.Write(agoraUint16(agoraServiceRTC))
+	privileges := []uint16{agoraPrivilegeJoin, agoraPrivilegeAudio, agoraPrivilegeVideo, agoraPrivilegeData}
+	data.Write(agoraUint16(uint16(len(privileges))))
+	for _, privilege := range privileges {
+		data.Write(agoraUint16(privilege))
+		data.Write(agoraUint32(expire))
+	}
+	agoraPackString(&data, []byte(channel))
+	agoraPackString(&data, []byte(identity))
+
+	var content bytes.Buffer
+	agoraPackString(&content, hmacSHA256(signing, data.Bytes()))
+	content.Write(data.Bytes())
+
+	var compressed bytes.Buffer
+	writer := zlib.NewWriter(&compressed)
+	if _, err := writer.Write(content.Bytes()); err != nil {
+		return "", err
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+	return "007" + base64.StdEncoding.EncodeToString(compressed.Bytes()), nil
+}
+
+// TwilioTokenProvider mints Twilio Video access tokens (HS256 JWTs signed with an API key)
+type TwilioTokenProvider struct {
+	AccountSID   string
+	APIKeySID    string
+	APIKeySecret string
+}
+
+// Name identifies the provider
+func (p *TwilioTokenProvider) Name() string { return models.RTCProviderTwilio }
+
+// ClientAppID is empty: Twilio tokens name the account themselves
+func (p *TwilioTokenProvider) ClientAppID() string { return "" }
+
+// MintToken builds a token that lets identity join the room named channel until issuedAt+ttl
+func (p *TwilioTokenProvider) MintToken(channel, identity string, issuedAt time.Time, ttl time.Duration) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT", "cty": "twilio-fpa;v=1"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"jti": fmt.Sprintf("%s-%d", p.APIKeySID, issuedAt.Unix()),
+		"iss": p.APIKeySID,
+		"sub": p.AccountSID,
+		"iat": issuedAt.Unix(),
+		"nbf": issuedAt.Unix(),
+		"exp": issuedAt.Add(ttl).Unix(),
+		"grants": map[string]interface{}{
+			"identity": identity,
+			"video":    map[string]string{"room": channel},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	signature := hmacSHA256([]byte(p.APIKeySecret), []byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// hmacSHA256 returns HMAC-SHA256(key, message)
+func hmacSHA256(key, message []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(message)
+	return mac.Sum(nil)
+}
+
+// agoraUint16 and agoraUint32 encode integers little-endian, as Agora tokens do
+func agoraUint16(value uint16) []byte { return binary.LittleEndian.AppendUint16(nil, value) }
+func agoraUint32(value uint32) []byte { return binary.LittleEndian.AppendUint32(nil, value) }
+
+// agoraPackString writes a length-prefixed byte string
+func agoraPackString(buffer *bytes.Buffer, value []byte) {
+	buffer.Write(agoraUint16(uint16(len(value))))
+	buffer.Write(value)
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+)
+
+// ReportService files reports in the safety center's taxonomy with their evidence (messages from
+// the reported user, screenshots uploaded by the reporter). Reporting always blocks the reported
+// user too; reports are queued for trust & safety by severity.
+type ReportService struct {
+	Dynamo   *DynamoService
+	Blocks   *BlockService
+	S3       *S3Service
+	Webhooks *WebhookService
+}
+
+// ReportUser checks the evidence, blocks the reported user and queues the report (published as user.reported)
+func (s *ReportService) ReportUser(ctx context.Context, request models.ReportRequest) (*models.Report, error) {
+	severity, ok := models.ReportCategorySeverity[request.Category]
+	if !ok {
+		return nil, validationError("unknown report category")
+	}
+	messageIDs := slices.Compact(slices.Sorted(slices.Values(request.MessageIDs)))
+	if len(messageIDs) > 0 {
+		if err := s.checkMessages(ctx, request.UserHandle, request.ReportedHandle, request.MatchID, messageIDs); err != nil {
+			return nil, err
+		}
+	}
+	for _, key := range request.ScreenshotKeys {
+		if err := s.checkScreenshot(ctx, request.UserHandle, key); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := s.Blocks.putBlock(ctx, request.UserHandle, request.ReportedHandle, models.BlockKindReport, request.Category); err != nil {
+		return nil, err
+	}
+
+	createdAt := time.Now().UTC().Format(time.RFC3339)
+	report := &models.Report{
+		ReportID:       uuid.New().String(),
+		ReporterHandle: request.UserHandle,
+		ReportedHandle: request.ReportedHandle,
+		Category:       request.Category,
+		Severity:       severity,
+		Details:        request.Details,
+		MessageIDs:     messageIDs,
+		ScreenshotKeys: request.ScreenshotKeys,
+		Status:         models.ReportStatusOpen,
+		QueueKey:       models.ReportQueueKey(severity, createdAt),
+		CreatedAt:      createdAt,
+	}
+	if len(messageIDs) > 0 {
+		report.MatchID = request.MatchID
+	}
+	if err := s.Dynamo.PutItem(ctx, models.ReportsTable, report); err != nil {
+		return nil, fmt.Errorf("failed to store report: %w", err)
+	}
+
+	s.Webhooks.Publish(ctx, models.WebhookUserReported, map[string]interface{}{
+		"reporterHandle": report.ReporterHandle,
+		"reportedHandle": report.ReportedHandle,
+		"reason":         report.Category,
+		"reportId":       report.ReportID,
+		"category":       report.Category,
+		"severity":       report.Severity,
+	})
+
+	utils.Logf(ctx, "🚩 %s reported %s for %s (%s, %d messages, %d screenshots)", report.ReporterHandle, report.ReportedHandle, report.Category, severity, len(messageIDs), len(report.ScreenshotKeys))
+	return report, nil
+}
+
+// checkMessages fails unless every message was sent by the reported user in a match with the reporter
+func (s *ReportService) checkMessages(ctx context.Context, reporter, reported, matchID string, messageIDs []string) error {
+	if matchID == "" {
+		return validationError("matchId is required with messageIds")
+	}
+	partner, err := findMatchPartner(ctx, s.Dynamo, matchID, reporter)
+	if errors.Is(err, ErrMatchNotFound) || (err == nil && partner != reported) {
+		return validationError("matchId must be your match with the reported user")
+	}
+	if err != nil {
+		return err
+	}
+
+	values := map[string]types.AttributeValue{
+		":matchId": &types.AttributeValueMemberS{Value: matchID},
+		":sender":  &types.AttributeValueMemberS{Value: reported},
+	}
+	placeholders := make([]string, len(messageIDs))
+	for i, id := range messageIDs {
+		placeholders[i] = fmt.Sprintf(":m%d", i)
+		values[placeholders[i]] = &types.AttributeValueMemberS{Value: id}
+	}
+	items, err := s.Dynamo.QueryAll(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(models.MessagesTable),
+		KeyConditionExpression:    aws.String("matchId = :matchId"),
+		FilterExpression:          aws.String("senderId = :sender AND messageId IN (" + strings.Join(placeholders, ", ") + ")"),
+		ProjectionExpression:      aws.String("messageId"),
+		ExpressionAttributeValues: values,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to look up reported messages: %w", err)
+	}
+	found := map[string]bool{}
+	for _, item := range items {
+		if id, ok := item["messageId"].(*types.AttributeValueMemberS); ok {
+			found[id.Value] = true
+		}
+	}
+	if len(found) != len(messageIDs) {
+		return validationError("messageIds must be messages the reported user sent in the match")
+	}
+	return nil
+}
+
+// checkScreenshot fails unless the reporter uploaded the image under their own prefix
+func (s *ReportService) checkScreenshot(ctx context.Context, reporter, key string) error {
+	if !models.OwnsUploadKey(reporter, key) {
+		return validationError("screenshots must be uploaded by the reporter")
+	}
+	size, contentType, err := s.S3.HeadObject(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		return validationError("screenshot " + key + " was not uploaded")
+	}
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(contentType, "image/") || size > models.ReportScreenshotMaxBytes {
+		return validationError("screenshots must be images of at most 10 MB")
+	}
+	return nil
+}
+
+// ListReports returns one page of reports in the given status, most severe and then oldest first,
+// with presigned screenshot links
+func (s *ReportService) ListReports(ctx context.Context, status string, limit int32, cursor string) ([]models.Report, string, error) {
+	items, nextCursor, err := s.Dynamo.QueryPage(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.ReportsTable),
+		IndexName:              aws.String(models.ReportQueueIndex),
+		KeyConditionExpression: aws.String("#status = :status"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":status": &types.AttributeValueMemberS{Value: status},
+		},
+	}, limit, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	reports := []models.Report{}
+	if err := attributevalue.UnmarshalListOfMaps(items, &reports); err != nil {
+		return nil, "", fmt.Errorf("failed to parse reports: %w", err)
+	}
+	for i := range reports {
+		for _, key := range reports[i].ScreenshotKeys {
+			url, err := s.S3.GenerateReadURL(ctx, key)
+			if err != nil {
+				utils.Logf(ctx, "⚠️ Failed to presign screenshot %s: %v", key, err)
+				continue
+			}
+			reports[i].ScreenshotURLs = append(reports[i].ScreenshotURLs, url)
+		}
+	}
+	return reports, nextCursor, nil
+}
+
+// ResolveReport records trust & safety's decision on an open report
+func (s *ReportService) ResolveReport(ctx context.Context, reportID, decision, reviewedBy string) (*models.Report, error) {
+	if decision != models.ReportStatusActioned && decision != models.ReportStatusDismissed {
+		return nil, validationError("decision must be actioned or dismissed")
+	}
+
+	reviewedAt := time.Now().UTC().Format(time.RFC3339)
+	output, err := s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(models.ReportsTable),
+		Key: map[string]types.AttributeValue{
+			"reportId": &types.AttributeValueMemberS{Value: reportID},
+		},
+		UpdateExpression:    aws.String("SET #status = :decision, reviewedAt = :now, reviewedBy = :reviewedBy"),
+		ConditionExpression: aws.String("#status = :open"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":decision":   &types.AttributeValueMemberS{Value: decision},
+			":open":       &types.AttributeValueMemberS{Value: models.ReportStatusOpen},
+			":now":        &types.AttributeValueMemberS{Value: reviewedAt},
+			":reviewedBy": &types.AttributeValueMemberS{Value: reviewedBy},
+		},
+		ReturnValues:                        types.ReturnValueAllNew,
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		if len(conditionFailed.Item) == 0 {
+			return nil, notFoundError("report not found")
+		}
+		return nil, conflictError("report was already resolved")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve report %s: %w", reportID, err)
+	}
+
+	var report models.Report
+	if err := attributevalue.UnmarshalMap(output.Attributes, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse report: %w", err)
+	}
+
+	utils.Logf(ctx, "✅ Report %s against %s %s by %s", reportID, report.ReportedHandle, decision, reviewedBy)
+	return &report, nil
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SMSSender delivers text messages to phone numbers
+type SMSSender interface {
+	SendSMS(ctx context.Context, to, body string) error
+}
+
+// TwilioSMSSender sends SMS through Twilio's Messages API, authenticated with an API key
+type TwilioSMSSender struct {
+	AccountSID   string
+	APIKeySID    string
+	APIKeySecret string
+	From         string // E.164 sender number, or a messaging service SID ("MG...")
+	HTTPClient   *http.Client
+}
+
+// twilioAPIBase is the Twilio REST API root
+const twilioAPIBase = "https://api.twilio.com/2010-04-01"
+
+// SendSMS sends one message and returns once Twilio accepted it
+func (s *TwilioSMSSender) SendSMS(ctx context.Context, to, body string) error {
+	form := url.Values{"To": {to}, "Body": {body}}
+	if strings.HasPrefix(s.From, "MG") {
+		form.Set("MessagingServiceSid", s.From)
+	} else {
+		form.Set("From", s.From)
+	}
+	endpoint := fmt.Sprintf("%s/Accounts/%s/Messages.json", twilioAPIBase, s.AccountSID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(s.APIKeySID, s.APIKeySecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := s.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("twilio request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		raw, _ := io.ReadAll(resp.Body)
+		var twilioErr struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(raw, &twilioErr)
+		return fmt.Errorf("twilio returned %d: %s", resp.StatusCode, twilioErr.Message)
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidSession is returned for a token that is malformed, revoked or expired
+var ErrInvalidSession = unauthorizedError("invalid_session")
+
+// SessionService tracks signed-in devices. Each session has an opaque bearer token
+// ("<sessionId>.<secret>"; only a hash of the secret is stored) that the session middleware
+// validates, and users can list their devices and sign any of them out remotely. A token is only
+// issued for a one-time code sent to the account's email or phone, and sign-ins are guarded against
+// brute force (see LoginProtection.go).
+type SessionService struct {
+	Dynamo        *DynamoService
+	PII           *PIIService
+	Email         EmailSender // ✅ Sends sign-in codes to the account's email
+	SMS           SMSSender   // ✅ Sends sign-in codes to accounts without an email; nil disables
+	Required      bool        // ✅ Reject API requests without a valid token (SESSIONS_REQUIRED)
+	CountryHeader string      // ✅ Request header with the client's ISO country, set by the CDN
+}
+
+// ErrSignInUnavailable is returned when a sign-in code can't be sent: no sender is configured for
+// any contact the account has
+var ErrSignInUnavailable = forbiddenError("sign-in codes can't be sent to this account")
+
+// CreateSession starts a pending session for the device the request came from and sends the
+// account a one-time code; the session's token is issued by VerifySession once the code checks out
+func (s *SessionService) CreateSession(ctx context.Context, request models.SessionRequest, ip, country string) (*models.NewSession, error) {
+	entry := models.LoginAuditEntry{
+		UserHandle:  request.UserHandle,
+		IP:          ip,
+		Country:     country,
+		Platform:    request.Platform,
+		DeviceModel: request.DeviceModel,
+		DeviceID:    request.DeviceID,
+	}
+	if err := s.checkLockout(ctx, request.UserHandle, ipSubject(ip)); err != nil {
+		entry.Decision = models.LoginDecisionLockedOut
+		s.audit(ctx, entry)
+		return nil, err
+	}
+
+	item, err := s.Dynamo.GetItem(ctx, models.UserProfilesTable, profileKey(request.UserHandle))
+	if errors.Is(err, ErrNotFound) {
+		s.recordLoginFailure(ctx, ipSubject(ip)) // ✅ Guessing handles counts against the IP only
+		return nil, notFoundError("profile not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	var profile models.UserProfile
+	if err := attributevalue.UnmarshalMap(item, &profile); err != nil {
+		return nil, fmt.Errorf("failed to parse profile: %w", err)
+	}
+
+	now := time.Now().UTC()
+	session := models.Session{
+		SessionID:   uuid.New().String(),
+		UserHandle:  request.UserHandle,
+		Platform:    request.Platform,
+		DeviceModel: request.DeviceModel,
+		OSVersion:   request.OSVersion,
+		AppVersion:  request.AppVersion,
+		DeviceID:    request.DeviceID,
+		CreatedAt:   now.Format(time.RFC3339),
+		LastSeenAt:  now.Format(time.RFC3339),
+		LastIP:      ip,
+		Country:     country,
+	}
+	entry.SessionID = session.SessionID
+
+	risk, err := s.signInRisk(ctx, request, country)
+	if err != nil {
+		return nil, err
+	}
+	s.PII.UnprotectProfile(ctx, &profile)
+	return s.startStepUp(ctx, session, profile, risk, entry, now)
+}
+
+// issueToken generates the session's token, storing its hash and a fresh idle expiry on session
+func (s *SessionService) issueToken(session *models.Session, now time.Time) (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	encodedSecret := base64.RawURLEncoding.EncodeToString(secret)
+	session.TokenHash = sessionTokenHash(encodedSecret)
+	session.ExpiresAt = now.AddDate(0, 0, models.SessionIdleDays).Unix()
+	return session.SessionID + "." + encodedSecret, nil
+}
+
+// Authenticate returns the session a token belongs to, recording the device as seen from ip
+func (s *SessionService) Authenticate(ctx context.Context, token, ip string) (*models.Session, error) {
+	sessionID, secret, ok := parseSessionToken(token)
+	if !ok {
+		return nil, ErrInvalidSession
+	}
+	item, err := s.Dynamo.GetItem(ctx, models.SessionsTable, sessionKey(sessionID))
+	if errors.Is(err, ErrNotFound) {
+		return nil, ErrInvalidSession
+	}
+	if err != nil {
+		return nil, err
+	}
+	var session models.Session
+	if err := attributevalue.UnmarshalMap(item, &session); err != nil {
+		return nil, fmt.Errorf("failed to parse session: %w", err)
+	}
+
+	now := time.Now().UTC()
+	// ✅ TTL deletes lag behind expiry, so expired rows are checked here too
+	if session.Pending || !sessionSecretMatches(session.TokenHash, secret) || now.Unix() >= session.ExpiresAt {
+		return nil, ErrInvalidSession
+	}
+
+	lastSeen, _ := time.Parse(time.RFC3339, session.LastSeenAt)
+	if now.Sub(lastSeen) >= models.SessionTouchInterval || ip != session.LastIP {
+		s.touch(ctx, &session, now, ip)
+	}
+	return &session, nil
+}
+
+// touch records a use of the session and pushes its expiry back; failures are logged and never
+// fail the request
+func (s *SessionService) touch(ctx context.Context, session *models.Session, now time.Time, ip string) {
+	session.LastSeenAt = now.Format(time.RFC3339)
+	session.LastIP = ip
+	session.ExpiresAt = now.AddDate(0, 0, models.SessionIdleDays).Unix()
+	_, err := s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(models.SessionsTable),
+		Key:                 sessionKey(session.SessionID),
+		UpdateExpression:    aws.String("SET lastSeenAt = :now, lastIp = :ip, expiresAt = :expiresAt"),
+		ConditionExpression: aws.String("attribute_exists(sessionId)"), // ✅ Never resurrect a revoked session
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":now":       &types.AttributeValueMemberS{Value: session.LastSeenAt},
+			":ip":        &types.AttributeValueMemberS{Value: ip},
+			":expiresAt": &types.AttributeValueMemberN{Value: strconv.FormatInt(session.ExpiresAt, 10)},
+		},
+	})
+	if err != nil {
+		utils.Logf(ctx, "⚠️ Failed to record use of session %s: %v", session.SessionID, err)
+	}
+}
+
+// ListSessions returns the user's signed-in devices, most recently used first, marking currentID
+func (s *SessionService) ListSessions(ctx context.Context, userHandle, currentID string) ([]models.Session, error) {
+	items, err := s.Dynamo.QueryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.SessionsTable),
+		IndexName:              aws.String(models.SessionUserIndex),
+		KeyConditionExpression: aws.String("userhandle = :handle"),
+		FilterExpression:       aws.String("expiresAt > :now AND attribute_not_exists(pending)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":handle": &types.AttributeValueMemberS{Value: userHandle},
+			":now":    &types.AttributeValueMemberN{Value: strconv.FormatInt(time.Now().Unix(), 10)},
+		},
+		ScanIndexForward: aws.Bool(false),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	sessions := []models.Session{}
+	if err := attributevalue.UnmarshalListOfMaps(items, &sessions); err != nil {
+		return nil, fmt.Errorf("failed to parse sessions: %w", err)
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].SessionID == currentID
+	}
+	return sessions, nil
+}
+
+// RevokeSession signs one of the user's devices out; its token stops working immediately
+func (s *SessionService) RevokeSession(ctx context.Context, userHandle, sessionID string) error {
+	_, err := s.Dynamo.Client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(models.SessionsTable),
+		Key:                 sessionKey(sessionID),
+		ConditionExpression: aws.String("userhandle = :handle"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":handle": &types.AttributeValueMemberS{Value: userHandle},
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return notFoundError("session not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to revoke session %s: %w", sessionID, err)
+	}
+
+	utils.Logf(ctx, "🚪 %s signed out session %s", userHandle, sessionID)
+	return nil
+}
+
+// sessionKey builds the Sessions primary key
+func sessionKey(sessionID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"sessionId": &types.AttributeValueMemberS{Value: sessionID},
+	}
+}
+
+// sessionTokenHash is what is stored in place of a token's secret
+func sessionTokenHash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// parseSessionToken splits a "<sessionId>.<secret>" token, reporting whether both parts are present
+func parseSessionToken(token string) (sessionID, secret string, ok bool) {
+	sessionID, secret, ok = strings.Cut(token, ".")
+	return sessionID, secret, ok && sessionID != "" && secret != ""
+}
+
+// sessionSecretMatches compares a token's secret with the stored hash in constant time
+func sessionSecretMatches(tokenHash, secret string) bool {
+	return tokenHash != "" && subtle.ConstantTimeCompare([]byte(tokenHash), []byte(sessionTokenHash(secret))) == 1
+}
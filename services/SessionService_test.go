@@ -0,0 +1,96 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"vibin_server/models"
+)
+
+func TestParseSessionToken(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         string
+		wantSessionID string
+		wantSecret    string
+		wantOK        bool
+	}{
+		{"session and secret", "abc-123.c2VjcmV0", "abc-123", "c2VjcmV0", true},
+		{"secret keeps later dots", "abc-123.sec.ret", "abc-123", "sec.ret", true},
+		{"no separator", "abc-123", "", "", false},
+		{"empty session", ".c2VjcmV0", "", "", false},
+		{"empty secret", "abc-123.", "", "", false},
+		{"only separator", ".", "", "", false},
+		{"empty token", "", "", "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sessionID, secret, ok := parseSessionToken(test.token)
+			if ok != test.wantOK {
+				t.Fatalf("parseSessionToken(%q) ok = %v, want %v", test.token, ok, test.wantOK)
+			}
+			if ok && (sessionID != test.wantSessionID || secret != test.wantSecret) {
+				t.Errorf("parseSessionToken(%q) = %q, %q, want %q, %q", test.token, sessionID, secret, test.wantSessionID, test.wantSecret)
+			}
+		})
+	}
+}
+
+func TestSessionSecretMatches(t *testing.T) {
+	stored := sessionTokenHash("c2VjcmV0")
+
+	tests := []struct {
+		name      string
+		tokenHash string
+		secret    string
+		want      bool
+	}{
+		{"right secret", stored, "c2VjcmV0", true},
+		{"wrong secret", stored, "c2VjcmV1", false},
+		{"secret prefix", stored, "c2VjcmV", false},
+		{"empty secret", stored, "", false},
+		{"the hash itself", stored, stored, false},
+		{"no stored hash", "", "c2VjcmV0", false},
+		{"no stored hash or secret", "", "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := sessionSecretMatches(test.tokenHash, test.secret); got != test.want {
+				t.Errorf("sessionSecretMatches(%q) = %v, want %v", test.secret, got, test.want)
+			}
+		})
+	}
+}
+
+// An issued token must parse back to its session and match only the hash stored with it
+func TestIssueTokenRoundTrip(t *testing.T) {
+	var s SessionService
+	now := time.Unix(1_700_000_000, 0)
+	session := models.Session{SessionID: "3f1c9a52-7d0e-4b8a-9c41-2f6d8e0b7a13"}
+
+	token, err := s.issueToken(&session, now)
+	if err != nil {
+		t.Fatalf("issueToken: %v", err)
+	}
+	sessionID, secret, ok := parseSessionToken(token)
+	if !ok || sessionID != session.SessionID {
+		t.Fatalf("parseSessionToken(%q) = %q, ok %v, want session %q", token, sessionID, ok, session.SessionID)
+	}
+	if strings.Contains(session.TokenHash, secret) {
+		t.Errorf("stored hash %q contains the secret", session.TokenHash)
+	}
+	if !sessionSecretMatches(session.TokenHash, secret) {
+		t.Errorf("issued secret does not match its stored hash")
+	}
+	if want := now.AddDate(0, 0, models.SessionIdleDays).Unix(); session.ExpiresAt != want {
+		t.Errorf("ExpiresAt = %d, want %d", session.ExpiresAt, want)
+	}
+
+	other := models.Session{SessionID: session.SessionID}
+	if _, err := s.issueToken(&other, now); err != nil {
+		t.Fatalf("issueToken: %v", err)
+	}
+	if sessionSecretMatches(other.TokenHash, secret) {
+		t.Errorf("secret of one token matches the hash of another")
+	}
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ShownProfileService keeps suggestion feeds from repeating themselves: profiles served in one of
+// the user's last RepeatBatches suggestion batches are left out of the next, interaction or not.
+// Suppression is best effort and off when RepeatBatches is 0.
+type ShownProfileService struct {
+	Dynamo        *DynamoService
+	RepeatBatches int
+}
+
+// Enabled reports whether served profiles are suppressed
+func (s *ShownProfileService) Enabled() bool {
+	return s != nil && s.RepeatBatches > 0
+}
+
+// RecentlyShown returns the profiles served in the user's last RepeatBatches batches
+func (s *ShownProfileService) RecentlyShown(ctx context.Context, userHandle string) (map[string]bool, error) {
+	shown := make(map[string]bool)
+	if !s.Enabled() {
+		return shown, nil
+	}
+	items, err := s.Dynamo.QueryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.ShownProfilesTable),
+		KeyConditionExpression: aws.String("userhandle = :handle"),
+		FilterExpression:       aws.String("expiresAt > :now"), // ✅ TTL deletes lazily
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":handle": &types.AttributeValueMemberS{Value: userHandle},
+			":now":    &types.AttributeValueMemberN{Value: strconv.FormatInt(time.Now().Unix(), 10)},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shown profiles of %s: %w", userHandle, err)
+	}
+	var profiles []models.ShownProfile
+	if err := attributevalue.UnmarshalListOfMaps(items, &profiles); err != nil {
+		return nil, fmt.Errorf("failed to parse shown profiles: %w", err)
+	}
+
+	// ✅ A batch is every profile sharing a servedAt; keep the most recent RepeatBatches of them
+	var batches []int64
+	for _, profile := range profiles {
+		if !slices.Contains(batches, profile.ServedAt) {
+			batches = append(batches, profile.ServedAt)
+		}
+	}
+	slices.Sort(batches)
+	if len(batches) > s.RepeatBatches {
+		batches = batches[len(batches)-s.RepeatBatches:]
+	}
+	for _, profile := range profiles {
+		if slices.Contains(batches, profile.ServedAt) {
+			shown[profile.ShownHandle] = true
+		}
+	}
+	return shown, nil
+}
+
+// RecordShown stores the profiles of a suggestion batch just served to the user
+func (s *ShownProfileService) RecordShown(ctx context.Context, userHandle string, shownHandles []string) error {
+	if !s.Enabled() || len(shownHandles) == 0 {
+		return nil
+	}
+	now := time.Now()
+	requests := make([]types.WriteRequest, 0, len(shownHandles))
+	for _, handle := range shownHandles {
+		item, err := attributevalue.MarshalMap(models.ShownProfile{
+			UserHandle:  userHandle,
+			ShownHandle: handle,
+			ServedAt:    now.UnixMilli(),
+			ExpiresAt:   now.Add(models.ShownProfileRetention).Unix(),
+		})
+		if err != nil {
+			return err
+		}
+		requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: item}})
+	}
+	if err := s.Dynamo.BatchWriteItems(ctx, models.ShownProfilesTable, requests); err != nil {
+		return fmt.Errorf("failed to record shown profiles of %s: %w", userHandle, err)
+	}
+
+	utils.Logf(ctx, "👀 Recorded %d profiles shown to %s", len(shownHandles), userHandle)
+	return nil
+}
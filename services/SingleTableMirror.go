@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"vibin_server/models"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	streamtypes "github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+)
+
+// SingleTableMirrorTables are the legacy tables mirrored into the consolidated table until cutover.
+// Their streams need NEW_AND_OLD_IMAGES.
+var SingleTableMirrorTables = []string{
+	models.InteractionsTable,
+	models.MessagesTable,
+	models.GroupInteractionsTable,
+	models.GroupMessageTable,
+}
+
+// SingleTableMirror copies changes to the legacy tables into the consolidated table from their
+// streams, off the request path. Each record's old and new images are converted like the migration
+// does: the new image's items are put, and the items only the old image produced are deleted.
+type SingleTableMirror struct {
+	Dynamo *DynamoService
+}
+
+// HandleRecord returns the stream handler for one legacy table. A failed write is retried by the
+// stream consumer and skipped after that; re-running the migration repairs any drift before cutover.
+func (m *SingleTableMirror) HandleRecord(table string) StreamRecordHandler {
+	convert := legacyConverter(table)
+	return func(ctx context.Context, record streamtypes.Record) error {
+		if convert == nil || record.Dynamodb == nil {
+			return nil
+		}
+		before, err := convertStreamImage(convert, record.Dynamodb.OldImage)
+		if err != nil {
+			return fmt.Errorf("%s old image: %w", table, err)
+		}
+		after, err := convertStreamImage(convert, record.Dynamodb.NewImage)
+		if err != nil {
+			return fmt.Errorf("%s new image: %w", table, err)
+		}
+
+		requests := mirrorWriteRequests(before, after)
+		if len(requests) == 0 {
+			return nil
+		}
+		return m.Dynamo.BatchWriteItems(ctx, models.SingleTable, requests)
+	}
+}
+
+// mirrorWriteRequests puts every item after produces and deletes the ones only before produced.
+// Shared META summaries are never deleted, since other legacy items produce them too.
+func mirrorWriteRequests(before, after []map[string]types.AttributeValue) []types.WriteRequest {
+	var requests []types.WriteRequest
+	produced := make(map[string]bool, len(after))
+	for _, item := range after {
+		produced[singleTableID(item)] = true
+		requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: item}})
+	}
+	for _, item := range before {
+		sk, _ := item["SK"].(*types.AttributeValueMemberS)
+		if produced[singleTableID(item)] || (sk != nil && sk.Value == models.KeyMeta) {
+			continue
+		}
+		requests = append(requests, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: map[string]types.AttributeValue{
+			"PK": item["PK"],
+			"SK": item["SK"],
+		}}})
+	}
+	return dedupeWriteRequests(requests)
+}
+
+// convertStreamImage converts a stream image with a migration converter; a missing image (the item
+// didn't exist before, or was deleted) converts to nothing
+func convertStreamImage(convert func(map[string]types.AttributeValue) ([]map[string]types.AttributeValue, error), image map[string]streamtypes.AttributeValue) ([]map[string]types.AttributeValue, error) {
+	if image == nil {
+		return nil, nil
+	}
+	item, err := attributevalue.FromDynamoDBStreamsMap(image)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert stream image: %w", err)
+	}
+	return convert(item)
+}
+
+// legacyConverter returns the converter for a legacy table, or nil for any other table
+func legacyConverter(table string) func(map[string]types.AttributeValue) ([]map[string]types.AttributeValue, error) {
+	switch table {
+	case models.InteractionsTable:
+		return convertInteractionItem
+	case models.MessagesTable:
+		return convertMessageItem
+	case models.GroupInteractionsTable:
+		return convertGroupInteractionItem
+	case models.GroupMessageTable:
+		return convertGroupMessageItem
+	}
+	return nil
+}
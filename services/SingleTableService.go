@@ -0,0 +1,354 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// SingleTableService reads the consolidated table and migrates legacy tables into it
+type SingleTableService struct {
+	Dynamo *DynamoService
+}
+
+// MigrationReport summarizes a run of the single-table migration
+type MigrationReport struct {
+	DryRun   bool           `json:"dryRun"`
+	Read     map[string]int `json:"read"`    // Items read per legacy table
+	Written  int            `json:"written"` // Items written to the consolidated table
+	Skipped  int            `json:"skipped"` // Legacy items that could not be converted
+	Warnings []string       `json:"warnings,omitempty"`
+	Resume   string         `json:"resume,omitempty"` // Where a failed run stopped; pass it back to continue
+}
+
+// GetUserCollection returns one page of everything stored for a user's partition
+func (s *SingleTableService) GetUserCollection(ctx context.Context, userHandle string, limit int32, cursor string) (*models.ItemCollection, string, error) {
+	return s.getCollection(ctx, models.UserPK(userHandle), limit, cursor)
+}
+
+// GetMatchCollection returns one page of the match summary and its messages
+func (s *SingleTableService) GetMatchCollection(ctx context.Context, matchID string, limit int32, cursor string) (*models.ItemCollection, string, error) {
+	return s.getCollection(ctx, models.MatchPK(matchID), limit, cursor)
+}
+
+// GetGroupCollection returns one page of the group summary and its messages
+func (s *SingleTableService) GetGroupCollection(ctx context.Context, groupID string, limit int32, cursor string) (*models.ItemCollection, string, error) {
+	return s.getCollection(ctx, models.GroupPK(groupID), limit, cursor)
+}
+
+func (s *SingleTableService) getCollection(ctx context.Context, pk string, limit int32, cursor string) (*models.ItemCollection, string, error) {
+	utils.Logf(ctx, "🔍 Fetching item collection for %s (limit=%d)", pk, limit)
+
+	items, nextCursor, err := s.Dynamo.QueryPage(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.SingleTable),
+		KeyConditionExpression: aws.String("PK = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: pk},
+		},
+	}, limit, cursor)
+	if err != nil {
+		utils.Logf(ctx, "❌ Error fetching item collection for %s: %v", pk, err)
+		return nil, "", fmt.Errorf("failed to fetch item collection: %w", err)
+	}
+
+	collection := &models.ItemCollection{PK: pk, Items: []map[string]interface{}{}}
+	for _, item := range items {
+		var decoded map[string]interface{}
+		if err := attributevalue.UnmarshalMap(item, &decoded); err != nil {
+			utils.Logf(ctx, "⚠️ Skipping item due to unmarshalling error: %v", err)
+			continue
+		}
+		collection.Items = append(collection.Items, decoded)
+	}
+
+	utils.Logf(ctx, "✅ Found %d items for %s", len(collection.Items), pk)
+	return collection, nextCursor, nil
+}
+
+// MigrateToSingleTable copies Interactions, Message, GroupInteractions and GroupMessages into the
+// consolidated table, one scan page at a time so memory stays flat. Writes are idempotent puts, so the
+// migration can be re-run; a failed run returns the report with Resume set, and passing that back as
+// resume continues from the page that failed instead of starting over.
+func (s *SingleTableService) MigrateToSingleTable(ctx context.Context, dryRun bool, resume string) (*MigrationReport, error) {
+	utils.Logf(ctx, "🚚 Starting single-table migration (dryRun=%v, resume=%q)", dryRun, resume)
+
+	report := &MigrationReport{DryRun: dryRun, Read: map[string]int{}}
+	resumeTable, cursor, err := parseMigrationResume(resume)
+	if err != nil {
+		return nil, err
+	}
+
+	started := resumeTable == ""
+	for _, table := range SingleTableMirrorTables {
+		if !started && table != resumeTable {
+			continue
+		}
+		started = true
+
+		for {
+			items, nextCursor, err := s.Dynamo.ScanPage(ctx, table, cursor)
+			if err != nil {
+				report.Resume = migrationResume(table, cursor)
+				return report, err
+			}
+			report.Read[table] += len(items)
+
+			writeRequests := s.convertMigrationPage(table, items, report)
+			if !dryRun && len(writeRequests) > 0 {
+				if err := s.Dynamo.BatchWriteItems(ctx, models.SingleTable, writeRequests); err != nil {
+					utils.Logf(ctx, "❌ Single-table migration failed in %s: %v", table, err)
+					report.Resume = migrationResume(table, cursor)
+					return report, err
+				}
+			}
+			report.Written += len(writeRequests)
+
+			cursor = nextCursor
+			if cursor == "" {
+				break
+			}
+			utils.Logf(ctx, "📦 %s: %d items read so far (resume=%s)", table, report.Read[table], migrationResume(table, cursor))
+		}
+	}
+	if !started {
+		return nil, validationError(fmt.Sprintf("unknown table in resume %q", resume))
+	}
+
+	if dryRun {
+		utils.Logf(ctx, "ℹ️ Dry run: %d items would be written to '%s'", report.Written, models.SingleTable)
+		return report, nil
+	}
+	utils.Logf(ctx, "✅ Single-table migration complete: %d items written, %d skipped", report.Written, report.Skipped)
+	return report, nil
+}
+
+// convertMigrationPage converts one scan page into puts, recording items that can't be converted
+func (s *SingleTableService) convertMigrationPage(table string, items []map[string]types.AttributeValue, report *MigrationReport) []types.WriteRequest {
+	convert := legacyConverter(table)
+	var writeRequests []types.WriteRequest
+	for _, item := range items {
+		converted, err := convert(item)
+		if err != nil {
+			report.Skipped++
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%s: %v", table, err))
+			continue
+		}
+		for _, newItem := range converted {
+			writeRequests = append(writeRequests, types.WriteRequest{PutRequest: &types.PutRequest{Item: newItem}})
+		}
+	}
+	return dedupeWriteRequests(writeRequests)
+}
+
+// migrationResume is the resume point for a table's scan page ("<table>@<cursor>")
+func migrationResume(table, cursor string) string {
+	return table + "@" + cursor
+}
+
+// parseMigrationResume splits a resume point; "" starts from the first table
+func parseMigrationResume(resume string) (string, string, error) {
+	if resume == "" {
+		return "", "", nil
+	}
+	table, cursor, ok := strings.Cut(resume, "@")
+	if !ok || table == "" {
+		return "", "", validationError("resume must be <table>@<cursor>")
+	}
+	return table, cursor, nil
+}
+
+///// 🔹🔹🔹 Legacy → consolidated converters 🔹🔹🔹 /////
+
+// convertInteractionItem keeps the interaction under the sender and indexes it for the receiver.
+// Matched interactions also produce the match summary and a membership row for the sender.
+func convertInteractionItem(item map[string]types.AttributeValue) ([]map[string]types.AttributeValue, error) {
+	var interaction models.Interaction
+	if err := attributevalue.UnmarshalMap(item, &interaction); err != nil {
+		return nil, err
+	}
+	if interaction.SenderHandle == "" || interaction.ReceiverHandle == "" {
+		return nil, fmt.Errorf("interaction %s/%s is missing handles", interaction.PK, interaction.SK)
+	}
+
+	result := []map[string]types.AttributeValue{
+		withKeys(item, models.SingleTableKeys{
+			PK:         models.UserPK(interaction.SenderHandle),
+			SK:         models.InteractionSK(interaction.ReceiverHandle),
+			GSI1PK:     models.UserPK(interaction.ReceiverHandle),
+			GSI1SK:     models.InteractionSK(interaction.SenderHandle),
+			EntityType: models.EntityInteraction,
+		}),
+	}
+
+	if interaction.Status == models.StatusMatch && interaction.MatchID != nil && *interaction.MatchID != "" {
+		matchID := *interaction.MatchID
+		summary, err := attributevalue.MarshalMap(models.MatchSummary{
+			MatchID:      matchID,
+			Participants: []string{interaction.SenderHandle, interaction.ReceiverHandle},
+			CreatedAt:    interaction.LastUpdated,
+		})
+		if err != nil {
+			return nil, err
+		}
+		member := map[string]types.AttributeValue{
+			"matchId":    &types.AttributeValueMemberS{Value: matchID},
+			"userHandle": &types.AttributeValueMemberS{Value: interaction.ReceiverHandle},
+		}
+
+		result = append(result,
+			withKeys(summary, models.SingleTableKeys{
+				PK:         models.MatchPK(matchID),
+				SK:         models.KeyMeta,
+				EntityType: models.EntityMatch,
+			}),
+			withKeys(member, models.SingleTableKeys{
+				PK:         models.UserPK(interaction.SenderHandle),
+				SK:         models.MatchPK(matchID),
+				GSI1PK:     models.MatchPK(matchID),
+				GSI1SK:     models.UserPK(interaction.SenderHandle),
+				EntityType: models.EntityMatchMember,
+			}),
+		)
+	}
+
+	return result, nil
+}
+
+// convertMessageItem moves a 1:1 message under its match partition
+func convertMessageItem(item map[string]types.AttributeValue) ([]map[string]types.AttributeValue, error) {
+	var message models.Message
+	if err := attributevalue.UnmarshalMap(item, &message); err != nil {
+		return nil, err
+	}
+	if message.MatchID == "" || message.CreatedAt == "" {
+		return nil, fmt.Errorf("message %s is missing matchId/createdAt", message.MessageID)
+	}
+
+	return []map[string]types.AttributeValue{
+		withKeys(item, models.SingleTableKeys{
+			PK:         models.MatchPK(message.MatchID),
+			SK:         models.MessageSK(message.CreatedAt, message.MessageID),
+			EntityType: models.EntityMessage,
+		}),
+	}, nil
+}
+
+// convertGroupInteractionItem keeps invites under the inviter (indexed by approver) and
+// memberships under the member (indexed by group). Memberships also produce the group summary.
+func convertGroupInteractionItem(item map[string]types.AttributeValue) ([]map[string]types.AttributeValue, error) {
+	var group models.GroupInteraction
+	if err := attributevalue.UnmarshalMap(item, &group); err != nil {
+		return nil, err
+	}
+
+	switch group.InteractionType {
+	case "group_invite":
+		return []map[string]types.AttributeValue{
+			withKeys(item, models.SingleTableKeys{
+				PK:         models.UserPK(group.InviterHandle),
+				SK:         models.GroupInviteSK(group.InviteeHandle),
+				GSI1PK:     models.UserPK(group.ApproverHandle),
+				GSI1SK:     models.GroupInviteSK(group.InviteeHandle),
+				EntityType: models.EntityGroupInvite,
+			}),
+		}, nil
+	case "group_chat":
+		if group.GroupID == nil || *group.GroupID == "" {
+			return nil, fmt.Errorf("group record %s/%s has no groupId", group.PK, group.SK)
+		}
+		groupID := *group.GroupID
+
+		summary := withKeys(item, models.SingleTableKeys{
+			PK:         models.GroupPK(groupID),
+			SK:         models.KeyMeta,
+			EntityType: models.EntityGroup,
+		})
+		member := withKeys(item, models.SingleTableKeys{
+			PK:         group.PK,
+			SK:         models.GroupPK(groupID),
+			GSI1PK:     models.GroupPK(groupID),
+			GSI1SK:     group.PK,
+			EntityType: models.EntityGroupMember,
+		})
+		return []map[string]types.AttributeValue{summary, member}, nil
+	case models.InteractionTypeGroupApproval:
+		return nil, nil // ✅ Approver pointer rows are derived from the invite's approvers list
+	default:
+		return nil, fmt.Errorf("unknown group interaction type %q", group.InteractionType)
+	}
+}
+
+// convertGroupMessageItem moves a group message under its group partition
+func convertGroupMessageItem(item map[string]types.AttributeValue) ([]map[string]types.AttributeValue, error) {
+	var message models.GroupMessage
+	if err := attributevalue.UnmarshalMap(item, &message); err != nil {
+		return nil, err
+	}
+	if message.GroupID == "" || message.CreatedAt == "" {
+		return nil, fmt.Errorf("group message %s is missing groupId/createdAt", message.MessageID)
+	}
+
+	return []map[string]types.AttributeValue{
+		withKeys(item, models.SingleTableKeys{
+			PK:         models.GroupPK(message.GroupID),
+			SK:         models.MessageSK(message.CreatedAt, message.MessageID),
+			EntityType: models.EntityGroupMsg,
+		}),
+	}, nil
+}
+
+// withKeys copies a legacy item and stamps the consolidated key attributes onto it
+func withKeys(item map[string]types.AttributeValue, keys models.SingleTableKeys) map[string]types.AttributeValue {
+	result := make(map[string]types.AttributeValue, len(item)+5)
+	for k, v := range item {
+		result[k] = v
+	}
+
+	result["PK"] = &types.AttributeValueMemberS{Value: keys.PK}
+	result["SK"] = &types.AttributeValueMemberS{Value: keys.SK}
+	result["entityType"] = &types.AttributeValueMemberS{Value: keys.EntityType}
+	if keys.GSI1PK != "" {
+		result["GSI1PK"] = &types.AttributeValueMemberS{Value: keys.GSI1PK}
+		result["GSI1SK"] = &types.AttributeValueMemberS{Value: keys.GSI1SK}
+	}
+	return result
+}
+
+// dedupeWriteRequests drops repeated PK/SK pairs (e.g. a group summary emitted once per member),
+// since BatchWriteItem rejects duplicate keys within one batch
+func dedupeWriteRequests(requests []types.WriteRequest) []types.WriteRequest {
+	seen := make(map[string]bool, len(requests))
+	unique := make([]types.WriteRequest, 0, len(requests))
+	for _, req := range requests {
+		item := map[string]types.AttributeValue(nil)
+		if req.PutRequest != nil {
+			item = req.PutRequest.Item
+		} else if req.DeleteRequest != nil {
+			item = req.DeleteRequest.Key
+		}
+		id := singleTableID(item)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, req)
+	}
+	return unique
+}
+
+// singleTableID identifies a consolidated item by its PK/SK pair
+func singleTableID(item map[string]types.AttributeValue) string {
+	pk, _ := item["PK"].(*types.AttributeValueMemberS)
+	sk, _ := item["SK"].(*types.AttributeValueMemberS)
+	if pk == nil || sk == nil {
+		return ""
+	}
+	return pk.Value + "|" + sk.Value
+}
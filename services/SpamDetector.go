@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+)
+
+// SpamDetector flags high-risk actions, which then need a CAPTCHA (see CaptchaService)
+type SpamDetector struct {
+	Dynamo *DynamoService
+}
+
+// PingRisk returns the models.SpamSignal* a ping raises, or "" when it looks normal. Lookup failures
+// don't flag the ping: a challenge is friction, not the last line of defense.
+func (d *SpamDetector) PingRisk(ctx context.Context, sender string, message *string) string {
+	if message != nil && linkPattern.MatchString(*message) {
+		return models.SpamSignalLink
+	}
+
+	item, err := d.Dynamo.GetItemAttributes(ctx, models.UserProfilesTable, profileKey(sender), "createdAt")
+	if err != nil {
+		utils.Logf(ctx, "⚠️ Failed to load sign-up time of %s: %v", sender, err)
+		return ""
+	}
+	var profile struct {
+		CreatedAt string `dynamodbav:"createdAt"`
+	}
+	if err := attributevalue.UnmarshalMap(item, &profile); err != nil || profile.CreatedAt == "" {
+		return ""
+	}
+	if createdAt, err := time.Parse(time.RFC3339, profile.CreatedAt); err == nil && time.Since(createdAt) < models.SpamNewAccountAge {
+		return models.SpamSignalNewAccount
+	}
+	return ""
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ErrSpotifyNotConfigured is returned when the Spotify client credentials are not set
+var ErrSpotifyNotConfigured = errors.New("spotify_not_configured")
+
+// ✅ Spotify endpoints and how much of the user's taste is copied
+const (
+	spotifyTokenURL   = "https://accounts.spotify.com/api/token"
+	spotifyAPIBase    = "https://api.spotify.com/v1"
+	spotifyTopArtists = 5
+)
+
+// SpotifyService connects profiles to Spotify. The OAuth code is exchanged once to copy the user's
+// top artists and anthem onto the profile; tokens are not kept, so reconnecting refreshes the data.
+type SpotifyService struct {
+	Dynamo       *DynamoService
+	ClientID     string
+	ClientSecret string
+	RedirectURI  string // Must match the redirect URI the app authorized with
+	HTTPClient   *http.Client
+}
+
+// Enabled reports whether Spotify is configured
+func (s *SpotifyService) Enabled() bool {
+	return s != nil && s.ClientID != "" && s.ClientSecret != "" && s.RedirectURI != ""
+}
+
+// spotifyImage is an album or artist image; Spotify lists the largest first
+type spotifyImage struct {
+	URL string `json:"url"`
+}
+
+// spotifyArtist holds the artist fields we use
+type spotifyArtist struct {
+	ID     string         `json:"id"`
+	Name   string         `json:"name"`
+	Genres []string       `json:"genres"`
+	Images []spotifyImage `json:"images"`
+}
+
+// spotifyTrack holds the track fields we use
+type spotifyTrack struct {
+	ID         string          `json:"id"`
+	Name       string          `json:"name"`
+	PreviewURL string          `json:"preview_url"`
+	Artists    []spotifyArtist `json:"artists"`
+	Album      struct {
+		Images []spotifyImage `json:"images"`
+	} `json:"album"`
+}
+
+// Connect exchanges an OAuth authorization code (scope user-top-read) and stores the user's top
+// artists and anthem on their profile
+func (s *SpotifyService) Connect(ctx context.Context, userHandle, code string) (*models.SpotifyProfile, error) {
+	if !s.Enabled() {
+		return nil, ErrSpotifyNotConfigured
+	}
+	utils.Logf(ctx, "🔍 Connecting Spotify for user: %s", userHandle)
+
+	accessToken, err := s.exchangeCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	var artists struct {
+		Items []spotifyArtist `json:"items"`
+	}
+	if err := s.spotifyGet(ctx, accessToken, fmt.Sprintf("/me/top/artists?limit=%d&time_range=medium_term", spotifyTopArtists), &artists); err != nil {
+		return nil, err
+	}
+	var tracks struct {
+		Items []spotifyTrack `json:"items"`
+	}
+	if err := s.spotifyGet(ctx, accessToken, "/me/top/tracks?limit=1&time_range=short_term", &tracks); err != nil {
+		return nil, err
+	}
+
+	spotify := &models.SpotifyProfile{
+		TopArtists:  make([]models.SpotifyArtist, 0, len(artists.Items)),
+		ConnectedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	for _, artist := range artists.Items {
+		spotify.TopArtists = append(spotify.TopArtists, models.SpotifyArtist{
+			ID:       artist.ID,
+			Name:     artist.Name,
+			ImageURL: firstImage(artist.Images),
+			Genres:   artist.Genres,
+		})
+	}
+	if len(tracks.Items) > 0 {
+		track := tracks.Items[0]
+		anthem := &models.SpotifyTrack{ID: track.ID, Name: track.Name, ImageURL: firstImage(track.Album.Images), PreviewURL: track.PreviewURL}
+		for _, artist := range track.Artists {
+			anthem.Artists = append(anthem.Artists, artist.Name)
+		}
+		spotify.Anthem = anthem
+	}
+
+	spotifyAV, err := attributevalue.Marshal(spotify)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal spotify profile: %w", err)
+	}
+	err = s.updateProfile(ctx, userHandle, "SET spotify = :spotify, updatedAt = :now ADD profileVersion :one",
+		"attribute_exists(userhandle)", "profile not found", map[string]types.AttributeValue{":spotify": spotifyAV})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Logf(ctx, "✅ Connected Spotify for %s (%d top artists)", userHandle, len(spotify.TopArtists))
+	return spotify, nil
+}
+
+// Disconnect removes the Spotify data from the user's profile
+func (s *SpotifyService) Disconnect(ctx context.Context, userHandle string) error {
+	err := s.updateProfile(ctx, userHandle, "REMOVE spotify SET updatedAt = :now ADD profileVersion :one",
+		"attribute_exists(spotify)", "spotify is not connected", nil)
+	if err != nil {
+		return err
+	}
+
+	utils.Logf(ctx, "✅ Disconnected Spotify for %s", userHandle)
+	return nil
+}
+
+// updateProfile applies an update that bumps the profile version, failing with ErrNotFound
+// (notFoundMessage) when the condition does not hold
+func (s *SpotifyService) updateProfile(ctx context.Context, userHandle, update, condition, notFoundMessage string, values map[string]types.AttributeValue) error {
+	if values == nil {
+		values = map[string]types.AttributeValue{}
+	}
+	values[":now"] = &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)}
+	values[":one"] = &types.AttributeValueMemberN{Value: "1"}
+
+	_, err := s.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(models.UserProfilesTable),
+		Key: map[string]types.AttributeValue{
+			"userhandle": &types.AttributeValueMemberS{Value: userHandle},
+		},
+		UpdateExpression:          aws.String(update),
+		ConditionExpression:       aws.String(condition),
+		ExpressionAttributeValues: values,
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return notFoundError(notFoundMessage)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update spotify data for %s: %w", userHandle, err)
+	}
+	return nil
+}
+
+// exchangeCode trades an authorization code for an access token; a rejected code is a validation error
+func (s *SpotifyService) exchangeCode(ctx context.Context, code string) (string, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", s.RedirectURI)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, spotifyTokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(s.ClientID, s.ClientSecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	status, err := s.do(req, &token)
+	if err != nil {
+		return "", err
+	}
+	if status == http.StatusBadRequest {
+		return "", validationError("spotify rejected the authorization code: " + token.ErrorDescription)
+	}
+	if status >= 300 || token.AccessToken == "" {
+		return "", fmt.Errorf("spotify token exchange returned %d: %s", status, token.Error)
+	}
+	return token.AccessToken, nil
+}
+
+// spotifyGet calls the Web API with the user's access token and decodes the JSON response
+func (s *SpotifyService) spotifyGet(ctx context.Context, accessToken, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, spotifyAPIBase+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	status, err := s.do(req, out)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusForbidden {
+		return validationError("spotify access must include the user-top-read scope")
+	}
+	if status >= 300 {
+		return fmt.Errorf("spotify %s returned %d", path, status)
+	}
+	return nil
+}
+
+// do sends a request and decodes the JSON body (of any status) into out
+func (s *SpotifyService) do(req *http.Request, out interface{}) (int, error) {
+	client := s.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("spotify request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read spotify response: %w", err)
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, out); err != nil && resp.StatusCode < 300 {
+			return 0, fmt.Errorf("failed to decode spotify response: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// firstImage returns the largest image URL, or "" when there are none
+func firstImage(images []spotifyImage) string {
+	if len(images) == 0 {
+		return ""
+	}
+	return images[0].URL
+}
@@ -0,0 +1,348 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	streamtypes "github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+	"github.com/google/uuid"
+)
+
+// ✅ Stream polling limits
+const (
+	streamLeaseDuration   = 60 * time.Second // Another instance may take a shard over after this
+	streamLeaseRenewEvery = 20 * time.Second
+	streamIdleWait        = time.Second // Pause when a shard has no new records
+	streamErrorWait       = 5 * time.Second
+	streamRecordAttempts  = 3 // A record that keeps failing is logged and skipped so the shard keeps moving
+	streamRecordsPerBatch = 100
+)
+
+// StreamRecordHandler applies one stream record; it may see a record more than once
+type StreamRecordHandler func(ctx context.Context, record streamtypes.Record) error
+
+// ChainStreamHandlers runs several handlers on one table's records, so features share a consumer
+// (and its shard leases) instead of each reading the stream. A retry reruns every handler.
+func ChainStreamHandlers(handlers ...StreamRecordHandler) StreamRecordHandler {
+	return func(ctx context.Context, record streamtypes.Record) error {
+		for _, handler := range handlers {
+			if err := handler(ctx, record); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// StreamConsumer reads a table's DynamoDB stream and hands every record to Handler. Shards are leased
+// through the StreamCheckpoints table, so each shard is read by one instance at a time and resumes
+// after the last checkpointed record.
+type StreamConsumer struct {
+	Dynamo  *DynamoService
+	Streams *dynamodbstreams.Client
+	Table   string
+	Handler StreamRecordHandler
+
+	ownerID string
+	mu      sync.Mutex
+	reading map[string]bool // Shards this instance is reading
+}
+
+// InitializeDynamoDBStreamsClient initializes the DynamoDB Streams client
+func InitializeDynamoDBStreamsClient(region string) *dynamodbstreams.Client {
+	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion(region))
+	if err != nil {
+		log.Fatalf("Failed to load AWS config: %v", err)
+	}
+	return dynamodbstreams.NewFromConfig(cfg)
+}
+
+// Start discovers the table's shards now and then every interval, reading the ones it can lease,
+// until ctx is cancelled. A table without a stream is logged and skipped.
+func (c *StreamConsumer) Start(ctx context.Context, interval time.Duration) {
+	output, err := c.Dynamo.Client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(c.Table)})
+	if err != nil {
+		utils.Logf(ctx, "⚠️ Not consuming %s stream: %v", c.Table, err)
+		return
+	}
+	streamARN := aws.ToString(output.Table.LatestStreamArn)
+	if streamARN == "" {
+		utils.Logf(ctx, "⚠️ Not consuming %s stream: streams are not enabled on the table", c.Table)
+		return
+	}
+
+	hostname, _ := os.Hostname()
+	c.ownerID = hostname + "/" + uuid.New().String()
+	c.reading = map[string]bool{}
+	utils.Logf(ctx, "✅ Consuming %s stream as %s", c.Table, c.ownerID)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := c.discoverShards(ctx, streamARN); err != nil {
+				utils.Logf(ctx, "⚠️ %s shard discovery failed: %v", c.Table, err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// discoverShards starts a reader for every shard that is ready (parent drained) and leasable
+func (c *StreamConsumer) discoverShards(ctx context.Context, streamARN string) error {
+	var shards []streamtypes.Shard
+	var startShardID *string
+	for {
+		output, err := c.Streams.DescribeStream(ctx, &dynamodbstreams.DescribeStreamInput{
+			StreamArn:             aws.String(streamARN),
+			ExclusiveStartShardId: startShardID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to describe stream: %w", err)
+		}
+		shards = append(shards, output.StreamDescription.Shards...)
+		startShardID = output.StreamDescription.LastEvaluatedShardId
+		if startShardID == nil {
+			break
+		}
+	}
+
+	listed := make(map[string]bool, len(shards))
+	for _, shard := range shards {
+		listed[aws.ToString(shard.ShardId)] = true
+	}
+
+	for _, shard := range shards {
+		shardID := aws.ToString(shard.ShardId)
+		c.mu.Lock()
+		busy := c.reading[shardID]
+		c.mu.Unlock()
+		if busy {
+			continue
+		}
+
+		// ✅ Children wait until their parent is drained so records stay in order per item
+		if parentID := aws.ToString(shard.ParentShardId); parentID != "" && listed[parentID] {
+			parent, err := c.getCheckpoint(ctx, parentID)
+			if err != nil {
+				return err
+			}
+			if parent == nil || !parent.Finished {
+				continue
+			}
+		}
+
+		checkpoint, err := c.acquireLease(ctx, shardID)
+		if err != nil {
+			return err
+		}
+		if checkpoint == nil {
+			continue
+		}
+
+		c.mu.Lock()
+		c.reading[shardID] = true
+		c.mu.Unlock()
+		go func() {
+			defer func() {
+				c.mu.Lock()
+				delete(c.reading, shardID)
+				c.mu.Unlock()
+			}()
+			c.readShard(ctx, streamARN, shardID, checkpoint.SequenceNumber)
+		}()
+	}
+	return nil
+}
+
+// readShard processes a leased shard until it is drained, the lease is lost or ctx is cancelled
+func (c *StreamConsumer) readShard(ctx context.Context, streamARN, shardID, sequenceNumber string) {
+	utils.Logf(ctx, "🔄 Reading %s shard %s from %q", c.Table, shardID, sequenceNumber)
+	iterator, err := c.shardIterator(ctx, streamARN, shardID, sequenceNumber)
+	if err != nil {
+		utils.Logf(ctx, "❌ Failed to open %s shard %s: %v", c.Table, shardID, err)
+		return
+	}
+
+	lastRenewal := time.Now()
+	for iterator != nil && ctx.Err() == nil {
+		output, err := c.Streams.GetRecords(ctx, &dynamodbstreams.GetRecordsInput{
+			ShardIterator: iterator,
+			Limit:         aws.Int32(streamRecordsPerBatch),
+		})
+		var expired *streamtypes.ExpiredIteratorException
+		var trimmed *streamtypes.TrimmedDataAccessException
+		switch {
+		case errors.As(err, &expired):
+			iterator, err = c.shardIterator(ctx, streamARN, shardID, sequenceNumber)
+			if err != nil {
+				utils.Logf(ctx, "❌ Failed to reopen %s shard %s: %v", c.Table, shardID, err)
+				return
+			}
+			continue
+		case errors.As(err, &trimmed):
+			utils.Logf(ctx, "⚠️ %s shard %s trimmed past %q; resuming at the oldest record", c.Table, shardID, sequenceNumber)
+			sequenceNumber = ""
+			iterator, err = c.shardIterator(ctx, streamARN, shardID, sequenceNumber)
+			if err != nil {
+				return
+			}
+			continue
+		case err != nil:
+			utils.Logf(ctx, "⚠️ Failed to read %s shard %s: %v", c.Table, shardID, err)
+			time.Sleep(streamErrorWait)
+			continue
+		}
+
+		for _, record := range output.Records {
+			c.handle(ctx, record)
+			sequenceNumber = aws.ToString(record.Dynamodb.SequenceNumber)
+		}
+		iterator = output.NextShardIterator
+
+		if len(output.Records) > 0 || iterator == nil || time.Since(lastRenewal) > streamLeaseRenewEvery {
+			if err := c.checkpoint(ctx, shardID, sequenceNumber, iterator == nil); err != nil {
+				utils.Logf(ctx, "⚠️ Stopped reading %s shard %s: %v", c.Table, shardID, err)
+				return
+			}
+			lastRenewal = time.Now()
+		}
+		if len(output.Records) == 0 && iterator != nil {
+			time.Sleep(streamIdleWait)
+		}
+	}
+	if iterator == nil {
+		utils.Logf(ctx, "✅ Finished %s shard %s", c.Table, shardID)
+	}
+}
+
+// handle runs the handler with a few retries; a record that keeps failing is skipped
+func (c *StreamConsumer) handle(ctx context.Context, record streamtypes.Record) {
+	var err error
+	for attempt := 1; attempt <= streamRecordAttempts; attempt++ {
+		if err = c.Handler(ctx, record); err == nil {
+			return
+		}
+		time.Sleep(time.Duration(attempt) * 200 * time.Millisecond)
+	}
+	utils.Logf(ctx, "❌ Skipping %s stream record %s after %d attempts: %v", c.Table, aws.ToString(record.EventID), streamRecordAttempts, err)
+}
+
+// shardIterator opens the shard after sequenceNumber, or at its oldest record when there is none
+func (c *StreamConsumer) shardIterator(ctx context.Context, streamARN, shardID, sequenceNumber string) (*string, error) {
+	input := &dynamodbstreams.GetShardIteratorInput{
+		StreamArn:         aws.String(streamARN),
+		ShardId:           aws.String(shardID),
+		ShardIteratorType: streamtypes.ShardIteratorTypeTrimHorizon,
+	}
+	if sequenceNumber != "" {
+		input.ShardIteratorType = streamtypes.ShardIteratorTypeAfterSequenceNumber
+		input.SequenceNumber = aws.String(sequenceNumber)
+	}
+	output, err := c.Streams.GetShardIterator(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return output.ShardIterator, nil
+}
+
+///// 🔹🔹🔹 Leases and checkpoints 🔹🔹🔹 /////
+
+func (c *StreamConsumer) shardKey(shardID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"shardKey": &types.AttributeValueMemberS{Value: c.Table + "#" + shardID},
+	}
+}
+
+// getCheckpoint returns a shard's checkpoint, or nil if it was never leased
+func (c *StreamConsumer) getCheckpoint(ctx context.Context, shardID string) (*models.StreamCheckpoint, error) {
+	item, err := c.Dynamo.GetItem(ctx, models.StreamCheckpointsTable, c.shardKey(shardID))
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var checkpoint models.StreamCheckpoint
+	if err := attributevalue.UnmarshalMap(item, &checkpoint); err != nil {
+		return nil, err
+	}
+	return &checkpoint, nil
+}
+
+// acquireLease takes the shard if it is free, expired or already ours; nil means another instance holds
+// it or it is finished
+func (c *StreamConsumer) acquireLease(ctx context.Context, shardID string) (*models.StreamCheckpoint, error) {
+	now := time.Now()
+	output, err := c.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(models.StreamCheckpointsTable),
+		Key:                 c.shardKey(shardID),
+		UpdateExpression:    aws.String("SET leaseOwner = :owner, leaseExpiresAt = :expires, finished = if_not_exists(finished, :false)"),
+		ConditionExpression: aws.String("(attribute_not_exists(shardKey) OR leaseOwner = :owner OR leaseExpiresAt < :now) AND (attribute_not_exists(finished) OR finished = :false)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":owner":   &types.AttributeValueMemberS{Value: c.ownerID},
+			":expires": &types.AttributeValueMemberN{Value: strconv.FormatInt(now.Add(streamLeaseDuration).Unix(), 10)},
+			":now":     &types.AttributeValueMemberN{Value: strconv.FormatInt(now.Unix(), 10)},
+			":false":   &types.AttributeValueMemberBOOL{Value: false},
+		},
+		ReturnValues: types.ReturnValueAllNew,
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lease shard %s: %w", shardID, err)
+	}
+
+	var checkpoint models.StreamCheckpoint
+	if err := attributevalue.UnmarshalMap(output.Attributes, &checkpoint); err != nil {
+		return nil, err
+	}
+	return &checkpoint, nil
+}
+
+// checkpoint records progress and renews the lease; it fails once another instance owns the shard
+func (c *StreamConsumer) checkpoint(ctx context.Context, shardID, sequenceNumber string, finished bool) error {
+	update := "SET leaseExpiresAt = :expires, finished = :finished"
+	values := map[string]types.AttributeValue{
+		":owner":    &types.AttributeValueMemberS{Value: c.ownerID},
+		":expires":  &types.AttributeValueMemberN{Value: strconv.FormatInt(time.Now().Add(streamLeaseDuration).Unix(), 10)},
+		":finished": &types.AttributeValueMemberBOOL{Value: finished},
+	}
+	if sequenceNumber != "" {
+		update += ", sequenceNumber = :sequence"
+		values[":sequence"] = &types.AttributeValueMemberS{Value: sequenceNumber}
+	}
+
+	_, err := c.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(models.StreamCheckpointsTable),
+		Key:                       c.shardKey(shardID),
+		UpdateExpression:          aws.String(update),
+		ConditionExpression:       aws.String("leaseOwner = :owner"),
+		ExpressionAttributeValues: values,
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return errors.New("lease lost to another instance")
+	}
+	return err
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// tracingServiceName is reported as service.name unless OTEL_SERVICE_NAME overrides it
+const tracingServiceName = "vibin_server"
+
+// InitializeTracing installs the global tracer provider exporting over OTLP/HTTP to endpoint.
+// With an empty endpoint tracing stays a no-op. The exporter and sampler also honour the
+// standard OTEL_* variables (headers, OTEL_TRACES_SAMPLER, ...). The returned functions export
+// buffered spans (flush) and stop the provider after a final export (shutdown).
+func InitializeTracing(ctx context.Context, endpoint string) (flush, shutdown func(context.Context) error, err error) {
+	if endpoint == "" {
+		log.Println("ℹ️ Tracing disabled (OTEL_EXPORTER_OTLP_ENDPOINT not set)")
+		noop := func(context.Context) error { return nil }
+		return noop, noop, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", tracingServiceName)),
+		resource.WithFromEnv(), // ✅ OTEL_SERVICE_NAME / OTEL_RESOURCE_ATTRIBUTES win
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	log.Printf("✅ Tracing enabled, exporting to %s", endpoint)
+	return provider.ForceFlush, provider.Shutdown, nil
+}
@@ -4,29 +4,97 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"math"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 	"vibin_server/models"
+	"vibin_server/utils"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"golang.org/x/sync/errgroup"
 )
 
 type UserProfileService struct {
-	Dynamo *DynamoService
+	Dynamo    *DynamoService
+	PII       *PIIService
+	Analytics *AnalyticsService // ✅ Set after construction (analytics reads consents from this service)
+	Webhooks  *WebhookService
+	S3        *S3Service           // ✅ Presigns voice prompt URLs; set after construction
+	Blocks    *BlockService        // ✅ Discovery exclusions (blocks, reports, past interactions)
+	Contacts  *ContactService      // ✅ Contact sync modes (hide contacts, mutual connections)
+	Geocoding *GeocodingService    // ✅ Names the city/region of locations for place browsing
+	Shown     *ShownProfileService // ✅ Keeps recently served profiles out of the next suggestion batches
+
+	MinSuggestionCompleteness int // ✅ Suggestions scoring below this go to the back of the list (0 = off)
+
+	NewUserBoost float64 // ✅ Distance divisor for profiles created within models.NewUserWindow (1 or less = off)
 }
 
-// AddUserProfile adds a new user profile to DynamoDB
+// ErrProfileExists is returned when creating a profile for a userhandle that already has one
+var ErrProfileExists = conflictError("a profile already exists for this userhandle")
+
+// AddUserProfile creates a new user profile. It never replaces an existing one (edits go through
+// UpdateUserProfile), so state the server manages can't be reset by creating the profile again.
 func (ups *UserProfileService) AddUserProfile(ctx context.Context, profile models.UserProfile) (*models.UserProfile, error) {
-	err := ups.Dynamo.PutItem(ctx, models.UserProfilesTable, profile)
+	// ✅ Encrypt phone/email on the stored copy; the caller gets plaintext back
+	profile.ProfileVersion = 1
+	profile.UpdatedAt = time.Now().Format(time.RFC3339)
+	clearServerManagedFields(&profile)
+	profile.RefreshAge(time.Now()) // ✅ Age comes from the (validated) date of birth, not the client
+	profile.CreatedAt = profile.UpdatedAt
+	profile.ContactIndex = ups.PII.PhoneContactIndex(profile.PhoneNumber)                         // ✅ Lets contact sync find this user
+	profile.InterestIDs = canonicalInterestIDs(append(profile.InterestIDs, profile.Interests...)) // ✅ Free-form interests map onto the catalog
+	stored := profile
+	if err := ups.PII.ProtectProfile(ctx, &stored); err != nil {
+		utils.Logf(ctx, "❌ Failed to protect PII for %s: %v", profile.UserHandle, err)
+		return nil, err
+	}
+
+	item, err := attributevalue.MarshalMap(stored)
 	if err != nil {
 		return nil, err
 	}
+	_, err = ups.Dynamo.Client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(models.UserProfilesTable),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(userhandle)"),
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return nil, ErrProfileExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create profile %s: %w", profile.UserHandle, err)
+	}
+	ups.Analytics.Track(ctx, models.EventProfileCreated, profile.UserHandle, nil)
+	ups.Webhooks.Publish(ctx, models.WebhookUserCreated, map[string]interface{}{"userhandle": profile.UserHandle})
 	return &profile, nil
 }
 
+// clearServerManagedFields drops state a client must not set on a new profile: moderation, age
+// verification, billing, photo processing and account lifecycle are only written by their own services
+func clearServerManagedFields(profile *models.UserProfile) {
+	profile.AgeStatus = ""
+	profile.AccountStatus = ""
+	profile.SuspendedUntil = ""
+	profile.Shadowbanned = false
+	profile.Subscription = nil // ✅ Billing state only changes through verified Stripe webhooks and promo codes
+	profile.Passport = nil     // ✅ A premium feature, set through the passport routes
+	profile.QuarantinedPhotos = nil
+	profile.PhotoRenditions = nil
+	profile.DeletedAt = ""
+	profile.PurgeAfter = ""
+	profile.PendingDeletion = ""
+	profile.Paused = false
+	profile.PausedUntil = ""
+}
+
 // GetUserProfile retrieves a user profile by ID
 func (ups *UserProfileService) GetUserProfile(ctx context.Context, emailID string) (*models.UserProfile, error) {
 	key := map[string]types.AttributeValue{
@@ -39,7 +107,7 @@ func (ups *UserProfileService) GetUserProfile(ctx context.Context, emailID strin
 	}
 
 	if item == nil {
-		return nil, errors.New("profile not found")
+		return nil, notFoundError("profile not found")
 	}
 
 	var profile models.UserProfile
@@ -53,24 +121,18 @@ func (ups *UserProfileService) GetUserProfile(ctx context.Context, emailID strin
 
 // GetUserProfileByEmail fetches a user profile based on the email GSI (`emailId-index`)
 func (ups *UserProfileService) GetUserProfileByEmail(ctx context.Context, emailID string) (*models.UserProfile, error) {
-	log.Printf("🔍 Fetching user profile for email: %s", emailID)
+	utils.Logf(ctx, "🔍 Fetching user profile for email: %s", emailID)
 
-	// Define query parameters for the GSI (emailId-index)
-	keyCondition := "emailId = :emailId"
-	expressionAttributeValues := map[string]types.AttributeValue{
-		":emailId": &types.AttributeValueMemberS{Value: emailID},
-	}
-
-	// Query the GSI (emailId-index)
-	items, err := ups.Dynamo.QueryItemsWithIndex(ctx, models.UserProfilesTable, "emailId-index", keyCondition, expressionAttributeValues, nil, 1)
+	// Query the email blind index (falls back to the legacy plaintext index)
+	items, err := ups.queryProfilesByEmail(ctx, emailID)
 	if err != nil {
-		log.Printf("❌ Error querying email index: %v", err)
+		utils.Logf(ctx, "❌ Error querying email index: %v", err)
 		return nil, fmt.Errorf("failed to fetch profile by email: %w", err)
 	}
 
 	// If no profile is found, return nil
 	if len(items) == 0 {
-		log.Printf("❌ No profile found for email: %s", emailID)
+		utils.Logf(ctx, "❌ No profile found for email: %s", emailID)
 		return nil, nil
 	}
 
@@ -78,79 +140,151 @@ func (ups *UserProfileService) GetUserProfileByEmail(ctx context.Context, emailI
 	var profile models.UserProfile
 	err = attributevalue.UnmarshalMap(items[0], &profile)
 	if err != nil {
-		log.Printf("❌ Error unmarshalling user profile: %v", err)
+		utils.Logf(ctx, "❌ Error unmarshalling user profile: %v", err)
 		return nil, fmt.Errorf("failed to unmarshal profile: %w", err)
 	}
+	ups.PII.UnprotectProfile(ctx, &profile)
+	profile.RefreshAge(time.Now())
+	completeness := ups.Completeness(&profile)
+	profile.Completeness = &completeness
+	ups.presignAudioPrompt(ctx, &profile)
 
-	log.Printf("✅ Successfully fetched user profile: %+v", profile)
+	utils.Logf(ctx, "✅ Successfully fetched user profile: %s", profile.UserHandle)
 	return &profile, nil
 }
 
-// UpdateUserProfile updates an existing user profile
-func (ups *UserProfileService) UpdateUserProfile(ctx context.Context, emailID string, updates map[string]interface{}) (*models.UserProfile, error) {
-	key := map[string]types.AttributeValue{
-		"emailId": &types.AttributeValueMemberS{Value: emailID},
+// UpdateUserProfile applies a user's edit to their profile with a targeted update, leaving every
+// field the edit doesn't carry (moderation, billing, age verification, ...) as stored
+func (ups *UserProfileService) UpdateUserProfile(ctx context.Context, userHandle string, update models.ProfileUpdate) (*models.UserProfile, error) {
+	if update.Videos != nil {
+		for _, video := range *update.Videos {
+			if !models.OwnsUploadKey(userHandle, video) {
+				return nil, validationError(fmt.Sprintf("%s was not uploaded by this user", video))
+			}
+		}
 	}
 
-	// Construct UpdateExpression, ExpressionAttributeValues, and ExpressionAttributeNames
-	updateExpression := "SET"
-	expressionAttributeValues := make(map[string]types.AttributeValue)
-	expressionAttributeNames := make(map[string]string)
-
-	for field, value := range updates {
-		placeholder := ":" + field
-		attributeName := "#" + field
-		updateExpression += " " + attributeName + " = " + placeholder + ","
-
-		// Convert value dynamically
-		switch v := value.(type) {
-		case string:
-			expressionAttributeValues[placeholder] = &types.AttributeValueMemberS{Value: v}
-		case bool:
-			expressionAttributeValues[placeholder] = &types.AttributeValueMemberBOOL{Value: v}
-		case int:
-			expressionAttributeValues[placeholder] = &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", v)}
-		case float64:
-			expressionAttributeValues[placeholder] = &types.AttributeValueMemberN{Value: fmt.Sprintf("%f", v)}
-		case []string:
-			stringSlice, _ := attributevalue.MarshalList(v)
-			expressionAttributeValues[placeholder] = &types.AttributeValueMemberL{Value: stringSlice}
-		default:
-			return nil, fmt.Errorf("unsupported update type for field %s", field)
+	edit := profileEdit{values: map[string]types.AttributeValue{}, names: map[string]string{}}
+	condition := "attribute_exists(userhandle)" // ✅ Never create a profile here
+	if update.DOB != nil {
+		// ✅ Age is derived from the date of birth, which must stay adult; disputes go through age verification
+		born, err := utils.ParseDOB(*update.DOB)
+		if err != nil {
+			return nil, validationError("dob must be a YYYY-MM-DD date")
 		}
-
-		expressionAttributeNames[attributeName] = field
+		age := utils.AgeOn(born, time.Now())
+		if age < models.MinUserAge || age > models.MaxUserAge {
+			return nil, validationError(fmt.Sprintf("dob must be between %d and %d years ago", models.MinUserAge, models.MaxUserAge))
+		}
+		setProfileField(&edit, "dob", update.DOB)
+		setProfileField(&edit, "age", &age)
+		condition += " AND attribute_not_exists(ageStatus)"
+	}
+	setProfileField(&edit, "name", update.Name)
+	setProfileField(&edit, "username", update.UserName)
+	setProfileField(&edit, "hideName", update.HideName)
+	setProfileField(&edit, "bio", update.Bio)
+	setProfileField(&edit, "desires", update.Desires)
+	setProfileField(&edit, "gender", update.Gender)
+	setProfileField(&edit, "lookingFor", update.LookingFor)
+	setProfileField(&edit, "orientation", update.Orientation)
+	setProfileField(&edit, "showGenderOnProfile", update.ShowGenderOnProfile)
+	setProfileField(&edit, "videos", update.Videos)
+	setProfileField(&edit, "questionnaire", update.Questionnaire)
+	if edit.err != nil {
+		return nil, edit.err
+	}
+	if len(edit.sets) == 0 && len(edit.removes) == 0 {
+		return ups.GetUserProfileByHandle(ctx, userHandle)
 	}
 
-	// Remove trailing comma
-	updateExpression = updateExpression[:len(updateExpression)-1]
+	// ✅ Every update bumps the version so cached snapshots can detect changes
+	edit.sets = append(edit.sets, "updatedAt = :updatedAt")
+	edit.values[":updatedAt"] = &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)}
+	edit.values[":one"] = &types.AttributeValueMemberN{Value: "1"}
+	expression := "SET " + strings.Join(edit.sets, ", ")
+	if len(edit.removes) > 0 {
+		expression += " REMOVE " + strings.Join(edit.removes, ", ")
+	}
+	expression += " ADD profileVersion :one"
 
-	// Call UpdateItem with correctly formatted parameters
-	updatedItem, err := ups.Dynamo.UpdateItem(ctx, models.UserProfilesTable, updateExpression, key, expressionAttributeValues, expressionAttributeNames)
+	output, err := ups.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                           aws.String(models.UserProfilesTable),
+		Key:                                 profileKey(userHandle),
+		UpdateExpression:                    aws.String(expression),
+		ConditionExpression:                 aws.String(condition),
+		ExpressionAttributeNames:            edit.names,
+		ExpressionAttributeValues:           edit.values,
+		ReturnValues:                        types.ReturnValueAllNew,
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		if conditionFailed.Item == nil {
+			return nil, notFoundError("user profile not found")
+		}
+		return nil, forbiddenError("dob can't be changed while the age is disputed or rejected")
+	}
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to update profile %s: %w", userHandle, err)
 	}
 
-	// Unmarshal response
 	var updatedProfile models.UserProfile
-	err = attributevalue.UnmarshalMap(updatedItem, &updatedProfile)
-	if err != nil {
+	if err := attributevalue.UnmarshalMap(output.Attributes, &updatedProfile); err != nil {
 		return nil, err
 	}
-
+	ups.PII.UnprotectProfile(ctx, &updatedProfile)
+	utils.Logf(ctx, "✅ Updated profile of %s (version %d)", userHandle, updatedProfile.ProfileVersion)
 	return &updatedProfile, nil
 }
 
-// DeleteUserProfile removes a user profile from DynamoDB
-func (ups *UserProfileService) DeleteUserProfile(ctx context.Context, userID string) error {
-	key := map[string]types.AttributeValue{
-		"userId": &types.AttributeValueMemberS{Value: userID},
+// profileEdit collects the SET and REMOVE clauses of a profile update
+type profileEdit struct {
+	sets    []string
+	removes []string
+	names   map[string]string
+	values  map[string]types.AttributeValue
+	err     error
+}
+
+// setProfileField adds field = *value to the edit; a nil value leaves the field alone and an empty
+// one removes it
+func setProfileField[T any](e *profileEdit, field string, value *T) {
+	if value == nil || e.err != nil {
+		return
+	}
+	e.names["#"+field] = field
+	av, err := attributevalue.Marshal(*value)
+	if err != nil {
+		e.err = fmt.Errorf("failed to marshal %s: %w", field, err)
+		return
+	}
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		if v.Value == "" {
+			e.removes = append(e.removes, "#"+field)
+			return
+		}
+	case *types.AttributeValueMemberL:
+		if len(v.Value) == 0 {
+			e.removes = append(e.removes, "#"+field)
+			return
+		}
+	case *types.AttributeValueMemberM:
+		if len(v.Value) == 0 {
+			e.removes = append(e.removes, "#"+field)
+			return
+		}
+	case *types.AttributeValueMemberNULL:
+		e.removes = append(e.removes, "#"+field)
+		return
 	}
-	return ups.Dynamo.DeleteItem(ctx, models.UserProfilesTable, key)
+	e.sets = append(e.sets, "#"+field+" = :"+field)
+	e.values[":"+field] = av
 }
 
 func (ups *UserProfileService) IsUserHandleAvailable(ctx context.Context, userHandle string) (bool, error) {
-	log.Printf("🔍 Checking availability of userhandle: %s", userHandle)
+	utils.Logf(ctx, "🔍 Checking availability of userhandle: %s", userHandle)
 
 	// Define the partition key for lookup
 	key := map[string]types.AttributeValue{
@@ -160,71 +294,59 @@ func (ups *UserProfileService) IsUserHandleAvailable(ctx context.Context, userHa
 	// Fetch item using GetItem
 	item, err := ups.Dynamo.GetItem(ctx, models.UserProfilesTable, key)
 	if err != nil {
-		// ✅ Check if error contains "item not found"
-		if strings.Contains(err.Error(), "item not found") {
-			log.Printf("✅ Userhandle '%s' is available (not found in DynamoDB).", userHandle)
+		// ✅ A missing item means the handle is free
+		if errors.Is(err, ErrNotFound) {
+			utils.Logf(ctx, "✅ Userhandle '%s' is available (not found in DynamoDB).", userHandle)
 			return true, nil
 		}
 
 		// ❌ Unexpected errors should still be logged and returned
-		log.Printf("❌ Unexpected error retrieving userhandle '%s' from DynamoDB: %v", userHandle, err)
+		utils.Logf(ctx, "❌ Unexpected error retrieving userhandle '%s' from DynamoDB: %v", userHandle, err)
 		return false, fmt.Errorf("failed to check userhandle: %w", err)
 	}
 
 	// If no item is returned, the userhandle is available
 	if item == nil || len(item) == 0 {
-		log.Printf("✅ Userhandle '%s' is available.", userHandle)
+		utils.Logf(ctx, "✅ Userhandle '%s' is available.", userHandle)
 		return true, nil
 	}
 
 	// ❌ Userhandle exists, return false
-	log.Printf("❌ Userhandle '%s' is already taken.", userHandle)
+	utils.Logf(ctx, "❌ Userhandle '%s' is already taken.", userHandle)
 	return false, nil
 }
 
 // CheckEmailExists checks if an email ID exists in the database
 func (ups *UserProfileService) CheckEmailExists(ctx context.Context, emailID string) (bool, error) {
-	log.Printf("🔍 Checking if email exists: %s", emailID)
+	utils.Logf(ctx, "🔍 Checking if email exists: %s", emailID)
 
-	// Define query parameters
-	keyCondition := "emailId = :emailId"
-	expressionAttributeValues := map[string]types.AttributeValue{
-		":emailId": &types.AttributeValueMemberS{Value: emailID},
-	}
-
-	// Query GSI (emailId-index)
-	items, err := ups.Dynamo.QueryItemsWithIndex(ctx, models.UserProfilesTable, "emailId-index", keyCondition, expressionAttributeValues, nil, 1)
+	// Query the email blind index (falls back to the legacy plaintext index)
+	items, err := ups.queryProfilesByEmail(ctx, emailID)
 	if err != nil {
-		log.Printf("❌ Error querying email index: %v", err)
+		utils.Logf(ctx, "❌ Error querying email index: %v", err)
 		return false, fmt.Errorf("failed to check email existence: %w", err)
 	}
 
 	// If items found, email exists
 	exists := len(items) > 0
-	log.Printf("✅ Email found: %t", exists)
+	utils.Logf(ctx, "✅ Email found: %t", exists)
 	return exists, nil
 }
 
 // GetUserHandleByEmail retrieves a userhandle based on an email lookup
 func (ups *UserProfileService) GetUserHandleByEmail(ctx context.Context, emailID string) (string, error) {
-	log.Printf("🔍 Fetching userhandle for email: %s", emailID)
-
-	// Define query parameters
-	keyCondition := "emailId = :emailId"
-	expressionAttributeValues := map[string]types.AttributeValue{
-		":emailId": &types.AttributeValueMemberS{Value: emailID},
-	}
+	utils.Logf(ctx, "🔍 Fetching userhandle for email: %s", emailID)
 
-	// Query GSI (emailId-index)
-	items, err := ups.Dynamo.QueryItemsWithIndex(ctx, models.UserProfilesTable, "emailId-index", keyCondition, expressionAttributeValues, nil, 1)
+	// Query the email blind index (falls back to the legacy plaintext index)
+	items, err := ups.queryProfilesByEmail(ctx, emailID)
 	if err != nil {
-		log.Printf("❌ Error querying email index: %v", err)
+		utils.Logf(ctx, "❌ Error querying email index: %v", err)
 		return "", fmt.Errorf("failed to fetch userhandle: %w", err)
 	}
 
 	// If no item found, return 404
 	if len(items) == 0 {
-		log.Printf("❌ Email not found: %s", emailID)
+		utils.Logf(ctx, "❌ Email not found: %s", emailID)
 		return "", nil
 	}
 
@@ -232,11 +354,11 @@ func (ups *UserProfileService) GetUserHandleByEmail(ctx context.Context, emailID
 	var profile models.UserProfile
 	err = attributevalue.UnmarshalMap(items[0], &profile)
 	if err != nil {
-		log.Printf("❌ Error unmarshalling user profile: %v", err)
+		utils.Logf(ctx, "❌ Error unmarshalling user profile: %v", err)
 		return "", fmt.Errorf("failed to unmarshal user profile: %w", err)
 	}
 
-	log.Printf("✅ Found userhandle: %s for email: %s", profile.UserHandle, emailID)
+	utils.Logf(ctx, "✅ Found userhandle: %s for email: %s", profile.UserHandle, emailID)
 	return profile.UserHandle, nil
 }
 
@@ -256,82 +378,296 @@ func haversine(lat1, lon1, lat2, lon2 float64) float64 {
 	return R * c
 }
 
-// GetUserSuggestions retrieves a list of users based on gender & interaction history
-func (ups *UserProfileService) GetUserSuggestions(ctx context.Context, userHandle, gender string) ([]models.UserProfile, error) {
-	log.Printf("🔍 Fetching user suggestions for gender: %s, excluding interactions from: %s", gender, userHandle)
+// suggestionsPerGender caps the profiles read per stored gender spelling when building suggestions
+const suggestionsPerGender = 50
+
+// ProfileSnapshotMaxAge bounds how long a suggestion card may be shown without re-checking its version
+const ProfileSnapshotMaxAge = 10 * time.Minute
+
+// GetUserSuggestions retrieves users compatible with the requester (orientation and lookingFor, both
+// ways) they haven't interacted with; requestedGenders optionally narrows the result ("everyone" or
+// none = every gender the requester is interested in)
+func (ups *UserProfileService) GetUserSuggestions(ctx context.Context, userHandle string, requestedGenders []string) ([]models.UserProfile, error) {
+	utils.Logf(ctx, "🔍 Fetching user suggestions for %s (genders: %v)", userHandle, requestedGenders)
 
 	// Step 1: Fetch the requester's latitude & longitude
 	requesterProfile, err := ups.GetUserProfileByHandle(ctx, userHandle)
 	if err != nil {
-		log.Printf("❌ Error fetching requester profile: %v", err)
+		utils.Logf(ctx, "❌ Error fetching requester profile: %v", err)
 		return nil, fmt.Errorf("failed to fetch requester profile: %w", err)
 	}
 
-	if requesterProfile.Latitude == 0 || requesterProfile.Longitude == 0 {
-		log.Println("⚠️ Requester profile does not have valid latitude/longitude")
+	// ✅ An active passport location replaces the GPS coordinates until it expires
+	latitude, longitude := requesterProfile.DiscoveryLocation(time.Now())
+	if latitude == 0 || longitude == 0 {
+		utils.Logln(ctx, "⚠️ Requester profile does not have valid latitude/longitude")
 		return nil, fmt.Errorf("requester location missing")
 	}
 
-	// Step 2: Fetch interaction history (liked/disliked profiles)
-	interactionService := InteractionService{Dynamo: ups.Dynamo} // Use InteractionService
-	interactedUsersList, err := interactionService.GetInteractedUsers(ctx, userHandle, []string{models.InteractionTypeLike, models.InteractionTypeDislike})
+	// Step 2: Query the `gender-index` GSI for every gender the requester wants to see, narrowed to
+	// the requested genders unless they asked for everyone
+	genders := requesterProfile.SeekingGenders()
+	if len(requestedGenders) > 0 && !slices.Contains(requestedGenders, models.GenderEveryone) {
+		genders = slices.DeleteFunc(slices.Clone(genders), func(g string) bool {
+			return !slices.ContainsFunc(requestedGenders, func(requested string) bool {
+				return models.CanonicalGender(requested) == g
+			})
+		})
+	}
+	items, err := ups.queryGenders(ctx, genders)
 	if err != nil {
-		log.Printf("❌ Error fetching interaction history: %v", err)
-		return nil, fmt.Errorf("failed to fetch interactions: %w", err)
+		utils.Logf(ctx, "❌ Error querying gender index: %v", err)
+		return nil, fmt.Errorf("failed to fetch user suggestions: %w", err)
 	}
 
-	// Convert interactedUsersList (slice) to a map for quick lookups
-	interactedUsers := make(map[string]bool)
-	for _, user := range interactedUsersList {
-		interactedUsers[user] = true
+	if len(items) == 0 {
+		utils.Logln(ctx, "⚠️ No profiles found matching the criteria.")
+		return []models.UserProfile{}, nil
 	}
 
-	// Step 3: Query the `gender-index` GSI to get potential matches
-	keyCondition := "gender = :gender"
-	expressionAttributeValues := map[string]types.AttributeValue{
-		":gender": &types.AttributeValueMemberS{Value: gender},
+	// Step 3: Unmarshal result into a list of UserProfile structs
+	var profiles []models.UserProfile
+	err = attributevalue.UnmarshalListOfMaps(items, &profiles)
+	if err != nil {
+		utils.Logf(ctx, "❌ Error unmarshalling user profiles: %v", err)
+		return nil, fmt.Errorf("failed to unmarshal user profiles: %w", err)
 	}
 
-	items, err := ups.Dynamo.QueryItemsWithIndex(ctx, models.UserProfilesTable, "gender-index", keyCondition, expressionAttributeValues, nil, 50)
+	filteredProfiles, err := ups.rankSuggestions(ctx, requesterProfile, profiles)
 	if err != nil {
-		log.Printf("❌ Error querying gender index: %v", err)
-		return nil, fmt.Errorf("failed to fetch user suggestions: %w", err)
+		return nil, err
 	}
 
-	if len(items) == 0 {
-		log.Println("⚠️ No profiles found matching the criteria.")
-		return []models.UserProfile{}, nil
+	// ✅ Don't serve the same people again for the next few refreshes, then remember this batch
+	shown, err := ups.Shown.RecentlyShown(ctx, userHandle)
+	if err != nil {
+		utils.Logf(ctx, "⚠️ Could not load profiles already shown to %s: %v", userHandle, err)
+	}
+	filteredProfiles = slices.DeleteFunc(filteredProfiles, func(profile models.UserProfile) bool {
+		return shown[profile.UserHandle]
+	})
+	served := make([]string, len(filteredProfiles))
+	for i, profile := range filteredProfiles {
+		served[i] = profile.UserHandle
+	}
+	if err := ups.Shown.RecordShown(ctx, userHandle, served); err != nil {
+		utils.Logf(ctx, "⚠️ %v", err)
+	}
+
+	utils.Logf(ctx, "✅ Successfully fetched %d user suggestions (%d already shown).", len(filteredProfiles), len(shown))
+	return filteredProfiles, nil
+}
+
+// BrowsePlace lists one page of compatible users in the requester's city or region (by reverse
+// geocoded name rather than radius, for areas where radius search finds nobody). Pages are filled
+// as fillPage describes, and ranked within each query page read.
+func (ups *UserProfileService) BrowsePlace(ctx context.Context, userHandle, scope string, limit int32, cursor string) ([]models.UserProfile, string, error) {
+	requesterProfile, err := ups.GetUserProfileByHandle(ctx, userHandle)
+	if err != nil {
+		return nil, "", err
 	}
 
-	// Step 4: Unmarshal result into a list of UserProfile structs
+	indexName, key, attribute := models.CityKeyIndex, requesterProfile.CityKey, "cityKey"
+	if scope == models.BrowseScopeRegion {
+		indexName, key, attribute = models.RegionKeyIndex, requesterProfile.RegionKey, "regionKey"
+	}
+	if key == "" {
+		return nil, "", conflictError(fmt.Sprintf("your location has no known %s yet", scope))
+	}
+
+	return fillPage(limit, cursor, func(limit int32, cursor string) ([]models.UserProfile, string, error) {
+		return ups.browsePlacePage(ctx, requesterProfile, scope, indexName, attribute, key, limit, cursor)
+	})
+}
+
+// browsePlacePage reads and ranks one query page of profiles in the requester's place
+func (ups *UserProfileService) browsePlacePage(ctx context.Context, requesterProfile *models.UserProfile, scope, indexName, attribute, key string, limit int32, cursor string) ([]models.UserProfile, string, error) {
+	items, nextCursor, err := ups.Dynamo.QueryPage(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(models.UserProfilesTable),
+		IndexName:              aws.String(indexName),
+		KeyConditionExpression: aws.String("#key = :key"),
+		ExpressionAttributeNames: map[string]string{
+			"#key": attribute,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":key": &types.AttributeValueMemberS{Value: key},
+		},
+	}, limit, cursor)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to browse %s: %w", scope, err)
+	}
 	var profiles []models.UserProfile
-	err = attributevalue.UnmarshalListOfMaps(items, &profiles)
+	if err := attributevalue.UnmarshalListOfMaps(items, &profiles); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal user profiles: %w", err)
+	}
+
+	filteredProfiles, err := ups.rankSuggestions(ctx, requesterProfile, profiles)
 	if err != nil {
-		log.Printf("❌ Error unmarshalling user profiles: %v", err)
-		return nil, fmt.Errorf("failed to unmarshal user profiles: %w", err)
+		return nil, "", err
+	}
+	utils.Logf(ctx, "✅ %d of %d profiles in the %s of %s are suggestions", len(filteredProfiles), len(profiles), scope, requesterProfile.UserHandle)
+	return filteredProfiles, nextCursor, nil
+}
+
+// rankSuggestions turns candidate profiles into suggestion cards for the requester: it drops the
+// requester, hidden, incompatible, blocked and already interacted users, strips private fields,
+// and orders the rest by distance (when personalized) and completeness
+func (ups *UserProfileService) rankSuggestions(ctx context.Context, requesterProfile *models.UserProfile, profiles []models.UserProfile) ([]models.UserProfile, error) {
+	userHandle := requesterProfile.UserHandle
+	now := time.Now()
+	latitude, longitude := requesterProfile.DiscoveryLocation(now)
+
+	// Everyone this user must not see again (blocks, reports, likes, passes, pings, matches, declines)
+	excludedUsers, err := ups.Blocks.DiscoveryExclusions(ctx, userHandle)
+	if err != nil {
+		utils.Logf(ctx, "❌ Error building discovery exclusions: %v", err)
+		return nil, fmt.Errorf("failed to fetch interactions: %w", err)
 	}
 
-	// Step 5: Filter out users who are already liked/disliked & calculate distance
+	// ✅ Contact sync: who "never show my contacts" hides, and whose contacts overlap with the requester's
+	contacts, err := ups.Contacts.DiscoveryGraph(ctx, requesterProfile)
+	if err != nil {
+		utils.Logf(ctx, "❌ Error loading contacts: %v", err)
+		return nil, fmt.Errorf("failed to fetch contacts: %w", err)
+	}
+
+	// ✅ Users who object to personalized ranking get suggestions in (interleaved) index order, not by distance
+	personalized := requesterProfile.EffectiveConsents().Allows(models.PurposePersonalizedRanking) && latitude != 0 && longitude != 0
+	if !personalized {
+		utils.Logf(ctx, "ℹ️ %s objected to personalized ranking or has no location; skipping distance ranking", userHandle)
+	}
+
+	// Filter out users who are already liked/disliked & calculate distance
 	filteredProfiles := make([]models.UserProfile, 0)
+	var mutualCandidates []string
 	for _, profile := range profiles {
-		// Exclude self & users without valid location
-		if profile.UserHandle != userHandle && profile.Latitude != 0 && profile.Longitude != 0 {
-			if _, exists := interactedUsers[profile.UserHandle]; !exists { // ✅ Skip already interacted users
-				profile.DistanceBetween = haversine(requesterProfile.Latitude, requesterProfile.Longitude, profile.Latitude, profile.Longitude)
+		ups.hidePrivateFields(&profile)
+		profile.RefreshAge(now)
+		hiddenByContacts := contacts.Hides(&profile)
+		sharesMutuals := profile.Contacts.ShowsMutualConnections()
+		profile.Contacts = nil // ✅ ...and their contact settings
+		// Exclude self, paused, deleted, age-restricted, suspended, banned or shadowbanned users & users without valid location
+		if profile.UserHandle != userHandle && !profile.IsPaused(now) && !profile.IsDeleted() && !profile.IsAgeRestricted() && profile.AccountRestriction(now) == "" && !profile.Shadowbanned && profile.Latitude != 0 && profile.Longitude != 0 &&
+			requesterProfile.IsCompatibleWith(&profile) { // ✅ Both want each other's gender and the same kind of connection
+			if !excludedUsers[profile.UserHandle] && !hiddenByContacts { // ✅ Skip blocked, already interacted and hidden contacts
+				if sharesMutuals {
+					mutualCandidates = append(mutualCandidates, profile.UserHandle)
+				}
+				profile.SharedInterests = sharedInterests(requesterProfile, &profile)
+				if personalized {
+					profile.DistanceBetween = haversine(latitude, longitude, profile.Latitude, profile.Longitude)
+				}
+				ups.presignAudioPrompt(ctx, &profile) // ✅ Cards play the voice answer directly
 				filteredProfiles = append(filteredProfiles, profile)
 			}
 		}
 	}
 
-	// Step 6: Sort by distance (nearest first)
-	sort.Slice(filteredProfiles, func(i, j int) bool {
-		return filteredProfiles[i].DistanceBetween < filteredProfiles[j].DistanceBetween
-	})
+	// ✅ Mutual connection counts, only between users who both opted in
+	var mutualConnections map[string]int
+	if requesterProfile.Contacts.ShowsMutualConnections() && len(mutualCandidates) > 0 {
+		mutualConnections = ups.Contacts.MutualConnections(ctx, contacts, mutualCandidates)
+	}
+
+	// ✅ Stamp each card so clients know when to re-check the profile version
+	cachedAt := time.Now()
+	for i := range filteredProfiles {
+		filteredProfiles[i].MutualConnections = mutualConnections[filteredProfiles[i].UserHandle]
+		filteredProfiles[i].CachedAt = cachedAt.Format(time.RFC3339)
+		filteredProfiles[i].StaleAfter = cachedAt.Add(ProfileSnapshotMaxAge).Format(time.RFC3339)
+	}
+
+	// Sort by distance (nearest first); new profiles rank as if they were NewUserBoost times closer
+	boosted := func(profile *models.UserProfile) bool {
+		return ups.NewUserBoost > 1 && profile.IsNewUser(now)
+	}
+	if personalized {
+		rankDistance := func(profile *models.UserProfile) float64 {
+			if boosted(profile) {
+				return profile.DistanceBetween / ups.NewUserBoost
+			}
+			return profile.DistanceBetween
+		}
+		sort.Slice(filteredProfiles, func(i, j int) bool {
+			return rankDistance(&filteredProfiles[i]) < rankDistance(&filteredProfiles[j])
+		})
+	} else {
+		// ✅ Without distances, new profiles simply go first
+		sort.SliceStable(filteredProfiles, func(i, j int) bool {
+			return boosted(&filteredProfiles[i]) && !boosted(&filteredProfiles[j])
+		})
+	}
+
+	// Move very incomplete profiles behind the rest, keeping the order within each group
+	if ups.MinSuggestionCompleteness > 0 {
+		complete := make([]models.UserProfile, 0, len(filteredProfiles))
+		var incomplete []models.UserProfile
+		for _, profile := range filteredProfiles {
+			if ups.Completeness(&profile).Score >= ups.MinSuggestionCompleteness {
+				complete = append(complete, profile)
+			} else {
+				incomplete = append(incomplete, profile)
+			}
+		}
+		filteredProfiles = append(complete, incomplete...)
+	}
 
-	log.Printf("✅ Successfully fetched %d user suggestions.", len(filteredProfiles))
 	return filteredProfiles, nil
 }
 
+// hidePrivateFields prepares another user's profile for display
+func (ups *UserProfileService) hidePrivateFields(profile *models.UserProfile) {
+	ups.PII.StripProfile(profile)
+	profile.HideQuarantinedPhotos() // ✅ Flagged photos wait for review
+	profile.Consents = nil          // ✅ Another user's consents are private
+	profile.Subscription = nil      // ✅ ...and so is their billing plan
+	profile.Passport = nil          // ✅ ...and where they are browsing from
+}
+
+// queryGenders reads up to suggestionsPerGender profiles of each gender from the `gender-index` GSI
+// (under every spelling profiles store it as) concurrently, and merges them
+func (ups *UserProfileService) queryGenders(ctx context.Context, genders []string) ([]map[string]types.AttributeValue, error) {
+	var values []string
+	for _, gender := range genders {
+		values = append(values, models.GenderIndexValues(gender)...)
+	}
+
+	results := make([][]map[string]types.AttributeValue, len(values))
+	g, gctx := errgroup.WithContext(ctx)
+	for i, value := range values {
+		g.Go(func() error {
+			items, err := ups.Dynamo.QueryItemsWithIndex(gctx, models.UserProfilesTable, "gender-index", "gender = :gender", map[string]types.AttributeValue{
+				":gender": &types.AttributeValueMemberS{Value: value},
+			}, nil, suggestionsPerGender)
+			results[i] = items
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	// ✅ Interleave the partitions so no gender crowds the others out, dropping repeats
+	var merged []map[string]types.AttributeValue
+	seen := make(map[string]bool)
+	for i, more := 0, true; more; i++ {
+		more = false
+		for _, items := range results {
+			if i >= len(items) {
+				continue
+			}
+			more = true
+			handle, _ := items[i]["userhandle"].(*types.AttributeValueMemberS)
+			if handle == nil || seen[handle.Value] {
+				continue
+			}
+			seen[handle.Value] = true
+			merged = append(merged, items[i])
+		}
+	}
+	return merged, nil
+}
+
 // ✅ Fetch a user profile by userHandle
 func (ups *UserProfileService) GetUserProfileByHandle(ctx context.Context, userHandle string) (*models.UserProfile, error) {
 	key := map[string]types.AttributeValue{
@@ -344,7 +680,7 @@ func (ups *UserProfileService) GetUserProfileByHandle(ctx context.Context, userH
 	}
 
 	if item == nil {
-		return nil, fmt.Errorf("profile not found")
+		return nil, notFoundError("profile not found")
 	}
 
 	var profile models.UserProfile
@@ -352,6 +688,888 @@ func (ups *UserProfileService) GetUserProfileByHandle(ctx context.Context, userH
 	if err != nil {
 		return nil, err
 	}
+	if profile.IsDeleted() { // ✅ Accounts awaiting purge are hidden from everyone else
+		return nil, notFoundError("profile not found")
+	}
+	ups.PII.UnprotectProfile(ctx, &profile)
+	profile.RefreshAge(time.Now())
+	completeness := ups.Completeness(&profile)
+	profile.Completeness = &completeness
 
 	return &profile, nil
 }
+
+// ✅ GetUserProfilesByHandles fetches many profiles with BatchGetItem, keyed by userHandle.
+// Handles with no stored profile are simply absent from the returned map.
+func (ups *UserProfileService) GetUserProfilesByHandles(ctx context.Context, userHandles []string) (map[string]*models.UserProfile, error) {
+	profiles := make(map[string]*models.UserProfile, len(userHandles))
+
+	seen := make(map[string]bool, len(userHandles))
+	var keys []map[string]types.AttributeValue
+	for _, handle := range userHandles {
+		if handle == "" || seen[handle] {
+			continue
+		}
+		seen[handle] = true
+		keys = append(keys, map[string]types.AttributeValue{
+			"userhandle": &types.AttributeValueMemberS{Value: handle},
+		})
+	}
+
+	if len(keys) == 0 {
+		return profiles, nil
+	}
+
+	items, err := ups.Dynamo.BatchGetItems(ctx, models.UserProfilesTable, keys)
+	if err != nil {
+		utils.Logf(ctx, "❌ Error batch fetching profiles: %v", err)
+		return nil, fmt.Errorf("failed to fetch profiles: %w", err)
+	}
+
+	for _, item := range items {
+		var profile models.UserProfile
+		if err := attributevalue.UnmarshalMap(item, &profile); err != nil {
+			utils.Logf(ctx, "⚠️ Skipping profile due to unmarshalling error: %v", err)
+			continue
+		}
+		if profile.IsDeleted() {
+			continue
+		}
+		ups.PII.StripProfile(&profile) // Enrichment never needs contact details
+		profile.RefreshAge(time.Now())
+		profiles[profile.UserHandle] = &profile
+	}
+
+	utils.Logf(ctx, "✅ Batch fetched %d of %d requested profiles", len(profiles), len(keys))
+	return profiles, nil
+}
+
+// queryProfilesByEmail looks a profile up by email blind index, falling back to the
+// legacy plaintext emailId index for profiles written before PII encryption
+func (ups *UserProfileService) queryProfilesByEmail(ctx context.Context, emailID string) ([]map[string]types.AttributeValue, error) {
+	if ups.PII.Enabled() {
+		items, err := ups.Dynamo.QueryItemsWithIndex(ctx, models.UserProfilesTable, models.EmailBlindIndex, "emailIdIndex = :emailIdIndex",
+			map[string]types.AttributeValue{
+				":emailIdIndex": &types.AttributeValueMemberS{Value: ups.PII.EmailIndex(emailID)},
+			}, nil, 1)
+		if err != nil || len(items) > 0 {
+			return items, err
+		}
+	}
+
+	keyCondition := "emailId = :emailId"
+	expressionAttributeValues := map[string]types.AttributeValue{
+		":emailId": &types.AttributeValueMemberS{Value: emailID},
+	}
+	return ups.Dynamo.QueryItemsWithIndex(ctx, models.UserProfilesTable, models.EmailIndex, keyCondition, expressionAttributeValues, nil, 1)
+}
+
+// GetProcessingConsents returns the user's processing consents, falling back to the defaults
+func (ups *UserProfileService) GetProcessingConsents(ctx context.Context, userHandle string) (*models.ProcessingConsents, error) {
+	profile, err := ups.GetUserProfileByHandle(ctx, userHandle)
+	if err != nil {
+		return nil, err
+	}
+	consents := profile.EffectiveConsents()
+	return &consents, nil
+}
+
+// UpdateProcessingConsents applies a partial consent change and stores the full result on the profile
+func (ups *UserProfileService) UpdateProcessingConsents(ctx context.Context, userHandle string, update models.ProcessingConsentsUpdate) (*models.ProcessingConsents, error) {
+	utils.Logf(ctx, "🔄 Updating processing consents for user: %s", userHandle)
+
+	consents, err := ups.GetProcessingConsents(ctx, userHandle)
+	if err != nil {
+		return nil, err
+	}
+	consents.Apply(update)
+	consents.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	consentsAV, err := attributevalue.Marshal(consents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal consents: %w", err)
+	}
+
+	key := map[string]types.AttributeValue{
+		"userhandle": &types.AttributeValueMemberS{Value: userHandle},
+	}
+	_, err = ups.Dynamo.UpdateItem(ctx, models.UserProfilesTable, "SET #consents = :consents", key,
+		map[string]types.AttributeValue{":consents": consentsAV},
+		map[string]string{"#consents": "consents"},
+	)
+	if err != nil {
+		utils.Logf(ctx, "❌ Error updating processing consents: %v", err)
+		return nil, fmt.Errorf("failed to update consents: %w", err)
+	}
+
+	utils.Logf(ctx, "✅ Processing consents updated for user %s: %+v", userHandle, *consents)
+	return consents, nil
+}
+
+// AllowsProcessing is the gate the ranking, analytics and campaign code paths check before
+// processing a user's data. Lookup failures deny processing rather than risk ignoring an objection.
+func (ups *UserProfileService) AllowsProcessing(ctx context.Context, userHandle, purpose string) bool {
+	consents, err := ups.GetProcessingConsents(ctx, userHandle)
+	if err != nil {
+		utils.Logf(ctx, "⚠️ Could not load consents for %s, denying %s: %v", userHandle, purpose, err)
+		return false
+	}
+	return consents.Allows(purpose)
+}
+
+// GetProfileVersion reads only the version attributes of a profile, so clients can cheaply
+// check whether a cached suggestion card is stale. Returns nil when the profile does not exist.
+func (ups *UserProfileService) GetProfileVersion(ctx context.Context, userHandle string) (*models.ProfileVersionInfo, error) {
+	key := map[string]types.AttributeValue{
+		"userhandle": &types.AttributeValueMemberS{Value: userHandle},
+	}
+
+	item, err := ups.Dynamo.GetItemAttributes(ctx, models.UserProfilesTable, key, "userhandle", "profileVersion", "updatedAt")
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var info models.ProfileVersionInfo
+	if err := attributevalue.UnmarshalMap(item, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// Completeness scores how filled-in a profile is. Photos, interests and questionnaire answers earn
+// a share of their weight per item up to the complete count; photos awaiting review do not count.
+func (ups *UserProfileService) Completeness(profile *models.UserProfile) models.CompletenessScore {
+	photos := 0
+	for _, photo := range profile.Photos {
+		if !slices.Contains(profile.QuarantinedPhotos, photo) {
+			photos++
+		}
+	}
+	verified := 0
+	if profile.EmailIDVerified {
+		verified = 1
+	}
+	bio := 0
+	if strings.TrimSpace(profile.Bio) != "" {
+		bio = 1
+	}
+	earned := map[string]float64{
+		models.CompletenessPhotos:        math.Min(float64(photos)/models.CompletePhotoCount, 1),
+		models.CompletenessBio:           float64(bio),
+		models.CompletenessInterests:     math.Min(float64(len(profile.Interests))/models.CompleteInterestCount, 1),
+		models.CompletenessQuestionnaire: math.Min(float64(len(profile.Questionnaire))/models.CompleteQuestionnaireCount, 1),
+		models.CompletenessVerification:  float64(verified),
+	}
+
+	result := models.CompletenessScore{Missing: []string{}}
+	score := 0.0
+	for section, weight := range models.CompletenessWeights {
+		score += earned[section] * float64(weight)
+		if earned[section] < 1 {
+			result.Missing = append(result.Missing, section)
+		}
+	}
+	result.Score = int(math.Round(score))
+	sort.Slice(result.Missing, func(i, j int) bool {
+		wi, wj := models.CompletenessWeights[result.Missing[i]], models.CompletenessWeights[result.Missing[j]]
+		if wi != wj {
+			return wi > wj
+		}
+		return result.Missing[i] < result.Missing[j]
+	})
+	return result
+}
+
+// SetPhotoRenditions records the renditions of one photo on the profile, bumping its version so
+// cached snapshots pick up the new keys
+func (ups *UserProfileService) SetPhotoRenditions(ctx context.Context, userHandle string, renditions models.PhotoRenditions) error {
+	key := map[string]types.AttributeValue{
+		"userhandle": &types.AttributeValueMemberS{Value: userHandle},
+	}
+
+	// ✅ A nested path can only be set once its parent map exists; a concurrent creator winning is fine
+	_, err := ups.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(models.UserProfilesTable),
+		Key:                 key,
+		UpdateExpression:    aws.String("SET photoRenditions = :empty"),
+		ConditionExpression: aws.String("attribute_exists(userhandle) AND attribute_not_exists(photoRenditions)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":empty": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{}},
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if err != nil && !errors.As(err, &conditionFailed) {
+		return fmt.Errorf("failed to initialize photo renditions for %s: %w", userHandle, err)
+	}
+
+	renditionsAV, err := attributevalue.Marshal(renditions)
+	if err != nil {
+		return fmt.Errorf("failed to marshal photo renditions: %w", err)
+	}
+	_, err = ups.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(models.UserProfilesTable),
+		Key:                 key,
+		UpdateExpression:    aws.String("SET photoRenditions.#photo = :renditions, updatedAt = :now ADD profileVersion :one"),
+		ConditionExpression: aws.String("contains(photos, :photo)"), // ✅ Only photos still on the profile
+		ExpressionAttributeNames: map[string]string{
+			"#photo": renditions.Original,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":photo":      &types.AttributeValueMemberS{Value: renditions.Original},
+			":renditions": renditionsAV,
+			":now":        &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)},
+			":one":        &types.AttributeValueMemberN{Value: "1"},
+		},
+	})
+	if errors.As(err, &conditionFailed) {
+		return validationError("photo is not on the user's profile")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to store photo renditions for %s: %w", userHandle, err)
+	}
+
+	utils.Logf(ctx, "✅ Stored renditions of %s for %s", renditions.Original, userHandle)
+	return nil
+}
+
+// AddProfileVideo appends an uploaded video's key to the profile, keeping at most maxVideos.
+// Adding a key that is already listed succeeds without changes.
+func (ups *UserProfileService) AddProfileVideo(ctx context.Context, userHandle, videoKey string, maxVideos int) error {
+	_, err := ups.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(models.UserProfilesTable),
+		Key: map[string]types.AttributeValue{
+			"userhandle": &types.AttributeValueMemberS{Value: userHandle},
+		},
+		UpdateExpression:    aws.String("SET videos = list_append(if_not_exists(videos, :empty), :video), updatedAt = :now ADD profileVersion :one"),
+		ConditionExpression: aws.String("attribute_exists(userhandle) AND (attribute_not_exists(videos) OR (size(videos) < :max AND NOT contains(videos, :key)))"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":empty": &types.AttributeValueMemberL{Value: []types.AttributeValue{}},
+			":video": &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberS{Value: videoKey}}},
+			":key":   &types.AttributeValueMemberS{Value: videoKey},
+			":max":   &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", maxVideos)},
+			":now":   &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)},
+			":one":   &types.AttributeValueMemberN{Value: "1"},
+		},
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	switch {
+	case err == nil:
+	case !errors.As(err, &conditionFailed):
+		return fmt.Errorf("failed to add video for %s: %w", userHandle, err)
+	case conditionFailed.Item == nil:
+		return notFoundError("user profile not found")
+	default:
+		var existing models.UserProfile
+		if err := attributevalue.UnmarshalMap(conditionFailed.Item, &existing); err == nil && slices.Contains(existing.Videos, videoKey) {
+			return nil // ✅ Completing the same upload twice
+		}
+		return conflictError(fmt.Sprintf("a profile can have at most %d videos", maxVideos))
+	}
+
+	utils.Logf(ctx, "✅ Added video %s to %s", videoKey, userHandle)
+	return nil
+}
+
+// ReplacePhotos stores a new photo list with its captions and renditions, failing with ErrConflict
+// if the profile changed since expectedVersion was read
+func (ups *UserProfileService) ReplacePhotos(ctx context.Context, userHandle string, expectedVersion int, photos []string, captions map[string]string, renditions models.PhotoRenditionsMap) (*models.UserProfile, error) {
+	if captions == nil {
+		captions = map[string]string{}
+	}
+	if renditions == nil {
+		renditions = models.PhotoRenditionsMap{}
+	}
+	photosAV, err := attributevalue.Marshal(photos)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal photos: %w", err)
+	}
+	captionsAV, err := attributevalue.Marshal(captions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal photo captions: %w", err)
+	}
+	renditionsAV, err := attributevalue.Marshal(renditions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal photo renditions: %w", err)
+	}
+
+	condition := "profileVersion = :expected"
+	if expectedVersion == 0 {
+		condition = "attribute_exists(userhandle) AND attribute_not_exists(profileVersion)" // ✅ Legacy profile never updated
+	}
+	values := map[string]types.AttributeValue{
+		":photos":     photosAV,
+		":captions":   captionsAV,
+		":renditions": renditionsAV,
+		":now":        &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)},
+		":one":        &types.AttributeValueMemberN{Value: "1"},
+	}
+	if expectedVersion != 0 {
+		values[":expected"] = &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", expectedVersion)}
+	}
+
+	output, err := ups.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(models.UserProfilesTable),
+		Key: map[string]types.AttributeValue{
+			"userhandle": &types.AttributeValueMemberS{Value: userHandle},
+		},
+		UpdateExpression:          aws.String("SET photos = :photos, photoCaptions = :captions, photoRenditions = :renditions, updatedAt = :now ADD profileVersion :one"),
+		ConditionExpression:       aws.String(condition),
+		ExpressionAttributeValues: values,
+		ReturnValues:              types.ReturnValueAllNew,
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return nil, conflictError("profile was changed by another request, please retry")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update photos for %s: %w", userHandle, err)
+	}
+
+	var profile models.UserProfile
+	if err := attributevalue.UnmarshalMap(output.Attributes, &profile); err != nil {
+		return nil, err
+	}
+	ups.PII.UnprotectProfile(ctx, &profile)
+	return &profile, nil
+}
+
+// SetPhotoQuarantined adds a photo to, or removes it from, the profile's quarantined set
+func (ups *UserProfileService) SetPhotoQuarantined(ctx context.Context, userHandle, photoKey string, quarantined bool) error {
+	// ✅ One clause per action: DynamoDB rejects an expression that repeats ADD
+	update := "SET updatedAt = :now DELETE quarantinedPhotos :photo ADD profileVersion :one"
+	if quarantined {
+		update = "SET updatedAt = :now ADD quarantinedPhotos :photo, profileVersion :one"
+	}
+	_, err := ups.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(models.UserProfilesTable),
+		Key: map[string]types.AttributeValue{
+			"userhandle": &types.AttributeValueMemberS{Value: userHandle},
+		},
+		UpdateExpression:    aws.String(update),
+		ConditionExpression: aws.String("attribute_exists(userhandle)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":photo": &types.AttributeValueMemberSS{Value: []string{photoKey}},
+			":now":   &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)},
+			":one":   &types.AttributeValueMemberN{Value: "1"},
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return notFoundError("user profile not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update quarantined photos for %s: %w", userHandle, err)
+	}
+	return nil
+}
+
+// CanReadMedia reports whether viewer may read the S3 object at key: any of their own uploads, or a
+// photo (or one of its renditions), video or voice answer shown on a profile they can see. Quarantined
+// photos, blocked users and profiles hidden from everyone else are never readable by others.
+func (ups *UserProfileService) CanReadMedia(ctx context.Context, viewer, key string) (bool, error) {
+	if models.OwnsUploadKey(viewer, key) {
+		return true, nil
+	}
+	rest, ok := strings.CutPrefix(key, models.UserUploadPrefix(""))
+	owner, _, found := strings.Cut(rest, "/")
+	if !ok || !found || owner == "" {
+		return false, nil
+	}
+	if ups.Blocks != nil && ups.Blocks.IsBlocked(ctx, viewer, owner) {
+		return false, nil
+	}
+	profile, err := ups.GetUserProfileByHandle(ctx, owner)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if profile.Shadowbanned || profile.IsAgeRestricted() {
+		return false, nil
+	}
+
+	for _, photo := range profile.Photos {
+		if slices.Contains(profile.QuarantinedPhotos, photo) {
+			continue
+		}
+		renditions := profile.PhotoRenditions[photo]
+		if key == photo || key == renditions.Thumbnail || key == renditions.Medium || key == renditions.Large {
+			return true, nil
+		}
+	}
+	if slices.Contains(profile.Videos, key) {
+		return true, nil
+	}
+	return profile.AudioPrompt != nil && profile.AudioPrompt.Key == key, nil
+}
+
+// InterestCatalog returns the catalog of interests users can pick
+func (ups *UserProfileService) InterestCatalog() []models.Interest {
+	return interestCatalog
+}
+
+// SetInterests replaces the user's interests with catalog IDs, storing their names alongside for
+// older clients; an empty list clears them
+func (ups *UserProfileService) SetInterests(ctx context.Context, userHandle string, ids []string) ([]models.Interest, error) {
+	interests := make([]models.Interest, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		interest, ok := interestByID(id)
+		if !ok {
+			return nil, validationError(fmt.Sprintf("unknown interest %q", id))
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		interests = append(interests, interest)
+	}
+
+	interestIDs := make([]string, len(interests))
+	names := make([]string, len(interests))
+	for i, interest := range interests {
+		interestIDs[i], names[i] = interest.ID, interest.Name
+	}
+	idsAV, err := attributevalue.Marshal(interestIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal interests: %w", err)
+	}
+	namesAV, err := attributevalue.Marshal(names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal interests: %w", err)
+	}
+	_, err = ups.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(models.UserProfilesTable),
+		Key:                 profileKey(userHandle),
+		UpdateExpression:    aws.String("SET interestIds = :ids, interests = :names, updatedAt = :now ADD profileVersion :one"),
+		ConditionExpression: aws.String("attribute_exists(userhandle)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":ids":   idsAV,
+			":names": namesAV,
+			":now":   &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)},
+			":one":   &types.AttributeValueMemberN{Value: "1"},
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return nil, notFoundError("profile not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update interests for %s: %w", userHandle, err)
+	}
+
+	utils.Logf(ctx, "✅ Stored %d interests for %s", len(interests), userHandle)
+	return interests, nil
+}
+
+// PromptQuestions returns the catalog of prompt questions users can answer
+func (ups *UserProfileService) PromptQuestions() []models.PromptQuestion {
+	return promptQuestionCatalog
+}
+
+// SetProfilePrompts replaces the user's prompt answers. Each prompt must come from the catalog and
+// may be answered once; an empty list clears them.
+func (ups *UserProfileService) SetProfilePrompts(ctx context.Context, userHandle string, answers []models.ProfilePromptAnswer) ([]models.ProfilePrompt, error) {
+	prompts := make([]models.ProfilePrompt, 0, len(answers))
+	seen := make(map[string]bool, len(answers))
+	for _, answer := range answers {
+		question, ok := promptQuestion(answer.PromptID)
+		if !ok {
+			return nil, validationError(fmt.Sprintf("unknown prompt %q", answer.PromptID))
+		}
+		if seen[answer.PromptID] {
+			return nil, validationError(fmt.Sprintf("prompt %q is answered more than once", answer.PromptID))
+		}
+		seen[answer.PromptID] = true
+		prompts = append(prompts, models.ProfilePrompt{PromptID: question.ID, Question: question.Text, Answer: strings.TrimSpace(answer.Answer)})
+	}
+
+	promptsAV, err := attributevalue.Marshal(prompts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal prompts: %w", err)
+	}
+	_, err = ups.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(models.UserProfilesTable),
+		Key: map[string]types.AttributeValue{
+			"userhandle": &types.AttributeValueMemberS{Value: userHandle},
+		},
+		UpdateExpression:    aws.String("SET prompts = :prompts, updatedAt = :now ADD profileVersion :one"),
+		ConditionExpression: aws.String("attribute_exists(userhandle)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":prompts": promptsAV,
+			":now":     &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)},
+			":one":     &types.AttributeValueMemberN{Value: "1"},
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return nil, notFoundError("profile not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update prompts for %s: %w", userHandle, err)
+	}
+
+	utils.Logf(ctx, "✅ Stored %d prompts for %s", len(prompts), userHandle)
+	return prompts, nil
+}
+
+// SetAudioPrompt attaches an uploaded voice recording to the user's profile as the answer to one
+// catalog prompt, replacing (and deleting) any earlier recording. The upload's size and type are
+// checked in S3; the duration is the client's measurement.
+func (ups *UserProfileService) SetAudioPrompt(ctx context.Context, userHandle, promptID, key string, durationSeconds float64) (*models.AudioPrompt, error) {
+	question, ok := promptQuestion(promptID)
+	if !ok {
+		return nil, validationError(fmt.Sprintf("unknown prompt %q", promptID))
+	}
+	if !models.OwnsUploadKey(userHandle, key) {
+		return nil, validationError("key was not issued to this user")
+	}
+	if durationSeconds <= 0 || durationSeconds > models.MaxAudioPromptSeconds {
+		return nil, validationError(fmt.Sprintf("durationSeconds must be between 0 and %d", models.MaxAudioPromptSeconds))
+	}
+	size, contentType, err := ups.S3.HeadObject(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := models.AudioUploadTypes[contentType]; !ok {
+		return nil, validationError(fmt.Sprintf("upload type %q is not an allowed audio type", contentType))
+	}
+	if size > models.MaxAudioUploadBytes {
+		return nil, validationError(fmt.Sprintf("recording must be at most %d bytes", models.MaxAudioUploadBytes))
+	}
+
+	audioPrompt := models.AudioPrompt{PromptID: question.ID, Question: question.Text, Key: key, DurationSeconds: durationSeconds}
+	audioPromptAV, err := attributevalue.Marshal(audioPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audio prompt: %w", err)
+	}
+	output, err := ups.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(models.UserProfilesTable),
+		Key: map[string]types.AttributeValue{
+			"userhandle": &types.AttributeValueMemberS{Value: userHandle},
+		},
+		UpdateExpression:    aws.String("SET audioPrompt = :audioPrompt, updatedAt = :now ADD profileVersion :one"),
+		ConditionExpression: aws.String("attribute_exists(userhandle)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":audioPrompt": audioPromptAV,
+			":now":         &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)},
+			":one":         &types.AttributeValueMemberN{Value: "1"},
+		},
+		ReturnValues: types.ReturnValueUpdatedOld,
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return nil, notFoundError("profile not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set audio prompt for %s: %w", userHandle, err)
+	}
+	ups.deleteReplacedAudio(ctx, output.Attributes, key)
+
+	utils.Logf(ctx, "✅ Stored a %.1fs voice answer to %s for %s", durationSeconds, promptID, userHandle)
+	return &audioPrompt, nil
+}
+
+// DeleteAudioPrompt removes the user's voice answer and its recording
+func (ups *UserProfileService) DeleteAudioPrompt(ctx context.Context, userHandle string) error {
+	output, err := ups.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(models.UserProfilesTable),
+		Key: map[string]types.AttributeValue{
+			"userhandle": &types.AttributeValueMemberS{Value: userHandle},
+		},
+		UpdateExpression:    aws.String("REMOVE audioPrompt SET updatedAt = :now ADD profileVersion :one"),
+		ConditionExpression: aws.String("attribute_exists(audioPrompt)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":now": &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)},
+			":one": &types.AttributeValueMemberN{Value: "1"},
+		},
+		ReturnValues: types.ReturnValueUpdatedOld,
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return notFoundError("no audio prompt to delete")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete audio prompt for %s: %w", userHandle, err)
+	}
+	ups.deleteReplacedAudio(ctx, output.Attributes, "")
+
+	utils.Logf(ctx, "✅ Deleted the voice answer of %s", userHandle)
+	return nil
+}
+
+// deleteReplacedAudio removes the recording referenced by the old audioPrompt attribute, unless it
+// is still in use. Failures only leave an orphaned object behind.
+func (ups *UserProfileService) deleteReplacedAudio(ctx context.Context, oldAttributes map[string]types.AttributeValue, currentKey string) {
+	old, ok := oldAttributes["audioPrompt"]
+	if !ok {
+		return
+	}
+	var previous models.AudioPrompt
+	if err := attributevalue.Unmarshal(old, &previous); err != nil || previous.Key == "" || previous.Key == currentKey {
+		return
+	}
+	if err := ups.S3.DeleteObjects(ctx, []string{previous.Key}); err != nil {
+		utils.Logf(ctx, "⚠️ Failed to delete replaced voice answer %s: %v", previous.Key, err)
+	}
+}
+
+// presignAudioPrompt sets AudioPromptURL so clients can play the voice answer without another request
+func (ups *UserProfileService) presignAudioPrompt(ctx context.Context, profile *models.UserProfile) {
+	if profile.AudioPrompt == nil || ups.S3 == nil {
+		return
+	}
+	url, err := ups.S3.GenerateReadURL(ctx, profile.AudioPrompt.Key)
+	if err != nil {
+		utils.Logf(ctx, "⚠️ Failed to presign voice answer of %s: %v", profile.UserHandle, err)
+		return
+	}
+	profile.AudioPromptURL = url
+}
+
+// UpdateLocation stores the user's coordinates and discovery geohash. Moves shorter than
+// LocationJitterMeters are ignored so GPS noise doesn't bump the profile version.
+func (ups *UserProfileService) UpdateLocation(ctx context.Context, userHandle string, latitude, longitude float64) (*models.LocationUpdate, error) {
+	key := map[string]types.AttributeValue{
+		"userhandle": &types.AttributeValueMemberS{Value: userHandle},
+	}
+	item, err := ups.Dynamo.GetItemAttributes(ctx, models.UserProfilesTable, key, "latitude", "longitude", "geohash", "locationUpdatedAt", "place")
+	if errors.Is(err, ErrNotFound) {
+		return nil, notFoundError("profile not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	var current models.UserProfile
+	if err := attributevalue.UnmarshalMap(item, &current); err != nil {
+		return nil, err
+	}
+	if current.Latitude != 0 && current.Longitude != 0 && current.Geohash != "" &&
+		haversine(current.Latitude, current.Longitude, latitude, longitude)*1000 < models.LocationJitterMeters {
+		utils.Logf(ctx, "ℹ️ Ignoring location jitter for %s", userHandle)
+		return &models.LocationUpdate{
+			Latitude:          current.Latitude,
+			Longitude:         current.Longitude,
+			Geohash:           current.Geohash,
+			LocationUpdatedAt: current.LocationUpdatedAt,
+		}, nil
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	update := &models.LocationUpdate{
+		Latitude:          latitude,
+		Longitude:         longitude,
+		Geohash:           utils.EncodeGeohash(latitude, longitude, models.GeohashPrecision),
+		LocationUpdatedAt: now,
+		Moved:             true,
+	}
+	values := map[string]types.AttributeValue{
+		":latitude":  &types.AttributeValueMemberN{Value: strconv.FormatFloat(latitude, 'f', -1, 64)},
+		":longitude": &types.AttributeValueMemberN{Value: strconv.FormatFloat(longitude, 'f', -1, 64)},
+		":geohash":   &types.AttributeValueMemberS{Value: update.Geohash},
+		":now":       &types.AttributeValueMemberS{Value: now},
+		":one":       &types.AttributeValueMemberN{Value: "1"},
+	}
+	expression := "SET latitude = :latitude, longitude = :longitude, geohash = :geohash, locationUpdatedAt = :now, updatedAt = :now"
+
+	// ✅ Name the city/region again when the user left their discovery cell (a failure keeps the old place)
+	if update.Geohash != current.Geohash || current.Place == nil {
+		set, remove, err := ups.placeUpdate(ctx, latitude, longitude, values)
+		if err != nil {
+			utils.Logf(ctx, "⚠️ Could not name the place of %s: %v", userHandle, err)
+		}
+		expression += set + remove
+	}
+
+	_, err = ups.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(models.UserProfilesTable),
+		Key:                       key,
+		UpdateExpression:          aws.String(expression + " ADD profileVersion :one"),
+		ConditionExpression:       aws.String("attribute_exists(userhandle)"),
+		ExpressionAttributeValues: values,
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return nil, notFoundError("profile not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update location for %s: %w", userHandle, err)
+	}
+
+	utils.Logf(ctx, "✅ Updated location of %s (cell %s)", userHandle, update.Geohash)
+	return update, nil
+}
+
+// placeUpdate reverse geocodes the location into clauses for place, cityKey and regionKey (set is
+// appended to a SET expression, remove is a whole REMOVE clause), adding their values; both are
+// empty when geocoding is disabled or finds nothing
+func (ups *UserProfileService) placeUpdate(ctx context.Context, latitude, longitude float64, values map[string]types.AttributeValue) (set, remove string, err error) {
+	if !ups.Geocoding.Enabled() {
+		return "", "", nil
+	}
+	place, err := ups.Geocoding.ReverseGeocode(ctx, latitude, longitude)
+	if err != nil || place == nil {
+		return "", "", err
+	}
+	placeAV, err := attributevalue.Marshal(place)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal place: %w", err)
+	}
+	values[":place"] = placeAV
+	set = ", place = :place"
+
+	// ✅ GSI keys can't be empty; an unknown city or region is simply not browsable
+	var removed []string
+	for _, key := range []struct{ attribute, value string }{
+		{"cityKey", place.CityKey()},
+		{"regionKey", place.RegionKey()},
+	} {
+		if key.value == "" {
+			removed = append(removed, key.attribute)
+			continue
+		}
+		values[":"+key.attribute] = &types.AttributeValueMemberS{Value: key.value}
+		set += fmt.Sprintf(", %s = :%s", key.attribute, key.attribute)
+	}
+	if len(removed) > 0 {
+		remove = " REMOVE " + strings.Join(removed, ", ")
+	}
+
+	utils.Logf(ctx, "📍 Location is in %s, %s, %s", place.City, place.Region, place.Country)
+	return set, remove, nil
+}
+
+// PauseProfile snoozes the user: they leave discovery and can't be liked or pinged, while matches
+// and chats keep working. A zero until pauses until ResumeProfile is called.
+func (ups *UserProfileService) PauseProfile(ctx context.Context, userHandle string, until time.Time) error {
+	values := map[string]types.AttributeValue{
+		":paused": &types.AttributeValueMemberBOOL{Value: true},
+		":now":    &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)},
+		":one":    &types.AttributeValueMemberN{Value: "1"},
+	}
+	update := "SET paused = :paused, updatedAt = :now REMOVE pausedUntil ADD profileVersion :one"
+	resumes := "when resumed"
+	if !until.IsZero() {
+		resumes = until.UTC().Format(time.RFC3339)
+		update = "SET paused = :paused, pausedUntil = :until, updatedAt = :now ADD profileVersion :one"
+		values[":until"] = &types.AttributeValueMemberS{Value: resumes}
+	}
+	if err := ups.updatePauseState(ctx, userHandle, update, values); err != nil {
+		return err
+	}
+
+	utils.Logf(ctx, "😴 Paused %s (resumes %s)", userHandle, resumes)
+	return nil
+}
+
+// ResumeProfile ends a pause early
+func (ups *UserProfileService) ResumeProfile(ctx context.Context, userHandle string) error {
+	err := ups.updatePauseState(ctx, userHandle, "REMOVE paused, pausedUntil SET updatedAt = :now ADD profileVersion :one", map[string]types.AttributeValue{
+		":now": &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)},
+		":one": &types.AttributeValueMemberN{Value: "1"},
+	})
+	if err != nil {
+		return err
+	}
+
+	utils.Logf(ctx, "✅ Resumed %s", userHandle)
+	return nil
+}
+
+// ReceiverState reports whether the user is currently paused, or hidden (awaiting deletion, age
+// restricted, suspended or banned). Lookup failures count as neither, so a DynamoDB hiccup never
+// blocks an interaction.
+func (ups *UserProfileService) ReceiverState(ctx context.Context, userHandle string) (paused, hidden bool) {
+	item, err := ups.Dynamo.GetItemAttributes(ctx, models.UserProfilesTable, map[string]types.AttributeValue{
+		"userhandle": &types.AttributeValueMemberS{Value: userHandle},
+	}, "paused", "pausedUntil", "deletedAt", "ageStatus", "accountStatus", "suspendedUntil")
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			utils.Logf(ctx, "⚠️ Could not check whether %s is paused: %v", userHandle, err)
+		}
+		return false, false
+	}
+	var profile models.UserProfile
+	if err := attributevalue.UnmarshalMap(item, &profile); err != nil {
+		return false, false
+	}
+	now := time.Now()
+	return profile.IsPaused(now), profile.IsDeleted() || profile.IsAgeRestricted() || profile.AccountRestriction(now) != ""
+}
+
+// ErrAccountSuspended and ErrAccountBanned reject writes by a restricted user; GET /account/standing
+// tells them why and until when
+var (
+	ErrAccountSuspended = forbiddenError("account_suspended")
+	ErrAccountBanned    = forbiddenError("account_banned")
+)
+
+// CheckStanding returns ErrAccountSuspended or ErrAccountBanned while the user is restricted. As with
+// ReceiverState, lookup failures let the user through.
+func (ups *UserProfileService) CheckStanding(ctx context.Context, userHandle string) error {
+	item, err := ups.Dynamo.GetItemAttributes(ctx, models.UserProfilesTable, map[string]types.AttributeValue{
+		"userhandle": &types.AttributeValueMemberS{Value: userHandle},
+	}, "accountStatus", "suspendedUntil")
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			utils.Logf(ctx, "⚠️ Could not check the standing of %s: %v", userHandle, err)
+		}
+		return nil
+	}
+	var profile models.UserProfile
+	if err := attributevalue.UnmarshalMap(item, &profile); err != nil {
+		return nil
+	}
+	switch profile.AccountRestriction(time.Now()) {
+	case models.AccountStatusSuspended:
+		return ErrAccountSuspended
+	case models.AccountStatusBanned:
+		return ErrAccountBanned
+	}
+	return nil
+}
+
+// IsShadowbanned reports whether the user's likes and pings are silently withheld. Lookup failures
+// count as not shadowbanned.
+func (ups *UserProfileService) IsShadowbanned(ctx context.Context, userHandle string) bool {
+	item, err := ups.Dynamo.GetItemAttributes(ctx, models.UserProfilesTable, map[string]types.AttributeValue{
+		"userhandle": &types.AttributeValueMemberS{Value: userHandle},
+	}, "shadowbanned")
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			utils.Logf(ctx, "⚠️ Could not check whether %s is shadowbanned: %v", userHandle, err)
+		}
+		return false
+	}
+	var profile models.UserProfile
+	if err := attributevalue.UnmarshalMap(item, &profile); err != nil {
+		return false
+	}
+	return profile.Shadowbanned
+}
+
+// updatePauseState applies a pause/resume update to an existing profile
+func (ups *UserProfileService) updatePauseState(ctx context.Context, userHandle, update string, values map[string]types.AttributeValue) error {
+	_, err := ups.Dynamo.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(models.UserProfilesTable),
+		Key: map[string]types.AttributeValue{
+			"userhandle": &types.AttributeValueMemberS{Value: userHandle},
+		},
+		UpdateExpression:          aws.String(update),
+		ConditionExpression:       aws.String("attribute_exists(userhandle)"),
+		ExpressionAttributeValues: values,
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return notFoundError("profile not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update pause state of %s: %w", userHandle, err)
+	}
+	return nil
+}
@@ -0,0 +1,334 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+	"vibin_server/models"
+	"vibin_server/utils"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+)
+
+// ✅ Outbound delivery headers; receivers verify HMAC-SHA256(secret, "<t>.<body>") like Stripe's scheme
+const (
+	WebhookSignatureHeader = "X-Vibin-Signature" // "t=<unix>,v1=<hex hmac>"
+	WebhookEventHeader     = "X-Vibin-Event"
+	WebhookDeliveryHeader  = "X-Vibin-Delivery"
+)
+
+// ✅ Delivery limits
+const (
+	webhookMinSecretLength  = 16
+	webhookDeliveryAttempts = 5                // First try plus 4 retries
+	webhookRetryBaseDelay   = 2 * time.Second  // Doubles per retry: 2s, 4s, 8s, 16s
+	webhookAttemptTimeout   = 10 * time.Second // Per POST
+	webhookMaxInFlight      = 32               // Concurrent deliveries per instance
+)
+
+// WebhookService registers webhooks and delivers signed platform events to them. Subscriptions are
+// served from an in-memory cache so publishing never reads DynamoDB on the request path.
+type WebhookService struct {
+	Dynamo *DynamoService
+	Client *http.Client // Defaults to http.DefaultClient
+	Jobs   *JobQueue    // ✅ Set by RegisterJobs; events fan out in-process without it
+
+	mu       sync.RWMutex
+	webhooks []models.Webhook
+	inFlight chan struct{}
+	initOnce sync.Once
+}
+
+// StartWebhookReloader loads the webhooks now and then every interval until ctx is cancelled
+func (s *WebhookService) StartWebhookReloader(ctx context.Context, interval time.Duration) {
+	if err := s.ReloadWebhooks(ctx); err != nil {
+		utils.Logf(ctx, "⚠️ Initial webhook load failed: %v", err)
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.ReloadWebhooks(ctx); err != nil {
+					utils.Logf(ctx, "⚠️ Webhook reload failed, keeping cached webhooks: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// ReloadWebhooks replaces the cache with the active webhooks currently stored
+func (s *WebhookService) ReloadWebhooks(ctx context.Context) error {
+	webhooks, err := s.ListWebhooks(ctx)
+	if err != nil {
+		return err
+	}
+
+	active := make([]models.Webhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		if webhook.Active {
+			active = append(active, webhook)
+		}
+	}
+	s.mu.Lock()
+	s.webhooks = active
+	s.mu.Unlock()
+	return nil
+}
+
+// ListWebhooks reads every webhook from DynamoDB, secrets included (the table is small and admin-managed)
+func (s *WebhookService) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	items, err := s.Dynamo.ScanAll(ctx, models.WebhooksTable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	webhooks := []models.Webhook{}
+	if err := attributevalue.UnmarshalListOfMaps(items, &webhooks); err != nil {
+		return nil, fmt.Errorf("failed to parse webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+// CreateWebhook validates and stores a webhook. A secret is generated when none is given; the
+// returned webhook is the only place it is shown.
+func (s *WebhookService) CreateWebhook(ctx context.Context, webhook models.Webhook) (*models.Webhook, error) {
+	if err := validateWebhookURL(webhook.URL); err != nil {
+		return nil, err
+	}
+	if len(webhook.Events) == 0 {
+		return nil, validationError("events must list at least one event type")
+	}
+	for _, eventType := range webhook.Events {
+		if !slices.Contains(models.WebhookEventTypes, eventType) {
+			return nil, validationError(fmt.Sprintf("unknown event type %q", eventType))
+		}
+	}
+	switch {
+	case webhook.Secret == "":
+		secret, err := generateWebhookSecret()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		webhook.Secret = secret
+	case len(webhook.Secret) < webhookMinSecretLength:
+		return nil, validationError(fmt.Sprintf("secret must be at least %d characters", webhookMinSecretLength))
+	}
+
+	webhook.WebhookID = uuid.New().String()
+	webhook.Active = true
+	webhook.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	utils.Logf(ctx, "📝 Registering webhook %s → %s for %v by %s", webhook.WebhookID, webhook.URL, webhook.Events, webhook.CreatedBy)
+	if err := s.Dynamo.PutItem(ctx, models.WebhooksTable, webhook); err != nil {
+		utils.Logf(ctx, "❌ Failed to store webhook: %v", err)
+		return nil, fmt.Errorf("failed to store webhook: %w", err)
+	}
+
+	if err := s.ReloadWebhooks(ctx); err != nil {
+		utils.Logf(ctx, "⚠️ Webhook %s saved but cache reload failed: %v", webhook.WebhookID, err)
+	}
+	return &webhook, nil
+}
+
+// DeleteWebhook removes a webhook; deliveries already in flight still finish
+func (s *WebhookService) DeleteWebhook(ctx context.Context, webhookID string) error {
+	key := map[string]types.AttributeValue{
+		"webhookId": &types.AttributeValueMemberS{Value: webhookID},
+	}
+	if _, err := s.Dynamo.GetItemAttributes(ctx, models.WebhooksTable, key, "webhookId"); err != nil {
+		return err
+	}
+	if err := s.Dynamo.DeleteItem(ctx, models.WebhooksTable, key); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	utils.Logf(ctx, "🗑️ Deleted webhook %s", webhookID)
+
+	if err := s.ReloadWebhooks(ctx); err != nil {
+		utils.Logf(ctx, "⚠️ Webhook %s deleted but cache reload failed: %v", webhookID, err)
+	}
+	return nil
+}
+
+// Publish delivers an event to every subscribed webhook in the background. It never fails or
+// delays the caller; delivery failures are retried with backoff and then only logged. With a job
+// queue, a job whose deliveries failed is retried by the queue under the same delivery ID.
+func (s *WebhookService) Publish(ctx context.Context, eventType string, data map[string]interface{}) {
+	if s == nil {
+		return
+	}
+	job := models.PublishWebhookJob{
+		EventType:  eventType,
+		Data:       data,
+		DeliveryID: uuid.New().String(),
+		CreatedAt:  time.Now().UTC().Format(time.RFC3339),
+	}
+	if s.Jobs != nil {
+		s.Jobs.Enqueue(ctx, models.JobPublishWebhook, job)
+		return
+	}
+	go func() {
+		if err := s.fanOut(context.WithoutCancel(ctx), job); err != nil { // ✅ Keep the request ID, outlive the request
+			utils.Logf(ctx, "❌ %v", err)
+		}
+	}()
+}
+
+// RegisterJobs moves event fan-out onto the job queue, so events survive an instance restart
+func (s *WebhookService) RegisterJobs(queue *JobQueue) {
+	s.Jobs = queue
+	queue.Handle(models.JobPublishWebhook, func(ctx context.Context, payload json.RawMessage) error {
+		job, err := decodeJob[models.PublishWebhookJob](payload)
+		if err != nil {
+			return err
+		}
+		if job.DeliveryID == "" { // ✅ Queued before jobs carried their delivery ID
+			job.DeliveryID, job.CreatedAt = uuid.New().String(), time.Now().UTC().Format(time.RFC3339)
+		}
+		return s.fanOut(ctx, job)
+	})
+}
+
+// fanOut delivers the event to every subscribed webhook and waits for the deliveries, returning an
+// error if any of them failed
+func (s *WebhookService) fanOut(ctx context.Context, job models.PublishWebhookJob) error {
+	s.mu.RLock()
+	var targets []models.Webhook
+	for _, webhook := range s.webhooks {
+		if slices.Contains(webhook.Events, job.EventType) {
+			targets = append(targets, webhook)
+		}
+	}
+	s.mu.RUnlock()
+	if len(targets) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(models.WebhookDelivery{
+		DeliveryID: job.DeliveryID,
+		EventType:  job.EventType,
+		CreatedAt:  job.CreatedAt,
+		Data:       job.Data,
+	})
+	if err != nil {
+		utils.Logf(ctx, "❌ Failed to encode %s webhook event: %v", job.EventType, err)
+		return nil // Retrying can't fix the payload
+	}
+
+	s.initOnce.Do(func() { s.inFlight = make(chan struct{}, webhookMaxInFlight) })
+	errs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for i, webhook := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = s.deliver(ctx, webhook, job.EventType, job.DeliveryID, body)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// deliver POSTs one event to one webhook, retrying network errors, 429s and 5xx responses until the
+// attempts or ctx run out. A rejection (other 4xx) is final and not reported as a failure.
+func (s *WebhookService) deliver(ctx context.Context, webhook models.Webhook, eventType, deliveryID string, body []byte) error {
+	delay := webhookRetryBaseDelay
+	for attempt := 1; attempt <= webhookDeliveryAttempts; attempt++ {
+		status, err := s.post(ctx, webhook, eventType, deliveryID, body)
+		switch {
+		case err == nil && status < 300:
+			utils.Logf(ctx, "✅ Delivered %s %s to webhook %s (attempt %d)", eventType, deliveryID, webhook.WebhookID, attempt)
+			return nil
+		case err == nil && status < 500 && status != http.StatusTooManyRequests:
+			utils.Logf(ctx, "❌ Webhook %s rejected %s %s with %d; not retrying", webhook.WebhookID, eventType, deliveryID, status)
+			return nil
+		case err != nil:
+			utils.Logf(ctx, "⚠️ Webhook %s delivery of %s failed (attempt %d/%d): %v", webhook.WebhookID, deliveryID, attempt, webhookDeliveryAttempts, err)
+		default:
+			utils.Logf(ctx, "⚠️ Webhook %s returned %d for %s (attempt %d/%d)", webhook.WebhookID, status, deliveryID, attempt, webhookDeliveryAttempts)
+		}
+
+		if attempt < webhookDeliveryAttempts {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("webhook %s delivery of %s %s cut short: %w", webhook.WebhookID, eventType, deliveryID, ctx.Err())
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+	}
+	return fmt.Errorf("giving up on %s %s for webhook %s after %d attempts", eventType, deliveryID, webhook.WebhookID, webhookDeliveryAttempts)
+}
+
+// post sends one signed attempt and returns the response status
+func (s *WebhookService) post(ctx context.Context, webhook models.Webhook, eventType, deliveryID string, body []byte) (int, error) {
+	s.inFlight <- struct{}{} // ✅ Held per attempt, not across backoff sleeps
+	defer func() { <-s.inFlight }()
+
+	ctx, cancel := context.WithTimeout(ctx, webhookAttemptTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, eventType)
+	req.Header.Set(WebhookDeliveryHeader, deliveryID)
+	req.Header.Set(WebhookSignatureHeader, signWebhookPayload(body, webhook.Secret, time.Now()))
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// signWebhookPayload builds the signature header: "t=<unix>,v1=<hex HMAC-SHA256(secret, "<t>.<payload>")>"
+func signWebhookPayload(payload []byte, secret string, now time.Time) string {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// validateWebhookURL accepts absolute https URLs only, so payloads and signatures never travel in clear text
+func validateWebhookURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return validationError("url must be an absolute https URL")
+	}
+	return nil
+}
+
+// generateWebhookSecret returns 32 random bytes as hex
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+
+	tests := []struct {
+		name    string
+		payload string
+		secret  string
+		want    string
+	}{
+		{
+			name:    "event payload",
+			payload: `{"type":"match.created"}`,
+			secret:  "s3cr3t-signing-key",
+			want:    "t=1700000000,v1=" + stripeV1(`{"type":"match.created"}`, "s3cr3t-signing-key", 1_700_000_000),
+		},
+		{
+			name:    "empty payload",
+			payload: "",
+			secret:  "s3cr3t-signing-key",
+			want:    "t=1700000000,v1=" + stripeV1("", "s3cr3t-signing-key", 1_700_000_000),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := signWebhookPayload([]byte(test.payload), test.secret, now); got != test.want {
+				t.Errorf("signWebhookPayload() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+// Receivers verify our deliveries the way Stripe's are verified, so a signed payload must pass the
+// same check and fail it once anything changes
+func TestSignWebhookPayloadVerifies(t *testing.T) {
+	const payload = `{"type":"report.created","data":{"reportId":"r1"}}`
+	const secret = "s3cr3t-signing-key"
+	now := time.Unix(1_700_000_000, 0)
+	header := signWebhookPayload([]byte(payload), secret, now)
+
+	tests := []struct {
+		name    string
+		payload string
+		secret  string
+		at      time.Time
+		wantErr bool
+	}{
+		{"same payload and secret", payload, secret, now, false},
+		{"delivered within tolerance", payload, secret, now.Add(webhookTolerance), false},
+		{"replayed after tolerance", payload, secret, now.Add(webhookTolerance + time.Second), true},
+		{"changed payload", strings.Replace(payload, "r1", "r2", 1), secret, now, true},
+		{"other secret", payload, "another-signing-key", now, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := verifyStripeSignature([]byte(test.payload), header, test.secret, test.at)
+			if (err != nil) != test.wantErr {
+				t.Errorf("verifying %q: error = %v, want error %v", header, err, test.wantErr)
+			}
+		})
+	}
+}
@@ -1,39 +1,82 @@
 package services
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
-	"os"
+	"io"
+	"log"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
 	"time"
+	"vibin_server/models"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/google/uuid"
 )
 
-var s3Client *s3.Client
+// S3Service presigns uploads and reads against the media bucket
+type S3Service struct {
+	Client *s3.Client
+	Bucket string
+}
 
-func init() {
-	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion(os.Getenv("AWS_REGION")))
+// InitializeS3Client initializes the S3 client
+func InitializeS3Client(region string) *s3.Client {
+	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion(region))
 	if err != nil {
-		panic(err)
+		log.Fatalf("Failed to load AWS config: %v", err)
 	}
-	s3Client = s3.NewFromConfig(cfg)
+	return s3.NewFromConfig(cfg)
 }
 
-// GenerateUploadURL generates a presigned URL for uploading a file
-func GenerateUploadURL(fileName, fileType, path string) (string, string, error) {
-	// ✅ Ensure `path` is added only once
-	key := fmt.Sprintf("%s%s", path, fileName)
+// ✅ Presigned URL lifetimes; short so a leaked URL is of little use
+const (
+	uploadURLExpiry = 5 * time.Minute
+	partURLExpiry   = 15 * time.Minute // One part of a large video on a slow connection
+	readURLExpiry   = 5 * time.Minute
+)
+
+// uploadPathPattern matches the client-chosen folder under the user's prefix, e.g. "photos" or "chat/images"
+var uploadPathPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}(/[A-Za-z0-9_-]{1,32}){0,3}$`)
+
+// GenerateUploadURL presigns a PUT of an allowed image, video or audio type, of exactly size bytes when
+// the client sends one (older clients don't). The key is generated under the prefix of userHandle,
+// the session's user, so clients can't choose (or overwrite) arbitrary keys.
+func (s *S3Service) GenerateUploadURL(ctx context.Context, userHandle, fileName, fileType, path string, size int64) (string, string, error) {
+	maxBytes := int64(models.MaxImageUploadBytes)
+	if _, ok := models.VideoUploadTypes[fileType]; ok {
+		maxBytes = models.MaxVideoBytes
+	} else if _, ok := models.AudioUploadTypes[fileType]; ok {
+		maxBytes = models.MaxAudioUploadBytes
+	}
+	if size < 0 || size > maxBytes {
+		return "", "", validationError(fmt.Sprintf("size must be between 1 and %d bytes", maxBytes))
+	}
+	key, err := uploadKey(userHandle, fileName, fileType, path, models.ImageUploadTypes, models.VideoUploadTypes, models.AudioUploadTypes)
+	if err != nil {
+		return "", "", err
+	}
 
 	params := &s3.PutObjectInput{
-		Bucket:      aws.String(os.Getenv("S3_BUCKET_NAME")),
+		Bucket:      aws.String(s.Bucket),
 		Key:         aws.String(key),
-		ContentType: aws.String(fileType),
+		ContentType: aws.String(fileType), // ✅ Signed: the upload must use this type (and length, when given)
+	}
+	if size > 0 {
+		params.ContentLength = aws.Int64(size)
 	}
 
-	presigner := s3.NewPresignClient(s3Client)
-	presignedURL, err := presigner.PresignPutObject(context.TODO(), params, s3.WithPresignExpires(5*time.Minute))
+	presigner := s3.NewPresignClient(s.Client)
+	presignedURL, err := presigner.PresignPutObject(ctx, params, s3.WithPresignExpires(uploadURLExpiry))
 
 	if err != nil {
 		return "", "", err
@@ -43,15 +86,233 @@ func GenerateUploadURL(fileName, fileType, path string) (string, string, error)
 }
 
 // GenerateReadURL generates a presigned URL for reading a file
-func GenerateReadURL(key string) (string, error) {
+func (s *S3Service) GenerateReadURL(ctx context.Context, key string) (string, error) {
 	params := &s3.GetObjectInput{
-		Bucket: aws.String(os.Getenv("S3_BUCKET_NAME")),
+		Bucket: aws.String(s.Bucket),
 		Key:    aws.String(key),
 	}
-	presigner := s3.NewPresignClient(s3Client)
-	presignedURL, err := presigner.PresignGetObject(context.TODO(), params, s3.WithPresignExpires(5*time.Minute))
+	presigner := s3.NewPresignClient(s.Client)
+	presignedURL, err := presigner.PresignGetObject(ctx, params, s3.WithPresignExpires(readURLExpiry))
 	if err != nil {
 		return "", err
 	}
 	return presignedURL.URL, nil
 }
+
+// uploadKey checks the content type is allowed and agrees with the file extension, then builds
+// "users/<handle>/<path>/<random><ext>"
+func uploadKey(userHandle, fileName, fileType, path string, allowed ...map[string][]string) (string, error) {
+	var extensions []string
+	for _, typeExtensions := range allowed {
+		if exts, ok := typeExtensions[fileType]; ok {
+			extensions = exts
+		}
+	}
+	if extensions == nil {
+		return "", validationError(fmt.Sprintf("file type %q is not allowed", fileType))
+	}
+	ext := strings.ToLower(filepath.Ext(fileName))
+	if !slices.Contains(extensions, ext) {
+		return "", validationError(fmt.Sprintf("file extension %q does not match type %s", ext, fileType))
+	}
+	path = strings.Trim(path, "/")
+	if !uploadPathPattern.MatchString(path) {
+		return "", validationError("path must be one to four folders of letters, digits, '_' or '-'")
+	}
+	return models.UserUploadPrefix(userHandle) + path + "/" + uuid.New().String() + ext, nil
+}
+
+// GetObject downloads an object, failing with a validation error when it is larger than maxBytes
+func (s *S3Service) GetObject(ctx context.Context, key string, maxBytes int64) ([]byte, error) {
+	output, err := s.Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object %s: %w", key, err)
+	}
+	defer output.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(output.Body, maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object %s: %w", key, err)
+	}
+	if int64(len(body)) > maxBytes {
+		return nil, validationError(fmt.Sprintf("object is larger than %d bytes", maxBytes))
+	}
+	return body, nil
+}
+
+// HeadObject returns an uploaded object's size and content type, failing with ErrNotFound when it
+// doesn't exist
+func (s *S3Service) HeadObject(ctx context.Context, key string) (int64, string, error) {
+	output, err := s.Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	})
+	var notFound *s3types.NotFound
+	if errors.As(err, &notFound) {
+		return 0, "", notFoundError("upload not found")
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to head object %s: %w", key, err)
+	}
+	return aws.ToInt64(output.ContentLength), aws.ToString(output.ContentType), nil
+}
+
+// PutObject uploads an object
+func (s *S3Service) PutObject(ctx context.Context, key, contentType string, body []byte) error {
+	_, err := s.Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.Bucket),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+		Body:        bytes.NewReader(body),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put object %s: %w", key, err)
+	}
+	return nil
+}
+
+// CreateMultipartUpload starts a multipart upload of a size-byte video under the user's prefix and
+// returns how to split it into parts
+func (s *S3Service) CreateMultipartUpload(ctx context.Context, userHandle, fileName, fileType, path string, size int64) (*models.MultipartUpload, error) {
+	if size <= 0 || size > models.MaxVideoBytes {
+		return nil, validationError(fmt.Sprintf("size must be between 1 and %d bytes", models.MaxVideoBytes))
+	}
+	key, err := uploadKey(userHandle, fileName, fileType, path, models.VideoUploadTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := s.Client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(s.Bucket),
+		Key:         aws.String(key),
+		ContentType: aws.String(fileType),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create multipart upload for %s: %w", key, err)
+	}
+
+	return &models.MultipartUpload{
+		Key:       key,
+		UploadID:  aws.ToString(output.UploadId),
+		PartSize:  models.VideoPartSize,
+		PartCount: int((size + models.VideoPartSize - 1) / models.VideoPartSize),
+	}, nil
+}
+
+// GeneratePartUploadURLs presigns a PUT for each requested part of a multipart upload
+func (s *S3Service) GeneratePartUploadURLs(ctx context.Context, key, uploadID string, partNumbers []int32) ([]models.PartUploadURL, error) {
+	presigner := s3.NewPresignClient(s.Client)
+	urls := make([]models.PartUploadURL, 0, len(partNumbers))
+	for _, partNumber := range partNumbers {
+		presigned, err := presigner.PresignUploadPart(ctx, &s3.UploadPartInput{
+			Bucket:     aws.String(s.Bucket),
+			Key:        aws.String(key),
+			UploadId:   aws.String(uploadID),
+			PartNumber: aws.Int32(partNumber),
+		}, s3.WithPresignExpires(partURLExpiry))
+		if err != nil {
+			return nil, fmt.Errorf("failed to presign part %d of %s: %w", partNumber, key, err)
+		}
+		urls = append(urls, models.PartUploadURL{PartNumber: partNumber, URL: presigned.URL})
+	}
+	return urls, nil
+}
+
+// CompleteMultipartUpload assembles the uploaded parts into the final object
+func (s *S3Service) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []models.UploadedPart) error {
+	// ✅ S3 requires ascending part numbers; clients may finish parts out of order
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	completed := make([]s3types.CompletedPart, 0, len(parts))
+	for _, part := range parts {
+		completed = append(completed, s3types.CompletedPart{
+			PartNumber: aws.Int32(part.PartNumber),
+			ETag:       aws.String(part.ETag),
+		})
+	}
+
+	_, err := s.Client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(s.Bucket),
+		Key:             aws.String(key),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &s3types.CompletedMultipartUpload{Parts: completed},
+	})
+	if err != nil {
+		return classifyMultipartError(err, key)
+	}
+	return nil
+}
+
+// AbortMultipartUpload discards an unfinished upload and the parts stored so far
+func (s *S3Service) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	_, err := s.Client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(s.Bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+	if err != nil {
+		return classifyMultipartError(err, key)
+	}
+	return nil
+}
+
+// classifyMultipartError reports unknown or already finished uploads as not found, and parts the
+// client got wrong (missing, mismatched ETag, too small) as validation errors
+func classifyMultipartError(err error, key string) error {
+	var noSuchUpload *s3types.NoSuchUpload
+	if errors.As(err, &noSuchUpload) {
+		return notFoundError("upload not found")
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "NoSuchUpload":
+			return notFoundError("upload not found")
+		case "InvalidPart", "InvalidPartOrder", "EntityTooSmall":
+			return validationError(apiErr.ErrorMessage())
+		}
+	}
+	return fmt.Errorf("multipart upload of %s failed: %w", key, err)
+}
+
+// ListKeys returns every key under prefix
+func (s *S3Service) ListKeys(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	paginator := s3.NewListObjectsV2Paginator(s.Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.Bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects under %s: %w", prefix, err)
+		}
+		for _, object := range page.Contents {
+			keys = append(keys, aws.ToString(object.Key))
+		}
+	}
+	return keys, nil
+}
+
+// DeleteObjects removes the given keys; keys that don't exist count as deleted
+func (s *S3Service) DeleteObjects(ctx context.Context, keys []string) error {
+	objects := make([]s3types.ObjectIdentifier, 0, len(keys))
+	for _, key := range keys {
+		objects = append(objects, s3types.ObjectIdentifier{Key: aws.String(key)})
+	}
+
+	output, err := s.Client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+		Bucket: aws.String(s.Bucket),
+		Delete: &s3types.Delete{Objects: objects, Quiet: aws.Bool(true)},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete objects: %w", err)
+	}
+	if len(output.Errors) > 0 {
+		failed := output.Errors[0]
+		return fmt.Errorf("failed to delete %d of %d objects (first: %s: %s)", len(output.Errors), len(keys), aws.ToString(failed.Key), aws.ToString(failed.Message))
+	}
+	return nil
+}
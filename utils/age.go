@@ -0,0 +1,21 @@
+package utils
+
+import "time"
+
+// ParseDOB parses a date of birth given as YYYY-MM-DD or RFC3339
+func ParseDOB(dob string) (time.Time, error) {
+	born, err := time.Parse("2006-01-02", dob)
+	if err != nil {
+		born, err = time.Parse(time.RFC3339, dob)
+	}
+	return born, err
+}
+
+// AgeOn returns the age in whole years, on the given day, of someone born on born
+func AgeOn(born, now time.Time) int {
+	age := now.Year() - born.Year()
+	if now.Month() < born.Month() || now.Month() == born.Month() && now.Day() < born.Day() {
+		age--
+	}
+	return age
+}
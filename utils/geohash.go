@@ -0,0 +1,35 @@
+package utils
+
+// geohashAlphabet is the base32 alphabet of geohashes (no a, i, l, o)
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// EncodeGeohash returns the geohash of a point with the given number of characters. Nearby points
+// share a prefix, so a prefix query finds everyone in the same cell.
+func EncodeGeohash(latitude, longitude float64, precision int) string {
+	latRange := [2]float64{-90, 90}
+	lonRange := [2]float64{-180, 180}
+	hash := make([]byte, 0, precision)
+	even := true // ✅ Bits alternate longitude, latitude, starting with longitude
+	bit, ch := 0, 0
+	for len(hash) < precision {
+		span, value := &lonRange, longitude
+		if !even {
+			span, value = &latRange, latitude
+		}
+		mid := (span[0] + span[1]) / 2
+		ch <<= 1
+		if value >= mid {
+			ch |= 1
+			span[0] = mid
+		} else {
+			span[1] = mid
+		}
+		even = !even
+
+		if bit++; bit == 5 {
+			hash = append(hash, geohashAlphabet[ch])
+			bit, ch = 0, 0
+		}
+	}
+	return string(hash)
+}
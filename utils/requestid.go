@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// requestIDKey is the context key holding the current request's ID
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID, or "" outside a request (startup, reloaders)
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// Logf logs like log.Printf, prefixed with the request ID when ctx carries one
+func Logf(ctx context.Context, format string, args ...interface{}) {
+	log.Output(2, requestIDPrefix(ctx)+fmt.Sprintf(format, args...))
+}
+
+// Logln logs like log.Println, prefixed with the request ID when ctx carries one
+func Logln(ctx context.Context, args ...interface{}) {
+	log.Output(2, requestIDPrefix(ctx)+fmt.Sprintln(args...))
+}
+
+// requestIDPrefix formats the ID so log lines can be grepped by request
+func requestIDPrefix(ctx context.Context) string {
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		return "[req " + requestID + "] "
+	}
+	return ""
+}